@@ -0,0 +1,142 @@
+//go:build js && wasm
+// +build js,wasm
+
+// Command flux-wasm runs Flux in a JavaScript host such as a browser.
+//
+// It is built with GOOS=js GOARCH=wasm and needs the libflux module,
+// built with wasm-pack, to be assigned to the libflux global before
+// it is run. It then assigns the flux global, whose functions return
+// promises:
+//
+//	flux.parse(script)          resolves to the AST of the script as JSON.
+//	flux.analyze(script)        resolves if the script is valid.
+//	flux.execute(script, files) resolves to the results as annotated CSV.
+//
+// The files passed to execute are an object that maps file names
+// to CSV text, which the script can read with csv.from(file: name).
+package main
+
+import (
+	"bytes"
+	"context"
+	"syscall/js"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/fluxinit"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/parser"
+	"github.com/influxdata/flux/runtime"
+)
+
+// script returns the script that is the first argument.
+func script(args []js.Value) (string, error) {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return "", errors.New(codes.Invalid, "expected the script as the first argument")
+	}
+	return args[0].String(), nil
+}
+
+func parse(args []js.Value) (interface{}, error) {
+	src, err := script(args)
+	if err != nil {
+		return nil, err
+	}
+	hdl, err := parser.ParseToHandle([]byte(src))
+	if err != nil {
+		return nil, err
+	}
+	bs, err := parser.HandleToJSON(hdl)
+	if err != nil {
+		return nil, err
+	}
+	return string(bs), nil
+}
+
+func analyze(args []js.Value) (interface{}, error) {
+	src, err := script(args)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := runtime.AnalyzeSource(src); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+func execute(args []js.Value) (interface{}, error) {
+	src, err := script(args)
+	if err != nil {
+		return nil, err
+	}
+	fs := filesystem.MemoryFS{}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		names := js.Global().Get("Object").Call("keys", args[1])
+		for i := 0; i < names.Length(); i++ {
+			name := names.Index(i).String()
+			fs[name] = []byte(args[1].Get(name).String())
+		}
+	}
+
+	deps := flux.NewDefaultDependencies()
+	deps.Deps.FilesystemService = fs
+	ctx := deps.Inject(context.Background())
+
+	prog, err := lang.FluxCompiler{Query: src}.Compile(ctx, runtime.Default)
+	if err != nil {
+		return nil, err
+	}
+	q, err := prog.Start(ctx, &memory.Allocator{})
+	if err != nil {
+		return nil, err
+	}
+	defer q.Done()
+
+	var buf bytes.Buffer
+	enc := csv.NewMultiResultEncoder(csv.DefaultEncoderConfig())
+	if _, err := enc.Encode(&buf, flux.NewResultIteratorFromQuery(q)); err != nil {
+		return nil, err
+	}
+	return buf.String(), nil
+}
+
+// promise wraps the function in a JavaScript function that returns
+// a promise. The function runs in its own goroutine because it may
+// block, which a function called from JavaScript must not do.
+func promise(fn func(args []js.Value) (interface{}, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		// The executor is called before the promise is constructed,
+		// so it can be released on return.
+		executor := js.FuncOf(func(this js.Value, cbs []js.Value) interface{} {
+			resolve, reject := cbs[0], cbs[1]
+			go func() {
+				v, err := fn(args)
+				if err != nil {
+					reject.Invoke(js.Global().Get("Error").New(err.Error()))
+					return
+				}
+				resolve.Invoke(v)
+			}()
+			return nil
+		})
+		defer executor.Release()
+		return js.Global().Get("Promise").New(executor)
+	})
+}
+
+func main() {
+	fluxinit.FluxInit()
+
+	js.Global().Set("flux", map[string]interface{}{
+		"parse":   promise(parse),
+		"analyze": promise(analyze),
+		"execute": promise(execute),
+	})
+
+	// Keep the program running so the functions can be called.
+	select {}
+}
@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/influxdata/flux/fluxdoc"
+	"github.com/spf13/cobra"
+)
+
+// docsCmd represents the docs command
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Show the documentation of the standard library",
+	Long: `Show the documentation of the standard library (flux docs [package [member]]).
+Without arguments, the packages are listed with their headlines.
+With a package, its members are listed, and with a member its full documentation is shown.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: showDocs,
+}
+
+var docsJSON bool
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.SilenceUsage = true
+	docsCmd.Flags().BoolVar(&docsJSON, "json", false, "print the documentation as JSON")
+}
+
+func showDocs(cmd *cobra.Command, args []string) error {
+	var v interface{}
+	switch len(args) {
+	case 0:
+		pkgs, err := fluxdoc.Packages()
+		if err != nil {
+			return err
+		}
+		v = pkgs
+		if !docsJSON {
+			for _, pkg := range pkgs {
+				fmt.Printf("%-50s %s\n", pkg.Path, headline(pkg.Headline))
+			}
+			return nil
+		}
+	case 1:
+		pkg, err := fluxdoc.LookupPackage(args[0])
+		if err != nil {
+			return err
+		}
+		v = pkg
+		if !docsJSON {
+			printPackageDoc(os.Stdout, pkg)
+			return nil
+		}
+	default:
+		m, err := fluxdoc.Lookup(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		v = m
+		if !docsJSON {
+			printMemberDoc(os.Stdout, m)
+			return nil
+		}
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// headline joins the lines of a headline to list it on one line.
+func headline(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func printPackageDoc(w io.Writer, pkg *fluxdoc.Package) {
+	fmt.Fprintf(w, "package %s // import %q\n\n%s\n", pkg.Name, pkg.Path, pkg.Headline)
+	if pkg.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", pkg.Description)
+	}
+	fmt.Fprintln(w)
+	for _, name := range pkg.MemberNames() {
+		m := pkg.Members[name]
+		fmt.Fprintf(w, "%s\n    %s\n", m.Signature(), headline(m.Headline))
+	}
+}
+
+func printMemberDoc(w io.Writer, m *fluxdoc.Member) {
+	fmt.Fprintf(w, "%s\n\n%s\n", m.Signature(), m.Headline)
+	if m.Description != "" {
+		fmt.Fprintf(w, "\n%s\n", m.Description)
+	}
+	if len(m.Parameters) > 0 {
+		fmt.Fprintln(w, "\nParameters:")
+		for _, p := range m.Parameters {
+			optional := ""
+			if !p.Required {
+				optional = " (optional)"
+			}
+			fmt.Fprintf(w, "  %s%s: %s\n", p.Name, optional, headline(p.Headline))
+		}
+	}
+	for _, ex := range m.Examples {
+		fmt.Fprintf(w, "\nExample: %s\n\n%s\n", ex.Title, ex.Content)
+	}
+}
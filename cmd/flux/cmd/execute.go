@@ -2,13 +2,30 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/feature"
 	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/dependencies/http"
 	"github.com/influxdata/flux/dependencies/influxdb"
+	"github.com/influxdata/flux/dependencies/objectstore"
+	"github.com/influxdata/flux/dependencies/sandbox"
+	"github.com/influxdata/flux/dependencies/secret"
+	"github.com/influxdata/flux/dependencies/sqlpool"
+	"github.com/influxdata/flux/dependencies/tablebuffer"
+	"github.com/influxdata/flux/dependencies/url"
 	"github.com/influxdata/flux/fluxinit"
+	"github.com/influxdata/flux/fluxpkg"
 	"github.com/influxdata/flux/repl"
+	"github.com/influxdata/flux/resultcache"
+	"github.com/influxdata/flux/runtime"
 	"github.com/spf13/cobra"
 )
 
@@ -21,15 +38,172 @@ var executeCmd = &cobra.Command{
 	RunE:  execute,
 }
 
+var executeFlags struct {
+	watch         bool
+	watchInterval time.Duration
+}
+
 func init() {
+	addQueryFlags(executeCmd)
+	executeCmd.Flags().BoolVar(&executeFlags.watch, "watch", false, "Execute the script file again each time it changes.")
+	executeCmd.Flags().DurationVar(&executeFlags.watchInterval, "watch-interval", repl.DefaultWatchInterval, "How often the script file is checked for changes when watching.")
 	rootCmd.AddCommand(executeCmd)
 }
 
+var queryFlags struct {
+	profilers     []string
+	profileOutput string
+	bufferSize    int
+	cacheSize     int64
+	cacheDir      string
+	timeout       time.Duration
+	otlpEndpoint  string
+	disableRules  []string
+	features      []string
+	limits        sandbox.Limits
+	secrets       secret.Config
+	urlRules      url.Rules
+	urlPolicy     string
+	http          http.Config
+	httpConfig    string
+	httpMaxBytes  int64
+	fsRoot        string
+	maxFileSize   int64
+	sqlPool       sqlpool.Config
+	compileCache  int
+}
+
+// addQueryFlags adds the flags used to configure
+// how queries are executed to the command.
+func addQueryFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&queryFlags.profilers, "profilers", nil, "Comma-separated list of profilers to enable (query, operator).")
+	cmd.Flags().StringVar(&queryFlags.profileOutput, "profile-output", "", "Write the operator profile to this file in pprof format.")
+	cmd.Flags().IntVar(&queryFlags.bufferSize, "buffer-size", 0, "The number of rows sources buffer in each table chunk. Each source uses its own default when unset.")
+	cmd.Flags().Int64Var(&queryFlags.cacheSize, "cache-size", 0, "Cache query results in memory up to this many bytes.")
+	cmd.Flags().StringVar(&queryFlags.cacheDir, "cache-dir", "", "Cache query results in this directory.")
+	cmd.Flags().DurationVar(&queryFlags.timeout, "timeout", 0, "The maximum amount of time each query may run.")
+	cmd.Flags().StringSliceVar(&queryFlags.disableRules, "disable-rules", nil, "Comma-separated list of planner rules that are not applied.")
+	cmd.Flags().StringSliceVar(&queryFlags.features, "feature", nil, "Comma-separated list of feature flags to override, as key=value.")
+	cmd.Flags().StringVar(&queryFlags.otlpEndpoint, "otlp-endpoint", "", "Export traces of each query to this OTLP gRPC endpoint (host:port).")
+	cmd.Flags().DurationVar(&queryFlags.limits.StatementTimeout, "max-statement-time", 0, "The maximum amount of time the evaluation of each statement may take.")
+	cmd.Flags().IntVar(&queryFlags.limits.MaxCallDepth, "max-call-depth", 0, "The maximum depth of nested function calls.")
+	cmd.Flags().IntVar(&queryFlags.limits.MaxStringSize, "max-string-size", 0, "The maximum size in bytes of the strings built by evaluation.")
+	cmd.Flags().IntVar(&queryFlags.limits.MaxArraySize, "max-array-size", 0, "The maximum number of elements of the arrays built by evaluation.")
+	cmd.Flags().StringVar(&queryFlags.http.Proxy, "http-proxy", "", "The url of the proxy of the HTTP requests. Defaults to $HTTP_PROXY and $HTTPS_PROXY.")
+	cmd.Flags().StringVar(&queryFlags.http.CAFile, "http-ca-file", "", "A PEM file of the certificate authorities trusted by the HTTP client in addition to those of the system.")
+	cmd.Flags().StringVar(&queryFlags.http.CertSecret, "http-cert-secret", "", "The secret key of the PEM certificate the HTTP client authenticates with.")
+	cmd.Flags().StringVar(&queryFlags.http.KeySecret, "http-key-secret", "", "The secret key of the PEM private key of the HTTP client certificate.")
+	cmd.Flags().StringVar(&queryFlags.httpConfig, "http-config", "", "A JSON file of the HTTP client configuration, with the configuration of some hosts under \"hosts\". The other HTTP flags override it.")
+	cmd.Flags().Float64Var(&queryFlags.http.RequestsPerSecond, "http-requests-per-second", 0, "The maximum rate of the HTTP requests to each host. Requests above the rate wait for their turn.")
+	cmd.Flags().IntVar(&queryFlags.http.Burst, "http-burst", 1, "The number of HTTP requests to a host that may be sent at once above --http-requests-per-second.")
+	cmd.Flags().IntVar(&queryFlags.http.Retry.MaxAttempts, "http-max-attempts", 0, "The maximum number of times an HTTP request that fails is sent. It overrides \"retry\" in --http-config, and the functions that send requests may override both.")
+	cmd.Flags().Int64Var(&queryFlags.httpMaxBytes, "http-max-query-bytes", 0, "The maximum number of bytes the HTTP requests of each query may send and receive.")
+	cmd.Flags().StringVar(&queryFlags.fsRoot, "fs-root", "", "Confine the files that queries read to this directory. Paths are resolved under it and may not escape it through symbolic links.")
+	cmd.Flags().Int64Var(&queryFlags.maxFileSize, "max-file-size", 0, "The maximum size in bytes of the files that queries read under --fs-root.")
+	cmd.Flags().IntVar(&queryFlags.sqlPool.MaxDataSources, "sql-max-data-sources", sqlpool.DefaultMaxDataSources, "The maximum number of SQL data sources that are kept open. The least recently used data source is closed to open another.")
+	cmd.Flags().IntVar(&queryFlags.sqlPool.MaxOpenConns, "sql-max-open-conns", 0, "The maximum number of open connections to each SQL data source. There is no limit when unset.")
+	cmd.Flags().IntVar(&queryFlags.sqlPool.MaxIdleConns, "sql-max-idle-conns", sqlpool.DefaultMaxIdleConns, "The maximum number of idle connections kept open to each SQL data source.")
+	cmd.Flags().DurationVar(&queryFlags.sqlPool.ConnMaxLifetime, "sql-conn-max-lifetime", 0, "The maximum amount of time a SQL connection is reused.")
+	cmd.Flags().DurationVar(&queryFlags.sqlPool.ConnMaxIdleTime, "sql-conn-max-idle-time", sqlpool.DefaultConnMaxIdleTime, "The maximum amount of time a SQL connection is kept open while it is idle.")
+	cmd.Flags().StringVar(&queryFlags.secrets.Backend, "secrets", "", "The backend of secrets.get (env, file, vault, aws). No secrets exist when unset.")
+	cmd.Flags().StringVar(&queryFlags.secrets.Dir, "secrets-dir", "", "The directory of the file secrets backend, with one file per secret.")
+	cmd.Flags().StringVar(&queryFlags.secrets.VaultAddress, "vault-addr", "", "The address of the Vault server of the vault secrets backend. Defaults to $VAULT_ADDR; the token is read from $VAULT_TOKEN.")
+	cmd.Flags().StringVar(&queryFlags.secrets.VaultMount, "vault-mount", secret.DefaultVaultMount, "The mount of the KV secrets engine of the vault secrets backend.")
+	cmd.Flags().StringSliceVar(&queryFlags.urlRules.Schemes, "allow-schemes", nil, "Comma-separated list of the url schemes that queries may connect with. All schemes are allowed when unset.")
+	cmd.Flags().StringSliceVar(&queryFlags.urlRules.AllowHosts, "allow-hosts", nil, "Comma-separated list of the hosts (example.com, *.example.com) and IP ranges (10.0.0.0/8) that queries may connect to. All hosts are allowed when unset.")
+	cmd.Flags().StringSliceVar(&queryFlags.urlRules.DenyHosts, "deny-hosts", nil, "Comma-separated list of the hosts and IP ranges that queries may not connect to.")
+	cmd.Flags().BoolVar(&queryFlags.urlRules.DenyPrivateIPs, "deny-private-ips", false, "Deny the connections to private IPs that are not in the IP ranges of --allow-hosts.")
+	cmd.Flags().StringVar(&queryFlags.urlPolicy, "url-policy", "", "A JSON file of url rules, with rules for the packages (http, sql) under \"packages\". The other url flags add to its rules.")
+	cmd.Flags().IntVar(&queryFlags.compileCache, "compile-cache-size", 100, "The number of scripts and query plans kept compiled, so running them again does not compile them again. Nothing is kept when 0.")
+	cmd.Flags().StringVar(&queryFlags.secrets.AWSRegion, "aws-region", "", "The region of the aws secrets backend. Defaults to $AWS_REGION; the credentials are read from the AWS environment variables.")
+}
+
+// newREPL creates a REPL configured with the query flags.
+func newREPL(ctx context.Context, deps flux.Dependencies) (*repl.REPL, error) {
+	r := repl.New(ctx, deps)
+	r.EnableProfilers(queryFlags.profilers...)
+	r.SetProfileOutput(queryFlags.profileOutput)
+	r.SetTimeout(queryFlags.timeout)
+	r.SetDisabledRules(queryFlags.disableRules...)
+	flags, err := feature.ParseOverrides(queryFlags.features)
+	if err != nil {
+		return nil, err
+	}
+	r.SetFeatureFlags(flags)
+	r.SetFluxPath(fluxPath())
+
+	if queryFlags.cacheSize > 0 || queryFlags.cacheDir != "" {
+		var next resultcache.Cache
+		if queryFlags.cacheDir != "" {
+			dir, err := resultcache.NewDir(queryFlags.cacheDir)
+			if err != nil {
+				return nil, err
+			}
+			next = dir
+		}
+		r.SetResultCache(resultcache.NewLRU(queryFlags.cacheSize, next))
+	}
+	return r, nil
+}
+
+// fluxPath returns the directories that packages which are not
+// part of the standard library are imported from. The packages
+// installed in the project in the current directory are imported
+// before the packages in FLUXPATH.
+func fluxPath() []string {
+	dirs := runtime.FluxPath()
+	if fluxpkg.HasManifest(".") {
+		dirs = append([]string{fluxpkg.Dir(".")}, dirs...)
+	}
+	return dirs
+}
+
 const DefaultInfluxDBHost = "http://localhost:8086"
 
-func injectDependencies(ctx context.Context) (context.Context, flux.Dependencies) {
+func injectDependencies(ctx context.Context) (context.Context, flux.Dependencies, error) {
 	deps := flux.NewDefaultDependencies()
 	deps.Deps.FilesystemService = filesystem.SystemFS
+	if queryFlags.fsRoot != "" {
+		fs, err := filesystem.NewRootFS(queryFlags.fsRoot, queryFlags.maxFileSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		deps.Deps.FilesystemService = fs
+	} else if queryFlags.maxFileSize > 0 {
+		return nil, nil, fmt.Errorf("--max-file-size requires --fs-root")
+	}
+
+	// like the objects, the secrets may be read
+	// with the credentials of the environment.
+	secrets := queryFlags.secrets
+	secrets.UseEnvironment = true
+	ss, err := secret.New(secrets)
+	if err != nil {
+		return nil, nil, err
+	}
+	deps.Deps.SecretService = ss
+
+	policy, err := newURLPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+	httpConfig, err := newHTTPConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	if policy != nil || !httpConfig.IsZero() {
+		var validator url.Validator = url.PassValidator{}
+		if policy != nil {
+			deps.Deps.URLValidator = policy
+			// the HTTP client checks the IPs of its connections with the rules of the http package.
+			validator = policy.ForPackage(url.HTTPPackage)
+		}
+		client, err := http.NewClient(ctx, validator, httpConfig, ss)
+		if err != nil {
+			return nil, nil, err
+		}
+		deps.Deps.HTTPClient = client
+	}
 
 	// inject the dependencies to the context.
 	// one useful example is socket.from, kafka.to, and sql.from/sql.to where we need
@@ -43,13 +217,114 @@ func injectDependencies(ctx context.Context) (context.Context, flux.Dependencies
 			},
 		},
 	}
-	return ip.Inject(ctx), deps
+	ctx = ip.Inject(ctx)
+
+	// the flux command runs the queries of its user,
+	// who may use the credentials of the environment to read objects.
+	op := objectstore.Dependency{
+		Provider: objectstore.DefaultProvider{UseEnvironment: true},
+	}
+	ctx = op.Inject(ctx)
+
+	// the queries of the session share the connections to the SQL databases.
+	ctx = sqlpool.Inject(ctx, sqlpool.New(queryFlags.sqlPool))
+
+	if queryFlags.bufferSize > 0 {
+		ctx = tablebuffer.Inject(ctx, queryFlags.bufferSize)
+	}
+	ctx = queryFlags.limits.Inject(ctx)
+	ctx = http.InjectMaxQueryBytes(ctx, queryFlags.httpMaxBytes)
+	ctx = runtime.NewCompileCache(queryFlags.compileCache).Inject(ctx)
+	return ctx, deps, nil
+}
+
+// newURLPolicy creates the policy of the url flags.
+// It returns nil when they allow every url.
+func newURLPolicy() (*url.Policy, error) {
+	rules, err := newURLRules()
+	if err != nil {
+		return nil, err
+	}
+	if rules.IsZero() {
+		return nil, nil
+	}
+	return url.NewPolicy(rules)
+}
+
+// newURLRules creates the url rules of the url flags.
+func newURLRules() (url.Rules, error) {
+	var rules url.Rules
+	if queryFlags.urlPolicy != "" {
+		data, err := ioutil.ReadFile(queryFlags.urlPolicy)
+		if err != nil {
+			return rules, err
+		}
+		if err := json.Unmarshal(data, &rules); err != nil {
+			return rules, fmt.Errorf("invalid url policy %s: %v", queryFlags.urlPolicy, err)
+		}
+	}
+	rules.Schemes = append(rules.Schemes, queryFlags.urlRules.Schemes...)
+	rules.AllowHosts = append(rules.AllowHosts, queryFlags.urlRules.AllowHosts...)
+	rules.DenyHosts = append(rules.DenyHosts, queryFlags.urlRules.DenyHosts...)
+	rules.DenyPrivateIPs = rules.DenyPrivateIPs || queryFlags.urlRules.DenyPrivateIPs
+	return rules, nil
+}
+
+// newHTTPConfig creates the HTTP client configuration of the http flags.
+func newHTTPConfig() (http.Config, error) {
+	var config http.Config
+	if queryFlags.httpConfig != "" {
+		data, err := ioutil.ReadFile(queryFlags.httpConfig)
+		if err != nil {
+			return config, err
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return config, fmt.Errorf("invalid http config %s: %v", queryFlags.httpConfig, err)
+		}
+	}
+	override := func(v *string, flag string) {
+		if flag != "" {
+			*v = flag
+		}
+	}
+	override(&config.Proxy, queryFlags.http.Proxy)
+	override(&config.CAFile, queryFlags.http.CAFile)
+	override(&config.CertSecret, queryFlags.http.CertSecret)
+	override(&config.KeySecret, queryFlags.http.KeySecret)
+	if queryFlags.http.RequestsPerSecond > 0 {
+		config.RequestsPerSecond = queryFlags.http.RequestsPerSecond
+		config.Burst = queryFlags.http.Burst
+	}
+	if queryFlags.http.Retry.MaxAttempts > 0 {
+		config.Retry.MaxAttempts = queryFlags.http.Retry.MaxAttempts
+	}
+	return config, nil
 }
 
 func execute(cmd *cobra.Command, args []string) error {
 	fluxinit.FluxInit()
-	ctx, deps := injectDependencies(context.Background())
-	r := repl.New(ctx, deps)
+	ctx, deps, err := injectDependencies(context.Background())
+	if err != nil {
+		return err
+	}
+	ctx, shutdown, err := setupTracing(ctx)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	r, err := newREPL(ctx, deps)
+	if err != nil {
+		return err
+	}
+	if executeFlags.watch {
+		if !strings.HasPrefix(args[0], "@") {
+			return fmt.Errorf("--watch requires a script file (use @ as prefix to the file)")
+		}
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+		defer stop()
+		return r.Watch(ctx, args[0][1:], executeFlags.watchInterval)
+	}
 	if err := r.Input(args[0]); err != nil {
 		return fmt.Errorf("failed to execute query: %v", err)
 	}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+
+	"github.com/influxdata/flux/fluxpkg"
+	"github.com/spf13/cobra"
+)
+
+// getCmd represents the get command
+var getCmd = &cobra.Command{
+	Use:   "get <path>@<version>",
+	Short: "Add a Flux package to the project",
+	Long:  "Add a Flux package to the manifest of the project in the current directory and install it",
+	Args:  cobra.ExactArgs(1),
+	RunE:  get,
+}
+
+// installCmd represents the install command
+var installCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the Flux packages of the project",
+	Long:  "Install the Flux packages listed in the manifest of the project in the current directory",
+	Args:  cobra.NoArgs,
+	RunE:  install,
+}
+
+var getFlags struct {
+	source string
+}
+
+func init() {
+	getCmd.Flags().StringVar(&getFlags.source, "source", "", "The git repository or HTTP registry the package is downloaded from.")
+	rootCmd.AddCommand(getCmd)
+	rootCmd.AddCommand(installCmd)
+}
+
+func get(cmd *cobra.Command, args []string) error {
+	installer, err := fluxpkg.NewInstaller()
+	if err != nil {
+		return err
+	}
+	path, version := args[0], ""
+	if i := strings.LastIndex(path, "@"); i >= 0 {
+		path, version = path[:i], path[i+1:]
+	}
+	return installer.Get(context.Background(), ".", fluxpkg.Requirement{
+		Path:    path,
+		Version: version,
+		Source:  getFlags.source,
+	})
+}
+
+func install(cmd *cobra.Command, args []string) error {
+	installer, err := fluxpkg.NewInstaller()
+	if err != nil {
+		return err
+	}
+	return installer.Install(context.Background(), ".")
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/influxdata/flux/fluxinit"
+	"github.com/spf13/cobra"
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Print the plan of a Flux script",
+	Long:  "Print the plan of a Flux script from string or file (use @ as prefix to the file) without executing it",
+	Args:  cobra.ExactArgs(1),
+	RunE:  explain,
+}
+
+var planFlags struct {
+	format     string
+	dot        bool
+	traceRules bool
+}
+
+func init() {
+	planCmd.Flags().StringVar(&planFlags.format, "format", "text", "The output format of the plan (text, json, dot).")
+	planCmd.Flags().BoolVar(&planFlags.dot, "dot", false, "Print the plan as a Graphviz DOT graph. This is the same as --format=dot.")
+	planCmd.Flags().StringSliceVar(&queryFlags.disableRules, "disable-rules", nil, "Comma-separated list of planner rules that are not applied.")
+	planCmd.Flags().BoolVar(&planFlags.traceRules, "trace-rules", false, "Write each planner rule evaluated and the plan before and after the rules that matched to stderr.")
+	rootCmd.AddCommand(planCmd)
+}
+
+func explain(cmd *cobra.Command, args []string) error {
+	fluxinit.FluxInit()
+	ctx, deps, err := injectDependencies(context.Background())
+	if err != nil {
+		return err
+	}
+	r, err := newREPL(ctx, deps)
+	if err != nil {
+		return err
+	}
+	if planFlags.traceRules {
+		// The trace is kept apart from the plan
+		// so the plan can be piped to another tool.
+		r.SetPlannerTrace(os.Stderr)
+	}
+	format := planFlags.format
+	if planFlags.dot {
+		format = "dot"
+	}
+	return r.Explain(os.Stdout, args[0], format)
+}
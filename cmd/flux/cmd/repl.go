@@ -4,7 +4,6 @@ import (
 	"context"
 
 	"github.com/influxdata/flux/fluxinit"
-	"github.com/influxdata/flux/repl"
 	"github.com/spf13/cobra"
 )
 
@@ -13,14 +12,28 @@ var replCmd = &cobra.Command{
 	Use:   "repl",
 	Short: "Launch a Flux REPL",
 	Long:  "Launch a Flux REPL (Read-Eval-Print-Loop)",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		fluxinit.FluxInit()
-		ctx, deps := injectDependencies(context.Background())
-		r := repl.New(ctx, deps)
+		ctx, deps, err := injectDependencies(context.Background())
+		if err != nil {
+			return err
+		}
+		ctx, shutdown, err := setupTracing(ctx)
+		if err != nil {
+			return err
+		}
+		defer shutdown()
+
+		r, err := newREPL(ctx, deps)
+		if err != nil {
+			return err
+		}
 		r.Run()
+		return nil
 	},
 }
 
 func init() {
+	addQueryFlags(replCmd)
 	rootCmd.AddCommand(replCmd)
 }
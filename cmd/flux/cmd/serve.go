@@ -0,0 +1,223 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/dependencies/deadline"
+	"github.com/influxdata/flux/dependencies/feature"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/dependencies/http"
+	"github.com/influxdata/flux/dependencies/influxdb"
+	"github.com/influxdata/flux/dependencies/metrics"
+	"github.com/influxdata/flux/dependencies/objectstore"
+	"github.com/influxdata/flux/dependencies/secret"
+	"github.com/influxdata/flux/dependencies/sqlpool"
+	"github.com/influxdata/flux/dependencies/tablebuffer"
+	"github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/flux/flightsql"
+	"github.com/influxdata/flux/fluxinit"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/queryservice"
+	"github.com/influxdata/flux/runtime"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve Flux queries over gRPC",
+	Long:  "Serve the Flux query service and Arrow Flight SQL over gRPC, which stream the results of the queries as Arrow record batches",
+	Args:  cobra.NoArgs,
+	RunE:  serve,
+}
+
+var serveFlags struct {
+	addr            string
+	metricsAddr     string
+	allowPrivateIPs bool
+	flightSQL       flightsql.Config
+	control         control.Config
+}
+
+func init() {
+	addQueryFlags(serveCmd)
+	serveCmd.Flags().StringVar(&serveFlags.addr, "addr", "localhost:8093", "The address the gRPC server listens on.")
+	serveCmd.Flags().StringVar(&serveFlags.metricsAddr, "metrics-addr", "", "Serve the Prometheus metrics of the queries over HTTP on /metrics at this address.")
+	serveCmd.Flags().BoolVar(&serveFlags.allowPrivateIPs, "allow-private-ips", false, "Allow the queries to connect to private IPs. They may only connect to the private IPs in the IP ranges of --allow-hosts when unset.")
+	serveCmd.Flags().IntVar(&serveFlags.control.ConcurrencyQuota, "concurrency", 0, "The number of queries that may execute at the same time. There is no limit when unset.")
+	serveCmd.Flags().IntVar(&serveFlags.control.QueueSize, "queue-size", 0, "The number of queries that may wait for another query to finish when --concurrency queries are executing. The queries above it are rejected.")
+	serveCmd.Flags().DurationVar(&serveFlags.control.QueueTimeout, "queue-timeout", 0, "The maximum amount of time a query waits in the queue. It waits until its client cancels it when unset.")
+	serveCmd.Flags().IntVar(&serveFlags.flightSQL.MaxStatements, "flightsql-max-statements", flightsql.DefaultMaxStatements, "The number of Flight SQL prepared statements and unread statements the server keeps at once.")
+	serveCmd.Flags().DurationVar(&serveFlags.flightSQL.StatementTTL, "flightsql-statement-ttl", flightsql.DefaultStatementTTL, "How long an unused Flight SQL prepared statement or unread statement is kept.")
+	serveCmd.Flags().Int64Var(&serveFlags.flightSQL.MemoryBytesQuota, "flightsql-memory-bytes", 0, "The maximum number of bytes of memory each Flight SQL statement may use. There is no limit when unset.")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func serve(cmd *cobra.Command, args []string) error {
+	fluxinit.FluxInit()
+	// the dependencies are injected once and shared by the queries.
+	ctx, err := injectServeDependencies(context.Background())
+	if err != nil {
+		return err
+	}
+	ctx, shutdown, err := setupTracing(ctx)
+	if err != nil {
+		return err
+	}
+	defer shutdown()
+
+	if serveFlags.metricsAddr != "" {
+		reg := prometheus.NewRegistry()
+		m, err := metrics.New(reg)
+		if err != nil {
+			return err
+		}
+		ctx = m.Inject(ctx)
+		metricsLis, err := net.Listen("tcp", serveFlags.metricsAddr)
+		if err != nil {
+			return err
+		}
+		mux := nethttp.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{}))
+		metricsSrv := &nethttp.Server{Handler: mux}
+		defer func() { _ = metricsSrv.Close() }()
+		go func() { _ = metricsSrv.Serve(metricsLis) }()
+		fmt.Fprintf(os.Stderr, "Serving metrics on http://%s/metrics\n", metricsLis.Addr())
+	}
+
+	lis, err := net.Listen("tcp", serveFlags.addr)
+	if err != nil {
+		return err
+	}
+	srv := grpc.NewServer()
+	queryservice.RegisterQueryServiceServer(srv, queryservice.New(ctx))
+	flightsql.Register(srv, flightsql.New(ctx, serveFlags.flightSQL))
+
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		srv.GracefulStop()
+	}()
+	fmt.Fprintf(os.Stderr, "Serving queries on %s\n", lis.Addr())
+	return srv.Serve(lis)
+}
+
+// injectServeDependencies injects the dependencies of the queries of the
+// clients of the server. Unlike the queries of the user of the flux command,
+// they may not use the credentials in the environment of the server, may only
+// read the files under --fs-root and may not connect to private IPs unless
+// --allow-private-ips is set.
+func injectServeDependencies(ctx context.Context) (context.Context, error) {
+	deps := flux.NewDefaultDependencies()
+	// the queries cannot read files without a root to confine them to.
+	if queryFlags.fsRoot != "" {
+		fs, err := filesystem.NewRootFS(queryFlags.fsRoot, queryFlags.maxFileSize)
+		if err != nil {
+			return nil, err
+		}
+		deps.Deps.FilesystemService = fs
+	} else if queryFlags.maxFileSize > 0 {
+		return nil, fmt.Errorf("--max-file-size requires --fs-root")
+	}
+
+	// the vault and aws backends are configured by the environment
+	// like for the flux command, but the env backend would let the
+	// queries read every variable of the environment.
+	if queryFlags.secrets.Backend == secret.EnvironmentBackend {
+		return nil, fmt.Errorf("the %q secrets backend would let clients read the environment of the server", secret.EnvironmentBackend)
+	}
+	secrets := queryFlags.secrets
+	secrets.UseEnvironment = true
+	ss, err := secret.New(secrets)
+	if err != nil {
+		return nil, err
+	}
+	deps.Deps.SecretService = ss
+
+	rules, err := newURLRules()
+	if err != nil {
+		return nil, err
+	}
+	if !serveFlags.allowPrivateIPs {
+		rules.DenyPrivateIPs = true
+		for pkg, r := range rules.Packages {
+			r.DenyPrivateIPs = true
+			rules.Packages[pkg] = r
+		}
+	}
+	policy, err := url.NewPolicy(rules)
+	if err != nil {
+		return nil, err
+	}
+	deps.Deps.URLValidator = policy
+	httpConfig, err := newHTTPConfig()
+	if err != nil {
+		return nil, err
+	}
+	client, err := http.NewClient(ctx, policy.ForPackage(url.HTTPPackage), httpConfig, ss)
+	if err != nil {
+		return nil, err
+	}
+	deps.Deps.HTTPClient = client
+	ctx = deps.Inject(ctx)
+
+	ip := influxdb.Dependency{
+		Provider: &influxdb.HttpProvider{
+			DefaultConfig: influxdb.Config{
+				Host: DefaultInfluxDBHost,
+			},
+		},
+	}
+	ctx = ip.Inject(ctx)
+
+	// the objects may only be read with the credentials given by the queries.
+	op := objectstore.Dependency{
+		Provider: objectstore.DefaultProvider{},
+	}
+	ctx = op.Inject(ctx)
+
+	ctx = sqlpool.Inject(ctx, sqlpool.New(queryFlags.sqlPool))
+	if queryFlags.bufferSize > 0 {
+		ctx = tablebuffer.Inject(ctx, queryFlags.bufferSize)
+	}
+	ctx = queryFlags.limits.Inject(ctx)
+	ctx = http.InjectMaxQueryBytes(ctx, queryFlags.httpMaxBytes)
+	// the queries of all clients share the concurrency and the queue.
+	if serveFlags.control.ConcurrencyQuota > 0 {
+		c, err := control.New(serveFlags.control)
+		if err != nil {
+			return nil, err
+		}
+		ctx = control.Inject(ctx, c)
+	} else if serveFlags.control.QueueSize > 0 || serveFlags.control.QueueTimeout > 0 {
+		return nil, fmt.Errorf("--queue-size and --queue-timeout require --concurrency")
+	}
+	// the clients share the compiled scripts and plans.
+	ctx = runtime.NewCompileCache(queryFlags.compileCache).Inject(ctx)
+	if queryFlags.timeout > 0 {
+		ctx = deadline.Inject(ctx, queryFlags.timeout)
+	}
+
+	flags, err := feature.ParseOverrides(queryFlags.features)
+	if err != nil {
+		return nil, err
+	}
+	ctx = feature.Override(ctx, flags)
+	if len(queryFlags.disableRules) > 0 {
+		ctx = plan.WithDisabledRules(ctx, queryFlags.disableRules...)
+	}
+	// the packages are imported again for each query,
+	// so the changes to their files are picked up.
+	return runtime.WithFluxPath(ctx, fluxPath()), nil
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/influxdata/flux/execute/executetest"
 	"github.com/influxdata/flux/execute/table"
 	"github.com/influxdata/flux/fluxinit"
+	"github.com/influxdata/flux/fluxtest"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/memory"
@@ -36,6 +37,7 @@ type testFlags struct {
 	paths         []string
 	skipTestCases []string
 	verbosity     int
+	update        bool
 }
 
 func TestCommand(setup TestSetupFunc) *cobra.Command {
@@ -53,6 +55,7 @@ func TestCommand(setup TestSetupFunc) *cobra.Command {
 	testCommand.Flags().StringSliceVar(&flags.testNames, "test", []string{}, "The name of a specific test to run.")
 	testCommand.Flags().StringSliceVar(&flags.skipTestCases, "skip", []string{}, "Comma-separated list of test cases to skip.")
 	testCommand.Flags().CountVarP(&flags.verbosity, "verbose", "v", "verbose (-v, or -vv)")
+	testCommand.Flags().BoolVar(&flags.update, "update", false, "Write the golden files of the golden tests with the results of their scripts.")
 	return testCommand
 }
 
@@ -73,6 +76,10 @@ func runFluxTests(setup TestSetupFunc, flags testFlags) {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	if err := runner.GatherGolden(flags.paths, flags.testNames, flags.update); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
 
 	executor, err := setup(context.Background())
 	if err != nil {
@@ -91,6 +98,11 @@ type Test struct {
 	name string
 	ast  *ast.Package
 	err  error
+
+	// golden is set for a golden test, which runs
+	// its script rather than the executor.
+	golden *fluxtest.Golden
+	update bool
 }
 
 // NewTest creates a new Test instance from an ast.Package.
@@ -113,9 +125,24 @@ func (t *Test) Error() error {
 
 // Run the test, saving the error to the err property of the struct.
 func (t *Test) Run(executor TestExecutor) {
+	if t.golden != nil {
+		t.err = runGolden(*t.golden, t.update)
+		return
+	}
 	t.err = executor.Run(t.ast)
 }
 
+// runGolden runs a golden test with the dependencies of the testcases,
+// or writes its golden file when update is set.
+func runGolden(g fluxtest.Golden, update bool) error {
+	ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+	ctx = testing.Inject(ctx)
+	if update {
+		return g.Update(ctx)
+	}
+	return g.Run(ctx)
+}
+
 // contains checks a slice of strings for a given string.
 func contains(names []string, name string) bool {
 	for _, n := range names {
@@ -196,6 +223,31 @@ func (t *TestRunner) Gather(roots []string, names []string) error {
 	return nil
 }
 
+// GatherGolden gathers the golden tests of the directories of roots,
+// which are the scripts with a golden file of the same name.
+// When update is set, the tests write their golden files.
+func (t *TestRunner) GatherGolden(roots []string, names []string, update bool) error {
+	for _, root := range roots {
+		if st, err := os.Stat(root); err != nil || !st.IsDir() {
+			continue
+		}
+		tests, err := fluxtest.FindGolden(root)
+		if err != nil {
+			return err
+		}
+		for i := range tests {
+			if len(names) == 0 || contains(names, tests[i].Name) {
+				t.tests = append(t.tests, &Test{
+					name:   tests[i].Name,
+					golden: &tests[i],
+					update: update,
+				})
+			}
+		}
+	}
+	return nil
+}
+
 func gatherFromTarArchive(filename string) ([]string, fs, testcase.TestModules, error) {
 	var f io.ReadCloser
 	f, err := os.Open(filename)
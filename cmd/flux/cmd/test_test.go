@@ -6,6 +6,7 @@ import (
 	"context"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -211,3 +212,36 @@ func testGatherFromArchive(t *testing.T, fn gatherFunc, filename string) {
 		}
 	}
 }
+
+func TestGatherGolden(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"a.flux",
+		"a.golden.csv",
+		"b.flux",
+		"c_test.flux",
+		"c_test.golden.csv",
+		"sub/d.flux",
+		"sub/d.golden.csv",
+	} {
+		fpath := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(fpath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fpath, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runner := NewTestRunner(NewTestReporter(0))
+	if err := runner.GatherGolden([]string{dir}, nil, false); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, test := range runner.tests {
+		names = append(names, test.Name())
+	}
+	if want := []string{"a", "sub/d"}; !cmp.Equal(want, names) {
+		t.Errorf("unexpected tests -want/+got:\n%s", cmp.Diff(want, names))
+	}
+}
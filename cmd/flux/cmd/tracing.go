@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/dependencies/tracing"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// setupTracing injects a tracer that exports the spans of each query
+// to the OTLP endpoint, if one was given. The returned function flushes
+// the spans that have not been exported yet and must be called before
+// the command exits.
+func setupTracing(ctx context.Context) (context.Context, func(), error) {
+	if queryFlags.otlpEndpoint == "" {
+		return ctx, func() {}, nil
+	}
+	exporter, err := otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(queryFlags.otlpEndpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceNameKey.String("flux"),
+		)),
+	)
+	ctx = tracing.Inject(ctx, tp.Tracer("github.com/influxdata/flux"))
+	return ctx, func() { _ = tp.Shutdown(context.Background()) }, nil
+}
@@ -179,6 +179,9 @@ func (t *TableObject) Dict() values.Dictionary {
 	panic(values.UnexpectedKind(semantic.Array, semantic.Dictionary))
 }
 
+// IsStream marks the TableObject as a values.Stream.
+func (t *TableObject) IsStream() {}
+
 func (t *TableObject) Get(i int) values.Value {
 	panic("cannot index into stream")
 }
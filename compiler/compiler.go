@@ -3,6 +3,7 @@ package compiler
 import (
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
 )
@@ -62,7 +63,7 @@ func Compile(scope Scope, f *semantic.FunctionExpression, in semantic.MonoType)
 	if err != nil {
 		return nil, errors.Wrapf(err, codes.Inherit, "cannot compile @ %v", f.Location())
 	}
-	return compiledFn{
+	return &compiledFn{
 		root:        root,
 		parentScope: scope,
 	}, nil
@@ -686,6 +687,8 @@ func compile(n semantic.Node, subst map[uint64]semantic.MonoType, scope Scope) (
 			t:      apply(subst, nil, n.TypeOf()),
 			callee: callee,
 			args:   args,
+			name:   interpreter.FunctionName(n),
+			loc:    n.Location(),
 		}, nil
 	case *semantic.FunctionExpression:
 		fnType := apply(subst, nil, n.TypeOf())
@@ -11,7 +11,6 @@ import (
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/compiler"
 	_ "github.com/influxdata/flux/fluxinit/static"
-	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/semantic/semantictest"
@@ -770,6 +769,31 @@ func TestRuntimeTypeErrors(t *testing.T) {
 			`,
 			err: `attempt to call a value of type string`,
 		},
+		{
+			name: `error in nested calls`,
+			fn: `
+				import "internal/testutil"
+				() => {
+					inner = () => {
+						n = testutil.makeAny(typ: "null")
+						return n()
+					}
+					outer = () => inner()
+					return outer()
+				}
+			`,
+			err: `error calling function "outer" @9:13-9:20: error calling function "inner" @8:20-8:27: attempt to call a null value`,
+		},
+		{
+			name: `error in builtin call`,
+			fn: `
+				() => {
+					f = () => int(v: "foo")
+					return f()
+				}
+			`,
+			err: `error calling function "f" @4:13-4:16: cannot convert string "foo" to int due to invalid syntax`,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -792,8 +816,8 @@ func TestRuntimeTypeErrors(t *testing.T) {
 				t.Fatal("expected error during evaluation, got nil")
 			}
 
-			if err := err.(*errors.Error); err.Code != codes.Invalid {
-				t.Fatalf("expected error to have code %q, but it had %q", codes.Invalid, err.Code)
+			if code := flux.ErrorCode(err); code != codes.Invalid {
+				t.Fatalf("expected error to have code %q, but it had %q", codes.Invalid, code)
 			}
 			if want, got := tc.err, err.Error(); !strings.Contains(got, want) {
 				t.Fatalf("expected evaluation error that contained %q, but it did not; error was %q", want, got)
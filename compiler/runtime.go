@@ -6,10 +6,12 @@ import (
 	"log"
 	"regexp"
 	"strings"
+	"sync"
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/feature"
 	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
@@ -28,20 +30,37 @@ type Evaluator interface {
 type compiledFn struct {
 	root        Evaluator
 	parentScope Scope
+
+	// The evaluator is chosen on the first call, when the
+	// feature flags in the context are known.
+	once sync.Once
+	eval Evaluator
 }
 
 // Type returns the return type of the compiled function.
-func (c compiledFn) Type() semantic.MonoType {
+func (c *compiledFn) Type() semantic.MonoType {
 	return c.root.Type()
 }
 
-func (c compiledFn) Eval(ctx context.Context, input values.Object) (values.Value, error) {
+func (c *compiledFn) Eval(ctx context.Context, input values.Object) (values.Value, error) {
+	c.once.Do(func() {
+		c.eval = c.root
+		if !feature.BytecodeCompiler().Enabled(ctx) {
+			return
+		}
+		// The tree walker remains the fallback for
+		// functions the VM is unable to compile.
+		if p, err := compileProgram(c.root); err == nil {
+			c.eval = p
+		}
+	})
+
 	inputScope := nestScope(c.parentScope)
 	input.Range(func(k string, v values.Value) {
 		inputScope.Set(k, v)
 	})
 
-	return eval(ctx, c.root, inputScope)
+	return eval(ctx, c.eval, inputScope)
 }
 
 type Scope interface {
@@ -199,15 +218,9 @@ func (e *objEvaluator) Eval(ctx context.Context, scope Scope) (values.Value, err
 			if err != nil {
 				return err
 			}
-			if with.IsNull() {
-				return errors.New(codes.Invalid, `null value on left hand side of "with" in record literal`)
-			}
-			if typ := with.Type().Nature(); typ != semantic.Object {
-				return errors.Newf(codes.Invalid, `value on left hand side of "with" in record literal has type %s; expected record`, typ)
+			if err := extendRecord(set, with); err != nil {
+				return err
 			}
-			with.Object().Range(func(name string, v values.Value) {
-				set(name, v)
-			})
 		}
 
 		for k, node := range e.properties {
@@ -221,6 +234,21 @@ func (e *objEvaluator) Eval(ctx context.Context, scope Scope) (values.Value, err
 	})
 }
 
+// extendRecord sets the properties of the record on the
+// left hand side of "with" in a record literal.
+func extendRecord(set values.ObjectSetter, with values.Value) error {
+	if with.IsNull() {
+		return errors.New(codes.Invalid, `null value on left hand side of "with" in record literal`)
+	}
+	if typ := with.Type().Nature(); typ != semantic.Object {
+		return errors.Newf(codes.Invalid, `value on left hand side of "with" in record literal has type %s; expected record`, typ)
+	}
+	with.Object().Range(func(name string, v values.Value) {
+		set(name, v)
+	})
+	return nil
+}
+
 type arrayEvaluator struct {
 	t     semantic.MonoType
 	array []Evaluator
@@ -289,31 +317,45 @@ func (e *logicalEvaluator) Eval(ctx context.Context, scope Scope) (values.Value,
 	if err != nil {
 		return nil, err
 	}
+	if v, done, err := logicalShort(e.operator, l); err != nil || done {
+		return v, err
+	}
+
+	r, err := e.right.Eval(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	return logicalCheckRight(e.operator, r)
+}
+
+// logicalShort checks the value of the left operand of the operator and
+// reports whether it decides the result without evaluating the right operand.
+func logicalShort(operator ast.LogicalOperatorKind, l values.Value) (values.Value, bool, error) {
 	if typ := l.Type().Nature(); !l.IsNull() && typ != semantic.Bool {
-		return nil, errors.Newf(codes.Invalid, "cannot use operand of type %s with logical %s; exected boolean", typ, e.operator)
+		return nil, false, errors.Newf(codes.Invalid, "cannot use operand of type %s with logical %s; exected boolean", typ, operator)
 	}
 
-	switch e.operator {
+	switch operator {
 	case ast.AndOperator:
 		if l.IsNull() || !l.Bool() {
-			return values.NewBool(false), nil
+			return values.NewBool(false), true, nil
 		}
 	case ast.OrOperator:
 		if !l.IsNull() && l.Bool() {
-			return values.NewBool(true), nil
+			return values.NewBool(true), true, nil
 		}
 	default:
-		panic(errors.Newf(codes.Internal, "unknown logical operator %v", e.operator))
+		panic(errors.Newf(codes.Internal, "unknown logical operator %v", operator))
 	}
+	return nil, false, nil
+}
 
-	r, err := e.right.Eval(ctx, scope)
-	if err != nil {
-		return nil, err
-	}
+// logicalCheckRight checks the value of the right operand
+// of the operator, which is the result.
+func logicalCheckRight(operator ast.LogicalOperatorKind, r values.Value) (values.Value, error) {
 	if typ := r.Type().Nature(); !r.IsNull() && typ != semantic.Bool {
-		return nil, errors.Newf(codes.Invalid, "cannot use operand of type %s with logical %s; expected boolean", typ, e.operator)
+		return nil, errors.Newf(codes.Invalid, "cannot use operand of type %s with logical %s; expected boolean", typ, operator)
 	}
-
 	return r, nil
 }
 
@@ -332,17 +374,26 @@ func (e *conditionalEvaluator) Eval(ctx context.Context, scope Scope) (values.Va
 	if err != nil {
 		return nil, err
 	}
-	if typ := t.Type().Nature(); !t.IsNull() && typ != semantic.Bool {
-		return nil, errors.Newf(codes.Invalid, "cannot use test of type %s in conditional expression; expected boolean", typ)
+	ok, err := conditionTrue(t)
+	if err != nil {
+		return nil, err
 	}
-
-	if t.IsNull() || !t.Bool() {
+	if !ok {
 		return eval(ctx, e.alternate, scope)
 	} else {
 		return eval(ctx, e.consequent, scope)
 	}
 }
 
+// conditionTrue reports whether the value of the test of
+// a conditional expression selects the consequent.
+func conditionTrue(t values.Value) (bool, error) {
+	if typ := t.Type().Nature(); !t.IsNull() && typ != semantic.Bool {
+		return false, errors.Newf(codes.Invalid, "cannot use test of type %s in conditional expression; expected boolean", typ)
+	}
+	return !t.IsNull() && t.Bool(), nil
+}
+
 type binaryEvaluator struct {
 	t           semantic.MonoType
 	left, right Evaluator
@@ -380,45 +431,44 @@ func (e *unaryEvaluator) Eval(ctx context.Context, scope Scope) (values.Value, e
 	if err != nil {
 		return nil, err
 	}
+	return unaryOp(e.op, e.t, v)
+}
 
-	ret, err := func(v values.Value) (values.Value, error) {
-		if e.op == ast.ExistsOperator {
-			return values.NewBool(!v.IsNull()), nil
-		}
-
-		// If the value is null, return it immediately.
-		if v.IsNull() {
-			return v, nil
-		}
+// unaryOp applies the operator to the value of the operand,
+// whose result has type t.
+func unaryOp(op ast.OperatorKind, t semantic.MonoType, v values.Value) (values.Value, error) {
+	if op == ast.ExistsOperator {
+		return values.NewBool(!v.IsNull()), nil
+	}
 
-		switch e.op {
-		case ast.AdditionOperator:
-			// Do nothing.
-			return v, nil
-		case ast.SubtractionOperator, ast.NotOperator:
-			// Fallthrough to below.
-		default:
-			return nil, errors.Newf(codes.Internal, "unknown unary operator: %s", e.op)
-		}
+	// If the value is null, return it immediately.
+	if v.IsNull() {
+		return v, nil
+	}
 
-		// The subtraction operator falls through to here.
-		switch v.Type().Nature() {
-		case semantic.Int:
-			return values.NewInt(-v.Int()), nil
-		case semantic.Float:
-			return values.NewFloat(-v.Float()), nil
-		case semantic.Bool:
-			return values.NewBool(!v.Bool()), nil
-		case semantic.Duration:
-			return values.NewDuration(v.Duration().Mul(-1)), nil
-		default:
-			panic(values.UnexpectedKind(e.t.Nature(), v.Type().Nature()))
-		}
-	}(v)
-	if err != nil {
-		return nil, err
+	switch op {
+	case ast.AdditionOperator:
+		// Do nothing.
+		return v, nil
+	case ast.SubtractionOperator, ast.NotOperator:
+		// Fallthrough to below.
+	default:
+		return nil, errors.Newf(codes.Internal, "unknown unary operator: %s", op)
+	}
+
+	// The subtraction operator falls through to here.
+	switch v.Type().Nature() {
+	case semantic.Int:
+		return values.NewInt(-v.Int()), nil
+	case semantic.Float:
+		return values.NewFloat(-v.Float()), nil
+	case semantic.Bool:
+		return values.NewBool(!v.Bool()), nil
+	case semantic.Duration:
+		return values.NewDuration(v.Duration().Mul(-1)), nil
+	default:
+		panic(values.UnexpectedKind(t.Nature(), v.Type().Nature()))
 	}
-	return ret, nil
 }
 
 type integerEvaluator struct {
@@ -547,6 +597,12 @@ func (e *memberEvaluator) Eval(ctx context.Context, scope Scope) (values.Value,
 	if err != nil {
 		return nil, err
 	}
+	return getMember(o, e.property, e.nullable, e.t)
+}
+
+// getMember returns the property of the value of the object. A property
+// of type t that is not in the record is an error unless it is nullable.
+func getMember(o values.Value, property string, nullable bool, t semantic.MonoType) (values.Value, error) {
 	if o.IsNull() {
 		return nil, errors.Newf(codes.Invalid, "cannot access property of a null value; expected record")
 	}
@@ -554,9 +610,9 @@ func (e *memberEvaluator) Eval(ctx context.Context, scope Scope) (values.Value,
 		return nil, errors.Newf(codes.Invalid, "cannot access property of a value with type %s; expected record", typ)
 	}
 
-	v, ok := o.Object().Get(e.property)
-	if !ok && !e.nullable {
-		return nil, errors.Newf(codes.Invalid, "member %q with type %s is not in the record", e.property, e.t.Nature())
+	v, ok := o.Object().Get(property)
+	if !ok && !nullable {
+		return nil, errors.Newf(codes.Invalid, "member %q with type %s is not in the record", property, t.Nature())
 	}
 	return v, nil
 }
@@ -604,6 +660,11 @@ type callEvaluator struct {
 	t      semantic.MonoType
 	callee Evaluator
 	args   Evaluator
+
+	// name and loc identify the call in errors so that an error
+	// inside nested calls reports each call that led to it.
+	name string
+	loc  ast.SourceLocation
 }
 
 func (e *callEvaluator) Type() semantic.MonoType {
@@ -626,7 +687,18 @@ func (e *callEvaluator) Eval(ctx context.Context, scope Scope) (values.Value, er
 		return nil, errors.Newf(codes.Invalid, "attempt to call a value of type %s; expected function", typ)
 	}
 
-	return f.Function().Call(ctx, args.Object())
+	fn := f.Function()
+	v, err := fn.Call(ctx, args.Object())
+	if err != nil {
+		// Only calls to user-defined functions are reported, the errors
+		// of builtins already describe the call. Underscore functions
+		// are internal calls, which the interpreter also leaves out of the error.
+		if _, ok := fn.(*functionValue); ok && !strings.HasPrefix(e.name, "_") {
+			err = errors.Wrapf(err, codes.Inherit, "error calling function %q @%s", e.name, e.loc)
+		}
+		return nil, err
+	}
+	return v, nil
 }
 
 type functionEvaluator struct {
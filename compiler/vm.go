@@ -0,0 +1,397 @@
+package compiler
+
+import (
+	"context"
+	"sort"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// opcode is an operation of the bytecode VM.
+//
+// The VM is a stack machine. Each instruction pops its operands
+// from the stack and pushes its result.
+type opcode uint8
+
+const (
+	// opConst pushes the constant at index a.
+	opConst opcode = iota
+	// opLoad pushes the value of the name at index a from the scope.
+	opLoad
+	// opStore sets the name at index a in the scope to the
+	// value on top of the stack, leaving it on the stack.
+	opStore
+	// opPop discards the value on top of the stack.
+	opPop
+	// opMember pops a record and pushes its property
+	// described by the member at index a.
+	opMember
+	// opLoadMember pushes the property described by the member at
+	// index b of the record of the name at index a from the scope.
+	// It is opLoad followed by opMember, as in r._value.
+	opLoadMember
+	// opBinary pops the right and left operands and pushes
+	// the result of the binary function at index a.
+	opBinary
+	// opUnary pops the operand and pushes the result
+	// of the unary operator at index a.
+	opUnary
+	// opLogical pops the left operand of the logical operator a.
+	// If the left operand decides the result, it pushes
+	// the result and jumps to b.
+	opLogical
+	// opLogicalRight checks the right operand of the logical
+	// operator a, which is left on the stack as the result.
+	opLogicalRight
+	// opJumpIfFalse pops the test of a conditional
+	// expression and jumps to a if it is not true.
+	opJumpIfFalse
+	// opJump jumps to a.
+	opJump
+	// opObject pops the values of the properties of the record
+	// at index a, and the record it extends if any, and pushes
+	// the new record.
+	opObject
+	// opEval pushes the result of the evaluator at index a,
+	// for the expressions the VM evaluates with the tree walker.
+	opEval
+)
+
+type instruction struct {
+	op   opcode
+	a, b int32
+}
+
+// member describes a property read by opMember and opLoadMember.
+type member struct {
+	property string
+	nullable bool
+	t        semantic.MonoType
+}
+
+// unary describes an operator applied by opUnary.
+type unary struct {
+	op ast.OperatorKind
+	t  semantic.MonoType
+}
+
+// objectLayout describes a record built by opObject.
+// The values of the properties are on the stack in the order
+// of the keys, after the record it extends if with is set.
+type objectLayout struct {
+	with bool
+	keys []string
+}
+
+// program is a compiled function body that is evaluated by the
+// bytecode VM instead of walking the tree of evaluators.
+//
+// It implements Evaluator so that it can be used wherever the root
+// evaluator of a function is used.
+type program struct {
+	t        semantic.MonoType
+	code     []instruction
+	maxStack int
+
+	consts   []values.Value
+	names    []string
+	members  []member
+	binaries []values.BinaryFunction
+	unaries  []unary
+	objects  []objectLayout
+	evals    []Evaluator
+}
+
+// compileProgram compiles the tree of evaluators into a program.
+func compileProgram(root Evaluator) (*program, error) {
+	c := &programCompiler{
+		p: &program{t: root.Type()},
+	}
+	if err := c.compile(root); err != nil {
+		return nil, err
+	}
+	return c.p, nil
+}
+
+type programCompiler struct {
+	p     *program
+	depth int
+}
+
+func (c *programCompiler) emit(op opcode, a int32, push int) int {
+	return c.emit2(op, a, 0, push)
+}
+
+func (c *programCompiler) emit2(op opcode, a, b int32, push int) int {
+	c.p.code = append(c.p.code, instruction{op: op, a: a, b: b})
+	c.depth += push
+	if c.depth > c.p.maxStack {
+		c.p.maxStack = c.depth
+	}
+	return len(c.p.code) - 1
+}
+
+// patch sets the target of the jump at pc to the next instruction.
+func (c *programCompiler) patch(pc int) {
+	target := int32(len(c.p.code))
+	switch c.p.code[pc].op {
+	case opLogical:
+		c.p.code[pc].b = target
+	default:
+		c.p.code[pc].a = target
+	}
+}
+
+func (c *programCompiler) name(name string) int32 {
+	for i, n := range c.p.names {
+		if n == name {
+			return int32(i)
+		}
+	}
+	c.p.names = append(c.p.names, name)
+	return int32(len(c.p.names) - 1)
+}
+
+func (c *programCompiler) constant(e Evaluator) error {
+	// Literals do not depend on the scope or the
+	// context so they are evaluated once here.
+	v, err := e.Eval(context.Background(), nil)
+	if err != nil {
+		return err
+	}
+	c.p.consts = append(c.p.consts, v)
+	c.emit(opConst, int32(len(c.p.consts)-1), 1)
+	return nil
+}
+
+func (c *programCompiler) compile(e Evaluator) error {
+	switch e := e.(type) {
+	case *blockEvaluator:
+		for i, s := range e.body {
+			if i > 0 {
+				c.emit(opPop, 0, -1)
+			}
+			if err := c.compile(s); err != nil {
+				return err
+			}
+		}
+	case returnEvaluator:
+		return c.compile(e.Evaluator)
+	case *declarationEvaluator:
+		if err := c.compile(e.init); err != nil {
+			return err
+		}
+		c.emit(opStore, c.name(e.id), 0)
+	case *booleanEvaluator, *integerEvaluator, *unsignedIntegerEvaluator,
+		*floatEvaluator, *stringEvaluator, *regexpEvaluator,
+		*timeEvaluator, *durationEvaluator:
+		return c.constant(e)
+	case *identifierEvaluator:
+		c.emit(opLoad, c.name(e.name), 1)
+	case *memberEvaluator:
+		c.p.members = append(c.p.members, member{
+			property: e.property,
+			nullable: e.nullable,
+			t:        e.t,
+		})
+		idx := int32(len(c.p.members) - 1)
+		if id, ok := e.object.(*identifierEvaluator); ok {
+			c.emit2(opLoadMember, c.name(id.name), idx, 1)
+			return nil
+		}
+		if err := c.compile(e.object); err != nil {
+			return err
+		}
+		c.emit(opMember, idx, 0)
+	case *binaryEvaluator:
+		if err := c.compile(e.left); err != nil {
+			return err
+		}
+		if err := c.compile(e.right); err != nil {
+			return err
+		}
+		c.p.binaries = append(c.p.binaries, e.f)
+		c.emit(opBinary, int32(len(c.p.binaries)-1), -1)
+	case *unaryEvaluator:
+		if err := c.compile(e.node); err != nil {
+			return err
+		}
+		c.p.unaries = append(c.p.unaries, unary{op: e.op, t: e.t})
+		c.emit(opUnary, int32(len(c.p.unaries)-1), 0)
+	case *logicalEvaluator:
+		if err := c.compile(e.left); err != nil {
+			return err
+		}
+		// The left operand is popped and, when it decides
+		// the result, the result is pushed in its place.
+		short := c.emit(opLogical, int32(e.operator), -1)
+		if err := c.compile(e.right); err != nil {
+			return err
+		}
+		c.emit(opLogicalRight, int32(e.operator), 0)
+		c.patch(short)
+	case *conditionalEvaluator:
+		if err := c.compile(e.test); err != nil {
+			return err
+		}
+		test := c.emit(opJumpIfFalse, 0, -1)
+		if err := c.compile(e.consequent); err != nil {
+			return err
+		}
+		end := c.emit(opJump, 0, 0)
+		c.patch(test)
+		// Only one of the branches pushes its result.
+		c.depth--
+		if err := c.compile(e.alternate); err != nil {
+			return err
+		}
+		c.patch(end)
+	case *objEvaluator:
+		layout := objectLayout{
+			with: e.with != nil,
+			keys: make([]string, 0, len(e.properties)),
+		}
+		for k := range e.properties {
+			layout.keys = append(layout.keys, k)
+		}
+		sort.Strings(layout.keys)
+		if layout.with {
+			if err := c.compile(e.with); err != nil {
+				return err
+			}
+		}
+		for _, k := range layout.keys {
+			if err := c.compile(e.properties[k]); err != nil {
+				return err
+			}
+		}
+		c.p.objects = append(c.p.objects, layout)
+		n := len(layout.keys)
+		if layout.with {
+			n++
+		}
+		c.emit(opObject, int32(len(c.p.objects)-1), 1-n)
+	default:
+		// Calls, functions, strings, arrays, dictionaries and
+		// index expressions are evaluated by the tree walker.
+		c.p.evals = append(c.p.evals, e)
+		c.emit(opEval, int32(len(c.p.evals)-1), 1)
+	}
+	return nil
+}
+
+func (p *program) Type() semantic.MonoType {
+	return p.t
+}
+
+func (p *program) Eval(ctx context.Context, scope Scope) (values.Value, error) {
+	stack := make([]values.Value, 0, p.maxStack)
+	pop := func() values.Value {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v
+	}
+	for pc := 0; pc < len(p.code); pc++ {
+		ins := p.code[pc]
+		switch ins.op {
+		case opConst:
+			stack = append(stack, p.consts[ins.a])
+		case opLoad:
+			stack = append(stack, scope.Get(p.names[ins.a]))
+		case opStore:
+			scope.Set(p.names[ins.a], stack[len(stack)-1])
+		case opPop:
+			pop()
+		case opMember:
+			m := &p.members[ins.a]
+			v, err := getMember(pop(), m.property, m.nullable, m.t)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+		case opLoadMember:
+			m := &p.members[ins.b]
+			v, err := getMember(scope.Get(p.names[ins.a]), m.property, m.nullable, m.t)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+		case opBinary:
+			r := pop()
+			l := pop()
+			v, err := p.binaries[ins.a](l, r)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+		case opUnary:
+			u := &p.unaries[ins.a]
+			v, err := unaryOp(u.op, u.t, pop())
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+		case opLogical:
+			v, done, err := logicalShort(ast.LogicalOperatorKind(ins.a), pop())
+			if err != nil {
+				return nil, err
+			} else if done {
+				stack = append(stack, v)
+				pc = int(ins.b) - 1
+			}
+		case opLogicalRight:
+			if _, err := logicalCheckRight(ast.LogicalOperatorKind(ins.a), stack[len(stack)-1]); err != nil {
+				return nil, err
+			}
+		case opJumpIfFalse:
+			ok, err := conditionTrue(pop())
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				pc = int(ins.a) - 1
+			}
+		case opJump:
+			pc = int(ins.a) - 1
+		case opObject:
+			layout := p.objects[ins.a]
+			n := len(layout.keys)
+			vs := stack[len(stack)-n:]
+			stack = stack[:len(stack)-n]
+			var with values.Value
+			if layout.with {
+				with = pop()
+			}
+			v, err := values.BuildObject(func(set values.ObjectSetter) error {
+				if with != nil {
+					if err := extendRecord(set, with); err != nil {
+						return err
+					}
+				}
+				for i, k := range layout.keys {
+					set(k, vs[i])
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+		case opEval:
+			v, err := p.evals[ins.a].Eval(ctx, scope)
+			if err != nil {
+				return nil, err
+			}
+			stack = append(stack, v)
+		default:
+			panic(errors.Newf(codes.Internal, "unknown opcode %d", ins.op))
+		}
+	}
+	if len(stack) != 1 {
+		return nil, errors.Newf(codes.Internal, "program left %d values on the stack; expected 1", len(stack))
+	}
+	return stack[0], nil
+}
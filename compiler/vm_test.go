@@ -0,0 +1,242 @@
+package compiler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+func binary(t testing.TB, op ast.OperatorKind, typ semantic.Nature, left, right Evaluator) Evaluator {
+	f, err := values.LookupBinaryFunction(values.BinaryFuncSignature{
+		Operator: op,
+		Left:     typ,
+		Right:    typ,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &binaryEvaluator{t: left.Type(), left: left, right: right, f: f}
+}
+
+func rowMember(name string, t semantic.MonoType) *memberEvaluator {
+	return &memberEvaluator{
+		t:        t,
+		object:   &identifierEvaluator{t: semantic.BasicInt, name: "r"},
+		property: name,
+		nullable: true,
+	}
+}
+
+// TestProgram checks that the bytecode VM
+// evaluates like the tree-walking evaluator.
+func TestProgram(t *testing.T) {
+	value := rowMember("_value", semantic.BasicFloat)
+	tag := rowMember("tag", semantic.BasicString)
+
+	testCases := []struct {
+		name    string
+		root    func(t *testing.T) Evaluator
+		input   map[string]values.Value
+		wantErr bool
+	}{
+		{
+			name: "arithmetic",
+			root: func(t *testing.T) Evaluator {
+				mul := binary(t, ast.MultiplicationOperator, semantic.Float, value, &floatEvaluator{f: 2})
+				return binary(t, ast.AdditionOperator, semantic.Float, mul, &floatEvaluator{f: 1})
+			},
+			input: map[string]values.Value{"_value": values.NewFloat(2)},
+		},
+		{
+			name: "conditional",
+			root: func(t *testing.T) Evaluator {
+				return &conditionalEvaluator{
+					test:       binary(t, ast.GreaterThanOperator, semantic.Float, value, &floatEvaluator{f: 1}),
+					consequent: &stringEvaluator{s: "high"},
+					alternate:  &stringEvaluator{s: "low"},
+				}
+			},
+			input: map[string]values.Value{"_value": values.NewFloat(0.5)},
+		},
+		{
+			name: "logical short circuit",
+			root: func(t *testing.T) Evaluator {
+				return &logicalEvaluator{
+					operator: ast.OrOperator,
+					left:     binary(t, ast.EqualOperator, semantic.String, tag, &stringEvaluator{s: "a"}),
+					// The right operand is not a boolean,
+					// which is an error if it is evaluated.
+					right: value,
+				}
+			},
+			input: map[string]values.Value{"tag": values.NewString("a"), "_value": values.NewFloat(1)},
+		},
+		{
+			name: "logical type error",
+			root: func(t *testing.T) Evaluator {
+				return &logicalEvaluator{
+					operator: ast.AndOperator,
+					left:     binary(t, ast.EqualOperator, semantic.String, tag, &stringEvaluator{s: "a"}),
+					right:    value,
+				}
+			},
+			input:   map[string]values.Value{"tag": values.NewString("a"), "_value": values.NewFloat(1)},
+			wantErr: true,
+		},
+		{
+			name: "block with record",
+			root: func(t *testing.T) Evaluator {
+				x := &identifierEvaluator{t: semantic.BasicFloat, name: "x"}
+				return &blockEvaluator{
+					body: []Evaluator{
+						&declarationEvaluator{
+							t:    semantic.BasicFloat,
+							id:   "x",
+							init: binary(t, ast.MultiplicationOperator, semantic.Float, value, &floatEvaluator{f: 2}),
+						},
+						returnEvaluator{Evaluator: &objEvaluator{
+							with: &identifierEvaluator{t: semantic.BasicInt, name: "r"},
+							properties: map[string]Evaluator{
+								"x": x,
+								"y": &unaryEvaluator{
+									op:   ast.ExistsOperator,
+									node: rowMember("missing", semantic.BasicString),
+								},
+								"z": &unaryEvaluator{
+									op:   ast.NotOperator,
+									node: &booleanEvaluator{b: false},
+								},
+							},
+						}},
+					},
+				}
+			},
+			input: map[string]values.Value{"tag": values.NewString("a"), "_value": values.NewFloat(3)},
+		},
+		{
+			name: "negate",
+			root: func(t *testing.T) Evaluator {
+				return &unaryEvaluator{
+					t:    semantic.BasicFloat,
+					op:   ast.SubtractionOperator,
+					node: value,
+				}
+			},
+			input: map[string]values.Value{"_value": values.NewFloat(2)},
+		},
+		{
+			name: "member of null",
+			root: func(t *testing.T) Evaluator {
+				return &memberEvaluator{
+					t:        semantic.BasicFloat,
+					object:   rowMember("missing", semantic.BasicInt),
+					property: "_value",
+				}
+			},
+			input:   map[string]values.Value{"_value": values.NewFloat(1)},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			root := tc.root(t)
+			p, err := compileProgram(root)
+			if err != nil {
+				t.Fatalf("unexpected compile error: %s", err)
+			}
+
+			newScope := func() Scope {
+				scope := NewScope()
+				scope.Set("r", values.NewObjectWithValues(tc.input))
+				return scope
+			}
+			want, wantErr := root.Eval(context.Background(), newScope())
+			got, gotErr := p.Eval(context.Background(), newScope())
+			if tc.wantErr {
+				if wantErr == nil || gotErr == nil {
+					t.Fatalf("expected errors, got %v and %v", wantErr, gotErr)
+				}
+				if wantErr.Error() != gotErr.Error() {
+					t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", wantErr, gotErr)
+				}
+				return
+			}
+			if wantErr != nil || gotErr != nil {
+				t.Fatalf("unexpected errors: %v and %v", wantErr, gotErr)
+			}
+			if !want.Equal(got) {
+				t.Errorf("unexpected value -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
+
+// BenchmarkProgram compares the bytecode VM with the tree-walking
+// evaluator on the bodies of typical filter and map functions.
+func BenchmarkProgram(b *testing.B) {
+	value := rowMember("_value", semantic.BasicFloat)
+	tag := rowMember("tag", semantic.BasicString)
+	r := values.NewObjectWithValues(map[string]values.Value{
+		"_time":  values.NewTime(values.ConvertTime(time.Unix(0, 0))),
+		"_value": values.NewFloat(12),
+		"tag":    values.NewString("a"),
+	})
+
+	for _, bm := range []struct {
+		name string
+		root Evaluator
+	}{
+		{
+			// (r) => r._value > 10.0 and r.tag == "a"
+			name: "filter",
+			root: &logicalEvaluator{
+				operator: ast.AndOperator,
+				left:     binary(b, ast.GreaterThanOperator, semantic.Float, value, &floatEvaluator{f: 10}),
+				right:    binary(b, ast.EqualOperator, semantic.String, tag, &stringEvaluator{s: "a"}),
+			},
+		},
+		{
+			// (r) => ({r with _value: r._value * 2.0 + 1.0, high: r._value > 10.0})
+			name: "map",
+			root: &objEvaluator{
+				with: &identifierEvaluator{t: semantic.BasicInt, name: "r"},
+				properties: map[string]Evaluator{
+					"_value": binary(b, ast.AdditionOperator, semantic.Float,
+						binary(b, ast.MultiplicationOperator, semantic.Float, value, &floatEvaluator{f: 2}),
+						&floatEvaluator{f: 1},
+					),
+					"high": binary(b, ast.GreaterThanOperator, semantic.Float, value, &floatEvaluator{f: 10}),
+				},
+			},
+		},
+	} {
+		p, err := compileProgram(bm.root)
+		if err != nil {
+			b.Fatal(err)
+		}
+		for _, e := range []struct {
+			name string
+			eval Evaluator
+		}{
+			{name: "tree walker", eval: bm.root},
+			{name: "vm", eval: p},
+		} {
+			b.Run(bm.name+"/"+e.name, func(b *testing.B) {
+				scope := NewScope()
+				scope.Set("r", r)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					if _, err := e.eval.Eval(context.Background(), scope); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,223 @@
+// Package control provides admission control for programs
+// that are executed by the same process.
+//
+// A Controller limits the number of programs that execute at
+// the same time. Programs that cannot be executed immediately
+// wait in a bounded queue and are admitted in the order they
+// arrived. A program is rejected if the queue is full or it
+// waits longer than the configured queue timeout.
+package control
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+)
+
+type key int
+
+const controllerKey key = iota
+
+// Config configures a Controller.
+type Config struct {
+	// ConcurrencyQuota is the number of programs that may
+	// execute at the same time.
+	ConcurrencyQuota int
+
+	// QueueSize is the number of programs that may wait
+	// for execution. Programs are rejected when the queue
+	// is full. A zero value disables queueing.
+	QueueSize int
+
+	// QueueTimeout is the maximum amount of time a program
+	// may wait in the queue. A zero value waits until the
+	// program is admitted or its context is canceled.
+	QueueTimeout time.Duration
+}
+
+// Validate reports an error if the configuration is invalid.
+func (c Config) Validate() error {
+	if c.ConcurrencyQuota <= 0 {
+		return errors.New(codes.Invalid, "ConcurrencyQuota must be positive")
+	}
+	if c.QueueSize < 0 {
+		return errors.New(codes.Invalid, "QueueSize must not be negative")
+	}
+	if c.QueueTimeout < 0 {
+		return errors.New(codes.Invalid, "QueueTimeout must not be negative")
+	}
+	return nil
+}
+
+// Stats reports the state of a Controller.
+type Stats struct {
+	// Executing is the number of programs currently executing.
+	Executing int
+	// Queued is the number of programs currently waiting to execute.
+	Queued int
+	// Admitted is the total number of programs that were admitted.
+	Admitted int64
+	// Rejected is the total number of programs that were
+	// rejected because the queue was full.
+	Rejected int64
+	// TimedOut is the total number of programs that were
+	// rejected because they waited too long in the queue.
+	TimedOut int64
+	// Canceled is the total number of programs whose context
+	// was canceled while they waited in the queue.
+	Canceled int64
+}
+
+// Controller limits the number of programs executing at the same time.
+type Controller struct {
+	config Config
+	slots  chan struct{}
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// New creates a Controller with the given configuration.
+func New(config Config) (*Controller, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &Controller{
+		config: config,
+		slots:  make(chan struct{}, config.ConcurrencyQuota),
+	}, nil
+}
+
+// Query waits until the program is admitted and then starts it.
+// The program counts against the concurrency quota until
+// Done is called on the returned query.
+func (c *Controller) Query(ctx context.Context, p flux.Program, alloc *memory.Allocator) (flux.Query, error) {
+	start := time.Now()
+	if err := c.admit(ctx); err != nil {
+		return nil, err
+	}
+	queueDuration := time.Since(start)
+
+	q, err := p.Start(ctx, alloc)
+	if err != nil {
+		c.release()
+		return nil, err
+	}
+	return &query{
+		Query:         q,
+		c:             c,
+		queueDuration: queueDuration,
+	}, nil
+}
+
+// Stats returns the current statistics for the Controller.
+func (c *Controller) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+func (c *Controller) admit(ctx context.Context) error {
+	select {
+	case c.slots <- struct{}{}:
+		c.admitted()
+		return nil
+	default:
+	}
+
+	c.mu.Lock()
+	if c.stats.Queued >= c.config.QueueSize {
+		c.stats.Rejected++
+		c.mu.Unlock()
+		return errors.New(codes.ResourceExhausted, "query queue is full")
+	}
+	c.stats.Queued++
+	c.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if c.config.QueueTimeout > 0 {
+		timer := time.NewTimer(c.config.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+		c.mu.Lock()
+		c.stats.Queued--
+		c.mu.Unlock()
+		c.admitted()
+		return nil
+	case <-timeout:
+		c.mu.Lock()
+		c.stats.Queued--
+		c.stats.TimedOut++
+		c.mu.Unlock()
+		return errors.Newf(codes.DeadlineExceeded, "query timed out after waiting %s in the queue", c.config.QueueTimeout)
+	case <-ctx.Done():
+		c.mu.Lock()
+		c.stats.Queued--
+		c.stats.Canceled++
+		c.mu.Unlock()
+		return errors.Wrap(ctx.Err(), codes.Canceled, "query canceled while waiting in the queue")
+	}
+}
+
+func (c *Controller) admitted() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stats.Executing++
+	c.stats.Admitted++
+}
+
+func (c *Controller) release() {
+	c.mu.Lock()
+	c.stats.Executing--
+	c.mu.Unlock()
+	<-c.slots
+}
+
+// query releases its slot in the Controller when it is done.
+type query struct {
+	flux.Query
+	c             *Controller
+	queueDuration time.Duration
+	once          sync.Once
+}
+
+func (q *query) Done() {
+	q.Query.Done()
+	q.once.Do(q.c.release)
+}
+
+func (q *query) Statistics() flux.Statistics {
+	stats := q.Query.Statistics()
+	stats.QueueDuration += q.queueDuration
+	return stats
+}
+
+// Inject will inject the controller into the dependency chain.
+func Inject(ctx context.Context, c *Controller) context.Context {
+	return context.WithValue(ctx, controllerKey, c)
+}
+
+// Get returns the controller of the context,
+// or nil if no controller has been injected.
+func Get(ctx context.Context) *Controller {
+	c, _ := ctx.Value(controllerKey).(*Controller)
+	return c
+}
+
+// Start starts a program through the controller of the context.
+// Without a controller, the program is started immediately.
+func Start(ctx context.Context, p flux.Program, alloc *memory.Allocator) (flux.Query, error) {
+	if c := Get(ctx); c != nil {
+		return c.Query(ctx, p, alloc)
+	}
+	return p.Start(ctx, alloc)
+}
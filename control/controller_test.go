@@ -0,0 +1,232 @@
+package control_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+)
+
+type program struct {
+	err error
+}
+
+func (p *program) Start(ctx context.Context, alloc *memory.Allocator) (flux.Query, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	results := make(chan flux.Result)
+	close(results)
+	return &query{results: results}, nil
+}
+
+type query struct {
+	results chan flux.Result
+}
+
+func (q *query) Results() <-chan flux.Result                   { return q.results }
+func (q *query) Done()                                         {}
+func (q *query) Cancel()                                       {}
+func (q *query) Err() error                                    { return nil }
+func (q *query) Statistics() flux.Statistics                   { return flux.Statistics{} }
+func (q *query) ProfilerResults() (flux.ResultIterator, error) { return nil, nil }
+
+func TestConfig_Validate(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		config  control.Config
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			config: control.Config{ConcurrencyQuota: 1, QueueSize: 1, QueueTimeout: time.Second},
+		},
+		{
+			name:    "zero concurrency",
+			config:  control.Config{},
+			wantErr: true,
+		},
+		{
+			name:    "negative queue size",
+			config:  control.Config{ConcurrencyQuota: 1, QueueSize: -1},
+			wantErr: true,
+		},
+		{
+			name:    "negative queue timeout",
+			config:  control.Config{ConcurrencyQuota: 1, QueueTimeout: -time.Second},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if got, want := err != nil, tt.wantErr; got != want {
+				t.Errorf("unexpected error -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
+
+func TestController_Query(t *testing.T) {
+	c, err := control.New(control.Config{ConcurrencyQuota: 1, QueueSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	q1, err := c.Query(ctx, &program{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// The second query waits in the queue.
+	type result struct {
+		q   flux.Query
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		q, err := c.Query(ctx, &program{}, nil)
+		done <- result{q: q, err: err}
+	}()
+	for c.Stats().Queued != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The third query is rejected because the queue is full.
+	if _, err := c.Query(ctx, &program{}, nil); err == nil {
+		t.Fatal("expected the query to be rejected")
+	} else if got, want := errors.Code(err), codes.ResourceExhausted; got != want {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	// Finishing the first query admits the queued query.
+	q1.Done()
+	r := <-done
+	if r.err != nil {
+		t.Fatalf("unexpected error: %s", r.err)
+	}
+	if r.q.Statistics().QueueDuration <= 0 {
+		t.Error("expected the queue duration to be recorded")
+	}
+	r.q.Done()
+	r.q.Done()
+
+	want := control.Stats{Admitted: 2, Rejected: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("unexpected stats -want/+got:\n\t- %+v\n\t+ %+v", want, got)
+	}
+}
+
+func TestController_QueueTimeout(t *testing.T) {
+	c, err := control.New(control.Config{
+		ConcurrencyQuota: 1,
+		QueueSize:        1,
+		QueueTimeout:     10 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	q, err := c.Query(ctx, &program{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer q.Done()
+
+	if _, err := c.Query(ctx, &program{}, nil); err == nil {
+		t.Fatal("expected the query to time out")
+	} else if got, want := errors.Code(err), codes.DeadlineExceeded; got != want {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	want := control.Stats{Executing: 1, Admitted: 1, TimedOut: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("unexpected stats -want/+got:\n\t- %+v\n\t+ %+v", want, got)
+	}
+}
+
+func TestController_Canceled(t *testing.T) {
+	c, err := control.New(control.Config{ConcurrencyQuota: 1, QueueSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := c.Query(context.Background(), &program{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer q.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := c.Query(ctx, &program{}, nil); err == nil {
+		t.Fatal("expected the query to be canceled")
+	} else if got, want := errors.Code(err), codes.Canceled; got != want {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	want := control.Stats{Executing: 1, Admitted: 1, Canceled: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("unexpected stats -want/+got:\n\t- %+v\n\t+ %+v", want, got)
+	}
+}
+
+func TestController_StartError(t *testing.T) {
+	c, err := control.New(control.Config{ConcurrencyQuota: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := &program{err: errors.New(codes.Invalid, "expected")}
+	if _, err := c.Query(context.Background(), p, nil); err == nil {
+		t.Fatal("expected error")
+	}
+
+	// The slot is released so the next query is admitted.
+	q, err := c.Query(context.Background(), &program{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	q.Done()
+
+	want := control.Stats{Admitted: 2}
+	if got := c.Stats(); got != want {
+		t.Errorf("unexpected stats -want/+got:\n\t- %+v\n\t+ %+v", want, got)
+	}
+}
+
+func TestStart(t *testing.T) {
+	// Without a controller, the program starts immediately.
+	q, err := control.Start(context.Background(), &program{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	q.Done()
+
+	c, err := control.New(control.Config{ConcurrencyQuota: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := control.Inject(context.Background(), c)
+	if got := control.Get(ctx); got != c {
+		t.Fatal("expected the injected controller")
+	}
+	q, err = control.Start(ctx, &program{}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer q.Done()
+
+	// The controller admits one query at a time and has no queue.
+	if _, err := control.Start(ctx, &program{}, nil); err == nil {
+		t.Fatal("expected the query to be rejected")
+	} else if got, want := errors.Code(err), codes.ResourceExhausted; got != want {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
@@ -87,6 +87,10 @@ type ResultDecoderConfig struct {
 	// When the context is canceled, the decoder will also be canceled.
 	// This defaults to context.Background.
 	Context context.Context
+	// Columns limits decoding to the columns with these labels.
+	// Values in other columns are skipped without being decoded.
+	// If nil, every column is decoded.
+	Columns []string
 }
 
 func (d *ResultDecoder) Decode(r io.Reader) (flux.Result, error) {
@@ -303,9 +307,11 @@ EOF:
 }
 
 type tableMetadata struct {
-	ResultID       string
-	TableID        string
-	Cols           []colMeta
+	ResultID string
+	TableID  string
+	Cols     []colMeta
+	// Indices are the positions of Cols in a record.
+	Indices        []int
 	Groups         []bool
 	Defaults       []values.Value
 	NumFields      int
@@ -458,12 +464,30 @@ func readMetadata(r *bufferedCSVReader, c ResultDecoderConfig) (tableMetadata, e
 		labels = line[recordStartIdx:]
 	}
 
-	cols := make([]colMeta, len(labels))
-	defaultValues := make([]values.Value, len(labels))
-	groupValues := make([]bool, len(labels))
+	var keep map[string]bool
+	if c.Columns != nil {
+		keep = make(map[string]bool, len(c.Columns))
+		for _, label := range c.Columns {
+			keep[label] = true
+		}
+	}
+
+	cols := make([]colMeta, 0, len(labels))
+	indices := make([]int, 0, len(labels))
+	defaultValues := make([]values.Value, 0, len(labels))
+	groupValues := make([]bool, 0, len(labels))
+
+	for i, label := range labels {
+		if keep != nil && !keep[label] {
+			continue
+		}
+		j := len(cols)
+		cols = append(cols, colMeta{})
+		indices = append(indices, i)
+		defaultValues = append(defaultValues, nil)
+		groupValues = append(groupValues, false)
 
-	for j, label := range labels {
-		t, desc, err := decodeType(datatypes[j])
+		t, desc, err := decodeType(datatypes[i])
 		if err != nil {
 			return tableMetadata{}, errors.Wrapf(err, codes.Invalid, "column %q has invalid datatype", label)
 		}
@@ -479,28 +503,29 @@ func readMetadata(r *bufferedCSVReader, c ResultDecoderConfig) (tableMetadata, e
 				cols[j].fmt = desc
 			}
 		}
-		if defaults[j] == nullValue {
+		if defaults[i] == nullValue {
 			defaultValues[j] = values.NewNull(flux.SemanticType(cols[j].ColMeta.Type))
-		} else if defaults[j] == "" {
+		} else if defaults[i] == "" {
 			// for now, the null value is always represented with "", so this is
 			// unreachable.
 			// When we support the #null annotation we'll want to distinguish
 			// between "" (for strings) and null here.
 			panic("unreachable")
 		} else {
-			v, err := decodeValue(defaults[j], cols[j])
+			v, err := decodeValue(defaults[i], cols[j])
 			if err != nil {
 				return tableMetadata{}, errors.Wrapf(err, codes.Invalid, "column %q has invalid default value", label)
 			}
 			defaultValues[j] = v
 		}
-		groupValues[j] = groups[j] == "true"
+		groupValues[j] = groups[i] == "true"
 	}
 
 	return tableMetadata{
 		ResultID:       resultID,
 		TableID:        tableID,
 		Cols:           cols,
+		Indices:        indices,
 		Groups:         groupValues,
 		Defaults:       defaultValues,
 		NumFields:      n,
@@ -687,9 +712,9 @@ func (d *tableDecoder) init(line []string) error {
 	for j, c := range d.meta.Cols {
 		if d.meta.Groups[j] {
 			var value values.Value
-			if record != nil && record[j] != "" {
+			if i := d.meta.Indices[j]; record != nil && record[i] != "" {
 				// TODO: consider treatment of nullValue here
-				v, err := decodeValue(record[j], c)
+				v, err := decodeValue(record[i], c)
 				if err != nil {
 					return err
 				}
@@ -722,14 +747,15 @@ func (d *tableDecoder) init(line []string) error {
 func (d *tableDecoder) appendRecord(record []string) error {
 	d.empty = false
 	for j, c := range d.meta.Cols {
-		if record[j] == "" {
+		value := record[d.meta.Indices[j]]
+		if value == "" {
 			v := d.meta.Defaults[j]
 			if err := arrow.AppendValue(d.cols[j], v); err != nil {
 				return err
 			}
 			continue
 		}
-		if err := decodeValueInto(c, record[j], d.cols[j]); err != nil {
+		if err := decodeValueInto(c, value, d.cols[j]); err != nil {
 			return err
 		}
 	}
@@ -78,6 +78,7 @@ type (
 	Table      = bigtable.Table
 	RowSet     = bigtable.RowSet
 	RowRange   = bigtable.RowRange
+	RowList    = bigtable.RowList
 	Row        = bigtable.Row
 	Filter     = bigtable.Filter
 	ReadOption = bigtable.ReadOption
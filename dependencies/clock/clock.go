@@ -0,0 +1,79 @@
+// Package clock provides a dependency for the current time.
+//
+// The now option, system.time and the default now time of queries
+// read the time from the clock in the context. Tests and replay tools
+// inject a clock that returns a fixed or scripted time so that the
+// results of a script do not depend on when it is run.
+//
+// The clock is carried by the context alone, with Inject and Get,
+// so that it is not a part of flux.Dependencies and its implementations.
+package clock
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type key int
+
+const clockKey key = iota
+
+// Clock returns the current time.
+type Clock interface {
+	Now() time.Time
+}
+
+// Func is a function that implements Clock.
+type Func func() time.Time
+
+// Now calls the function.
+func (f Func) Now() time.Time {
+	return f()
+}
+
+// System is the Clock of the system.
+var System Clock = Func(time.Now)
+
+// Fixed returns a Clock that always returns t.
+func Fixed(t time.Time) Clock {
+	return Func(func() time.Time {
+		return t
+	})
+}
+
+// Script returns a Clock that returns each of the times in turn.
+// Once the times are used up, it keeps returning the last one.
+// It returns the zero time if there are no times.
+func Script(times ...time.Time) Clock {
+	var (
+		mu sync.Mutex
+		i  int
+	)
+	return Func(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(times) == 0 {
+			return time.Time{}
+		}
+		t := times[i]
+		if i < len(times)-1 {
+			i++
+		}
+		return t
+	})
+}
+
+// Inject will inject the Clock into the dependency chain.
+func Inject(ctx context.Context, c Clock) context.Context {
+	return context.WithValue(ctx, clockKey, c)
+}
+
+// Get returns the Clock from the context.
+// It returns the System clock if no Clock has been configured.
+func Get(ctx context.Context) Clock {
+	if c, ok := ctx.Value(clockKey).(Clock); ok {
+		return c
+	}
+	return System
+}
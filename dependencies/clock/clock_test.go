@@ -0,0 +1,42 @@
+package clock_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/dependencies/clock"
+)
+
+func TestGet(t *testing.T) {
+	if c := clock.Get(context.Background()); c == nil {
+		t.Fatal("expected the system clock when no clock is configured")
+	}
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ctx := clock.Inject(context.Background(), clock.Fixed(now))
+	for i := 0; i < 2; i++ {
+		if got := clock.Get(ctx).Now(); !got.Equal(now) {
+			t.Errorf("unexpected time -want/+got:\n\t- %v\n\t+ %v", now, got)
+		}
+	}
+}
+
+func TestScript(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := clock.Script(start, start.Add(time.Second))
+	for _, want := range []time.Time{
+		start,
+		start.Add(time.Second),
+		// The last time is repeated.
+		start.Add(time.Second),
+	} {
+		if got := c.Now(); !got.Equal(want) {
+			t.Errorf("unexpected time -want/+got:\n\t- %v\n\t+ %v", want, got)
+		}
+	}
+
+	if got := clock.Script().Now(); !got.IsZero() {
+		t.Errorf("expected the zero time from an empty script, got %v", got)
+	}
+}
@@ -0,0 +1,184 @@
+// Package concurrency provides a process-level quota on the number
+// of workers that may process queries at the same time.
+//
+// Each query sizes its own pool of dispatcher workers from its
+// concurrency quota. When many queries run in the same process,
+// a Quota can be injected into the dependencies so the workers of
+// every query draw from the same set of slots. A worker holds a
+// slot only while it runs scheduled work, and not while it waits
+// for the results of its query to be read. Slots are handed out
+// to the query holding the fewest slots first so a busy query
+// cannot starve the others.
+package concurrency
+
+import (
+	"context"
+	"sync"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+type key int
+
+const quotaKey key = iota
+
+// Quota limits the number of workers that may run at the same time
+// across all of the queries that share it.
+type Quota struct {
+	mu      sync.Mutex
+	limit   int
+	inUse   int
+	waiters []*waiter
+}
+
+// NewQuota creates a Quota that allows limit workers to run at once.
+func NewQuota(limit int) (*Quota, error) {
+	if limit <= 0 {
+		return nil, errors.Newf(codes.Invalid, "concurrency quota must be positive, got %d", limit)
+	}
+	return &Quota{limit: limit}, nil
+}
+
+// Inject will inject the Quota into the dependency chain.
+func (q *Quota) Inject(ctx context.Context) context.Context {
+	return Inject(ctx, q)
+}
+
+// Inject will inject the Quota into the dependency chain.
+func Inject(ctx context.Context, q *Quota) context.Context {
+	return context.WithValue(ctx, quotaKey, q)
+}
+
+// Get returns the Quota from the context.
+// It returns nil if no Quota has been configured.
+func Get(ctx context.Context) *Quota {
+	q, _ := ctx.Value(quotaKey).(*Quota)
+	return q
+}
+
+// Limit returns the number of workers that may run at once.
+func (q *Quota) Limit() int {
+	return q.limit
+}
+
+// InUse returns the number of slots currently held.
+func (q *Quota) InUse() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.inUse
+}
+
+// Waiting returns the number of workers waiting for a slot.
+func (q *Quota) Waiting() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiters)
+}
+
+// NewClient returns a Client that acquires slots on behalf of one query.
+// A nil Quota returns a nil Client which never blocks.
+func (q *Quota) NewClient() *Client {
+	if q == nil {
+		return nil
+	}
+	return &Client{q: q}
+}
+
+// Client acquires slots from a Quota for a single query.
+// Slots are shared fairly between the clients of a Quota.
+type Client struct {
+	q    *Quota
+	held int
+}
+
+type waiter struct {
+	c       *Client
+	ready   chan struct{}
+	granted bool
+}
+
+// Acquire blocks until a slot is available or the context is done.
+// Each successful call must be followed by a call to Release.
+func (c *Client) Acquire(ctx context.Context) error {
+	if c == nil {
+		return nil
+	}
+
+	q := c.q
+	q.mu.Lock()
+	if q.inUse < q.limit && len(q.waiters) == 0 {
+		q.grant(c)
+		q.mu.Unlock()
+		return nil
+	}
+	w := &waiter{c: c, ready: make(chan struct{})}
+	q.waiters = append(q.waiters, w)
+	q.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if w.granted {
+		// The slot was handed to us while we were giving up
+		// so return it so another waiter can use it.
+		q.release(c)
+	} else {
+		q.remove(w)
+	}
+	return ctx.Err()
+}
+
+// Release returns a slot acquired with Acquire.
+func (c *Client) Release() {
+	if c == nil {
+		return
+	}
+	c.q.mu.Lock()
+	defer c.q.mu.Unlock()
+	c.q.release(c)
+}
+
+func (q *Quota) grant(c *Client) {
+	q.inUse++
+	c.held++
+}
+
+func (q *Quota) release(c *Client) {
+	q.inUse--
+	c.held--
+	for q.inUse < q.limit && len(q.waiters) > 0 {
+		w := q.next()
+		q.grant(w.c)
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// next removes and returns the waiter whose client holds the fewest
+// slots. Waiters with the same number of slots are served in order.
+func (q *Quota) next() *waiter {
+	idx := 0
+	for i, w := range q.waiters[1:] {
+		if w.c.held < q.waiters[idx].c.held {
+			idx = i + 1
+		}
+	}
+	w := q.waiters[idx]
+	q.waiters = append(q.waiters[:idx], q.waiters[idx+1:]...)
+	return w
+}
+
+func (q *Quota) remove(w *waiter) {
+	for i, other := range q.waiters {
+		if other == w {
+			q.waiters = append(q.waiters[:i], q.waiters[i+1:]...)
+			return
+		}
+	}
+}
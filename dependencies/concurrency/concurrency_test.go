@@ -0,0 +1,149 @@
+package concurrency_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/dependencies/concurrency"
+)
+
+func TestNewQuota(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		limit   int
+		wantErr bool
+	}{
+		{name: "positive", limit: 4},
+		{name: "zero", limit: 0, wantErr: true},
+		{name: "negative", limit: -1, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := concurrency.NewQuota(tt.limit)
+			if got, want := err != nil, tt.wantErr; got != want {
+				t.Errorf("unexpected error -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	if q := concurrency.Get(context.Background()); q != nil {
+		t.Errorf("expected no quota, got %v", q)
+	}
+
+	q, _ := concurrency.NewQuota(1)
+	ctx := q.Inject(context.Background())
+	if got := concurrency.Get(ctx); got != q {
+		t.Errorf("unexpected quota -want/+got:\n\t- %v\n\t+ %v", q, got)
+	}
+}
+
+func TestClient_Nil(t *testing.T) {
+	var q *concurrency.Quota
+	c := q.NewClient()
+	if err := c.Acquire(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	c.Release()
+}
+
+func TestClient_Acquire(t *testing.T) {
+	q, _ := concurrency.NewQuota(2)
+	c := q.NewClient()
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if err := c.Acquire(ctx); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if got, want := q.InUse(), 2; got != want {
+		t.Errorf("unexpected slots in use -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		_ = c.Acquire(ctx)
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("acquired a slot beyond the limit")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	c.Release()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("expected the waiter to acquire the released slot")
+	}
+	c.Release()
+	c.Release()
+
+	if got, want := q.InUse(), 0; got != want {
+		t.Errorf("unexpected slots in use -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
+func TestClient_AcquireCanceled(t *testing.T) {
+	q, _ := concurrency.NewQuota(1)
+	c := q.NewClient()
+	if err := c.Acquire(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if got, want := c.Acquire(ctx), context.DeadlineExceeded; got != want {
+		t.Errorf("unexpected error -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+	if got, want := q.Waiting(), 0; got != want {
+		t.Errorf("unexpected waiters -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	c.Release()
+	if got, want := q.InUse(), 0; got != want {
+		t.Errorf("unexpected slots in use -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
+func TestClient_Fair(t *testing.T) {
+	q, _ := concurrency.NewQuota(2)
+	busy, idle := q.NewClient(), q.NewClient()
+	ctx := context.Background()
+
+	// The busy client holds every slot.
+	for i := 0; i < 2; i++ {
+		if err := busy.Acquire(ctx); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// The busy client queues first, but the idle client should
+	// be given the next slot because it holds none.
+	order := make(chan string, 2)
+	waitFor := func(name string, c *concurrency.Client) {
+		if err := c.Acquire(ctx); err == nil {
+			order <- name
+		}
+	}
+	go waitFor("busy", busy)
+	for q.Waiting() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	go waitFor("idle", idle)
+	for q.Waiting() != 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	busy.Release()
+	if got, want := <-order, "idle"; got != want {
+		t.Errorf("unexpected client -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+	busy.Release()
+	if got, want := <-order, "busy"; got != want {
+		t.Errorf("unexpected client -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
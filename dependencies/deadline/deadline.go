@@ -0,0 +1,60 @@
+// Package deadline provides a dependency for limiting
+// how long each query is allowed to run.
+//
+// The timeout is applied when a program is started so it covers
+// the evaluation, planning and execution of the query. Sources
+// and transformations observe the deadline through the context
+// of the query and stop with an error when it is exceeded.
+package deadline
+
+import (
+	"context"
+	"time"
+)
+
+type key int
+
+const timeoutKey key = iota
+
+// Inject will inject the query timeout into the dependency chain.
+// A timeout that is not positive removes any configured timeout.
+func Inject(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, timeoutKey, timeout)
+}
+
+// Dependency will inject the query timeout into the dependency chain.
+type Dependency struct {
+	// Timeout is the maximum amount of time a query may run.
+	Timeout time.Duration
+}
+
+// Inject will inject the query timeout into the dependency chain.
+func (d Dependency) Inject(ctx context.Context) context.Context {
+	return Inject(ctx, d.Timeout)
+}
+
+// GetTimeout returns the query timeout configured in the context.
+// It returns zero if no timeout has been configured.
+func GetTimeout(ctx context.Context) time.Duration {
+	timeout, _ := ctx.Value(timeoutKey).(time.Duration)
+	if timeout < 0 {
+		timeout = 0
+	}
+	return timeout
+}
+
+// WithTimeout returns a copy of the context that is canceled when
+// the query timeout configured in the context elapses. The configured
+// timeout is removed from the returned context so that it is only
+// applied once to a query.
+//
+// If no timeout has been configured, the context is only
+// canceled when the returned cancel function is called.
+func WithTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	timeout := GetTimeout(ctx)
+	if timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return Inject(ctx, 0), cancel
+}
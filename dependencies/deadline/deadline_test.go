@@ -0,0 +1,70 @@
+package deadline_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/dependencies/deadline"
+)
+
+func TestGetTimeout(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		want time.Duration
+	}{
+		{
+			name: "not configured",
+			ctx:  context.Background(),
+		},
+		{
+			name: "configured",
+			ctx:  deadline.Dependency{Timeout: time.Second}.Inject(context.Background()),
+			want: time.Second,
+		},
+		{
+			name: "negative",
+			ctx:  deadline.Inject(context.Background(), -time.Second),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deadline.GetTimeout(tt.ctx); got != tt.want {
+				t.Errorf("unexpected timeout -want/+got:\n\t- %v\n\t+ %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	ctx := deadline.Inject(context.Background(), time.Millisecond)
+	ctx, cancel := deadline.WithTimeout(ctx)
+	defer cancel()
+
+	if _, ok := ctx.Deadline(); !ok {
+		t.Fatal("expected the context to have a deadline")
+	}
+	if got := deadline.GetTimeout(ctx); got != 0 {
+		t.Errorf("expected the timeout to be applied once, got %v", got)
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected the context to be canceled")
+	}
+	if got, want := ctx.Err(), context.DeadlineExceeded; got != want {
+		t.Errorf("unexpected error -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
+func TestWithTimeout_NotConfigured(t *testing.T) {
+	ctx, cancel := deadline.WithTimeout(context.Background())
+	if _, ok := ctx.Deadline(); ok {
+		t.Fatal("unexpected deadline")
+	}
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("expected the context to be canceled, got %v", ctx.Err())
+	}
+}
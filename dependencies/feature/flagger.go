@@ -2,8 +2,12 @@ package feature
 
 import (
 	"context"
+	"strings"
 
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/internal/feature"
+	pkgfeature "github.com/influxdata/flux/internal/pkg/feature"
 )
 
 type (
@@ -18,10 +22,46 @@ func Inject(ctx context.Context, flagger Flagger) context.Context {
 
 type Dependency struct {
 	Flagger Flagger
+	// Overrides are flag values, by key, that override
+	// the values returned by the Flagger.
+	Overrides map[string]interface{}
 }
 
 func (d Dependency) Inject(ctx context.Context) context.Context {
-	return Inject(ctx, d.Flagger)
+	return Override(Inject(ctx, d.Flagger), d.Overrides)
+}
+
+// Override returns a context in which the flags with the given keys
+// have the given values, regardless of the Flagger of the context,
+// so a single query or session can use experimental behavior
+// without enabling it globally.
+func Override(ctx context.Context, overrides map[string]interface{}) context.Context {
+	return pkgfeature.WithOverrides(ctx, overrides)
+}
+
+// ParseOverrides parses flag settings of the form key=value into values
+// of the type of each flag. A setting without a value enables a boolean flag.
+func ParseOverrides(settings []string) (map[string]interface{}, error) {
+	if len(settings) == 0 {
+		return nil, nil
+	}
+	overrides := make(map[string]interface{}, len(settings))
+	for _, setting := range settings {
+		key, value := setting, "true"
+		if i := strings.Index(setting, "="); i >= 0 {
+			key, value = setting[:i], setting[i+1:]
+		}
+		flag, ok := ByKey(key)
+		if !ok {
+			return nil, errors.Newf(codes.Invalid, "unknown feature flag %q", key)
+		}
+		v, err := pkgfeature.ParseValue(flag, value)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "invalid value %q for feature flag %q", value, key)
+		}
+		overrides[key] = v
+	}
+	return overrides, nil
 }
 
 // Flags returns all feature flags.
@@ -0,0 +1,54 @@
+package feature_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/feature"
+	ifeature "github.com/influxdata/flux/internal/feature"
+)
+
+func TestParseOverrides(t *testing.T) {
+	got, err := feature.ParseOverrides([]string{
+		"bytecodeCompiler",
+		"queryConcurrencyLimit=4",
+		"optimizeDerivative=false",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"bytecodeCompiler":      true,
+		"queryConcurrencyLimit": int32(4),
+		"optimizeDerivative":    false,
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected overrides -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	for _, settings := range [][]string{
+		{"notAFlag=true"},
+		{"queryConcurrencyLimit=many"},
+	} {
+		if _, err := feature.ParseOverrides(settings); err == nil {
+			t.Errorf("expected an error parsing %v", settings)
+		} else if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+			t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+		}
+	}
+}
+
+func TestDependency_Overrides(t *testing.T) {
+	ctx := feature.Dependency{
+		Overrides: map[string]interface{}{"bytecodeCompiler": true},
+	}.Inject(context.Background())
+	if !ifeature.BytecodeCompiler().Enabled(ctx) {
+		t.Error("expected the overridden flag to be enabled")
+	}
+	if ifeature.OptimizeDerivative().Enabled(ctx) {
+		t.Error("expected the other flags to keep their defaults")
+	}
+}
@@ -18,16 +18,21 @@ func ReadFile(ctx context.Context, filename string) ([]byte, error) {
 		return nil, err
 	}
 	defer func() { _ = f.Close() }()
-	return ioutil.ReadAll(f)
+	return ioutil.ReadAll(&contextFile{ctx: ctx, File: f})
 }
 
 // OpenFile will open the file from the service.
+// Reads from the file fail once the context is canceled.
 func OpenFile(ctx context.Context, filename string) (File, error) {
 	fs, err := Get(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return fs.Open(filename)
+	f, err := fs.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return &contextFile{ctx: ctx, File: f}, nil
 }
 
 // Stat will retrieve the os.FileInfo for a file.
@@ -43,3 +48,17 @@ func Stat(ctx context.Context, filename string) (os.FileInfo, error) {
 	defer func() { _ = f.Close() }()
 	return f.Stat()
 }
+
+// contextFile is a File that stops reading when its context is canceled
+// so that reading a large file does not delay the cancellation of a query.
+type contextFile struct {
+	ctx context.Context
+	File
+}
+
+func (f *contextFile) Read(p []byte) (int, error) {
+	if err := f.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return f.File.Read(p)
+}
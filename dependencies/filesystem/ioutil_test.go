@@ -0,0 +1,48 @@
+package filesystem_test
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/influxdata/flux/dependencies/filesystem"
+)
+
+func TestOpenFile_Canceled(t *testing.T) {
+	tmpfile, err := ioutil.TempFile("", "flux-ioutil-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Remove(tmpfile.Name()) }()
+	defer func() { _ = tmpfile.Close() }()
+
+	if _, err := io.WriteString(tmpfile, "Hello, World!"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = filesystem.Inject(ctx, filesystem.SystemFS)
+	f, err := filesystem.OpenFile(ctx, tmpfile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(buf), "Hello"; got != want {
+		t.Fatalf("unexpected file contents -want/+got:\n\t- %q\n\t+ %q", want, got)
+	}
+
+	cancel()
+	if _, err := f.Read(buf); err != context.Canceled {
+		t.Fatalf("expected read to be canceled, got %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package filesystem
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"time"
+)
+
+// MemoryFS implements the filesystem.Service with files held in memory,
+// keyed by their path. It is used where there is no filesystem,
+// such as when Flux runs in a browser.
+type MemoryFS map[string][]byte
+
+func (fs MemoryFS) Open(fpath string) (File, error) {
+	data, ok := fs[fpath]
+	if !ok {
+		return nil, &os.PathError{Op: "open", Path: fpath, Err: os.ErrNotExist}
+	}
+	return &memFile{
+		Reader: bytes.NewReader(data),
+		name:   path.Base(fpath),
+		size:   int64(len(data)),
+	}, nil
+}
+
+type memFile struct {
+	*bytes.Reader
+	name string
+	size int64
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
+
+func (f *memFile) Stat() (os.FileInfo, error) {
+	return memFileInfo{name: f.name, size: f.size}, nil
+}
+
+type memFileInfo struct {
+	name string
+	size int64
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return fi.size }
+func (fi memFileInfo) Mode() os.FileMode  { return 0444 }
+func (fi memFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi memFileInfo) IsDir() bool        { return false }
+func (fi memFileInfo) Sys() interface{}   { return nil }
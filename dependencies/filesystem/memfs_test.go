@@ -0,0 +1,39 @@
+package filesystem_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/influxdata/flux/dependencies/filesystem"
+)
+
+func TestMemoryFS(t *testing.T) {
+	fs := filesystem.MemoryFS{
+		"data/a.csv": []byte("Hello, World!"),
+	}
+	ctx := filesystem.Inject(context.Background(), fs)
+
+	data, err := filesystem.ReadFile(ctx, "data/a.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "Hello, World!"; got != want {
+		t.Fatalf("unexpected file contents -want/+got:\n\t- %q\n\t+ %q", want, got)
+	}
+
+	fi, err := filesystem.Stat(ctx, "data/a.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fi.Name(), "a.csv"; got != want {
+		t.Fatalf("unexpected file info name -want/+got:\n\t- %q\n\t+ %q", want, got)
+	}
+	if got, want := fi.Size(), int64(13); got != want {
+		t.Fatalf("unexpected file info size -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	if _, err := fs.Open("b.csv"); !os.IsNotExist(err) {
+		t.Fatalf("expected a not exist error, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// NewRootFS creates a filesystem.Service that confines the files
+// that are opened to a root directory. The paths are resolved
+// under the root as if it were the root of the filesystem,
+// so "/data/a.csv" and "data/a.csv" both open the file a.csv
+// of the data directory of the root.
+//
+// A path whose symbolic links resolve outside of the root
+// is denied, as are the files that are not regular files.
+// When maxSize is positive, the files that are larger than
+// maxSize bytes are denied and reads fail once maxSize bytes
+// have been read, in case the file grows after it is opened.
+func NewRootFS(root string, maxSize int64) (Service, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	// the root itself may be a symbolic link.
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return nil, err
+	}
+	if fi, err := os.Stat(resolved); err != nil {
+		return nil, err
+	} else if !fi.IsDir() {
+		return nil, errors.Newf(codes.Invalid, "root %q is not a directory", root)
+	}
+	return rootFS{root: resolved, maxSize: maxSize}, nil
+}
+
+type rootFS struct {
+	root    string
+	maxSize int64
+}
+
+func (fs rootFS) Open(fpath string) (File, error) {
+	name := filepath.Join(fs.root, filepath.Clean(string(filepath.Separator)+fpath))
+	resolved, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		return nil, err
+	}
+	if !fs.contains(resolved) {
+		return nil, errors.Newf(codes.PermissionDenied, "file %q is outside of the root directory", fpath)
+	}
+
+	f, err := os.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		_ = f.Close()
+		return nil, errors.Newf(codes.PermissionDenied, "file %q is not a regular file", fpath)
+	}
+	if fs.maxSize > 0 {
+		if fi.Size() > fs.maxSize {
+			_ = f.Close()
+			return nil, errors.Newf(codes.ResourceExhausted, "file %q is larger than the limit of %d bytes", fpath, fs.maxSize)
+		}
+		return &limitedFile{File: f, name: fpath, remaining: fs.maxSize}, nil
+	}
+	return f, nil
+}
+
+// contains reports whether a resolved path is the root or is under it.
+func (fs rootFS) contains(resolved string) bool {
+	rel, err := filepath.Rel(fs.root, resolved)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// limitedFile is a File that fails to read more than a number of bytes.
+type limitedFile struct {
+	File
+	name      string
+	remaining int64
+}
+
+func (f *limitedFile) Read(p []byte) (int, error) {
+	if f.remaining <= 0 {
+		// the limit has been read, which is fine if it is the end of the file.
+		var b [1]byte
+		if n, err := f.File.Read(b[:]); n == 0 {
+			return 0, err
+		}
+		return 0, errors.Newf(codes.ResourceExhausted, "file %q grew larger than the limit", f.name)
+	}
+	if int64(len(p)) > f.remaining {
+		p = p[:f.remaining]
+	}
+	n, err := f.File.Read(p)
+	f.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,109 @@
+package filesystem_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestRootFS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-rootfs-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	root := filepath.Join(dir, "root")
+	for _, d := range []string{root, filepath.Join(root, "data")} {
+		if err := os.Mkdir(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, content := range map[string]string{
+		filepath.Join(root, "data", "a.csv"): "Hello, World!",
+		filepath.Join(root, "large.csv"):     "Hello, World! Hello, World!",
+		filepath.Join(dir, "secret.csv"):     "secret",
+	} {
+		if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	links := map[string]string{
+		filepath.Join(root, "inside.csv"):  filepath.Join(root, "data", "a.csv"),
+		filepath.Join(root, "outside.csv"): filepath.Join(dir, "secret.csv"),
+		filepath.Join(root, "parent"):      dir,
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, name); err != nil {
+			t.Skipf("symbolic links are not supported: %s", err)
+		}
+	}
+
+	fs, err := filesystem.NewRootFS(root, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"data/a.csv", "/data/a.csv", "../data/a.csv", "inside.csv"} {
+		f, err := fs.Open(name)
+		if err != nil {
+			t.Errorf("unexpected error opening %q: %s", name, err)
+			continue
+		}
+		data, err := ioutil.ReadAll(f)
+		_ = f.Close()
+		if err != nil {
+			t.Errorf("unexpected error reading %q: %s", name, err)
+		} else if got, want := string(data), "Hello, World!"; got != want {
+			t.Errorf("unexpected contents of %q -want/+got:\n\t- %q\n\t+ %q", name, want, got)
+		}
+	}
+
+	for name, code := range map[string]codes.Code{
+		"outside.csv":       codes.PermissionDenied,
+		"parent/secret.csv": codes.PermissionDenied,
+		"data":              codes.PermissionDenied,
+		"large.csv":         codes.ResourceExhausted,
+	} {
+		if _, err := fs.Open(name); errors.Code(err) != code {
+			t.Errorf("unexpected error opening %q: want code %v, got %v", name, code, err)
+		}
+	}
+	// a path outside of the root is resolved under it.
+	if _, err := fs.Open("../secret.csv"); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
+
+func TestRootFS_Grows(t *testing.T) {
+	dir, err := ioutil.TempDir("", "flux-rootfs-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	name := filepath.Join(dir, "a.csv")
+	if err := ioutil.WriteFile(name, []byte("Hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fs, err := filesystem.NewRootFS(dir, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := fs.Open("a.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	if err := ioutil.WriteFile(name, []byte("Hello, World!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioutil.ReadAll(f); errors.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected a resource exhausted error, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/secret"
+	"github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// TransportConfig configures how a client connects to the hosts.
+type TransportConfig struct {
+	// Proxy is the url of the proxy that the requests are sent through.
+	// The HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	// select the proxy when it is empty.
+	Proxy string `json:"proxy,omitempty"`
+	// CAFile is a PEM file of the certificate authorities
+	// that are trusted in addition to those of the system.
+	CAFile string `json:"caFile,omitempty"`
+	// CertSecret and KeySecret are the keys of the secrets of the PEM
+	// certificate and private key that the client authenticates with.
+	CertSecret string `json:"certSecret,omitempty"`
+	KeySecret  string `json:"keySecret,omitempty"`
+}
+
+// Config configures the clients created by NewClient.
+type Config struct {
+	TransportConfig
+	// Hosts replaces the transport configuration for the requests to some hosts.
+	// A host name that starts with "*." matches all of the subdomains of the rest of the name.
+	Hosts map[string]TransportConfig `json:"hosts,omitempty"`
+	// RequestsPerSecond limits the rate of the requests to each host,
+	// the requests above the rate wait for their turn.
+	// There is no limit when it is not positive.
+	RequestsPerSecond float64 `json:"requestsPerSecond,omitempty"`
+	// Burst is the number of requests to a host that may be sent
+	// at once above the rate. It is at least one.
+	Burst int `json:"burst,omitempty"`
+	// Retry is how the failed requests are retried,
+	// the functions that send requests may override it per call.
+	// The requests are not retried when it is zero.
+	Retry RetryPolicy `json:"retry,omitempty"`
+}
+
+// IsZero reports whether the configuration is the one of the default client.
+func (c Config) IsZero() bool {
+	return c.TransportConfig == TransportConfig{} && len(c.Hosts) == 0 && c.RequestsPerSecond <= 0 && c.Retry.MaxAttempts < 2
+}
+
+// NewClient creates a client with a limit on the response body size,
+// like NewLimitedDefaultClient, that connects to the hosts
+// and retries the requests as configured.
+// The client certificates are read from the secret service.
+func NewClient(ctx context.Context, urlValidator url.Validator, config Config, secrets secret.Service) (*http.Client, error) {
+	base, err := newConfiguredTransport(ctx, urlValidator, config.TransportConfig, secrets)
+	if err != nil {
+		return nil, err
+	}
+	var transport http.RoundTripper = base
+	if len(config.Hosts) > 0 {
+		ht := hostTransport{
+			base:  base,
+			hosts: make(map[string]http.RoundTripper, len(config.Hosts)),
+		}
+		for host, tc := range config.Hosts {
+			t, err := newConfiguredTransport(ctx, urlValidator, tc, secrets)
+			if err != nil {
+				return nil, errors.Wrapf(err, codes.Invalid, "invalid configuration for host %q", host)
+			}
+			ht.hosts[strings.ToLower(host)] = t
+		}
+		transport = ht
+	}
+	if config.RequestsPerSecond > 0 {
+		transport = rateLimitTransport{
+			RoundTripper: transport,
+			limiter:      newRateLimiter(config.RequestsPerSecond, config.Burst),
+		}
+	}
+	policy := config.Retry
+	if policy.InitialBackoff == 0 && policy.MaxBackoff == 0 {
+		policy.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+		policy.MaxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	transport = retryTransport{RoundTripper: transport, policy: policy}
+	return LimitHTTPBody(http.Client{Transport: transport}, maxResponseBody), nil
+}
+
+func newConfiguredTransport(ctx context.Context, urlValidator url.Validator, config TransportConfig, secrets secret.Service) (*http.Transport, error) {
+	proxy := http.ProxyFromEnvironment
+	if config.Proxy != "" {
+		u, err := neturl.Parse(config.Proxy)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "invalid proxy url %q", config.Proxy)
+		}
+		proxy = http.ProxyURL(u)
+	}
+
+	var tlsConfig *tls.Config
+	if config.CAFile != "" {
+		data, err := ioutil.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "failed to read CA file")
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, errors.Newf(codes.Invalid, "no certificates found in CA file %q", config.CAFile)
+		}
+		tlsConfig = &tls.Config{RootCAs: pool}
+	}
+	if config.CertSecret != "" || config.KeySecret != "" {
+		if config.CertSecret == "" || config.KeySecret == "" {
+			return nil, errors.New(codes.Invalid, "both the certificate and the key secrets are required for client authentication")
+		} else if secrets == nil {
+			return nil, errors.New(codes.Invalid, "a secret service is required for client authentication")
+		}
+		certPEM, err := secrets.LoadSecret(ctx, config.CertSecret)
+		if err != nil {
+			return nil, err
+		}
+		keyPEM, err := secrets.LoadSecret(ctx, config.KeySecret)
+		if err != nil {
+			return nil, err
+		}
+		cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "invalid client certificate")
+		}
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return newTransport(urlValidator, proxy, tlsConfig), nil
+}
+
+// hostTransport sends the requests to some hosts with their own transport.
+type hostTransport struct {
+	base  http.RoundTripper
+	hosts map[string]http.RoundTripper
+}
+
+func (t hostTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := strings.ToLower(req.URL.Hostname())
+	if rt, ok := t.hosts[host]; ok {
+		return rt.RoundTrip(req)
+	}
+	// the longest wildcard name that matches wins.
+	for i := strings.IndexByte(host, '.'); i >= 0; {
+		if rt, ok := t.hosts["*"+host[i:]]; ok {
+			return rt.RoundTrip(req)
+		}
+		next := strings.IndexByte(host[i+1:], '.')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,189 @@
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	depsUrl "github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+type testSecretService map[string]string
+
+func (s testSecretService) LoadSecret(ctx context.Context, k string) (string, error) {
+	if v, ok := s[k]; ok {
+		return v, nil
+	}
+	return "", errors.Newf(codes.NotFound, "secret key %q not found", k)
+}
+
+// writeCAFile writes the certificate of a TLS test server to a file.
+func writeCAFile(t *testing.T, dir string, ts *httptest.Server) string {
+	t.Helper()
+	name := filepath.Join(dir, "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw})
+	if err := ioutil.WriteFile(name, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return name
+}
+
+// newClientCertificate creates a self-signed client certificate and its private key in PEM.
+func newClientCertificate(t *testing.T) (certPEM, keyPEM string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "flux"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestNewClient_TLS(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 || r.TLS.PeerCertificates[0].Subject.CommonName != "flux" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	ts.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	ts.StartTLS()
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "flux-http-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	caFile := writeCAFile(t, dir, ts)
+	certPEM, keyPEM := newClientCertificate(t)
+	secrets := testSecretService{"cert": certPEM, "key": keyPEM}
+
+	testCases := []struct {
+		name       string
+		config     TransportConfig
+		wantStatus int
+		wantErr    bool
+	}{
+		{
+			name:    "unknown authority",
+			wantErr: true,
+		},
+		{
+			name:       "custom authority",
+			config:     TransportConfig{CAFile: caFile},
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "client certificate",
+			config:     TransportConfig{CAFile: caFile, CertSecret: "cert", KeySecret: "key"},
+			wantStatus: http.StatusNoContent,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewClient(context.Background(), depsUrl.PassValidator{}, Config{TransportConfig: tc.config}, secrets)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Get(ts.URL)
+			if tc.wantErr {
+				if err == nil {
+					_ = resp.Body.Close()
+					t.Fatal("expected an error")
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("unexpected status code: want %d, got %d", tc.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestNewClient_Hosts(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+	// the proxy answers the requests for the hosts that are sent to it.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Host != "api.example.com" {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer proxy.Close()
+
+	client, err := NewClient(context.Background(), depsUrl.PassValidator{}, Config{
+		Hosts: map[string]TransportConfig{
+			"*.example.com": {Proxy: proxy.URL},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for u, want := range map[string]int{
+		ts.URL:                    http.StatusNoContent,
+		"http://api.example.com/": http.StatusAccepted,
+	} {
+		resp, err := client.Get(u)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("unexpected status code for %s: want %d, got %d", u, want, resp.StatusCode)
+		}
+	}
+}
+
+func TestNewClient_Invalid(t *testing.T) {
+	for _, config := range []Config{
+		{TransportConfig: TransportConfig{Proxy: "://proxy"}},
+		{TransportConfig: TransportConfig{CAFile: "/does/not/exist.pem"}},
+		{TransportConfig: TransportConfig{CertSecret: "cert"}},
+		{TransportConfig: TransportConfig{CertSecret: "cert", KeySecret: "missing"}},
+		{Hosts: map[string]TransportConfig{"example.com": {Proxy: "://proxy"}}},
+	} {
+		if _, err := NewClient(context.Background(), depsUrl.PassValidator{}, config, testSecretService{"cert": "x"}); err == nil {
+			t.Errorf("expected an error for config %+v", config)
+		}
+	}
+}
@@ -1,9 +1,11 @@
 package http
 
 import (
+	"crypto/tls"
 	"io"
 	"net"
 	"net/http"
+	neturl "net/url"
 	"syscall"
 	"time"
 
@@ -46,17 +48,39 @@ type roundTripLimiter struct {
 }
 
 func (l roundTripLimiter) RoundTrip(r *http.Request) (*http.Response, error) {
+	// the bytes of the requests and the responses
+	// are taken from the quota of the query, if any.
+	q := getQuota(r.Context())
+	if q != nil && r.ContentLength > 0 {
+		if err := q.take(r.ContentLength); err != nil {
+			if r.Body != nil {
+				_ = r.Body.Close()
+			}
+			return nil, err
+		}
+	}
 	response, err := l.RoundTripper.RoundTrip(r)
 	if err != nil {
 		return nil, err
 	}
 	response.Body = limitReadCloser(response.Body, l.size)
+	if q != nil {
+		response.Body = quotaReadCloser{ReadCloser: response.Body, quota: q}
+	}
 	return response, nil
 }
 
 // NewDefaultClient creates a client with sane defaults.
 func NewDefaultClient(urlValidator url.Validator) *http.Client {
+	return &http.Client{
+		Transport: newTransport(urlValidator, http.ProxyFromEnvironment, nil),
+	}
+}
 
+// NewDialer creates a dialer that validates the IPs of its connections
+// like the transports of the clients. The functions that connect to
+// HTTP servers without a client, such as to websockets, dial with it.
+func NewDialer(urlValidator url.Validator) *net.Dialer {
 	// Control is called after DNS lookup, but before the network connection is
 	// initiated.
 	control := func(network, address string, c syscall.RawConn) error {
@@ -69,30 +93,38 @@ func NewDefaultClient(urlValidator url.Validator) *http.Client {
 		return urlValidator.ValidateIP(ip)
 	}
 
-	dialer := &net.Dialer{
+	return &net.Dialer{
 		Timeout:   30 * time.Second,
 		KeepAlive: 30 * time.Second,
 		Control:   control,
 		// DualStack is deprecated
 	}
+}
+
+// newTransport creates a transport that validates the IPs
+// of its connections, with the proxy and the TLS configuration.
+func newTransport(urlValidator url.Validator, proxy func(*http.Request) (*neturl.URL, error), tlsConfig *tls.Config) *http.Transport {
+	dialer := NewDialer(urlValidator)
 
 	// These defaults are copied from http.DefaultTransport.
-	return &http.Client{
-		Transport: &http.Transport{
-			Proxy:                 http.ProxyFromEnvironment,
-			DialContext:           dialer.DialContext,
-			MaxIdleConns:          100,
-			IdleConnTimeout:       10 * time.Second,
-			TLSHandshakeTimeout:   10 * time.Second,
-			ExpectContinueTimeout: 1 * time.Second,
-			// Fields below are NOT part of Go's defaults
-			MaxIdleConnsPerHost: 100,
-		},
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       10 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		// Fields below are NOT part of Go's defaults
+		MaxIdleConnsPerHost: 100,
 	}
 }
 
 // NewLimitedDefaultClient creates a client with a limit on the response body size.
+// It does not retry the requests unless a call overrides
+// the DefaultRetryPolicy with WithRetryOverride.
 func NewLimitedDefaultClient(urlValidator url.Validator) *http.Client {
 	cli := NewDefaultClient(urlValidator)
+	cli.Transport = retryTransport{RoundTripper: cli.Transport, policy: DefaultRetryPolicy}
 	return LimitHTTPBody(*cli, maxResponseBody)
 }
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+type key int
+
+const (
+	maxQueryBytesKey key = iota
+	quotaKey
+)
+
+// InjectMaxQueryBytes will inject the maximum number of bytes that
+// the HTTP requests of each query may send and receive into the
+// dependency chain. A maximum that is not positive removes the limit.
+func InjectMaxQueryBytes(ctx context.Context, n int64) context.Context {
+	return context.WithValue(ctx, maxQueryBytesKey, n)
+}
+
+// WithQueryQuota returns a copy of the context with a quota for the bytes
+// that the HTTP requests of a query may send and receive, as configured
+// by InjectMaxQueryBytes. It is called once for each query when the
+// program is started.
+//
+// If no maximum has been configured, the context is returned unchanged.
+func WithQueryQuota(ctx context.Context) context.Context {
+	n, _ := ctx.Value(maxQueryBytesKey).(int64)
+	if n <= 0 {
+		return ctx
+	}
+	ctx = context.WithValue(ctx, quotaKey, &quota{remaining: n, max: n})
+	return InjectMaxQueryBytes(ctx, 0)
+}
+
+// quota counts the bytes that the requests of a query may still transfer.
+type quota struct {
+	mu        sync.Mutex
+	remaining int64
+	max       int64
+}
+
+// take takes n bytes from the quota
+// and reports an error if there are not enough.
+func (q *quota) take(n int64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n > q.remaining {
+		q.remaining = 0
+		return errors.Newf(codes.ResourceExhausted, "http requests of the query transferred more than the limit of %d bytes", q.max)
+	}
+	q.remaining -= n
+	return nil
+}
+
+func getQuota(ctx context.Context) *quota {
+	q, _ := ctx.Value(quotaKey).(*quota)
+	return q
+}
+
+// quotaReadCloser takes the bytes it reads from the quota of a query.
+type quotaReadCloser struct {
+	io.ReadCloser
+	quota *quota
+}
+
+func (r quotaReadCloser) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if qerr := r.quota.take(int64(n)); qerr != nil {
+		return 0, qerr
+	}
+	return n, err
+}
+
+// QuotaConn returns a connection that takes the bytes it reads and writes
+// from the quota of the query of the context, for the functions that
+// connect to HTTP servers without a client. Without a quota, the
+// connection is returned unchanged.
+func QuotaConn(ctx context.Context, conn net.Conn) net.Conn {
+	q := getQuota(ctx)
+	if q == nil {
+		return conn
+	}
+	return quotaConn{Conn: conn, quota: q}
+}
+
+// quotaConn takes the bytes it reads and writes from the quota of a query.
+type quotaConn struct {
+	net.Conn
+	quota *quota
+}
+
+func (c quotaConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if qerr := c.quota.take(int64(n)); qerr != nil {
+		return 0, qerr
+	}
+	return n, err
+}
+
+func (c quotaConn) Write(p []byte) (int, error) {
+	if err := c.quota.take(int64(len(p))); err != nil {
+		return 0, err
+	}
+	return c.Conn.Write(p)
+}
+
+// rateLimiter limits the rate of the requests to each host with a token bucket.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		rate:  rate,
+		burst: float64(burst),
+		now:   time.Now,
+		hosts: make(map[string]*bucket),
+	}
+}
+
+// reserve reserves a token to send a request to a host
+// and returns how long to wait before sending it.
+func (l *rateLimiter) reserve(host string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	b, ok := l.hosts[host]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.hosts[host] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / l.rate * float64(time.Second))
+}
+
+// cancel gives back a token that was reserved for a request that is not sent.
+func (l *rateLimiter) cancel(host string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if b, ok := l.hosts[host]; ok {
+		b.tokens++
+	}
+}
+
+// rateLimitTransport delays the requests to the hosts
+// that were sent requests above the rate of the limiter.
+type rateLimitTransport struct {
+	http.RoundTripper
+	limiter *rateLimiter
+}
+
+func (t rateLimitTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	host := strings.ToLower(r.URL.Host)
+	if wait := t.limiter.reserve(host); wait > 0 {
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-r.Context().Done():
+			timer.Stop()
+			t.limiter.cancel(host)
+			return nil, r.Context().Err()
+		}
+	}
+	return t.RoundTripper.RoundTrip(r)
+}
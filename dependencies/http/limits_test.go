@@ -0,0 +1,160 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	depsUrl "github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(2, 3)
+	l.now = func() time.Time { return now }
+
+	// the burst is sent at once, then one request every 500ms.
+	for i, want := range []time.Duration{0, 0, 0, 500 * time.Millisecond, time.Second} {
+		if got := l.reserve("a:80"); got != want {
+			t.Errorf("unexpected wait for request %d: want %v, got %v", i, want, got)
+		}
+	}
+	// the hosts have their own bucket.
+	if got := l.reserve("b:80"); got != 0 {
+		t.Errorf("unexpected wait for another host: %v", got)
+	}
+	// the tokens come back with time, up to the burst.
+	now = now.Add(10 * time.Second)
+	for i := 0; i < 3; i++ {
+		if got := l.reserve("a:80"); got != 0 {
+			t.Errorf("unexpected wait after the bucket is refilled: %v", got)
+		}
+	}
+	l.cancel("a:80")
+	if got := l.reserve("a:80"); got != 0 {
+		t.Errorf("unexpected wait after a canceled request: %v", got)
+	}
+}
+
+func TestNewClient_RateLimit(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(context.Background(), depsUrl.PassValidator{}, Config{RequestsPerSecond: 0.001, Burst: 1}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Get(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	// the next request waits for a long time, until it is canceled.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodGet, ts.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Do(req.WithContext(ctx)); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+		t.Errorf("expected the request to wait until the deadline, got %v", err)
+	}
+}
+
+func TestQueryQuota(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("0123456789"))
+	}))
+	defer ts.Close()
+	client := NewLimitedDefaultClient(depsUrl.PassValidator{})
+
+	ctx := InjectMaxQueryBytes(context.Background(), 25)
+	send := func(ctx context.Context, body string) error {
+		req, err := http.NewRequest(http.MethodPost, ts.URL, bytes.NewReader([]byte(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		defer func() { _ = resp.Body.Close() }()
+		_, err = ioutil.ReadAll(resp.Body)
+		return err
+	}
+
+	// without a quota, the requests are not limited.
+	for i := 0; i < 3; i++ {
+		if err := send(ctx, "body"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// each query has its own quota of 25 bytes.
+	for i := 0; i < 2; i++ {
+		qctx := WithQueryQuota(ctx)
+		if err := send(qctx, "body"); err != nil {
+			t.Fatal(err)
+		}
+		if err := send(qctx, "body"); errors.Code(err) != codes.ResourceExhausted {
+			t.Errorf("expected a resource exhausted error, got %v", err)
+		}
+		// the client wraps the errors of the transport.
+		if err := send(qctx, "b"); err == nil || !strings.Contains(err.Error(), "more than the limit") {
+			t.Errorf("expected the quota to stay exhausted, got %v", err)
+		}
+	}
+}
+
+func TestQuotaConn(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() { _ = server.Close() }()
+	go func() {
+		buf := make([]byte, 10)
+		for {
+			n, err := server.Read(buf)
+			if err != nil {
+				return
+			}
+			_, _ = server.Write(buf[:n])
+		}
+	}()
+
+	// without a quota, the connection is not wrapped.
+	if conn := QuotaConn(context.Background(), client); conn != client {
+		t.Fatal("expected the connection to be returned unchanged")
+	}
+
+	ctx := WithQueryQuota(InjectMaxQueryBytes(context.Background(), 15))
+	conn := QuotaConn(ctx, client)
+	defer func() { _ = conn.Close() }()
+	buf := make([]byte, 10)
+	// 5 bytes are written and 5 are read back.
+	if _, err := conn.Write([]byte("01234")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Read(buf); err != nil {
+		t.Fatal(err)
+	}
+	// the next write fits in the quota but reading it back does not.
+	if _, err := conn.Write([]byte("56789")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Read(buf); errors.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected a resource exhausted error, got %v", err)
+	}
+	if _, err := conn.Write([]byte("a")); errors.Code(err) != codes.ResourceExhausted {
+		t.Errorf("expected the quota to stay exhausted, got %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryStatusCodes are the status codes that are retried
+// when a RetryPolicy does not list any.
+var DefaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy describes how failed requests are retried.
+// A request fails if the client returns an error or
+// if the status code of the response is one of StatusCodes.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent,
+	// a request is not retried if it is less than 2.
+	MaxAttempts int `json:"maxAttempts,omitempty"`
+	// StatusCodes are the status codes that are retried,
+	// DefaultRetryStatusCodes if empty.
+	StatusCodes []int `json:"statusCodes,omitempty"`
+	// InitialBackoff is the maximum wait before the first retry.
+	// The maximum wait doubles with each retry
+	// and the actual wait is picked at random up to it.
+	InitialBackoff time.Duration `json:"-"`
+	// MaxBackoff caps the wait between two attempts.
+	MaxBackoff time.Duration `json:"-"`
+}
+
+// DefaultRetryPolicy is a policy that does not retry requests,
+// but that backs off sensibly once MaxAttempts is set.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    1,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     10 * time.Second,
+}
+
+// UnmarshalJSON reads a policy whose backoffs are durations
+// such as "100ms" under "backoff" and "maxBackoff".
+// The fields that are not set keep the values of DefaultRetryPolicy.
+func (p *RetryPolicy) UnmarshalJSON(data []byte) error {
+	type policy RetryPolicy
+	var v struct {
+		policy
+		Backoff    string `json:"backoff,omitempty"`
+		MaxBackoff string `json:"maxBackoff,omitempty"`
+	}
+	v.policy = policy(DefaultRetryPolicy)
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*p = RetryPolicy(v.policy)
+	for _, d := range []struct {
+		s string
+		v *time.Duration
+	}{
+		{s: v.Backoff, v: &p.InitialBackoff},
+		{s: v.MaxBackoff, v: &p.MaxBackoff},
+	} {
+		if d.s == "" {
+			continue
+		}
+		dur, err := time.ParseDuration(d.s)
+		if err != nil {
+			return err
+		}
+		*d.v = dur
+	}
+	return nil
+}
+
+func (p RetryPolicy) retryable(statusCode int) bool {
+	codes := p.StatusCodes
+	if len(codes) == 0 {
+		codes = DefaultRetryStatusCodes
+	}
+	for _, c := range codes {
+		if c == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns how long to wait before the given retry, starting at 1.
+// The wait is picked at random up to the exponential backoff
+// so that clients do not retry in lockstep.
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	max := p.InitialBackoff
+	for i := 1; i < retry && (p.MaxBackoff <= 0 || max < p.MaxBackoff); i++ {
+		max *= 2
+	}
+	if p.MaxBackoff > 0 && max > p.MaxBackoff {
+		max = p.MaxBackoff
+	}
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)) + 1)
+}
+
+type retryOverrideKey struct{}
+
+// WithRetryOverride returns a context whose requests are retried
+// with the policy of the client as changed by override.
+// This lets a single call, such as http.post, pick its own retries
+// while the other requests keep the policy of the client configuration.
+func WithRetryOverride(ctx context.Context, override func(p *RetryPolicy)) context.Context {
+	if override == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, retryOverrideKey{}, override)
+}
+
+// retryTransport retries the requests that it sends with the RoundTripper
+// per its policy and the override of the context of the request, if any.
+//
+// Requests with a body are only retried if they define GetBody,
+// which http.NewRequest does for the common readers.
+// When the response has a Retry-After header with a number of seconds,
+// the transport waits that long instead, up to MaxBackoff.
+type retryTransport struct {
+	http.RoundTripper
+	policy RetryPolicy
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	policy := t.policy
+	if override, ok := req.Context().Value(retryOverrideKey{}).(func(p *RetryPolicy)); ok {
+		override(&policy)
+	}
+	if policy.MaxAttempts < 2 {
+		return t.RoundTripper.RoundTrip(req)
+	}
+
+	// a body that cannot be read again is only sent once
+	retryable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	for attempt := 1; ; attempt++ {
+		resp, err := t.RoundTripper.RoundTrip(req)
+		if attempt >= policy.MaxAttempts || (err == nil && !policy.retryable(resp.StatusCode)) || !retryable {
+			return resp, err
+		}
+		if err != nil && req.Context().Err() != nil {
+			// the request was canceled or timed out
+			return resp, err
+		}
+		if req.Body != nil && req.Body != http.NoBody {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			// the caller's request is left alone
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		wait := policy.backoff(attempt)
+		if resp != nil {
+			if d, ok := retryAfter(resp); ok {
+				wait = d
+				if policy.MaxBackoff > 0 && wait > policy.MaxBackoff {
+					wait = policy.MaxBackoff
+				}
+			}
+			// read the body so that the connection can be reused
+			_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxResponseBody))
+			_ = resp.Body.Close()
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header with a number of seconds.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	s := resp.Header.Get("Retry-After")
+	if s == "" {
+		return 0, false
+	}
+	secs, err := strconv.Atoi(s)
+	if err != nil || secs < 0 {
+		return 0, false
+	}
+	return time.Duration(secs) * time.Second, true
+}
+
+// sleep waits for d or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,221 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/dependencies/url"
+)
+
+func TestRetryTransport(t *testing.T) {
+	var attempts int32
+	var bodies []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client := newRetryClient(RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+	})
+	req, err := http.NewRequest("POST", ts.URL, bytes.NewReader([]byte("body")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if want, got := http.StatusNoContent, resp.StatusCode; want != got {
+		t.Errorf("unexpected status code want: %d got: %d", want, got)
+	}
+	if want, got := int32(3), atomic.LoadInt32(&attempts); want != got {
+		t.Errorf("unexpected number of attempts want: %d got: %d", want, got)
+	}
+	for _, body := range bodies {
+		if body != "body" {
+			t.Errorf("the body was not sent again on retry, got %q", body)
+		}
+	}
+}
+
+func TestRetryTransport_MaxAttempts(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		name     string
+		policy   RetryPolicy
+		attempts int32
+	}{
+		{
+			name:     "status code not retried by default",
+			policy:   RetryPolicy{MaxAttempts: 3},
+			attempts: 1,
+		},
+		{
+			name:     "retried status code",
+			policy:   RetryPolicy{MaxAttempts: 3, StatusCodes: []int{http.StatusInternalServerError}},
+			attempts: 3,
+		},
+		{
+			name:     "no retries",
+			policy:   DefaultRetryPolicy,
+			attempts: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			atomic.StoreInt32(&attempts, 0)
+			resp, err := newRetryClient(tc.policy).Do(mustRequest(t, ts.URL))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if want, got := http.StatusInternalServerError, resp.StatusCode; want != got {
+				t.Errorf("unexpected status code want: %d got: %d", want, got)
+			}
+			if want, got := tc.attempts, atomic.LoadInt32(&attempts); want != got {
+				t.Errorf("unexpected number of attempts want: %d got: %d", want, got)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_Canceled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := newRetryClient(RetryPolicy{MaxAttempts: 3, MaxBackoff: time.Minute})
+	start := time.Now()
+	if _, err := client.Do(mustRequest(t, ts.URL).WithContext(ctx)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the deadline to be exceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("the client did not stop waiting when the context was done, waited %v", elapsed)
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+	for retry, max := range map[int]time.Duration{
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		3: 300 * time.Millisecond,
+		8: 300 * time.Millisecond,
+	} {
+		for i := 0; i < 100; i++ {
+			if d := p.backoff(retry); d <= 0 || d > max {
+				t.Fatalf("unexpected backoff of retry %d: %v is not in (0, %v]", retry, d, max)
+			}
+		}
+	}
+}
+
+func TestWithRetryOverride(t *testing.T) {
+	var attempts int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(context.Background(), url.PassValidator{}, Config{
+		Retry: RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name     string
+		override func(p *RetryPolicy)
+		attempts int32
+	}{
+		{
+			name:     "client policy",
+			attempts: 3,
+		},
+		{
+			name: "more attempts",
+			override: func(p *RetryPolicy) {
+				p.MaxAttempts = 4
+			},
+			attempts: 4,
+		},
+		{
+			name: "other status codes",
+			override: func(p *RetryPolicy) {
+				p.StatusCodes = []int{http.StatusInternalServerError}
+			},
+			attempts: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			atomic.StoreInt32(&attempts, 0)
+			ctx := WithRetryOverride(context.Background(), tc.override)
+			resp, err := client.Do(mustRequest(t, ts.URL).WithContext(ctx))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = resp.Body.Close()
+			if want, got := tc.attempts, atomic.LoadInt32(&attempts); want != got {
+				t.Errorf("unexpected number of attempts want: %d got: %d", want, got)
+			}
+		})
+	}
+}
+
+func TestRetryPolicy_UnmarshalJSON(t *testing.T) {
+	var c Config
+	if err := json.Unmarshal([]byte(`{"retry": {"maxAttempts": 3, "statusCodes": [500], "maxBackoff": "1s"}}`), &c); err != nil {
+		t.Fatal(err)
+	}
+	want := RetryPolicy{
+		MaxAttempts:    3,
+		StatusCodes:    []int{500},
+		InitialBackoff: DefaultRetryPolicy.InitialBackoff,
+		MaxBackoff:     time.Second,
+	}
+	if !cmp.Equal(want, c.Retry) {
+		t.Errorf("unexpected retry policy -want/+got:\n%s", cmp.Diff(want, c.Retry))
+	}
+}
+
+func newRetryClient(policy RetryPolicy) *http.Client {
+	return &http.Client{Transport: retryTransport{RoundTripper: http.DefaultTransport, policy: policy}}
+}
+
+func mustRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
@@ -0,0 +1,122 @@
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+const DefaultTimeout = 10 * time.Second
+
+type key int
+
+const dialerKey key = iota
+
+// Inject will inject this Dialer into the dependency chain.
+func Inject(ctx context.Context, dialer Dialer) context.Context {
+	return context.WithValue(ctx, dialerKey, dialer)
+}
+
+// Dependency will inject the Dialer into the dependency chain.
+type Dependency struct {
+	Dialer Dialer
+}
+
+// Inject will inject the Dialer into the dependency chain.
+func (d Dependency) Inject(ctx context.Context) context.Context {
+	return Inject(ctx, d.Dialer)
+}
+
+// GetDialer will return the Dialer for the current context.
+// If no Dialer has been injected into the dependencies,
+// this will return a default provider.
+func GetDialer(ctx context.Context) Dialer {
+	d := ctx.Value(dialerKey)
+	if d == nil {
+		return DefaultDialer{}
+	}
+	return d.(Dialer)
+}
+
+// The SASL mechanisms that can be used to authenticate with the brokers.
+const (
+	SASLPlain       = "plain"
+	SASLSCRAMSHA256 = "scram-sha-256"
+	SASLSCRAMSHA512 = "scram-sha-512"
+)
+
+// Options contains the options of the connections to the brokers
+// that are set by the queries.
+type Options struct {
+	// TLS enables TLS connections.
+	TLS bool
+	// SASLMechanism is the SASL mechanism, authentication is disabled if it is empty.
+	SASLMechanism string
+	Username      string
+	Password      string
+	Timeout       time.Duration
+}
+
+// Dialer provides the dialers that connect to the kafka brokers.
+type Dialer interface {
+	// Dialer returns a kafka dialer that connects with the given options.
+	Dialer(ctx context.Context, options Options) (*kafka.Dialer, error)
+}
+
+// DefaultDialer is the default dialer.
+type DefaultDialer struct {
+	// TLSConfig is the configuration of the TLS connections,
+	// eg. with the certificate authorities and the client certificates of the deployment.
+	// The system certificate authorities are used if it is nil.
+	TLSConfig *tls.Config
+}
+
+func (d DefaultDialer) Dialer(ctx context.Context, options Options) (*kafka.Dialer, error) {
+	dialer := &kafka.Dialer{
+		Timeout:   options.Timeout,
+		DualStack: true,
+	}
+	if dialer.Timeout <= 0 {
+		dialer.Timeout = DefaultTimeout
+	}
+	if options.TLS {
+		if d.TLSConfig != nil {
+			dialer.TLS = d.TLSConfig.Clone()
+		} else {
+			dialer.TLS = &tls.Config{}
+		}
+	}
+	if options.SASLMechanism != "" {
+		mechanism, err := NewSASLMechanism(options.SASLMechanism, options.Username, options.Password)
+		if err != nil {
+			return nil, err
+		}
+		dialer.SASLMechanism = mechanism
+	}
+	return dialer, nil
+}
+
+// NewSASLMechanism returns the SASL mechanism with the given name.
+func NewSASLMechanism(name, username, password string) (sasl.Mechanism, error) {
+	if username == "" {
+		return nil, errors.New(codes.Invalid, "a username is required for SASL authentication")
+	}
+	switch strings.ToLower(name) {
+	case SASLPlain:
+		return plain.Mechanism{Username: username, Password: password}, nil
+	case SASLSCRAMSHA256:
+		return scram.Mechanism(scram.SHA256, username, password)
+	case SASLSCRAMSHA512:
+		return scram.Mechanism(scram.SHA512, username, password)
+	default:
+		return nil, errors.Newf(codes.Invalid, "unsupported SASL mechanism %q, must be one of %q, %q or %q", name, SASLPlain, SASLSCRAMSHA256, SASLSCRAMSHA512)
+	}
+}
@@ -0,0 +1,74 @@
+package kafka_test
+
+import (
+	"context"
+	"crypto/tls"
+	"testing"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/kafka"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestDefaultDialer(t *testing.T) {
+	ctx := context.Background()
+	config := &tls.Config{ServerName: "kafka.example.com"}
+	d, err := kafka.DefaultDialer{TLSConfig: config}.Dialer(ctx, kafka.Options{
+		TLS:           true,
+		SASLMechanism: "SCRAM-SHA-512",
+		Username:      "user",
+		Password:      "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.TLS == nil || d.TLS == config || d.TLS.ServerName != config.ServerName {
+		t.Errorf("expected a copy of the TLS configuration, got %v", d.TLS)
+	}
+	if want, got := "SCRAM-SHA-512", d.SASLMechanism.Name(); want != got {
+		t.Errorf("unexpected SASL mechanism want: %s got: %s", want, got)
+	}
+	if want, got := kafka.DefaultTimeout, d.Timeout; want != got {
+		t.Errorf("unexpected timeout want: %v got: %v", want, got)
+	}
+
+	d, err = kafka.GetDialer(ctx).Dialer(ctx, kafka.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.TLS != nil || d.SASLMechanism != nil {
+		t.Errorf("expected a dialer without TLS or SASL, got %+v", d)
+	}
+}
+
+func TestNewSASLMechanism(t *testing.T) {
+	for _, tc := range []struct {
+		name, mechanism, username string
+		want                      string
+		wantErr                   bool
+	}{
+		{name: "plain", mechanism: "plain", username: "user", want: "PLAIN"},
+		{name: "scram", mechanism: "scram-sha-256", username: "user", want: "SCRAM-SHA-256"},
+		{name: "no username", mechanism: "plain", wantErr: true},
+		{name: "unsupported", mechanism: "gssapi", username: "user", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := kafka.NewSASLMechanism(tc.mechanism, tc.username, "secret")
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				if want, got := codes.Invalid, errors.Code(err); want != got {
+					t.Errorf("unexpected error code want: %v got: %v", want, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Name(); got != tc.want {
+				t.Errorf("unexpected mechanism want: %s got: %s", tc.want, got)
+			}
+		})
+	}
+}
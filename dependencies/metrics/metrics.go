@@ -0,0 +1,149 @@
+// Package metrics provides a dependency for collecting Prometheus
+// metrics about the queries that are compiled and executed.
+//
+// The metrics are created with New and registered on a
+// prometheus.Registerer that is provided by the embedder.
+// They are only collected for queries whose context
+// contains the metrics, see Inject.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "flux"
+
+// The values of the result label of the queries finished metric.
+const (
+	ResultSuccess  = "success"
+	ResultError    = "error"
+	ResultCanceled = "canceled"
+)
+
+// Metrics are the metrics collected for queries.
+// The methods of a nil *Metrics do nothing.
+type Metrics struct {
+	queriesStarted  prometheus.Counter
+	queriesFinished *prometheus.CounterVec
+	compileDuration prometheus.Histogram
+	executeDuration prometheus.Histogram
+	maxAllocated    prometheus.Histogram
+	operationRows   *prometheus.CounterVec
+}
+
+// New creates the metrics and registers them with the registerer.
+func New(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		queriesStarted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "queries_started_total",
+			Help:      "Number of queries that were started.",
+		}),
+		queriesFinished: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "queries_finished_total",
+			Help:      "Number of queries that finished, by result.",
+		}, []string{"result"}),
+		compileDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "compile_duration_seconds",
+			Help:      "Time spent evaluating and planning a query before it is executed.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 8),
+		}),
+		executeDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "execute_duration_seconds",
+			Help:      "Time spent executing a query, from when it is started until it is done.",
+			Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+		}),
+		maxAllocated: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "query_max_allocated_bytes",
+			Help:      "The largest amount of memory allocated by a query at one time.",
+			Buckets:   prometheus.ExponentialBuckets(1024, 8, 9),
+		}),
+		operationRows: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "operation_rows_total",
+			Help:      "Number of rows received by each kind of operation.",
+		}, []string{"operation"}),
+	}
+	for _, c := range []prometheus.Collector{
+		m.queriesStarted,
+		m.queriesFinished,
+		m.compileDuration,
+		m.executeDuration,
+		m.maxAllocated,
+		m.operationRows,
+	} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+type key int
+
+const metricsKey key = iota
+
+// Inject will inject the metrics into the dependency chain.
+func (m *Metrics) Inject(ctx context.Context) context.Context {
+	if m == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, metricsKey, m)
+}
+
+// Get returns the metrics in the context.
+// It returns nil if no metrics have been injected.
+func Get(ctx context.Context) *Metrics {
+	m, _ := ctx.Value(metricsKey).(*Metrics)
+	return m
+}
+
+// QueryStarted records that a query was started.
+func (m *Metrics) QueryStarted() {
+	if m == nil {
+		return
+	}
+	m.queriesStarted.Inc()
+}
+
+// QueryFinished records that a query finished after running for the
+// duration and the largest amount of memory that it allocated.
+func (m *Metrics) QueryFinished(err error, d time.Duration, maxAllocated int64) {
+	if m == nil {
+		return
+	}
+	result := ResultSuccess
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		result = ResultCanceled
+	} else if err != nil {
+		result = ResultError
+	}
+	m.queriesFinished.WithLabelValues(result).Inc()
+	m.executeDuration.Observe(d.Seconds())
+	m.maxAllocated.Observe(float64(maxAllocated))
+}
+
+// ObserveCompile records the time spent compiling a query.
+func (m *Metrics) ObserveCompile(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.compileDuration.Observe(d.Seconds())
+}
+
+// OperationRows returns the counter for the rows received by the
+// kind of operation. It returns nil if m is nil.
+func (m *Metrics) OperationRows(op string) prometheus.Counter {
+	if m == nil {
+		return nil
+	}
+	return m.operationRows.WithLabelValues(op)
+}
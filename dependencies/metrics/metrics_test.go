@@ -0,0 +1,84 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/dependencies/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := metrics.New(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := m.Inject(context.Background())
+	if got := metrics.Get(ctx); got != m {
+		t.Fatal("expected the metrics to be injected into the context")
+	}
+
+	m.QueryStarted()
+	m.QueryStarted()
+	m.QueryStarted()
+	m.QueryFinished(nil, time.Second, 1024)
+	m.QueryFinished(errors.New("expected"), time.Second, 1024)
+	m.QueryFinished(context.Canceled, time.Second, 1024)
+	m.ObserveCompile(time.Millisecond)
+	m.OperationRows("*universe.mapTransformation").Add(10)
+
+	if got, want := testutil.ToFloat64(m.OperationRows("*universe.mapTransformation")), 10.0; got != want {
+		t.Errorf("unexpected number of rows -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	mfs, err := reg.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := len(mfs), 6; got != want {
+		t.Errorf("unexpected number of metrics -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+	for _, mf := range mfs {
+		switch mf.GetName() {
+		case "flux_queries_started_total":
+			if got, want := mf.Metric[0].Counter.GetValue(), 3.0; got != want {
+				t.Errorf("unexpected queries started -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		case "flux_queries_finished_total":
+			for _, metric := range mf.Metric {
+				if got, want := metric.Counter.GetValue(), 1.0; got != want {
+					t.Errorf("unexpected queries finished for %v -want/+got:\n\t- %v\n\t+ %v", metric.Label, want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestMetrics_Nil(t *testing.T) {
+	m := metrics.Get(context.Background())
+	if m != nil {
+		t.Fatal("expected no metrics")
+	}
+	// None of these should panic.
+	m.QueryStarted()
+	m.QueryFinished(nil, time.Second, 0)
+	m.ObserveCompile(time.Second)
+	if c := m.OperationRows("op"); c != nil {
+		t.Error("expected no counter")
+	}
+}
+
+func TestNew_AlreadyRegistered(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	if _, err := metrics.New(reg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := metrics.New(reg); err == nil {
+		t.Fatal("expected an error when registering the metrics twice")
+	}
+}
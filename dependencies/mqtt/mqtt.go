@@ -2,7 +2,10 @@ package mqtt
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"io"
+	"net/url"
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
@@ -37,21 +40,30 @@ func (d Dependency) Inject(ctx context.Context) context.Context {
 
 // GetDialer will return the Dialer for the current context.
 // If no Dialer has been injected into the dependencies,
-// this will return a default provider.
+// this will return a default provider that shares the connections
+// of the DefaultDialer between the calls.
 func GetDialer(ctx context.Context) Dialer {
 	d := ctx.Value(clientKey)
 	if d == nil {
-		return DefaultDialer{}
+		return defaultPool
 	}
 	return d.(Dialer)
 }
 
+var defaultPool = NewPoolDialer(DefaultDialer{}, DefaultIdleTimeout)
+
 // Options contains additional options for configuring the mqtt client.
 type Options struct {
 	ClientID string
 	Username string
 	Password string
 	Timeout  time.Duration
+
+	// CACert, ClientCert and ClientKey are PEM encoded.
+	// They configure the TLS connections to the brokers with the ssl, tls, tcps or wss schemes.
+	CACert     string
+	ClientCert string
+	ClientKey  string
 }
 
 // Dialer provides a method to connect a client to one or more mqtt brokers.
@@ -69,7 +81,12 @@ type Client interface {
 }
 
 // DefaultDialer is the default dialer that uses the default mqtt client.
-type DefaultDialer struct{}
+type DefaultDialer struct {
+	// TLSConfig is the configuration of the TLS connections, eg. with the certificate
+	// authorities and the client certificates of the deployment.
+	// The certificates of the options are added to a copy of it.
+	TLSConfig *tls.Config
+}
 
 func (d DefaultDialer) Dial(ctx context.Context, brokers []string, options Options) (Client, error) {
 	if len(brokers) == 0 {
@@ -80,15 +97,8 @@ func (d DefaultDialer) Dial(ctx context.Context, brokers []string, options Optio
 		opts.AddBroker(broker)
 	}
 
-	deps := flux.GetDependencies(ctx)
-	if url, err := deps.URLValidator(); err != nil {
+	if err := validateBrokers(ctx, opts.Servers); err != nil {
 		return nil, err
-	} else {
-		for _, broker := range opts.Servers {
-			if err := url.Validate(broker); err != nil {
-				return nil, err
-			}
-		}
 	}
 
 	if options.ClientID != "" {
@@ -110,6 +120,14 @@ func (d DefaultDialer) Dial(ctx context.Context, brokers []string, options Optio
 		}
 	}
 
+	tlsConfig, err := d.tlsConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		opts.SetTLSConfig(tlsConfig)
+	}
+
 	client := mqtt.NewClient(opts)
 	if token := client.Connect(); token.Wait() && token.Error() != nil {
 		return nil, token.Error()
@@ -120,6 +138,33 @@ func (d DefaultDialer) Dial(ctx context.Context, brokers []string, options Optio
 	}, nil
 }
 
+// tlsConfig returns the TLS configuration with the certificates of the options,
+// or nil if there is no TLS configuration.
+func (d DefaultDialer) tlsConfig(options Options) (*tls.Config, error) {
+	if d.TLSConfig == nil && options.CACert == "" && options.ClientCert == "" && options.ClientKey == "" {
+		return nil, nil
+	}
+	config := &tls.Config{}
+	if d.TLSConfig != nil {
+		config = d.TLSConfig.Clone()
+	}
+	if options.CACert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(options.CACert)) {
+			return nil, errors.New(codes.Invalid, "invalid mqtt CA certificate, it must be PEM encoded")
+		}
+		config.RootCAs = pool
+	}
+	if options.ClientCert != "" || options.ClientKey != "" {
+		cert, err := tls.X509KeyPair([]byte(options.ClientCert), []byte(options.ClientKey))
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "invalid mqtt client certificate")
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
 type defaultClient struct {
 	client  mqtt.Client
 	timeout time.Duration
@@ -140,6 +185,19 @@ func (d *defaultClient) Close() error {
 	return nil
 }
 
+func validateBrokers(ctx context.Context, brokers []*url.URL) error {
+	validator, err := flux.GetDependencies(ctx).URLValidator()
+	if err != nil {
+		return err
+	}
+	for _, broker := range brokers {
+		if err := validator.Validate(broker); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ErrorDialer is the default dialer that uses the default mqtt client.
 type ErrorDialer struct{}
 
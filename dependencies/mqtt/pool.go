@@ -0,0 +1,159 @@
+package mqtt
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// DefaultIdleTimeout is the time after which the unused connections of the default dialer are closed.
+const DefaultIdleTimeout = time.Minute
+
+// PoolDialer shares the clients of a Dialer between the calls with the same brokers and options,
+// since the brokers disconnect a client when another client connects with the same client ID.
+// The clients are closed when they have not been used for the idle timeout,
+// or when they fail to publish.
+type PoolDialer struct {
+	dialer      Dialer
+	idleTimeout time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+}
+
+// NewPoolDialer returns a PoolDialer that shares the clients of the dialer.
+func NewPoolDialer(dialer Dialer, idleTimeout time.Duration) *PoolDialer {
+	return &PoolDialer{
+		dialer:      dialer,
+		idleTimeout: idleTimeout,
+		clients:     make(map[string]*pooledClient),
+	}
+}
+
+type pooledClient struct {
+	Client
+	key   string
+	refs  int
+	timer *time.Timer
+}
+
+func (p *PoolDialer) Dial(ctx context.Context, brokers []string, options Options) (Client, error) {
+	// the brokers are validated before sharing a client, since the validator depends on the context
+	urls := make([]*url.URL, 0, len(brokers))
+	for _, broker := range brokers {
+		u, err := url.Parse(broker)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "invalid mqtt broker %q", broker)
+		}
+		urls = append(urls, u)
+	}
+	if err := validateBrokers(ctx, urls); err != nil {
+		return nil, err
+	}
+
+	key, err := json.Marshal(struct {
+		Brokers []string
+		Options Options
+	}{Brokers: brokers, Options: options})
+	if err != nil {
+		return nil, err
+	}
+	if c := p.acquire(string(key)); c != nil {
+		return &poolHandle{pool: p, client: c}, nil
+	}
+
+	client, err := p.dialer.Dial(ctx, brokers, options)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.clients[string(key)]
+	if ok {
+		// another call connected a client in the meantime
+		_ = client.Close()
+		p.retain(c)
+	} else {
+		c = &pooledClient{Client: client, key: string(key), refs: 1}
+		p.clients[c.key] = c
+	}
+	return &poolHandle{pool: p, client: c}, nil
+}
+
+func (p *PoolDialer) acquire(key string) *pooledClient {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	c, ok := p.clients[key]
+	if !ok {
+		return nil
+	}
+	p.retain(c)
+	return c
+}
+
+// retain adds a reference to a client, p.mu must be held.
+func (p *PoolDialer) retain(c *pooledClient) {
+	c.refs++
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+}
+
+func (p *PoolDialer) release(c *pooledClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if c.refs--; c.refs > 0 {
+		return
+	}
+	if p.clients[c.key] != c {
+		// the client has been evicted
+		_ = c.Client.Close()
+		return
+	}
+	c.timer = time.AfterFunc(p.idleTimeout, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if c.refs == 0 && p.clients[c.key] == c {
+			delete(p.clients, c.key)
+			_ = c.Client.Close()
+		}
+	})
+}
+
+// evict removes a client from the pool, it is closed when it is released.
+func (p *PoolDialer) evict(c *pooledClient) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.clients[c.key] == c {
+		delete(p.clients, c.key)
+	}
+}
+
+// poolHandle is a reference to a shared client, that is released when it is closed.
+type poolHandle struct {
+	pool   *PoolDialer
+	client *pooledClient
+	once   sync.Once
+}
+
+func (h *poolHandle) Publish(ctx context.Context, topic string, qos byte, retain bool, payload interface{}) error {
+	err := h.client.Publish(ctx, topic, qos, retain, payload)
+	if err != nil {
+		// the connection may be broken, the next calls use a new client
+		h.pool.evict(h.client)
+	}
+	return err
+}
+
+func (h *poolHandle) Close() error {
+	h.once.Do(func() {
+		h.pool.release(h.client)
+	})
+	return nil
+}
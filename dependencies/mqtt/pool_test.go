@@ -0,0 +1,145 @@
+package mqtt_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/mqtt"
+	"github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// countingDialer counts the clients that are connected and closed.
+type countingDialer struct {
+	mu     sync.Mutex
+	dials  int
+	closed int
+	fail   bool
+}
+
+func (d *countingDialer) Dial(ctx context.Context, brokers []string, options mqtt.Options) (mqtt.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dials++
+	return &countingClient{dialer: d}, nil
+}
+
+func (d *countingDialer) counts() (int, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.dials, d.closed
+}
+
+type countingClient struct {
+	dialer *countingDialer
+}
+
+func (c *countingClient) Publish(ctx context.Context, topic string, qos byte, retain bool, payload interface{}) error {
+	c.dialer.mu.Lock()
+	defer c.dialer.mu.Unlock()
+	if c.dialer.fail {
+		return errors.New(codes.Unavailable, "connection lost")
+	}
+	return nil
+}
+
+func (c *countingClient) Close() error {
+	c.dialer.mu.Lock()
+	defer c.dialer.mu.Unlock()
+	c.dialer.closed++
+	return nil
+}
+
+func TestPoolDialer(t *testing.T) {
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	dialer := &countingDialer{}
+	pool := mqtt.NewPoolDialer(dialer, 50*time.Millisecond)
+	brokers := []string{"tcp://localhost:1883"}
+	options := mqtt.Options{ClientID: "flux"}
+
+	c1, err := pool.Dial(ctx, brokers, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c2, err := pool.Dial(ctx, brokers, options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c1.Publish(ctx, "topic", 0, false, "message"); err != nil {
+		t.Fatal(err)
+	}
+	_ = c1.Close()
+	_ = c2.Close()
+	// a client with other options is not shared
+	c3, err := pool.Dial(ctx, brokers, mqtt.Options{ClientID: "other"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = c3.Close()
+	if dials, closed := dialer.counts(); dials != 2 || closed != 0 {
+		t.Errorf("expected 2 clients and none closed, got %d clients and %d closed", dials, closed)
+	}
+
+	// the idle clients are closed after the timeout
+	time.Sleep(200 * time.Millisecond)
+	if _, closed := dialer.counts(); closed != 2 {
+		t.Errorf("expected the idle clients to be closed, got %d closed", closed)
+	}
+}
+
+func TestPoolDialer_Evict(t *testing.T) {
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	dialer := &countingDialer{fail: true}
+	pool := mqtt.NewPoolDialer(dialer, time.Hour)
+	brokers := []string{"tcp://localhost:1883"}
+
+	c, err := pool.Dial(ctx, brokers, mqtt.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Publish(ctx, "topic", 0, false, "message"); err == nil {
+		t.Fatal("expected an error")
+	}
+	_ = c.Close()
+	if _, closed := dialer.counts(); closed != 1 {
+		t.Errorf("expected the failed client to be closed, got %d closed", closed)
+	}
+	c, err = pool.Dial(ctx, brokers, mqtt.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Close()
+	if dials, _ := dialer.counts(); dials != 2 {
+		t.Errorf("expected a new client, got %d clients", dials)
+	}
+}
+
+func TestPoolDialer_Validation(t *testing.T) {
+	deps := flux.NewDefaultDependencies()
+	deps.Deps.URLValidator = url.PrivateIPValidator{}
+	ctx := deps.Inject(context.Background())
+	pool := mqtt.NewPoolDialer(&countingDialer{}, time.Hour)
+	if _, err := pool.Dial(ctx, []string{"tcp://127.0.0.1:1883"}, mqtt.Options{}); err == nil {
+		t.Error("expected a validation error for a private broker")
+	}
+}
+
+func TestDefaultDialer_InvalidCertificates(t *testing.T) {
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	for _, options := range []mqtt.Options{
+		{CACert: "not a certificate"},
+		{ClientCert: "not a certificate", ClientKey: "not a key"},
+	} {
+		_, err := mqtt.DefaultDialer{}.Dial(ctx, []string{"ssl://localhost:8883"}, options)
+		if err == nil {
+			t.Fatal("expected an error")
+		}
+		if want, got := codes.Invalid, errors.Code(err); want != got {
+			t.Errorf("unexpected error code want: %v got: %v", want, got)
+		}
+	}
+}
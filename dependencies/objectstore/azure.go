@@ -0,0 +1,216 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	fluxhttp "github.com/influxdata/flux/dependencies/http"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// azureVersion is the version of the Blob service REST API.
+const azureVersion = "2020-04-08"
+
+// azureBucket reads the blobs of an Azure Blob Storage container with the REST API.
+// Requests are authorized with the shared key of the storage account or with a SAS token.
+type azureBucket struct {
+	client    fluxhttp.Client
+	account   string
+	key       []byte
+	sas       url.Values
+	container string
+	endpoint  *url.URL
+	now       func() time.Time
+}
+
+func newAzureBucket(ctx context.Context, config Config, useEnv bool) (*azureBucket, error) {
+	client, err := flux.GetDependencies(ctx).HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	creds := config.Credentials
+	if useEnv && creds.IsZero() {
+		creds = Credentials{
+			AccessKey:    os.Getenv("AZURE_STORAGE_ACCOUNT"),
+			SecretKey:    os.Getenv("AZURE_STORAGE_KEY"),
+			SessionToken: os.Getenv("AZURE_STORAGE_SAS_TOKEN"),
+		}
+	}
+	if creds.AccessKey == "" {
+		return nil, errors.New(codes.Invalid, "the storage account name is required to read azure blobs, set it as the access key")
+	}
+
+	b := &azureBucket{
+		client:    client,
+		account:   creds.AccessKey,
+		container: config.Bucket,
+		now:       time.Now,
+	}
+	if creds.SecretKey != "" {
+		if b.key, err = base64.StdEncoding.DecodeString(creds.SecretKey); err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "invalid azure storage account key")
+		}
+	}
+	if creds.SessionToken != "" {
+		if b.sas, err = url.ParseQuery(strings.TrimPrefix(creds.SessionToken, "?")); err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "invalid azure SAS token")
+		}
+	}
+
+	defaultEndpoint := fmt.Sprintf("https://%s.blob.core.windows.net", b.account)
+	if b.endpoint, err = parseEndpoint(ctx, config.Endpoint, defaultEndpoint); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// blobURL returns the URL of a blob, or of the container if name is empty.
+func (b *azureBucket) blobURL(name string, query url.Values) *url.URL {
+	u := *b.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + b.container
+	if name != "" {
+		u.Path += "/" + name
+	}
+	u.RawPath = escapePath(u.Path)
+	if b.sas != nil {
+		if query == nil {
+			query = url.Values{}
+		}
+		for k, vs := range b.sas {
+			query[k] = vs
+		}
+	}
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+func (b *azureBucket) get(ctx context.Context, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid azure request")
+	}
+	req.Header.Set("X-Ms-Version", azureVersion)
+	req.Header.Set("X-Ms-Date", b.now().UTC().Format(http.TimeFormat))
+	if b.key != nil && b.sas == nil {
+		b.sign(req)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Unavailable, "azure request failed")
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, xmlError(resp, "azure")
+	}
+	return resp, nil
+}
+
+func (b *azureBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	marker := ""
+	for {
+		query := url.Values{"restype": {"container"}, "comp": {"list"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+		resp, err := b.get(ctx, b.blobURL("", query))
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Blobs []struct {
+				Name string
+			} `xml:"Blobs>Blob"`
+			NextMarker string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Internal, "failed to decode the azure blob list")
+		}
+		for _, blob := range result.Blobs {
+			keys = append(keys, blob.Name)
+		}
+		if result.NextMarker == "" {
+			break
+		}
+		marker = result.NextMarker
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b *azureBucket) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := b.get(ctx, b.blobURL(key, nil))
+	if err != nil {
+		return nil, errors.Wrapf(err, codes.Inherit, "failed to read azure blob %q", key)
+	}
+	return newLengthCheckReader(resp, key), nil
+}
+
+// sign authorizes a request with the shared key of the storage account.
+// See https://docs.microsoft.com/en-us/rest/api/storageservices/authorize-with-shared-key.
+func (b *azureBucket) sign(req *http.Request) {
+	var headers []string
+	for k := range req.Header {
+		if k := strings.ToLower(k); strings.HasPrefix(k, "x-ms-") {
+			headers = append(headers, k)
+		}
+	}
+	sort.Strings(headers)
+	var canonicalHeaders strings.Builder
+	for _, k := range headers {
+		canonicalHeaders.WriteString(k + ":" + strings.TrimSpace(req.Header.Get(k)) + "\n")
+	}
+
+	var resource strings.Builder
+	resource.WriteString("/" + b.account + req.URL.EscapedPath())
+	query := req.URL.Query()
+	params := make([]string, 0, len(query))
+	for k := range query {
+		params = append(params, k)
+	}
+	sort.Strings(params)
+	for _, k := range params {
+		vs := append([]string(nil), query[k]...)
+		sort.Strings(vs)
+		resource.WriteString("\n" + strings.ToLower(k) + ":" + strings.Join(vs, ","))
+	}
+
+	stringToSign := strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		"", // Content-Length, empty for requests without a body
+		req.Header.Get("Content-Md5"),
+		req.Header.Get("Content-Type"),
+		"", // Date, x-ms-date is used instead
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+		canonicalHeaders.String() + resource.String(),
+	}, "\n")
+
+	h := hmac.New(sha256.New, b.key)
+	_, _ = h.Write([]byte(stringToSign))
+	signature := base64.StdEncoding.EncodeToString(h.Sum(nil))
+	req.Header.Set("Authorization", "SharedKey "+b.account+":"+signature)
+}
@@ -0,0 +1,146 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+)
+
+func TestAzureSign(t *testing.T) {
+	b := &azureBucket{
+		account: "acct",
+		key:     []byte("0123456789abcdef0123456789abcdef"),
+	}
+	for _, tc := range []struct {
+		url  string
+		want string
+	}{
+		{
+			url:  "https://acct.blob.core.windows.net/container/dir/a%20b%2Bc.csv",
+			want: "SharedKey acct:1LLJqW+Bya67wtz+N7NlkhIe+WXvXsZW2vw9JI/Mp4I=",
+		},
+		{
+			url:  "https://acct.blob.core.windows.net/container?comp=list&marker=x&prefix=dir%2F&restype=container",
+			want: "SharedKey acct:NPJOUyLqkg8QqT5inXRQo9D5nRvqbD/CZmscq+N4VQM=",
+		},
+	} {
+		req, err := http.NewRequest(http.MethodGet, tc.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("X-Ms-Version", azureVersion)
+		req.Header.Set("X-Ms-Date", time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat))
+		b.sign(req)
+		if got := req.Header.Get("Authorization"); got != tc.want {
+			t.Errorf("unexpected authorization header for %s -want/+got:\n%s", tc.url, cmp.Diff(tc.want, got))
+		}
+	}
+}
+
+func TestAzureBucket(t *testing.T) {
+	objects := map[string]string{
+		"data/a.csv": "a",
+		"data/b.csv": "b",
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "SharedKey acct:") {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`<Error><Code>AuthenticationFailed</Code><Message>Server failed to authenticate the request.</Message></Error>`))
+			return
+		}
+		if r.URL.Path == "/container" && r.URL.Query().Get("comp") == "list" {
+			// the second page is requested with the marker of the first one
+			if r.URL.Query().Get("marker") == "" {
+				_, _ = w.Write([]byte(`<EnumerationResults><Blobs><Blob><Name>data/b.csv</Name></Blob></Blobs><NextMarker>m</NextMarker></EnumerationResults>`))
+			} else {
+				_, _ = w.Write([]byte(`<EnumerationResults><Blobs><Blob><Name>data/a.csv</Name></Blob></Blobs><NextMarker/></EnumerationResults>`))
+			}
+			return
+		}
+		content, ok := objects[strings.TrimPrefix(r.URL.Path, "/container/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`<Error><Code>BlobNotFound</Code><Message>The specified blob does not exist.</Message></Error>`))
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer ts.Close()
+
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	bucket, err := DefaultProvider{}.Bucket(ctx, Config{
+		Provider: "azblob",
+		Bucket:   "container",
+		Endpoint: ts.URL,
+		Credentials: Credentials{
+			AccessKey: "acct",
+			SecretKey: base64.StdEncoding.EncodeToString([]byte("key")),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := bucket.List(ctx, "data/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"data/a.csv", "data/b.csv"}; !cmp.Equal(want, keys) {
+		t.Errorf("unexpected keys -want/+got:\n%s", cmp.Diff(want, keys))
+	}
+
+	rc, err := bucket.Open(ctx, "data/a.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	content, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "a", string(content); want != got {
+		t.Errorf("unexpected content want: %q got: %q", want, got)
+	}
+}
+
+func TestAzureBucket_SAS(t *testing.T) {
+	var query string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if r.Header.Get("Authorization") != "" {
+			t.Error("unexpected authorization header with a SAS token")
+		}
+		_, _ = w.Write([]byte("a"))
+	}))
+	defer ts.Close()
+
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	bucket, err := DefaultProvider{}.Bucket(ctx, Config{
+		Provider: "azblob",
+		Bucket:   "container",
+		Endpoint: ts.URL,
+		Credentials: Credentials{
+			AccessKey:    "acct",
+			SessionToken: "?sv=2020-04-08&sig=abc",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := bucket.Open(ctx, "a.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = rc.Close()
+	if want := "sig=abc&sv=2020-04-08"; query != want {
+		t.Errorf("unexpected query want: %q got: %q", want, query)
+	}
+}
@@ -0,0 +1,171 @@
+package objectstore
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	fluxhttp "github.com/influxdata/flux/dependencies/http"
+	"github.com/influxdata/flux/internal/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// DefaultGCSEndpoint is the address of Google Cloud Storage.
+const DefaultGCSEndpoint = "https://storage.googleapis.com"
+
+const gcsReadOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
+
+// gcsBucket reads the objects of a Google Cloud Storage bucket with the JSON API.
+// Requests are authenticated with the OAuth2 tokens of a service account.
+type gcsBucket struct {
+	client   fluxhttp.Client
+	bucket   string
+	endpoint *url.URL
+	tokens   oauth2.TokenSource
+}
+
+func newGCSBucket(ctx context.Context, config Config, useEnv bool) (*gcsBucket, error) {
+	deps := flux.GetDependencies(ctx)
+	client, err := deps.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := parseEndpoint(ctx, config.Endpoint, DefaultGCSEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	b := &gcsBucket{
+		client:   client,
+		bucket:   config.Bucket,
+		endpoint: endpoint,
+	}
+
+	// the tokens are requested with the HTTP client of the dependencies
+	tokenCtx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Transport: clientTransport{client: client},
+	})
+	if key := config.Credentials.SecretKey; key != "" {
+		// Only service account keys are accepted. The other types of
+		// credentials, such as external accounts, name files and URLs
+		// that the tokens are read from on behalf of the script.
+		conf, err := google.JWTConfigFromJSON([]byte(key), gcsReadOnlyScope)
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "invalid google service account key")
+		}
+		b.tokens = conf.TokenSource(tokenCtx)
+	} else if useEnv {
+		creds, err := google.FindDefaultCredentials(tokenCtx, gcsReadOnlyScope)
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Unauthenticated, "failed to find the google application default credentials")
+		}
+		b.tokens = creds.TokenSource
+	}
+	return b, nil
+}
+
+func (b *gcsBucket) get(ctx context.Context, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid gcs request")
+	}
+	if b.tokens != nil {
+		t, err := b.tokens.Token()
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Unauthenticated, "failed to obtain a google access token")
+		}
+		t.SetAuthHeader(req)
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Unavailable, "gcs request failed")
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, gcsError(resp)
+	}
+	return resp, nil
+}
+
+// objectsURL returns the URL of the objects of the bucket, or of one object if name is not empty.
+func (b *gcsBucket) objectsURL(name string, query url.Values) *url.URL {
+	u := *b.endpoint
+	p := strings.TrimSuffix(u.Path, "/") + "/storage/v1/b/" + b.bucket + "/o"
+	u.Path = p
+	u.RawPath = ""
+	if name != "" {
+		// object names may contain slashes, which must be escaped
+		u.Path = p + "/" + name
+		u.RawPath = p + "/" + url.PathEscape(name)
+	}
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+func (b *gcsBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"fields": {"items(name),nextPageToken"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("pageToken", token)
+		}
+		resp, err := b.get(ctx, b.objectsURL("", query))
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Items []struct {
+				Name string `json:"name"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Internal, "failed to decode the gcs object list")
+		}
+		for _, item := range result.Items {
+			keys = append(keys, item.Name)
+		}
+		if result.NextPageToken == "" {
+			break
+		}
+		token = result.NextPageToken
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b *gcsBucket) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := b.get(ctx, b.objectsURL(key, url.Values{"alt": {"media"}}))
+	if err != nil {
+		return nil, errors.Wrapf(err, codes.Inherit, "failed to read gcs object %q", key)
+	}
+	return newLengthCheckReader(resp, key), nil
+}
+
+// gcsError converts the error response of Google Cloud Storage to an error.
+func gcsError(resp *http.Response) error {
+	var body struct {
+		Error struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	msg := resp.Status
+	if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+		msg = body.Error.Message
+	}
+	return errors.Newf(statusCode(resp.StatusCode), "gcs request failed: %s", msg)
+}
@@ -0,0 +1,103 @@
+package objectstore
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestGCSBucket(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/storage/v1/b/bucket/o":
+			if r.URL.Query().Get("pageToken") == "" {
+				_, _ = w.Write([]byte(`{"items": [{"name": "data/b.csv"}], "nextPageToken": "t"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"items": [{"name": "data/a.csv"}]}`))
+			}
+		case "/storage/v1/b/bucket/o/data%2Fa.csv":
+			if r.URL.Query().Get("alt") != "media" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte("a"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error": {"code": 404, "message": "No such object"}}`))
+		}
+	}))
+	defer ts.Close()
+
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	bucket, err := DefaultProvider{}.Bucket(ctx, Config{
+		Provider: "gcs",
+		Bucket:   "bucket",
+		Endpoint: ts.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, err := bucket.List(ctx, "data/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"data/a.csv", "data/b.csv"}; !cmp.Equal(want, keys) {
+		t.Errorf("unexpected keys -want/+got:\n%s", cmp.Diff(want, keys))
+	}
+
+	rc, err := bucket.Open(ctx, "data/a.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	content, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "a", string(content); want != got {
+		t.Errorf("unexpected content want: %q got: %q", want, got)
+	}
+
+	if _, err := bucket.Open(ctx, "missing.csv"); err == nil {
+		t.Error("expected an error for a missing object")
+	} else if want, got := codes.NotFound, errors.Code(err); want != got {
+		t.Errorf("unexpected error code want: %v got: %v", want, got)
+	}
+}
+
+func TestGCSBucket_InvalidKey(t *testing.T) {
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	for _, tc := range []struct {
+		name string
+		key  string
+	}{
+		{name: "not json", key: "not json"},
+		{
+			name: "external account",
+			key:  `{"type": "external_account", "audience": "a", "token_url": "https://sts.googleapis.com/v1/token", "credential_source": {"file": "/etc/passwd"}}`,
+		},
+		{
+			name: "authorized user",
+			key:  `{"type": "authorized_user", "client_id": "id", "client_secret": "secret", "refresh_token": "token"}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DefaultProvider{}.Bucket(ctx, Config{
+				Provider:    "gcs",
+				Bucket:      "bucket",
+				Credentials: Credentials{SecretKey: tc.key},
+			})
+			if want, got := codes.Invalid, errors.Code(err); want != got {
+				t.Errorf("unexpected error code want: %v got: %v", want, got)
+			}
+		})
+	}
+}
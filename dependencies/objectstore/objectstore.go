@@ -0,0 +1,193 @@
+// Package objectstore provides access to the buckets of object stores:
+// Amazon S3, Google Cloud Storage and Azure Blob Storage.
+package objectstore
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	fluxhttp "github.com/influxdata/flux/dependencies/http"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+type key int
+
+const providerKey key = iota
+
+// Inject will inject this Provider into the dependency chain.
+func Inject(ctx context.Context, provider Provider) context.Context {
+	return context.WithValue(ctx, providerKey, provider)
+}
+
+// Dependency will inject the Provider into the dependency chain.
+type Dependency struct {
+	Provider Provider
+}
+
+// Inject will inject the Provider into the dependency chain.
+func (d Dependency) Inject(ctx context.Context) context.Context {
+	return Inject(ctx, d.Provider)
+}
+
+// GetProvider will return the Provider for the current context.
+// If no Provider has been injected into the dependencies,
+// this will return a default provider.
+func GetProvider(ctx context.Context) Provider {
+	p := ctx.Value(providerKey)
+	if p == nil {
+		return DefaultProvider{}
+	}
+	return p.(Provider)
+}
+
+// Credentials authenticate the requests to an object store.
+// Requests are anonymous when the credentials are empty.
+//
+// For S3, they are the access key ID, the secret access key and the session token.
+// For Google Cloud Storage, the secret key is the JSON key of a service account.
+// For Azure Blob Storage, the access key is the name of the storage account,
+// the secret key is the account key and the session token is a SAS token.
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// IsZero reports whether no credentials are set.
+func (c Credentials) IsZero() bool {
+	return c.AccessKey == "" && c.SecretKey == "" && c.SessionToken == ""
+}
+
+// Config identifies a bucket and how to connect to it.
+type Config struct {
+	// Provider is the name of the object store: s3 (default), gcs or azblob.
+	Provider string
+	// Bucket is the name of the bucket, or of the container for Azure Blob Storage.
+	Bucket string
+	// Region is the region of the bucket, if the object store has regions.
+	Region string
+	// Endpoint overrides the address of the object store,
+	// eg. to use an S3 compatible object store.
+	Endpoint    string
+	Credentials Credentials
+}
+
+// Bucket reads the objects of a bucket.
+type Bucket interface {
+	// List returns the keys of the objects that start with prefix in lexical order.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Open returns a reader of the content of an object.
+	// The content is streamed from the object store as it is read.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// Provider opens the buckets of object stores.
+type Provider interface {
+	Bucket(ctx context.Context, config Config) (Bucket, error)
+}
+
+// DefaultProvider opens the buckets of the object stores supported by flux.
+type DefaultProvider struct {
+	// UseEnvironment allows reading the credentials and the region
+	// from the environment of the process when the config does not set them.
+	// It must only be set when the queries are trusted with the credentials of the process.
+	UseEnvironment bool
+}
+
+func (p DefaultProvider) Bucket(ctx context.Context, config Config) (Bucket, error) {
+	if config.Bucket == "" {
+		return nil, errors.New(codes.Invalid, "bucket must not be empty")
+	}
+	switch config.Provider {
+	case "s3", "":
+		return newS3Bucket(ctx, config, p.UseEnvironment)
+	case "gcs":
+		return newGCSBucket(ctx, config, p.UseEnvironment)
+	case "azblob":
+		return newAzureBucket(ctx, config, p.UseEnvironment)
+	default:
+		return nil, errors.Newf(codes.Invalid, "unsupported object store provider %q", config.Provider)
+	}
+}
+
+// statusCode returns the error code of an HTTP status code.
+func statusCode(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return codes.Invalid
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// lengthCheckReader reports an error if the content of an object is shorter
+// than its length, which happens when the HTTP client limits the size of the responses.
+type lengthCheckReader struct {
+	io.ReadCloser
+	key       string
+	remaining int64
+}
+
+func newLengthCheckReader(resp *http.Response, key string) *lengthCheckReader {
+	return &lengthCheckReader{
+		ReadCloser: resp.Body,
+		key:        key,
+		remaining:  resp.ContentLength,
+	}
+}
+
+func (r *lengthCheckReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if r.remaining >= 0 {
+		r.remaining -= int64(n)
+		if err == io.EOF && r.remaining > 0 {
+			return n, errors.Newf(codes.ResourceExhausted, "object %q was truncated, it may exceed the response size limit of the http client", r.key)
+		}
+	}
+	return n, err
+}
+
+// parseEndpoint parses the endpoint of an object store, or defaultEndpoint if it is empty,
+// and checks that it passes the URL validator of the dependencies.
+func parseEndpoint(ctx context.Context, endpoint, defaultEndpoint string) (*url.URL, error) {
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid endpoint")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Newf(codes.Invalid, "invalid endpoint %q, the scheme must be http or https", endpoint)
+	}
+	validator, err := flux.GetDependencies(ctx).URLValidator()
+	if err != nil {
+		return nil, err
+	}
+	if err := validator.Validate(u); err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "endpoint did not pass url validation")
+	}
+	return u, nil
+}
+
+// clientTransport sends requests with the HTTP client of the dependencies
+// for the libraries that need an *http.Client.
+type clientTransport struct {
+	client fluxhttp.Client
+}
+
+func (t clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.client.Do(req)
+}
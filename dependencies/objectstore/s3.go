@@ -0,0 +1,293 @@
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	fluxhttp "github.com/influxdata/flux/dependencies/http"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// DefaultS3Region is the region of the S3 buckets when none is configured.
+const DefaultS3Region = "us-east-1"
+
+// emptyPayloadHash is the SHA256 hash of an empty request body.
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// s3Bucket reads the objects of an S3 bucket with the REST API.
+// Requests are signed with AWS Signature Version 4 and sent
+// with the HTTP client of the flux dependencies.
+type s3Bucket struct {
+	client      fluxhttp.Client
+	bucket      string
+	region      string
+	endpoint    *url.URL
+	pathStyle   bool
+	credentials Credentials
+	now         func() time.Time
+}
+
+func newS3Bucket(ctx context.Context, config Config, useEnv bool) (*s3Bucket, error) {
+	deps := flux.GetDependencies(ctx)
+	client, err := deps.HTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	b := &s3Bucket{
+		client:      client,
+		bucket:      config.Bucket,
+		region:      config.Region,
+		credentials: config.Credentials,
+		now:         time.Now,
+	}
+	if useEnv {
+		if b.region == "" {
+			b.region = os.Getenv("AWS_REGION")
+		}
+		if b.region == "" {
+			b.region = os.Getenv("AWS_DEFAULT_REGION")
+		}
+		if b.credentials.IsZero() {
+			b.credentials = Credentials{
+				AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+				SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+			}
+		}
+	}
+	if b.region == "" {
+		b.region = DefaultS3Region
+	}
+	if (b.credentials.AccessKey == "") != (b.credentials.SecretKey == "") {
+		return nil, errors.New(codes.Invalid, "both the access key and the secret key are required to authenticate with s3")
+	}
+
+	endpoint := fmt.Sprintf("https://%s.s3.%s.amazonaws.com", b.bucket, b.region)
+	if config.Endpoint != "" {
+		// S3 compatible object stores usually only support path-style requests
+		endpoint = config.Endpoint
+		b.pathStyle = true
+	}
+	if b.endpoint, err = parseEndpoint(ctx, endpoint, ""); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// objectURL returns the URL of an object, or of the bucket if key is empty.
+func (b *s3Bucket) objectURL(key string, query url.Values) *url.URL {
+	u := *b.endpoint
+	p := strings.TrimSuffix(u.Path, "/")
+	if b.pathStyle {
+		p += "/" + b.bucket
+	}
+	u.Path = p + "/" + key
+	// send the path as it is escaped in the signature
+	u.RawPath = escapePath(u.Path)
+	u.RawQuery = query.Encode()
+	return &u
+}
+
+func (b *s3Bucket) get(ctx context.Context, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid s3 request")
+	}
+	b.sign(req, emptyPayloadHash)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Unavailable, "s3 request failed")
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, xmlError(resp, "s3")
+	}
+	return resp, nil
+}
+
+func (b *s3Bucket) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		resp, err := b.get(ctx, b.objectURL("", query))
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Contents []struct {
+				Key string
+			}
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Internal, "failed to decode the s3 object list")
+		}
+		for _, c := range result.Contents {
+			keys = append(keys, c.Key)
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b *s3Bucket) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := b.get(ctx, b.objectURL(key, nil))
+	if err != nil {
+		return nil, errors.Wrapf(err, codes.Inherit, "failed to read s3 object %q", key)
+	}
+	return newLengthCheckReader(resp, key), nil
+}
+
+// xmlError converts the XML error response of S3 or Azure to an error.
+func xmlError(resp *http.Response, service string) error {
+	var body struct {
+		Code    string
+		Message string
+	}
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	msg := resp.Status
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		msg = fmt.Sprintf("%s: %s", body.Code, body.Message)
+	}
+	return errors.Newf(statusCode(resp.StatusCode), "%s request failed: %s", service, msg)
+}
+
+// sign signs a request with AWS Signature Version 4.
+// See https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html.
+func (b *s3Bucket) sign(req *http.Request, payloadHash string) {
+	if b.credentials.AccessKey == "" {
+		return
+	}
+	t := b.now().UTC()
+	date := t.Format("20060102")
+	timestamp := t.Format("20060102T150405Z")
+
+	req.Header.Set("X-Amz-Date", timestamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if b.credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", b.credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + b.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		timestamp,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+b.credentials.SecretKey), date)
+	key = hmacSHA256(key, b.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		b.credentials.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+// escapePath escapes each segment of a path as required by the canonical requests.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = escape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var parts []string
+	for _, k := range keys {
+		vs := append([]string(nil), query[k]...)
+		sort.Strings(vs)
+		for _, v := range vs {
+			parts = append(parts, escape(k)+"="+escape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escape escapes all the characters but the unreserved characters of RFC 3986.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
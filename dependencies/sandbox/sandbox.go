@@ -0,0 +1,52 @@
+// Package sandbox provides a dependency for limiting the resources
+// that the evaluation of a script may use.
+//
+// The limits apply to the pure evaluation of Flux by the interpreter,
+// before any query reaches the table engine, so that embedders can
+// evaluate untrusted scripts safely. The table engine is limited
+// separately by the memory allocator and the query deadline.
+package sandbox
+
+import (
+	"context"
+	"time"
+)
+
+type key int
+
+const limitsKey key = iota
+
+// Limits are the limits on the evaluation of a script.
+// A limit that is not positive is not enforced.
+type Limits struct {
+	// StatementTimeout is the maximum amount of time the evaluation
+	// of each top-level statement of a script may take.
+	StatementTimeout time.Duration
+	// MaxCallDepth is the maximum depth of the call stack.
+	// When it is not set, the default of the interpreter is used.
+	MaxCallDepth int
+	// MaxStringSize is the maximum length in bytes of the
+	// strings built or returned by function calls.
+	MaxStringSize int
+	// MaxArraySize is the maximum number of elements of the
+	// arrays built or returned by function calls.
+	MaxArraySize int
+}
+
+// Inject will inject the limits into the dependency chain.
+func (l Limits) Inject(ctx context.Context) context.Context {
+	return Inject(ctx, l)
+}
+
+// Inject will inject the limits into the dependency chain.
+func Inject(ctx context.Context, l Limits) context.Context {
+	return context.WithValue(ctx, limitsKey, l)
+}
+
+// Get returns the limits configured in the context.
+// It returns the zero Limits, which enforce nothing,
+// if no limits have been configured.
+func Get(ctx context.Context) Limits {
+	l, _ := ctx.Value(limitsKey).(Limits)
+	return l
+}
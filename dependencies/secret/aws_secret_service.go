@@ -0,0 +1,196 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// DefaultAWSRegion is the region of AWS Secrets Manager
+// when none is configured.
+const DefaultAWSRegion = "us-east-1"
+
+// Secret service that reads the secrets of AWS Secrets Manager.
+// Requests are signed with AWS Signature Version 4.
+//
+// A key is the name or the ARN of a secret. It may be followed by #
+// and the name of a field to read the field of a secret that is
+// stored as a JSON object, eg. "prod/db#password".
+type AWSSecretService struct {
+	// Region is the region of the secrets.
+	// It is DefaultAWSRegion when empty.
+	Region string
+	// Endpoint overrides the address of Secrets Manager.
+	Endpoint string
+	// AccessKey, SecretKey and SessionToken are the credentials
+	// that sign the requests.
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	// Client sends the requests.
+	// It is http.DefaultClient when nil.
+	Client *http.Client
+
+	now func() time.Time
+}
+
+func (ass AWSSecretService) LoadSecret(ctx context.Context, k string) (string, error) {
+	name, field := splitSecretKey(k)
+	if name == "" {
+		return "", errors.Newf(codes.Invalid, "invalid secret key %q", k)
+	}
+	region := ass.Region
+	if region == "" {
+		region = DefaultAWSRegion
+	}
+	endpoint := ass.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region)
+	}
+
+	payload, err := json.Marshal(struct {
+		SecretId string
+	}{SecretId: name})
+	if err != nil {
+		return "", errors.Wrap(err, codes.Internal)
+	}
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", errors.Wrap(err, codes.Invalid, "invalid secrets manager endpoint")
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	ass.sign(req, region, hexSHA256(payload))
+
+	resp, err := httpClient(ass.Client).Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, codes.Unavailable, "secrets manager request failed")
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", errors.Wrap(err, codes.Unavailable, "failed to read secrets manager response")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		msg := resp.Status
+		if json.Unmarshal(data, &body) == nil && body.Type != "" {
+			// The type may be prefixed with the namespace of the error.
+			typ := body.Type[strings.LastIndex(body.Type, "#")+1:]
+			if typ == "ResourceNotFoundException" {
+				return "", errors.Newf(codes.NotFound, "secret key %q not found", k)
+			}
+			msg = fmt.Sprintf("%s: %s", typ, body.Message)
+		}
+		return "", errors.Newf(statusCode(resp.StatusCode), "secrets manager request failed: %s", msg)
+	}
+
+	var body struct {
+		SecretString *string
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", errors.Wrap(err, codes.Internal, "invalid secrets manager response")
+	} else if body.SecretString == nil {
+		return "", errors.Newf(codes.Invalid, "secret key %q is not a string", k)
+	}
+	if field == "" {
+		return *body.SecretString, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(*body.SecretString), &fields); err != nil {
+		return "", errors.Newf(codes.Invalid, "secret %q is not a JSON object", name)
+	}
+	return secretField(fields, k, field)
+}
+
+// sign signs a request with AWS Signature Version 4.
+// See https://docs.aws.amazon.com/general/latest/gr/sigv4_signing.html.
+func (ass AWSSecretService) sign(req *http.Request, region, payloadHash string) {
+	if ass.AccessKey == "" {
+		return
+	}
+	now := time.Now
+	if ass.now != nil {
+		now = ass.now
+	}
+	t := now().UTC()
+	date := t.Format("20060102")
+	timestamp := t.Format("20060102T150405Z")
+
+	req.Header.Set("X-Amz-Date", timestamp)
+	if ass.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", ass.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		headers[strings.ToLower(k)] = strings.TrimSpace(strings.Join(v, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/secretsmanager/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		timestamp,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+ass.SecretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "secretsmanager")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s",
+		ass.AccessKey, scope, signedHeaders, signature,
+	))
+}
+
+func hexSHA256(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
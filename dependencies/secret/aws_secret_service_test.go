@@ -0,0 +1,97 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// TestAWSSign checks the signature with the one of the signer of the AWS SDK for Go.
+func TestAWSSign(t *testing.T) {
+	payload := []byte(`{"SecretId":"x"}`)
+	req, err := http.NewRequest(http.MethodPost, "https://secretsmanager.eu-west-1.amazonaws.com/", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+
+	ss := AWSSecretService{
+		AccessKey:    "AK",
+		SecretKey:    "SK",
+		SessionToken: "TOK",
+		now: func() time.Time {
+			return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		},
+	}
+	ss.sign(req, "eu-west-1", hexSHA256(payload))
+
+	want := "AWS4-HMAC-SHA256 Credential=AK/20200102/eu-west-1/secretsmanager/aws4_request," +
+		"SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target," +
+		"Signature=13328d22c9d947fa2a9188783f30af0afa72c1ea92ba86b2576c038d30ea2689"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("unexpected authorization header -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestAWSSecretService(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AK/") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var body struct {
+			SecretId string
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch body.SecretId {
+		case "token":
+			_, _ = w.Write([]byte(`{"Name":"token","SecretString":"mytoken"}`))
+		case "prod/db":
+			_, _ = w.Write([]byte(`{"Name":"prod/db","SecretString":"{\"password\":\"p\"}"}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException","message":"Secrets Manager can't find the specified secret."}`))
+		}
+	}))
+	defer ts.Close()
+
+	ss := AWSSecretService{
+		Endpoint:  ts.URL,
+		AccessKey: "AK",
+		SecretKey: "SK",
+	}
+	for k, want := range map[string]string{
+		"token":            "mytoken",
+		"prod/db#password": "p",
+	} {
+		got, err := ss.LoadSecret(context.Background(), k)
+		if err != nil {
+			t.Errorf("unexpected error for key %q: %s", k, err)
+		} else if got != want {
+			t.Errorf("unexpected secret for key %q -want/+got:\n\t- %q\n\t+ %q", k, want, got)
+		}
+	}
+
+	for k, code := range map[string]codes.Code{
+		"missing":      codes.NotFound,
+		"prod/db#user": codes.NotFound,
+		"token#field":  codes.Invalid,
+	} {
+		if _, err := ss.LoadSecret(context.Background(), k); errors.Code(err) != code {
+			t.Errorf("unexpected error code for key %q: want %v, got %v", k, code, err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package secret
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func (fss FileSecretService) LoadSecret(ctx context.Context, k string) (string, error) {
+	if k == "" || k == "." || k == ".." || strings.ContainsAny(k, `/\`) {
+		return "", errors.Newf(codes.Invalid, "invalid secret key %q", k)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(fss.Dir, k))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.Newf(codes.NotFound, "secret key %q not found", k)
+		}
+		return "", errors.Wrapf(err, codes.Internal, "failed to read secret %q", k)
+	}
+	// Editors and tools such as echo end the files with a newline.
+	s := strings.TrimSuffix(string(data), "\n")
+	return strings.TrimSuffix(s, "\r"), nil
+}
+
+// Secret service that reads each secret from the file
+// with the name of its key in a directory, such as
+// the secrets mounted by Docker or Kubernetes.
+type FileSecretService struct {
+	Dir string
+}
@@ -0,0 +1,44 @@
+package secret_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/secret"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestFileSecretService(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	if err := ioutil.WriteFile(filepath.Join(dir, "token"), []byte("mytoken\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ss := secret.FileSecretService{Dir: dir}
+
+	val, err := ss.LoadSecret(context.Background(), "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "mytoken"; val != want {
+		t.Errorf("unexpected secret -want/+got:\n\t- %q\n\t+ %q", want, val)
+	}
+
+	for k, code := range map[string]codes.Code{
+		"missing":      codes.NotFound,
+		"":             codes.Invalid,
+		"..":           codes.Invalid,
+		"../etc/token": codes.Invalid,
+	} {
+		if _, err := ss.LoadSecret(context.Background(), k); errors.Code(err) != code {
+			t.Errorf("unexpected error code for key %q: want %v, got %v", k, code, err)
+		}
+	}
+}
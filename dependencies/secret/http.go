@@ -0,0 +1,63 @@
+package secret
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// maxResponseSize is the maximum size of the responses
+// read from the secret stores.
+const maxResponseSize = 1 << 20
+
+func httpClient(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+	return client
+}
+
+// statusCode returns the error code of an HTTP status code.
+func statusCode(status int) codes.Code {
+	switch status {
+	case http.StatusBadRequest:
+		return codes.Invalid
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+// splitSecretKey splits a key into the name of a secret and
+// the name of one of its fields, which are separated by #.
+func splitSecretKey(k string) (name, field string) {
+	if i := strings.LastIndex(k, "#"); i >= 0 {
+		return k[:i], k[i+1:]
+	}
+	return k, ""
+}
+
+// secretField returns the field of a secret with many fields.
+func secretField(fields map[string]interface{}, k, field string) (string, error) {
+	if field == "" {
+		field = DefaultSecretField
+	}
+	v, ok := fields[field]
+	if !ok {
+		return "", errors.Newf(codes.NotFound, "secret key %q not found", k)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", errors.Newf(codes.Invalid, "secret key %q is not a string", k)
+	}
+	return s, nil
+}
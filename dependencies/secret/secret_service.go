@@ -2,6 +2,11 @@ package secret
 
 import (
 	"context"
+	"net/http"
+	"os"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
 )
 
 // Service generalizes the process of looking up secrets based on a key.
@@ -9,3 +14,105 @@ type Service interface {
 	// LoadSecret retrieves the secret value v found at key k given the calling context ctx.
 	LoadSecret(ctx context.Context, k string) (string, error)
 }
+
+// The backends of the secret services that New creates.
+const (
+	EmptyBackend       = ""
+	EnvironmentBackend = "env"
+	FileBackend        = "file"
+	VaultBackend       = "vault"
+	AWSBackend         = "aws"
+)
+
+// Config selects the backend of a secret service and configures it.
+type Config struct {
+	// Backend is the name of the backend.
+	// The empty backend reports that no secrets exist.
+	Backend string
+
+	// Dir is the directory of the file backend.
+	Dir string
+
+	// VaultAddress, VaultToken and VaultMount configure the vault backend.
+	VaultAddress string
+	VaultToken   string
+	VaultMount   string
+
+	// AWSRegion, AWSEndpoint and the AWS credentials configure the aws backend.
+	AWSRegion       string
+	AWSEndpoint     string
+	AWSAccessKey    string
+	AWSSecretKey    string
+	AWSSessionToken string
+
+	// UseEnvironment fills the settings of the vault and aws backends
+	// that are not set with the standard environment variables of their
+	// command line tools, such as VAULT_TOKEN and AWS_ACCESS_KEY_ID.
+	UseEnvironment bool
+
+	// Client sends the requests of the vault and aws backends.
+	// It is http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// New creates the secret service of a configuration.
+func New(config Config) (Service, error) {
+	if config.UseEnvironment {
+		config = config.withEnvironment()
+	}
+	switch config.Backend {
+	case EmptyBackend:
+		return EmptySecretService{}, nil
+	case EnvironmentBackend:
+		return EnvironmentSecretService{}, nil
+	case FileBackend:
+		if config.Dir == "" {
+			return nil, errors.New(codes.Invalid, "the file secret service requires a directory")
+		}
+		return FileSecretService{Dir: config.Dir}, nil
+	case VaultBackend:
+		if config.VaultAddress == "" {
+			return nil, errors.New(codes.Invalid, "the vault secret service requires an address")
+		}
+		return VaultSecretService{
+			Address: config.VaultAddress,
+			Token:   config.VaultToken,
+			Mount:   config.VaultMount,
+			Client:  config.Client,
+		}, nil
+	case AWSBackend:
+		if (config.AWSAccessKey == "") != (config.AWSSecretKey == "") {
+			return nil, errors.New(codes.Invalid, "both the access key and the secret key are required to authenticate with aws")
+		}
+		return AWSSecretService{
+			Region:       config.AWSRegion,
+			Endpoint:     config.AWSEndpoint,
+			AccessKey:    config.AWSAccessKey,
+			SecretKey:    config.AWSSecretKey,
+			SessionToken: config.AWSSessionToken,
+			Client:       config.Client,
+		}, nil
+	default:
+		return nil, errors.Newf(codes.Invalid, "unknown secret service backend %q", config.Backend)
+	}
+}
+
+func (c Config) withEnvironment() Config {
+	setenv := func(v *string, keys ...string) {
+		for _, k := range keys {
+			if *v != "" {
+				return
+			}
+			*v = os.Getenv(k)
+		}
+	}
+	setenv(&c.VaultAddress, "VAULT_ADDR")
+	setenv(&c.VaultToken, "VAULT_TOKEN")
+	setenv(&c.AWSRegion, "AWS_REGION", "AWS_DEFAULT_REGION")
+	if c.AWSAccessKey == "" && c.AWSSecretKey == "" && c.AWSSessionToken == "" {
+		c.AWSAccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		c.AWSSecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		c.AWSSessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	return c
+}
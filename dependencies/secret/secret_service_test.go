@@ -4,6 +4,9 @@ import (
 	"context"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/influxdata/flux/dependencies/secret"
 	"github.com/influxdata/flux/mock"
 )
 
@@ -21,3 +24,41 @@ func TestSecret_Service(t *testing.T) {
 		t.Error("secret service should have errored on key lookup")
 	}
 }
+
+func TestNew(t *testing.T) {
+	for _, tc := range []struct {
+		config  secret.Config
+		want    secret.Service
+		wantErr bool
+	}{
+		{config: secret.Config{}, want: secret.EmptySecretService{}},
+		{config: secret.Config{Backend: "env"}, want: secret.EnvironmentSecretService{}},
+		{config: secret.Config{Backend: "file", Dir: "/run/secrets"}, want: secret.FileSecretService{Dir: "/run/secrets"}},
+		{config: secret.Config{Backend: "file"}, wantErr: true},
+		{
+			config: secret.Config{Backend: "vault", VaultAddress: "http://vault:8200", VaultToken: "t"},
+			want:   secret.VaultSecretService{Address: "http://vault:8200", Token: "t"},
+		},
+		{config: secret.Config{Backend: "vault"}, wantErr: true},
+		{
+			config: secret.Config{Backend: "aws", AWSRegion: "eu-west-1"},
+			want:   secret.AWSSecretService{Region: "eu-west-1"},
+		},
+		{config: secret.Config{Backend: "aws", AWSAccessKey: "AK"}, wantErr: true},
+		{config: secret.Config{Backend: "keyring"}, wantErr: true},
+	} {
+		got, err := secret.New(tc.config)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("expected an error for backend %q", tc.config.Backend)
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("unexpected error for backend %q: %s", tc.config.Backend, err)
+			continue
+		}
+		if !cmp.Equal(tc.want, got, cmpopts.IgnoreUnexported(secret.AWSSecretService{})) {
+			t.Errorf("unexpected service -want/+got:\n%s", cmp.Diff(tc.want, got, cmpopts.IgnoreUnexported(secret.AWSSecretService{})))
+		}
+	}
+}
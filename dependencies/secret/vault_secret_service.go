@@ -0,0 +1,93 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// DefaultVaultMount is the mount of the KV secrets engine
+// when none is configured.
+const DefaultVaultMount = "secret"
+
+// DefaultSecretField is the field of a secret that is
+// read when its key does not name one.
+const DefaultSecretField = "value"
+
+// Secret service that reads the secrets of the version 2
+// KV secrets engine of HashiCorp Vault.
+//
+// A key is the path of a secret followed by # and the name
+// of one of its fields, eg. "db/postgres#password".
+// The field is DefaultSecretField when the key has none.
+type VaultSecretService struct {
+	// Address is the address of the Vault server, eg. https://vault:8200.
+	Address string
+	// Token authenticates the requests.
+	Token string
+	// Mount is the mount of the KV secrets engine.
+	// It is DefaultVaultMount when empty.
+	Mount string
+	// Client sends the requests.
+	// It is http.DefaultClient when nil.
+	Client *http.Client
+}
+
+func (vss VaultSecretService) LoadSecret(ctx context.Context, k string) (string, error) {
+	path, field := splitSecretKey(k)
+	if path == "" {
+		return "", errors.Newf(codes.Invalid, "invalid secret key %q", k)
+	}
+	mount := vss.Mount
+	if mount == "" {
+		mount = DefaultVaultMount
+	}
+	u := strings.TrimSuffix(vss.Address, "/") + "/v1/" + strings.Trim(mount, "/") + "/data/" + strings.Trim(path, "/")
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", errors.Wrap(err, codes.Invalid, "invalid vault address")
+	}
+	req = req.WithContext(ctx)
+	if vss.Token != "" {
+		req.Header.Set("X-Vault-Token", vss.Token)
+	}
+
+	resp, err := httpClient(vss.Client).Do(req)
+	if err != nil {
+		return "", errors.Wrap(err, codes.Unavailable, "vault request failed")
+	}
+	defer func() { _ = resp.Body.Close() }()
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return "", errors.Wrap(err, codes.Unavailable, "failed to read vault response")
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", errors.Newf(codes.NotFound, "secret key %q not found", k)
+	} else if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Errors []string `json:"errors"`
+		}
+		msg := resp.Status
+		if json.Unmarshal(data, &body) == nil && len(body.Errors) > 0 {
+			msg = strings.Join(body.Errors, "; ")
+		}
+		return "", errors.Newf(statusCode(resp.StatusCode), "vault request failed: %s", msg)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return "", errors.Wrap(err, codes.Internal, "invalid vault response")
+	}
+	return secretField(body.Data.Data, k, field)
+}
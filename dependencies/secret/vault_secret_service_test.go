@@ -0,0 +1,63 @@
+package secret_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/secret"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestVaultSecretService(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-Vault-Token"); got != "mytoken" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/db/postgres":
+			_, _ = w.Write([]byte(`{"data":{"data":{"value":"v","password":"p","port":5432},"metadata":{"version":1}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":[]}`))
+		}
+	}))
+	defer ts.Close()
+
+	ss := secret.VaultSecretService{
+		Address: ts.URL,
+		Token:   "mytoken",
+		Mount:   "kv",
+	}
+	for k, want := range map[string]string{
+		"db/postgres":          "v",
+		"db/postgres#password": "p",
+	} {
+		got, err := ss.LoadSecret(context.Background(), k)
+		if err != nil {
+			t.Errorf("unexpected error for key %q: %s", k, err)
+		} else if got != want {
+			t.Errorf("unexpected secret for key %q -want/+got:\n\t- %q\n\t+ %q", k, want, got)
+		}
+	}
+
+	for k, code := range map[string]codes.Code{
+		"db/mysql":         codes.NotFound,
+		"db/postgres#user": codes.NotFound,
+		"db/postgres#port": codes.Invalid,
+		"#password":        codes.Invalid,
+	} {
+		if _, err := ss.LoadSecret(context.Background(), k); errors.Code(err) != code {
+			t.Errorf("unexpected error code for key %q: want %v, got %v", k, code, err)
+		}
+	}
+
+	ss.Token = "wrong"
+	if _, err := ss.LoadSecret(context.Background(), "db/postgres"); errors.Code(err) != codes.PermissionDenied {
+		t.Errorf("expected a permission denied error, got %v", err)
+	}
+}
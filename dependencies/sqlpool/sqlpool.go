@@ -0,0 +1,207 @@
+// Package sqlpool provides a dependency that shares the connections
+// to SQL databases between the queries of a process.
+//
+// Without a pool, each call to sql.from or sql.to opens its own
+// connection to the database and closes it when it is done, so
+// short queries that run often spend most of their time connecting.
+// With a pool, the connections are kept open and reused by the
+// calls that connect to the same data source.
+package sqlpool
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+type key int
+
+const poolKey key = iota
+
+const (
+	// DefaultMaxDataSources is the number of data sources
+	// a pool keeps open when the configuration does not set it.
+	DefaultMaxDataSources = 16
+	// DefaultMaxIdleConns is the number of idle connections
+	// kept open to each data source when the configuration does not set it.
+	DefaultMaxIdleConns = 2
+	// DefaultConnMaxIdleTime is the amount of time a connection may be idle
+	// when the configuration does not set it.
+	DefaultConnMaxIdleTime = 5 * time.Minute
+)
+
+// Config limits the data sources of a pool and their connections.
+// A zero value uses the default of the pool.
+type Config struct {
+	// MaxDataSources is the maximum number of data sources that are kept open.
+	// When a data source is opened above the limit, the data source that
+	// was used least recently is closed once no query uses it.
+	MaxDataSources int
+	// MaxOpenConns is the maximum number of open connections.
+	// There is no limit when it is not positive.
+	MaxOpenConns int
+	// MaxIdleConns is the maximum number of idle connections.
+	MaxIdleConns int
+	// ConnMaxLifetime is the maximum amount of time a connection may be reused.
+	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime is the maximum amount of time a connection may be idle.
+	ConnMaxIdleTime time.Duration
+}
+
+// Pool holds a sql.DB, and its pool of connections, for each
+// driver and data source name. It is safe for concurrent use.
+type Pool struct {
+	config Config
+
+	mu  sync.Mutex
+	dbs map[dataSource]*list.Element
+	// lru holds the entries of the open data sources,
+	// from the most to the least recently used.
+	lru *list.List
+}
+
+type dataSource struct {
+	driverName     string
+	dataSourceName string
+}
+
+type entry struct {
+	key dataSource
+	db  *sql.DB
+	// refs is the number of callers that have not released the database.
+	refs int
+	// evicted is set when the entry was removed from the pool
+	// while it was used, and the last release closes the database.
+	evicted bool
+}
+
+// New creates a pool whose data sources are limited by the configuration.
+func New(config Config) *Pool {
+	if config.MaxDataSources <= 0 {
+		config.MaxDataSources = DefaultMaxDataSources
+	}
+	if config.MaxIdleConns <= 0 {
+		config.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if config.ConnMaxIdleTime <= 0 {
+		config.ConnMaxIdleTime = DefaultConnMaxIdleTime
+	}
+	return &Pool{
+		config: config,
+		dbs:    make(map[dataSource]*list.Element),
+		lru:    list.New(),
+	}
+}
+
+// Open returns the sql.DB of a driver and a data source name and the
+// function that releases it. The first call for a data source opens it
+// with open and the next calls return the same sql.DB. The sql.DB must
+// not be closed by the caller, it is closed when it is evicted from the
+// pool and released, or when the pool is closed.
+func (p *Pool) Open(driverName, dataSourceName string, open func() (*sql.DB, error)) (*sql.DB, func() error, error) {
+	key := dataSource{driverName: driverName, dataSourceName: dataSourceName}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if elem, ok := p.dbs[key]; ok {
+		p.lru.MoveToFront(elem)
+		e := elem.Value.(*entry)
+		e.refs++
+		return e.db, p.releaseFunc(e), nil
+	}
+	db, err := open()
+	if err != nil {
+		return nil, nil, err
+	}
+	if p.config.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.config.MaxOpenConns)
+	}
+	db.SetMaxIdleConns(p.config.MaxIdleConns)
+	if p.config.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.config.ConnMaxLifetime)
+	}
+	db.SetConnMaxIdleTime(p.config.ConnMaxIdleTime)
+
+	e := &entry{key: key, db: db, refs: 1}
+	p.dbs[key] = p.lru.PushFront(e)
+	p.evict()
+	return db, p.releaseFunc(e), nil
+}
+
+// evict removes the least recently used data sources above the limit.
+// A data source that is not used is closed now, the others are closed
+// when they are released.
+func (p *Pool) evict() {
+	for p.lru.Len() > p.config.MaxDataSources {
+		elem := p.lru.Back()
+		e := elem.Value.(*entry)
+		p.lru.Remove(elem)
+		delete(p.dbs, e.key)
+		if e.refs == 0 {
+			_ = e.db.Close()
+		} else {
+			e.evicted = true
+		}
+	}
+}
+
+// releaseFunc returns the function that releases an entry once.
+func (p *Pool) releaseFunc(e *entry) func() error {
+	var once sync.Once
+	return func() (err error) {
+		once.Do(func() {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			e.refs--
+			if e.refs == 0 && e.evicted {
+				err = e.db.Close()
+			}
+		})
+		return err
+	}
+}
+
+// Len returns the number of data sources that are open.
+func (p *Pool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.dbs)
+}
+
+// Close closes the sql.DB of each data source and returns the first error.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var firstErr error
+	for key, elem := range p.dbs {
+		if err := elem.Value.(*entry).db.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(p.dbs, key)
+	}
+	p.lru.Init()
+	return firstErr
+}
+
+// Inject will inject the pool into the dependency chain.
+func Inject(ctx context.Context, pool *Pool) context.Context {
+	return context.WithValue(ctx, poolKey, pool)
+}
+
+// Dependency will inject the pool into the dependency chain.
+type Dependency struct {
+	Pool *Pool
+}
+
+// Inject will inject the pool into the dependency chain.
+func (d Dependency) Inject(ctx context.Context) context.Context {
+	return Inject(ctx, d.Pool)
+}
+
+// Get returns the pool of the context,
+// or nil if no pool has been injected.
+func Get(ctx context.Context) *Pool {
+	pool, _ := ctx.Value(poolKey).(*Pool)
+	return pool
+}
@@ -0,0 +1,135 @@
+package sqlpool_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/dependencies/sqlpool"
+)
+
+type testDriver struct{}
+
+func (testDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("connections are not supported")
+}
+
+func init() {
+	sql.Register("sqlpool-test", testDriver{})
+}
+
+func TestPool(t *testing.T) {
+	pool := sqlpool.New(sqlpool.Config{
+		MaxOpenConns:    4,
+		ConnMaxLifetime: time.Minute,
+	})
+	ctx := sqlpool.Inject(context.Background(), pool)
+	if got := sqlpool.Get(ctx); got != pool {
+		t.Fatal("expected the injected pool")
+	}
+
+	opened := 0
+	open := func(dsn string) (*sql.DB, error) {
+		db, _, err := sqlpool.Get(ctx).Open("sqlpool-test", dsn, func() (*sql.DB, error) {
+			opened++
+			return sql.Open("sqlpool-test", dsn)
+		})
+		return db, err
+	}
+	a, err := open("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := open("a"); err != nil {
+		t.Fatal(err)
+	} else if again != a {
+		t.Error("expected the same database for the same data source")
+	}
+	b, err := open("b")
+	if err != nil {
+		t.Fatal(err)
+	} else if b == a {
+		t.Error("expected another database for another data source")
+	}
+	if opened != 2 || pool.Len() != 2 {
+		t.Errorf("expected 2 data sources to be opened, got %d and %d in the pool", opened, pool.Len())
+	}
+	if got := a.Stats().MaxOpenConnections; got != 4 {
+		t.Errorf("unexpected max open connections: want 4, got %d", got)
+	}
+
+	if err := pool.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if pool.Len() != 0 {
+		t.Errorf("expected the pool to be empty, got %d data sources", pool.Len())
+	}
+	if err := a.Ping(); err == nil {
+		t.Error("expected the database to be closed")
+	}
+}
+
+func TestPool_Evict(t *testing.T) {
+	pool := sqlpool.New(sqlpool.Config{MaxDataSources: 2})
+	defer func() { _ = pool.Close() }()
+
+	open := func(dsn string) (*sql.DB, func() error) {
+		db, release, err := pool.Open("sqlpool-test", dsn, func() (*sql.DB, error) {
+			return sql.Open("sqlpool-test", dsn)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return db, release
+	}
+	isClosed := func(db *sql.DB) bool {
+		// The test driver cannot connect, so the error
+		// tells whether the database was closed.
+		return db.Ping().Error() == "sql: database is closed"
+	}
+
+	a, releaseA := open("a")
+	b, releaseB := open("b")
+	if err := releaseB(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Opening a third data source evicts a, the least recently used,
+	// which stays open until it is released.
+	_, releaseC := open("c")
+	defer func() { _ = releaseC() }()
+	if pool.Len() != 2 {
+		t.Errorf("expected 2 data sources in the pool, got %d", pool.Len())
+	}
+	if isClosed(a) {
+		t.Error("expected the database to stay open while it is used")
+	}
+	if err := releaseA(); err != nil {
+		t.Fatal(err)
+	}
+	if !isClosed(a) {
+		t.Error("expected the evicted database to be closed when it is released")
+	}
+	if isClosed(b) {
+		t.Error("expected the recently used database to stay open")
+	}
+
+	// Opening a again opens it again and evicts b, which is not used.
+	again, releaseAgain := open("a")
+	defer func() { _ = releaseAgain() }()
+	if again == a {
+		t.Error("expected the evicted data source to be opened again")
+	}
+	if !isClosed(b) {
+		t.Error("expected the unused evicted database to be closed")
+	}
+}
+
+func TestGet_None(t *testing.T) {
+	if pool := sqlpool.Get(context.Background()); pool != nil {
+		t.Errorf("expected no pool, got %v", pool)
+	}
+}
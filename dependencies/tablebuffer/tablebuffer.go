@@ -0,0 +1,64 @@
+// Package tablebuffer provides a dependency for configuring the number
+// of rows that sources buffer in each chunk of a table.
+//
+// Small buffers reduce the latency and memory used by a query, but the
+// overhead of processing many small chunks hurts throughput. Large buffers
+// do the opposite. Each source chooses a default that suits the data it
+// reads and the dependency allows an embedder to override it.
+package tablebuffer
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/execute/table"
+)
+
+type key int
+
+const sizeKey key = iota
+
+const (
+	// DefaultSize is the default buffer size for sources
+	// that do not have a more specific default.
+	DefaultSize = table.BufferSize
+
+	// MaxSize is the largest buffer size that may be configured.
+	// Larger values are clamped to this size.
+	MaxSize = 1 << 20
+)
+
+// Inject will inject the buffer size into the dependency chain.
+// A size that is not positive removes any configured size
+// so that each source uses its own default.
+func Inject(ctx context.Context, size int) context.Context {
+	return context.WithValue(ctx, sizeKey, size)
+}
+
+// Dependency will inject the buffer size into the dependency chain.
+type Dependency struct {
+	// Size is the number of rows a source should
+	// buffer in each chunk of a table.
+	Size int
+}
+
+// Inject will inject the buffer size into the dependency chain.
+func (d Dependency) Inject(ctx context.Context) context.Context {
+	return Inject(ctx, d.Size)
+}
+
+// GetSize returns the buffer size configured in the context.
+// If no buffer size has been configured, the default for
+// the source is returned instead.
+func GetSize(ctx context.Context, def int) int {
+	size, _ := ctx.Value(sizeKey).(int)
+	if size <= 0 {
+		size = def
+	}
+	if size <= 0 {
+		size = DefaultSize
+	}
+	if size > MaxSize {
+		size = MaxSize
+	}
+	return size
+}
@@ -0,0 +1,53 @@
+package tablebuffer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux/dependencies/tablebuffer"
+)
+
+func TestGetSize(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		def  int
+		want int
+	}{
+		{
+			name: "source default",
+			ctx:  context.Background(),
+			def:  100,
+			want: 100,
+		},
+		{
+			name: "no default",
+			ctx:  context.Background(),
+			want: tablebuffer.DefaultSize,
+		},
+		{
+			name: "configured",
+			ctx:  tablebuffer.Dependency{Size: 50}.Inject(context.Background()),
+			def:  100,
+			want: 50,
+		},
+		{
+			name: "not positive",
+			ctx:  tablebuffer.Inject(context.Background(), -1),
+			def:  100,
+			want: 100,
+		},
+		{
+			name: "too large",
+			ctx:  tablebuffer.Inject(context.Background(), tablebuffer.MaxSize+1),
+			def:  100,
+			want: tablebuffer.MaxSize,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tablebuffer.GetSize(tt.ctx, tt.def); got != tt.want {
+				t.Errorf("unexpected buffer size -want/+got:\n\t- %d\n\t+ %d", tt.want, got)
+			}
+		})
+	}
+}
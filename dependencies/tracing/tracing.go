@@ -0,0 +1,116 @@
+// Package tracing provides a dependency for tracing the compilation,
+// planning and execution of queries with OpenTelemetry.
+//
+// Spans are only created when a tracer has been injected into the
+// dependency chain so tracing has no cost when it is not configured.
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// The attributes that are added to the spans of a query.
+const (
+	// QueryIDKey identifies the query that a span belongs to.
+	QueryIDKey = attribute.Key("flux.query.id")
+	// NodeIDKey identifies the plan node that a span belongs to.
+	NodeIDKey = attribute.Key("flux.node.id")
+	// OperationKey is the kind of operation that a plan node executes.
+	OperationKey = attribute.Key("flux.operation")
+	// RowsKey is the number of rows that were processed by a plan node.
+	RowsKey = attribute.Key("flux.rows")
+)
+
+type key int
+
+const (
+	tracerKey key = iota
+	queryIDKey
+)
+
+// Inject will inject the tracer into the dependency chain.
+func Inject(ctx context.Context, tracer trace.Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey, tracer)
+}
+
+// Dependency will inject the tracer into the dependency chain.
+type Dependency struct {
+	// Tracer creates the spans for each query.
+	Tracer trace.Tracer
+}
+
+// Inject will inject the tracer into the dependency chain.
+func (d Dependency) Inject(ctx context.Context) context.Context {
+	if d.Tracer == nil {
+		return ctx
+	}
+	return Inject(ctx, d.Tracer)
+}
+
+// GetTracer returns the tracer in the context.
+// It returns nil if no tracer has been injected.
+func GetTracer(ctx context.Context) trace.Tracer {
+	tracer, _ := ctx.Value(tracerKey).(trace.Tracer)
+	return tracer
+}
+
+// IsEnabled reports whether a tracer has been injected into the context.
+func IsEnabled(ctx context.Context) bool {
+	return GetTracer(ctx) != nil
+}
+
+// WithQueryID sets the identifier of the query that is added to its spans.
+// Embedders that have their own identifiers for queries should set them so
+// the traces can be found. Otherwise, the trace id is used to identify the query.
+func WithQueryID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, queryIDKey, id)
+}
+
+// QueryID returns the identifier of the query in the context.
+func QueryID(ctx context.Context) string {
+	if id, ok := ctx.Value(queryIDKey).(string); ok {
+		return id
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+var noopTracer = trace.NewNoopTracerProvider().Tracer("")
+
+// Start creates a span with the tracer in the context.
+// If there is no tracer, the context is returned unchanged
+// with a span that does nothing.
+func Start(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	tracer := GetTracer(ctx)
+	if tracer == nil {
+		_, span := noopTracer.Start(ctx, name)
+		return ctx, span
+	}
+	return tracer.Start(ctx, name, opts...)
+}
+
+// StartQuery creates the span for a query and adds the
+// identifier of the query to it. It is used by the outermost
+// operation that is performed for a query.
+func StartQuery(ctx context.Context, name string) (context.Context, trace.Span) {
+	ctx, span := Start(ctx, name)
+	if span.IsRecording() {
+		span.SetAttributes(QueryIDKey.String(QueryID(ctx)))
+	}
+	return ctx, span
+}
+
+// End ends the span and records the error, if there is one.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,70 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/influxdata/flux/dependencies/tracing"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStart_NoTracer(t *testing.T) {
+	ctx := context.Background()
+	if tracing.IsEnabled(ctx) {
+		t.Fatal("expected tracing to be disabled")
+	}
+
+	got, span := tracing.Start(ctx, "query")
+	if got != ctx {
+		t.Error("expected the context to be unchanged")
+	}
+	if span.IsRecording() {
+		t.Error("expected the span to not be recording")
+	}
+	tracing.End(span, errors.New("expected"))
+}
+
+func TestStartQuery(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	ctx := tracing.Dependency{Tracer: tp.Tracer("test")}.Inject(context.Background())
+
+	ctx, query := tracing.StartQuery(ctx, "query")
+	_, plan := tracing.Start(ctx, "plan")
+	tracing.End(plan, errors.New("expected"))
+	tracing.End(query, nil)
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("unexpected number of spans -want/+got:\n\t- 2\n\t+ %d", len(spans))
+	}
+
+	planSpan, querySpan := spans[0], spans[1]
+	if got, want := planSpan.Parent().SpanID(), querySpan.SpanContext().SpanID(); got != want {
+		t.Errorf("unexpected parent span -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	if got, want := planSpan.Status().Code, codes.Error; got != want {
+		t.Errorf("unexpected status -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	attrs := querySpan.Attributes()
+	if len(attrs) != 1 || attrs[0].Key != tracing.QueryIDKey {
+		t.Fatalf("expected the query id attribute, got %v", attrs)
+	}
+	if got, want := attrs[0].Value.AsString(), querySpan.SpanContext().TraceID().String(); got != want {
+		t.Errorf("unexpected query id -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestQueryID(t *testing.T) {
+	ctx := tracing.WithQueryID(context.Background(), "abc")
+	if got, want := tracing.QueryID(ctx), "abc"; got != want {
+		t.Errorf("unexpected query id -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	if got := tracing.QueryID(context.Background()); got != "" {
+		t.Errorf("expected no query id, got %q", got)
+	}
+}
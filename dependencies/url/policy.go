@@ -0,0 +1,217 @@
+package url
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// The packages whose connections are validated with the overrides of their name.
+const (
+	// HTTPPackage identifies the functions that send HTTP requests.
+	HTTPPackage = "http"
+	// SQLPackage identifies the functions that connect to SQL databases.
+	SQLPackage = "sql"
+)
+
+// Rules configure a Policy.
+type Rules struct {
+	// Schemes lists the schemes of the urls that are allowed.
+	// All schemes are allowed when it is empty.
+	Schemes []string `json:"schemes,omitempty"`
+	// AllowHosts lists the host names and the IP ranges that may be connected to.
+	// A host name that starts with "*." matches all of the subdomains of the rest of the name,
+	// an IP range is written in CIDR notation or as a single IP.
+	// All hosts are allowed when it is empty.
+	AllowHosts []string `json:"allowHosts,omitempty"`
+	// DenyHosts lists the host names and the IP ranges that may not be connected to,
+	// even when they are allowed by AllowHosts.
+	DenyHosts []string `json:"denyHosts,omitempty"`
+	// DenyPrivateIPs denies connections to the private IPs
+	// that are not in the IP ranges of AllowHosts.
+	DenyPrivateIPs bool `json:"denyPrivateIPs,omitempty"`
+	// Packages replaces the rules for the connections
+	// of the packages, such as HTTPPackage and SQLPackage.
+	Packages map[string]Rules `json:"packages,omitempty"`
+}
+
+// IsZero reports whether the rules allow every url.
+func (r Rules) IsZero() bool {
+	return len(r.Schemes) == 0 && len(r.AllowHosts) == 0 && len(r.DenyHosts) == 0 &&
+		!r.DenyPrivateIPs && len(r.Packages) == 0
+}
+
+// Policy validates urls with allow and deny lists of schemes, hosts and IP ranges.
+//
+// Host names are only known to Validate. When the policy validates the IPs
+// of the connections with ValidateIP, such as in the dialers of the HTTP client,
+// the IP ranges are checked but the host names are not, so a policy whose
+// AllowHosts lists host names does not deny the IPs that are not in its IP ranges.
+type Policy struct {
+	schemes        map[string]bool
+	allowNames     []string
+	allowIPs       []*net.IPNet
+	denyNames      []string
+	denyIPs        []*net.IPNet
+	denyPrivateIPs bool
+	packages       map[string]*Policy
+
+	lookupIP func(host string) ([]net.IP, error)
+}
+
+// NewPolicy creates the policy of the rules.
+func NewPolicy(rules Rules) (*Policy, error) {
+	p := &Policy{
+		denyPrivateIPs: rules.DenyPrivateIPs,
+		lookupIP:       net.LookupIP,
+	}
+	if len(rules.Schemes) > 0 {
+		p.schemes = make(map[string]bool, len(rules.Schemes))
+		for _, s := range rules.Schemes {
+			p.schemes[strings.ToLower(s)] = true
+		}
+	}
+	var err error
+	if p.allowNames, p.allowIPs, err = parseHosts(rules.AllowHosts); err != nil {
+		return nil, err
+	}
+	if p.denyNames, p.denyIPs, err = parseHosts(rules.DenyHosts); err != nil {
+		return nil, err
+	}
+	if len(rules.Packages) > 0 {
+		p.packages = make(map[string]*Policy, len(rules.Packages))
+		for pkg, r := range rules.Packages {
+			if len(r.Packages) > 0 {
+				return nil, errors.Newf(codes.Invalid, "the rules of package %q may not have package rules", pkg)
+			}
+			if p.packages[pkg], err = NewPolicy(r); err != nil {
+				return nil, errors.Wrapf(err, codes.Invalid, "invalid rules for package %q", pkg)
+			}
+		}
+	}
+	return p, nil
+}
+
+func parseHosts(hosts []string) (names []string, ips []*net.IPNet, err error) {
+	for _, h := range hosts {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if strings.Contains(h, "/") {
+			_, block, err := net.ParseCIDR(h)
+			if err != nil {
+				return nil, nil, errors.Newf(codes.Invalid, "invalid IP range %q", h)
+			}
+			ips = append(ips, block)
+		} else if ip := net.ParseIP(h); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			ips = append(ips, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+		} else if h != "" && h != "*." {
+			names = append(names, h)
+		} else {
+			return nil, nil, errors.Newf(codes.Invalid, "invalid host %q", h)
+		}
+	}
+	return names, ips, nil
+}
+
+// ForPackage returns the validator of the connections of a package.
+// It is the policy itself when the package has no rules of its own.
+func (p *Policy) ForPackage(pkg string) Validator {
+	if pp, ok := p.packages[pkg]; ok {
+		return pp
+	}
+	return p
+}
+
+func (p *Policy) Validate(u *url.URL) error {
+	if p.schemes != nil && !p.schemes[strings.ToLower(u.Scheme)] {
+		return errors.Newf(codes.Invalid, "url is not valid, scheme %q is not allowed", u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	if matchHost(p.denyNames, host) {
+		return errors.Newf(codes.Invalid, "url is not valid, host %q is denied", host)
+	}
+	allowedByName := matchHost(p.allowNames, host)
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		// The IPs of a host are only needed to check the IP ranges.
+		if len(p.denyIPs) == 0 && !p.denyPrivateIPs &&
+			(allowedByName || len(p.allowNames) == 0 && len(p.allowIPs) == 0) {
+			return nil
+		}
+		var err error
+		if ips, err = p.lookupIP(host); err != nil {
+			return err
+		}
+	}
+	for _, ip := range ips {
+		if err := p.validateIP(ip, allowedByName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Policy) ValidateIP(ip net.IP) error {
+	return p.validateIP(ip, len(p.allowNames) > 0)
+}
+
+// validateIP validates an IP of a host, which may be allowed by its name.
+func (p *Policy) validateIP(ip net.IP, allowedByName bool) error {
+	if matchIP(p.denyIPs, ip) {
+		return errors.New(codes.Invalid, "url is not valid, it connects to a denied IP")
+	}
+	allowedByIP := matchIP(p.allowIPs, ip)
+	if (len(p.allowNames) > 0 || len(p.allowIPs) > 0) && !allowedByName && !allowedByIP {
+		return errors.New(codes.Invalid, "url is not valid, it connects to a host that is not allowed")
+	}
+	if p.denyPrivateIPs && !allowedByIP && isPrivateIP(ip) {
+		return errors.New(codes.Invalid, "url is not valid, it connects to a private IP")
+	}
+	return nil
+}
+
+// matchHost reports whether a host name matches one of the names.
+func matchHost(names []string, host string) bool {
+	for _, name := range names {
+		if strings.HasPrefix(name, "*.") {
+			if strings.HasSuffix(host, name[1:]) {
+				return true
+			}
+		} else if host == name {
+			return true
+		}
+	}
+	return false
+}
+
+func matchIP(blocks []*net.IPNet, ip net.IP) bool {
+	for _, block := range blocks {
+		if block.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// PackageValidator is a validator with different rules for the
+// connections of some packages.
+type PackageValidator interface {
+	Validator
+	ForPackage(pkg string) Validator
+}
+
+// ForPackage returns the validator of the connections of a package.
+// It is the validator itself unless it is a PackageValidator.
+func ForPackage(v Validator, pkg string) Validator {
+	if pv, ok := v.(PackageValidator); ok {
+		return pv.ForPackage(pkg)
+	}
+	return v
+}
@@ -0,0 +1,183 @@
+package url_test
+
+import (
+	"net"
+	nurl "net/url"
+	"testing"
+
+	"github.com/influxdata/flux/dependencies/url"
+)
+
+func TestPolicy(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules url.Rules
+		url   string
+		valid bool
+	}{
+		{
+			name:  "no rules",
+			url:   "http://10.0.0.1",
+			valid: true,
+		},
+		{
+			name:  "allowed scheme",
+			rules: url.Rules{Schemes: []string{"https"}},
+			url:   "HTTPS://example.com",
+			valid: true,
+		},
+		{
+			name:  "denied scheme",
+			rules: url.Rules{Schemes: []string{"https"}},
+			url:   "http://example.com",
+			valid: false,
+		},
+		{
+			name:  "allowed host",
+			rules: url.Rules{AllowHosts: []string{"example.com"}},
+			url:   "http://example.com:8086",
+			valid: true,
+		},
+		{
+			name:  "allowed subdomain",
+			rules: url.Rules{AllowHosts: []string{"*.example.com"}},
+			url:   "http://api.example.com",
+			valid: true,
+		},
+		{
+			name:  "wildcard does not match the domain",
+			rules: url.Rules{AllowHosts: []string{"*.example.com"}},
+			url:   "http://1.1.1.1",
+			valid: false,
+		},
+		{
+			name:  "allowed IP range",
+			rules: url.Rules{AllowHosts: []string{"1.1.1.0/24"}},
+			url:   "http://1.1.1.1",
+			valid: true,
+		},
+		{
+			name:  "IP out of the allowed range",
+			rules: url.Rules{AllowHosts: []string{"1.1.1.0/24"}},
+			url:   "http://1.1.2.1",
+			valid: false,
+		},
+		{
+			name:  "denied host",
+			rules: url.Rules{AllowHosts: []string{"*.example.com"}, DenyHosts: []string{"admin.example.com"}},
+			url:   "http://admin.example.com",
+			valid: false,
+		},
+		{
+			name:  "denied IP",
+			rules: url.Rules{DenyHosts: []string{"1.1.1.1"}},
+			url:   "http://1.1.1.1",
+			valid: false,
+		},
+		{
+			name:  "denied IPv6 range",
+			rules: url.Rules{DenyHosts: []string{"2001:db8::/32"}},
+			url:   "http://[2001:db8::1]:8086",
+			valid: false,
+		},
+		{
+			name:  "private IP",
+			rules: url.Rules{DenyPrivateIPs: true},
+			url:   "http://192.168.1.1",
+			valid: false,
+		},
+		{
+			name:  "private host",
+			rules: url.Rules{DenyPrivateIPs: true},
+			url:   "http://localhost",
+			valid: false,
+		},
+		{
+			name:  "allowed private IP",
+			rules: url.Rules{DenyPrivateIPs: true, AllowHosts: []string{"192.168.1.0/24"}},
+			url:   "http://192.168.1.1",
+			valid: true,
+		},
+		{
+			name:  "public IP",
+			rules: url.Rules{DenyPrivateIPs: true},
+			url:   "http://1.1.1.1",
+			valid: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := url.NewPolicy(tc.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			u, err := nurl.Parse(tc.url)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Validate(u); tc.valid && err != nil {
+				t.Errorf("expected %s to be valid, got %s", tc.url, err)
+			} else if !tc.valid && err == nil {
+				t.Errorf("expected %s to be invalid", tc.url)
+			}
+		})
+	}
+}
+
+func TestPolicy_ValidateIP(t *testing.T) {
+	p, err := url.NewPolicy(url.Rules{
+		AllowHosts:     []string{"10.0.0.0/8"},
+		DenyHosts:      []string{"10.0.0.1"},
+		DenyPrivateIPs: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for ip, valid := range map[string]bool{
+		"10.1.2.3":    true,
+		"10.0.0.1":    false,
+		"192.168.0.1": false,
+		"1.1.1.1":     false,
+	} {
+		if err := p.ValidateIP(net.ParseIP(ip)); valid && err != nil {
+			t.Errorf("expected %s to be valid, got %s", ip, err)
+		} else if !valid && err == nil {
+			t.Errorf("expected %s to be invalid", ip)
+		}
+	}
+}
+
+func TestPolicy_ForPackage(t *testing.T) {
+	p, err := url.NewPolicy(url.Rules{
+		DenyPrivateIPs: true,
+		Packages: map[string]url.Rules{
+			url.SQLPackage: {AllowHosts: []string{"10.0.0.0/8"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := &nurl.URL{Scheme: "postgres", Host: "10.0.0.1:5432"}
+	if err := url.ForPackage(p, url.SQLPackage).Validate(u); err != nil {
+		t.Errorf("expected the sql rules to allow %s, got %s", u, err)
+	}
+	if err := url.ForPackage(p, url.HTTPPackage).Validate(u); err == nil {
+		t.Errorf("expected the http rules to deny %s", u)
+	}
+	if v := url.ForPackage(url.PassValidator{}, url.SQLPackage); v != (url.PassValidator{}) {
+		t.Errorf("expected the validator itself, got %T", v)
+	}
+}
+
+func TestNewPolicy_Invalid(t *testing.T) {
+	for _, rules := range []url.Rules{
+		{AllowHosts: []string{"10.0.0.0/33"}},
+		{DenyHosts: []string{""}},
+		{Packages: map[string]url.Rules{"sql": {Packages: map[string]url.Rules{"http": {}}}}},
+	} {
+		if _, err := url.NewPolicy(rules); err == nil {
+			t.Errorf("expected an error for rules %+v", rules)
+		}
+	}
+}
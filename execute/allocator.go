@@ -163,9 +163,10 @@ func (a *Allocator) Strings(l, c int) []string {
 }
 
 // AppendStrings appends strings to a slice.
-// Only the string headers are accounted for.
+// Both the string headers and the bytes of the appended strings are accounted for.
+// The string bytes must be released with FreeStrings.
 func (a *Allocator) AppendStrings(slice []string, vs ...string) []string {
-	//TODO(nathanielc): Account for actual size of strings
+	a.account(stringsSize(vs), 1)
 	if cap(slice)-len(slice) >= len(vs) {
 		return append(slice, vs...)
 	}
@@ -175,6 +176,28 @@ func (a *Allocator) AppendStrings(slice []string, vs ...string) []string {
 	return s
 }
 
+// SetString replaces the string at index i of the slice
+// and accounts for the difference in string bytes.
+func (a *Allocator) SetString(slice []string, i int, v string) {
+	a.account(len(v)-len(slice[i]), 1)
+	slice[i] = v
+}
+
+// FreeStrings informs the allocator that the bytes of the given
+// strings have been freed. The string headers are freed separately
+// using Free.
+func (a *Allocator) FreeStrings(vs ...string) {
+	a.Free(stringsSize(vs), 1)
+}
+
+func stringsSize(vs []string) int {
+	n := 0
+	for _, v := range vs {
+		n += len(v)
+	}
+	return n
+}
+
 func (a *Allocator) GrowStrings(slice []string, n int) []string {
 	newCap := len(slice) + n
 	if newCap < cap(slice) {
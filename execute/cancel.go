@@ -0,0 +1,21 @@
+package execute
+
+import "context"
+
+// CancelCheckInterval is the number of iterations between checks for
+// the cancellation of a query in loops that process individual rows.
+const CancelCheckInterval = 1024
+
+// CheckCancel returns the error from the context if it has been canceled.
+//
+// Loops that process individual rows may run for a long time before they
+// return to the dispatcher, which is where cancellation is normally
+// noticed. The context is only checked when i is a multiple of
+// CancelCheckInterval so the check is cheap enough to make on every
+// iteration of such a loop.
+func CheckCancel(ctx context.Context, i int) error {
+	if i%CancelCheckInterval != 0 {
+		return nil
+	}
+	return ctx.Err()
+}
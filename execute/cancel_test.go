@@ -0,0 +1,25 @@
+package execute_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux/execute"
+)
+
+func TestCheckCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 2*execute.CancelCheckInterval; i++ {
+		if err := execute.CheckCancel(ctx, i); err != nil {
+			t.Fatalf("unexpected error at iteration %d: %s", i, err)
+		}
+	}
+
+	cancel()
+	if err := execute.CheckCancel(ctx, 1); err != nil {
+		t.Fatalf("expected the context to only be checked periodically, got %s", err)
+	}
+	if err := execute.CheckCancel(ctx, execute.CancelCheckInterval); err != context.Canceled {
+		t.Fatalf("expected the check to be canceled, got %v", err)
+	}
+}
@@ -33,6 +33,11 @@ type ExecutionDependencies struct {
 	Metadata metadata.Metadata
 
 	ExecutionOptions *ExecutionOptions
+
+	// FragmentExecutor executes the plan fragments that were split
+	// from the plan to run on remote workers. Allowed to be nil
+	// if the plan is executed locally.
+	FragmentExecutor FragmentExecutor
 }
 
 func (d ExecutionDependencies) Inject(ctx context.Context) context.Context {
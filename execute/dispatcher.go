@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"runtime/debug"
 	"sync"
+	"sync/atomic"
 
 	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/concurrency"
 	"github.com/influxdata/flux/internal/errors"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -32,6 +34,16 @@ type poolDispatcher struct {
 
 	throughput int
 
+	// quota is shared with other queries and limits the
+	// number of workers running at the same time.
+	// A nil quota never blocks.
+	quota *concurrency.Client
+	// lost is the number of slots that were released while
+	// a worker was blocked and could not be acquired again.
+	lost int32
+	// ctx is the context the workers were started with.
+	ctx context.Context
+
 	mu      sync.Mutex
 	closed  bool
 	closing chan struct{}
@@ -71,6 +83,7 @@ func (d *poolDispatcher) Schedule(fn ScheduleFunc) {
 }
 
 func (d *poolDispatcher) Start(n int, ctx context.Context) {
+	d.ctx = ctx
 	d.wg.Add(n)
 	for i := 0; i < n; i++ {
 		go func() {
@@ -178,7 +191,9 @@ func (d *poolDispatcher) doWork(ctx context.Context) {
 			// which will wait until new work has been appended.
 			return
 		}
-		fn(ctx, d.throughput)
+		if !d.runWork(ctx, fn) {
+			return
+		}
 
 		// Check to see if the context was canceled or
 		// the dispatcher was closed. This allows us to exit
@@ -192,3 +207,49 @@ func (d *poolDispatcher) doWork(ctx context.Context) {
 		}
 	}
 }
+
+// runWork runs the scheduled function while holding a slot
+// from the concurrency quota. It returns false if the context
+// was canceled before a slot became available.
+func (d *poolDispatcher) runWork(ctx context.Context, fn ScheduleFunc) bool {
+	if err := d.quota.Acquire(ctx); err != nil {
+		return false
+	}
+	defer d.release()
+	fn(ctx, d.throughput)
+	return true
+}
+
+// release releases the slot of a worker once its work is done,
+// unless the slot was lost while the worker was blocked.
+func (d *poolDispatcher) release() {
+	for {
+		n := atomic.LoadInt32(&d.lost)
+		if n == 0 {
+			d.quota.Release()
+			return
+		}
+		if atomic.CompareAndSwapInt32(&d.lost, n, n-1) {
+			return
+		}
+	}
+}
+
+// block calls f, which blocks until the reader of a result is ready,
+// with the slot of the calling worker released. A query whose results
+// are read one after the other may then run the work that produces the
+// result being read, while the workers of the other results are blocked.
+// The slot is acquired again before block returns.
+func (d *poolDispatcher) block(f func()) {
+	if d.quota == nil {
+		f()
+		return
+	}
+	d.quota.Release()
+	f()
+	if err := d.quota.Acquire(d.ctx); err != nil {
+		// The query was canceled, so the worker finishes
+		// its work without a slot.
+		atomic.AddInt32(&d.lost, 1)
+	}
+}
@@ -2,10 +2,13 @@ package execute
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/concurrency"
 	"go.uber.org/zap/zaptest"
 )
 
@@ -81,3 +84,123 @@ func TestDispatcher_ScheduleMany(t *testing.T) {
 	cancel()
 	wg.Wait()
 }
+
+func TestDispatcher_ConcurrencyQuota(t *testing.T) {
+	quota, err := concurrency.NewQuota(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	d := newPoolDispatcher(10, zaptest.NewLogger(t))
+	d.quota = quota.NewClient()
+	d.Start(8, ctx)
+
+	var (
+		mu           sync.Mutex
+		running, max int
+		wg           sync.WaitGroup
+	)
+	wg.Add(32)
+	for i := 0; i < 32; i++ {
+		d.Schedule(func(ctx context.Context, throughput int) {
+			defer wg.Done()
+			mu.Lock()
+			running++
+			if running > max {
+				max = running
+			}
+			mu.Unlock()
+
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	if err := d.Stop(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if max > quota.Limit() {
+		t.Errorf("workers exceeded the concurrency quota: %d > %d", max, quota.Limit())
+	}
+	if got, want := quota.InUse(), 0; got != want {
+		t.Errorf("unexpected slots in use -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+}
+
+func TestDispatcher_ConcurrencyQuotaBlockedResults(t *testing.T) {
+	quota, err := concurrency.NewQuota(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	d := newPoolDispatcher(10, zaptest.NewLogger(t))
+	d.quota = quota.NewClient()
+	d.Start(4, ctx)
+
+	// There are more yields than slots and the results are read in
+	// the reverse order of their work, so the workers of the results
+	// that are not read yet block until the later results are read.
+	const yields, tables = 3, 2
+	results := make([]*result, yields)
+	for i := range results {
+		r := newResult(fmt.Sprintf("r%d", i))
+		r.tables = make(chan resultMessage)
+		r.dispatcher = d
+		results[i] = r
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		for _, r := range results {
+			r := r
+			started := make(chan struct{})
+			d.Schedule(func(ctx context.Context, throughput int) {
+				close(started)
+				for j := 0; j < tables; j++ {
+					_ = r.Process(DatasetID{}, nil)
+				}
+				r.Finish(DatasetID{}, nil)
+			})
+			// A worker runs the work that is in the queue when it
+			// is woken, so the next work is scheduled once the
+			// work is running to be run by another worker.
+			<-started
+		}
+		for i := yields - 1; i >= 0; i-- {
+			n := 0
+			if err := results[i].Do(func(flux.Table) error {
+				n++
+				return nil
+			}); err != nil {
+				done <- err
+				return
+			}
+			if n != tables {
+				done <- fmt.Errorf("result %d has %d tables, want %d", i, n, tables)
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out running the work of the results")
+	}
+
+	if err := d.Stop(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if got, want := quota.InUse(), 0; got != want {
+		t.Errorf("unexpected slots in use -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+}
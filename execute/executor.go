@@ -11,10 +11,13 @@ import (
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/concurrency"
+	"github.com/influxdata/flux/dependencies/tracing"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/metadata"
 	"github.com/influxdata/flux/plan"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -29,6 +32,11 @@ type Executor interface {
 	Execute(ctx context.Context, p *plan.Spec, a *memory.Allocator) (map[string]flux.Result, <-chan metadata.Metadata, error)
 }
 
+// DefaultSourceQueueSize is the maximum number of messages
+// that a source may queue for a downstream transformation
+// before it blocks waiting for the transformation to catch up.
+const DefaultSourceQueueSize = 16
+
 type executor struct {
 	logger *zap.Logger
 }
@@ -103,6 +111,7 @@ func (e *executor) createExecutionState(ctx context.Context, p *plan.Spec, a *me
 		dispatcher: newPoolDispatcher(10, e.logger),
 		logger:     e.logger,
 	}
+	es.dispatcher.quota = concurrency.Get(ctx).NewClient()
 	v := &createExecutionNodeVisitor{
 		es:    es,
 		nodes: make(map[plan.Node]Node),
@@ -160,9 +169,7 @@ func (v *createExecutionNodeVisitor) Visit(node plan.Node) error {
 	id := DatasetIDFromNodeID(node.ID())
 
 	if yieldSpec, ok := spec.(plan.YieldProcedureSpec); ok {
-		r := newResult(yieldSpec.YieldName())
-		v.es.results[yieldSpec.YieldName()] = r
-		v.nodes[skipYields(node)].AddTransformation(r)
+		v.addResult(yieldSpec.YieldName(), v.nodes[skipYields(node)])
 		return nil
 	}
 
@@ -229,24 +236,43 @@ func (v *createExecutionNodeVisitor) Visit(node plan.Node) error {
 		ds.SetTriggerSpec(ppn.TriggerSpec)
 		v.nodes[node] = ds
 
-		for _, p := range nonYieldPredecessors(node) {
+		preds := nonYieldPredecessors(node)
+		span := startNodeSpan(v.es.ctx, node, OperationType(tr), len(preds))
+		for _, p := range preds {
 			executionNode := v.nodes[p]
 			transport := newConsecutiveTransport(v.es.ctx, v.es.dispatcher, tr, node, v.es.logger, v.es.alloc)
+			transport.span = span
+			if _, ok := executionNode.(Source); ok {
+				// Sources run in their own goroutines and can produce
+				// tables faster than the downstream transformation is
+				// able to process them. Bound the number of messages
+				// so the source blocks instead of buffering everything.
+				transport = transport.withBoundedQueue(DefaultSourceQueueSize)
+			}
 			v.es.transports = append(v.es.transports, transport)
 			executionNode.AddTransformation(transport)
 		}
 
 		if plan.HasSideEffect(spec) && len(node.Successors()) == 0 {
 			name := string(node.ID())
-			r := newResult(name)
-			v.es.results[name] = r
-			v.nodes[skipYields(node)].AddTransformation(r)
+			v.addResult(name, v.nodes[skipYields(node)])
 		}
 	}
 
 	return nil
 }
 
+// addResult adds a result with the tables of the node.
+func (v *createExecutionNodeVisitor) addResult(name string, node Node) {
+	r := newResult(name)
+	if _, ok := node.(Source); !ok {
+		// The tables are produced by the workers of the dispatcher.
+		r.dispatcher = v.es.dispatcher
+	}
+	v.es.results[name] = r
+	node.AddTransformation(r)
+}
+
 func (es *executionState) abort(err error) {
 	for _, r := range es.results {
 		r.(*result).abort(err)
@@ -264,6 +290,12 @@ func (es *executionState) do() {
 				ctx = ctxWithSpan
 				defer span.Finish()
 			}
+			if tracing.IsEnabled(ctx) {
+				op := reflect.TypeOf(src).String()
+				var span trace.Span
+				ctx, span = tracing.Start(ctx, op, trace.WithAttributes(nodeAttributes(ctx, src.Label(), op)...))
+				defer span.End()
+			}
 			defer wg.Done()
 
 			// Setup panic handling on the source goroutines
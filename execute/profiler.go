@@ -3,9 +3,13 @@ package execute
 import (
 	"context"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/google/pprof/profile"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/values"
@@ -42,8 +46,12 @@ type OperatorProfilingResult struct {
 	// Some examples are:
 	// merged_fromRemote_range1_filter2_filter3_filter4, window5, window8, generated_yield, etc.
 	Label string
-	Start time.Time
-	Stop  time.Time
+	// MessageType is the kind of message that was being processed
+	// when the span was recorded, if any. For example, "process" for
+	// a table being processed and "flushKey" for a table flush.
+	MessageType string
+	Start       time.Time
+	Stop        time.Time
 }
 
 type OperatorProfilingSpan struct {
@@ -87,6 +95,43 @@ type operatorProfilingResultAggregate struct {
 	resultMax     int64
 	resultSum     int64
 	resultMean    float64
+	// resultPercent is the share of the total profiled
+	// duration that was spent in this operation.
+	resultPercent float64
+	// messages holds the duration sums and counts for each
+	// message type that was processed by this operation.
+	messages map[string]*operatorProfilingMessageAggregate
+}
+
+type operatorProfilingMessageAggregate struct {
+	count int64
+	sum   int64
+}
+
+func (a *operatorProfilingResultAggregate) add(result OperatorProfilingResult) {
+	a.resultCount++
+	duration := result.Stop.Sub(result.Start).Nanoseconds()
+	if duration > a.resultMax {
+		a.resultMax = duration
+	}
+	if duration < a.resultMin || a.resultMin == 0 {
+		a.resultMin = duration
+	}
+	a.resultSum += duration
+
+	if result.MessageType == "" {
+		return
+	}
+	if a.messages == nil {
+		a.messages = make(map[string]*operatorProfilingMessageAggregate)
+	}
+	m, ok := a.messages[result.MessageType]
+	if !ok {
+		m = &operatorProfilingMessageAggregate{}
+		a.messages[result.MessageType] = m
+	}
+	m.count++
+	m.sum += duration
 }
 
 type operatorProfilerLabelGroup = map[string]*operatorProfilingResultAggregate
@@ -96,6 +141,12 @@ type OperatorProfiler struct {
 	// Receive the profiling results from the spans.
 	chIn  chan OperatorProfilingResult
 	chOut chan operatorProfilingResultAggregate
+
+	// The aggregated results are read from chOut once
+	// and then cached so they can be reported in more
+	// than one format.
+	once    sync.Once
+	results []operatorProfilingResultAggregate
 }
 
 func createOperatorProfiler() Profiler {
@@ -114,18 +165,15 @@ func createOperatorProfiler() Profiler {
 			if !ok {
 				aggs[result.Type][result.Label] = &operatorProfilingResultAggregate{}
 			}
-			a := aggs[result.Type][result.Label]
-
 			// Aggregate the results
-			a.resultCount++
-			duration := result.Stop.Sub(result.Start).Nanoseconds()
-			if duration > a.resultMax {
-				a.resultMax = duration
-			}
-			if duration < a.resultMin || a.resultMin == 0 {
-				a.resultMin = duration
+			aggs[result.Type][result.Label].add(result)
+		}
+
+		var total int64
+		for _, labels := range aggs {
+			for _, agg := range labels {
+				total += agg.resultSum
 			}
-			a.resultSum += duration
 		}
 
 		// Write the aggregated results to chOut, where they'll be
@@ -133,6 +181,9 @@ func createOperatorProfiler() Profiler {
 		for typ, labels := range aggs {
 			for label, agg := range labels {
 				agg.resultMean = float64(agg.resultSum) / float64(agg.resultCount)
+				if total > 0 {
+					agg.resultPercent = float64(agg.resultSum) / float64(total) * 100
+				}
 				agg.operationType = typ
 				agg.label = label
 				p.chOut <- *agg
@@ -155,8 +206,19 @@ func (o *OperatorProfiler) closeIncomingChannel() {
 	}
 }
 
+// collectResults stops the profiler from receiving any more
+// results and returns the aggregated results.
+func (o *OperatorProfiler) collectResults() []operatorProfilingResultAggregate {
+	o.once.Do(func() {
+		o.closeIncomingChannel()
+		for agg := range o.chOut {
+			o.results = append(o.results, agg)
+		}
+	})
+	return o.results
+}
+
 func (o *OperatorProfiler) GetResult(q flux.Query, alloc *memory.Allocator) (flux.Table, error) {
-	o.closeIncomingChannel()
 	b, err := o.getTableBuilder(alloc)
 	if err != nil {
 		return nil, err
@@ -172,7 +234,6 @@ func (o *OperatorProfiler) GetResult(q flux.Query, alloc *memory.Allocator) (flu
 // on the ColListTableBuilder to make testing easier.
 // sortKeys and desc are passed directly into the Sort() call
 func (o *OperatorProfiler) GetSortedResult(q flux.Query, alloc *memory.Allocator, desc bool, sortKeys ...string) (flux.Table, error) {
-	o.closeIncomingChannel()
 	b, err := o.getTableBuilder(alloc)
 	if err != nil {
 		return nil, err
@@ -231,6 +292,10 @@ func (o *OperatorProfiler) getTableBuilder(alloc *memory.Allocator) (*ColListTab
 			Label: "MeanDuration",
 			Type:  flux.TFloat,
 		},
+		{
+			Label: "DurationPercent",
+			Type:  flux.TFloat,
+		},
 	}
 	for _, col := range colMeta {
 		if _, err := b.AddCol(col); err != nil {
@@ -238,7 +303,7 @@ func (o *OperatorProfiler) getTableBuilder(alloc *memory.Allocator) (*ColListTab
 		}
 	}
 
-	for agg := range o.chOut {
+	for _, agg := range o.collectResults() {
 		b.AppendString(0, "profiler/operator")
 		b.AppendString(1, agg.operationType)
 		b.AppendString(2, agg.label)
@@ -247,10 +312,101 @@ func (o *OperatorProfiler) getTableBuilder(alloc *memory.Allocator) (*ColListTab
 		b.AppendInt(5, agg.resultMax)
 		b.AppendInt(6, agg.resultSum)
 		b.AppendFloat(7, agg.resultMean)
+		b.AppendFloat(8, agg.resultPercent)
 	}
 	return b, nil
 }
 
+// WriteProfile writes the aggregated results of the operator profiler
+// to w in the gzipped protobuf format understood by pprof.
+//
+// Each sample is attributed to a stack with the operation type at the
+// root, the operation label beneath it and, when it is known, the kind of
+// message that was being processed at the leaf. This allows the time spent
+// flushing tables to be told apart from the time spent processing them.
+// Like GetResult, this stops the profiler from receiving new results.
+func (o *OperatorProfiler) WriteProfile(w io.Writer) error {
+	results := o.collectResults()
+
+	p := &profile.Profile{
+		SampleType: []*profile.ValueType{
+			{Type: "messages", Unit: "count"},
+			{Type: "duration", Unit: "nanoseconds"},
+		},
+		PeriodType: &profile.ValueType{Type: "duration", Unit: "nanoseconds"},
+		Period:     1,
+	}
+	locations := make(map[string]*profile.Location)
+	location := func(name string) *profile.Location {
+		if loc, ok := locations[name]; ok {
+			return loc
+		}
+		fn := &profile.Function{
+			ID:         uint64(len(p.Function) + 1),
+			Name:       name,
+			SystemName: name,
+		}
+		p.Function = append(p.Function, fn)
+		loc := &profile.Location{
+			ID:   uint64(len(p.Location) + 1),
+			Line: []profile.Line{{Function: fn}},
+		}
+		p.Location = append(p.Location, loc)
+		locations[name] = loc
+		return loc
+	}
+
+	// Sort the results so the output is deterministic.
+	sorted := make([]operatorProfilingResultAggregate, len(results))
+	copy(sorted, results)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].operationType != sorted[j].operationType {
+			return sorted[i].operationType < sorted[j].operationType
+		}
+		return sorted[i].label < sorted[j].label
+	})
+	for _, agg := range sorted {
+		typLoc := location(agg.operationType)
+		labelLoc := location(agg.operationType + "/" + agg.label)
+		if len(agg.messages) == 0 {
+			p.Sample = append(p.Sample, &profile.Sample{
+				Location: []*profile.Location{labelLoc, typLoc},
+				Value:    []int64{agg.resultCount, agg.resultSum},
+			})
+			continue
+		}
+
+		msgTypes := make([]string, 0, len(agg.messages))
+		for msgType := range agg.messages {
+			msgTypes = append(msgTypes, msgType)
+		}
+		sort.Strings(msgTypes)
+		var count, sum int64
+		for _, msgType := range msgTypes {
+			m := agg.messages[msgType]
+			p.Sample = append(p.Sample, &profile.Sample{
+				Location: []*profile.Location{
+					location(agg.operationType + "/" + agg.label + "/" + msgType),
+					labelLoc,
+					typLoc,
+				},
+				Value: []int64{m.count, m.sum},
+			})
+			count += m.count
+			sum += m.sum
+		}
+		// Attribute any time that was not associated
+		// with a message to the operation itself.
+		if count < agg.resultCount || sum < agg.resultSum {
+			p.Sample = append(p.Sample, &profile.Sample{
+				Location: []*profile.Location{labelLoc, typLoc},
+				Value:    []int64{agg.resultCount - count, agg.resultSum - sum},
+			})
+		}
+	}
+	return p.Write(w)
+}
+
 // Create a tracing span.
 // Depending on whether the Jaeger tracing and/or the operator profiling are enabled,
 // the Span produced by this function can be very different.
@@ -10,6 +10,8 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/pprof/profile"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/flux/execute"
@@ -44,22 +46,22 @@ func TestOperatorProfiler_GetResult(t *testing.T) {
 	// Build the "want" table.
 	var wantStr bytes.Buffer
 	wantStr.WriteString(`
-#datatype,string,long,string,string,string,long,long,long,long,double
-#group,false,false,true,false,false,false,false,false,false,false
-#default,_profiler,,,,,,,,,
-,result,table,_measurement,Type,Label,Count,MinDuration,MaxDuration,DurationSum,MeanDuration
+#datatype,string,long,string,string,string,long,long,long,long,double,double
+#group,false,false,true,false,false,false,false,false,false,false,false
+#default,_profiler,,,,,,,,,,
+,result,table,_measurement,Type,Label,Count,MinDuration,MaxDuration,DurationSum,MeanDuration,DurationPercent
 `)
-	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f\n",
-		"type0", "lab0", 4, 1000, 1606, 5212, 1303.0,
+	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f,%s\n",
+		"type0", "lab0", 4, 1000, 1606, 5212, 1303.0, "18.5348506401138",
 	))
-	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f\n",
-		"type1", "lab0", 4, 1101, 1707, 5616, 1404.0,
+	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f,%s\n",
+		"type1", "lab0", 4, 1101, 1707, 5616, 1404.0, "19.971550497866286",
 	))
-	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f\n",
-		"type0", "lab1", 4, 1808, 2414, 8444, 2111.0,
+	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f,%s\n",
+		"type0", "lab1", 4, 1808, 2414, 8444, 2111.0, "30.028449502133714",
 	))
-	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f\n",
-		"type1", "lab1", 4, 1909, 2515, 8848, 2212.0,
+	wantStr.WriteString(fmt.Sprintf(",,0,profiler/operator,%s,%s,%d,%d,%d,%d,%f,%s\n",
+		"type1", "lab1", 4, 1909, 2515, 8848, 2212.0, "31.4651493598862",
 	))
 	count := 16
 	wg := sync.WaitGroup{}
@@ -97,6 +99,48 @@ func TestOperatorProfiler_GetResult(t *testing.T) {
 	}
 }
 
+func TestOperatorProfiler_WriteProfile(t *testing.T) {
+	deps := execute.DefaultExecutionDependencies()
+	ctx := deps.Inject(context.Background())
+	p := configureOperatorProfiler(ctx)
+
+	st := time.Date(2020, 10, 14, 12, 30, 0, 0, time.UTC)
+	for i, msgType := range []string{"process", "process", "flushKey", ""} {
+		_, span := execute.StartSpanFromContext(ctx, "join", "join0", opentracing.StartTime(st))
+		profilerSpan := span.(*execute.OperatorProfilingSpan)
+		profilerSpan.Result.MessageType = msgType
+		profilerSpan.FinishWithOptions(opentracing.FinishOptions{
+			FinishTime: st.Add(time.Duration(i+1) * time.Microsecond),
+		})
+	}
+
+	var buf bytes.Buffer
+	if err := p.WriteProfile(&buf); err != nil {
+		t.Fatal(err)
+	}
+	prof, err := profile.Parse(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make(map[string][]int64)
+	for _, s := range prof.Sample {
+		names := make([]string, 0, len(s.Location))
+		for _, loc := range s.Location {
+			names = append(names, loc.Line[0].Function.Name)
+		}
+		got[strings.Join(names, ";")] = s.Value
+	}
+	want := map[string][]int64{
+		"join/join0/flushKey;join/join0;join": {1, 3000},
+		"join/join0/process;join/join0;join":  {2, 3000},
+		"join/join0;join":                     {1, 4000},
+	}
+	if !cmp.Equal(want, got) {
+		t.Fatalf("unexpected profile samples -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
 func TestQueryProfiler_GetResult(t *testing.T) {
 	p := &execute.QueryProfiler{}
 	q := &mock.Query{}
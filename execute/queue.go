@@ -1,6 +1,7 @@
 package execute
 
 import (
+	"context"
 	"sync"
 	"sync/atomic"
 )
@@ -62,3 +63,56 @@ func (q *unboundedMessageQueue) Pop() Message {
 	q.mu.Unlock()
 	return m
 }
+
+// boundedMessageQueue is a MessageQueue that limits the number of
+// messages that may be queued at one time.
+//
+// When the queue is full, Push blocks until the consumer pops a message
+// or the queue is done. This applies backpressure to a producer that is
+// faster than the consumer so that the memory retained by the queued
+// messages stays flat. Once the queue is done, Push no longer blocks so
+// that a blocked producer is able to observe a cancellation.
+//
+// A producer must never be the goroutine that consumes the queue or one that the
+// consumer waits on, so this should only be used for producers that run outside
+// of the dispatcher such as sources.
+type boundedMessageQueue struct {
+	unboundedMessageQueue
+	slots chan struct{}
+	ctx   context.Context
+	done  <-chan struct{}
+}
+
+func newBoundedMessageQueue(ctx context.Context, n int, done <-chan struct{}) *boundedMessageQueue {
+	return &boundedMessageQueue{
+		unboundedMessageQueue: unboundedMessageQueue{
+			buf: make([]Message, n+1),
+		},
+		slots: make(chan struct{}, n),
+		ctx:   ctx,
+		done:  done,
+	}
+}
+
+func (q *boundedMessageQueue) Push(m Message) {
+	select {
+	case q.slots <- struct{}{}:
+	case <-q.ctx.Done():
+	case <-q.done:
+	}
+	q.unboundedMessageQueue.Push(m)
+}
+
+func (q *boundedMessageQueue) Pop() Message {
+	m := q.unboundedMessageQueue.Pop()
+	if m != nil {
+		// Release the slot that was reserved by Push.
+		// The slot may not exist if the message was pushed
+		// after the queue was done.
+		select {
+		case <-q.slots:
+		default:
+		}
+	}
+	return m
+}
@@ -0,0 +1,71 @@
+package execute
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBoundedMessageQueue(t *testing.T) {
+	q := newBoundedMessageQueue(context.Background(), 2, nil)
+	q.Push(&finishMsg{})
+	q.Push(&finishMsg{})
+
+	pushed := make(chan struct{})
+	go func() {
+		defer close(pushed)
+		q.Push(&finishMsg{})
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("expected push to block on a full queue")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	if m := q.Pop(); m == nil {
+		t.Fatal("expected a message")
+	}
+
+	select {
+	case <-pushed:
+	case <-time.After(time.Second):
+		t.Fatal("expected push to unblock after a pop")
+	}
+
+	for i := 0; i < 2; i++ {
+		if m := q.Pop(); m == nil {
+			t.Fatalf("expected message %d", i)
+		}
+	}
+	if m := q.Pop(); m != nil {
+		t.Fatalf("unexpected message: %v", m)
+	}
+}
+
+func TestBoundedMessageQueue_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := newBoundedMessageQueue(ctx, 1, nil)
+	q.Push(&finishMsg{})
+
+	pushed := make(chan struct{})
+	go func() {
+		defer close(pushed)
+		q.Push(&finishMsg{})
+	}()
+
+	cancel()
+	select {
+	case <-pushed:
+	case <-time.After(time.Second):
+		t.Fatal("expected push to unblock after cancel")
+	}
+
+	// Both messages were queued even though one
+	// was pushed without reserving a slot.
+	for i := 0; i < 2; i++ {
+		if m := q.Pop(); m == nil {
+			t.Fatalf("expected message %d", i)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package execute
+
+import (
+	"context"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+)
+
+// FragmentExecutor executes plan fragments on remote workers.
+type FragmentExecutor interface {
+	// ExecuteFragment executes a fragment encoded by plan.MarshalFragment
+	// and calls f with each table of the named result of the fragment.
+	ExecuteFragment(ctx context.Context, fragment []byte, result string, f func(flux.Table) error) error
+}
+
+func init() {
+	RegisterSource(plan.RemoteKind, createRemoteSource)
+}
+
+func createRemoteSource(s plan.ProcedureSpec, id DatasetID, a Administration) (Source, error) {
+	spec, ok := s.(*plan.RemoteProcedureSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", s)
+	}
+	return CreateSourceFromIterator(&remoteIterator{spec: spec}, id)
+}
+
+// remoteIterator reads the result of a fragment from the
+// FragmentExecutor of the execution dependencies.
+type remoteIterator struct {
+	spec *plan.RemoteProcedureSpec
+}
+
+func (r *remoteIterator) Do(ctx context.Context, f func(flux.Table) error) error {
+	if !HaveExecutionDependencies(ctx) {
+		return errors.New(codes.Unimplemented, "no fragment executor to execute remote plan fragments")
+	}
+	e := GetExecutionDependencies(ctx).FragmentExecutor
+	if e == nil {
+		return errors.New(codes.Unimplemented, "no fragment executor to execute remote plan fragments")
+	}
+	return e.ExecuteFragment(ctx, r.spec.Fragment, r.spec.Result, f)
+}
@@ -0,0 +1,153 @@
+package execute_test
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/stdlib/csv"
+	"github.com/influxdata/flux/stdlib/universe"
+	"go.uber.org/zap/zaptest"
+)
+
+// localFragmentExecutor executes fragments in this
+// process the way a remote worker would.
+type localFragmentExecutor struct {
+	t *testing.T
+}
+
+func (e localFragmentExecutor) ExecuteFragment(ctx context.Context, fragment []byte, result string, f func(flux.Table) error) error {
+	p, err := plan.UnmarshalFragment(ctx, fragment)
+	if err != nil {
+		return err
+	}
+	results, _, err := execute.NewExecutor(zaptest.NewLogger(e.t)).Execute(ctx, p, executetest.UnlimitedAllocator)
+	if err != nil {
+		return err
+	}
+	r, ok := results[result]
+	if !ok {
+		return errors.Newf(codes.Internal, "fragment has no result %q", result)
+	}
+	return r.Tables().Do(f)
+}
+
+func TestExecutor_RemoteFragment(t *testing.T) {
+	const data = `#datatype,string,long,dateTime:RFC3339,double
+#group,false,false,false,false
+#default,_result,,,
+,result,table,_time,_value
+,,0,2021-01-01T00:00:00Z,1
+,,0,2021-01-01T00:00:01Z,2
+,,0,2021-01-01T00:00:02Z,3
+`
+
+	for _, tt := range []struct {
+		name     string
+		executor execute.FragmentExecutor
+		want     []*executetest.Table
+		wantErr  codes.Code
+	}{
+		{
+			name:     "local worker",
+			executor: localFragmentExecutor{t: t},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{5.0},
+				},
+			}},
+		},
+		{
+			name:    "no executor",
+			wantErr: codes.Unimplemented,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := plantest.CreatePlanSpec(&plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", &csv.FromCSVProcedureSpec{CSV: data}),
+					plan.CreatePhysicalNode("filter", &universe.FilterProcedureSpec{
+						Fn: interpreter.ResolvedFunction{
+							Fn:    executetest.FunctionExpression(t, "(r) => r._value > 1.5"),
+							Scope: runtime.Prelude(),
+						},
+					}),
+					plan.CreatePhysicalNode("sum", &universe.SumProcedureSpec{
+						SimpleAggregateConfig: execute.SimpleAggregateConfig{Columns: []string{"_value"}},
+					}),
+					plan.CreatePhysicalNode("yield", executetest.NewYieldProcedureSpec("_result")),
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+					{2, 3},
+				},
+				Resources: flux.ResourceManagement{
+					ConcurrencyQuota: 1,
+					MemoryBytesQuota: math.MaxInt64,
+				},
+				Now: time.Now(),
+			})
+
+			// The source and the filter are executed by the worker
+			// and the sum is executed on its result.
+			var filter plan.Node
+			_ = p.BottomUpWalk(func(n plan.Node) error {
+				if n.ID() == "filter" {
+					filter = n
+				}
+				return nil
+			})
+			if _, err := plan.SplitFragment(p, filter, plan.LocalCapabilities()); err != nil {
+				t.Fatal(err)
+			}
+
+			deps := execute.DefaultExecutionDependencies()
+			deps.FragmentExecutor = tt.executor
+			ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+			ctx = deps.Inject(ctx)
+
+			results, _, err := execute.NewExecutor(zaptest.NewLogger(t)).Execute(ctx, p, executetest.UnlimitedAllocator)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []*executetest.Table
+			err = results["_result"].Tables().Do(func(tbl flux.Table) error {
+				cb, err := executetest.ConvertTable(tbl)
+				if err != nil {
+					return err
+				}
+				got = append(got, cb)
+				return nil
+			})
+			if tt.want == nil {
+				if want, got := tt.wantErr, flux.ErrorCode(err); want != got {
+					t.Fatalf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+				}
+				return
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			executetest.NormalizeTables(got)
+			executetest.NormalizeTables(tt.want)
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("unexpected tables -want/+got:\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
@@ -17,6 +17,11 @@ type result struct {
 
 	abortErr chan error
 	aborted  chan struct{}
+
+	// dispatcher runs the work that produces the tables of the result.
+	// It is nil if the tables are produced by a source, which does not
+	// hold a slot of the concurrency quota.
+	dispatcher *poolDispatcher
 }
 
 type resultMessage struct {
@@ -43,13 +48,34 @@ func (s *result) RetractTable(DatasetID, flux.GroupKey) error {
 }
 
 func (s *result) Process(id DatasetID, tbl flux.Table) error {
-	select {
-	case s.tables <- resultMessage{
+	s.send(resultMessage{
 		table: tbl,
-	}:
+	})
+	return nil
+}
+
+// send sends the message to the reader of the result. If the reader
+// is not ready, the concurrency slot of the worker is released while
+// it waits so that the results of a query can be read in any order.
+func (s *result) send(msg resultMessage) {
+	select {
+	case s.tables <- msg:
+		return
 	case <-s.aborted:
+		return
+	default:
 	}
-	return nil
+	wait := func() {
+		select {
+		case s.tables <- msg:
+		case <-s.aborted:
+		}
+	}
+	if s.dispatcher == nil {
+		wait()
+		return
+	}
+	s.dispatcher.block(wait)
 }
 
 func (s *result) Tables() flux.TableIterator {
@@ -86,12 +112,9 @@ func (s *result) UpdateProcessingTime(id DatasetID, t Time) error {
 
 func (s *result) Finish(id DatasetID, err error) {
 	if err != nil {
-		select {
-		case s.tables <- resultMessage{
+		s.send(resultMessage{
 			err: err,
-		}:
-		case <-s.aborted:
-		}
+		})
 	}
 	close(s.tables)
 }
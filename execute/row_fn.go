@@ -234,6 +234,9 @@ type rowFn struct {
 }
 
 func (f *rowFn) eval(ctx context.Context, row int, cr flux.ColReader, extraParams map[string]values.Value) (values.Value, error) {
+	if err := CheckCancel(ctx, row); err != nil {
+		return nil, err
+	}
 	for j, col := range cr.Cols() {
 		f.arg0.Set(col.Label, ValueForRow(cr, row, j))
 	}
@@ -337,6 +340,17 @@ func (f *RowMapPreparedFn) Eval(ctx context.Context, row int, cr flux.ColReader)
 	return v.Object(), nil
 }
 
+// EvalRecord evaluates the function with the record instead of a row.
+// The record must have the columns the function was prepared with.
+func (f *RowMapPreparedFn) EvalRecord(ctx context.Context, record values.Object) (values.Object, error) {
+	f.args.Set(f.recordName, record)
+	v, err := f.fn.Eval(ctx, f.args)
+	if err != nil {
+		return nil, err
+	}
+	return v.Object(), nil
+}
+
 type RowReduceFn struct {
 	dynamicFn
 }
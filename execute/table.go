@@ -917,8 +917,9 @@ func (b *ColListTableBuilder) SetString(i int, j int, value string) error {
 	if err := b.checkCol(j, flux.TString); err != nil {
 		return err
 	}
-	b.cols[j].(*stringColumnBuilder).data[i] = value
-	b.cols[j].SetNil(i, false)
+	col := b.cols[j].(*stringColumnBuilder)
+	b.alloc.SetString(col.data, i, value)
+	col.SetNil(i, false)
 	return nil
 }
 
@@ -1219,26 +1220,36 @@ func (b *ColListTableBuilder) SliceColumns(start, stop int) error {
 		return fmt.Errorf("invalid start/stop parameters: %d/%d", start, stop)
 	}
 
+	// Reslicing drops the values before start from the capacity
+	// that is freed on Release so they are freed here instead.
 	for i, c := range b.cols {
 		switch c.Meta().Type {
 
 		case flux.TBool:
 			col := b.cols[i].(*boolColumnBuilder)
+			b.alloc.Free(start, boolSize)
 			col.data = col.data[start:stop]
 		case flux.TInt:
 			col := b.cols[i].(*intColumnBuilder)
+			b.alloc.Free(start, int64Size)
 			col.data = col.data[start:stop]
 		case flux.TUInt:
 			col := b.cols[i].(*uintColumnBuilder)
+			b.alloc.Free(start, uint64Size)
 			col.data = col.data[start:stop]
 		case flux.TFloat:
 			col := b.cols[i].(*floatColumnBuilder)
+			b.alloc.Free(start, float64Size)
 			col.data = col.data[start:stop]
 		case flux.TString:
 			col := b.cols[i].(*stringColumnBuilder)
+			b.alloc.FreeStrings(col.data[:start]...)
+			b.alloc.FreeStrings(col.data[stop:]...)
+			b.alloc.Free(start, stringSize)
 			col.data = col.data[start:stop]
 		case flux.TTime:
 			col := b.cols[i].(*timeColumnBuilder)
+			b.alloc.Free(start, timeSize)
 			col.data = col.data[start:stop]
 		default:
 			panic(fmt.Errorf("unexpected column type %v", c.Meta().Type))
@@ -1870,10 +1881,12 @@ type stringColumnBuilder struct {
 }
 
 func (c *stringColumnBuilder) Clear() {
+	c.alloc.FreeStrings(c.data...)
 	c.data = c.data[0:0]
 }
 
 func (c *stringColumnBuilder) Release() {
+	c.alloc.FreeStrings(c.data...)
 	c.alloc.Free(cap(c.data), stringSize)
 	c.data = nil
 }
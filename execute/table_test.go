@@ -3,6 +3,7 @@ package execute_test
 import (
 	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/array"
 	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
 	"github.com/influxdata/flux/execute/table"
@@ -569,6 +571,85 @@ func TestColListTableBuilder_AppendValues(t *testing.T) {
 	}
 }
 
+func TestColListTableBuilder_StringMemory(t *testing.T) {
+	alloc := &memory.Allocator{}
+	key := execute.NewGroupKey(nil, nil)
+	b := execute.NewColListTableBuilder(key, alloc)
+	if _, err := b.AddCol(flux.ColMeta{Label: "_value", Type: flux.TString}); err != nil {
+		t.Fatal(err)
+	}
+
+	wide := strings.Repeat("a", 1024)
+	for i := 0; i < 10; i++ {
+		if err := b.AppendString(0, wide); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The string data must be accounted for along with the headers.
+	if got, want := alloc.Allocated(), int64(10*len(wide)); got < want {
+		t.Errorf("expected string bytes to be accounted: got=%d want>=%d", got, want)
+	}
+
+	// Replacing a value accounts for the difference in size.
+	before := alloc.Allocated()
+	if err := b.SetString(0, 0, wide+wide); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := alloc.Allocated()-before, int64(len(wide)); got != want {
+		t.Errorf("unexpected accounted difference -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	// Slicing the columns frees the strings that were removed.
+	before = alloc.Allocated()
+	if err := b.SliceColumns(0, 5); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := before-alloc.Allocated(), int64(5*len(wide)); got != want {
+		t.Errorf("unexpected freed bytes -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	b.Release()
+	if got, want := alloc.Allocated(), int64(0); got != want {
+		t.Errorf("memory leak -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+}
+
+func TestColListTableBuilder_StringMemoryLimit(t *testing.T) {
+	alloc := &memory.Allocator{
+		Limit: func(v int64) *int64 { return &v }(4096),
+	}
+	key := execute.NewGroupKey(nil, nil)
+	b := execute.NewColListTableBuilder(key, alloc)
+	if _, err := b.AddCol(flux.ColMeta{Label: "_value", Type: flux.TString}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = r.(error)
+			}
+		}()
+		wide := strings.Repeat("a", 1024)
+		for i := 0; i < 10; i++ {
+			if err := b.AppendString(0, wide); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	if err == nil {
+		t.Fatal("expected memory limit error")
+	}
+	if got, want := flux.ErrorCode(err), codes.ResourceExhausted; got != want {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+	if got := alloc.MaxAllocated(); got > 4096 {
+		t.Errorf("allocated memory exceeded the limit: %d", got)
+	}
+}
+
 func TestCopyTable_Empty(t *testing.T) {
 	in := &executetest.Table{
 		GroupKey: execute.NewGroupKey(
@@ -0,0 +1,59 @@
+package execute
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/influxdata/flux/dependencies/tracing"
+	"github.com/influxdata/flux/plan"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// nodeSpan traces the execution of a transformation.
+//
+// A transformation with several predecessors receives data through
+// a transport for each of them so the span counts the rows received
+// by all of the transports and ends when the last one finishes.
+// A nil nodeSpan does nothing so it is used when tracing is disabled.
+type nodeSpan struct {
+	span    trace.Span
+	rows    int64
+	pending int32
+}
+
+func startNodeSpan(ctx context.Context, node plan.Node, op string, transports int) *nodeSpan {
+	if !tracing.IsEnabled(ctx) || transports == 0 {
+		return nil
+	}
+	_, span := tracing.Start(ctx, op, trace.WithAttributes(nodeAttributes(ctx, string(node.ID()), op)...))
+	return &nodeSpan{
+		span:    span,
+		pending: int32(transports),
+	}
+}
+
+func nodeAttributes(ctx context.Context, id, op string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		tracing.QueryIDKey.String(tracing.QueryID(ctx)),
+		tracing.NodeIDKey.String(id),
+		tracing.OperationKey.String(op),
+	}
+}
+
+// addRows records that n rows were received by the transformation.
+func (s *nodeSpan) addRows(n int) {
+	if s == nil {
+		return
+	}
+	atomic.AddInt64(&s.rows, int64(n))
+}
+
+// finish is called when a transport of the transformation has finished.
+func (s *nodeSpan) finish(err error) {
+	if s == nil || atomic.AddInt32(&s.pending, -1) > 0 {
+		return
+	}
+	s.span.SetAttributes(tracing.RowsKey.Int64(atomic.LoadInt64(&s.rows)))
+	tracing.End(s.span, err)
+}
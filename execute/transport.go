@@ -10,12 +10,14 @@ import (
 	"github.com/apache/arrow/go/arrow/memory"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/metrics"
 	"github.com/influxdata/flux/execute/table"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/internal/jaeger"
 	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/plan"
 	"github.com/opentracing/opentracing-go"
+	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
 
@@ -51,6 +53,12 @@ type consecutiveTransport struct {
 
 	schedulerState int32
 	inflight       int32
+
+	// span traces the transformation, if tracing is enabled.
+	span *nodeSpan
+	// rows counts the rows received by the
+	// transformation, if metrics are enabled.
+	rows prometheus.Counter
 }
 
 func newConsecutiveTransport(ctx context.Context, dispatcher Dispatcher, t Transformation, n plan.Node, logger *zap.Logger, mem memory.Allocator) *consecutiveTransport {
@@ -63,11 +71,21 @@ func newConsecutiveTransport(ctx context.Context, dispatcher Dispatcher, t Trans
 		messages: newMessageQueue(64),
 		op:       OperationType(t),
 		label:    string(n.ID()),
+		rows:     metrics.Get(ctx).OperationRows(OperationType(t)),
 		stack:    n.CallStack(),
 		finished: make(chan struct{}),
 	}
 }
 
+// withBoundedQueue limits the number of messages that may be queued
+// in the transport to n. Once the limit is reached, producers block until
+// the transport catches up, the transport finishes, or the execution is canceled.
+// It must be called before any messages are sent to the transport.
+func (t *consecutiveTransport) withBoundedQueue(n int) *consecutiveTransport {
+	t.messages = newBoundedMessageQueue(t.ctx, n, t.finished)
+	return t
+}
+
 func (t *consecutiveTransport) sourceInfo() string {
 	if len(t.stack) == 0 {
 		return ""
@@ -96,6 +114,11 @@ func (t *consecutiveTransport) setErr(err error) {
 		msg += " " + srcInfo
 	}
 	err = errors.Wrap(err, codes.Inherit, msg)
+	// When the transformation was created inside of user-defined
+	// functions, the message is followed by the calls that led to it.
+	if len(t.stack) > 1 {
+		err = interpreter.WithCallStack(err, t.stack)
+	}
 	t.errValue = err
 	t.errMu.Unlock()
 }
@@ -229,6 +252,7 @@ PROCESS:
 					_ = t.t.ProcessMessage(m)
 				}
 				// We are finished
+				t.span.finish(t.err())
 				close(t.finished)
 				return
 			}
@@ -257,8 +281,14 @@ PROCESS:
 // The return value is true if the message was a FinishMsg.
 func (t *consecutiveTransport) processMessage(ctx context.Context, m Message) (finished bool, err error) {
 	if _, span := StartSpanFromContext(ctx, t.op, t.label); span != nil {
+		if ps, ok := span.(*OperatorProfilingSpan); ok {
+			ps.Result.MessageType = m.Type().String()
+		}
 		defer span.Finish()
 	}
+	if m, ok := m.(ProcessChunkMsg); ok {
+		t.addRows(m.TableChunk().Len())
+	}
 	if err := t.t.ProcessMessage(m); err != nil {
 		return false, err
 	}
@@ -266,6 +296,14 @@ func (t *consecutiveTransport) processMessage(ctx context.Context, m Message) (f
 	return finished, nil
 }
 
+// addRows records that n rows were received by the transformation.
+func (t *consecutiveTransport) addRows(n int) {
+	t.span.addRows(n)
+	if t.rows != nil {
+		t.rows.Add(float64(n))
+	}
+}
+
 // Message is a message sent from one Dataset to another.
 type Message interface {
 	// Type returns the MessageType for this Message.
@@ -320,6 +358,27 @@ const (
 	FlushKeyType
 )
 
+func (t MessageType) String() string {
+	switch t {
+	case RetractTableType:
+		return "retractTable"
+	case ProcessType:
+		return "process"
+	case UpdateWatermarkType:
+		return "updateWatermark"
+	case UpdateProcessingTimeType:
+		return "updateProcessingTime"
+	case FinishType:
+		return "finish"
+	case ProcessChunkType:
+		return "processChunk"
+	case FlushKeyType:
+		return "flushKey"
+	default:
+		return fmt.Sprintf("MessageType(%d)", int(t))
+	}
+}
+
 type srcMessage DatasetID
 
 func (m srcMessage) SrcDatasetID() DatasetID {
@@ -517,6 +576,7 @@ func (t *consecutiveTransportTable) Cols() []flux.ColMeta {
 
 func (t *consecutiveTransportTable) Do(f func(flux.ColReader) error) error {
 	return t.tbl.Do(func(cr flux.ColReader) error {
+		t.transport.addRows(cr.Len())
 		if err := t.validate(cr); err != nil {
 			fields := []zap.Field{
 				zap.String("source", t.transport.sourceInfo()),
@@ -0,0 +1,896 @@
+// The messages of the Arrow Flight SQL protocol that are supported
+// by the server. They are a subset of FlightSql.proto of Apache Arrow,
+// with the same names and field numbers, so they are packed into the
+// same google.protobuf.Any messages that the clients of Flight SQL send.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: flightsql/flightsql.proto
+
+package flightsql
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// CommandStatementQuery is the descriptor of a query to execute.
+type CommandStatementQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Query is the Flux script to execute.
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// TransactionId is unused, since queries are not transactional.
+	TransactionId []byte `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+}
+
+func (x *CommandStatementQuery) Reset() {
+	*x = CommandStatementQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandStatementQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandStatementQuery) ProtoMessage() {}
+
+func (x *CommandStatementQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandStatementQuery.ProtoReflect.Descriptor instead.
+func (*CommandStatementQuery) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CommandStatementQuery) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *CommandStatementQuery) GetTransactionId() []byte {
+	if x != nil {
+		return x.TransactionId
+	}
+	return nil
+}
+
+// TicketStatementQuery is the ticket of the results of a query.
+type TicketStatementQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// StatementHandle identifies the results on the server.
+	StatementHandle []byte `protobuf:"bytes,1,opt,name=statement_handle,json=statementHandle,proto3" json:"statement_handle,omitempty"`
+}
+
+func (x *TicketStatementQuery) Reset() {
+	*x = TicketStatementQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TicketStatementQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TicketStatementQuery) ProtoMessage() {}
+
+func (x *TicketStatementQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TicketStatementQuery.ProtoReflect.Descriptor instead.
+func (*TicketStatementQuery) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TicketStatementQuery) GetStatementHandle() []byte {
+	if x != nil {
+		return x.StatementHandle
+	}
+	return nil
+}
+
+// ActionCreatePreparedStatementRequest is the body of the
+// CreatePreparedStatement action.
+type ActionCreatePreparedStatementRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Query is the Flux script of the statement.
+	Query string `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	// TransactionId is unused, since queries are not transactional.
+	TransactionId []byte `protobuf:"bytes,2,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+}
+
+func (x *ActionCreatePreparedStatementRequest) Reset() {
+	*x = ActionCreatePreparedStatementRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ActionCreatePreparedStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionCreatePreparedStatementRequest) ProtoMessage() {}
+
+func (x *ActionCreatePreparedStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionCreatePreparedStatementRequest.ProtoReflect.Descriptor instead.
+func (*ActionCreatePreparedStatementRequest) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ActionCreatePreparedStatementRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *ActionCreatePreparedStatementRequest) GetTransactionId() []byte {
+	if x != nil {
+		return x.TransactionId
+	}
+	return nil
+}
+
+// ActionCreatePreparedStatementResult is the result of the
+// CreatePreparedStatement action.
+type ActionCreatePreparedStatementResult struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// PreparedStatementHandle identifies the statement on the server.
+	PreparedStatementHandle []byte `protobuf:"bytes,1,opt,name=prepared_statement_handle,json=preparedStatementHandle,proto3" json:"prepared_statement_handle,omitempty"`
+	// DatasetSchema is the serialized schema of the results. It is empty,
+	// since the schema is not known until the statement is executed.
+	DatasetSchema []byte `protobuf:"bytes,2,opt,name=dataset_schema,json=datasetSchema,proto3" json:"dataset_schema,omitempty"`
+	// ParameterSchema is empty, since statements have no parameters.
+	ParameterSchema []byte `protobuf:"bytes,3,opt,name=parameter_schema,json=parameterSchema,proto3" json:"parameter_schema,omitempty"`
+}
+
+func (x *ActionCreatePreparedStatementResult) Reset() {
+	*x = ActionCreatePreparedStatementResult{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ActionCreatePreparedStatementResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionCreatePreparedStatementResult) ProtoMessage() {}
+
+func (x *ActionCreatePreparedStatementResult) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionCreatePreparedStatementResult.ProtoReflect.Descriptor instead.
+func (*ActionCreatePreparedStatementResult) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ActionCreatePreparedStatementResult) GetPreparedStatementHandle() []byte {
+	if x != nil {
+		return x.PreparedStatementHandle
+	}
+	return nil
+}
+
+func (x *ActionCreatePreparedStatementResult) GetDatasetSchema() []byte {
+	if x != nil {
+		return x.DatasetSchema
+	}
+	return nil
+}
+
+func (x *ActionCreatePreparedStatementResult) GetParameterSchema() []byte {
+	if x != nil {
+		return x.ParameterSchema
+	}
+	return nil
+}
+
+// ActionClosePreparedStatementRequest is the body of the
+// ClosePreparedStatement action.
+type ActionClosePreparedStatementRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// PreparedStatementHandle identifies the statement to close.
+	PreparedStatementHandle []byte `protobuf:"bytes,1,opt,name=prepared_statement_handle,json=preparedStatementHandle,proto3" json:"prepared_statement_handle,omitempty"`
+}
+
+func (x *ActionClosePreparedStatementRequest) Reset() {
+	*x = ActionClosePreparedStatementRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ActionClosePreparedStatementRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ActionClosePreparedStatementRequest) ProtoMessage() {}
+
+func (x *ActionClosePreparedStatementRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ActionClosePreparedStatementRequest.ProtoReflect.Descriptor instead.
+func (*ActionClosePreparedStatementRequest) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ActionClosePreparedStatementRequest) GetPreparedStatementHandle() []byte {
+	if x != nil {
+		return x.PreparedStatementHandle
+	}
+	return nil
+}
+
+// CommandPreparedStatementQuery is the descriptor of
+// a prepared statement to execute.
+type CommandPreparedStatementQuery struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// PreparedStatementHandle identifies the statement to execute.
+	PreparedStatementHandle []byte `protobuf:"bytes,1,opt,name=prepared_statement_handle,json=preparedStatementHandle,proto3" json:"prepared_statement_handle,omitempty"`
+}
+
+func (x *CommandPreparedStatementQuery) Reset() {
+	*x = CommandPreparedStatementQuery{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandPreparedStatementQuery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandPreparedStatementQuery) ProtoMessage() {}
+
+func (x *CommandPreparedStatementQuery) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandPreparedStatementQuery.ProtoReflect.Descriptor instead.
+func (*CommandPreparedStatementQuery) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CommandPreparedStatementQuery) GetPreparedStatementHandle() []byte {
+	if x != nil {
+		return x.PreparedStatementHandle
+	}
+	return nil
+}
+
+// CommandGetCatalogs is the descriptor of the list of catalogs.
+type CommandGetCatalogs struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CommandGetCatalogs) Reset() {
+	*x = CommandGetCatalogs{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandGetCatalogs) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandGetCatalogs) ProtoMessage() {}
+
+func (x *CommandGetCatalogs) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandGetCatalogs.ProtoReflect.Descriptor instead.
+func (*CommandGetCatalogs) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{6}
+}
+
+// CommandGetDbSchemas is the descriptor of the list of schemas.
+type CommandGetDbSchemas struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Catalog               string `protobuf:"bytes,1,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	DbSchemaFilterPattern string `protobuf:"bytes,2,opt,name=db_schema_filter_pattern,json=dbSchemaFilterPattern,proto3" json:"db_schema_filter_pattern,omitempty"`
+}
+
+func (x *CommandGetDbSchemas) Reset() {
+	*x = CommandGetDbSchemas{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandGetDbSchemas) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandGetDbSchemas) ProtoMessage() {}
+
+func (x *CommandGetDbSchemas) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandGetDbSchemas.ProtoReflect.Descriptor instead.
+func (*CommandGetDbSchemas) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CommandGetDbSchemas) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+func (x *CommandGetDbSchemas) GetDbSchemaFilterPattern() string {
+	if x != nil {
+		return x.DbSchemaFilterPattern
+	}
+	return ""
+}
+
+// CommandGetTables is the descriptor of the list of tables.
+type CommandGetTables struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Catalog                string   `protobuf:"bytes,1,opt,name=catalog,proto3" json:"catalog,omitempty"`
+	DbSchemaFilterPattern  string   `protobuf:"bytes,2,opt,name=db_schema_filter_pattern,json=dbSchemaFilterPattern,proto3" json:"db_schema_filter_pattern,omitempty"`
+	TableNameFilterPattern string   `protobuf:"bytes,3,opt,name=table_name_filter_pattern,json=tableNameFilterPattern,proto3" json:"table_name_filter_pattern,omitempty"`
+	TableTypes             []string `protobuf:"bytes,4,rep,name=table_types,json=tableTypes,proto3" json:"table_types,omitempty"`
+	IncludeSchema          bool     `protobuf:"varint,5,opt,name=include_schema,json=includeSchema,proto3" json:"include_schema,omitempty"`
+}
+
+func (x *CommandGetTables) Reset() {
+	*x = CommandGetTables{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandGetTables) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandGetTables) ProtoMessage() {}
+
+func (x *CommandGetTables) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandGetTables.ProtoReflect.Descriptor instead.
+func (*CommandGetTables) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CommandGetTables) GetCatalog() string {
+	if x != nil {
+		return x.Catalog
+	}
+	return ""
+}
+
+func (x *CommandGetTables) GetDbSchemaFilterPattern() string {
+	if x != nil {
+		return x.DbSchemaFilterPattern
+	}
+	return ""
+}
+
+func (x *CommandGetTables) GetTableNameFilterPattern() string {
+	if x != nil {
+		return x.TableNameFilterPattern
+	}
+	return ""
+}
+
+func (x *CommandGetTables) GetTableTypes() []string {
+	if x != nil {
+		return x.TableTypes
+	}
+	return nil
+}
+
+func (x *CommandGetTables) GetIncludeSchema() bool {
+	if x != nil {
+		return x.IncludeSchema
+	}
+	return false
+}
+
+// CommandGetTableTypes is the descriptor of the list of table types.
+type CommandGetTableTypes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CommandGetTableTypes) Reset() {
+	*x = CommandGetTableTypes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandGetTableTypes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandGetTableTypes) ProtoMessage() {}
+
+func (x *CommandGetTableTypes) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandGetTableTypes.ProtoReflect.Descriptor instead.
+func (*CommandGetTableTypes) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{9}
+}
+
+// CommandGetSqlInfo is the descriptor of the metadata of the server.
+type CommandGetSqlInfo struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Info are the codes of the metadata to get. Every metadata
+	// that the server has is returned when it is empty.
+	Info []uint32 `protobuf:"varint,1,rep,packed,name=info,proto3" json:"info,omitempty"`
+}
+
+func (x *CommandGetSqlInfo) Reset() {
+	*x = CommandGetSqlInfo{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_flightsql_flightsql_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CommandGetSqlInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandGetSqlInfo) ProtoMessage() {}
+
+func (x *CommandGetSqlInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_flightsql_flightsql_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandGetSqlInfo.ProtoReflect.Descriptor instead.
+func (*CommandGetSqlInfo) Descriptor() ([]byte, []int) {
+	return file_flightsql_flightsql_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CommandGetSqlInfo) GetInfo() []uint32 {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+var File_flightsql_flightsql_proto protoreflect.FileDescriptor
+
+var file_flightsql_flightsql_proto_rawDesc = []byte{
+	0x0a, 0x19, 0x66, 0x6c, 0x69, 0x67, 0x68, 0x74, 0x73, 0x71, 0x6c, 0x2f, 0x66, 0x6c, 0x69, 0x67,
+	0x68, 0x74, 0x73, 0x71, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x19, 0x61, 0x72, 0x72,
+	0x6f, 0x77, 0x2e, 0x66, 0x6c, 0x69, 0x67, 0x68, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63,
+	0x6f, 0x6c, 0x2e, 0x73, 0x71, 0x6c, 0x22, 0x54, 0x0a, 0x15, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x74,
+	0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x22, 0x41, 0x0a, 0x14,
+	0x54, 0x69, 0x63, 0x6b, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x51,
+	0x75, 0x65, 0x72, 0x79, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e,
+	0x74, 0x5f, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f,
+	0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x22,
+	0x63, 0x0a, 0x24, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x50,
+	0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x25, 0x0a,
+	0x0e, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x49, 0x64, 0x22, 0xb3, 0x01, 0x0a, 0x23, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x43,
+	0x72, 0x65, 0x61, 0x74, 0x65, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61,
+	0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x12, 0x3a, 0x0a, 0x19,
+	0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x5f, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x17, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65,
+	0x6e, 0x74, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x61, 0x74, 0x61,
+	0x73, 0x65, 0x74, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c,
+	0x52, 0x0d, 0x64, 0x61, 0x74, 0x61, 0x73, 0x65, 0x74, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x12,
+	0x29, 0x0a, 0x10, 0x70, 0x61, 0x72, 0x61, 0x6d, 0x65, 0x74, 0x65, 0x72, 0x5f, 0x73, 0x63, 0x68,
+	0x65, 0x6d, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0f, 0x70, 0x61, 0x72, 0x61, 0x6d,
+	0x65, 0x74, 0x65, 0x72, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x22, 0x61, 0x0a, 0x23, 0x41, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65,
+	0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x3a, 0x0a, 0x19, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x73, 0x74,
+	0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x17, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x22, 0x5b, 0x0a,
+	0x1d, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x50, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64,
+	0x53, 0x74, 0x61, 0x74, 0x65, 0x6d, 0x65, 0x6e, 0x74, 0x51, 0x75, 0x65, 0x72, 0x79, 0x12, 0x3a,
+	0x0a, 0x19, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x5f, 0x68, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x17, 0x70, 0x72, 0x65, 0x70, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65,
+	0x6d, 0x65, 0x6e, 0x74, 0x48, 0x61, 0x6e, 0x64, 0x6c, 0x65, 0x22, 0x14, 0x0a, 0x12, 0x43, 0x6f,
+	0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x47, 0x65, 0x74, 0x43, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x73,
+	0x22, 0x68, 0x0a, 0x13, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x47, 0x65, 0x74, 0x44, 0x62,
+	0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x61, 0x74, 0x61, 0x6c,
+	0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f,
+	0x67, 0x12, 0x37, 0x0a, 0x18, 0x64, 0x62, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x66,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x15, 0x64, 0x62, 0x53, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x22, 0xe8, 0x01, 0x0a, 0x10, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x47, 0x65, 0x74, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x63, 0x61, 0x74, 0x61, 0x6c, 0x6f, 0x67, 0x12, 0x37, 0x0a, 0x18, 0x64, 0x62, 0x5f,
+	0x73, 0x63, 0x68, 0x65, 0x6d, 0x61, 0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x5f, 0x70, 0x61,
+	0x74, 0x74, 0x65, 0x72, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x15, 0x64, 0x62, 0x53,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x50, 0x61, 0x74, 0x74, 0x65,
+	0x72, 0x6e, 0x12, 0x39, 0x0a, 0x19, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65,
+	0x5f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x5f, 0x70, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x16, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x50, 0x61, 0x74, 0x74, 0x65, 0x72, 0x6e, 0x12, 0x1f, 0x0a,
+	0x0b, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x0a, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x73, 0x12, 0x25,
+	0x0a, 0x0e, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x6d, 0x61,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x69, 0x6e, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x53,
+	0x63, 0x68, 0x65, 0x6d, 0x61, 0x22, 0x16, 0x0a, 0x14, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64,
+	0x47, 0x65, 0x74, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x54, 0x79, 0x70, 0x65, 0x73, 0x22, 0x27, 0x0a,
+	0x11, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x47, 0x65, 0x74, 0x53, 0x71, 0x6c, 0x49, 0x6e,
+	0x66, 0x6f, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d,
+	0x52, 0x04, 0x69, 0x6e, 0x66, 0x6f, 0x42, 0x26, 0x5a, 0x24, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62,
+	0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x69, 0x6e, 0x66, 0x6c, 0x75, 0x78, 0x64, 0x61, 0x74, 0x61, 0x2f,
+	0x66, 0x6c, 0x75, 0x78, 0x2f, 0x66, 0x6c, 0x69, 0x67, 0x68, 0x74, 0x73, 0x71, 0x6c, 0x62, 0x06,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_flightsql_flightsql_proto_rawDescOnce sync.Once
+	file_flightsql_flightsql_proto_rawDescData = file_flightsql_flightsql_proto_rawDesc
+)
+
+func file_flightsql_flightsql_proto_rawDescGZIP() []byte {
+	file_flightsql_flightsql_proto_rawDescOnce.Do(func() {
+		file_flightsql_flightsql_proto_rawDescData = protoimpl.X.CompressGZIP(file_flightsql_flightsql_proto_rawDescData)
+	})
+	return file_flightsql_flightsql_proto_rawDescData
+}
+
+var file_flightsql_flightsql_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_flightsql_flightsql_proto_goTypes = []interface{}{
+	(*CommandStatementQuery)(nil),                // 0: arrow.flight.protocol.sql.CommandStatementQuery
+	(*TicketStatementQuery)(nil),                 // 1: arrow.flight.protocol.sql.TicketStatementQuery
+	(*ActionCreatePreparedStatementRequest)(nil), // 2: arrow.flight.protocol.sql.ActionCreatePreparedStatementRequest
+	(*ActionCreatePreparedStatementResult)(nil),  // 3: arrow.flight.protocol.sql.ActionCreatePreparedStatementResult
+	(*ActionClosePreparedStatementRequest)(nil),  // 4: arrow.flight.protocol.sql.ActionClosePreparedStatementRequest
+	(*CommandPreparedStatementQuery)(nil),        // 5: arrow.flight.protocol.sql.CommandPreparedStatementQuery
+	(*CommandGetCatalogs)(nil),                   // 6: arrow.flight.protocol.sql.CommandGetCatalogs
+	(*CommandGetDbSchemas)(nil),                  // 7: arrow.flight.protocol.sql.CommandGetDbSchemas
+	(*CommandGetTables)(nil),                     // 8: arrow.flight.protocol.sql.CommandGetTables
+	(*CommandGetTableTypes)(nil),                 // 9: arrow.flight.protocol.sql.CommandGetTableTypes
+	(*CommandGetSqlInfo)(nil),                    // 10: arrow.flight.protocol.sql.CommandGetSqlInfo
+}
+var file_flightsql_flightsql_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_flightsql_flightsql_proto_init() }
+func file_flightsql_flightsql_proto_init() {
+	if File_flightsql_flightsql_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_flightsql_flightsql_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandStatementQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TicketStatementQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ActionCreatePreparedStatementRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ActionCreatePreparedStatementResult); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ActionClosePreparedStatementRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandPreparedStatementQuery); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandGetCatalogs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandGetDbSchemas); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandGetTables); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandGetTableTypes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_flightsql_flightsql_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CommandGetSqlInfo); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_flightsql_flightsql_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_flightsql_flightsql_proto_goTypes,
+		DependencyIndexes: file_flightsql_flightsql_proto_depIdxs,
+		MessageInfos:      file_flightsql_flightsql_proto_msgTypes,
+	}.Build()
+	File_flightsql_flightsql_proto = out.File
+	file_flightsql_flightsql_proto_rawDesc = nil
+	file_flightsql_flightsql_proto_goTypes = nil
+	file_flightsql_flightsql_proto_depIdxs = nil
+}
@@ -0,0 +1,124 @@
+package flightsql
+
+import (
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/flight"
+	"github.com/apache/arrow/go/arrow/ipc"
+	arrowmemory "github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/arrowutil"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// resultWriter streams the tables of the only result of a query as one
+// stream of record batches. The schema of a stream is written before its
+// first record batch, so it has the columns of the first table of the
+// result. The other tables may lack some of these columns, which are null
+// in their rows, but may not have other columns.
+type resultWriter struct {
+	stream flight.FlightService_DoGetServer
+	mem    arrowmemory.Allocator
+
+	name   string
+	schema *arrow.Schema
+	index  map[string]int
+	w      *flight.Writer
+}
+
+func newResultWriter(stream flight.FlightService_DoGetServer, mem arrowmemory.Allocator) *resultWriter {
+	return &resultWriter{stream: stream, mem: mem}
+}
+
+// writeResult writes the tables of a result.
+// It returns an error if it is not the first result.
+func (rw *resultWriter) writeResult(res flux.Result) error {
+	if rw.name != "" {
+		return errors.Newf(codes.Invalid, "a statement must produce one result, but it produced %q and %q", rw.name, res.Name())
+	}
+	rw.name = res.Name()
+	return res.Tables().Do(func(tbl flux.Table) error {
+		if err := rw.checkColumns(tbl.Cols()); err != nil {
+			return err
+		}
+		return tbl.Do(func(cr flux.ColReader) error {
+			if cr.Len() == 0 {
+				return nil
+			}
+			rec := rw.newRecord(cr)
+			defer rec.Release()
+			return rw.w.Write(rec)
+		})
+	})
+}
+
+// checkColumns sets the schema of the stream from the columns of the
+// first table, or checks that the columns of another table are in it.
+func (rw *resultWriter) checkColumns(cols []flux.ColMeta) error {
+	if rw.schema == nil {
+		fields := make([]arrow.Field, len(cols))
+		rw.index = make(map[string]int, len(cols))
+		for j, col := range cols {
+			typ, err := arrowutil.DataType(col.Type)
+			if err != nil {
+				return err
+			}
+			fields[j] = arrow.Field{Name: col.Label, Type: typ, Nullable: true}
+			rw.index[col.Label] = j
+		}
+		rw.schema = arrow.NewSchema(fields, nil)
+		rw.w = flight.NewRecordWriter(rw.stream, ipc.WithSchema(rw.schema), ipc.WithAllocator(rw.mem))
+		return nil
+	}
+	for _, col := range cols {
+		j, ok := rw.index[col.Label]
+		if !ok {
+			return errors.Newf(codes.Invalid, "column %q is not in the first table of the result, but the tables of a statement may only have the columns of its first table", col.Label)
+		}
+		typ, err := arrowutil.DataType(col.Type)
+		if err != nil {
+			return err
+		}
+		if field := rw.schema.Field(j); !arrow.TypeEqual(field.Type, typ) {
+			return errors.Newf(codes.Invalid, "column %q has type %v in one table and %v in another", col.Label, field.Type, typ)
+		}
+	}
+	return nil
+}
+
+// newRecord creates a record with the schema of the stream from
+// a chunk of a table. The columns the chunk lacks are null.
+func (rw *resultWriter) newRecord(cr flux.ColReader) array.Record {
+	fields := rw.schema.Fields()
+	arrs := make([]array.Interface, len(fields))
+	for j, col := range cr.Cols() {
+		arrs[rw.index[col.Label]] = arrowutil.Column(cr, j, rw.mem)
+	}
+	for j, field := range fields {
+		if arrs[j] != nil {
+			continue
+		}
+		b := array.NewBuilder(rw.mem, field.Type)
+		for i := 0; i < cr.Len(); i++ {
+			b.AppendNull()
+		}
+		arrs[j] = b.NewArray()
+		b.Release()
+	}
+	defer func() {
+		for _, arr := range arrs {
+			arr.Release()
+		}
+	}()
+	return array.NewRecord(rw.schema, arrs, int64(cr.Len()))
+}
+
+// close ends the stream. A result without tables has no columns.
+func (rw *resultWriter) close() error {
+	if rw.w == nil {
+		rw.schema = arrow.NewSchema(nil, nil)
+		rw.w = flight.NewRecordWriter(rw.stream, ipc.WithSchema(rw.schema), ipc.WithAllocator(rw.mem))
+	}
+	return rw.w.Close()
+}
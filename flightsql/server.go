@@ -0,0 +1,428 @@
+// Package flightsql implements an Arrow Flight SQL server that
+// executes Flux queries, so that the clients of Flight SQL, such as
+// the JDBC and ADBC drivers of Arrow, can query with Flux.
+//
+// The statements of the clients are Flux scripts. A statement is
+// executed when its ticket is read and its result is streamed as Arrow
+// record batches while it is executed, so the schema of the result is
+// only known once it is read. A statement must produce one result,
+// whose tables are combined into one stream with the columns of its
+// first table. Flux has no catalog of tables, so the metadata commands
+// return empty lists.
+package flightsql
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative flightsql/flightsql.proto
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/flight"
+	"github.com/apache/arrow/go/arrow/ipc"
+	arrowmemory "github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/grpcutil"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	grpccodes "google.golang.org/grpc/codes"
+)
+
+const (
+	// ActionCreatePreparedStatement is the type of the action
+	// that creates a prepared statement.
+	ActionCreatePreparedStatement = "CreatePreparedStatement"
+	// ActionClosePreparedStatement is the type of the action
+	// that closes a prepared statement.
+	ActionClosePreparedStatement = "ClosePreparedStatement"
+)
+
+const (
+	// DefaultMaxStatements is the default of Config.MaxStatements.
+	DefaultMaxStatements = 1000
+	// DefaultStatementTTL is the default of Config.StatementTTL.
+	DefaultStatementTTL = 10 * time.Minute
+)
+
+// Config configures a Server.
+type Config struct {
+	// MaxStatements is the number of prepared statements and tickets
+	// of statements that have not been read that the server keeps at
+	// once. The server refuses new statements above it until others
+	// are closed, read or expire. DefaultMaxStatements is used when it is zero.
+	MaxStatements int
+	// StatementTTL is how long a prepared statement or the ticket of
+	// a statement is kept since it was last used before it expires.
+	// DefaultStatementTTL is used when it is zero.
+	StatementTTL time.Duration
+	// MemoryBytesQuota is the number of bytes of memory each statement
+	// may use while it is executed. There is no limit when it is zero.
+	MemoryBytesQuota int64
+}
+
+// Server serves the Flight SQL protocol with the runtime of Flux.
+type Server struct {
+	deps   context.Context
+	mem    arrowmemory.Allocator
+	config Config
+
+	mu         sync.Mutex
+	statements map[string]*statement
+}
+
+// statement is a prepared statement or the ticket of a statement,
+// which is executed when it is read.
+type statement struct {
+	query    string
+	now      time.Time
+	prepared bool
+	expires  time.Time
+}
+
+// New creates a Server. The queries read their dependencies
+// from the values of ctx, such as those injected by flux.Dependencies,
+// and are canceled when their RPC is canceled.
+func New(ctx context.Context, c Config) *Server {
+	if c.MaxStatements <= 0 {
+		c.MaxStatements = DefaultMaxStatements
+	}
+	if c.StatementTTL <= 0 {
+		c.StatementTTL = DefaultStatementTTL
+	}
+	return &Server{
+		deps:       ctx,
+		mem:        arrowmemory.DefaultAllocator,
+		config:     c,
+		statements: make(map[string]*statement),
+	}
+}
+
+// Register registers the Flight service of a Server with a gRPC server.
+func Register(r grpc.ServiceRegistrar, s *Server) {
+	flight.RegisterFlightServiceService(r, &flight.FlightServiceService{
+		GetFlightInfo: s.GetFlightInfo,
+		DoGet:         s.DoGet,
+		DoAction:      s.DoAction,
+		ListActions:   s.ListActions,
+	})
+}
+
+// GetFlightInfo returns the ticket of a statement or a prepared statement,
+// which executes it when it is read. The statement is checked for syntax
+// errors, but its schema is not known until it is executed, so the flight
+// has no schema. The result of a metadata command is read with the command
+// as its ticket.
+func (s *Server) GetFlightInfo(ctx context.Context, desc *flight.FlightDescriptor) (*flight.FlightInfo, error) {
+	if desc.Type != flight.FlightDescriptor_CMD {
+		return nil, status.Error(grpccodes.InvalidArgument, "only command descriptors are supported")
+	}
+	cmd, err := unpack(desc.Cmd)
+	if err != nil {
+		return nil, grpcutil.ToStatus(err)
+	}
+	switch cmd := cmd.(type) {
+	case *CommandStatementQuery:
+		return s.statementInfo(desc, cmd.Query)
+	case *CommandPreparedStatementQuery:
+		query, err := s.preparedQuery(string(cmd.PreparedStatementHandle))
+		if err != nil {
+			return nil, grpcutil.ToStatus(err)
+		}
+		return s.statementInfo(desc, query)
+	case *CommandGetSqlInfo:
+		return &flight.FlightInfo{
+			Schema:           sqlInfoSchema(),
+			FlightDescriptor: desc,
+			Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: desc.Cmd}}},
+			TotalRecords:     int64(len(selectSQLInfo(cmd.Info))),
+			TotalBytes:       -1,
+		}, nil
+	default:
+		schema, err := metadataSchema(cmd)
+		if err != nil {
+			return nil, grpcutil.ToStatus(err)
+		}
+		return &flight.FlightInfo{
+			Schema:           flight.SerializeSchema(schema, s.mem),
+			FlightDescriptor: desc,
+			Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: desc.Cmd}}},
+			TotalBytes:       -1,
+		}, nil
+	}
+}
+
+func (s *Server) statementInfo(desc *flight.FlightDescriptor, query string) (*flight.FlightInfo, error) {
+	if query == "" {
+		return nil, status.Error(grpccodes.InvalidArgument, "query is required")
+	}
+	// The statement is compiled again when it is executed,
+	// so the syntax errors are reported to the client first.
+	if _, err := runtime.Parse(query); err != nil {
+		return nil, grpcutil.ToStatus(errors.Wrap(err, codes.Invalid, "invalid statement"))
+	}
+	handle := newHandle()
+	ticket, err := pack(&TicketStatementQuery{StatementHandle: handle})
+	if err != nil {
+		return nil, grpcutil.ToStatus(err)
+	}
+	// The time of the statement is the time the ticket was
+	// requested, rather than the time it is read.
+	if err := s.addStatement(string(handle), &statement{query: query, now: time.Now()}); err != nil {
+		return nil, grpcutil.ToStatus(err)
+	}
+	return &flight.FlightInfo{
+		FlightDescriptor: desc,
+		Endpoint:         []*flight.FlightEndpoint{{Ticket: &flight.Ticket{Ticket: ticket}}},
+		TotalRecords:     -1,
+		TotalBytes:       -1,
+	}, nil
+}
+
+// DoGet executes the statement of a ticket and streams its result,
+// or streams the result of a metadata command. The ticket of a
+// statement can only be read once.
+func (s *Server) DoGet(ticket *flight.Ticket, stream flight.FlightService_DoGetServer) error {
+	cmd, err := unpack(ticket.Ticket)
+	if err != nil {
+		return grpcutil.ToStatus(err)
+	}
+	switch cmd := cmd.(type) {
+	case *TicketStatementQuery:
+		st := s.takeStatement(string(cmd.StatementHandle))
+		if st == nil {
+			return status.Error(grpccodes.NotFound, "statement does not exist or has expired")
+		}
+		if err := s.execute(grpcutil.WithValues(stream.Context(), s.deps), st, stream); err != nil {
+			return grpcutil.ToStatus(err)
+		}
+		return nil
+	case *CommandGetSqlInfo:
+		return writeSQLInfo(stream, selectSQLInfo(cmd.Info))
+	}
+	schema, err := metadataSchema(cmd)
+	if err != nil {
+		return grpcutil.ToStatus(err)
+	}
+	w := flight.NewRecordWriter(stream, ipc.WithSchema(schema), ipc.WithAllocator(s.mem))
+	return w.Close()
+}
+
+// execute executes a statement and streams its result.
+func (s *Server) execute(ctx context.Context, st *statement, stream flight.FlightService_DoGetServer) error {
+	program, err := lang.FluxCompiler{Now: st.now, Query: st.query}.Compile(ctx, runtime.Default)
+	if err != nil {
+		return err
+	}
+	alloc := &memory.Allocator{}
+	if s.config.MemoryBytesQuota > 0 {
+		limit := s.config.MemoryBytesQuota
+		alloc.Limit = &limit
+	}
+	q, err := control.Start(ctx, program, alloc)
+	if err != nil {
+		return err
+	}
+	defer q.Done()
+
+	w := newResultWriter(stream, s.mem)
+	for res := range q.Results() {
+		if err := w.writeResult(res); err != nil {
+			q.Cancel()
+			return err
+		}
+	}
+	q.Done()
+	if err := q.Err(); err != nil {
+		return err
+	}
+	return w.close()
+}
+
+// DoAction creates and closes prepared statements.
+func (s *Server) DoAction(action *flight.Action, stream flight.FlightService_DoActionServer) error {
+	switch action.Type {
+	case ActionCreatePreparedStatement:
+		var req ActionCreatePreparedStatementRequest
+		if err := unpackTo(action.Body, &req); err != nil {
+			return grpcutil.ToStatus(err)
+		}
+		if req.Query == "" {
+			return status.Error(grpccodes.InvalidArgument, "query is required")
+		}
+		handle := newHandle()
+		body, err := pack(&ActionCreatePreparedStatementResult{PreparedStatementHandle: handle})
+		if err != nil {
+			return grpcutil.ToStatus(err)
+		}
+		if err := s.addStatement(string(handle), &statement{query: req.Query, prepared: true}); err != nil {
+			return grpcutil.ToStatus(err)
+		}
+		return stream.Send(&flight.Result{Body: body})
+	case ActionClosePreparedStatement:
+		var req ActionClosePreparedStatementRequest
+		if err := unpackTo(action.Body, &req); err != nil {
+			return grpcutil.ToStatus(err)
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if st, ok := s.statements[string(req.PreparedStatementHandle)]; !ok || !st.prepared {
+			return status.Error(grpccodes.NotFound, "prepared statement does not exist")
+		}
+		delete(s.statements, string(req.PreparedStatementHandle))
+		return nil
+	default:
+		return status.Errorf(grpccodes.Unimplemented, "action %q is not supported", action.Type)
+	}
+}
+
+// ListActions lists the actions of DoAction.
+func (s *Server) ListActions(_ *flight.Empty, stream flight.FlightService_ListActionsServer) error {
+	for _, a := range []*flight.ActionType{
+		{Type: ActionCreatePreparedStatement, Description: "Creates a prepared statement from a Flux script."},
+		{Type: ActionClosePreparedStatement, Description: "Closes a prepared statement."},
+	} {
+		if err := stream.Send(a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addStatement adds a statement after discarding the statements
+// that have expired. It returns an error if the server has
+// Config.MaxStatements statements.
+func (s *Server) addStatement(handle string, st *statement) error {
+	now := time.Now()
+	st.expires = now.Add(s.config.StatementTTL)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for h, st := range s.statements {
+		if now.After(st.expires) {
+			delete(s.statements, h)
+		}
+	}
+	if len(s.statements) >= s.config.MaxStatements {
+		return errors.Newf(codes.ResourceExhausted, "the server has %d statements, close prepared statements or read the results of statements before creating more", len(s.statements))
+	}
+	s.statements[handle] = st
+	return nil
+}
+
+// preparedQuery returns the query of a prepared statement
+// and keeps the statement from expiring.
+func (s *Server) preparedQuery(handle string) (string, error) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.statements[handle]
+	if !ok || !st.prepared || now.After(st.expires) {
+		return "", errors.New(codes.NotFound, "prepared statement does not exist or has expired")
+	}
+	st.expires = now.Add(s.config.StatementTTL)
+	return st.query, nil
+}
+
+// takeStatement removes the statement of a ticket.
+func (s *Server) takeStatement(handle string) *statement {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.statements[handle]
+	if !ok || st.prepared {
+		return nil
+	}
+	delete(s.statements, handle)
+	if time.Now().After(st.expires) {
+		return nil
+	}
+	return st
+}
+
+// metadataSchema returns the schema of the result of a metadata command.
+func metadataSchema(cmd proto.Message) (*arrow.Schema, error) {
+	str := func(name string, nullable bool) arrow.Field {
+		return arrow.Field{Name: name, Type: arrow.BinaryTypes.String, Nullable: nullable}
+	}
+	switch cmd := cmd.(type) {
+	case *CommandGetCatalogs:
+		return arrow.NewSchema([]arrow.Field{
+			str("catalog_name", false),
+		}, nil), nil
+	case *CommandGetDbSchemas:
+		return arrow.NewSchema([]arrow.Field{
+			str("catalog_name", true),
+			str("db_schema_name", false),
+		}, nil), nil
+	case *CommandGetTables:
+		fields := []arrow.Field{
+			str("catalog_name", true),
+			str("db_schema_name", true),
+			str("table_name", false),
+			str("table_type", false),
+		}
+		if cmd.IncludeSchema {
+			fields = append(fields, arrow.Field{Name: "table_schema", Type: arrow.BinaryTypes.Binary})
+		}
+		return arrow.NewSchema(fields, nil), nil
+	case *CommandGetTableTypes:
+		return arrow.NewSchema([]arrow.Field{
+			str("table_type", false),
+		}, nil), nil
+	default:
+		return nil, errors.Newf(codes.Unimplemented, "command %s is not supported", proto.MessageName(cmd))
+	}
+}
+
+// pack serializes a message as a google.protobuf.Any,
+// which is how the commands of Flight SQL are sent.
+func pack(m proto.Message) ([]byte, error) {
+	a, err := anypb.New(m)
+	if err != nil {
+		return nil, err
+	}
+	return proto.Marshal(a)
+}
+
+// unpack deserializes a message that was serialized with pack.
+func unpack(b []byte) (proto.Message, error) {
+	var a anypb.Any
+	if err := proto.Unmarshal(b, &a); err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid command")
+	}
+	m, err := a.UnmarshalNew()
+	if err != nil {
+		return nil, errors.Newf(codes.Unimplemented, "command %s is not supported", a.TypeUrl)
+	}
+	return m, nil
+}
+
+// unpackTo deserializes a message of a known type that was serialized with pack.
+func unpackTo(b []byte, m proto.Message) error {
+	var a anypb.Any
+	if err := proto.Unmarshal(b, &a); err != nil {
+		return errors.Wrap(err, codes.Invalid, "invalid request")
+	}
+	if err := a.UnmarshalTo(m); err != nil {
+		return errors.Wrap(err, codes.Invalid, "invalid request")
+	}
+	return nil
+}
+
+func newHandle() []byte {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return []byte(hex.EncodeToString(b[:]))
+}
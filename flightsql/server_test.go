@@ -0,0 +1,376 @@
+package flightsql_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/flight"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/flightsql"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func newTestClient(t *testing.T) flight.FlightServiceClient {
+	t.Helper()
+	return newTestClientWithConfig(t, flightsql.Config{})
+}
+
+func newTestClientWithConfig(t *testing.T, c flightsql.Config) flight.FlightServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	flightsql.Register(srv, flightsql.New(ctx, c))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial("bufconn",
+		grpc.WithInsecure(),
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return flight.NewFlightServiceClient(conn)
+}
+
+func pack(t *testing.T, m proto.Message) []byte {
+	t.Helper()
+	a, err := anypb.New(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := proto.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func getFlightInfo(client flight.FlightServiceClient, cmd []byte) (*flight.FlightInfo, error) {
+	return client.GetFlightInfo(context.Background(), &flight.FlightDescriptor{
+		Type: flight.FlightDescriptor_CMD,
+		Cmd:  cmd,
+	})
+}
+
+// readRows reads the rows of the ticket of a flight as strings.
+func readRows(t *testing.T, client flight.FlightServiceClient, info *flight.FlightInfo) (columns []string, rows [][]string) {
+	t.Helper()
+	stream, err := client.DoGet(context.Background(), info.Endpoint[0].Ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := flight.NewRecordReader(stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Release()
+	for _, field := range r.Schema().Fields() {
+		columns = append(columns, field.Name)
+	}
+	for r.Next() {
+		rec := r.Record()
+		for i := 0; i < int(rec.NumRows()); i++ {
+			var row []string
+			for j := range columns {
+				switch col := rec.Column(j); {
+				case col.IsNull(i):
+					row = append(row, "null")
+				case col.DataType().Name() == "utf8":
+					row = append(row, col.(*array.String).Value(i))
+				default:
+					row = append(row, col.DataType().Name())
+				}
+			}
+			rows = append(rows, row)
+		}
+	}
+	return columns, rows
+}
+
+func TestServer_StatementQuery(t *testing.T) {
+	client := newTestClient(t)
+	info, err := getFlightInfo(client, pack(t, &flightsql.CommandStatementQuery{
+		Query: `
+import "array"
+
+array.from(rows: [
+    {host: "a", field: "x", _value: 1.5},
+    {host: "a", field: "y", _value: 2.5},
+    {host: "b", field: "x", _value: 3.5},
+])
+    |> group(columns: ["host"])
+    |> pivot(rowKey: ["host"], columnKey: ["field"], valueColumn: "_value")
+`,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the statement is executed when it is read.
+	if info.TotalRecords != -1 {
+		t.Errorf("unexpected total records: %d", info.TotalRecords)
+	}
+
+	columns, rows := readRows(t, client, info)
+	if want := []string{"host", "x", "y"}; !cmp.Equal(want, columns) {
+		t.Errorf("unexpected columns -want/+got:\n%s", cmp.Diff(want, columns))
+	}
+	// the second table lacks a column of the first.
+	if want := [][]string{{"a", "float64", "float64"}, {"b", "float64", "null"}}; !cmp.Equal(want, rows) {
+		t.Errorf("unexpected rows -want/+got:\n%s", cmp.Diff(want, rows))
+	}
+
+	// the ticket can only be read once.
+	stream, err := client.DoGet(context.Background(), info.Endpoint[0].Ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestServer_StatementQueryError(t *testing.T) {
+	client := newTestClient(t)
+	for _, tc := range []struct {
+		name  string
+		query string
+		code  codes.Code
+	}{
+		{name: "empty", query: "", code: codes.InvalidArgument},
+		{name: "syntax", query: `from(bucket: `, code: codes.InvalidArgument},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := getFlightInfo(client, pack(t, &flightsql.CommandStatementQuery{Query: tc.query}))
+			if status.Code(err) != tc.code {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestServer_DoGetError(t *testing.T) {
+	client := newTestClient(t)
+	for _, tc := range []struct {
+		name  string
+		query string
+		code  codes.Code
+	}{
+		{name: "invalid", query: `from(bucket: 1)`, code: codes.InvalidArgument},
+		{
+			name: "multiple results",
+			query: `
+import "array"
+
+array.from(rows: [{_value: 1}]) |> yield(name: "a")
+array.from(rows: [{_value: 2}]) |> yield(name: "b")
+`,
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "extra column",
+			query: `
+import "array"
+
+array.from(rows: [
+    {host: "a", field: "x", _value: 1.5},
+    {host: "b", field: "x", _value: 2.5},
+    {host: "b", field: "y", _value: 3.5},
+])
+    |> group(columns: ["host"])
+    |> pivot(rowKey: ["host"], columnKey: ["field"], valueColumn: "_value")
+`,
+			code: codes.InvalidArgument,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			info, err := getFlightInfo(client, pack(t, &flightsql.CommandStatementQuery{Query: tc.query}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			stream, err := client.DoGet(context.Background(), info.Endpoint[0].Ticket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for {
+				if _, err = stream.Recv(); err != nil {
+					break
+				}
+			}
+			if status.Code(err) != tc.code {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestServer_MaxStatements(t *testing.T) {
+	client := newTestClientWithConfig(t, flightsql.Config{MaxStatements: 1})
+	cmd := pack(t, &flightsql.CommandStatementQuery{Query: `import "array" array.from(rows: [{host: "a"}])`})
+	info, err := getFlightInfo(client, cmd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := getFlightInfo(client, cmd); status.Code(err) != codes.ResourceExhausted {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// the ticket no longer counts once it is read.
+	readRows(t, client, info)
+	if _, err := getFlightInfo(client, cmd); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestServer_StatementTTL(t *testing.T) {
+	client := newTestClientWithConfig(t, flightsql.Config{StatementTTL: time.Nanosecond})
+	info, err := getFlightInfo(client, pack(t, &flightsql.CommandStatementQuery{Query: `import "array" array.from(rows: [{host: "a"}])`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond)
+	stream, err := client.DoGet(context.Background(), info.Endpoint[0].Ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestServer_PreparedStatement(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+	stream, err := client.DoAction(ctx, &flight.Action{
+		Type: flightsql.ActionCreatePreparedStatement,
+		Body: pack(t, &flightsql.ActionCreatePreparedStatementRequest{
+			Query: `import "array" array.from(rows: [{host: "a"}, {host: "b"}])`,
+		}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var a anypb.Any
+	if err := proto.Unmarshal(res.Body, &a); err != nil {
+		t.Fatal(err)
+	}
+	var prepared flightsql.ActionCreatePreparedStatementResult
+	if err := a.UnmarshalTo(&prepared); err != nil {
+		t.Fatal(err)
+	}
+
+	// a prepared statement can be executed more than once.
+	for i := 0; i < 2; i++ {
+		info, err := getFlightInfo(client, pack(t, &flightsql.CommandPreparedStatementQuery{
+			PreparedStatementHandle: prepared.PreparedStatementHandle,
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		columns, rows := readRows(t, client, info)
+		if want := []string{"host"}; !cmp.Equal(want, columns) {
+			t.Errorf("unexpected columns -want/+got:\n%s", cmp.Diff(want, columns))
+		}
+		if want := [][]string{{"a"}, {"b"}}; !cmp.Equal(want, rows) {
+			t.Errorf("unexpected rows -want/+got:\n%s", cmp.Diff(want, rows))
+		}
+	}
+
+	closeStatement := func() error {
+		stream, err := client.DoAction(ctx, &flight.Action{
+			Type: flightsql.ActionClosePreparedStatement,
+			Body: pack(t, &flightsql.ActionClosePreparedStatementRequest{
+				PreparedStatementHandle: prepared.PreparedStatementHandle,
+			}),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := stream.Recv(); err != io.EOF {
+			return err
+		}
+		return nil
+	}
+	if err := closeStatement(); err != nil {
+		t.Fatal(err)
+	}
+	if err := closeStatement(); status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+	_, err = getFlightInfo(client, pack(t, &flightsql.CommandPreparedStatementQuery{
+		PreparedStatementHandle: prepared.PreparedStatementHandle,
+	}))
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestServer_Metadata(t *testing.T) {
+	client := newTestClient(t)
+	info, err := getFlightInfo(client, pack(t, &flightsql.CommandGetTables{IncludeSchema: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns, rows := readRows(t, client, info)
+	if want := []string{"catalog_name", "db_schema_name", "table_name", "table_type", "table_schema"}; !cmp.Equal(want, columns) {
+		t.Errorf("unexpected columns -want/+got:\n%s", cmp.Diff(want, columns))
+	}
+	if len(rows) != 0 {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+
+	// the metadata of the server is a column of dense unions.
+	info, err = getFlightInfo(client, pack(t, &flightsql.CommandGetSqlInfo{Info: []uint32{0, 3}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TotalRecords != 2 {
+		t.Errorf("unexpected total records: %d", info.TotalRecords)
+	}
+	stream, err := client.DoGet(context.Background(), info.Endpoint[0].Ticket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []*flight.FlightData
+	for {
+		data, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, data)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("expected a schema and a record batch, got %d messages", len(messages))
+	}
+	if !bytes.Contains(messages[1].DataBody, []byte("flux")) {
+		t.Errorf("expected the name of the server in the record batch")
+	}
+
+	// the commands that are not supported are unimplemented.
+	_, err = getFlightInfo(client, pack(t, &flight.Criteria{}))
+	if status.Code(err) != codes.Unimplemented {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
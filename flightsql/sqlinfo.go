@@ -0,0 +1,403 @@
+package flightsql
+
+import (
+	"encoding/binary"
+	"runtime/debug"
+
+	"github.com/apache/arrow/go/arrow/flight"
+	flatbuffers "github.com/google/flatbuffers/go"
+)
+
+// The codes of the metadata of the server returned by CommandGetSqlInfo.
+// They are the values of the SqlInfo enum of FlightSql.proto.
+const (
+	sqlInfoServerName         = 0
+	sqlInfoServerVersion      = 1
+	sqlInfoServerArrowVersion = 2
+	sqlInfoServerReadOnly     = 3
+	sqlInfoServerSQL          = 4
+	sqlInfoServerSubstrait    = 5
+	sqlInfoDDLCatalog         = 500
+	sqlInfoDDLSchema          = 501
+	sqlInfoDDLTable           = 502
+)
+
+// sqlInfo is the value of a metadata of the server,
+// which is either a string or a bool.
+type sqlInfo struct {
+	code   uint32
+	str    string
+	b      bool
+	isBool bool
+}
+
+// sqlInfos are the metadata of the server in the order of their codes.
+// The server does not execute SQL and the statements cannot change
+// the catalog, since Flux has none.
+var sqlInfos = []sqlInfo{
+	{code: sqlInfoServerName, str: "flux"},
+	{code: sqlInfoServerVersion, str: moduleVersion("github.com/influxdata/flux")},
+	{code: sqlInfoServerArrowVersion, str: moduleVersion("github.com/apache/arrow/go/arrow")},
+	{code: sqlInfoServerReadOnly, b: true, isBool: true},
+	{code: sqlInfoServerSQL, b: false, isBool: true},
+	{code: sqlInfoServerSubstrait, b: false, isBool: true},
+	{code: sqlInfoDDLCatalog, b: false, isBool: true},
+	{code: sqlInfoDDLSchema, b: false, isBool: true},
+	{code: sqlInfoDDLTable, b: false, isBool: true},
+}
+
+// moduleVersion returns the version of a module of the binary.
+func moduleVersion(path string) string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "unknown"
+	}
+	m := &bi.Main
+	if m.Path != path {
+		m = nil
+		for _, dep := range bi.Deps {
+			if dep.Path == path {
+				m = dep
+				break
+			}
+		}
+	}
+	if m == nil {
+		return "unknown"
+	}
+	if m.Replace != nil {
+		m = m.Replace
+	}
+	return m.Version
+}
+
+// selectSQLInfo returns the metadata with the given codes in their order,
+// or every metadata if there are no codes. The codes of metadata the
+// server does not have are ignored.
+func selectSQLInfo(codes []uint32) []sqlInfo {
+	if len(codes) == 0 {
+		return sqlInfos
+	}
+	var infos []sqlInfo
+	for _, code := range codes {
+		for _, info := range sqlInfos {
+			if info.code == code {
+				infos = append(infos, info)
+				break
+			}
+		}
+	}
+	return infos
+}
+
+// The result of CommandGetSqlInfo has a column of dense unions, which
+// the Arrow library of this module cannot build, so its IPC messages
+// are encoded here. The result has this schema:
+//
+//	info_name: uint32 not null
+//	value: dense_union<
+//	    string_value: utf8,
+//	    bool_value: bool,
+//	    bigint_value: int64,
+//	    int32_bitmask: int32,
+//	    string_list: list<item: utf8>,
+//	    int32_to_int32_list_map: map<int32, list<item: int32>>,
+//	> not null
+
+// The values of the enums of the flatbuffers of the Arrow format.
+const (
+	fbMetadataV5          = 4
+	fbHeaderSchema        = 1
+	fbHeaderRecordBatch   = 3
+	fbTypeInt             = 2
+	fbTypeUtf8            = 5
+	fbTypeBool            = 6
+	fbTypeList            = 12
+	fbTypeStruct          = 13
+	fbTypeUnion           = 14
+	fbTypeMap             = 17
+	fbUnionModeDense      = 1
+	fbContinuationMarker  = 0xFFFFFFFF
+	fbStringValueTypeID   = 0
+	fbBoolValueTypeID     = 1
+	fbSQLInfoUnionMembers = 6
+)
+
+// fbField describes a field of a flatbuffer schema.
+type fbField struct {
+	name     string
+	nullable bool
+	typeType byte
+	// typ builds the table of the type, which is empty if it is nil.
+	typ      func(b *flatbuffers.Builder) flatbuffers.UOffsetT
+	children []fbField
+}
+
+func fbInt(bitWidth int32, signed bool) func(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+	return func(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+		b.StartObject(2)
+		b.PrependInt32Slot(0, bitWidth, 0)
+		b.PrependBoolSlot(1, signed, false)
+		return b.EndObject()
+	}
+}
+
+func (f fbField) build(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+	children := make([]flatbuffers.UOffsetT, len(f.children))
+	for i, c := range f.children {
+		children[i] = c.build(b)
+	}
+	b.StartVector(4, len(children), 4)
+	for i := len(children) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(children[i])
+	}
+	childrenVec := b.EndVector(len(children))
+	name := b.CreateString(f.name)
+	var typ flatbuffers.UOffsetT
+	if f.typ != nil {
+		typ = f.typ(b)
+	} else {
+		b.StartObject(0)
+		typ = b.EndObject()
+	}
+
+	b.StartObject(7)
+	b.PrependUOffsetTSlot(0, name, 0)
+	b.PrependBoolSlot(1, f.nullable, false)
+	b.PrependByteSlot(2, f.typeType, 0)
+	b.PrependUOffsetTSlot(3, typ, 0)
+	b.PrependUOffsetTSlot(5, childrenVec, 0)
+	return b.EndObject()
+}
+
+var sqlInfoFields = []fbField{
+	{name: "info_name", typeType: fbTypeInt, typ: fbInt(32, false)},
+	{
+		name:     "value",
+		typeType: fbTypeUnion,
+		typ: func(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+			b.StartVector(4, fbSQLInfoUnionMembers, 4)
+			for id := fbSQLInfoUnionMembers - 1; id >= 0; id-- {
+				b.PrependInt32(int32(id))
+			}
+			ids := b.EndVector(fbSQLInfoUnionMembers)
+			b.StartObject(2)
+			b.PrependInt16Slot(0, fbUnionModeDense, 0)
+			b.PrependUOffsetTSlot(1, ids, 0)
+			return b.EndObject()
+		},
+		children: []fbField{
+			{name: "string_value", nullable: true, typeType: fbTypeUtf8},
+			{name: "bool_value", nullable: true, typeType: fbTypeBool},
+			{name: "bigint_value", nullable: true, typeType: fbTypeInt, typ: fbInt(64, true)},
+			{name: "int32_bitmask", nullable: true, typeType: fbTypeInt, typ: fbInt(32, true)},
+			{name: "string_list", nullable: true, typeType: fbTypeList, children: []fbField{
+				{name: "item", nullable: true, typeType: fbTypeUtf8},
+			}},
+			{
+				name:     "int32_to_int32_list_map",
+				nullable: true,
+				typeType: fbTypeMap,
+				typ: func(b *flatbuffers.Builder) flatbuffers.UOffsetT {
+					b.StartObject(1)
+					b.PrependBoolSlot(0, false, false)
+					return b.EndObject()
+				},
+				children: []fbField{
+					{name: "entries", typeType: fbTypeStruct, children: []fbField{
+						{name: "key", typeType: fbTypeInt, typ: fbInt(32, true)},
+						{name: "value", nullable: true, typeType: fbTypeList, children: []fbField{
+							{name: "item", nullable: true, typeType: fbTypeInt, typ: fbInt(32, true)},
+						}},
+					}},
+				},
+			},
+		},
+	},
+}
+
+// fbMessage finishes a message with its header.
+func fbMessage(b *flatbuffers.Builder, headerType byte, header flatbuffers.UOffsetT, bodyLength int64) []byte {
+	b.StartObject(5)
+	b.PrependInt16Slot(0, fbMetadataV5, 0)
+	b.PrependByteSlot(1, headerType, 0)
+	b.PrependUOffsetTSlot(2, header, 0)
+	b.PrependInt64Slot(3, bodyLength, 0)
+	b.Finish(b.EndObject())
+	return b.FinishedBytes()
+}
+
+// sqlInfoSchemaMessage encodes the message of the schema.
+func sqlInfoSchemaMessage() []byte {
+	b := flatbuffers.NewBuilder(1024)
+	fields := make([]flatbuffers.UOffsetT, len(sqlInfoFields))
+	for i, f := range sqlInfoFields {
+		fields[i] = f.build(b)
+	}
+	b.StartVector(4, len(fields), 4)
+	for i := len(fields) - 1; i >= 0; i-- {
+		b.PrependUOffsetT(fields[i])
+	}
+	fieldsVec := b.EndVector(len(fields))
+	b.StartObject(4)
+	b.PrependUOffsetTSlot(1, fieldsVec, 0)
+	return fbMessage(b, fbHeaderSchema, b.EndObject(), 0)
+}
+
+// sqlInfoSchema encodes the schema as an IPC stream
+// without record batches, like flight.SerializeSchema.
+func sqlInfoSchema() []byte {
+	msg := sqlInfoSchemaMessage()
+	// The message is padded to 8 bytes.
+	n := (len(msg) + 7) &^ 7
+	buf := make([]byte, 8+n+8)
+	binary.LittleEndian.PutUint32(buf[0:], fbContinuationMarker)
+	binary.LittleEndian.PutUint32(buf[4:], uint32(n))
+	copy(buf[8:], msg)
+	// The end of the stream.
+	binary.LittleEndian.PutUint32(buf[8+n:], fbContinuationMarker)
+	return buf
+}
+
+// recordBatch accumulates the field nodes and buffers of a record batch.
+type recordBatch struct {
+	nodes   [][2]int64
+	buffers [][2]int64
+	body    []byte
+}
+
+func (rb *recordBatch) node(length int) {
+	rb.nodes = append(rb.nodes, [2]int64{int64(length), 0})
+}
+
+// buffer appends a buffer to the body, padded to 8 bytes.
+func (rb *recordBatch) buffer(data []byte) {
+	rb.buffers = append(rb.buffers, [2]int64{int64(len(rb.body)), int64(len(data))})
+	rb.body = append(rb.body, data...)
+	for len(rb.body)%8 != 0 {
+		rb.body = append(rb.body, 0)
+	}
+}
+
+// emptyValidity is the validity buffer of an array without nulls.
+var emptyValidity []byte
+
+// zeroOffset is the offsets buffer of an empty array of lists or strings.
+var zeroOffset = make([]byte, 4)
+
+func (rb *recordBatch) message(length int) []byte {
+	b := flatbuffers.NewBuilder(1024)
+	vector := func(pairs [][2]int64) flatbuffers.UOffsetT {
+		b.StartVector(16, len(pairs), 8)
+		for i := len(pairs) - 1; i >= 0; i-- {
+			b.Prep(8, 16)
+			b.PrependInt64(pairs[i][1])
+			b.PrependInt64(pairs[i][0])
+		}
+		return b.EndVector(len(pairs))
+	}
+	nodes := vector(rb.nodes)
+	buffers := vector(rb.buffers)
+	b.StartObject(4)
+	b.PrependInt64Slot(0, int64(length), 0)
+	b.PrependUOffsetTSlot(1, nodes, 0)
+	b.PrependUOffsetTSlot(2, buffers, 0)
+	return fbMessage(b, fbHeaderRecordBatch, b.EndObject(), int64(len(rb.body)))
+}
+
+// sqlInfoRecordBatch encodes the message and the body of a record batch
+// of metadata. The buffers are in the order of the fields of the schema,
+// and a dense union has no validity buffer.
+func sqlInfoRecordBatch(infos []sqlInfo) (header, body []byte) {
+	var (
+		names   = make([]byte, 4*len(infos))
+		typeIDs = make([]byte, len(infos))
+		offsets = make([]byte, 4*len(infos))
+
+		strOffsets = []byte{0, 0, 0, 0}
+		strData    []byte
+		nstr       int
+		bools      []byte
+		nbool      int
+	)
+	for i, info := range infos {
+		binary.LittleEndian.PutUint32(names[4*i:], info.code)
+		if info.isBool {
+			typeIDs[i] = fbBoolValueTypeID
+			binary.LittleEndian.PutUint32(offsets[4*i:], uint32(nbool))
+			if nbool%8 == 0 {
+				bools = append(bools, 0)
+			}
+			if info.b {
+				bools[nbool/8] |= 1 << uint(nbool%8)
+			}
+			nbool++
+			continue
+		}
+		typeIDs[i] = fbStringValueTypeID
+		binary.LittleEndian.PutUint32(offsets[4*i:], uint32(nstr))
+		strData = append(strData, info.str...)
+		strOffsets = append(strOffsets, 0, 0, 0, 0)
+		binary.LittleEndian.PutUint32(strOffsets[len(strOffsets)-4:], uint32(len(strData)))
+		nstr++
+	}
+
+	var rb recordBatch
+	// info_name
+	rb.node(len(infos))
+	rb.buffer(emptyValidity)
+	rb.buffer(names)
+	// value
+	rb.node(len(infos))
+	rb.buffer(typeIDs)
+	rb.buffer(offsets)
+	// string_value
+	rb.node(nstr)
+	rb.buffer(emptyValidity)
+	rb.buffer(strOffsets)
+	rb.buffer(strData)
+	// bool_value
+	rb.node(nbool)
+	rb.buffer(emptyValidity)
+	rb.buffer(bools)
+	// bigint_value and int32_bitmask
+	for i := 0; i < 2; i++ {
+		rb.node(0)
+		rb.buffer(emptyValidity)
+		rb.buffer(nil)
+	}
+	// string_list and its items
+	rb.node(0)
+	rb.buffer(emptyValidity)
+	rb.buffer(zeroOffset)
+	rb.node(0)
+	rb.buffer(emptyValidity)
+	rb.buffer(zeroOffset)
+	rb.buffer(nil)
+	// int32_to_int32_list_map, its entries, their keys,
+	// their values and the items of their values
+	rb.node(0)
+	rb.buffer(emptyValidity)
+	rb.buffer(zeroOffset)
+	rb.node(0)
+	rb.buffer(emptyValidity)
+	rb.node(0)
+	rb.buffer(emptyValidity)
+	rb.buffer(nil)
+	rb.node(0)
+	rb.buffer(emptyValidity)
+	rb.buffer(zeroOffset)
+	rb.node(0)
+	rb.buffer(emptyValidity)
+	rb.buffer(nil)
+	return rb.message(len(infos)), rb.body
+}
+
+// writeSQLInfo streams the metadata as a record batch.
+func writeSQLInfo(stream flight.FlightService_DoGetServer, infos []sqlInfo) error {
+	if err := stream.Send(&flight.FlightData{DataHeader: sqlInfoSchemaMessage()}); err != nil {
+		return err
+	}
+	header, body := sqlInfoRecordBatch(infos)
+	return stream.Send(&flight.FlightData{DataHeader: header, DataBody: body})
+}
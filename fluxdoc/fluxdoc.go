@@ -0,0 +1,180 @@
+// Package fluxdoc provides the documentation of the Flux standard library
+// as Go values.
+//
+// The documentation is generated from the doc comments of the stdlib
+// packages when libflux is built, so the REPL, the flux docs command
+// and editor integrations all read the same documentation that is
+// published for the standard library. See docs/fluxdoc.md for the
+// format of the doc comments.
+package fluxdoc
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/libflux/go/libflux"
+)
+
+// The kinds of members of a package.
+const (
+	ValueKind    = "Value"
+	FunctionKind = "Function"
+)
+
+// Package is the documentation of a package.
+type Package struct {
+	// Path is the import path of the package.
+	Path string `json:"path"`
+	// Name is the name of the package.
+	Name string `json:"name"`
+	// Headline is the first paragraph of the documentation.
+	Headline string `json:"headline"`
+	// Description is the rest of the documentation, if any.
+	Description string `json:"description"`
+	// Members are the documented values and functions
+	// of the package by name.
+	Members map[string]*Member `json:"members"`
+	// Examples are the examples of the package.
+	Examples []Example `json:"examples"`
+	// Metadata holds the metadata of the documentation,
+	// such as the version that introduced the package.
+	Metadata map[string]string `json:"metadata"`
+}
+
+// Member is the documentation of a value or a function of a package.
+type Member struct {
+	// Kind is either ValueKind or FunctionKind.
+	Kind string `json:"kind"`
+	// Name is the name of the member.
+	Name string `json:"name"`
+	// Headline is the first paragraph of the documentation.
+	Headline string `json:"headline"`
+	// Description is the rest of the documentation, if any.
+	Description string `json:"description"`
+	// Type is the Flux type of the member.
+	Type string `json:"flux_type"`
+	// IsOption reports whether the member is an option.
+	IsOption bool `json:"is_option"`
+	// Parameters are the parameters of a function.
+	Parameters []Parameter `json:"parameters"`
+	// Location is the location of the member in the source of the package.
+	Location ast.SourceLocation `json:"source_location"`
+	// Examples are the examples of the member.
+	Examples []Example `json:"examples"`
+	// Metadata holds the metadata of the documentation.
+	Metadata map[string]string `json:"metadata"`
+}
+
+// Signature returns the name of the member followed by its type.
+func (m *Member) Signature() string {
+	if m.IsOption {
+		return "option " + m.Name + " : " + m.Type
+	}
+	return m.Name + " : " + m.Type
+}
+
+// Parameter is the documentation of a parameter of a function.
+type Parameter struct {
+	// Name is the name of the parameter.
+	Name string `json:"name"`
+	// Headline is the first paragraph of the documentation.
+	Headline string `json:"headline"`
+	// Description is the rest of the documentation, if any.
+	Description string `json:"description"`
+	// Required reports whether the parameter must be passed.
+	Required bool `json:"required"`
+}
+
+// Example is an example from the documentation.
+type Example struct {
+	// Title is the heading of the example.
+	Title string `json:"title"`
+	// Content is the markdown of the example, including its code.
+	Content string `json:"content"`
+}
+
+var stdlib struct {
+	once  sync.Once
+	pkgs  []*Package
+	paths map[string]*Package
+	err   error
+}
+
+// load decodes the documentation of the stdlib the first time it is needed.
+func load() ([]*Package, map[string]*Package, error) {
+	stdlib.once.Do(func() {
+		pkgs, err := decode(libflux.StdlibDocs())
+		if err != nil {
+			stdlib.err = err
+			return
+		}
+		stdlib.pkgs = pkgs
+		stdlib.paths = make(map[string]*Package, len(pkgs))
+		for _, pkg := range pkgs {
+			stdlib.paths[pkg.Path] = pkg
+		}
+	})
+	return stdlib.pkgs, stdlib.paths, stdlib.err
+}
+
+// decode decodes the JSON list of package docs
+// and sorts the packages by path.
+func decode(data []byte) ([]*Package, error) {
+	var pkgs []*Package
+	if err := json.Unmarshal(data, &pkgs); err != nil {
+		return nil, errors.Wrap(err, codes.Internal, "invalid stdlib documentation")
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		return pkgs[i].Path < pkgs[j].Path
+	})
+	return pkgs, nil
+}
+
+// Packages returns the documentation of all of the stdlib packages
+// sorted by path. The returned values must not be modified.
+func Packages() ([]*Package, error) {
+	pkgs, _, err := load()
+	return pkgs, err
+}
+
+// LookupPackage returns the documentation of the stdlib package
+// with the given import path.
+func LookupPackage(path string) (*Package, error) {
+	_, paths, err := load()
+	if err != nil {
+		return nil, err
+	}
+	pkg, ok := paths[path]
+	if !ok {
+		return nil, errors.Newf(codes.NotFound, "package %q not found", path)
+	}
+	return pkg, nil
+}
+
+// Lookup returns the documentation of the member
+// with the given name of the stdlib package.
+func Lookup(path, name string) (*Member, error) {
+	pkg, err := LookupPackage(path)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := pkg.Members[name]
+	if !ok {
+		return nil, errors.Newf(codes.NotFound, "package %q has no member %q", path, name)
+	}
+	return m, nil
+}
+
+// MemberNames returns the names of the members of the package in order.
+func (p *Package) MemberNames() []string {
+	names := make([]string, 0, len(p.Members))
+	for name := range p.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,68 @@
+package fluxdoc_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/fluxdoc"
+)
+
+func TestLookup(t *testing.T) {
+	m, err := fluxdoc.Lookup("strings", "toUpper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := fluxdoc.FunctionKind, m.Kind; want != got {
+		t.Errorf("unexpected kind -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	if want, got := "toUpper : (v: string) => string", m.Signature(); want != got {
+		t.Errorf("unexpected signature -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	if want, got := "toUpper converts a string to uppercase.", m.Headline; want != got {
+		t.Errorf("unexpected headline -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	var names []string
+	for _, p := range m.Parameters {
+		names = append(names, p.Name)
+	}
+	if want, got := []string{"v"}, names; !cmp.Equal(want, got) {
+		t.Errorf("unexpected parameters -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestPackages(t *testing.T) {
+	pkgs, err := fluxdoc.Packages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(pkgs); i++ {
+		if pkgs[i-1].Path >= pkgs[i].Path {
+			t.Fatalf("packages are not sorted: %q before %q", pkgs[i-1].Path, pkgs[i].Path)
+		}
+	}
+
+	pkg, err := fluxdoc.LookupPackage("universe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pkg.Members["filter"]; !ok {
+		t.Error("expected universe to document filter")
+	}
+}
+
+func TestLookup_NotFound(t *testing.T) {
+	for _, tc := range []struct {
+		path, name string
+	}{
+		{path: "strings", name: "missing"},
+		{path: "missing", name: "toUpper"},
+	} {
+		if _, err := fluxdoc.Lookup(tc.path, tc.name); err == nil {
+			t.Errorf("expected an error looking up %s.%s", tc.path, tc.name)
+		} else if want, got := codes.NotFound, flux.ErrorCode(err); want != got {
+			t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package fluxpkg
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// Fetch downloads the files of the package into the directory dst,
+// which must not exist.
+//
+// A source that starts with git+ or ends with .git is a git repository
+// and the version is the tag that is checked out. Any other source is
+// an HTTP registry and the package is downloaded from the gzipped
+// tarball at <source>/<path>/@v/<version>.tar.gz. Without a source,
+// the package is cloned from https://<path>.git.
+func Fetch(ctx context.Context, req Requirement, dst string) error {
+	src := req.Source
+	switch {
+	case src == "":
+		return fetchGit(ctx, "https://"+req.Path+".git", req.Version, dst)
+	case strings.HasPrefix(src, "git+"):
+		return fetchGit(ctx, strings.TrimPrefix(src, "git+"), req.Version, dst)
+	case strings.HasSuffix(src, ".git"):
+		return fetchGit(ctx, src, req.Version, dst)
+	default:
+		url := strings.TrimSuffix(src, "/") + "/" + path.Join(req.Path, "@v", req.Version+".tar.gz")
+		return fetchArchive(ctx, url, dst)
+	}
+}
+
+func fetchGit(ctx context.Context, repo, version, dst string) error {
+	cmd := exec.CommandContext(ctx, "git", "clone", "--quiet", "--depth", "1", "--branch", version, "--", repo, dst)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Newf(codes.Unavailable, "failed to clone %s at %s: %s", repo, version, strings.TrimSpace(string(out)))
+	}
+	return os.RemoveAll(filepath.Join(dst, ".git"))
+}
+
+func fetchArchive(ctx context.Context, url, dst string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return errors.Wrapf(err, codes.Invalid, "invalid package url %s", url)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, codes.Unavailable, "failed to download %s", url)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errors.Newf(codes.NotFound, "package not found at %s", url)
+	case resp.StatusCode != http.StatusOK:
+		return errors.Newf(codes.Unavailable, "failed to download %s: %s", url, resp.Status)
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return errors.Wrapf(err, codes.Invalid, "invalid package archive %s", url)
+	}
+	if err := os.MkdirAll(dst, 0755); err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, codes.Invalid, "invalid package archive %s", url)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		// Names are cleaned as absolute paths so an entry
+		// cannot be written outside of the destination.
+		name := filepath.Join(dst, filepath.FromSlash(path.Clean("/"+hdr.Name)))
+		if err := writeFile(name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func writeFile(name string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,205 @@
+package fluxpkg
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// CacheDirEnv is the environment variable that sets
+// the directory downloaded packages are cached in.
+const CacheDirEnv = "FLUXPKGCACHE"
+
+// DefaultCacheDir returns the directory set by FLUXPKGCACHE
+// or the flux/pkg directory of the user's cache directory.
+func DefaultCacheDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "flux", "pkg"), nil
+}
+
+// Dir returns the directory the packages of the project in
+// projectDir are installed into. It is searched for packages
+// like the directories listed in FLUXPATH.
+func Dir(projectDir string) string {
+	return filepath.Join(projectDir, PackagesDir)
+}
+
+// Installer installs the packages required by projects.
+type Installer struct {
+	// CacheDir is the directory downloaded packages are cached in.
+	CacheDir string
+	// Fetch downloads a package. It defaults to Fetch.
+	Fetch func(ctx context.Context, req Requirement, dst string) error
+}
+
+// NewInstaller creates an Installer that uses the default cache directory.
+func NewInstaller() (*Installer, error) {
+	dir, err := DefaultCacheDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Installer{CacheDir: dir}, nil
+}
+
+// Get adds the package to the manifest of the
+// project in projectDir and installs the project.
+func (i *Installer) Get(ctx context.Context, projectDir string, req Requirement) error {
+	if err := req.Validate(); err != nil {
+		return err
+	}
+	m, err := ReadManifest(projectDir)
+	if err != nil {
+		return err
+	}
+	m.Add(req)
+	if err := WriteManifest(projectDir, m); err != nil {
+		return err
+	}
+	return i.Install(ctx, projectDir)
+}
+
+// Install installs the packages listed in the manifest of the project
+// in projectDir and writes its lock file. It returns an error if the
+// content of a locked package changed since it was locked.
+func (i *Installer) Install(ctx context.Context, projectDir string) error {
+	m, err := ReadManifest(projectDir)
+	if err != nil {
+		return err
+	}
+	lock, err := ReadLock(projectDir)
+	if err != nil {
+		return err
+	}
+
+	// Download and verify every package before the packages
+	// directory is replaced so a failure leaves it unchanged.
+	dirs := make([]string, len(m.Packages))
+	newLock := &Lock{}
+	for j, req := range m.Packages {
+		// The manifest may have been edited by hand.
+		if err := req.Validate(); err != nil {
+			return err
+		}
+		dir, err := i.download(ctx, req)
+		if err != nil {
+			return err
+		}
+		sum, err := hashDir(dir)
+		if err != nil {
+			return err
+		}
+		if locked, ok := lock.Lookup(req); ok && locked.Sum != sum {
+			return errors.Newf(codes.FailedPrecondition, "package %s@%s does not match %s: got %s, want %s", req.Path, req.Version, LockFile, sum, locked.Sum)
+		}
+		dirs[j] = dir
+		newLock.Packages = append(newLock.Packages, LockedPackage{Requirement: req, Sum: sum})
+	}
+
+	pkgsDir := Dir(projectDir)
+	if err := os.RemoveAll(pkgsDir); err != nil {
+		return err
+	}
+	for j, req := range m.Packages {
+		if err := copyDir(dirs[j], filepath.Join(pkgsDir, filepath.FromSlash(req.Path))); err != nil {
+			return err
+		}
+	}
+	return WriteLock(projectDir, newLock)
+}
+
+// download returns the cached directory of the package
+// and downloads the package if it is not cached.
+func (i *Installer) download(ctx context.Context, req Requirement) (string, error) {
+	dir := filepath.Join(i.CacheDir, filepath.FromSlash(req.Path)+"@"+req.Version)
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+
+	// Download into a temporary directory and rename it so a
+	// failed download never leaves a partial package in the cache.
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := ioutil.TempDir(filepath.Dir(dir), "download")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	fetch := i.Fetch
+	if fetch == nil {
+		fetch = Fetch
+	}
+	dst := filepath.Join(tmp, "pkg")
+	if err := fetch(ctx, req, dst); err != nil {
+		return "", errors.Wrapf(err, codes.Inherit, "failed to download %s@%s", req.Path, req.Version)
+	}
+	if err := os.Rename(dst, dir); err != nil {
+		// Another process may have downloaded the package first.
+		if _, statErr := os.Stat(dir); statErr == nil {
+			return dir, nil
+		}
+		return "", err
+	}
+	return dir, nil
+}
+
+// hashDir hashes the names and contents of the regular files in dir.
+func hashDir(dir string) (string, error) {
+	h := sha256.New()
+	if err := filepath.Walk(dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		fh := sha256.New()
+		if _, err := io.Copy(fh, f); err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(h, "%x  %s\n", fh.Sum(nil), filepath.ToSlash(rel))
+		return err
+	}); err != nil {
+		return "", err
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(name string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(src, name)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+		return writeFile(filepath.Join(dst, rel), f)
+	})
+}
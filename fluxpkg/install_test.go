@@ -0,0 +1,176 @@
+package fluxpkg_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/fluxpkg"
+)
+
+func archive(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tempDir(t *testing.T) string {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "fluxpkg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+	return dir
+}
+
+func TestInstaller(t *testing.T) {
+	archives := map[string][]byte{
+		"/example.com/alerts/@v/v1.0.0.tar.gz": archive(t, map[string]string{
+			"alerts.flux":    "package alerts\n",
+			"../escape.flux": "package escape\n",
+		}),
+	}
+	requests := 0
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		data, ok := archives[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer registry.Close()
+
+	project := tempDir(t)
+	installer := &fluxpkg.Installer{CacheDir: tempDir(t)}
+	req := fluxpkg.Requirement{
+		Path:    "example.com/alerts",
+		Version: "v1.0.0",
+		Source:  registry.URL,
+	}
+	ctx := context.Background()
+	if err := installer.Get(ctx, project, req); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := fluxpkg.ReadManifest(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []fluxpkg.Requirement{req}, m.Packages; !cmp.Equal(want, got) {
+		t.Errorf("unexpected manifest -want/+got:\n%s", cmp.Diff(want, got))
+	}
+	lock, err := fluxpkg.ReadLock(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lock.Packages) != 1 || lock.Packages[0].Requirement != req || lock.Packages[0].Sum == "" {
+		t.Errorf("unexpected lock: %+v", lock.Packages)
+	}
+
+	pkgDir := filepath.Join(fluxpkg.Dir(project), "example.com", "alerts")
+	if _, err := os.Stat(filepath.Join(pkgDir, "alerts.flux")); err != nil {
+		t.Errorf("package was not installed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(pkgDir, "escape.flux")); err != nil {
+		t.Errorf("entry outside of the package was not kept in the package: %v", err)
+	}
+
+	// Installing again uses the cache.
+	if err := installer.Install(ctx, project); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := 1, requests; want != got {
+		t.Errorf("unexpected number of downloads -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	// A package that changed after it was locked is rejected.
+	archives["/example.com/alerts/@v/v1.0.0.tar.gz"] = archive(t, map[string]string{
+		"alerts.flux": "package alerts\n\nx = 1\n",
+	})
+	changed := &fluxpkg.Installer{CacheDir: tempDir(t)}
+	if err := changed.Install(ctx, project); err == nil {
+		t.Error("expected error installing a changed package")
+	} else if want, got := codes.FailedPrecondition, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	missing := fluxpkg.Requirement{
+		Path:    "example.com/missing",
+		Version: "v1.0.0",
+		Source:  registry.URL,
+	}
+	if err := installer.Get(ctx, tempDir(t), missing); err == nil {
+		t.Error("expected error getting a missing package")
+	} else if want, got := codes.NotFound, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
+func TestRequirement_Validate(t *testing.T) {
+	for _, tc := range []struct {
+		path    string
+		version string
+		valid   bool
+	}{
+		{path: "example.com/alerts", version: "v1.0.0", valid: true},
+		{path: "example.com/org/alerts", version: "1.2.3-rc.1+build.5", valid: true},
+		{path: "", version: "v1.0.0"},
+		{path: "/etc/alerts", version: "v1.0.0"},
+		{path: "example.com/../../alerts", version: "v1.0.0"},
+		{path: "example.com//alerts", version: "v1.0.0"},
+		{path: "example.com/./alerts", version: "v1.0.0"},
+		{path: "example.com/alerts/", version: "v1.0.0"},
+		{path: `example.com\alerts`, version: "v1.0.0"},
+		{path: "-example.com/alerts", version: "v1.0.0"},
+		{path: "example.com/alerts", version: ""},
+		{path: "example.com/alerts", version: "--upload-pack=touch"},
+		{path: "example.com/alerts", version: "v1.0.0/../../x"},
+		{path: "example.com/alerts", version: "main"},
+	} {
+		req := fluxpkg.Requirement{Path: tc.path, Version: tc.version}
+		err := req.Validate()
+		if tc.valid {
+			if err != nil {
+				t.Errorf("unexpected error for %s@%s: %s", tc.path, tc.version, err)
+			}
+			continue
+		}
+		if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+			t.Errorf("unexpected error code for %s@%s -want/+got:\n\t- %v\n\t+ %v", tc.path, tc.version, want, got)
+		}
+	}
+}
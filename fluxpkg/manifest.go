@@ -0,0 +1,171 @@
+// Package fluxpkg downloads third-party Flux packages so they can be
+// imported by the scripts of a project.
+//
+// A project lists the packages it requires in a manifest file.
+// Each package is downloaded once into a cache shared by all projects
+// and copied into the packages directory of the project, which is searched
+// by the filesystem importer like a directory listed in FLUXPATH.
+// The exact content of each package is recorded in a lock file so
+// later installs fail if a package changes.
+package fluxpkg
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+const (
+	// ManifestFile is the name of the file that lists
+	// the packages required by a project.
+	ManifestFile = "flux.json"
+	// LockFile is the name of the file that records
+	// the content of the installed packages.
+	LockFile = "flux.lock"
+	// PackagesDir is the name of the directory of a project
+	// that the packages are installed into.
+	PackagesDir = "flux_packages"
+)
+
+// Requirement is a package required by a project.
+type Requirement struct {
+	// Path is the import path of the package.
+	Path string `json:"path"`
+	// Version is the semantic version of the package.
+	// It is a tag of a git repository or the
+	// name of an archive in an HTTP registry.
+	Version string `json:"version"`
+	// Source is where the package is downloaded from.
+	// See Fetch for how it is interpreted.
+	Source string `json:"source,omitempty"`
+}
+
+var (
+	// pathPattern matches import paths, which are relative paths
+	// of names that start with a letter or a digit. They are clean
+	// and cannot name a parent directory, so a package is always
+	// installed within the cache and the packages directory.
+	pathPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(/[A-Za-z0-9][A-Za-z0-9._-]*)*$`)
+	// versionPattern matches semantic versions with an optional v
+	// prefix, such as v1.2.0 or 1.2.0-rc.1.
+	versionPattern = regexp.MustCompile(`^v?[0-9]+\.[0-9]+\.[0-9]+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+)
+
+// Validate returns an error if the path or the version
+// of the requirement is not valid.
+func (r Requirement) Validate() error {
+	if !pathPattern.MatchString(r.Path) {
+		return errors.Newf(codes.Invalid, "invalid package path %q: must be a relative path of names such as example.com/org/pkg", r.Path)
+	}
+	if !versionPattern.MatchString(r.Version) {
+		return errors.Newf(codes.Invalid, "invalid version %q of package %s: must be a semantic version such as v1.0.0", r.Version, r.Path)
+	}
+	return nil
+}
+
+// Manifest lists the packages required by a project.
+type Manifest struct {
+	Packages []Requirement `json:"packages"`
+}
+
+// Add adds the requirement to the manifest and
+// replaces any requirement with the same path.
+func (m *Manifest) Add(req Requirement) {
+	for i, r := range m.Packages {
+		if r.Path == req.Path {
+			m.Packages[i] = req
+			return
+		}
+	}
+	m.Packages = append(m.Packages, req)
+	sort.Slice(m.Packages, func(i, j int) bool {
+		return m.Packages[i].Path < m.Packages[j].Path
+	})
+}
+
+// LockedPackage records the content of an installed package.
+type LockedPackage struct {
+	Requirement
+	// Sum is the hash of the files of the package.
+	Sum string `json:"sum"`
+}
+
+// Lock records the content of the packages installed in a project.
+type Lock struct {
+	Packages []LockedPackage `json:"packages"`
+}
+
+// Lookup returns the locked package for the requirement.
+// A package is only locked for the version and source it was installed from.
+func (l *Lock) Lookup(req Requirement) (LockedPackage, bool) {
+	for _, p := range l.Packages {
+		if p.Requirement == req {
+			return p, true
+		}
+	}
+	return LockedPackage{}, false
+}
+
+// ReadManifest reads the manifest of the project in dir.
+// A project without a manifest has an empty manifest.
+func ReadManifest(dir string) (*Manifest, error) {
+	m := &Manifest{}
+	if err := readJSON(filepath.Join(dir, ManifestFile), m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WriteManifest writes the manifest of the project in dir.
+func WriteManifest(dir string, m *Manifest) error {
+	return writeJSON(filepath.Join(dir, ManifestFile), m)
+}
+
+// ReadLock reads the lock file of the project in dir.
+// A project without a lock file has an empty lock.
+func ReadLock(dir string) (*Lock, error) {
+	l := &Lock{}
+	if err := readJSON(filepath.Join(dir, LockFile), l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// WriteLock writes the lock file of the project in dir.
+func WriteLock(dir string, l *Lock) error {
+	return writeJSON(filepath.Join(dir, LockFile), l)
+}
+
+// HasManifest reports whether dir contains a manifest.
+func HasManifest(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ManifestFile))
+	return err == nil
+}
+
+func readJSON(filename string, v interface{}) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.Wrapf(err, codes.Invalid, "failed to decode %s", filename)
+	}
+	return nil
+}
+
+func writeJSON(filename string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0644)
+}
@@ -0,0 +1,227 @@
+package fluxtest
+
+import (
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/values"
+)
+
+// TableBuilder builds a table from its group key and its rows.
+// The methods return the builder, so a table is declared in one
+// chain of calls:
+//
+//	fluxtest.NewTable().
+//		Key("_measurement", "cpu").
+//		Column("_time", flux.TTime).
+//		Column("_value", flux.TFloat).
+//		Row("2021-01-01T00:00:00Z", 1.5).
+//		Row("2021-01-01T00:00:10Z", nil)
+//
+// The first error of the calls is returned when the table is built.
+type TableBuilder struct {
+	keyCols   []flux.ColMeta
+	keyValues []values.Value
+	cols      []flux.ColMeta
+	rows      [][]values.Value
+	err       error
+}
+
+// NewTable creates a TableBuilder of a table with no columns.
+func NewTable() *TableBuilder {
+	return &TableBuilder{}
+}
+
+// Key adds a column to the group key of the table. The type of
+// the column is the type of v, which is the value of the column
+// in each row. An int is an int column and a time.Time or
+// a values.Time is a time column.
+func (b *TableBuilder) Key(label string, v interface{}) *TableBuilder {
+	if b.err != nil {
+		return b
+	}
+	typ := columnType(v)
+	if typ == flux.TInvalid {
+		b.err = errors.Newf(codes.Invalid, "key column %q has a value of unsupported type %T", label, v)
+		return b
+	}
+	if len(b.cols) > 0 {
+		b.err = errors.Newf(codes.Invalid, "key column %q must be added before the other columns", label)
+		return b
+	}
+	value, err := newValue(v, typ)
+	if err != nil {
+		b.err = errors.Wrapf(err, codes.Invalid, "key column %q", label)
+		return b
+	}
+	b.keyCols = append(b.keyCols, flux.ColMeta{Label: label, Type: typ})
+	b.keyValues = append(b.keyValues, value)
+	return b
+}
+
+// Column adds a column that is not in the group key.
+// The values of the column are given by Row.
+func (b *TableBuilder) Column(label string, typ flux.ColType) *TableBuilder {
+	if b.err != nil {
+		return b
+	}
+	if len(b.rows) > 0 {
+		b.err = errors.Newf(codes.Invalid, "column %q must be added before the rows", label)
+		return b
+	}
+	b.cols = append(b.cols, flux.ColMeta{Label: label, Type: typ})
+	return b
+}
+
+// Row adds a row with a value for each of the columns added
+// by Column, in the same order. A nil value is null, an int is
+// converted to the type of its column and a string is parsed
+// as a time for a time column.
+func (b *TableBuilder) Row(vs ...interface{}) *TableBuilder {
+	if b.err != nil {
+		return b
+	}
+	if len(vs) != len(b.cols) {
+		b.err = errors.Newf(codes.Invalid, "row %d has %d values, but the table has %d columns", len(b.rows), len(vs), len(b.cols))
+		return b
+	}
+	row := make([]values.Value, len(vs))
+	for j, v := range vs {
+		value, err := newValue(v, b.cols[j].Type)
+		if err != nil {
+			b.err = errors.Wrapf(err, codes.Invalid, "row %d, column %q", len(b.rows), b.cols[j].Label)
+			return b
+		}
+		row[j] = value
+	}
+	b.rows = append(b.rows, row)
+	return b
+}
+
+// Build builds the table with the memory of mem.
+func (b *TableBuilder) Build(mem *memory.Allocator) (flux.Table, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	key := execute.NewGroupKey(b.keyCols, b.keyValues)
+	tb := execute.NewColListTableBuilder(key, mem)
+	if err := execute.AddTableKeyCols(key, tb); err != nil {
+		return nil, err
+	}
+	for _, col := range b.cols {
+		if _, err := tb.AddCol(col); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range b.rows {
+		if err := execute.AppendKeyValues(key, tb); err != nil {
+			return nil, err
+		}
+		for j, v := range row {
+			if err := tb.AppendValue(len(b.keyCols)+j, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return tb.Table()
+}
+
+// Do builds the table and calls f with it,
+// so that a TableBuilder is a flux.TableIterator.
+//
+// If the table is invalid, then this method will panic,
+// so that the mistakes in the tables of a test are not
+// compared as the errors of the tables.
+func (b *TableBuilder) Do(f func(flux.Table) error) error {
+	tbl, err := b.Build(&memory.Allocator{})
+	if err != nil {
+		panic(err)
+	}
+	return f(tbl)
+}
+
+// Tables is a flux.TableIterator of the tables of the builders.
+type Tables []*TableBuilder
+
+// Do builds each table and calls f with it.
+func (ts Tables) Do(f func(flux.Table) error) error {
+	for _, b := range ts {
+		if err := b.Do(f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewResult creates a flux.Result with the tables of the builders.
+func NewResult(name string, tables ...*TableBuilder) flux.Result {
+	return result{name: name, tables: tables}
+}
+
+type result struct {
+	name   string
+	tables Tables
+}
+
+func (r result) Name() string               { return r.name }
+func (r result) Tables() flux.TableIterator { return r.tables }
+
+// columnType returns the type of the column of a key value.
+func columnType(v interface{}) flux.ColType {
+	switch v.(type) {
+	case int, int64:
+		return flux.TInt
+	case uint64:
+		return flux.TUInt
+	case float64:
+		return flux.TFloat
+	case string:
+		return flux.TString
+	case bool:
+		return flux.TBool
+	case time.Time, values.Time:
+		return flux.TTime
+	default:
+		return flux.TInvalid
+	}
+}
+
+// newValue converts a Go value to the value of a column of a type.
+func newValue(v interface{}, typ flux.ColType) (values.Value, error) {
+	switch v := v.(type) {
+	case nil:
+		return values.NewNull(flux.SemanticType(typ)), nil
+	case int:
+		switch typ {
+		case flux.TInt:
+			return values.NewInt(int64(v)), nil
+		case flux.TUInt:
+			if v >= 0 {
+				return values.NewUInt(uint64(v)), nil
+			}
+		case flux.TFloat:
+			return values.NewFloat(float64(v)), nil
+		}
+	case time.Time:
+		if typ == flux.TTime {
+			return values.NewTime(values.ConvertTime(v)), nil
+		}
+	case string:
+		if typ == flux.TTime {
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewTime(values.ConvertTime(t)), nil
+		}
+	}
+	value := values.New(v)
+	if flux.ColumnType(value.Type()) != typ {
+		return nil, errors.Newf(codes.Invalid, "cannot use %v of type %T as a value of type %v", v, v, typ)
+	}
+	return value, nil
+}
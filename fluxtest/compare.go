@@ -0,0 +1,276 @@
+package fluxtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/andreyvit/diff"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// CompareOption configures how DiffTables and DiffResults compare tables.
+// By default, the tables and their rows are compared in order
+// and the values must be equal.
+type CompareOption func(c *comparer)
+
+type comparer struct {
+	epsilon          float64
+	ignoreTableOrder bool
+	ignoreRowOrder   bool
+}
+
+// FloatEpsilon compares two floats as equal when
+// they differ by no more than epsilon.
+func FloatEpsilon(epsilon float64) CompareOption {
+	return func(c *comparer) {
+		c.epsilon = epsilon
+	}
+}
+
+// IgnoreTableOrder compares the tables in the order of their
+// group keys rather than in the order they were produced.
+func IgnoreTableOrder() CompareOption {
+	return func(c *comparer) {
+		c.ignoreTableOrder = true
+	}
+}
+
+// IgnoreRowOrder compares the rows of each table in sorted order
+// rather than in the order they were produced.
+func IgnoreRowOrder() CompareOption {
+	return func(c *comparer) {
+		c.ignoreRowOrder = true
+	}
+}
+
+// DiffTables compares the tables of two table iterators and returns
+// the line diff of their text, where the lines of want start with "-"
+// and the lines of got start with "+". It returns an empty string
+// when the tables are equal.
+func DiffTables(want, got flux.TableIterator, opts ...CompareOption) string {
+	c := newComparer(opts)
+	return c.diff([]resultData{readTables(want)}, []resultData{readTables(got)})
+}
+
+// DiffResults compares the results of two result iterators by name,
+// with the tables of each result compared as with DiffTables.
+// It returns an empty string when the results are equal.
+// The iterators are released.
+func DiffResults(want, got flux.ResultIterator, opts ...CompareOption) string {
+	c := newComparer(opts)
+	return c.diff(readResults(want), readResults(got))
+}
+
+func newComparer(opts []CompareOption) *comparer {
+	c := &comparer{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// tableData is a table that was read into memory.
+type tableData struct {
+	key  flux.GroupKey
+	cols []flux.ColMeta
+	rows [][]values.Value
+}
+
+// resultData is a result that was read into memory.
+// A result that failed has the error that it failed with.
+type resultData struct {
+	name   string
+	tables []*tableData
+	err    error
+}
+
+func readTables(iter flux.TableIterator) resultData {
+	var res resultData
+	res.err = iter.Do(func(tbl flux.Table) error {
+		t := &tableData{key: tbl.Key(), cols: tbl.Cols()}
+		res.tables = append(res.tables, t)
+		return tbl.Do(func(cr flux.ColReader) error {
+			for i, n := 0, cr.Len(); i < n; i++ {
+				row := make([]values.Value, len(t.cols))
+				for j := range t.cols {
+					row[j] = execute.ValueForRow(cr, i, j)
+				}
+				t.rows = append(t.rows, row)
+			}
+			return nil
+		})
+	})
+	return res
+}
+
+func readResults(iter flux.ResultIterator) []resultData {
+	defer iter.Release()
+	var results []resultData
+	for iter.More() {
+		r := iter.Next()
+		res := readTables(r.Tables())
+		res.name = r.Name()
+		results = append(results, res)
+	}
+	if err := iter.Err(); err != nil {
+		results = append(results, resultData{err: err})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].name < results[j].name
+	})
+	return results
+}
+
+func (c *comparer) diff(want, got []resultData) string {
+	for _, results := range [][]resultData{want, got} {
+		for _, res := range results {
+			c.sort(res)
+		}
+	}
+	if c.epsilon > 0 {
+		for i := 0; i < len(want) && i < len(got); i++ {
+			if want[i].name == got[i].name {
+				c.align(want[i], got[i])
+			}
+		}
+	}
+	w, g := formatResults(want), formatResults(got)
+	if w == g {
+		return ""
+	}
+	return strings.Join(diff.LineDiffAsLines(w, g), "\n")
+}
+
+// sort sorts the tables and rows of a result
+// in the order that they are compared in.
+func (c *comparer) sort(res resultData) {
+	if c.ignoreTableOrder {
+		sort.SliceStable(res.tables, func(i, j int) bool {
+			return res.tables[i].key.Less(res.tables[j].key)
+		})
+	}
+	if c.ignoreRowOrder {
+		for _, t := range res.tables {
+			keys := make([]string, len(t.rows))
+			for i, row := range t.rows {
+				keys[i] = formatRow(row)
+			}
+			sort.Sort(byKey{keys: keys, rows: t.rows})
+		}
+	}
+}
+
+type byKey struct {
+	keys []string
+	rows [][]values.Value
+}
+
+func (b byKey) Len() int           { return len(b.keys) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+	b.rows[i], b.rows[j] = b.rows[j], b.rows[i]
+}
+
+// align replaces the floats of got that are within epsilon
+// of the floats of want at the same position with the floats
+// of want, so that they are formatted as equal.
+func (c *comparer) align(want, got resultData) {
+	for k := 0; k < len(want.tables) && k < len(got.tables); k++ {
+		tw, tg := want.tables[k], got.tables[k]
+		if !colsEqual(tw.cols, tg.cols) {
+			continue
+		}
+		for i := 0; i < len(tw.rows) && i < len(tg.rows); i++ {
+			for j, col := range tw.cols {
+				if col.Type != flux.TFloat {
+					continue
+				}
+				a, b := tw.rows[i][j], tg.rows[i][j]
+				if !a.IsNull() && !b.IsNull() && math.Abs(a.Float()-b.Float()) <= c.epsilon {
+					tg.rows[i][j] = a
+				}
+			}
+		}
+	}
+}
+
+func colsEqual(a, b []flux.ColMeta) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for j := range a {
+		if a[j] != b[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// formatResults formats results as text with a line for
+// the group key, the columns and each row of each table.
+func formatResults(results []resultData) string {
+	var sb strings.Builder
+	for _, res := range results {
+		if res.name != "" {
+			fmt.Fprintf(&sb, "result: %s\n", res.name)
+		}
+		for _, t := range res.tables {
+			sb.WriteString("table: ")
+			for j, col := range t.key.Cols() {
+				if j > 0 {
+					sb.WriteString(",")
+				}
+				fmt.Fprintf(&sb, "%s=%s", col.Label, formatValue(t.key.Value(j)))
+			}
+			sb.WriteString("\n")
+			for j, col := range t.cols {
+				if j > 0 {
+					sb.WriteString(",")
+				}
+				fmt.Fprintf(&sb, "%s:%s", col.Label, col.Type)
+			}
+			sb.WriteString("\n")
+			for _, row := range t.rows {
+				sb.WriteString(formatRow(row))
+				sb.WriteString("\n")
+			}
+		}
+		if res.err != nil {
+			fmt.Fprintf(&sb, "error: %s\n", res.err)
+		}
+	}
+	return sb.String()
+}
+
+func formatRow(row []values.Value) string {
+	vs := make([]string, len(row))
+	for j, v := range row {
+		vs[j] = formatValue(v)
+	}
+	return strings.Join(vs, ",")
+}
+
+// formatValue formats a value, with the strings quoted
+// so that they are distinct from null.
+func formatValue(v values.Value) string {
+	if v.IsNull() {
+		return "null"
+	}
+	switch v.Type().Nature() {
+	case semantic.String:
+		return strconv.Quote(v.Str())
+	case semantic.Float:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case semantic.Time:
+		return v.Time().String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
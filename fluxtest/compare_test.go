@@ -0,0 +1,184 @@
+package fluxtest_test
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/fluxtest"
+)
+
+func cpu(value float64) []*fluxtest.TableBuilder {
+	return []*fluxtest.TableBuilder{
+		fluxtest.NewTable().
+			Key("host", "a").
+			Column("_time", flux.TTime).
+			Column("_value", flux.TFloat).
+			Row("2021-01-01T00:00:00Z", 1.5).
+			Row(time.Date(2021, 1, 1, 0, 0, 10, 0, time.UTC), nil),
+		fluxtest.NewTable().
+			Key("host", "b").
+			Column("_time", flux.TTime).
+			Column("_value", flux.TFloat).
+			Column("count", flux.TInt).
+			Row("2021-01-01T00:00:00Z", value, 3).
+			Row("2021-01-01T00:00:10Z", 2, nil),
+	}
+}
+
+func TestDiffTables(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		want  fluxtest.Tables
+		got   fluxtest.Tables
+		opts  []fluxtest.CompareOption
+		equal bool
+	}{
+		{
+			name:  "equal",
+			want:  cpu(1),
+			got:   cpu(1),
+			equal: true,
+		},
+		{
+			name: "table order",
+			want: cpu(1),
+			got:  fluxtest.Tables{cpu(1)[1], cpu(1)[0]},
+		},
+		{
+			name:  "ignore table order",
+			want:  cpu(1),
+			got:   fluxtest.Tables{cpu(1)[1], cpu(1)[0]},
+			opts:  []fluxtest.CompareOption{fluxtest.IgnoreTableOrder()},
+			equal: true,
+		},
+		{
+			name: "row order",
+			want: fluxtest.Tables{fluxtest.NewTable().Column("n", flux.TInt).Row(1).Row(2)},
+			got:  fluxtest.Tables{fluxtest.NewTable().Column("n", flux.TInt).Row(2).Row(1)},
+		},
+		{
+			name:  "ignore row order",
+			want:  fluxtest.Tables{fluxtest.NewTable().Column("n", flux.TInt).Row(1).Row(2)},
+			got:   fluxtest.Tables{fluxtest.NewTable().Column("n", flux.TInt).Row(2).Row(1)},
+			opts:  []fluxtest.CompareOption{fluxtest.IgnoreRowOrder()},
+			equal: true,
+		},
+		{
+			name: "float",
+			want: cpu(1),
+			got:  cpu(1.0000001),
+		},
+		{
+			name:  "float epsilon",
+			want:  cpu(1),
+			got:   cpu(1.0000001),
+			opts:  []fluxtest.CompareOption{fluxtest.FloatEpsilon(1e-6)},
+			equal: true,
+		},
+		{
+			name: "float beyond epsilon",
+			want: cpu(1),
+			got:  cpu(1.1),
+			opts: []fluxtest.CompareOption{fluxtest.FloatEpsilon(1e-6)},
+		},
+		{
+			name: "null",
+			want: fluxtest.Tables{fluxtest.NewTable().Column("s", flux.TString).Row(nil)},
+			got:  fluxtest.Tables{fluxtest.NewTable().Column("s", flux.TString).Row("null")},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			diff := fluxtest.DiffTables(tc.want, tc.got, tc.opts...)
+			if tc.equal && diff != "" {
+				t.Errorf("unexpected diff:\n%s", diff)
+			} else if !tc.equal && diff == "" {
+				t.Error("expected a diff")
+			}
+		})
+	}
+}
+
+func TestDiffTables_Output(t *testing.T) {
+	diff := fluxtest.DiffTables(fluxtest.Tables(cpu(1)), fluxtest.Tables(cpu(2.5)))
+	want := ` table: host="a"
+ host:string,_time:time,_value:float
+ "a",2021-01-01T00:00:00.000000000Z,1.5
+ "a",2021-01-01T00:00:10.000000000Z,null
+ table: host="b"
+ host:string,_time:time,_value:float,count:int
+-"b",2021-01-01T00:00:00.000000000Z,1,3
++"b",2021-01-01T00:00:00.000000000Z,2.5,3
+ "b",2021-01-01T00:00:10.000000000Z,2,null`
+	if diff != want {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+}
+
+func TestDiffResults(t *testing.T) {
+	const data = `#datatype,string,long,string,dateTime:RFC3339,double
+#group,false,false,true,false,false
+#default,_result,,,,
+,result,table,host,_time,_value
+,,0,b,2021-01-01T00:00:00Z,1
+,,1,a,2021-01-01T00:00:00Z,1.5
+
+`
+	decode := func(data string) flux.ResultIterator {
+		results, err := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).Decode(ioutil.NopCloser(strings.NewReader(data)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return results
+	}
+
+	if diff := fluxtest.DiffResults(decode(data), decode(data)); diff != "" {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+	got := strings.Replace(data, ",,1,a,2021-01-01T00:00:00Z,1.5", ",,1,a,2021-01-01T00:00:00Z,2.5", 1)
+	if diff := fluxtest.DiffResults(decode(data), decode(got)); !strings.Contains(diff, `+"a",2021-01-01T00:00:00.000000000Z,2.5`) {
+		t.Errorf("unexpected diff:\n%s", diff)
+	}
+}
+
+func TestTableBuilder_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		table *fluxtest.TableBuilder
+		want  string
+	}{
+		{
+			name:  "value type",
+			table: fluxtest.NewTable().Column("n", flux.TInt).Row("a"),
+			want:  `row 0, column "n": cannot use a of type string as a value of type int`,
+		},
+		{
+			name:  "row length",
+			table: fluxtest.NewTable().Column("n", flux.TInt).Row(1, 2),
+			want:  "row 0 has 2 values, but the table has 1 columns",
+		},
+		{
+			name:  "key after columns",
+			table: fluxtest.NewTable().Column("n", flux.TInt).Key("host", "a"),
+			want:  `key column "host" must be added before the other columns`,
+		},
+		{
+			name:  "invalid time",
+			table: fluxtest.NewTable().Column("_time", flux.TTime).Row("yesterday"),
+			want:  `row 0, column "_time": parsing time "yesterday" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "yesterday" as "2006"`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.table.Build(nil)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if got := err.Error(); got != tc.want {
+				t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+// Package fluxtest contains utilities for testing Flux scripts
+// and the functions of query libraries.
+//
+// NewTable builds the tables of a test row by row. DiffTables and
+// DiffResults compare tables and can ignore the order of the tables
+// and of their rows and small differences between floats.
+//
+// The golden tests of RunGolden execute a script with the CSV files
+// next to it as its inputs and compare its results with the annotated
+// CSV of a golden file. The golden tests in the directories that are
+// given to flux test are run along with the testcases.
+package fluxtest
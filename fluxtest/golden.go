@@ -0,0 +1,179 @@
+package fluxtest
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/runtime"
+)
+
+// GoldenExt is the extension of a golden file, which is named
+// after the script of its test, such as cpu.golden.csv for cpu.flux.
+const GoldenExt = ".golden.csv"
+
+// GoldenNow is the time of now() in the scripts of golden tests,
+// so that their results do not depend on when they are run.
+var GoldenNow = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Golden is a golden-file test. It executes a script and compares
+// its results with the annotated CSV of its golden file.
+//
+// The script reads its fixture inputs with the paths relative
+// to its directory, such as csv.from(file: "cpu.csv"),
+// and cannot read the files outside of its directory.
+type Golden struct {
+	// Name is the name of the test.
+	Name string
+	// Script is the path of the script of the test.
+	Script string
+}
+
+// GoldenFile returns the path of the golden file of the test.
+func (g Golden) GoldenFile() string {
+	return strings.TrimSuffix(g.Script, ".flux") + GoldenExt
+}
+
+// FindGolden finds the golden tests of the scripts in dir and its
+// subdirectories that have a golden file. The name of a test is the path
+// of its script relative to dir, without the extension.
+func FindGolden(dir string) ([]Golden, error) {
+	var tests []Golden
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".flux") || strings.HasSuffix(path, "_test.flux") {
+			return nil
+		}
+		g := Golden{Script: path}
+		if _, err := os.Stat(g.GoldenFile()); os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		g.Name = filepath.ToSlash(strings.TrimSuffix(name, ".flux"))
+		tests = append(tests, g)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return tests, nil
+}
+
+// Run executes the script of the test with the dependencies of ctx
+// and compares its results with the golden file. The tables are
+// compared in the order of their group keys and the options further
+// configure the comparison. The error has the diff of the results
+// when they differ.
+func (g Golden) Run(ctx context.Context, opts ...CompareOption) error {
+	f, err := os.Open(g.GoldenFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return errors.Newf(codes.NotFound, "golden file %s does not exist", g.GoldenFile())
+		}
+		return err
+	}
+	want, err := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).Decode(f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	opts = append([]CompareOption{IgnoreTableOrder()}, opts...)
+	return g.execute(ctx, func(got flux.ResultIterator) error {
+		if diff := DiffResults(want, got, opts...); diff != "" {
+			return errors.Newf(codes.FailedPrecondition, "results differ from %s -want/+got:\n%s", g.GoldenFile(), diff)
+		}
+		return nil
+	})
+}
+
+// Update executes the script of the test with the dependencies
+// of ctx and writes its results to the golden file.
+func (g Golden) Update(ctx context.Context) error {
+	var buf bytes.Buffer
+	if err := g.execute(ctx, func(results flux.ResultIterator) error {
+		_, err := csv.NewMultiResultEncoder(csv.DefaultEncoderConfig()).Encode(&buf, results)
+		return err
+	}); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(g.GoldenFile(), buf.Bytes(), 0644)
+}
+
+func (g Golden) execute(ctx context.Context, f func(results flux.ResultIterator) error) error {
+	script, err := ioutil.ReadFile(g.Script)
+	if err != nil {
+		return err
+	}
+	fs, err := filesystem.NewRootFS(filepath.Dir(g.Script), 0)
+	if err != nil {
+		return err
+	}
+	ctx = filesystem.Inject(ctx, fs)
+
+	program, err := lang.FluxCompiler{Now: GoldenNow, Query: string(script)}.Compile(ctx, runtime.Default)
+	if err != nil {
+		return errors.Wrapf(err, codes.Inherit, "failed to compile %s", g.Script)
+	}
+	q, err := program.Start(ctx, &memory.Allocator{})
+	if err != nil {
+		return err
+	}
+	results := flux.NewResultIteratorFromQuery(q)
+	defer results.Release()
+	return f(results)
+}
+
+// RunGolden runs the golden tests in dir as subtests of t with the
+// dependencies of ctx. When update is set, the golden files are
+// written with the results of the scripts rather than compared.
+// The golden file of a new test is created empty, so that the test
+// is found, and is then written by running the tests with update:
+//
+//	var update = flag.Bool("update", false, "update the golden files")
+//
+//	func TestGolden(t *testing.T) {
+//		ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+//		fluxtest.RunGolden(ctx, t, "testdata", *update)
+//	}
+func RunGolden(ctx context.Context, t *testing.T, dir string, update bool, opts ...CompareOption) {
+	t.Helper()
+	tests, err := FindGolden(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) == 0 {
+		t.Fatalf("no golden tests in %s", dir)
+	}
+	for _, g := range tests {
+		g := g
+		t.Run(g.Name, func(t *testing.T) {
+			if update {
+				if err := g.Update(ctx); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err := g.Run(ctx, opts...); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
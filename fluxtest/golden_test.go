@@ -0,0 +1,65 @@
+package fluxtest_test
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/fluxtest"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func TestGolden(t *testing.T) {
+	ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+	fluxtest.RunGolden(ctx, t, "testdata", *update)
+}
+
+func TestGolden_Update(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "a.flux")
+	if err := ioutil.WriteFile(script, []byte(`
+import "array"
+
+array.from(rows: [{_time: now(), _value: 1.5}])
+`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	const golden = "#datatype,string,long,dateTime:RFC3339,double\r\n" +
+		"#group,false,false,false,false\r\n" +
+		"#default,_result,,,\r\n" +
+		",result,table,_time,_value\r\n" +
+		",,0,2021-01-01T00:00:00Z,2.5\r\n" +
+		"\r\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, "a"+fluxtest.GoldenExt), []byte(golden), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests, err := fluxtest.FindGolden(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 1 || tests[0].Name != "a" || tests[0].Script != script {
+		t.Fatalf("unexpected tests: %v", tests)
+	}
+	g := tests[0]
+
+	ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+	err = g.Run(ctx)
+	if errors.Code(err) != codes.FailedPrecondition || !strings.Contains(err.Error(), "+2021-01-01T00:00:00.000000000Z,1.5") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := g.Update(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Run(ctx); err != nil {
+		t.Errorf("unexpected error after update: %v", err)
+	}
+}
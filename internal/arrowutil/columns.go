@@ -0,0 +1,77 @@
+package arrowutil
+
+import (
+	"github.com/apache/arrow/go/arrow"
+	arrowarray "github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// DataType returns the Arrow data type that a column of a type
+// is exported as. The time columns are timestamps in nanoseconds.
+func DataType(typ flux.ColType) (arrow.DataType, error) {
+	switch typ {
+	case flux.TInt:
+		return arrow.PrimitiveTypes.Int64, nil
+	case flux.TUInt:
+		return arrow.PrimitiveTypes.Uint64, nil
+	case flux.TFloat:
+		return arrow.PrimitiveTypes.Float64, nil
+	case flux.TString:
+		return arrow.BinaryTypes.String, nil
+	case flux.TBool:
+		return arrow.FixedWidthTypes.Boolean, nil
+	case flux.TTime:
+		return arrow.FixedWidthTypes.Timestamp_ns, nil
+	default:
+		return nil, errors.Newf(codes.Unimplemented, "cannot export column of type %v", typ)
+	}
+}
+
+// Column returns column j of a chunk as an Arrow array
+// with the data type returned by DataType.
+// The array must be released.
+func Column(cr flux.ColReader, j int, mem memory.Allocator) arrowarray.Interface {
+	switch cr.Cols()[j].Type {
+	case flux.TInt:
+		arr := cr.Ints(j)
+		arr.Retain()
+		return arr
+	case flux.TUInt:
+		arr := cr.UInts(j)
+		arr.Retain()
+		return arr
+	case flux.TFloat:
+		arr := cr.Floats(j)
+		arr.Retain()
+		return arr
+	case flux.TBool:
+		arr := cr.Bools(j)
+		arr.Retain()
+		return arr
+	case flux.TTime:
+		// the times are int64 nanoseconds, so the
+		// buffers are shared with a timestamp array.
+		data := cr.Times(j).Data()
+		tdata := arrowarray.NewData(arrow.FixedWidthTypes.Timestamp_ns, data.Len(), data.Buffers(), nil, data.NullN(), data.Offset())
+		defer tdata.Release()
+		return arrowarray.MakeFromData(tdata)
+	default:
+		// the strings of flux may be a repeated value
+		// rather than an array, so they are copied.
+		vs := cr.Strings(j)
+		b := arrowarray.NewStringBuilder(mem)
+		defer b.Release()
+		b.Reserve(vs.Len())
+		for i, l := 0, vs.Len(); i < l; i++ {
+			if vs.IsNull(i) {
+				b.AppendNull()
+			} else {
+				b.Append(vs.Value(i))
+			}
+		}
+		return b.NewArray()
+	}
+}
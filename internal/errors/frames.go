@@ -0,0 +1,129 @@
+package errors
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxFrames is the maximum number of source
+// frames added to the message of an error.
+const maxFrames = 5
+
+// locationPattern matches the source locations in error messages,
+// which are formatted as @line:column-line:column. Locations in other
+// files are prefixed with the file name and a pipe instead. Ranges
+// without either prefix, such as the times 10:00-12:30, are not locations.
+var locationPattern = regexp.MustCompile(`(?:@|([^\s|@]+\|))(\d+):(\d+)-(\d+):(\d+)`)
+
+// WithSourceFrames returns an error whose message is followed by the
+// lines of the source that the locations in the message refer to,
+// with carets under each location. Locations that refer to other files
+// or are not in the source are ignored. The code and documentation URL
+// of the error are kept and err is wrapped, so errors.Is and errors.As
+// find its cause. If there are no locations, err is returned.
+func WithSourceFrames(err error, src string) error {
+	if err == nil || src == "" {
+		return err
+	}
+	msg := err.Error()
+	lines := strings.Split(src, "\n")
+
+	var (
+		frames []string
+		seen   = make(map[string]bool)
+	)
+	for _, m := range locationPattern.FindAllStringSubmatch(msg, -1) {
+		if m[1] != "" || seen[m[0]] {
+			continue
+		}
+		seen[m[0]] = true
+		var pos [4]int
+		for i := range pos {
+			pos[i], _ = strconv.Atoi(m[i+2])
+		}
+		if frame, ok := sourceFrame(lines, pos[0], pos[1], pos[2], pos[3]); ok {
+			frames = append(frames, frame)
+		}
+		if len(frames) == maxFrames {
+			break
+		}
+	}
+	if len(frames) == 0 {
+		return err
+	}
+	return &Error{
+		Code:   Code(err),
+		DocURL: DocURL(err),
+		Err: &sourceFramesError{
+			err:    err,
+			frames: strings.Join(frames, "\n"),
+		},
+	}
+}
+
+// sourceFramesError is an error followed by its source frames.
+// It wraps the error so that errors.Is and errors.As find its cause.
+type sourceFramesError struct {
+	err    error
+	frames string
+}
+
+func (e *sourceFramesError) Error() string {
+	return e.err.Error() + "\n\n" + e.frames
+}
+
+func (e *sourceFramesError) Unwrap() error {
+	return e.err
+}
+
+// sourceFrame formats the lines from the start to the end position
+// with a gutter of line numbers and carets under the span. Columns
+// count bytes from 1 and the end column is exclusive.
+func sourceFrame(lines []string, startLine, startCol, endLine, endCol int) (string, bool) {
+	if startLine < 1 || endLine < startLine || endLine > len(lines) {
+		return "", false
+	}
+	width := len(strconv.Itoa(endLine))
+	var sb strings.Builder
+	for n := startLine; n <= endLine; n++ {
+		line := strings.TrimRight(lines[n-1], "\r")
+		fmt.Fprintf(&sb, "%*d | %s\n", width, n, line)
+
+		from, to := 1, len(line)+1
+		if n == startLine {
+			from = startCol
+		}
+		if n == endLine {
+			to = endCol
+		}
+		if from < 1 {
+			from = 1
+		}
+		if from > len(line)+1 {
+			from = len(line) + 1
+		}
+		if to > len(line)+1 {
+			to = len(line) + 1
+		}
+		if to <= from {
+			// Empty spans, such as the end of the input,
+			// get a single caret.
+			to = from + 1
+		}
+		fmt.Fprintf(&sb, "%*s | %s%s\n", width, "", padding(line[:from-1]), strings.Repeat("^", to-from))
+	}
+	return sb.String(), true
+}
+
+// padding returns spaces as wide as the prefix of a line,
+// keeping its tabs so the carets line up with the line.
+func padding(prefix string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return r
+		}
+		return ' '
+	}, prefix)
+}
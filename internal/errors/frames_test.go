@@ -0,0 +1,114 @@
+package errors_test
+
+import (
+	stderrors "errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+func TestWithSourceFrames(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+		src  string
+		want string
+	}{
+		{
+			name: "single line",
+			err:  errors.New(codes.Invalid, "error @1:10-1:15: expected int but found string"),
+			src:  `x = 10 + "foo"`,
+			want: `error @1:10-1:15: expected int but found string
+
+1 | x = 10 + "foo"
+  |          ^^^^^
+`,
+		},
+		{
+			name: "multiple lines",
+			err:  errors.New(codes.Invalid, "error @2:5-4:2: missing required argument"),
+			src: `a = 1
+b = f(
+	x: 1,
+)`,
+			want: `error @2:5-4:2: missing required argument
+
+2 | b = f(
+  |     ^^
+3 | 	x: 1,
+  | ^^^^^^
+4 | )
+  | ^
+`,
+		},
+		{
+			name: "tabs",
+			err:  errors.New(codes.Invalid, "error @1:3-1:4: undefined identifier y"),
+			src:  "\t\ty",
+			want: "error @1:3-1:4: undefined identifier y\n\n1 | \t\ty\n  | \t\t^\n",
+		},
+		{
+			name: "empty span and duplicates",
+			err:  stderrors.New("error at @1:7-1:7: expected ARROW, got EOF\n\nerror at @1:7-1:7: invalid expression"),
+			src:  "x = ()",
+			want: `error at @1:7-1:7: expected ARROW, got EOF
+
+error at @1:7-1:7: invalid expression
+
+1 | x = ()
+  |       ^
+`,
+		},
+		{
+			name: "other file",
+			err:  errors.New(codes.Invalid, "error calling function \"f\" @1:1-1:4: universe.flux|10:3-10:9: boom"),
+			src:  "f()",
+			want: `error calling function "f" @1:1-1:4: universe.flux|10:3-10:9: boom
+
+1 | f()
+  | ^^^
+`,
+		},
+		{
+			name: "other file with at sign",
+			err:  errors.New(codes.Invalid, "error @universe.flux|10:3-10:9: boom"),
+			src:  "f()",
+			want: "error @universe.flux|10:3-10:9: boom",
+		},
+		{
+			name: "range without prefix",
+			err:  errors.New(codes.Invalid, "no data between 1:00-1:30 or at 2019-08-14T10:03:12Z"),
+			src:  "x = 1",
+			want: "no data between 1:00-1:30 or at 2019-08-14T10:03:12Z",
+		},
+		{
+			name: "no location",
+			err:  errors.New(codes.Invalid, "something failed"),
+			src:  "x = 1",
+			want: "something failed",
+		},
+		{
+			name: "location outside of source",
+			err:  errors.New(codes.Invalid, "error @3:1-3:2: boom"),
+			src:  "x = 1",
+			want: "error @3:1-3:2: boom",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := errors.WithSourceFrames(tt.err, tt.src)
+			if !cmp.Equal(tt.want, got.Error()) {
+				t.Errorf("unexpected error -want/+got:\n%s", cmp.Diff(tt.want, got.Error()))
+			}
+			if want, got := flux.ErrorCode(tt.err), flux.ErrorCode(got); want != got {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+			if !stderrors.Is(got, tt.err) {
+				t.Error("expected the error to wrap its cause")
+			}
+		})
+	}
+}
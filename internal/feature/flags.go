@@ -77,6 +77,18 @@ func OptimizeDerivative() BoolFlag {
 	return optimizeDerivative
 }
 
+var bytecodeCompiler = feature.MakeBoolFlag(
+	"Bytecode Compiler",
+	"bytecodeCompiler",
+	"Query Team",
+	false,
+)
+
+// BytecodeCompiler - Evaluate compiled functions with the bytecode VM instead of the tree-walking evaluator
+func BytecodeCompiler() BoolFlag {
+	return bytecodeCompiler
+}
+
 // Inject will inject the Flagger into the context.
 func Inject(ctx context.Context, flagger Flagger) context.Context {
 	return feature.Inject(ctx, flagger)
@@ -88,6 +100,7 @@ var all = []Flag{
 	groupTransformationGroup,
 	queryConcurrencyLimit,
 	optimizeDerivative,
+	bytecodeCompiler,
 }
 
 var byKey = map[string]Flag{
@@ -96,6 +109,7 @@ var byKey = map[string]Flag{
 	"groupTransformationGroup":         groupTransformationGroup,
 	"queryConcurrencyLimit":            queryConcurrencyLimit,
 	"optimizeDerivative":               optimizeDerivative,
+	"bytecodeCompiler":                 bytecodeCompiler,
 }
 
 // Flags returns all feature flags.
@@ -0,0 +1,46 @@
+// Package grpcutil implements the helpers shared
+// by the gRPC services that execute Flux queries.
+package grpcutil
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"google.golang.org/grpc/status"
+
+	grpccodes "google.golang.org/grpc/codes"
+)
+
+// ToStatus converts an error to a gRPC status, unless it already is one.
+// The codes of flux are the same as the codes of gRPC.
+func ToStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	code := errors.Code(err)
+	if code == codes.Inherit {
+		code = codes.Unknown
+	}
+	return status.Error(grpccodes.Code(code), err.Error())
+}
+
+// WithValues returns a context that is canceled with ctx and has the
+// values of both ctx and values, so a query executed for an RPC is
+// canceled with the RPC and reads the dependencies of the service.
+// The values of ctx take precedence.
+func WithValues(ctx, values context.Context) context.Context {
+	return valueContext{Context: ctx, values: values}
+}
+
+type valueContext struct {
+	context.Context
+	values context.Context
+}
+
+func (ctx valueContext) Value(key interface{}) interface{} {
+	if v := ctx.Context.Value(key); v != nil {
+		return v
+	}
+	return ctx.values.Value(key)
+}
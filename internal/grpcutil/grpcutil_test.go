@@ -0,0 +1,47 @@
+package grpcutil_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/grpcutil"
+	"google.golang.org/grpc/status"
+
+	grpccodes "google.golang.org/grpc/codes"
+)
+
+func TestToStatus(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want grpccodes.Code
+	}{
+		{err: errors.New(codes.Invalid, "invalid"), want: grpccodes.InvalidArgument},
+		{err: errors.Wrap(errors.New(codes.NotFound, "missing"), codes.Inherit, "wrapped"), want: grpccodes.NotFound},
+		{err: errors.New(codes.Inherit, "inherit"), want: grpccodes.Unknown},
+		{err: status.Error(grpccodes.Canceled, "canceled"), want: grpccodes.Canceled},
+	} {
+		if got := status.Code(grpcutil.ToStatus(tc.err)); got != tc.want {
+			t.Errorf("unexpected code for %q -want/+got:\n\t- %v\n\t+ %v", tc.err, tc.want, got)
+		}
+	}
+}
+
+func TestWithValues(t *testing.T) {
+	type key string
+	values := context.WithValue(context.WithValue(context.Background(), key("a"), "values"), key("b"), "values")
+	parent, cancel := context.WithCancel(context.WithValue(context.Background(), key("a"), "rpc"))
+	ctx := grpcutil.WithValues(parent, values)
+
+	if want, got := "rpc", ctx.Value(key("a")); want != got {
+		t.Errorf("unexpected value -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+	if want, got := "values", ctx.Value(key("b")); want != got {
+		t.Errorf("unexpected value -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+	cancel()
+	if ctx.Err() != context.Canceled {
+		t.Errorf("expected the context to be canceled with its parent, got %v", ctx.Err())
+	}
+}
@@ -36,3 +36,29 @@ type defaultFlagger struct{}
 func (defaultFlagger) FlagValue(_ context.Context, flag Flag) interface{} {
 	return flag.Default()
 }
+
+// WithOverrides will inject a Flagger that returns the given values
+// for the flags with matching keys and defers to the Flagger
+// of the context for the other flags.
+func WithOverrides(ctx context.Context, values map[string]interface{}) context.Context {
+	if len(values) == 0 {
+		return ctx
+	}
+	return Inject(ctx, overrideFlagger{
+		parent: GetFlagger(ctx),
+		values: values,
+	})
+}
+
+// overrideFlagger overrides the values of some flags of another Flagger.
+type overrideFlagger struct {
+	parent Flagger
+	values map[string]interface{}
+}
+
+func (f overrideFlagger) FlagValue(ctx context.Context, flag Flag) interface{} {
+	if v, ok := f.values[flag.Key()]; ok {
+		return v
+	}
+	return f.parent.FlagValue(ctx, flag)
+}
@@ -149,3 +149,57 @@ func (f testFlagsFlagger) FlagValue(ctx context.Context, flag feature.Flag) inte
 func newFlag(key string, defaultValue interface{}) feature.Flag {
 	return feature.MakeFlag(key, key, "", defaultValue)
 }
+
+func TestWithOverrides(t *testing.T) {
+	a, b := newFlag("a", false).(feature.BoolFlag), newFlag("b", 1).(feature.IntFlag)
+	ctx := feature.Inject(context.Background(), testFlagsFlagger{
+		m: map[string]interface{}{"b": int32(2)},
+	})
+	ctx = feature.WithOverrides(ctx, map[string]interface{}{"a": true})
+
+	if !a.Enabled(ctx) {
+		t.Error("expected the overridden flag to be enabled")
+	}
+	if want, got := int32(2), b.Int(ctx); want != got {
+		t.Errorf("unexpected value of the flag that is not overridden: got %v, want %v", got, want)
+	}
+
+	ctx = feature.WithOverrides(ctx, map[string]interface{}{"b": int32(3)})
+	if !a.Enabled(ctx) {
+		t.Error("expected the earlier override to be kept")
+	}
+	if want, got := int32(3), b.Int(ctx); want != got {
+		t.Errorf("unexpected value of the overridden flag: got %v, want %v", got, want)
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	cases := []struct {
+		flag     feature.Flag
+		s        string
+		expected interface{}
+		wantErr  bool
+	}{
+		{flag: newFlag("test", false), s: "true", expected: true},
+		{flag: newFlag("test", 0), s: "42", expected: int32(42)},
+		{flag: newFlag("test", 0.0), s: "4.2", expected: 4.2},
+		{flag: newFlag("test", ""), s: "abc", expected: "abc"},
+		{flag: newFlag("test", false), s: "notabool", wantErr: true},
+		{flag: newFlag("test", 0), s: "4.2", wantErr: true},
+	}
+	for _, test := range cases {
+		actual, err := feature.ParseValue(test.flag, test.s)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("expected an error parsing %q for a flag with default %v", test.s, test.flag.Default())
+			}
+			continue
+		} else if err != nil {
+			t.Errorf("unexpected error parsing %q: %s", test.s, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("unexpected value: got %v, want %v", actual, test.expected)
+		}
+	}
+}
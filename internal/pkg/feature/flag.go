@@ -3,6 +3,7 @@ package feature
 import (
 	"context"
 	"fmt"
+	"strconv"
 )
 
 // Flag represents a generic feature flag with a key and a default.
@@ -164,3 +165,19 @@ func (f BoolFlag) Enabled(ctx context.Context) bool {
 	}
 	return i
 }
+
+// ParseValue parses the string as a value for the flag.
+// The type of the value is the type of the default of the flag.
+func ParseValue(flag Flag, s string) (interface{}, error) {
+	switch flag.Default().(type) {
+	case bool:
+		return strconv.ParseBool(s)
+	case float64:
+		return strconv.ParseFloat(s, 64)
+	case int32, int:
+		v, err := strconv.ParseInt(s, 10, 32)
+		return int32(v), err
+	default:
+		return s, nil
+	}
+}
@@ -26,6 +26,8 @@ var (
 	TzsetOffset            = tzsetOffset
 )
 
+const Omega = omega
+
 type RuleKind int
 
 const (
@@ -53,3 +55,19 @@ func TzsetRule(s string) (Rule, string, bool) {
 	}
 	return rr, rs, ok
 }
+
+func SetEmbeddedTZDataForTesting(data string) (restore func()) {
+	orig := embeddedTZData
+	embeddedTZData = data
+	return func() {
+		embeddedTZData = orig
+	}
+}
+
+func SetZoneSourcesForTesting(sources []string) (restore func()) {
+	orig := zoneSources
+	zoneSources = sources
+	return func() {
+		zoneSources = orig
+	}
+}
@@ -0,0 +1,6 @@
+package zoneinfo
+
+// The time zone database embedded with the timetzdata build tag
+// is copied from the Go toolchain. It is checked in so that the
+// build tag works without generating it first.
+//go:generate go run gen_tzdata.go
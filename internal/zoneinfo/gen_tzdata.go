@@ -0,0 +1,40 @@
+//go:build ignore
+// +build ignore
+
+// This program copies $GOROOT/lib/time/zoneinfo.zip
+// to zoneinfo.zip for the timetzdata build tag.
+package main
+
+import (
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	out, err := exec.Command("go", "env", "GOROOT").Output()
+	if err != nil {
+		log.Fatalf("cannot find GOROOT: %v", err)
+	}
+	goroot := strings.TrimSpace(string(out))
+
+	src, err := os.Open(filepath.Join(goroot, "lib", "time", "zoneinfo.zip"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create("zoneinfo.zip")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		log.Fatal(err)
+	}
+	if err := dst.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
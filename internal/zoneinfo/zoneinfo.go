@@ -7,6 +7,7 @@ package zoneinfo
 import (
 	"errors"
 	"os"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -164,9 +165,11 @@ func (l *Location) lookup(sec int64) (name string, offset int, start, end int64,
 	isDST = zone.isDST
 
 	// If we're at the end of the known zone transitions,
-	// try the extend string.
+	// try the extend string. Its zone starts at the last
+	// transition, which sec is after, rather than at the
+	// end of the search, which is omega.
 	if lo == len(tx)-1 && l.extend != "" {
-		if ename, eoffset, estart, eend, eisDST, ok := tzset(l.extend, end, sec); ok {
+		if ename, eoffset, estart, eend, eisDST, ok := tzset(l.extend, start, sec); ok {
 			return ename, eoffset, estart, eend, eisDST
 		}
 	}
@@ -227,12 +230,12 @@ func (l *Location) firstZoneUsed() bool {
 }
 
 // tzset takes a timezone string like the one found in the TZ environment
-// variable, the end of the last time zone transition expressed as seconds
+// variable, the time of the last time zone transition expressed as seconds
 // since January 1, 1970 00:00:00 UTC, and a time expressed the same way.
 // We call this a tzset string since in C the function tzset reads TZ.
 // The return values are as for lookup, plus ok which reports whether the
 // parse succeeded.
-func tzset(s string, initEnd, sec int64) (name string, offset int, start, end int64, isDST, ok bool) {
+func tzset(s string, lastTxSec, sec int64) (name string, offset int, start, end int64, isDST, ok bool) {
 	var (
 		stdName, dstName     string
 		stdOffset, dstOffset int
@@ -253,7 +256,7 @@ func tzset(s string, initEnd, sec int64) (name string, offset int, start, end in
 
 	if len(s) == 0 || s[0] == ',' {
 		// No daylight savings time.
-		return stdName, stdOffset, initEnd, omega, false, true
+		return stdName, stdOffset, lastTxSec, omega, false, true
 	}
 
 	dstName, s, ok = tzsetName(s)
@@ -573,6 +576,11 @@ var errLocation = errors.New("time: invalid location name")
 var zoneinfo *string
 var zoneinfoOnce sync.Once
 
+// embeddedTZData is the uncompressed zip file of the time zone database
+// embedded in the binary, it is empty unless the binary is built
+// with the timetzdata build tag.
+var embeddedTZData string
+
 // LoadLocation returns the Location with the given name.
 //
 // If the name is "" or "UTC", LoadLocation returns UTC.
@@ -586,7 +594,9 @@ var zoneinfoOnce sync.Once
 // LoadLocation looks in the directory or uncompressed zip file
 // named by the ZONEINFO environment variable, if any, then looks in
 // known installation locations on Unix systems,
-// and finally looks in $GOROOT/lib/time/zoneinfo.zip.
+// then looks in $GOROOT/lib/time/zoneinfo.zip,
+// and finally looks in the time zone database embedded in the binary
+// when it is built with the timetzdata build tag.
 func LoadLocation(name string) (*Location, error) {
 	if name == "" || name == "UTC" {
 		return UTC, nil
@@ -616,6 +626,13 @@ func LoadLocation(name string) (*Location, error) {
 	} else if firstErr == nil {
 		firstErr = err
 	}
+	if embeddedTZData != "" {
+		if zoneData, err := loadTzinfoFromZipReader(strings.NewReader(embeddedTZData), "embedded tzdata", name); err == nil {
+			if z, err := LoadLocationFromTZData(name, zoneData); err == nil {
+				return z, nil
+			}
+		}
+	}
 	return nil, firstErr
 }
 
@@ -12,6 +12,7 @@ func TestLocation_ToLocalClock(t *testing.T) {
 		US_Pacific     = "America/Los_Angeles"
 		Australia_East = "Australia/Sydney"
 		American_Samoa = "Pacific/Apia"
+		Japan          = "Asia/Tokyo"
 	)
 	for _, tt := range []struct {
 		name    string
@@ -43,6 +44,12 @@ func TestLocation_ToLocalClock(t *testing.T) {
 			s:       "2017-11-05T01:30:00Z",
 			want:    "2017-11-05T01:30:00-07:00",
 		},
+		{
+			name:    "Japan",
+			locname: Japan,
+			s:       "2017-02-24T12:00:00Z",
+			want:    "2017-02-24T12:00:00+09:00",
+		},
 		{
 			name:    "Australia_East",
 			locname: Australia_East,
@@ -378,7 +378,12 @@ func loadTzinfoFromZip(zipfile, name string) ([]byte, error) {
 		return nil, err
 	}
 	defer fd.Close()
+	return loadTzinfoFromZipReader(fd, zipfile, name)
+}
 
+// loadTzinfoFromZipReader returns the contents of the file with the given name
+// in the uncompressed zip file read by fd. The zipfile is only used in errors.
+func loadTzinfoFromZipReader(fd io.ReadSeeker, zipfile, name string) ([]byte, error) {
 	const (
 		zecheader = 0x06054b50
 		zcheader  = 0x02014b50
@@ -518,7 +523,7 @@ func loadLocation(name string, sources []string) (z *Location, firstErr error) {
 	return nil, errors.New("unknown time zone " + name)
 }
 
-func preadn(fd *os.File, buf []byte, off int) error {
+func preadn(fd io.ReadSeeker, buf []byte, off int) error {
 	whence := io.SeekStart
 	if off < 0 {
 		whence = io.SeekEnd
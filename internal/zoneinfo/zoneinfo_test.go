@@ -7,6 +7,7 @@ package zoneinfo_test
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"reflect"
 	"testing"
 
@@ -65,6 +66,33 @@ func TestLoadLocationValidatesNames(t *testing.T) {
 	}
 }
 
+func TestLoadLocationFromEmbeddedTZData(t *testing.T) {
+	zoneinfo.ResetZoneinfoForTesting()
+	t.Setenv("ZONEINFO", "")
+	defer zoneinfo.SetZoneSourcesForTesting(nil)()
+
+	const name = "America/New_York"
+	restore := zoneinfo.SetEmbeddedTZDataForTesting("")
+	_, err := zoneinfo.LoadLocation(name)
+	restore()
+	if err == nil {
+		t.Fatalf("LoadLocation(%q) succeeded without a time zone database", name)
+	}
+
+	data, err := ioutil.ReadFile(zoneinfo.OrigZoneSources[len(zoneinfo.OrigZoneSources)-1])
+	if err != nil {
+		t.Skipf("cannot read the time zone database: %v", err)
+	}
+	defer zoneinfo.SetEmbeddedTZDataForTesting(string(data))()
+	loc, err := zoneinfo.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("LoadLocation(%q) error = %v", name, err)
+	}
+	if got := loc.String(); got != name {
+		t.Errorf("LoadLocation(%q) = %q", name, got)
+	}
+}
+
 func TestVersion3(t *testing.T) {
 	zoneinfo.ForceZipFileForTesting(true)
 	defer zoneinfo.ForceZipFileForTesting(false)
@@ -158,15 +186,15 @@ func TestMalformedTZData(t *testing.T) {
 
 func TestTzset(t *testing.T) {
 	for _, test := range []struct {
-		inStr string
-		inEnd int64
-		inSec int64
-		name  string
-		off   int
-		start int64
-		end   int64
-		isDST bool
-		ok    bool
+		inStr    string
+		inLastTx int64
+		inSec    int64
+		name     string
+		off      int
+		start    int64
+		end      int64
+		isDST    bool
+		ok       bool
 	}{
 		{"", 0, 0, "", 0, 0, 0, false, false},
 		{"PST8PDT,M3.2.0,M11.1.0", 0, 2159200800, "PDT", -7 * 60 * 60, 2152173600, 2172733200, true, true},
@@ -176,14 +204,43 @@ func TestTzset(t *testing.T) {
 		{"PST8PDT,M3.2.0,M11.1.0", 0, 2172733199, "PDT", -7 * 60 * 60, 2152173600, 2172733200, true, true},
 		{"PST8PDT,M3.2.0,M11.1.0", 0, 2172733200, "PST", -8 * 60 * 60, 2172733200, 2177452800, false, true},
 		{"PST8PDT,M3.2.0,M11.1.0", 0, 2172733201, "PST", -8 * 60 * 60, 2172733200, 2177452800, false, true},
+		{"JST-9", -577962000, 1487937600, "JST", 9 * 60 * 60, -577962000, zoneinfo.Omega, false, true},
 	} {
-		name, off, start, end, isDST, ok := zoneinfo.Tzset(test.inStr, test.inEnd, test.inSec)
+		name, off, start, end, isDST, ok := zoneinfo.Tzset(test.inStr, test.inLastTx, test.inSec)
 		if name != test.name || off != test.off || start != test.start || end != test.end || isDST != test.isDST || ok != test.ok {
-			t.Errorf("tzset(%q, %d, %d) = %q, %d, %d, %d, %t, %t, want %q, %d, %d, %d, %t, %t", test.inStr, test.inEnd, test.inSec, name, off, start, end, isDST, ok, test.name, test.off, test.start, test.end, test.isDST, test.ok)
+			t.Errorf("tzset(%q, %d, %d) = %q, %d, %d, %d, %t, %t, want %q, %d, %d, %d, %t, %t", test.inStr, test.inLastTx, test.inSec, name, off, start, end, isDST, ok, test.name, test.off, test.start, test.end, test.isDST, test.ok)
 		}
 	}
 }
 
+// The zones of the tzset string of a location without daylight saving
+// time start at its last transition, and times after it are in them.
+func TestLookupAfterLastTransition(t *testing.T) {
+	// Use the checked-in time zone database so that
+	// the test does not depend on the system's.
+	data, err := ioutil.ReadFile("zoneinfo.zip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	zoneinfo.ResetZoneinfoForTesting()
+	t.Setenv("ZONEINFO", "")
+	defer zoneinfo.SetZoneSourcesForTesting(nil)()
+	defer zoneinfo.SetEmbeddedTZDataForTesting(string(data))()
+
+	loc, err := zoneinfo.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	const sec = 1487937600 // 2017-02-24T12:00:00Z
+	name, offset, start, end, _ := loc.Lookup(sec)
+	if name != "JST" || offset != 9*60*60 {
+		t.Errorf("lookup(%d) = %q, %d, want %q, %d", sec, name, offset, "JST", 9*60*60)
+	}
+	if start > sec || sec >= end {
+		t.Errorf("lookup(%d) is in the zone [%d, %d), which does not contain it", sec, start, end)
+	}
+}
+
 func TestTzsetName(t *testing.T) {
 	for _, test := range []struct {
 		in   string
@@ -0,0 +1,20 @@
+//go:build timetzdata
+// +build timetzdata
+
+package zoneinfo
+
+import (
+	_ "embed"
+)
+
+// tzdataZip is a copy of $GOROOT/lib/time/zoneinfo.zip that is updated
+// by go generate, or make tzdata. Building with the timetzdata build tag embeds it in the binary,
+// so that static binaries running on systems without a time zone
+// database can still load locations.
+//
+//go:embed zoneinfo.zip
+var tzdataZip string
+
+func init() {
+	embeddedTZData = tzdataZip
+}
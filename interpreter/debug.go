@@ -0,0 +1,32 @@
+package interpreter
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// Debugger is notified by the interpreter before it evaluates each
+// statement, including the statements in the body of user-defined
+// functions. Functions that are compiled instead of interpreted,
+// such as the functions passed to map, are not debugged.
+type Debugger interface {
+	// Break is called before the statement is evaluated in the scope.
+	// The call stack of the statement is available from Stack(ctx).
+	// The evaluation stops with the error if it returns one.
+	Break(ctx context.Context, stmt semantic.Statement, scope values.Scope) error
+}
+
+type debuggerKey struct{}
+
+// WithDebugger returns a context where the interpreter
+// calls the debugger before it evaluates each statement.
+func WithDebugger(ctx context.Context, d Debugger) context.Context {
+	return context.WithValue(ctx, debuggerKey{}, d)
+}
+
+func debuggerFromContext(ctx context.Context) Debugger {
+	d, _ := ctx.Value(debuggerKey{}).(Debugger)
+	return d
+}
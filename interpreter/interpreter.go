@@ -9,6 +9,7 @@ import (
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/sandbox"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
@@ -108,7 +109,7 @@ func (itrp *Interpreter) doFile(ctx context.Context, file *semantic.File, scope
 		}
 	}
 	for _, stmt := range file.Body {
-		val, err := itrp.doStatement(ctx, stmt, scope)
+		val, err := itrp.doLimitedStatement(ctx, stmt, scope)
 		if err != nil {
 			return err
 		}
@@ -154,6 +155,11 @@ func (itrp *Interpreter) doImport(dec *semantic.ImportDeclaration, scope values.
 
 // doStatement returns the resolved value of a top-level statement
 func (itrp *Interpreter) doStatement(ctx context.Context, stmt semantic.Statement, scope values.Scope) (values.Value, error) {
+	if d := debuggerFromContext(ctx); d != nil {
+		if err := d.Break(ctx, stmt, scope); err != nil {
+			return nil, err
+		}
+	}
 	scope.SetReturn(values.InvalidValue)
 	switch s := stmt.(type) {
 	case *semantic.OptionStatement:
@@ -411,7 +417,14 @@ func (itrp *Interpreter) doExpression(ctx context.Context, expr semantic.Express
 		if err != nil {
 			return nil, err
 		}
-		return bf(l, r)
+		v, err := bf(l, r)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkSize(ctx, v); err != nil {
+			return nil, errors.Wrapf(err, codes.Inherit, "%s", e.Location())
+		}
+		return v, nil
 	case *semantic.LogicalExpression:
 		l, err := itrp.doExpression(ctx, e.Left, scope)
 		if err != nil {
@@ -481,6 +494,9 @@ func (itrp *Interpreter) doStringExpression(ctx context.Context, s *semantic.Str
 		}
 		b.WriteString(part.Str())
 	}
+	if err := checkStringSize(ctx, b.Len()); err != nil {
+		return nil, errors.Wrapf(err, codes.Inherit, "%s", s.Location())
+	}
 	return values.NewString(b.String()), nil
 }
 
@@ -517,6 +533,9 @@ func (itrp *Interpreter) doArray(ctx context.Context, a *semantic.ArrayExpressio
 		}
 		elements[i] = v
 	}
+	if err := checkArraySize(ctx, len(elements)); err != nil {
+		return nil, errors.Wrapf(err, codes.Inherit, "%s", a.Location())
+	}
 	return values.NewArrayWithBacking(a.TypeOf(), elements), nil
 }
 
@@ -616,7 +635,9 @@ func (itrp *Interpreter) doLiteral(lit semantic.Literal) (values.Value, error) {
 	}
 }
 
-func functionName(call *semantic.CallExpression) string {
+// FunctionName returns the name of the function called by the call
+// expression as it appears in errors and call stacks.
+func FunctionName(call *semantic.CallExpression) string {
 	switch callee := call.Callee.(type) {
 	case *semantic.IdentifierExpression:
 		return callee.Name
@@ -681,18 +702,30 @@ func (itrp *Interpreter) doCall(ctx context.Context, call *semantic.CallExpressi
 	// We do not attach this source location information when evaluating
 	// arguments as this source location information is only
 	// for the currently called function.
-	fname := functionName(call)
+	fname := FunctionName(call)
 	ctx = withStackEntry(ctx, fname, call.Location())
+	if max := maxCallDepth(ctx); callDepth(ctx) > max {
+		err := callDepthError{max: max}
+		return nil, errors.Wrapf(err, codes.ResourceExhausted, "error calling function %q @%s", fname, call.Location())
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, contextError(err)
+	}
 	value, err := f.Call(ctx, argObj)
 	if err != nil {
 		// If a function has an underscore as a prefix, consider it
 		// as an internal call and don't add it to the error message.
-		if !strings.HasPrefix(fname, "_") {
+		var depthErr callDepthError
+		if !strings.HasPrefix(fname, "_") && !errors.As(err, &depthErr) {
 			err = errors.Wrapf(err, codes.Inherit, "error calling function %q @%s", fname, call.Location())
 		}
 		return nil, err
 	}
 
+	if err := checkSize(ctx, value); err != nil {
+		return nil, errors.Wrapf(err, codes.Inherit, "error calling function %q @%s", fname, call.Location())
+	}
+
 	if f.HasSideEffect() {
 		itrp.sideEffects = append(itrp.sideEffects, SideEffect{Node: call, Value: value})
 	}
@@ -1634,8 +1667,51 @@ type contextKey int
 
 const (
 	callStackKey contextKey = iota
+	maxCallDepthKey
 )
 
+// DefaultMaxCallDepth is the maximum depth of the call stack
+// when the context does not set one with WithMaxCallDepth.
+const DefaultMaxCallDepth = 1000
+
+// WithMaxCallDepth returns a context where calls to functions fail
+// when the call stack is deeper than depth, instead of growing
+// the Go stack without bound. It takes precedence over the
+// MaxCallDepth of the sandbox limits.
+func WithMaxCallDepth(ctx context.Context, depth int) context.Context {
+	return context.WithValue(ctx, maxCallDepthKey, depth)
+}
+
+func maxCallDepth(ctx context.Context) int {
+	if depth, ok := ctx.Value(maxCallDepthKey).(int); ok {
+		return depth
+	}
+	if depth := sandbox.Get(ctx).MaxCallDepth; depth > 0 {
+		return depth
+	}
+	return DefaultMaxCallDepth
+}
+
+// callDepthError is the cause of the error returned by the call that
+// exceeds the maximum call depth. The calls that led to it do not wrap
+// the error, since the message would repeat each of them.
+type callDepthError struct {
+	max int
+}
+
+func (e callDepthError) Error() string {
+	return fmt.Sprintf("maximum call depth of %d exceeded", e.max)
+}
+
+// callDepth returns the depth of the call stack,
+// including the calls to internal functions.
+func callDepth(ctx context.Context) int {
+	if e, ok := ctx.Value(callStackKey).(*stackElement); ok {
+		return e.depth + 1
+	}
+	return 0
+}
+
 // StackEntry describes a single entry in the call stack.
 type StackEntry struct {
 	FunctionName string
@@ -1668,6 +1744,40 @@ func Stack(ctx context.Context) []StackEntry {
 	return stack
 }
 
+// WithCallStack returns an error whose message is followed by the
+// call stack, one line for each call starting with the innermost one.
+// The code and documentation URL of the error are kept and it unwraps
+// to the original error.
+func WithCallStack(err error, stack []StackEntry) error {
+	if err == nil || len(stack) == 0 {
+		return err
+	}
+	return &errors.Error{
+		Code:   errors.Code(err),
+		DocURL: errors.DocURL(err),
+		Err:    &callStackError{err: err, stack: stack},
+	}
+}
+
+type callStackError struct {
+	err   error
+	stack []StackEntry
+}
+
+func (e *callStackError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.err.Error())
+	sb.WriteString("\n\ncall stack:")
+	for _, entry := range e.stack {
+		fmt.Fprintf(&sb, "\n  %s @%s", entry.FunctionName, entry.Location)
+	}
+	return sb.String()
+}
+
+func (e *callStackError) Unwrap() error {
+	return e.err
+}
+
 // withStackEntry will attach StackEntry information
 // to the context to be retrieved by Stack.
 func withStackEntry(ctx context.Context, name string, loc ast.SourceLocation) context.Context {
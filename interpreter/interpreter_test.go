@@ -2,15 +2,20 @@ package interpreter_test
 
 import (
 	"context"
+	"fmt"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/dependencies/dependenciestest"
+	"github.com/influxdata/flux/dependencies/sandbox"
 	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/repl"
 	"github.com/influxdata/flux/runtime"
@@ -889,3 +894,185 @@ func TestStack(t *testing.T) {
 		t.Fatalf("unexpected stack -want/+got:\n%s", cmp.Diff(want, got))
 	}
 }
+
+// recordingDebugger records the statements it is called with
+// and stops the evaluation at the statement on the line stopAt.
+type recordingDebugger struct {
+	stopAt int
+	stmts  []string
+}
+
+func (d *recordingDebugger) Break(ctx context.Context, stmt semantic.Statement, scope values.Scope) error {
+	loc := stmt.Location()
+	if loc.File != "" {
+		return nil
+	}
+	entry := loc.Start.String()
+	if stack := interpreter.Stack(ctx); len(stack) > 0 {
+		entry += " in " + stack[0].FunctionName
+	}
+	if x, ok := scope.Lookup("x"); ok {
+		entry += fmt.Sprintf(" x=%v", x)
+	}
+	d.stmts = append(d.stmts, entry)
+	if loc.Start.Line == d.stopAt {
+		return errors.New(codes.Canceled, "stopped")
+	}
+	return nil
+}
+
+func TestDebugger(t *testing.T) {
+	src := `f = (x) => {
+    y = x + 1
+    return y * 2
+}
+a = f(x: 1)
+b = a + 1`
+
+	d := &recordingDebugger{}
+	ctx := interpreter.WithDebugger(context.Background(), d)
+	if _, _, err := runtime.Eval(ctx, src); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"1:1",
+		"5:1",
+		"2:5 in f x=1",
+		"3:5 in f x=1",
+		"6:1",
+	}
+	if !cmp.Equal(want, d.stmts) {
+		t.Errorf("unexpected statements -want/+got:\n%s", cmp.Diff(want, d.stmts))
+	}
+
+	d = &recordingDebugger{stopAt: 3}
+	ctx = interpreter.WithDebugger(context.Background(), d)
+	if _, _, err := runtime.Eval(ctx, src); err == nil {
+		t.Fatal("expected the debugger to stop the evaluation")
+	} else if want, got := codes.Canceled, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
+func TestWithCallStack(t *testing.T) {
+	cause := errors.New(codes.Invalid, "boom")
+	stack := []interpreter.StackEntry{
+		{FunctionName: "map", Location: ast.SourceLocation{Start: ast.Position{Line: 2, Column: 5}, End: ast.Position{Line: 2, Column: 30}}},
+		{FunctionName: "process", Location: ast.SourceLocation{Start: ast.Position{Line: 5, Column: 1}, End: ast.Position{Line: 5, Column: 10}}},
+	}
+	err := interpreter.WithCallStack(cause, stack)
+
+	want := "boom\n\ncall stack:\n  map @2:5-2:30\n  process @5:1-5:10"
+	if got := err.Error(); want != got {
+		t.Errorf("unexpected error -want/+got:\n%s", cmp.Diff(want, got))
+	}
+	if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+	if !errors.Is(err, cause) {
+		t.Error("expected the error to unwrap to its cause")
+	}
+}
+
+func TestMaxCallDepth(t *testing.T) {
+	src := `f = () => 1
+g = () => f()
+h = () => g()
+h()`
+
+	ctx := interpreter.WithMaxCallDepth(context.Background(), 3)
+	if _, _, err := runtime.Eval(ctx, src); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	ctx = interpreter.WithMaxCallDepth(context.Background(), 2)
+	_, _, err := runtime.Eval(ctx, src)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	// Only the call that exceeds the limit is in the message.
+	if want, got := `error calling function "f" @2:11-2:14: maximum call depth of 2 exceeded`, err.Error(); !strings.HasSuffix(got, want) || strings.Contains(got, `"g"`) {
+		t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	if want, got := codes.ResourceExhausted, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
+func TestSandboxLimits(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		src      string
+		limits   sandbox.Limits
+		wantCode codes.Code // Inherit when no error is expected
+	}{
+		{
+			name:   "within limits",
+			src:    `s = "a" + "b"` + "\n" + `arr = [1, 2, 3]`,
+			limits: sandbox.Limits{MaxStringSize: 2, MaxArraySize: 3},
+		},
+		{
+			name:     "string concatenation",
+			src:      `s = "ab" + "c"`,
+			limits:   sandbox.Limits{MaxStringSize: 2},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "string interpolation",
+			src:      `x = "ab"` + "\n" + `s = "${x}c"`,
+			limits:   sandbox.Limits{MaxStringSize: 2},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "string returned by a function",
+			src:      `import "strings"` + "\n" + `s = strings.repeat(v: "a", i: 10)`,
+			limits:   sandbox.Limits{MaxStringSize: 5},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "array",
+			src:      `arr = [1, 2, 3]`,
+			limits:   sandbox.Limits{MaxArraySize: 2},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name: "stream of tables without limits",
+			src:  `import "array"` + "\n" + `tables = array.from(rows: [{a: 1}]) |> filter(fn: (r) => r.a > 0)`,
+		},
+		{
+			name:   "stream of tables within limits",
+			src:    `import "array"` + "\n" + `tables = array.from(rows: [{a: 1}]) |> filter(fn: (r) => r.a > 0)`,
+			limits: sandbox.Limits{MaxStringSize: 2, MaxArraySize: 1},
+		},
+		{
+			name:     "call depth",
+			src:      `f = () => 1` + "\n" + `g = () => f()` + "\n" + `g()`,
+			limits:   sandbox.Limits{MaxCallDepth: 1},
+			wantCode: codes.ResourceExhausted,
+		},
+		{
+			name:     "statement timeout",
+			src:      `import "experimental/array"` + "\n" + `x = array.iterate(n: 1000000000, init: 0, fn: (state, i) => state + i)`,
+			limits:   sandbox.Limits{StatementTimeout: 10 * time.Millisecond},
+			wantCode: codes.DeadlineExceeded,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := tc.limits.Inject(context.Background())
+			_, _, err := runtime.Eval(ctx, tc.src)
+			if tc.wantCode == codes.Inherit {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if want, got := tc.wantCode, flux.ErrorCode(err); want != got {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package interpreter
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/sandbox"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// doLimitedStatement evaluates a top-level statement within
+// the statement timeout of the sandbox limits, if there is one.
+func (itrp *Interpreter) doLimitedStatement(ctx context.Context, stmt semantic.Statement, scope values.Scope) (values.Value, error) {
+	timeout := sandbox.Get(ctx).StatementTimeout
+	if timeout <= 0 {
+		return itrp.doStatement(ctx, stmt, scope)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	v, err := itrp.doStatement(ctx, stmt, scope)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		// The evaluation may have been stopped anywhere within
+		// the statement so report the statement itself.
+		return nil, errors.Newf(codes.DeadlineExceeded, "evaluation of statement @%s exceeded the time limit of %v", stmt.Location(), timeout)
+	}
+	return v, err
+}
+
+// contextError converts the error of a context that is
+// done into the error returned by the interpreter.
+func contextError(err error) error {
+	if err == context.DeadlineExceeded {
+		return errors.Wrap(err, codes.DeadlineExceeded)
+	}
+	return errors.Wrap(err, codes.Canceled)
+}
+
+// checkSize returns an error if the value is a string or an array
+// that is larger than the sandbox limits allow. The length of a stream
+// of tables is not known until it is executed, so it is never checked.
+func checkSize(ctx context.Context, v values.Value) error {
+	if v == nil || v.IsNull() {
+		return nil
+	}
+	switch v.Type().Nature() {
+	case semantic.String:
+		return checkStringSize(ctx, len(v.Str()))
+	case semantic.Array:
+		if sandbox.Get(ctx).MaxArraySize <= 0 {
+			return nil
+		}
+		if _, ok := v.(values.Stream); ok {
+			return nil
+		}
+		return checkArraySize(ctx, v.Array().Len())
+	}
+	return nil
+}
+
+func checkStringSize(ctx context.Context, n int) error {
+	if max := sandbox.Get(ctx).MaxStringSize; max > 0 && n > max {
+		return errors.Newf(codes.ResourceExhausted, "string of %d bytes exceeds the limit of %d bytes", n, max)
+	}
+	return nil
+}
+
+func checkArraySize(ctx context.Context, n int) error {
+	if max := sandbox.Get(ctx).MaxArraySize; max > 0 && n > max {
+		return errors.Newf(codes.ResourceExhausted, "array of %d elements exceeds the limit of %d elements", n, max)
+	}
+	return nil
+}
@@ -9,6 +9,12 @@ import (
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/clock"
+	"github.com/influxdata/flux/dependencies/deadline"
+	fluxhttp "github.com/influxdata/flux/dependencies/http"
+	"github.com/influxdata/flux/dependencies/feature"
+	"github.com/influxdata/flux/dependencies/metrics"
+	"github.com/influxdata/flux/dependencies/tracing"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/internal/jaeger"
@@ -17,9 +23,11 @@ import (
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/metadata"
 	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
 	"github.com/opentracing/opentracing-go"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
 )
 
@@ -123,6 +131,7 @@ func CompileAST(astPkg flux.ASTHandle, runtime flux.Runtime, now time.Time, opts
 // CompileTableObject evaluates a TableObject and produces a flux.Program.
 // now parameter must be non-zero, that is the default now time should be set before compiling.
 func CompileTableObject(ctx context.Context, to *flux.TableObject, now time.Time, opts ...CompileOption) (*Program, error) {
+	start := time.Now()
 	o := applyOptions(opts...)
 	s, err := spec.FromTableObject(ctx, to, now)
 	if err != nil {
@@ -135,6 +144,7 @@ func CompileTableObject(ctx context.Context, to *flux.TableObject, now time.Time
 	if err != nil {
 		return nil, err
 	}
+	metrics.Get(ctx).ObserveCompile(time.Since(start))
 	return &Program{
 		opts:     o,
 		PlanSpec: ps,
@@ -154,7 +164,13 @@ func buildPlan(ctx context.Context, spec *flux.Spec, opts *compileOptions) (*pla
 	pb.AddLogicalOptions(lopts...)
 	pb.AddPhysicalOptions(popts...)
 
-	ps, err := pb.Build().Plan(ctx, spec)
+	// The plans are only cached when the planner has its default options,
+	// which are not part of the key of the plan.
+	planner := pb.Build()
+	if len(lopts) > 0 || len(popts) > 0 {
+		return planner.Plan(ctx, spec)
+	}
+	ps, err := runtime.GetCompileCache(ctx).Plan(ctx, spec, planner.Plan)
 	if err != nil {
 		return nil, err
 	}
@@ -184,7 +200,10 @@ func IsNonNullJSON(bs json.RawMessage) bool {
 	return true
 }
 
-func (c FluxCompiler) Compile(ctx context.Context, runtime flux.Runtime) (flux.Program, error) {
+func (c FluxCompiler) Compile(ctx context.Context, runtime flux.Runtime) (p flux.Program, err error) {
+	_, span := tracing.Start(ctx, "compile")
+	defer func() { tracing.End(span, err) }()
+
 	query := c.Query
 
 	// Ignore context, it will be provided upon Program Start.
@@ -209,10 +228,13 @@ type ASTCompiler struct {
 	Now    time.Time
 }
 
-func (c ASTCompiler) Compile(ctx context.Context, runtime flux.Runtime) (flux.Program, error) {
+func (c ASTCompiler) Compile(ctx context.Context, runtime flux.Runtime) (p flux.Program, err error) {
+	_, span := tracing.Start(ctx, "compile")
+	defer func() { tracing.End(span, err) }()
+
 	now := c.Now
 	if now.IsZero() {
-		now = time.Now()
+		now = clock.Get(ctx).Now()
 	}
 	hdl, err := runtime.JSONToHandle(c.AST)
 	if err != nil {
@@ -273,25 +295,47 @@ func (p *Program) SetLogger(logger *zap.Logger) {
 }
 
 func (p *Program) Start(ctx context.Context, alloc *memory.Allocator) (flux.Query, error) {
-	ctx, cancel := context.WithCancel(ctx)
+	ctx, cancel := deadline.WithTimeout(ctx)
+	ctx = fluxhttp.WithQueryQuota(ctx)
+	ctx, span := tracing.StartQuery(ctx, "query")
+	q, err := p.start(ctx, cancel, alloc)
+	if err != nil {
+		tracing.End(span, err)
+		return nil, err
+	}
+	q.tracingSpans = append(q.tracingSpans, span)
+	return q, nil
+}
 
-	// This span gets closed by the query when it is done.
+// start executes the program with a context that
+// is canceled when the query is done or canceled.
+func (p *Program) start(ctx context.Context, cancel context.CancelFunc, alloc *memory.Allocator) (*query, error) {
+	// These spans get closed by the query when it is done.
 	s, cctx := opentracing.StartSpanFromContext(ctx, "execute")
+	cctx, span := tracing.Start(cctx, "execute")
 	results := make(chan flux.Result)
 	q := &query{
-		ctx:     cctx,
-		results: results,
-		alloc:   alloc,
-		span:    s,
-		cancel:  cancel,
+		ctx:          cctx,
+		results:      results,
+		alloc:        alloc,
+		span:         s,
+		tracingSpans: []trace.Span{span},
+		metrics:      metrics.Get(ctx),
+		start:        time.Now(),
+		cancel:       cancel,
 		stats: flux.Statistics{
 			Metadata: make(metadata.Metadata),
 		},
 	}
 
+	q.metrics.QueryStarted()
+
 	if execute.HaveExecutionDependencies(ctx) {
 		deps := execute.GetExecutionDependencies(ctx)
 		q.stats.Metadata.AddAll(deps.Metadata)
+		if deps.ExecutionOptions != nil {
+			q.profilers = deps.ExecutionOptions.Profilers
+		}
 	}
 
 	if traceID, sampled, found := jaeger.InfoFromSpan(s); found {
@@ -306,6 +350,9 @@ func (p *Program) Start(ctx context.Context, alloc *memory.Allocator) (flux.Quer
 	resultMap, md, err := e.Execute(cctx, p.PlanSpec, q.alloc)
 	if err != nil {
 		s.Finish()
+		tracing.End(span, err)
+		q.metrics.QueryFinished(err, time.Since(q.start), 0)
+		cancel()
 		return nil, err
 	}
 
@@ -427,13 +474,15 @@ func (eoc *ExecOptsConfig) ConfigureNow(ctx context.Context, now time.Time) {
 	deps.Inject(ctx)
 }
 
-func (p *AstProgram) getSpec(ctx context.Context, alloc *memory.Allocator) (*flux.Spec, values.Scope, error) {
+func (p *AstProgram) getSpec(ctx context.Context, alloc *memory.Allocator) (_ *flux.Spec, _ values.Scope, err error) {
 	ast, astErr := p.GetAst()
 	if astErr != nil {
 		return nil, nil, astErr
 	}
 
 	s, cctx := opentracing.StartSpanFromContext(ctx, "eval")
+	cctx, span := tracing.Start(cctx, "eval")
+	defer func() { tracing.End(span, err) }()
 
 	// Set the now option to our own default and capture the option itself
 	// to allow us to find it after the run. A user might overwrite the
@@ -473,9 +522,35 @@ func (p *AstProgram) getSpec(ctx context.Context, alloc *memory.Allocator) (*flu
 }
 
 func (p *AstProgram) Start(ctx context.Context, alloc *memory.Allocator) (flux.Query, error) {
+	// The query timeout covers the evaluation phase so
+	// it is applied before the program is evaluated.
+	ctx, cancel := deadline.WithTimeout(ctx)
+	// The HTTP requests of the evaluation count towards the quota of the query.
+	ctx = fluxhttp.WithQueryQuota(ctx)
+	ctx, span := tracing.StartQuery(ctx, "query")
+	q, err := p.start(ctx, cancel, alloc)
+	if err != nil {
+		tracing.End(span, err)
+		cancel()
+		return nil, err
+	}
+	q.tracingSpans = append(q.tracingSpans, span)
+	return q, nil
+}
+
+func (p *AstProgram) start(ctx context.Context, cancel context.CancelFunc, alloc *memory.Allocator) (*query, error) {
 	// The program must inject execution dependencies to make it available to
 	// function calls during the evaluation phase (see `tableFind`).
+	start := time.Now()
+	if p.Now.IsZero() {
+		p.Now = clock.Get(ctx).Now()
+	}
 	deps := execute.NewExecutionDependencies(alloc, &p.Now, p.Logger)
+	if execute.HaveExecutionDependencies(ctx) {
+		// Keep the workers of the caller for the fragments
+		// that are split from the plan.
+		deps.FragmentExecutor = execute.GetExecutionDependencies(ctx).FragmentExecutor
+	}
 	ctx = deps.Inject(ctx)
 	nextPlanNodeID := new(int)
 	ctx = context.WithValue(ctx, plan.NextPlanNodeIDKey, nextPlanNodeID)
@@ -486,6 +561,14 @@ func (p *AstProgram) Start(ctx context.Context, alloc *memory.Allocator) (flux.Q
 		return nil, err
 	}
 
+	// The feature flags set by the script apply to the
+	// planning and the execution of the query.
+	flags, err := FeatureFlags(scope)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Inherit, "error in reading options while starting program")
+	}
+	ctx = feature.Override(ctx, flags)
+
 	// Planning.
 	s, cctx := opentracing.StartSpanFromContext(ctx, "plan")
 	if p.opts.verbose {
@@ -503,11 +586,12 @@ func (p *AstProgram) Start(ctx context.Context, alloc *memory.Allocator) (flux.Q
 	}
 	p.PlanSpec = ps
 	s.Finish()
+	metrics.Get(ctx).ObserveCompile(time.Since(start))
 
 	// Execution.
 	s, cctx = opentracing.StartSpanFromContext(ctx, "start-program")
 	defer s.Finish()
-	return p.Program.Start(cctx, alloc)
+	return p.Program.start(cctx, cancel, alloc)
 }
 
 func (p *AstProgram) updateProfilers(ctx context.Context, scope values.Scope) error {
@@ -568,9 +652,84 @@ func getPlanOptions(plannerPkg values.Package) (plan.LogicalOption, plan.Physica
 	if err != nil {
 		return nil, nil, err
 	}
+	// Rule names are unique so the rules in disabledRules
+	// are removed from both planners.
+	ds, err := getOptionValues(plannerPkg.Object(), "disabledRules")
+	if err != nil {
+		return nil, nil, err
+	}
+	ls = append(ls, ds...)
+	ps = append(ps, ds...)
 	return plan.RemoveLogicalRules(ls...), plan.RemovePhysicalRules(ps...), nil
 }
 
+// DisabledRules returns the names of the rules disabled by the
+// options of the planner package, if it was imported into the scope.
+func DisabledRules(scope values.Scope) ([]string, error) {
+	pkg, ok := getPackageFromScope("planner", scope)
+	if !ok || pkg.Type().Nature() != semantic.Object {
+		return nil, nil
+	}
+	var rules []string
+	for _, name := range []string{"disableLogicalRules", "disablePhysicalRules", "disabledRules"} {
+		rs, err := getOptionValues(pkg.Object(), name)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rs {
+			if r != "" {
+				rules = append(rules, r)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// scriptFeatureFlags are the feature flags that a script may set.
+// They only change how a query is executed, not the limits that the
+// operator configured, so a script cannot set a flag like
+// queryConcurrencyLimit to use more resources.
+var scriptFeatureFlags = map[string]bool{
+	"narrowTransformationFilter":       true,
+	"aggregateTransformationTransport": true,
+	"groupTransformationGroup":         true,
+	"optimizeDerivative":               true,
+	"bytecodeCompiler":                 true,
+}
+
+// FeatureFlags returns the feature flag values set by the featureFlags
+// option of the planner package, if it was imported into the scope.
+// The option is a list of settings of the form key=value.
+// Only the boolean flags that change the behavior of the engine
+// may be set, and it returns an error for any other flag.
+func FeatureFlags(scope values.Scope) (map[string]interface{}, error) {
+	pkg, ok := getPackageFromScope("planner", scope)
+	if !ok || pkg.Type().Nature() != semantic.Object {
+		return nil, nil
+	}
+	settings, err := getOptionValues(pkg.Object(), "featureFlags")
+	if err != nil {
+		return nil, err
+	}
+	n := 0
+	for _, s := range settings {
+		if s != "" {
+			settings[n] = s
+			n++
+		}
+	}
+	flags, err := feature.ParseOverrides(settings[:n])
+	if err != nil {
+		return nil, err
+	}
+	for key := range flags {
+		if !scriptFeatureFlags[key] {
+			return nil, errors.Newf(codes.Invalid, "feature flag %q cannot be set by a script", key)
+		}
+	}
+	return flags, nil
+}
+
 func getOptionValues(pkg values.Object, optionName string) ([]string, error) {
 	value, ok := pkg.Get(optionName)
 	if !ok {
@@ -14,7 +14,9 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
 	fcsv "github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/dependencies/clock"
 	"github.com/influxdata/flux/dependencies/dependenciestest"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
@@ -488,6 +490,29 @@ csv.from(csv: "
 	}
 }
 
+func TestCompile_Clock(t *testing.T) {
+	src := `import "csv"
+			csv.from(csv: "foo,bar")
+				|> range(start: -1h)`
+
+	now := parser.MustParseTime("2018-10-10T00:00:00Z").Value
+	program, err := lang.Compile(src, runtime.Default, time.Time{})
+	if err != nil {
+		t.Fatalf("failed to compile script: %v", err)
+	}
+
+	// The query uses the time of the injected clock
+	// because the program was compiled without one.
+	ctx := executetest.NewTestExecuteDependencies().Inject(context.Background())
+	ctx = clock.Inject(ctx, clock.Fixed(now))
+	if _, err := program.Start(ctx, &memory.Allocator{}); err != nil {
+		t.Fatalf("failed to start program: %v", err)
+	}
+	if want, got := now, program.PlanSpec.Now; !want.Equal(got) {
+		t.Errorf("unexpected now -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
 func TestCompileOptions(t *testing.T) {
 	src := `import "csv"
 			csv.from(csv: "foo,bar")
@@ -625,6 +650,30 @@ import "planner"
 option planner.disablePhysicalRules = ["influxdata/influxdb.MergeRemoteFilterRule", "non_existent"]
 option planner.disableLogicalRules = ["removeCountRule", "non_existent"]
 
+from(bucket: "bkt") |> range(start: 0) |> filter(fn: (r) => r._value > 0) |> count()`},
+			want: plantest.CreatePlanSpec(&plantest.PlanSpec{
+				Nodes: []plan.Node{
+					&plan.PhysicalPlanNode{Spec: &influxdb.FromRemoteProcedureSpec{}},
+					&plan.PhysicalPlanNode{Spec: &universe.FilterProcedureSpec{}},
+					&plan.PhysicalPlanNode{Spec: &universe.CountProcedureSpec{}},
+					&plan.PhysicalPlanNode{Spec: &universe.YieldProcedureSpec{}},
+				},
+				Edges: [][2]int{
+					{0, 1},
+					{1, 2},
+					{2, 3},
+				},
+				Resources: flux.ResourceManagement{ConcurrencyQuota: 1, MemoryBytesQuota: math.MaxInt64},
+				Now:       nowFn(),
+			}),
+		},
+		{
+			name: "remove rules from both planners",
+			files: []string{`
+import "planner"
+
+option planner.disabledRules = ["influxdata/influxdb.MergeRemoteFilterRule", "removeCountRule"]
+
 from(bucket: "bkt") |> range(start: 0) |> filter(fn: (r) => r._value > 0) |> count()`},
 			want: plantest.CreatePlanSpec(&plantest.PlanSpec{
 				Nodes: []plan.Node{
@@ -852,6 +901,52 @@ option planner.disableLogicalRules = ["removeCountRule"]`},
 	}
 }
 
+func TestFeatureFlags(t *testing.T) {
+	_, scope, err := runtime.Eval(dependenciestest.Default().Inject(context.Background()), `
+import "planner"
+
+option planner.featureFlags = ["bytecodeCompiler", "optimizeDerivative=false"]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := lang.FeatureFlags(scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"bytecodeCompiler":   true,
+		"optimizeDerivative": false,
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected feature flags -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	for _, tt := range []struct {
+		name  string
+		flags string
+	}{
+		{name: "unknown flag", flags: `["notAFlag=true"]`},
+		{name: "limit", flags: `["queryConcurrencyLimit=1000"]`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, scope, err := runtime.Eval(dependenciestest.Default().Inject(context.Background()), `
+import "planner"
+
+option planner.featureFlags = `+tt.flags+`
+`)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := lang.FeatureFlags(scope); err == nil {
+				t.Error("expected an error setting the feature flag")
+			} else if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
+
 func TestQueryTracing(t *testing.T) {
 	// temporarily install a mock tracer to see which spans are created.
 	oldTracer := opentracing.GlobalTracer()
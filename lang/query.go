@@ -3,11 +3,17 @@ package lang
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/metrics"
 	"github.com/influxdata/flux/dependencies/testing"
+	"github.com/influxdata/flux/dependencies/tracing"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/table"
 	"github.com/influxdata/flux/memory"
 	"github.com/opentracing/opentracing-go"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // query implements the flux.Query interface.
@@ -20,6 +26,18 @@ type query struct {
 	cancel  func()
 	err     error
 	wg      sync.WaitGroup
+
+	// profilers are the profilers that were enabled
+	// when the query was started.
+	profilers []execute.Profiler
+
+	// tracingSpans are ended when the query is done,
+	// from the last span to the first.
+	tracingSpans []trace.Span
+
+	// metrics record the query when it is done, if set.
+	metrics *metrics.Metrics
+	start   time.Time
 }
 
 func (q *query) Results() <-chan flux.Result {
@@ -43,6 +61,12 @@ func (q *query) Done() {
 		// If the testing framework was configured, verify all expectations.
 		q.err = testing.Check(q.ctx)
 	}
+	for i := len(q.tracingSpans) - 1; i >= 0; i-- {
+		tracing.End(q.tracingSpans[i], q.err)
+	}
+	q.tracingSpans = nil
+	q.metrics.QueryFinished(q.err, time.Since(q.start), q.stats.MaxAllocated)
+	q.metrics = nil
 }
 
 func (q *query) Cancel() {
@@ -57,6 +81,22 @@ func (q *query) Statistics() flux.Statistics {
 	return q.stats
 }
 
+// ProfilerResults returns a single result named "_profiler"
+// with a table for each profiler that was enabled for the query.
+// It should only be called after Done because the profilers
+// stop collecting data when their results are read.
 func (q *query) ProfilerResults() (flux.ResultIterator, error) {
-	return nil, nil
+	if len(q.profilers) == 0 {
+		return nil, nil
+	}
+	tables := make([]flux.Table, 0, len(q.profilers))
+	for _, p := range q.profilers {
+		tbl, err := p.GetResult(q, q.alloc)
+		if err != nil {
+			return nil, err
+		}
+		tables = append(tables, tbl)
+	}
+	res := table.NewProfilerResult(tables...)
+	return flux.NewSliceResultIterator([]flux.Result{&res}), nil
 }
@@ -7,12 +7,15 @@ use std::{
     path::{self, Path},
 };
 
+use fluxcore::doc;
 use fluxcore::semantic::bootstrap;
 use fluxcore::semantic::env::Environment;
 use fluxcore::semantic::flatbuffers::types as fb;
 use fluxcore::semantic::sub::Substitutable;
+use fluxcore::semantic::types::PolyTypeMap;
+use fluxcore::semantic::Analyzer;
 
-use anyhow::{bail, Result};
+use anyhow::{bail, Context, Result};
 use walkdir::WalkDir;
 
 fn serialize<'a, T, S, F>(ty: T, f: F, path: &path::Path) -> Result<()>
@@ -75,6 +78,9 @@ fn main() -> Result<()> {
         }
     }
 
+    let path = dir.join("docs.json");
+    write_docs(stdlib_path, &prelude, &imports, &path)?;
+
     let path = dir.join("prelude.data");
     serialize(Environment::from(prelude), fb::build_env, &path)?;
 
@@ -83,3 +89,28 @@ fn main() -> Result<()> {
 
     Ok(())
 }
+
+// Generate the documentation of the stdlib packages from their doc comments
+// and write it as JSON so it can be looked up at runtime.
+// The doc comments are checked by fluxdoc lint, so the diagnostics are ignored here.
+fn write_docs(
+    stdlib_path: &Path,
+    prelude: &PolyTypeMap,
+    imports: &PolyTypeMap,
+    path: &path::Path,
+) -> Result<()> {
+    let mut analyzer =
+        Analyzer::new_with_defaults(Environment::from(prelude.clone()), imports.clone());
+    let mut docs = Vec::new();
+    for (pkgpath, ast_pkg) in bootstrap::parse_dir(stdlib_path)? {
+        let (pkgtypes, _) = analyzer
+            .analyze_ast(ast_pkg.clone())
+            .context(format!("analyzing \"{}\"", &pkgpath))?;
+        let (doc, _) = doc::parse_package_doc_comments(&ast_pkg, &pkgpath, &pkgtypes)
+            .context(format!("generating docs for \"{}\"", &pkgpath))?;
+        docs.push(doc);
+    }
+    let file = fs::File::create(path)?;
+    serde_json::to_writer(file, &docs)?;
+    Ok(())
+}
@@ -14,6 +14,7 @@ use anyhow::{self, bail, Result};
 use fluxcore::{
     parser::Parser,
     semantic::{
+        bootstrap::build_polytype,
         env::Environment,
         flatbuffers::semantic_generated::fbsemantic as fb,
         flatbuffers::types::{build_env, build_type},
@@ -30,6 +31,9 @@ pub use fluxcore::scanner;
 pub use fluxcore::semantic;
 pub use fluxcore::*;
 
+#[cfg(target_arch = "wasm32")]
+pub mod wasm;
+
 use crate::semantic::flatbuffers::semantic_generated::fbsemantic::MonoTypeHolderArgs;
 use std::ffi::*;
 use std::mem;
@@ -365,19 +369,7 @@ pub unsafe extern "C" fn flux_find_var_type(
     find_var_type(*ast_pkg, name).map_or_else(
         |e| Some(Box::from(e)),
         |t| {
-            let mut builder = flatbuffers::FlatBufferBuilder::new();
-            let (fb_mono_type, typ_type) = build_type(&mut builder, &t);
-            let fb_mono_type_holder = fb::MonoTypeHolder::create(
-                &mut builder,
-                &MonoTypeHolderArgs {
-                    typ_type,
-                    typ: Some(fb_mono_type),
-                },
-            );
-            builder.finish(fb_mono_type_holder, None);
-            let (mut vec, offset) = builder.collapse();
-            // Note, split_off() does a copy: https://github.com/influxdata/flux/issues/2194
-            let data = vec.split_off(offset);
+            let data = marshal_monotype(&t);
             let out_type = &mut *out_type; // Unsafe
             out_type.len = data.len();
             out_type.data = Box::into_raw(data.into_boxed_slice()) as *mut u8;
@@ -386,6 +378,23 @@ pub unsafe extern "C" fn flux_find_var_type(
     )
 }
 
+/// Serializes the given type to a MonoTypeHolder flatbuffer.
+fn marshal_monotype(t: &MonoType) -> Vec<u8> {
+    let mut builder = flatbuffers::FlatBufferBuilder::new();
+    let (fb_mono_type, typ_type) = build_type(&mut builder, t);
+    let fb_mono_type_holder = fb::MonoTypeHolder::create(
+        &mut builder,
+        &MonoTypeHolderArgs {
+            typ_type,
+            typ: Some(fb_mono_type),
+        },
+    );
+    builder.finish(fb_mono_type_holder, None);
+    let (mut vec, offset) = builder.collapse();
+    // Note, split_off() does a copy: https://github.com/influxdata/flux/issues/2194
+    vec.split_off(offset)
+}
+
 fn new_stateful_analyzer() -> Result<StatefulAnalyzer> {
     let env = match prelude() {
         Some(prelude) => Environment::new(prelude),
@@ -444,6 +453,30 @@ impl StatefulAnalyzer {
         self.env = env;
         Ok(sem_pkg)
     }
+
+    /// Analyze a package that is not part of the standard library and add its type
+    /// to the imports so packages analyzed later can import it with the given path.
+    fn analyze_package(
+        &mut self,
+        path: String,
+        ast_pkg: ast::Package,
+    ) -> Result<fluxcore::semantic::nodes::Package> {
+        let env = match prelude() {
+            Some(prelude) => Environment::new(prelude),
+            None => bail!("missing prelude"),
+        };
+        let mut sub = Substitution::default();
+        let mut analyzer = Analyzer::new_with_defaults(env, mem::take(&mut self.imports));
+        let result = analyzer.analyze_ast_with_substitution(ast_pkg, &mut sub);
+        // Restore the imports whether or not the analysis succeeded.
+        let (_, imports) = analyzer.drop();
+        self.imports = imports;
+
+        let (env, sem_pkg) = result?;
+        let typ = build_polytype(env.values, &mut sub)?;
+        self.imports.add(path, typ);
+        Ok(sem_pkg)
+    }
 }
 
 /// Create a new semantic analyzer.
@@ -489,6 +522,41 @@ pub unsafe extern "C" fn flux_analyze_with(
     None
 }
 
+/// flux_analyze_package_with analyzes a package that is not part of the standard library
+/// using the stateful analyzer. Packages analyzed with the analyzer afterwards can import
+/// the package with the given path.
+///
+/// # Safety
+///
+/// Ths function is unsafe because it dereferences raw pointers.
+#[no_mangle]
+#[allow(clippy::boxed_local)]
+pub unsafe extern "C" fn flux_analyze_package_with(
+    analyzer: *mut Result<StatefulAnalyzer>,
+    path: *const c_char,
+    ast_pkg: Box<ast::Package>,
+    out_sem_pkg: *mut Option<Box<semantic::nodes::Package>>,
+) -> Option<Box<ErrorHandle>> {
+    let ast_pkg = *ast_pkg;
+    let path = String::from_utf8_lossy(CStr::from_ptr(path).to_bytes()).into_owned();
+    let analyzer = match &mut *analyzer {
+        Ok(a) => a,
+        Err(err) => {
+            return Some(err.into());
+        }
+    };
+
+    let sem_pkg = Box::new(match analyzer.analyze_package(path, ast_pkg) {
+        Ok(sem_pkg) => sem_pkg,
+        Err(err) => {
+            return Some(err.into());
+        }
+    });
+
+    *out_sem_pkg = Some(sem_pkg);
+    None
+}
+
 /// analyze consumes the given AST package and returns a semantic package
 /// that has been type-inferred.  This function is aware of the standard library
 /// and prelude.
@@ -550,6 +618,31 @@ pub fn find_var_type(ast_pkg: ast::Package, var_name: String) -> Result<MonoType
 /// This function is unsafe because it dereferences a raw pointer.
 #[no_mangle]
 pub unsafe extern "C" fn flux_get_env_stdlib(buf: *mut flux_buffer_t) {
+    let data = marshal_env_stdlib();
+    let buf = &mut *buf; // Unsafe
+    buf.len = data.len();
+    buf.data = Box::into_raw(data.into_boxed_slice()) as *mut u8;
+}
+
+/// Returns the documentation of the stdlib packages, generated from their
+/// doc comments when the library is built, as a JSON list of package docs.
+pub fn stdlib_docs() -> &'static [u8] {
+    include_bytes!(concat!(env!("OUT_DIR"), "/docs.json"))
+}
+
+/// # Safety
+///
+/// This function is unsafe because it dereferences a raw pointer.
+#[no_mangle]
+pub unsafe extern "C" fn flux_get_stdlib_docs(buf: *mut flux_buffer_t) {
+    let data = stdlib_docs().to_vec();
+    let buf = &mut *buf; // Unsafe
+    buf.len = data.len();
+    buf.data = Box::into_raw(data.into_boxed_slice()) as *mut u8;
+}
+
+/// Serializes the types of the stdlib packages to a TypeEnvironment flatbuffer.
+fn marshal_env_stdlib() -> Vec<u8> {
     let env = imports().unwrap();
     let mut builder = flatbuffers::FlatBufferBuilder::new();
     let fb_type_env = build_env(&mut builder, env);
@@ -558,10 +651,7 @@ pub unsafe extern "C" fn flux_get_env_stdlib(buf: *mut flux_buffer_t) {
     let (mut vec, offset) = builder.collapse();
 
     // Note, split_off() does a copy: https://github.com/influxdata/flux/issues/2194
-    let data = vec.split_off(offset);
-    let buf = &mut *buf; // Unsafe
-    buf.len = data.len();
-    buf.data = Box::into_raw(data.into_boxed_slice()) as *mut u8;
+    vec.split_off(offset)
 }
 
 #[cfg(test)]
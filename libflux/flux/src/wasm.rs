@@ -0,0 +1,163 @@
+//! Bindings of the library for the wasm32 target.
+//!
+//! WebAssembly modules cannot use the C API, so the Go runtime compiled with
+//! GOOS=js GOARCH=wasm calls these bindings through JavaScript instead. They
+//! mirror the C API: packages are handles to Rust values and are serialized
+//! to flatbuffers when they cross into Go.
+use wasm_bindgen::prelude::*;
+
+use crate::{
+    analyze as analyze_pkg, ast, find_var_type as find_pkg_var_type, formatter, marshal_env_stdlib,
+    marshal_monotype, merge_packages, new_stateful_analyzer, parse as parse_pkg, semantic,
+    StatefulAnalyzer,
+};
+
+fn js_error(err: impl std::fmt::Display) -> JsValue {
+    JsValue::from_str(&err.to_string())
+}
+
+/// A parsed AST package.
+#[wasm_bindgen]
+pub struct AstPkg {
+    pkg: ast::Package,
+}
+
+#[wasm_bindgen]
+impl AstPkg {
+    /// Formats the package as Flux source.
+    pub fn format(&self) -> Result<String, JsValue> {
+        let mut out = String::new();
+        for file in &self.pkg.files {
+            out.push_str(&formatter::convert_to_string(file).map_err(js_error)?);
+        }
+        Ok(out)
+    }
+
+    /// Returns the first error in the package, if any.
+    pub fn error(&self) -> Option<String> {
+        ast::check::check(ast::walk::Node::Package(&self.pkg))
+            .err()
+            .map(|e| anyhow::Error::from(e).to_string())
+    }
+
+    /// Serializes the package to JSON.
+    #[wasm_bindgen(js_name = marshalJSON)]
+    pub fn marshal_json(&self) -> Result<Vec<u8>, JsValue> {
+        serde_json::to_vec(&self.pkg).map_err(js_error)
+    }
+
+    /// Serializes the package to a flatbuffer.
+    #[wasm_bindgen(js_name = marshalFB)]
+    pub fn marshal_fb(&self) -> Result<Vec<u8>, JsValue> {
+        let (mut vec, offset) = ast::flatbuffers::serialize(&self.pkg).map_err(js_error)?;
+        Ok(vec.split_off(offset))
+    }
+
+    /// Merges the files of the given package into this package.
+    pub fn merge(&mut self, in_pkg: &mut AstPkg) -> Result<(), JsValue> {
+        merge_packages(&mut self.pkg, &mut in_pkg.pkg).map_err(js_error)
+    }
+}
+
+/// Parses the Flux source of the file with the given name.
+#[wasm_bindgen]
+pub fn parse(fname: String, src: &str) -> AstPkg {
+    AstPkg {
+        pkg: parse_pkg(fname, src),
+    }
+}
+
+/// Parses an AST package serialized to JSON.
+#[wasm_bindgen(js_name = parseJSON)]
+pub fn parse_json(bs: &[u8]) -> Result<AstPkg, JsValue> {
+    serde_json::from_slice(bs)
+        .map(|pkg| AstPkg { pkg })
+        .map_err(js_error)
+}
+
+/// A semantic package.
+#[wasm_bindgen]
+pub struct SemanticPkg {
+    pkg: semantic::nodes::Package,
+}
+
+#[wasm_bindgen]
+impl SemanticPkg {
+    /// Serializes the package to a flatbuffer.
+    #[wasm_bindgen(js_name = marshalFB)]
+    pub fn marshal_fb(&self) -> Result<Vec<u8>, JsValue> {
+        let (mut vec, offset) =
+            semantic::flatbuffers::serialize_pkg(&self.pkg).map_err(js_error)?;
+        Ok(vec.split_off(offset))
+    }
+}
+
+/// Analyzes the AST package, consuming it.
+#[wasm_bindgen]
+pub fn analyze(ast_pkg: AstPkg) -> Result<SemanticPkg, JsValue> {
+    analyze_pkg(ast_pkg.pkg)
+        .map(|pkg| SemanticPkg { pkg })
+        .map_err(js_error)
+}
+
+/// Finds the type of the variable in the AST package, consuming it.
+/// The type is serialized to a MonoTypeHolder flatbuffer.
+#[wasm_bindgen(js_name = findVarType)]
+pub fn find_var_type(ast_pkg: AstPkg, var_name: String) -> Result<Vec<u8>, JsValue> {
+    find_pkg_var_type(ast_pkg.pkg, var_name)
+        .map(|t| marshal_monotype(&t))
+        .map_err(js_error)
+}
+
+/// Returns the types of the stdlib packages serialized to
+/// a TypeEnvironment flatbuffer.
+#[wasm_bindgen(js_name = envStdlib)]
+pub fn env_stdlib() -> Vec<u8> {
+    marshal_env_stdlib()
+}
+
+/// Returns the documentation of the stdlib packages as JSON.
+#[wasm_bindgen(js_name = stdlibDocs)]
+pub fn stdlib_docs() -> Vec<u8> {
+    crate::stdlib_docs().to_vec()
+}
+
+/// An analyzer that keeps the environment of the packages it has analyzed.
+#[wasm_bindgen]
+pub struct Analyzer {
+    analyzer: StatefulAnalyzer,
+}
+
+#[wasm_bindgen]
+impl Analyzer {
+    /// Creates an analyzer that is aware of the stdlib and prelude.
+    #[wasm_bindgen(constructor)]
+    pub fn new() -> Result<Analyzer, JsValue> {
+        new_stateful_analyzer()
+            .map(|analyzer| Analyzer { analyzer })
+            .map_err(js_error)
+    }
+
+    /// Analyzes the AST package, consuming it.
+    pub fn analyze(&mut self, ast_pkg: AstPkg) -> Result<SemanticPkg, JsValue> {
+        self.analyzer
+            .analyze(ast_pkg.pkg)
+            .map(|pkg| SemanticPkg { pkg })
+            .map_err(js_error)
+    }
+
+    /// Analyzes a package that is not part of the standard library,
+    /// consuming it. Packages analyzed afterwards can import it with
+    /// the given path.
+    #[wasm_bindgen(js_name = analyzePackage)]
+    pub fn analyze_package(
+        &mut self,
+        path: String,
+        ast_pkg: AstPkg,
+    ) -> Result<SemanticPkg, JsValue> {
+        self.analyzer
+            .analyze_package(path, ast_pkg.pkg)
+            .map(|pkg| SemanticPkg { pkg })
+            .map_err(js_error)
+    }
+}
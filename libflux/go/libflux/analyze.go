@@ -8,10 +8,8 @@ import (
 	"runtime"
 	"unsafe"
 
-	flatbuffers "github.com/google/flatbuffers/go"
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/internal/errors"
-	"github.com/influxdata/flux/internal/fbsemantic"
 	"github.com/influxdata/flux/semantic"
 )
 
@@ -96,12 +94,7 @@ func FindVarType(astPkg *ASTPkg, varName string) (semantic.MonoType, error) {
 		return semantic.MonoType{}, errors.New(codes.Invalid, str)
 	}
 	bytes := C.GoBytes(unsafe.Pointer(buf.data), C.int(buf.len))
-	monotype := fbsemantic.GetRootAsMonoTypeHolder(bytes, 0)
-	var table flatbuffers.Table
-	if !monotype.Typ(&table) {
-		return semantic.MonoType{}, errors.New(codes.Internal, "missing monotype")
-	}
-	return semantic.NewMonoType(table, monotype.TypType())
+	return unmarshalMonoType(bytes)
 }
 
 type Analyzer struct {
@@ -137,6 +130,30 @@ func (p *Analyzer) Analyze(astPkg *ASTPkg) (*SemanticPkg, error) {
 	return pkg, nil
 }
 
+// AnalyzePackage analyzes a package that is not part of the standard library.
+// Packages and snippets analyzed by the Analyzer afterwards can import
+// the package with the given path.
+func (p *Analyzer) AnalyzePackage(path string, astPkg *ASTPkg) (*SemanticPkg, error) {
+	var semPkg *C.struct_flux_semantic_pkg_t
+	defer func() {
+		// See Analyze for why this is needed.
+		astPkg.ptr = nil
+	}()
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+	if err := C.flux_analyze_package_with(p.ptr, cpath, astPkg.ptr, &semPkg); err != nil {
+		defer C.flux_free_error(err)
+		cstr := C.flux_error_str(err)
+		str := C.GoString(cstr)
+		return nil, errors.New(codes.Invalid, str)
+	}
+	runtime.KeepAlive(p)
+
+	pkg := &SemanticPkg{ptr: semPkg}
+	runtime.SetFinalizer(pkg, free)
+	return pkg, nil
+}
+
 // Free frees the memory allocated by Rust for the semantic graph.
 func (p *Analyzer) Free() {
 	if p.ptr != nil {
@@ -157,3 +174,12 @@ func EnvStdlib() []byte {
 	defer C.flux_free_bytes(buf.data)
 	return C.GoBytes(unsafe.Pointer(buf.data), C.int(buf.len))
 }
+
+// StdlibDocs returns the documentation of the stdlib packages,
+// generated from their doc comments, encoded as JSON.
+func StdlibDocs() []byte {
+	var buf C.struct_flux_buffer_t
+	C.flux_get_stdlib_docs(&buf)
+	defer C.flux_free_bytes(buf.data)
+	return C.GoBytes(unsafe.Pointer(buf.data), C.int(buf.len))
+}
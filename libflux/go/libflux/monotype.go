@@ -0,0 +1,19 @@
+package libflux
+
+import (
+	flatbuffers "github.com/google/flatbuffers/go"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/fbsemantic"
+	"github.com/influxdata/flux/semantic"
+)
+
+// unmarshalMonoType decodes a type from a MonoTypeHolder flatbuffer.
+func unmarshalMonoType(bs []byte) (semantic.MonoType, error) {
+	monotype := fbsemantic.GetRootAsMonoTypeHolder(bs, 0)
+	var table flatbuffers.Table
+	if !monotype.Typ(&table) {
+		return semantic.MonoType{}, errors.New(codes.Internal, "missing monotype")
+	}
+	return semantic.NewMonoType(table, monotype.TypType())
+}
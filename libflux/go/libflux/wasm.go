@@ -0,0 +1,290 @@
+//go:build js && wasm
+// +build js,wasm
+
+package libflux
+
+import (
+	"fmt"
+	"runtime"
+	"syscall/js"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/semantic"
+)
+
+// When compiled to WebAssembly, the flux library cannot be linked
+// with cgo. Instead, the library is compiled to WebAssembly with
+// wasm-pack and its module is loaded by the JavaScript host, which
+// must assign it to the libflux global before running the Go program.
+//
+//     globalThis.libflux = await import("@influxdata/flux");
+//
+// The functions in this file mirror the ones that use the C API.
+
+// module returns the libflux module of the JavaScript host.
+func module() js.Value {
+	m := js.Global().Get("libflux")
+	if m.IsUndefined() {
+		panic(errors.New(codes.Internal, "the libflux module has not been loaded"))
+	}
+	return m
+}
+
+// call calls the method of the JavaScript value and converts
+// the exception thrown for a Rust error into an error.
+func call(v js.Value, method string, args ...interface{}) (result js.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			jsErr, ok := r.(js.Error)
+			if !ok {
+				panic(r)
+			}
+			err = errors.New(codes.Invalid, jsErr.Value.String())
+		}
+	}()
+	return v.Call(method, args...), nil
+}
+
+func bytesToGo(v js.Value) []byte {
+	bs := make([]byte, v.Length())
+	js.CopyBytesToGo(bs, v)
+	return bs
+}
+
+func bytesToJS(bs []byte) js.Value {
+	v := js.Global().Get("Uint8Array").New(len(bs))
+	js.CopyBytesToJS(v, bs)
+	return v
+}
+
+// freeable indicates a resource that has memory
+// allocated to it outside of Go and must be freed.
+type freeable interface {
+	Free()
+}
+
+// free is a utility method for calling Free
+// on a resource.
+func free(f freeable) {
+	f.Free()
+}
+
+// ASTPkg is a parsed AST.
+type ASTPkg struct {
+	v js.Value
+}
+
+// ASTHandle makes sure that this type implements the flux.ASTHandle interface.
+func (p ASTPkg) ASTHandle() {}
+
+func (p ASTPkg) Format() (string, error) {
+	s, err := call(p.v, "format")
+	if err != nil {
+		return "", err
+	}
+	return s.String(), nil
+}
+
+// GetError will return the first error in the AST, if any
+func (p ASTPkg) GetError() error {
+	if s := p.v.Call("error"); !s.IsUndefined() && !s.IsNull() {
+		return errors.New(codes.Invalid, s.String())
+	}
+	return nil
+}
+
+func (p *ASTPkg) MarshalJSON() ([]byte, error) {
+	bs, err := call(p.v, "marshalJSON")
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Internal, "could not marshal AST to JSON")
+	}
+	return bytesToGo(bs), nil
+}
+
+func (p *ASTPkg) MarshalFB() ([]byte, error) {
+	bs, err := call(p.v, "marshalFB")
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Internal, "could not marshal AST to FlatBuffer")
+	}
+	return bytesToGo(bs), nil
+}
+
+func (p *ASTPkg) Free() {
+	if !p.v.IsUndefined() {
+		p.v.Call("free")
+	}
+	p.v = js.Undefined()
+	runtime.KeepAlive(p)
+}
+
+func (p *ASTPkg) String() string {
+	return fmt.Sprintf("%p", p)
+}
+
+// consume releases the ownership of the package,
+// which is passed by value to a function of the module.
+func (p *ASTPkg) consume() js.Value {
+	v := p.v
+	p.v = js.Undefined()
+	return v
+}
+
+func ParseString(src string) *ASTPkg {
+	return Parse("", src)
+}
+
+// Parse will take a filename and source string and return a parsed source file.
+func Parse(fname string, src string) *ASTPkg {
+	p := &ASTPkg{v: module().Call("parse", fname, src)}
+	runtime.SetFinalizer(p, free)
+	return p
+}
+
+// ParseJSON will take an AST formatted as JSON and return a
+// handle the Rust AST package.
+func ParseJSON(bs []byte) (*ASTPkg, error) {
+	v, err := call(module(), "parseJSON", bytesToJS(bs))
+	if err != nil {
+		return nil, err
+	}
+	p := &ASTPkg{v: v}
+	runtime.SetFinalizer(p, free)
+	return p, nil
+}
+
+// Merge packages merges the files of a given input package into a given output package.
+// This function borrows the input and output packages, but does not own them. Memory
+// must still be freed by the caller of this function.
+func MergePackages(outPkg *ASTPkg, inPkg *ASTPkg) error {
+	if inPkg == nil {
+		return nil
+	}
+	if _, err := call(outPkg.v, "merge", inPkg.v); err != nil {
+		return errors.Wrap(err, codes.Internal, "failed to merge packages")
+	}
+	return nil
+}
+
+// SemanticPkg is a handle to a semantic package of the Rust module.
+type SemanticPkg struct {
+	v js.Value
+}
+
+// MarshalFB serializes the given semantic package into a flatbuffer.
+func (p *SemanticPkg) MarshalFB() ([]byte, error) {
+	bs, err := call(p.v, "marshalFB")
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Internal, "could not marshal semantic graph to FlatBuffer")
+	}
+	return bytesToGo(bs), nil
+}
+
+// Free frees the memory allocated by Rust for the semantic graph.
+func (p *SemanticPkg) Free() {
+	if !p.v.IsUndefined() {
+		p.v.Call("free")
+	}
+	p.v = js.Undefined()
+	runtime.KeepAlive(p)
+}
+
+func newSemanticPkg(v js.Value) *SemanticPkg {
+	p := &SemanticPkg{v: v}
+	runtime.SetFinalizer(p, free)
+	return p
+}
+
+// Analyze performs type inference on the AST (taking into account
+// types from prelude and stdlib) and returns a SemanticPkg.
+//
+// Note that Analyze will consume the AST, even if there's an error in analysis.
+func Analyze(astPkg *ASTPkg) (*SemanticPkg, error) {
+	v, err := call(module(), "analyze", astPkg.consume())
+	if err != nil {
+		return nil, err
+	}
+	return newSemanticPkg(v), nil
+}
+
+func FindVarType(astPkg *ASTPkg, varName string) (semantic.MonoType, error) {
+	bs, err := call(module(), "findVarType", astPkg.consume(), varName)
+	if err != nil {
+		return semantic.MonoType{}, err
+	}
+	return unmarshalMonoType(bytesToGo(bs))
+}
+
+type Analyzer struct {
+	v   js.Value
+	err error
+}
+
+func NewAnalyzer() *Analyzer {
+	p := &Analyzer{}
+	func() {
+		// The constructor throws if the analyzer cannot be created.
+		// The error is reported by the calls to the analyzer,
+		// as it is by the C API.
+		defer func() {
+			if r := recover(); r != nil {
+				jsErr, ok := r.(js.Error)
+				if !ok {
+					panic(r)
+				}
+				p.err = errors.New(codes.Invalid, jsErr.Value.String())
+			}
+		}()
+		p.v = module().Get("Analyzer").New()
+	}()
+	runtime.SetFinalizer(p, free)
+	return p
+}
+
+func (p *Analyzer) Analyze(astPkg *ASTPkg) (*SemanticPkg, error) {
+	if p.err != nil {
+		astPkg.Free()
+		return nil, p.err
+	}
+	v, err := call(p.v, "analyze", astPkg.consume())
+	if err != nil {
+		return nil, err
+	}
+	return newSemanticPkg(v), nil
+}
+
+// AnalyzePackage analyzes a package that is not part of the standard library.
+// Packages and snippets analyzed by the Analyzer afterwards can import
+// the package with the given path.
+func (p *Analyzer) AnalyzePackage(path string, astPkg *ASTPkg) (*SemanticPkg, error) {
+	if p.err != nil {
+		astPkg.Free()
+		return nil, p.err
+	}
+	v, err := call(p.v, "analyzePackage", path, astPkg.consume())
+	if err != nil {
+		return nil, err
+	}
+	return newSemanticPkg(v), nil
+}
+
+// Free frees the memory allocated by Rust for the analyzer.
+func (p *Analyzer) Free() {
+	if !p.v.IsUndefined() {
+		p.v.Call("free")
+	}
+	p.v = js.Undefined()
+	runtime.KeepAlive(p)
+}
+
+// EnvStdlib returns the types of the stdlib packages
+// serialized to a TypeEnvironment flatbuffer.
+func EnvStdlib() []byte {
+	return bytesToGo(module().Call("envStdlib"))
+}
+
+// StdlibDocs returns the documentation of the stdlib packages,
+// generated from their doc comments, encoded as JSON.
+func StdlibDocs() []byte {
+	return bytesToGo(module().Call("stdlibDocs"))
+}
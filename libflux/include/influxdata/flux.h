@@ -87,6 +87,11 @@ struct flux_error_t *flux_ast_marshal_fb(struct flux_ast_pkg_t *, struct flux_bu
 // to it to use when performing lookups on the stdlib
 void flux_get_env_stdlib(struct flux_buffer_t *);
 
+// flux_get_stdlib_docs copies the documentation of the stdlib packages,
+// encoded as JSON, into the buffer. It is the caller's responsibility
+// to free the data.
+void flux_get_stdlib_docs(struct flux_buffer_t *);
+
 // flux_semantic_pkg_t represents a semantic graph package node, including all of its files
 // and their contents.
 struct flux_semantic_pkg_t;
@@ -106,6 +111,10 @@ void flux_free_stateful_analyzer(struct flux_stateful_analyzer_t *);
 // a semantic graph for that snippet.
 struct flux_error_t *flux_analyze_with(struct flux_stateful_analyzer_t *, struct flux_ast_pkg_t *, struct flux_semantic_pkg_t **);
 
+// flux_analyze_package_with analyzes a package that is not part of the standard library using the
+// flux_stateful_analyzer_t. Snippets and packages analyzed afterwards may import the package with the given path.
+struct flux_error_t *flux_analyze_package_with(struct flux_stateful_analyzer_t *, const char *, struct flux_ast_pkg_t *, struct flux_semantic_pkg_t **);
+
 // flux_analyze analyzes the given AST and will populate the second pointer argument with
 // a pointer to the resulting semantic graph.
 // It is the caller's responsibility to free the resulting semantic graph with a call to flux_free_semantic_pkg().
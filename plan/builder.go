@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/tracing"
 )
 
 // PlannerBuilder provides clients with an easy way to create planners.
@@ -37,7 +38,10 @@ type planner struct {
 	pp PhysicalPlanner
 }
 
-func (p *planner) Plan(ctx context.Context, fspec *flux.Spec) (*Spec, error) {
+func (p *planner) Plan(ctx context.Context, fspec *flux.Spec) (_ *Spec, err error) {
+	ctx, span := tracing.Start(ctx, "plan")
+	defer func() { tracing.End(span, err) }()
+
 	ip, err := p.lp.CreateInitialPlan(fspec)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,321 @@
+package plan
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/internal/feature"
+	pkgfeature "github.com/influxdata/flux/internal/pkg/feature"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/semantic"
+)
+
+// CacheKey computes a key for the physical plan of spec, so a plan can be
+// reused for a spec with the same key. The key covers the operations of
+// the spec and their time, the disabled rules and the feature flags.
+//
+// It returns false if the plan of spec cannot be reused, which is the case
+// when the rules are traced or an operation has a value that cannot be
+// described completely, such as a function that refers to the values of
+// its scope.
+func CacheKey(ctx context.Context, spec *flux.Spec) (key [sha256.Size]byte, ok bool) {
+	if traceFromContext(ctx) != nil {
+		return key, false
+	}
+	h := sha256.New()
+	for _, v := range []interface{}{spec.Operations, spec.Edges, spec.Resources} {
+		if !fingerprint(h, reflect.ValueOf(v)) {
+			return key, false
+		}
+	}
+	_, _ = fmt.Fprintf(h, "\x00now=%d", spec.Now.UnixNano())
+
+	disabled := make([]string, 0, len(disabledRules(ctx)))
+	for name, ok := range disabledRules(ctx) {
+		if ok {
+			disabled = append(disabled, name)
+		}
+	}
+	sort.Strings(disabled)
+	for _, name := range disabled {
+		_, _ = fmt.Fprintf(h, "\x00disabled=%s", name)
+	}
+	flagger := pkgfeature.GetFlagger(ctx)
+	for _, flag := range feature.Flags() {
+		_, _ = fmt.Fprintf(h, "\x00%s=%v", flag.Key(), flagger.FlagValue(ctx, flag))
+	}
+	copy(key[:], h.Sum(nil))
+	return key, true
+}
+
+var (
+	resolvedFunctionType = reflect.TypeOf(interpreter.ResolvedFunction{})
+	regexpType           = reflect.TypeOf((*regexp.Regexp)(nil))
+	textMarshalerType    = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	monoType             = reflect.TypeOf(semantic.MonoType{})
+	polyType             = reflect.TypeOf(semantic.PolyType{})
+)
+
+// fingerprint writes a description of v to w that is the same for two
+// values only if they are planned the same way. It returns false if v
+// has a value it cannot describe, such as a function value or a field
+// that is not exported.
+func fingerprint(w io.Writer, v reflect.Value) bool {
+	if !v.IsValid() {
+		_, _ = io.WriteString(w, "nil;")
+		return true
+	}
+	t := v.Type()
+	switch {
+	case t == resolvedFunctionType:
+		// The scope of the function is not described,
+		// so the function may only refer to its own parameters.
+		fn := v.Interface().(interpreter.ResolvedFunction).Fn
+		if fn == nil || !isClosed(fn) {
+			return false
+		}
+		return fingerprint(w, reflect.ValueOf(fn))
+	case t == regexpType:
+		if v.IsNil() {
+			_, _ = io.WriteString(w, "nil;")
+		} else {
+			_, _ = fmt.Fprintf(w, "regexp(%q);", v.Interface().(*regexp.Regexp).String())
+		}
+		return true
+	case t == monoType || t == polyType:
+		// The types of the semantic graph are inferred from the graph.
+		return true
+	case t.Implements(textMarshalerType):
+		if t.Kind() == reflect.Ptr && v.IsNil() {
+			_, _ = io.WriteString(w, "nil;")
+			return true
+		}
+		text, err := v.Interface().(encoding.TextMarshaler).MarshalText()
+		if err != nil {
+			return false
+		}
+		_, _ = fmt.Fprintf(w, "%s(%q);", t, text)
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		_, _ = fmt.Fprintf(w, "%v;", v)
+	case reflect.String:
+		_, _ = fmt.Fprintf(w, "%q;", v.String())
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			_, _ = io.WriteString(w, "nil;")
+			return true
+		}
+		_, _ = fmt.Fprintf(w, "%s{", v.Elem().Type())
+		if !fingerprint(w, v.Elem()) {
+			return false
+		}
+		_, _ = io.WriteString(w, "}")
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				if f.Type == monoType || f.Type == polyType {
+					continue
+				}
+				return false
+			}
+			_, _ = fmt.Fprintf(w, "%s:", f.Name)
+			if !fingerprint(w, v.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			_, _ = io.WriteString(w, "nil;")
+			return true
+		}
+		_, _ = fmt.Fprintf(w, "[%d]", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if !fingerprint(w, v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			_, _ = io.WriteString(w, "nil;")
+			return true
+		}
+		// The entries are described in the order of their keys.
+		type entry struct{ key, value []byte }
+		entries := make([]entry, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			var key, value bytesWriter
+			if !fingerprint(&key, iter.Key()) || !fingerprint(&value, iter.Value()) {
+				return false
+			}
+			entries = append(entries, entry{key: key, value: value})
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return string(entries[i].key) < string(entries[j].key)
+		})
+		_, _ = fmt.Fprintf(w, "map[%d]", len(entries))
+		for _, e := range entries {
+			_, _ = w.Write(e.key)
+			_, _ = w.Write(e.value)
+		}
+	default:
+		// Functions, channels and unsafe pointers cannot be described.
+		return false
+	}
+	return true
+}
+
+type bytesWriter []byte
+
+func (b *bytesWriter) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+// isClosed reports whether fn only refers to its parameters
+// and the variables it assigns.
+func isClosed(fn *semantic.FunctionExpression) bool {
+	v := &closedVisitor{
+		bound:  make(map[string]bool),
+		closed: true,
+	}
+	semantic.Walk(v, fn)
+	return v.closed
+}
+
+// closedVisitor finds the identifiers that are not bound in their scope.
+type closedVisitor struct {
+	bound  map[string]bool
+	closed bool
+	parent *closedVisitor
+}
+
+func (v *closedVisitor) nest() *closedVisitor {
+	return &closedVisitor{bound: make(map[string]bool), parent: v}
+}
+
+func (v *closedVisitor) isBound(name string) bool {
+	for ; v != nil; v = v.parent {
+		if v.bound[name] {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *closedVisitor) setOpen() {
+	for ; v != nil; v = v.parent {
+		v.closed = false
+	}
+}
+
+func (v *closedVisitor) Visit(node semantic.Node) semantic.Visitor {
+	switch n := node.(type) {
+	case *semantic.FunctionExpression:
+		// The defaults are evaluated in the enclosing scope.
+		if n.Defaults != nil {
+			semantic.Walk(v, n.Defaults)
+		}
+		scope := v.nest()
+		if n.Parameters != nil {
+			for _, p := range n.Parameters.List {
+				scope.bound[p.Key.Name] = true
+			}
+			if n.Parameters.Pipe != nil {
+				scope.bound[n.Parameters.Pipe.Name] = true
+			}
+		}
+		if n.Block != nil {
+			semantic.Walk(scope, n.Block)
+		}
+		return nil
+	case *semantic.Block:
+		return v.nest()
+	case *semantic.NativeVariableAssignment:
+		// The variable is bound after its value is evaluated.
+		semantic.Walk(v, n.Init)
+		v.bound[n.Identifier.Name] = true
+		return nil
+	case *semantic.IdentifierExpression:
+		if !v.isBound(n.Name) {
+			v.setOpen()
+		}
+	}
+	return v
+}
+
+func (v *closedVisitor) Done(semantic.Node) {}
+
+// Copy returns a copy of the plan with copies of its nodes and their
+// procedure specs, so the copy can be executed while the plan is reused.
+func (plan *Spec) Copy() *Spec {
+	copies := make(map[Node]Node)
+	var nodes []Node
+	_ = plan.BottomUpWalk(func(n Node) error {
+		var c Node
+		switch n := n.(type) {
+		case *PhysicalPlanNode:
+			nn := *n
+			nn.edges = edges{}
+			nn.Spec = n.Spec.Copy().(PhysicalProcedureSpec)
+			nn.RequiredAttrs = append([]PhysicalAttributes(nil), n.RequiredAttrs...)
+			nn.bounds = n.bounds.copy()
+			c = &nn
+		case *LogicalNode:
+			nn := *n
+			nn.edges = edges{}
+			nn.Spec = n.Spec.Copy()
+			nn.bounds = n.bounds.copy()
+			c = &nn
+		default:
+			c = n.ShallowCopy()
+		}
+		copies[n] = c
+		nodes = append(nodes, n)
+		return nil
+	})
+	mapNodes := func(ns []Node) []Node {
+		if ns == nil {
+			return nil
+		}
+		cs := make([]Node, len(ns))
+		for i, n := range ns {
+			cs[i] = copies[n]
+		}
+		return cs
+	}
+	for _, n := range nodes {
+		c := copies[n]
+		c.AddPredecessors(mapNodes(n.Predecessors())...)
+		c.AddSuccessors(mapNodes(n.Successors())...)
+	}
+
+	np := NewPlanSpec()
+	np.Resources = plan.Resources
+	np.Now = plan.Now
+	for root := range plan.Roots {
+		np.Roots[copies[root]] = struct{}{}
+	}
+	return np
+}
+
+func (b bounds) copy() bounds {
+	if b.value == nil {
+		return b
+	}
+	value := *b.value
+	return bounds{value: &value}
+}
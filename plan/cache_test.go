@@ -0,0 +1,104 @@
+package plan_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/plan"
+)
+
+func TestCacheKey(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	const script = `
+import "array"
+
+array.from(rows: [{_value: 1.0}])
+    |> filter(fn: (r) => r._value > 0.5)
+`
+	key := func(ctx context.Context, script string, now time.Time) ([32]byte, bool) {
+		t.Helper()
+		spec, err := compile(script, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return plan.CacheKey(ctx, spec)
+	}
+
+	ctx := context.Background()
+	want, ok := key(ctx, script, now)
+	if !ok {
+		t.Fatal("expected the plan to be cacheable")
+	}
+	if got, _ := key(ctx, script, now); got != want {
+		t.Error("expected the same key for the same script")
+	}
+	for name, tc := range map[string]struct {
+		ctx    context.Context
+		script string
+		now    time.Time
+	}{
+		"literal":  {ctx: ctx, script: `import "array" array.from(rows: [{_value: 1.0}]) |> filter(fn: (r) => r._value > 0.6)`, now: now},
+		"now":      {ctx: ctx, script: script, now: now.Add(time.Second)},
+		"disabled": {ctx: plan.WithDisabledRules(ctx, "RemoveTrivialFilterRule"), script: script, now: now},
+	} {
+		if got, ok := key(tc.ctx, tc.script, tc.now); !ok {
+			t.Errorf("%s: expected the plan to be cacheable", name)
+		} else if got == want {
+			t.Errorf("%s: expected a different key", name)
+		}
+	}
+
+	// The scope of a function is not part of the key.
+	if _, ok := key(ctx, `
+import "array"
+
+x = 0.5
+array.from(rows: [{_value: 1.0}])
+    |> filter(fn: (r) => r._value > x)
+`, now); ok {
+		t.Error("expected a function with a free variable not to be cacheable")
+	}
+	if _, ok := key(plan.WithTrace(ctx, &plan.Trace{}), script, now); ok {
+		t.Error("expected a traced plan not to be cacheable")
+	}
+}
+
+func TestSpec_Copy(t *testing.T) {
+	spec, err := compile(`
+import "array"
+
+array.from(rows: [{_value: 1.0}])
+    |> filter(fn: (r) => r._value > 0.5)
+    |> sum()
+`, time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ps, err := plan.PlannerBuilder{}.Build().Plan(context.Background(), spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cp := ps.Copy()
+	if want, got := fmt.Sprint(plan.Formatted(ps, plan.WithDetails())), fmt.Sprint(plan.Formatted(cp, plan.WithDetails())); want != got {
+		t.Fatalf("unexpected copy -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	nodes := make(map[plan.Node]bool)
+	_ = ps.BottomUpWalk(func(n plan.Node) error {
+		nodes[n] = true
+		return nil
+	})
+	_ = cp.BottomUpWalk(func(n plan.Node) error {
+		if nodes[n] {
+			t.Errorf("node %q is shared by the copy", n.ID())
+		}
+		for _, pred := range n.Predecessors() {
+			if nodes[pred] {
+				t.Errorf("predecessor %q of %q is shared by the copy", pred.ID(), n.ID())
+			}
+		}
+		return nil
+	})
+}
@@ -1,8 +1,32 @@
 package plan
 
+import "fmt"
+
+// Statistics are estimates of the data produced by a plan node.
 type Statistics struct {
-	Cardinality      int64
+	// Cardinality is the estimated number of rows.
+	Cardinality int64
+	// GroupCardinality is the estimated number of tables.
 	GroupCardinality int64
+	// Bytes is the estimated size of the data in bytes.
+	Bytes int64
+}
+
+// Add returns the sum of two statistics.
+func (s Statistics) Add(o Statistics) Statistics {
+	return Statistics{
+		Cardinality:      s.Cardinality + o.Cardinality,
+		GroupCardinality: s.GroupCardinality + o.GroupCardinality,
+		Bytes:            s.Bytes + o.Bytes,
+	}
+}
+
+// StatisticsProcedureSpec is implemented by the procedure specs of sources
+// that can estimate the data they produce before the query is executed.
+type StatisticsProcedureSpec interface {
+	// Statistics returns the estimates for the source
+	// and false if they are not known.
+	Statistics() (Statistics, bool)
 }
 
 // Cost stores various dimensions of the cost of a query plan
@@ -14,6 +38,12 @@ type Cost struct {
 	NET  int64
 }
 
+// Total returns the sum of the dimensions of the cost.
+// It is used to compare the cost of alternative plans.
+func (c Cost) Total() int64 {
+	return c.Disk + c.CPU + c.GPU + c.MEM + c.NET
+}
+
 // Add two cost structures together
 func Add(a Cost, b Cost) Cost {
 	return Cost{
@@ -25,9 +55,139 @@ func Add(a Cost, b Cost) Cost {
 	}
 }
 
+// DefaultCost is embedded by procedure specs that do not estimate their cost.
+// It assumes that every input row is processed once and passed through.
 type DefaultCost struct {
 }
 
 func (c DefaultCost) Cost(inStats []Statistics) (Cost, Statistics) {
-	return Cost{}, Statistics{}
+	var out Statistics
+	for _, s := range inStats {
+		out = out.Add(s)
+	}
+	return Cost{CPU: out.Cardinality}, out
+}
+
+// Estimate is the estimated cost of a node in a plan.
+type Estimate struct {
+	// Cost is the cost of the node and its predecessors.
+	Cost Cost
+	// Statistics describe the data produced by the node.
+	Statistics Statistics
+}
+
+// EstimateCosts estimates the cost of each physical node in the plan.
+// Sources start with the estimates from StatisticsProcedureSpec and
+// the other nodes compute their estimates from their predecessors.
+// A predecessor shared by more than one node is counted once for each of them.
+func EstimateCosts(p *Spec) map[Node]Estimate {
+	estimates := make(map[Node]Estimate)
+	_ = p.BottomUpWalk(func(n Node) error {
+		estimates[n] = estimateNode(n, n.ProcedureSpec(), n.Predecessors(), estimates)
+		return nil
+	})
+	return estimates
+}
+
+func estimateNode(n Node, spec ProcedureSpec, preds []Node, estimates map[Node]Estimate) Estimate {
+	var (
+		inCost  Cost
+		inStats = make([]Statistics, len(preds))
+	)
+	for i, pred := range preds {
+		e := estimates[pred]
+		inCost = Add(inCost, e.Cost)
+		inStats[i] = e.Statistics
+	}
+
+	var e Estimate
+	if ps, ok := spec.(PhysicalProcedureSpec); ok {
+		if _, ok := n.(*PhysicalPlanNode); ok {
+			e.Cost, e.Statistics = ps.Cost(inStats)
+		}
+	}
+	if ss, ok := spec.(StatisticsProcedureSpec); ok && len(preds) == 0 {
+		if stats, ok := ss.Statistics(); ok {
+			e.Statistics = stats
+		}
+	}
+	e.Cost = Add(e.Cost, inCost)
+	return e
+}
+
+// Alternative is another way to execute a plan node.
+type Alternative struct {
+	// Spec is the procedure spec that replaces the spec of the node.
+	Spec PhysicalProcedureSpec
+	// Inputs is the order of the predecessors for this alternative
+	// as indexes into the current predecessors of the node.
+	// If nil, the predecessors are not reordered.
+	Inputs []int
+}
+
+// AlternativesProcedureSpec is implemented by procedure specs that can be
+// executed in more than one way, such as with a different aggregation strategy
+// or with the inputs of a join in a different order.
+// The physical planner estimates the cost of each alternative and
+// replaces the node's spec with the cheapest one.
+type AlternativesProcedureSpec interface {
+	PhysicalProcedureSpec
+	Alternatives() []Alternative
+}
+
+// chooseAlternatives replaces the spec of each node that implements
+// AlternativesProcedureSpec with the alternative that has the lowest cost.
+// Alternatives are chosen from the sources to the roots so each choice
+// uses the estimates of the predecessors that were already chosen.
+func chooseAlternatives(p *Spec) error {
+	estimates := make(map[Node]Estimate)
+	return p.BottomUpWalk(func(n Node) error {
+		spec, ok := n.ProcedureSpec().(AlternativesProcedureSpec)
+		if !ok {
+			estimates[n] = estimateNode(n, n.ProcedureSpec(), n.Predecessors(), estimates)
+			return nil
+		}
+
+		preds := n.Predecessors()
+		best := Alternative{Spec: spec}
+		bestEstimate := estimateNode(n, spec, preds, estimates)
+		for _, alt := range spec.Alternatives() {
+			inputs := preds
+			if alt.Inputs != nil {
+				if len(alt.Inputs) != len(preds) {
+					return errInvalidAlternative(n)
+				}
+				inputs = make([]Node, len(preds))
+				for i, j := range alt.Inputs {
+					if j < 0 || j >= len(preds) {
+						return errInvalidAlternative(n)
+					}
+					inputs[i] = preds[j]
+				}
+			}
+			if e := estimateNode(n, alt.Spec, inputs, estimates); e.Cost.Total() < bestEstimate.Cost.Total() {
+				best, bestEstimate = alt, e
+			}
+		}
+
+		if best.Spec != spec {
+			if err := n.ReplaceSpec(best.Spec); err != nil {
+				return err
+			}
+		}
+		if best.Inputs != nil {
+			inputs := make([]Node, len(preds))
+			for i, j := range best.Inputs {
+				inputs[i] = preds[j]
+			}
+			n.ClearPredecessors()
+			n.AddPredecessors(inputs...)
+		}
+		estimates[n] = bestEstimate
+		return nil
+	})
+}
+
+func errInvalidAlternative(n Node) error {
+	return fmt.Errorf("invalid alternative for \"%v\": inputs do not match the predecessors", n.ID())
 }
@@ -0,0 +1,185 @@
+package plan_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+// statsSource is a source that knows how many rows it produces.
+type statsSource struct {
+	plan.DefaultCost
+	rows int64
+}
+
+func (s *statsSource) Kind() plan.ProcedureKind { return "statsSource" }
+func (s *statsSource) Copy() plan.ProcedureSpec { ns := *s; return &ns }
+
+func (s *statsSource) Statistics() (plan.Statistics, bool) {
+	return plan.Statistics{Cardinality: s.rows, GroupCardinality: 1}, true
+}
+
+// strategySpec has a cost per input row that depends on its strategy
+// and can be executed with its inputs in either order.
+type strategySpec struct {
+	strategy string
+	// swapped is set when the alternative with reversed inputs was chosen.
+	swapped bool
+}
+
+func (s *strategySpec) Kind() plan.ProcedureKind { return "strategy" }
+func (s *strategySpec) Copy() plan.ProcedureSpec { ns := *s; return &ns }
+
+func (s *strategySpec) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	var out plan.Statistics
+	for _, in := range inStats {
+		out = out.Add(in)
+	}
+	cost := out.Cardinality
+	switch s.strategy {
+	case "sort":
+		cost *= 10
+	case "hash":
+		cost *= 2
+	}
+	// Reading the second input costs more than reading the first.
+	if len(inStats) > 1 {
+		cost += inStats[1].Cardinality
+	}
+	return plan.Cost{CPU: cost}, out
+}
+
+func (s *strategySpec) Alternatives() []plan.Alternative {
+	return []plan.Alternative{
+		{Spec: &strategySpec{strategy: "hash"}},
+		{Spec: &strategySpec{strategy: "hash", swapped: true}, Inputs: []int{1, 0}},
+	}
+}
+
+func TestEstimateCosts(t *testing.T) {
+	source := plan.CreatePhysicalNode("source", &statsSource{rows: 10})
+	transform := plantest.CreatePhysicalMockNode("transform")
+	root := plantest.CreatePhysicalMockNode("root")
+	p := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{source, transform, root},
+		Edges: [][2]int{{0, 1}, {1, 2}},
+	})
+
+	stats := plan.Statistics{Cardinality: 10, GroupCardinality: 1}
+	want := map[plan.NodeID]plan.Estimate{
+		"source":    {Statistics: stats},
+		"transform": {Cost: plan.Cost{CPU: 10}, Statistics: stats},
+		"root":      {Cost: plan.Cost{CPU: 20}, Statistics: stats},
+	}
+	got := make(map[plan.NodeID]plan.Estimate)
+	for n, e := range plan.EstimateCosts(p) {
+		got[n.ID()] = e
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected estimates -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestDefaultCost(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		inStats   []plan.Statistics
+		wantCost  plan.Cost
+		wantStats plan.Statistics
+	}{
+		{name: "no inputs"},
+		{
+			name:      "one input",
+			inStats:   []plan.Statistics{{Cardinality: 10, GroupCardinality: 2, Bytes: 80}},
+			wantCost:  plan.Cost{CPU: 10},
+			wantStats: plan.Statistics{Cardinality: 10, GroupCardinality: 2, Bytes: 80},
+		},
+		{
+			name: "two inputs",
+			inStats: []plan.Statistics{
+				{Cardinality: 10, GroupCardinality: 2, Bytes: 80},
+				{Cardinality: 5, GroupCardinality: 1},
+			},
+			wantCost:  plan.Cost{CPU: 15},
+			wantStats: plan.Statistics{Cardinality: 15, GroupCardinality: 3, Bytes: 80},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, stats := plan.DefaultCost{}.Cost(tt.inStats)
+			if !cmp.Equal(tt.wantCost, cost) {
+				t.Errorf("unexpected cost -want/+got:\n%s", cmp.Diff(tt.wantCost, cost))
+			}
+			if !cmp.Equal(tt.wantStats, stats) {
+				t.Errorf("unexpected statistics -want/+got:\n%s", cmp.Diff(tt.wantStats, stats))
+			}
+		})
+	}
+}
+
+// Plans whose sources have no statistics have no estimates,
+// so the specs that use DefaultCost do not change how they are explained.
+func TestEstimateCosts_WithoutStatistics(t *testing.T) {
+	p := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("from", &influxdb.FromProcedureSpec{}),
+			plan.CreatePhysicalNode("filter", &universe.FilterProcedureSpec{}),
+			plan.CreatePhysicalNode("yield", &universe.YieldProcedureSpec{Name: "_result"}),
+		},
+		Edges: [][2]int{{0, 1}, {1, 2}},
+	})
+	for n, e := range plan.EstimateCosts(p) {
+		if e != (plan.Estimate{}) {
+			t.Errorf("unexpected estimate for %s: %+v", n.ID(), e)
+		}
+	}
+	for _, n := range plan.Explain(p).Nodes {
+		if n.Estimate != nil {
+			t.Errorf("unexpected explained estimate for %s: %+v", n.ID, *n.Estimate)
+		}
+	}
+}
+
+func TestPhysicalPlanner_Alternatives(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		left, right int64
+		wantSwapped bool
+	}{
+		{name: "keep order", left: 100, right: 10},
+		{name: "swap inputs", left: 10, right: 100, wantSwapped: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			left := plan.CreatePhysicalNode("left", &statsSource{rows: tt.left})
+			right := plan.CreatePhysicalNode("right", &statsSource{rows: tt.right})
+			join := plan.CreatePhysicalNode("join", &strategySpec{strategy: "sort"})
+			p := plantest.CreatePlanSpec(&plantest.PlanSpec{
+				Nodes: []plan.Node{left, right, join},
+				Edges: [][2]int{{0, 2}, {1, 2}},
+			})
+
+			planner := plan.NewPhysicalPlanner(plan.OnlyPhysicalRules(), plan.DisableValidation())
+			if _, err := planner.Plan(context.Background(), p); err != nil {
+				t.Fatal(err)
+			}
+
+			want := &strategySpec{strategy: "hash", swapped: tt.wantSwapped}
+			got := join.ProcedureSpec().(*strategySpec)
+			if *want != *got {
+				t.Errorf("unexpected alternative -want/+got:\n\t- %+v\n\t+ %+v", want, got)
+			}
+
+			wantFirst := plan.NodeID("left")
+			if tt.wantSwapped {
+				wantFirst = "right"
+			}
+			if got := join.Predecessors()[0].ID(); got != wantFirst {
+				t.Errorf("unexpected first input -want/+got:\n\t- %v\n\t+ %v", wantFirst, got)
+			}
+		})
+	}
+}
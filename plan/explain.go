@@ -0,0 +1,202 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Explanation is a description of a plan that can be rendered as text, JSON or DOT.
+// The nodes are listed from the sources to the roots in the same order
+// as BottomUpWalk so the output is the same each time a plan is explained.
+type Explanation struct {
+	// Physical reports whether every node in the plan is a physical node.
+	Physical bool `json:"physical"`
+	// Nodes describes each node in the plan.
+	Nodes []ExplainedNode `json:"nodes"`
+}
+
+// ExplainedNode describes a single node in a plan.
+type ExplainedNode struct {
+	ID   string `json:"id"`
+	Kind string `json:"kind"`
+	// Inputs lists the IDs of the predecessors of the node.
+	Inputs []string `json:"inputs,omitempty"`
+	// Bounds are the time bounds of the node, if known.
+	Bounds string `json:"bounds,omitempty"`
+	// Trigger is the kind of trigger used by a physical node.
+	Trigger string `json:"trigger,omitempty"`
+	// Details are the lines reported by a procedure spec that
+	// implements Detailer, such as a pushed down predicate.
+	Details []string `json:"details,omitempty"`
+	// Estimate is the estimated cost of a physical node,
+	// if anything is known about it.
+	Estimate *ExplainedEstimate `json:"estimate,omitempty"`
+}
+
+// ExplainedEstimate describes the estimates for a node from EstimateCosts.
+type ExplainedEstimate struct {
+	Rows   int64 `json:"rows"`
+	Tables int64 `json:"tables"`
+	Bytes  int64 `json:"bytes"`
+	// Cost is the total cost of the node and its predecessors.
+	Cost int64 `json:"cost"`
+}
+
+// Explain describes the plan so it can be written with WriteText, WriteJSON or WriteDOT.
+func Explain(p *Spec) *Explanation {
+	e := &Explanation{Physical: true}
+	estimates := EstimateCosts(p)
+	_ = p.BottomUpWalk(func(pn Node) error {
+		n := ExplainedNode{
+			ID:   string(pn.ID()),
+			Kind: string(pn.Kind()),
+		}
+		for _, pred := range pn.Predecessors() {
+			n.Inputs = append(n.Inputs, string(pred.ID()))
+		}
+		if b := pn.Bounds(); b != nil {
+			n.Bounds = fmt.Sprintf("[%v, %v)", b.Start, b.Stop)
+		}
+		if ppn, ok := pn.(*PhysicalPlanNode); ok {
+			if ppn.TriggerSpec != nil {
+				n.Trigger = ppn.TriggerSpec.Kind().String()
+			}
+			if est := estimates[pn]; est != (Estimate{}) {
+				n.Estimate = &ExplainedEstimate{
+					Rows:   est.Statistics.Cardinality,
+					Tables: est.Statistics.GroupCardinality,
+					Bytes:  est.Statistics.Bytes,
+					Cost:   est.Cost.Total(),
+				}
+			}
+		} else {
+			e.Physical = false
+		}
+		if d, ok := pn.ProcedureSpec().(Detailer); ok {
+			if details := strings.TrimSpace(d.PlanDetails()); details != "" {
+				n.Details = strings.Split(details, "\n")
+			}
+		}
+		e.Nodes = append(e.Nodes, n)
+		return nil
+	})
+	return e
+}
+
+// WriteText writes the explanation in a human readable form.
+func (e *Explanation) WriteText(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString(e.name() + "\n")
+	for _, n := range e.Nodes {
+		fmt.Fprintf(&sb, "\n%s: %s\n", n.ID, n.Kind)
+		if len(n.Inputs) > 0 {
+			fmt.Fprintf(&sb, "  inputs: %s\n", strings.Join(n.Inputs, ", "))
+		}
+		if n.Bounds != "" {
+			fmt.Fprintf(&sb, "  bounds: %s\n", n.Bounds)
+		}
+		if n.Trigger != "" {
+			fmt.Fprintf(&sb, "  trigger: %s\n", n.Trigger)
+		}
+		if n.Estimate != nil {
+			fmt.Fprintf(&sb, "  estimate: rows=%d tables=%d bytes=%d cost=%d\n",
+				n.Estimate.Rows, n.Estimate.Tables, n.Estimate.Bytes, n.Estimate.Cost)
+		}
+		if len(n.Details) > 0 {
+			sb.WriteString("  details:\n")
+			for _, line := range n.Details {
+				fmt.Fprintf(&sb, "    %s\n", line)
+			}
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteJSON writes the explanation as indented JSON.
+func (e *Explanation) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(e)
+}
+
+// WriteDOT writes the explanation as a Graphviz DOT graph.
+// Each node is labeled with its ID, kind and the other attributes
+// written by WriteText. The edges are labeled with the estimated
+// number of rows produced by the input when it is known.
+func (e *Explanation) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(e.name()))
+	sb.WriteString("  node [shape=box];\n")
+	e.writeDOTGraph(&sb, "  ", "")
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteDOTPlans writes the logical and the physical explanation of
+// the same query as a single Graphviz DOT graph. Each plan is written
+// as a cluster and its node IDs are prefixed with the name of the plan
+// so the nodes of the two plans do not collide.
+func WriteDOTPlans(w io.Writer, logical, physical *Explanation) error {
+	var sb strings.Builder
+	sb.WriteString("digraph \"plan\" {\n")
+	sb.WriteString("  node [shape=box];\n")
+	for i, e := range []*Explanation{logical, physical} {
+		name := e.name()
+		fmt.Fprintf(&sb, "  subgraph %s {\n", dotQuote(fmt.Sprintf("cluster_%d", i)))
+		fmt.Fprintf(&sb, "    label=%s;\n", dotQuote(name))
+		e.writeDOTGraph(&sb, "    ", name+"/")
+		sb.WriteString("  }\n")
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (e *Explanation) name() string {
+	if e.Physical {
+		return "physical plan"
+	}
+	return "logical plan"
+}
+
+// writeDOTGraph writes the nodes and edges of the explanation.
+// Each line is indented with indent and each node ID is prefixed with prefix.
+func (e *Explanation) writeDOTGraph(sb *strings.Builder, indent, prefix string) {
+	estimates := make(map[string]*ExplainedEstimate, len(e.Nodes))
+	for _, n := range e.Nodes {
+		estimates[n.ID] = n.Estimate
+		lines := []string{n.ID, n.Kind}
+		if n.Bounds != "" {
+			lines = append(lines, "bounds: "+n.Bounds)
+		}
+		if n.Trigger != "" {
+			lines = append(lines, "trigger: "+n.Trigger)
+		}
+		if n.Estimate != nil {
+			lines = append(lines, fmt.Sprintf("estimate: rows=%d tables=%d bytes=%d cost=%d",
+				n.Estimate.Rows, n.Estimate.Tables, n.Estimate.Bytes, n.Estimate.Cost))
+		}
+		lines = append(lines, n.Details...)
+		fmt.Fprintf(sb, "%s%s [label=%s];\n", indent, dotQuote(prefix+n.ID), dotQuote(strings.Join(lines, "\n")))
+	}
+	for _, n := range e.Nodes {
+		for _, in := range n.Inputs {
+			fmt.Fprintf(sb, "%s%s -> %s", indent, dotQuote(prefix+in), dotQuote(prefix+n.ID))
+			if est := estimates[in]; est != nil {
+				fmt.Fprintf(sb, " [label=%s]", dotQuote(fmt.Sprintf("rows=%d", est.Rows)))
+			}
+			sb.WriteString(";\n")
+		}
+	}
+}
+
+// dotQuote quotes s as a DOT string. Newlines
+// are written as line breaks in the label.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,266 @@
+package plan_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/andreyvit/diff"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
+	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/flux/values"
+)
+
+func TestExplain(t *testing.T) {
+	fromSpec := &influxdb.FromProcedureSpec{
+		Bucket: influxdb.NameOrID{Name: "my-bucket"},
+	}
+
+	// (r) => r._value > 5.0
+	filterSpec := &universe.FilterProcedureSpec{
+		Fn: interpreter.ResolvedFunction{
+			Fn: executetest.FunctionExpression(t, `(r) => r._value > 5.0`),
+		},
+	}
+
+	physicalPlan := func() *plantest.PlanSpec {
+		source := plantest.CreatePhysicalMockNode("source")
+		source.SetBounds(&plan.Bounds{
+			Start: values.Time(0),
+			Stop:  values.Time(10),
+		})
+		transform := plantest.CreatePhysicalMockNode("transform")
+		transform.TriggerSpec = plan.NarrowTransformationTriggerSpec{}
+		return &plantest.PlanSpec{
+			Nodes: []plan.Node{
+				source,
+				transform,
+				plantest.CreatePhysicalMockNode("yield0"),
+				plantest.CreatePhysicalMockNode("yield1"),
+			},
+			Edges: [][2]int{
+				{0, 1},
+				{1, 2},
+				{1, 3},
+			},
+		}
+	}
+
+	type testcase struct {
+		name     string
+		plan     *plantest.PlanSpec
+		wantText string
+		wantJSON string
+		wantDOT  string
+	}
+
+	tcs := []testcase{
+		{
+			name: "from |> filter",
+			plan: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreateLogicalNode("from", fromSpec),
+					plan.CreateLogicalNode("filter", filterSpec),
+				},
+				Edges: [][2]int{
+					{0, 1},
+				},
+			},
+			wantText: `logical plan
+
+from: from
+
+filter: filter
+  inputs: from
+  details:
+    r._value > 5.000000
+`,
+			wantJSON: `{
+  "physical": false,
+  "nodes": [
+    {
+      "id": "from",
+      "kind": "from"
+    },
+    {
+      "id": "filter",
+      "kind": "filter",
+      "inputs": [
+        "from"
+      ],
+      "details": [
+        "r._value > 5.000000"
+      ]
+    }
+  ]
+}
+`,
+			wantDOT: `digraph "logical plan" {
+  node [shape=box];
+  "from" [label="from\nfrom"];
+  "filter" [label="filter\nfilter\nr._value > 5.000000"];
+  "from" -> "filter";
+}
+`,
+		},
+		{
+			name: "physical with multiple roots",
+			plan: physicalPlan(),
+			wantText: `physical plan
+
+source: mock
+  bounds: [1970-01-01T00:00:00.000000000Z, 1970-01-01T00:00:00.000000010Z)
+
+transform: mock
+  inputs: source
+  trigger: narrowTransformation
+
+yield0: mock
+  inputs: transform
+
+yield1: mock
+  inputs: transform
+`,
+			wantJSON: `{
+  "physical": true,
+  "nodes": [
+    {
+      "id": "source",
+      "kind": "mock",
+      "bounds": "[1970-01-01T00:00:00.000000000Z, 1970-01-01T00:00:00.000000010Z)"
+    },
+    {
+      "id": "transform",
+      "kind": "mock",
+      "inputs": [
+        "source"
+      ],
+      "trigger": "narrowTransformation"
+    },
+    {
+      "id": "yield0",
+      "kind": "mock",
+      "inputs": [
+        "transform"
+      ]
+    },
+    {
+      "id": "yield1",
+      "kind": "mock",
+      "inputs": [
+        "transform"
+      ]
+    }
+  ]
+}
+`,
+			wantDOT: `digraph "physical plan" {
+  node [shape=box];
+  "source" [label="source\nmock\nbounds: [1970-01-01T00:00:00.000000000Z, 1970-01-01T00:00:00.000000010Z)"];
+  "transform" [label="transform\nmock\ntrigger: narrowTransformation"];
+  "yield0" [label="yield0\nmock"];
+  "yield1" [label="yield1\nmock"];
+  "source" -> "transform";
+  "transform" -> "yield0";
+  "transform" -> "yield1";
+}
+`,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			e := plan.Explain(plantest.CreatePlanSpec(tc.plan))
+
+			var buf bytes.Buffer
+			if err := e.WriteText(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); tc.wantText != got {
+				t.Errorf("unexpected text output: -want/+got:\n%v", diff.LineDiff(tc.wantText, got))
+			}
+
+			buf.Reset()
+			if err := e.WriteJSON(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); tc.wantJSON != got {
+				t.Errorf("unexpected json output: -want/+got:\n%v", diff.LineDiff(tc.wantJSON, got))
+			}
+
+			buf.Reset()
+			if err := e.WriteDOT(&buf); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); tc.wantDOT != got {
+				t.Errorf("unexpected dot output: -want/+got:\n%v", diff.LineDiff(tc.wantDOT, got))
+			}
+		})
+	}
+}
+
+func TestExplain_DOTEstimates(t *testing.T) {
+	p := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("source", &statsSource{rows: 10}),
+			plantest.CreatePhysicalMockNode("root"),
+		},
+		Edges: [][2]int{{0, 1}},
+	})
+
+	var buf bytes.Buffer
+	if err := plan.Explain(p).WriteDOT(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph "physical plan" {
+  node [shape=box];
+  "source" [label="source\nstatsSource\nestimate: rows=10 tables=1 bytes=0 cost=0"];
+  "root" [label="root\nmock\nestimate: rows=10 tables=1 bytes=0 cost=10"];
+  "source" -> "root" [label="rows=10"];
+}
+`
+	if got := buf.String(); want != got {
+		t.Errorf("unexpected dot output: -want/+got:\n%v", diff.LineDiff(want, got))
+	}
+}
+
+func TestWriteDOTPlans(t *testing.T) {
+	logical := plan.Explain(plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plantest.CreateLogicalMockNode("from"),
+			plantest.CreateLogicalMockNode("range"),
+		},
+		Edges: [][2]int{{0, 1}},
+	}))
+	physical := plan.Explain(plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plantest.CreatePhysicalMockNode("from"),
+		},
+	}))
+
+	var buf bytes.Buffer
+	if err := plan.WriteDOTPlans(&buf, logical, physical); err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph "plan" {
+  node [shape=box];
+  subgraph "cluster_0" {
+    label="logical plan";
+    "logical plan/from" [label="from\nmock"];
+    "logical plan/range" [label="range\nmock"];
+    "logical plan/from" -> "logical plan/range";
+  }
+  subgraph "cluster_1" {
+    label="physical plan";
+    "physical plan/from" [label="from\nmock"];
+  }
+}
+`
+	if got := buf.String(); want != got {
+		t.Errorf("unexpected dot output: -want/+got:\n%v", diff.LineDiff(want, got))
+	}
+}
@@ -0,0 +1,364 @@
+package plan
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative plan/planpb/fragment.proto
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan/planpb"
+	"google.golang.org/protobuf/proto"
+)
+
+// FragmentVersion is the version of the encoding written by MarshalFragment.
+// It is incremented when the encoding changes in a way that
+// older workers cannot read.
+const FragmentVersion = 2
+
+// FragmentSpecCodec converts the procedure specs of a kind
+// to and from the protobuf messages that encode them.
+type FragmentSpecCodec struct {
+	// NewMessage returns an empty message that a serialized
+	// procedure spec is decoded into.
+	NewMessage func() proto.Message
+	// ToMessage converts a procedure spec to its message.
+	// Every field that is needed to execute the procedure
+	// must be set in the message.
+	ToMessage func(spec PhysicalProcedureSpec) (proto.Message, error)
+	// FromMessage converts a decoded message back to a procedure spec.
+	FromMessage func(ctx context.Context, m proto.Message) (PhysicalProcedureSpec, error)
+}
+
+var fragmentSpecs = make(map[ProcedureKind]FragmentSpecCodec)
+
+// RegisterFragmentSpec registers a procedure kind that can be sent to
+// remote workers in a plan fragment.
+// The call panics if the kind is not unique.
+func RegisterFragmentSpec(k ProcedureKind, c FragmentSpecCodec) {
+	if _, ok := fragmentSpecs[k]; ok {
+		panic(errors.Newf(codes.Internal, "duplicate registration for fragment spec kind %v", k))
+	}
+	fragmentSpecs[k] = c
+}
+
+func init() {
+	RegisterFragmentSpec(generatedYieldKind, FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.YieldSpec) },
+		ToMessage: func(spec PhysicalProcedureSpec) (proto.Message, error) {
+			return &planpb.YieldSpec{Name: spec.(*GeneratedYieldProcedureSpec).Name}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (PhysicalProcedureSpec, error) {
+			return &GeneratedYieldProcedureSpec{Name: m.(*planpb.YieldSpec).Name}, nil
+		},
+	})
+	RegisterFragmentSpec(RemoteKind, FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.RemoteSpec) },
+		ToMessage: func(spec PhysicalProcedureSpec) (proto.Message, error) {
+			s := spec.(*RemoteProcedureSpec)
+			return &planpb.RemoteSpec{Fragment: s.Fragment, Result: s.Result}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (PhysicalProcedureSpec, error) {
+			pb := m.(*planpb.RemoteSpec)
+			return &RemoteProcedureSpec{Fragment: pb.Fragment, Result: pb.Result}, nil
+		},
+	})
+}
+
+// Capabilities describe the plan fragments a worker can execute.
+// A coordinator asks each worker for its capabilities and only
+// sends the worker fragments that it supports.
+type Capabilities struct {
+	// Version is the latest FragmentVersion the worker can read.
+	Version int
+	// Kinds are the procedure kinds the worker can execute.
+	Kinds []ProcedureKind
+}
+
+// LocalCapabilities returns the capabilities of this process.
+func LocalCapabilities() Capabilities {
+	kinds := make([]ProcedureKind, 0, len(fragmentSpecs))
+	for k := range fragmentSpecs {
+		kinds = append(kinds, k)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i] < kinds[j]
+	})
+	return Capabilities{
+		Version: FragmentVersion,
+		Kinds:   kinds,
+	}
+}
+
+// Supports reports whether the worker can execute nodes of the kind.
+func (c Capabilities) Supports(k ProcedureKind) bool {
+	for _, kind := range c.Kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalCapabilities encodes the capabilities of a worker
+// so they can be sent to a coordinator.
+func MarshalCapabilities(c Capabilities) ([]byte, error) {
+	pb := &planpb.Capabilities{
+		Version: int32(c.Version),
+		Kinds:   make([]string, len(c.Kinds)),
+	}
+	for i, k := range c.Kinds {
+		pb.Kinds[i] = string(k)
+	}
+	return proto.Marshal(pb)
+}
+
+// UnmarshalCapabilities decodes capabilities encoded by MarshalCapabilities.
+func UnmarshalCapabilities(data []byte) (Capabilities, error) {
+	var pb planpb.Capabilities
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return Capabilities{}, errors.Wrap(err, codes.Invalid, "failed to decode worker capabilities")
+	}
+	c := Capabilities{
+		Version: int(pb.Version),
+		Kinds:   make([]ProcedureKind, len(pb.Kinds)),
+	}
+	for i, k := range pb.Kinds {
+		c.Kinds[i] = ProcedureKind(k)
+	}
+	return c, nil
+}
+
+// MarshalFragment encodes a physical plan, or a part of one, so it can be
+// sent to a worker with the given capabilities. It returns an error if
+// the worker cannot read this version of the encoding or cannot execute
+// one of the nodes in the plan.
+//
+// The time bounds and triggers of the nodes are not encoded.
+// They are computed again by UnmarshalFragment.
+func MarshalFragment(p *Spec, caps Capabilities) ([]byte, error) {
+	if caps.Version < FragmentVersion {
+		return nil, errors.Newf(codes.Unimplemented, "worker does not support plan fragment version %d", FragmentVersion)
+	}
+
+	f := &planpb.Fragment{
+		Version: FragmentVersion,
+		Resources: &planpb.Resources{
+			Priority:         int64(p.Resources.Priority),
+			ConcurrencyQuota: int64(p.Resources.ConcurrencyQuota),
+			MemoryBytesQuota: p.Resources.MemoryBytesQuota,
+		},
+	}
+	if !p.Now.IsZero() {
+		f.Now = p.Now.UnixNano()
+	}
+	indexes := make(map[Node]int32)
+	if err := p.BottomUpWalk(func(n Node) error {
+		if _, ok := n.(*PhysicalPlanNode); !ok {
+			return errors.Newf(codes.Invalid, "cannot send logical node %q to a worker", n.ID())
+		}
+		c, ok := fragmentSpecs[n.Kind()]
+		if !ok {
+			return errors.Newf(codes.Unimplemented, "node %q of kind %q cannot be sent to a worker", n.ID(), n.Kind())
+		}
+		if !caps.Supports(n.Kind()) {
+			return errors.Newf(codes.Unimplemented, "worker does not support node %q of kind %q", n.ID(), n.Kind())
+		}
+
+		m, err := c.ToMessage(n.ProcedureSpec().(PhysicalProcedureSpec))
+		if err != nil {
+			return errors.Wrapf(err, codes.Inherit, "failed to encode node %q", n.ID())
+		}
+		spec, err := proto.Marshal(m)
+		if err != nil {
+			return errors.Wrapf(err, codes.Internal, "failed to encode node %q", n.ID())
+		}
+		fn := &planpb.Node{
+			Id:   string(n.ID()),
+			Kind: string(n.Kind()),
+			Spec: spec,
+		}
+		for _, pred := range n.Predecessors() {
+			fn.Inputs = append(fn.Inputs, indexes[pred])
+		}
+		indexes[n] = int32(len(f.Nodes))
+		f.Nodes = append(f.Nodes, fn)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return proto.Marshal(f)
+}
+
+// UnmarshalFragment decodes a plan encoded by MarshalFragment.
+// It returns an error with the code Unimplemented if the plan was
+// encoded with a newer version or contains a node this process
+// cannot execute, so a coordinator can send it to another worker.
+func UnmarshalFragment(ctx context.Context, data []byte) (*Spec, error) {
+	var f planpb.Fragment
+	if err := proto.Unmarshal(data, &f); err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "failed to decode plan fragment")
+	}
+	if f.Version > FragmentVersion {
+		return nil, errors.Newf(codes.Unimplemented, "unsupported plan fragment version %d", f.Version)
+	}
+
+	nodes := make([]Node, len(f.Nodes))
+	for i, fn := range f.Nodes {
+		c, ok := fragmentSpecs[ProcedureKind(fn.Kind)]
+		if !ok {
+			return nil, errors.Newf(codes.Unimplemented, "unsupported node %q of kind %q", fn.Id, fn.Kind)
+		}
+		m := c.NewMessage()
+		if err := proto.Unmarshal(fn.Spec, m); err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "failed to decode node %q", fn.Id)
+		}
+		spec, err := c.FromMessage(ctx, m)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Inherit, "failed to decode node %q", fn.Id)
+		}
+		nodes[i] = CreatePhysicalNode(NodeID(fn.Id), spec)
+		for _, in := range fn.Inputs {
+			// Nodes are listed after their inputs.
+			if in < 0 || int(in) >= i {
+				return nil, errors.Newf(codes.Invalid, "invalid input for node %q", fn.Id)
+			}
+			nodes[i].AddPredecessors(nodes[in])
+			nodes[in].AddSuccessors(nodes[i])
+		}
+	}
+
+	p := NewPlanSpec()
+	if f.Now != 0 {
+		p.Now = time.Unix(0, f.Now).UTC()
+	}
+	if r := f.Resources; r != nil {
+		p.Resources = flux.ResourceManagement{
+			Priority:         flux.Priority(r.Priority),
+			ConcurrencyQuota: int(r.ConcurrencyQuota),
+			MemoryBytesQuota: r.MemoryBytesQuota,
+		}
+	}
+	for _, n := range nodes {
+		if len(n.Successors()) == 0 {
+			p.Roots[n] = struct{}{}
+		}
+	}
+	if err := p.BottomUpWalk(ComputeBounds); err != nil {
+		return nil, err
+	}
+	if err := p.TopDownWalk(SetTriggerSpec); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// RemoteKind is the kind of the nodes that read the result
+// of a fragment executed by a worker.
+const RemoteKind = "remote"
+
+// RemoteProcedureSpec reads the result of a fragment that was split
+// from a plan by SplitFragment. The executor asks a worker to execute
+// the fragment and passes on the tables of the result.
+type RemoteProcedureSpec struct {
+	DefaultCost
+	// Fragment is the fragment encoded by MarshalFragment.
+	Fragment []byte
+	// Result is the name of the yield in the fragment.
+	Result string
+}
+
+func (s *RemoteProcedureSpec) Kind() ProcedureKind {
+	return RemoteKind
+}
+
+func (s *RemoteProcedureSpec) Copy() ProcedureSpec {
+	ns := *s
+	ns.Fragment = append([]byte(nil), s.Fragment...)
+	return &ns
+}
+
+// SplitFragment moves a node and the nodes it reads from out of a
+// physical plan into a fragment that is sent to a worker with the given
+// capabilities. The node is replaced by a remote node that reads the
+// result of the fragment, so the rest of the plan executes locally on
+// the tables the worker returns.
+//
+// It returns the remote node. It returns an error if the node is a root
+// of the plan, if one of the nodes it reads from is read by a node that
+// is not in the fragment, or if the fragment cannot be sent to the worker.
+func SplitFragment(p *Spec, n Node, caps Capabilities) (Node, error) {
+	if _, ok := p.Roots[n]; ok {
+		return nil, errors.Newf(codes.Invalid, "cannot split root node %q from the plan", n.ID())
+	}
+	pn, ok := n.(*PhysicalPlanNode)
+	if !ok {
+		return nil, errors.Newf(codes.Invalid, "cannot send logical node %q to a worker", n.ID())
+	}
+
+	// Every node the fragment reads from must only be read by
+	// nodes in the fragment, or it would be executed twice.
+	inFragment := map[Node]bool{n: true}
+	var visit func(n Node)
+	visit = func(n Node) {
+		for _, pred := range n.Predecessors() {
+			if !inFragment[pred] {
+				inFragment[pred] = true
+				visit(pred)
+			}
+		}
+	}
+	visit(n)
+	for m := range inFragment {
+		if m == n {
+			continue
+		}
+		for _, succ := range m.Successors() {
+			if !inFragment[succ] {
+				return nil, errors.Newf(codes.Invalid, "cannot split node %q from the plan because node %q is also read by %q", n.ID(), m.ID(), succ.ID())
+			}
+		}
+	}
+
+	result := string(n.ID())
+	yield := CreatePhysicalNode("generated_yield", &GeneratedYieldProcedureSpec{Name: result})
+	yield.AddPredecessors(n)
+	fragment := NewPlanSpec()
+	fragment.Now = p.Now
+	fragment.Resources = p.Resources
+	fragment.Roots[yield] = struct{}{}
+
+	// The node is only connected to the yield while the fragment is
+	// encoded, so the plan is left as it was if encoding fails.
+	successors := append([]Node(nil), n.Successors()...)
+	n.ClearSuccessors()
+	n.AddSuccessors(yield)
+	data, err := MarshalFragment(fragment, caps)
+	n.ClearSuccessors()
+	n.AddSuccessors(successors...)
+	if err != nil {
+		return nil, err
+	}
+
+	remote := CreatePhysicalNode("remote_"+n.ID(), &RemoteProcedureSpec{
+		Fragment: data,
+		Result:   result,
+	})
+	remote.SetBounds(pn.Bounds())
+	remote.TriggerSpec = pn.TriggerSpec
+	remote.OutputAttrs = pn.OutputAttrs
+	for _, succ := range successors {
+		remote.AddSuccessors(succ)
+		preds := succ.Predecessors()
+		for i, pred := range preds {
+			if pred == n {
+				preds[i] = remote
+			}
+		}
+	}
+	n.ClearSuccessors()
+	return remote, nil
+}
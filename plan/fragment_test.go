@@ -0,0 +1,290 @@
+package plan_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/planpb"
+	"github.com/influxdata/flux/plan/plantest"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	fragmentSourceKind    = "fragmentSource"
+	fragmentTransformKind = "fragmentTransform"
+)
+
+type fragmentSource struct {
+	plan.DefaultCost
+	Bucket string
+}
+
+func (s *fragmentSource) Kind() plan.ProcedureKind { return fragmentSourceKind }
+func (s *fragmentSource) Copy() plan.ProcedureSpec { ns := *s; return &ns }
+
+type fragmentTransform struct {
+	plan.DefaultCost
+	Columns []string
+}
+
+func (s *fragmentTransform) Kind() plan.ProcedureKind { return fragmentTransformKind }
+func (s *fragmentTransform) Copy() plan.ProcedureSpec {
+	ns := *s
+	ns.Columns = append([]string(nil), s.Columns...)
+	return &ns
+}
+
+func init() {
+	plan.RegisterFragmentSpec(fragmentSourceKind, plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(wrapperspb.StringValue) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			return wrapperspb.String(spec.(*fragmentSource).Bucket), nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			return &fragmentSource{Bucket: m.(*wrapperspb.StringValue).Value}, nil
+		},
+	})
+	plan.RegisterFragmentSpec(fragmentTransformKind, plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.SortSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			return &planpb.SortSpec{Columns: spec.(*fragmentTransform).Columns}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			return &fragmentTransform{Columns: m.(*planpb.SortSpec).Columns}, nil
+		},
+	})
+}
+
+func fragmentPlan() *plan.Spec {
+	p := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("a", &fragmentSource{Bucket: "a"}),
+			plan.CreatePhysicalNode("b", &fragmentSource{Bucket: "b"}),
+			plan.CreatePhysicalNode("union", &fragmentTransform{Columns: []string{"host"}}),
+		},
+		Edges: [][2]int{{0, 2}, {1, 2}},
+	})
+	p.Now = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	p.Resources = flux.ResourceManagement{ConcurrencyQuota: 2, MemoryBytesQuota: 1024}
+	return p
+}
+
+func TestFragment_RoundTrip(t *testing.T) {
+	data, err := plan.MarshalFragment(fragmentPlan(), plan.LocalCapabilities())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := plan.UnmarshalFragment(context.Background(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := plantest.ComparePlans(fragmentPlan(), got, plantest.ComparePhysicalPlanNodes); err != nil {
+		t.Error(err)
+	}
+	_ = got.TopDownWalk(func(n plan.Node) error {
+		if n.(*plan.PhysicalPlanNode).TriggerSpec == nil {
+			t.Errorf("trigger spec not set on %q", n.ID())
+		}
+		return nil
+	})
+}
+
+func TestFragment_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		marshal func() error
+	}{
+		{
+			name: "old worker",
+			marshal: func() error {
+				caps := plan.LocalCapabilities()
+				caps.Version = plan.FragmentVersion - 1
+				_, err := plan.MarshalFragment(fragmentPlan(), caps)
+				return err
+			},
+		},
+		{
+			name: "unsupported by worker",
+			marshal: func() error {
+				caps := plan.Capabilities{
+					Version: plan.FragmentVersion,
+					Kinds:   []plan.ProcedureKind{fragmentSourceKind},
+				}
+				_, err := plan.MarshalFragment(fragmentPlan(), caps)
+				return err
+			},
+		},
+		{
+			name: "unregistered kind",
+			marshal: func() error {
+				p := plantest.CreatePlanSpec(&plantest.PlanSpec{
+					Nodes: []plan.Node{plantest.CreatePhysicalMockNode("mock")},
+				})
+				_, err := plan.MarshalFragment(p, plan.LocalCapabilities())
+				return err
+			},
+		},
+		{
+			name: "newer version",
+			marshal: func() error {
+				data, _ := proto.Marshal(&planpb.Fragment{Version: 1000})
+				_, err := plan.UnmarshalFragment(context.Background(), data)
+				return err
+			},
+		},
+		{
+			name: "unknown node",
+			marshal: func() error {
+				data, _ := proto.Marshal(&planpb.Fragment{
+					Version: plan.FragmentVersion,
+					Nodes:   []*planpb.Node{{Id: "x", Kind: "unknown"}},
+				})
+				_, err := plan.UnmarshalFragment(context.Background(), data)
+				return err
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.marshal()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if want, got := codes.Unimplemented, flux.ErrorCode(err); want != got {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
+
+func TestCapabilities_RoundTrip(t *testing.T) {
+	want := plan.LocalCapabilities()
+	data, err := plan.MarshalCapabilities(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := plan.UnmarshalCapabilities(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected capabilities -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestSplitFragment(t *testing.T) {
+	p := fragmentPlan()
+	sink := plan.CreatePhysicalNode("sink", &fragmentTransform{})
+	var union plan.Node
+	for root := range p.Roots {
+		union = root
+	}
+	union.AddSuccessors(sink)
+	sink.AddPredecessors(union)
+	p.Replace(union, sink)
+
+	remote, err := plan.SplitFragment(p, union, plan.LocalCapabilities())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The plan reads the result of the fragment.
+	want := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plan.CreatePhysicalNode("remote_union", remote.ProcedureSpec().(plan.PhysicalProcedureSpec)),
+			plan.CreatePhysicalNode("sink", &fragmentTransform{}),
+		},
+		Edges:     [][2]int{{0, 1}},
+		Resources: p.Resources,
+		Now:       p.Now,
+	})
+	if err := plantest.ComparePlans(want, p, plantest.ComparePhysicalPlanNodes); err != nil {
+		t.Error(err)
+	}
+
+	// The fragment is the part of the plan that was split
+	// with a yield of the result.
+	spec := remote.ProcedureSpec().(*plan.RemoteProcedureSpec)
+	if want, got := "union", spec.Result; want != got {
+		t.Errorf("unexpected result name -want/+got:\n\t- %q\n\t+ %q", want, got)
+	}
+	fragment, err := plan.UnmarshalFragment(context.Background(), spec.Fragment)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = fragmentPlan()
+	var root plan.Node
+	for r := range want.Roots {
+		root = r
+	}
+	yield := plan.CreatePhysicalNode("generated_yield", &plan.GeneratedYieldProcedureSpec{Name: "union"})
+	root.AddSuccessors(yield)
+	yield.AddPredecessors(root)
+	want.Replace(root, yield)
+	if err := plantest.ComparePlans(want, fragment, plantest.ComparePhysicalPlanNodes); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSplitFragment_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		spec *plantest.PlanSpec
+		node int
+		code codes.Code
+	}{
+		{
+			name: "root",
+			spec: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("a", &fragmentSource{Bucket: "a"}),
+				},
+			},
+			node: 0,
+			code: codes.Invalid,
+		},
+		{
+			name: "shared input",
+			spec: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("a", &fragmentSource{Bucket: "a"}),
+					plan.CreatePhysicalNode("b", &fragmentTransform{}),
+					plan.CreatePhysicalNode("c", &fragmentTransform{}),
+				},
+				Edges: [][2]int{{0, 1}, {0, 2}},
+			},
+			node: 1,
+			code: codes.Invalid,
+		},
+		{
+			name: "unsupported by worker",
+			spec: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plantest.CreatePhysicalMockNode("mock"),
+					plan.CreatePhysicalNode("b", &fragmentTransform{}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			node: 0,
+			code: codes.Unimplemented,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			n := tt.spec.Nodes[tt.node]
+			p := plantest.CreatePlanSpec(tt.spec)
+			_, err := plan.SplitFragment(p, n, plan.LocalCapabilities())
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if want, got := tt.code, flux.ErrorCode(err); want != got {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
@@ -28,6 +28,9 @@ func (p *heuristicPlanner) addRules(rules ...Rule) {
 			p.rules[root] = append(ruleSlice, rule)
 		}
 	}
+	for _, rules := range p.rules {
+		sortRules(rules)
+	}
 }
 
 func (p *heuristicPlanner) removeRules(ruleNames ...string) {
@@ -36,6 +39,10 @@ func (p *heuristicPlanner) removeRules(ruleNames ...string) {
 	}
 }
 
+func (p *heuristicPlanner) isDisabled(ctx context.Context, rule Rule) bool {
+	return p.disabledRules[rule.Name()] || disabledRules(ctx)[rule.Name()]
+}
+
 func (p *heuristicPlanner) clearRules() {
 	p.rules = make(map[ProcedureKind][]Rule)
 }
@@ -46,40 +53,67 @@ func (p *heuristicPlanner) matchRules(ctx context.Context, node Node) (Node, boo
 	anyChanged := false
 
 	for _, rule := range p.rules[AnyKind] {
-		if p.disabledRules[rule.Name()] {
-			continue
-		}
-		if rule.Pattern().Match(node) {
-			newNode, changed, err := rule.Rewrite(ctx, node)
-			if err != nil {
-				return nil, false, err
-			} else if changed {
-				testing.MarkInvokedPlannerRule(ctx, rule.Name())
-				anyChanged = true
-			}
-			node = newNode
+		newNode, changed, err := p.applyRule(ctx, rule, node)
+		if err != nil {
+			return nil, false, err
 		}
+		anyChanged = anyChanged || changed
+		node = newNode
 	}
 
 	for _, rule := range p.rules[node.Kind()] {
-		if p.disabledRules[rule.Name()] {
-			continue
-		}
-		if rule.Pattern().Match(node) {
-			newNode, changed, err := rule.Rewrite(ctx, node)
-			if err != nil {
-				return nil, false, err
-			} else if changed {
-				testing.MarkInvokedPlannerRule(ctx, rule.Name())
-				anyChanged = true
-			}
-			node = newNode
+		newNode, changed, err := p.applyRule(ctx, rule, node)
+		if err != nil {
+			return nil, false, err
 		}
+		anyChanged = anyChanged || changed
+		node = newNode
 	}
 
 	return node, anyChanged, nil
 }
 
+// applyRule rewrites the node with the rule if the rule
+// is enabled and its pattern matches the node.
+// The evaluation is recorded if the context has a Trace.
+func (p *heuristicPlanner) applyRule(ctx context.Context, rule Rule, node Node) (Node, bool, error) {
+	if p.isDisabled(ctx, rule) {
+		return node, false, nil
+	}
+
+	trace := traceFromContext(ctx)
+	rt := RuleTrace{
+		Rule: rule.Name(),
+		Node: string(node.ID()),
+	}
+	if !rule.Pattern().Match(node) {
+		if trace != nil {
+			trace.add(rt)
+		}
+		return node, false, nil
+	}
+
+	rt.Matched = true
+	if trace != nil {
+		rt.Before = formatSnippet(node)
+	}
+	newNode, changed, err := rule.Rewrite(ctx, node)
+	if err != nil {
+		return nil, false, err
+	}
+	if changed {
+		testing.MarkInvokedPlannerRule(ctx, rule.Name())
+		rt.Fired = true
+		if trace != nil {
+			rt.After = formatSnippet(newNode)
+		}
+	}
+	if trace != nil {
+		trace.add(rt)
+	}
+	return newNode, changed, nil
+}
+
 // Plan is a fixed-point query planning algorithm.
 // It traverses the DAG depth-first, attempting to apply rewrite rules at each node.
 // Traversal is repeated until a pass over the DAG results in no changes with the given rule set.
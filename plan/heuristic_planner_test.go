@@ -141,3 +141,105 @@ func TestPlanTraversal(t *testing.T) {
 		})
 	}
 }
+
+// recordingRule records its name each time it is attempted on a node.
+type recordingRule struct {
+	name string
+	seen *[]string
+}
+
+func (r recordingRule) Name() string {
+	return r.name
+}
+
+func (r recordingRule) Pattern() plan.Pattern {
+	return plan.Any()
+}
+
+func (r recordingRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	*r.seen = append(*r.seen, r.name)
+	return node, false, nil
+}
+
+func TestRuleOrdering(t *testing.T) {
+	testCases := []struct {
+		name  string
+		ctx   func(ctx context.Context) context.Context
+		rules func(seen *[]string) []plan.Rule
+		want  []string
+	}{
+		{
+			name: "same priority",
+			rules: func(seen *[]string) []plan.Rule {
+				return []plan.Rule{
+					recordingRule{name: "a", seen: seen},
+					recordingRule{name: "b", seen: seen},
+				}
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name: "higher priority first",
+			rules: func(seen *[]string) []plan.Rule {
+				return []plan.Rule{
+					recordingRule{name: "a", seen: seen},
+					plan.WithPriority(recordingRule{name: "b", seen: seen}, 10),
+					plan.WithPriority(recordingRule{name: "c", seen: seen}, -10),
+				}
+			},
+			want: []string{"b", "a", "c"},
+		},
+		{
+			name: "disabled in context",
+			ctx: func(ctx context.Context) context.Context {
+				return plan.WithDisabledRules(ctx, "a", "c")
+			},
+			rules: func(seen *[]string) []plan.Rule {
+				return []plan.Rule{
+					recordingRule{name: "a", seen: seen},
+					recordingRule{name: "b", seen: seen},
+					recordingRule{name: "c", seen: seen},
+				}
+			},
+			want: []string{"b"},
+		},
+		{
+			name: "enabled in context",
+			ctx: func(ctx context.Context) context.Context {
+				ctx = plan.WithDisabledRules(ctx, "a", "c")
+				return plan.WithEnabledRules(ctx, "c")
+			},
+			rules: func(seen *[]string) []plan.Rule {
+				return []plan.Rule{
+					recordingRule{name: "a", seen: seen},
+					recordingRule{name: "b", seen: seen},
+					recordingRule{name: "c", seen: seen},
+				}
+			},
+			want: []string{"b", "c"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.ctx != nil {
+				ctx = tc.ctx(ctx)
+			}
+
+			var seen []string
+			planner := plan.NewLogicalPlanner(plan.OnlyLogicalRules(tc.rules(&seen)...))
+			spec := plantest.CreatePlanSpec(&plantest.PlanSpec{
+				Nodes: []plan.Node{plantest.CreateLogicalMockNode("0")},
+			})
+			if _, err := planner.Plan(ctx, spec); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !cmp.Equal(tc.want, seen) {
+				t.Errorf("unexpected rule order -want/+got:\n%s", cmp.Diff(tc.want, seen))
+			}
+		})
+	}
+}
@@ -30,12 +30,7 @@ func NewLogicalPlanner(options ...LogicalOption) LogicalPlanner {
 		heuristicPlanner: newHeuristicPlanner(),
 	}
 
-	rules := make([]Rule, len(ruleNameToLogicalRule))
-	i := 0
-	for _, v := range ruleNameToLogicalRule {
-		rules[i] = v
-		i++
-	}
+	rules := registeredRules(ruleNameToLogicalRule)
 
 	thePlanner.addRules(rules...)
 
@@ -23,12 +23,7 @@ func NewPhysicalPlanner(options ...PhysicalOption) PhysicalPlanner {
 		defaultMemoryLimit: math.MaxInt64,
 	}
 
-	rules := make([]Rule, len(ruleNameToPhysicalRule))
-	i := 0
-	for _, v := range ruleNameToPhysicalRule {
-		rules[i] = v
-		i++
-	}
+	rules := registeredRules(ruleNameToPhysicalRule)
 
 	pp.addRules(rules...)
 
@@ -49,6 +44,11 @@ func (pp *physicalPlanner) Plan(ctx context.Context, spec *Spec) (*Spec, error)
 		return nil, err
 	}
 
+	// Use the cheapest alternative for nodes that can be executed in more than one way
+	if err := chooseAlternatives(transformedSpec); err != nil {
+		return nil, err
+	}
+
 	// Compute time bounds for nodes in the plan
 	if err := transformedSpec.BottomUpWalk(ComputeBounds); err != nil {
 		return nil, err
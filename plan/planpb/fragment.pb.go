@@ -0,0 +1,1494 @@
+// The messages that encode the plan fragments that a coordinator
+// sends to its workers, and the procedure specs in them.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.27.1
+// 	protoc        (unknown)
+// source: plan/planpb/fragment.proto
+
+package planpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Fragment is a physical plan, or a part of one.
+type Fragment struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Version is the version of the encoding of the fragment.
+	Version int32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Now is the time of the query in nanoseconds since the Unix epoch.
+	Now       int64      `protobuf:"varint,2,opt,name=now,proto3" json:"now,omitempty"`
+	Resources *Resources `protobuf:"bytes,3,opt,name=resources,proto3" json:"resources,omitempty"`
+	// Nodes are listed from the sources to the roots.
+	Nodes []*Node `protobuf:"bytes,4,rep,name=nodes,proto3" json:"nodes,omitempty"`
+}
+
+func (x *Fragment) Reset() {
+	*x = Fragment{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Fragment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fragment) ProtoMessage() {}
+
+func (x *Fragment) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fragment.ProtoReflect.Descriptor instead.
+func (*Fragment) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Fragment) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Fragment) GetNow() int64 {
+	if x != nil {
+		return x.Now
+	}
+	return 0
+}
+
+func (x *Fragment) GetResources() *Resources {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
+func (x *Fragment) GetNodes() []*Node {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+// Resources are the resources the query may use.
+type Resources struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Priority         int64 `protobuf:"varint,1,opt,name=priority,proto3" json:"priority,omitempty"`
+	ConcurrencyQuota int64 `protobuf:"varint,2,opt,name=concurrency_quota,json=concurrencyQuota,proto3" json:"concurrency_quota,omitempty"`
+	MemoryBytesQuota int64 `protobuf:"varint,3,opt,name=memory_bytes_quota,json=memoryBytesQuota,proto3" json:"memory_bytes_quota,omitempty"`
+}
+
+func (x *Resources) Reset() {
+	*x = Resources{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Resources) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resources) ProtoMessage() {}
+
+func (x *Resources) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resources.ProtoReflect.Descriptor instead.
+func (*Resources) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Resources) GetPriority() int64 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Resources) GetConcurrencyQuota() int64 {
+	if x != nil {
+		return x.ConcurrencyQuota
+	}
+	return 0
+}
+
+func (x *Resources) GetMemoryBytesQuota() int64 {
+	if x != nil {
+		return x.MemoryBytesQuota
+	}
+	return 0
+}
+
+// Node is a node of a fragment.
+type Node struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	// Spec is the message that encodes the procedure spec of the kind.
+	Spec []byte `protobuf:"bytes,3,opt,name=spec,proto3" json:"spec,omitempty"`
+	// Inputs are the indexes of the predecessors of the node.
+	Inputs []int32 `protobuf:"varint,4,rep,packed,name=inputs,proto3" json:"inputs,omitempty"`
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Node) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Node) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Node) GetSpec() []byte {
+	if x != nil {
+		return x.Spec
+	}
+	return nil
+}
+
+func (x *Node) GetInputs() []int32 {
+	if x != nil {
+		return x.Inputs
+	}
+	return nil
+}
+
+// Capabilities describe the fragments a worker can execute.
+type Capabilities struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Version is the latest version of the encoding the worker can read.
+	Version int32 `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	// Kinds are the procedure kinds the worker can execute.
+	Kinds []string `protobuf:"bytes,2,rep,name=kinds,proto3" json:"kinds,omitempty"`
+}
+
+func (x *Capabilities) Reset() {
+	*x = Capabilities{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Capabilities) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Capabilities) ProtoMessage() {}
+
+func (x *Capabilities) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Capabilities.ProtoReflect.Descriptor instead.
+func (*Capabilities) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Capabilities) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Capabilities) GetKinds() []string {
+	if x != nil {
+		return x.Kinds
+	}
+	return nil
+}
+
+// Function is a function of a procedure spec, such as the predicate of filter.
+type Function struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Source is a Flux program whose only expression is the function,
+	// after the imports of the packages that the function uses.
+	Source string `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+}
+
+func (x *Function) Reset() {
+	*x = Function{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Function) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Function) ProtoMessage() {}
+
+func (x *Function) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Function.ProtoReflect.Descriptor instead.
+func (*Function) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Function) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+// Time is a point in time that may be relative to the time of the query.
+type Time struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	IsRelative bool `protobuf:"varint,1,opt,name=is_relative,json=isRelative,proto3" json:"is_relative,omitempty"`
+	// Relative is a duration in nanoseconds.
+	Relative int64 `protobuf:"varint,2,opt,name=relative,proto3" json:"relative,omitempty"`
+	// Absolute is in nanoseconds since the Unix epoch.
+	Absolute int64 `protobuf:"varint,3,opt,name=absolute,proto3" json:"absolute,omitempty"`
+}
+
+func (x *Time) Reset() {
+	*x = Time{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Time) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Time) ProtoMessage() {}
+
+func (x *Time) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Time.ProtoReflect.Descriptor instead.
+func (*Time) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Time) GetIsRelative() bool {
+	if x != nil {
+		return x.IsRelative
+	}
+	return false
+}
+
+func (x *Time) GetRelative() int64 {
+	if x != nil {
+		return x.Relative
+	}
+	return 0
+}
+
+func (x *Time) GetAbsolute() int64 {
+	if x != nil {
+		return x.Absolute
+	}
+	return 0
+}
+
+// Bounds are the bounds of a range.
+type Bounds struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Start *Time `protobuf:"bytes,1,opt,name=start,proto3" json:"start,omitempty"`
+	Stop  *Time `protobuf:"bytes,2,opt,name=stop,proto3" json:"stop,omitempty"`
+	Now   int64 `protobuf:"varint,3,opt,name=now,proto3" json:"now,omitempty"`
+}
+
+func (x *Bounds) Reset() {
+	*x = Bounds{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Bounds) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Bounds) ProtoMessage() {}
+
+func (x *Bounds) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Bounds.ProtoReflect.Descriptor instead.
+func (*Bounds) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Bounds) GetStart() *Time {
+	if x != nil {
+		return x.Start
+	}
+	return nil
+}
+
+func (x *Bounds) GetStop() *Time {
+	if x != nil {
+		return x.Stop
+	}
+	return nil
+}
+
+func (x *Bounds) GetNow() int64 {
+	if x != nil {
+		return x.Now
+	}
+	return 0
+}
+
+// RemoteSpec reads the result of a fragment executed by a worker.
+type RemoteSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Fragment []byte `protobuf:"bytes,1,opt,name=fragment,proto3" json:"fragment,omitempty"`
+	Result   string `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+}
+
+func (x *RemoteSpec) Reset() {
+	*x = RemoteSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RemoteSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoteSpec) ProtoMessage() {}
+
+func (x *RemoteSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoteSpec.ProtoReflect.Descriptor instead.
+func (*RemoteSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RemoteSpec) GetFragment() []byte {
+	if x != nil {
+		return x.Fragment
+	}
+	return nil
+}
+
+func (x *RemoteSpec) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+type YieldSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+}
+
+func (x *YieldSpec) Reset() {
+	*x = YieldSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *YieldSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*YieldSpec) ProtoMessage() {}
+
+func (x *YieldSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use YieldSpec.ProtoReflect.Descriptor instead.
+func (*YieldSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *YieldSpec) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type FromCSVSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Csv     string   `protobuf:"bytes,1,opt,name=csv,proto3" json:"csv,omitempty"`
+	File    string   `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	Mode    string   `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	Columns []string `protobuf:"bytes,4,rep,name=columns,proto3" json:"columns,omitempty"`
+	// Projected reports whether only the columns are decoded.
+	Projected bool `protobuf:"varint,5,opt,name=projected,proto3" json:"projected,omitempty"`
+}
+
+func (x *FromCSVSpec) Reset() {
+	*x = FromCSVSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FromCSVSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FromCSVSpec) ProtoMessage() {}
+
+func (x *FromCSVSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FromCSVSpec.ProtoReflect.Descriptor instead.
+func (*FromCSVSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *FromCSVSpec) GetCsv() string {
+	if x != nil {
+		return x.Csv
+	}
+	return ""
+}
+
+func (x *FromCSVSpec) GetFile() string {
+	if x != nil {
+		return x.File
+	}
+	return ""
+}
+
+func (x *FromCSVSpec) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *FromCSVSpec) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *FromCSVSpec) GetProjected() bool {
+	if x != nil {
+		return x.Projected
+	}
+	return false
+}
+
+type RangeSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Bounds      *Bounds `protobuf:"bytes,1,opt,name=bounds,proto3" json:"bounds,omitempty"`
+	TimeColumn  string  `protobuf:"bytes,2,opt,name=time_column,json=timeColumn,proto3" json:"time_column,omitempty"`
+	StartColumn string  `protobuf:"bytes,3,opt,name=start_column,json=startColumn,proto3" json:"start_column,omitempty"`
+	StopColumn  string  `protobuf:"bytes,4,opt,name=stop_column,json=stopColumn,proto3" json:"stop_column,omitempty"`
+}
+
+func (x *RangeSpec) Reset() {
+	*x = RangeSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RangeSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RangeSpec) ProtoMessage() {}
+
+func (x *RangeSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RangeSpec.ProtoReflect.Descriptor instead.
+func (*RangeSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *RangeSpec) GetBounds() *Bounds {
+	if x != nil {
+		return x.Bounds
+	}
+	return nil
+}
+
+func (x *RangeSpec) GetTimeColumn() string {
+	if x != nil {
+		return x.TimeColumn
+	}
+	return ""
+}
+
+func (x *RangeSpec) GetStartColumn() string {
+	if x != nil {
+		return x.StartColumn
+	}
+	return ""
+}
+
+func (x *RangeSpec) GetStopColumn() string {
+	if x != nil {
+		return x.StopColumn
+	}
+	return ""
+}
+
+type LimitSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	N      int64 `protobuf:"varint,1,opt,name=n,proto3" json:"n,omitempty"`
+	Offset int64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+}
+
+func (x *LimitSpec) Reset() {
+	*x = LimitSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LimitSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LimitSpec) ProtoMessage() {}
+
+func (x *LimitSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LimitSpec.ProtoReflect.Descriptor instead.
+func (*LimitSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *LimitSpec) GetN() int64 {
+	if x != nil {
+		return x.N
+	}
+	return 0
+}
+
+func (x *LimitSpec) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type SortSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Columns []string `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+	Desc    bool     `protobuf:"varint,2,opt,name=desc,proto3" json:"desc,omitempty"`
+}
+
+func (x *SortSpec) Reset() {
+	*x = SortSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SortSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SortSpec) ProtoMessage() {}
+
+func (x *SortSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SortSpec.ProtoReflect.Descriptor instead.
+func (*SortSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SortSpec) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+func (x *SortSpec) GetDesc() bool {
+	if x != nil {
+		return x.Desc
+	}
+	return false
+}
+
+type FilterSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Fn              *Function `protobuf:"bytes,1,opt,name=fn,proto3" json:"fn,omitempty"`
+	KeepEmptyTables bool      `protobuf:"varint,2,opt,name=keep_empty_tables,json=keepEmptyTables,proto3" json:"keep_empty_tables,omitempty"`
+}
+
+func (x *FilterSpec) Reset() {
+	*x = FilterSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *FilterSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FilterSpec) ProtoMessage() {}
+
+func (x *FilterSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FilterSpec.ProtoReflect.Descriptor instead.
+func (*FilterSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FilterSpec) GetFn() *Function {
+	if x != nil {
+		return x.Fn
+	}
+	return nil
+}
+
+func (x *FilterSpec) GetKeepEmptyTables() bool {
+	if x != nil {
+		return x.KeepEmptyTables
+	}
+	return false
+}
+
+type MapSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Fn       *Function `protobuf:"bytes,1,opt,name=fn,proto3" json:"fn,omitempty"`
+	MergeKey bool      `protobuf:"varint,2,opt,name=merge_key,json=mergeKey,proto3" json:"merge_key,omitempty"`
+}
+
+func (x *MapSpec) Reset() {
+	*x = MapSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *MapSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MapSpec) ProtoMessage() {}
+
+func (x *MapSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MapSpec.ProtoReflect.Descriptor instead.
+func (*MapSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *MapSpec) GetFn() *Function {
+	if x != nil {
+		return x.Fn
+	}
+	return nil
+}
+
+func (x *MapSpec) GetMergeKey() bool {
+	if x != nil {
+		return x.MergeKey
+	}
+	return false
+}
+
+type GroupSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Mode    int32    `protobuf:"varint,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	Columns []string `protobuf:"bytes,2,rep,name=columns,proto3" json:"columns,omitempty"`
+}
+
+func (x *GroupSpec) Reset() {
+	*x = GroupSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GroupSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupSpec) ProtoMessage() {}
+
+func (x *GroupSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupSpec.ProtoReflect.Descriptor instead.
+func (*GroupSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *GroupSpec) GetMode() int32 {
+	if x != nil {
+		return x.Mode
+	}
+	return 0
+}
+
+func (x *GroupSpec) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+// AggregateSpec encodes the aggregates count, sum and mean.
+type AggregateSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Columns []string `protobuf:"bytes,1,rep,name=columns,proto3" json:"columns,omitempty"`
+}
+
+func (x *AggregateSpec) Reset() {
+	*x = AggregateSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AggregateSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AggregateSpec) ProtoMessage() {}
+
+func (x *AggregateSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AggregateSpec.ProtoReflect.Descriptor instead.
+func (*AggregateSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *AggregateSpec) GetColumns() []string {
+	if x != nil {
+		return x.Columns
+	}
+	return nil
+}
+
+// SelectorSpec encodes the selectors first, last, min and max.
+type SelectorSpec struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Column string `protobuf:"bytes,1,opt,name=column,proto3" json:"column,omitempty"`
+}
+
+func (x *SelectorSpec) Reset() {
+	*x = SelectorSpec{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_plan_planpb_fragment_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SelectorSpec) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelectorSpec) ProtoMessage() {}
+
+func (x *SelectorSpec) ProtoReflect() protoreflect.Message {
+	mi := &file_plan_planpb_fragment_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelectorSpec.ProtoReflect.Descriptor instead.
+func (*SelectorSpec) Descriptor() ([]byte, []int) {
+	return file_plan_planpb_fragment_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *SelectorSpec) GetColumn() string {
+	if x != nil {
+		return x.Column
+	}
+	return ""
+}
+
+var File_plan_planpb_fragment_proto protoreflect.FileDescriptor
+
+var file_plan_planpb_fragment_proto_rawDesc = []byte{
+	0x0a, 0x1a, 0x70, 0x6c, 0x61, 0x6e, 0x2f, 0x70, 0x6c, 0x61, 0x6e, 0x70, 0x62, 0x2f, 0x66, 0x72,
+	0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x09, 0x66, 0x6c,
+	0x75, 0x78, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x22, 0x91, 0x01, 0x0a, 0x08, 0x46, 0x72, 0x61, 0x67,
+	0x6d, 0x65, 0x6e, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x10,
+	0x0a, 0x03, 0x6e, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x03, 0x6e, 0x6f, 0x77,
+	0x12, 0x32, 0x0a, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x2e,
+	0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x52, 0x09, 0x72, 0x65, 0x73, 0x6f, 0x75,
+	0x72, 0x63, 0x65, 0x73, 0x12, 0x25, 0x0a, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x18, 0x04, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x2e,
+	0x4e, 0x6f, 0x64, 0x65, 0x52, 0x05, 0x6e, 0x6f, 0x64, 0x65, 0x73, 0x22, 0x82, 0x01, 0x0a, 0x09,
+	0x52, 0x65, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x70, 0x72, 0x69,
+	0x6f, 0x72, 0x69, 0x74, 0x79, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72,
+	0x65, 0x6e, 0x63, 0x79, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x10, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x51, 0x75, 0x6f,
+	0x74, 0x61, 0x12, 0x2c, 0x0a, 0x12, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x5f, 0x71, 0x75, 0x6f, 0x74, 0x61, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x10,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x42, 0x79, 0x74, 0x65, 0x73, 0x51, 0x75, 0x6f, 0x74, 0x61,
+	0x22, 0x56, 0x0a, 0x04, 0x4e, 0x6f, 0x64, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x6b, 0x69, 0x6e, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6b, 0x69, 0x6e, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x73, 0x70, 0x65, 0x63, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x73, 0x70, 0x65, 0x63,
+	0x12, 0x16, 0x0a, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x05,
+	0x52, 0x06, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x73, 0x22, 0x3e, 0x0a, 0x0c, 0x43, 0x61, 0x70, 0x61,
+	0x62, 0x69, 0x6c, 0x69, 0x74, 0x69, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x14, 0x0a, 0x05, 0x6b, 0x69, 0x6e, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x05, 0x6b, 0x69, 0x6e, 0x64, 0x73, 0x22, 0x22, 0x0a, 0x08, 0x46, 0x75, 0x6e, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22, 0x5f, 0x0a, 0x04,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x69, 0x73, 0x5f, 0x72, 0x65, 0x6c, 0x61, 0x74,
+	0x69, 0x76, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x69, 0x73, 0x52, 0x65, 0x6c,
+	0x61, 0x74, 0x69, 0x76, 0x65, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x76,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x76,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x62, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x65, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x03, 0x52, 0x08, 0x61, 0x62, 0x73, 0x6f, 0x6c, 0x75, 0x74, 0x65, 0x22, 0x66, 0x0a,
+	0x06, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x12, 0x25, 0x0a, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x2e, 0x70, 0x6c,
+	0x61, 0x6e, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x05, 0x73, 0x74, 0x61, 0x72, 0x74, 0x12, 0x23,
+	0x0a, 0x04, 0x73, 0x74, 0x6f, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x66,
+	0x6c, 0x75, 0x78, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x52, 0x04, 0x73,
+	0x74, 0x6f, 0x70, 0x12, 0x10, 0x0a, 0x03, 0x6e, 0x6f, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03,
+	0x52, 0x03, 0x6e, 0x6f, 0x77, 0x22, 0x40, 0x0a, 0x0a, 0x52, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x53,
+	0x70, 0x65, 0x63, 0x12, 0x1a, 0x0a, 0x08, 0x66, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x08, 0x66, 0x72, 0x61, 0x67, 0x6d, 0x65, 0x6e, 0x74, 0x12,
+	0x16, 0x0a, 0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x06, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x22, 0x1f, 0x0a, 0x09, 0x59, 0x69, 0x65, 0x6c, 0x64,
+	0x53, 0x70, 0x65, 0x63, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x22, 0x7f, 0x0a, 0x0b, 0x46, 0x72, 0x6f, 0x6d,
+	0x43, 0x53, 0x56, 0x53, 0x70, 0x65, 0x63, 0x12, 0x10, 0x0a, 0x03, 0x63, 0x73, 0x76, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x63, 0x73, 0x76, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x69, 0x6c,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x12, 0x0a,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6d, 0x6f, 0x64,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x04, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x70,
+	0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09,
+	0x70, 0x72, 0x6f, 0x6a, 0x65, 0x63, 0x74, 0x65, 0x64, 0x22, 0x9b, 0x01, 0x0a, 0x09, 0x52, 0x61,
+	0x6e, 0x67, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x29, 0x0a, 0x06, 0x62, 0x6f, 0x75, 0x6e, 0x64,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e, 0x66, 0x6c, 0x75, 0x78, 0x2e, 0x70,
+	0x6c, 0x61, 0x6e, 0x2e, 0x42, 0x6f, 0x75, 0x6e, 0x64, 0x73, 0x52, 0x06, 0x62, 0x6f, 0x75, 0x6e,
+	0x64, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x69, 0x6d, 0x65, 0x5f, 0x63, 0x6f, 0x6c, 0x75, 0x6d,
+	0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x74, 0x69, 0x6d, 0x65, 0x43, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x61, 0x72, 0x74, 0x5f, 0x63, 0x6f, 0x6c,
+	0x75, 0x6d, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x73, 0x74, 0x61, 0x72, 0x74,
+	0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x6f, 0x70, 0x5f, 0x63,
+	0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x74, 0x6f,
+	0x70, 0x43, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x22, 0x31, 0x0a, 0x09, 0x4c, 0x69, 0x6d, 0x69, 0x74,
+	0x53, 0x70, 0x65, 0x63, 0x12, 0x0c, 0x0a, 0x01, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x01, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x22, 0x38, 0x0a, 0x08, 0x53, 0x6f,
+	0x72, 0x74, 0x53, 0x70, 0x65, 0x63, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x65, 0x73, 0x63, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x04,
+	0x64, 0x65, 0x73, 0x63, 0x22, 0x5d, 0x0a, 0x0a, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x53, 0x70,
+	0x65, 0x63, 0x12, 0x23, 0x0a, 0x02, 0x66, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13,
+	0x2e, 0x66, 0x6c, 0x75, 0x78, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x2e, 0x46, 0x75, 0x6e, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x02, 0x66, 0x6e, 0x12, 0x2a, 0x0a, 0x11, 0x6b, 0x65, 0x65, 0x70, 0x5f,
+	0x65, 0x6d, 0x70, 0x74, 0x79, 0x5f, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x0f, 0x6b, 0x65, 0x65, 0x70, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x54, 0x61, 0x62,
+	0x6c, 0x65, 0x73, 0x22, 0x4b, 0x0a, 0x07, 0x4d, 0x61, 0x70, 0x53, 0x70, 0x65, 0x63, 0x12, 0x23,
+	0x0a, 0x02, 0x66, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x13, 0x2e, 0x66, 0x6c, 0x75,
+	0x78, 0x2e, 0x70, 0x6c, 0x61, 0x6e, 0x2e, 0x46, 0x75, 0x6e, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x02, 0x66, 0x6e, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x5f, 0x6b, 0x65, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x6d, 0x65, 0x72, 0x67, 0x65, 0x4b, 0x65, 0x79,
+	0x22, 0x39, 0x0a, 0x09, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x53, 0x70, 0x65, 0x63, 0x12, 0x12, 0x0a,
+	0x04, 0x6d, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x6d, 0x6f, 0x64,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x22, 0x29, 0x0a, 0x0d, 0x41,
+	0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x53, 0x70, 0x65, 0x63, 0x12, 0x18, 0x0a, 0x07,
+	0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x63,
+	0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x73, 0x22, 0x26, 0x0a, 0x0c, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74,
+	0x6f, 0x72, 0x53, 0x70, 0x65, 0x63, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x6f, 0x6c, 0x75, 0x6d, 0x6e, 0x42, 0x28,
+	0x5a, 0x26, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x69, 0x6e, 0x66,
+	0x6c, 0x75, 0x78, 0x64, 0x61, 0x74, 0x61, 0x2f, 0x66, 0x6c, 0x75, 0x78, 0x2f, 0x70, 0x6c, 0x61,
+	0x6e, 0x2f, 0x70, 0x6c, 0x61, 0x6e, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_plan_planpb_fragment_proto_rawDescOnce sync.Once
+	file_plan_planpb_fragment_proto_rawDescData = file_plan_planpb_fragment_proto_rawDesc
+)
+
+func file_plan_planpb_fragment_proto_rawDescGZIP() []byte {
+	file_plan_planpb_fragment_proto_rawDescOnce.Do(func() {
+		file_plan_planpb_fragment_proto_rawDescData = protoimpl.X.CompressGZIP(file_plan_planpb_fragment_proto_rawDescData)
+	})
+	return file_plan_planpb_fragment_proto_rawDescData
+}
+
+var file_plan_planpb_fragment_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
+var file_plan_planpb_fragment_proto_goTypes = []interface{}{
+	(*Fragment)(nil),      // 0: flux.plan.Fragment
+	(*Resources)(nil),     // 1: flux.plan.Resources
+	(*Node)(nil),          // 2: flux.plan.Node
+	(*Capabilities)(nil),  // 3: flux.plan.Capabilities
+	(*Function)(nil),      // 4: flux.plan.Function
+	(*Time)(nil),          // 5: flux.plan.Time
+	(*Bounds)(nil),        // 6: flux.plan.Bounds
+	(*RemoteSpec)(nil),    // 7: flux.plan.RemoteSpec
+	(*YieldSpec)(nil),     // 8: flux.plan.YieldSpec
+	(*FromCSVSpec)(nil),   // 9: flux.plan.FromCSVSpec
+	(*RangeSpec)(nil),     // 10: flux.plan.RangeSpec
+	(*LimitSpec)(nil),     // 11: flux.plan.LimitSpec
+	(*SortSpec)(nil),      // 12: flux.plan.SortSpec
+	(*FilterSpec)(nil),    // 13: flux.plan.FilterSpec
+	(*MapSpec)(nil),       // 14: flux.plan.MapSpec
+	(*GroupSpec)(nil),     // 15: flux.plan.GroupSpec
+	(*AggregateSpec)(nil), // 16: flux.plan.AggregateSpec
+	(*SelectorSpec)(nil),  // 17: flux.plan.SelectorSpec
+}
+var file_plan_planpb_fragment_proto_depIdxs = []int32{
+	1, // 0: flux.plan.Fragment.resources:type_name -> flux.plan.Resources
+	2, // 1: flux.plan.Fragment.nodes:type_name -> flux.plan.Node
+	5, // 2: flux.plan.Bounds.start:type_name -> flux.plan.Time
+	5, // 3: flux.plan.Bounds.stop:type_name -> flux.plan.Time
+	6, // 4: flux.plan.RangeSpec.bounds:type_name -> flux.plan.Bounds
+	4, // 5: flux.plan.FilterSpec.fn:type_name -> flux.plan.Function
+	4, // 6: flux.plan.MapSpec.fn:type_name -> flux.plan.Function
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_plan_planpb_fragment_proto_init() }
+func file_plan_planpb_fragment_proto_init() {
+	if File_plan_planpb_fragment_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_plan_planpb_fragment_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Fragment); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Resources); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Node); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Capabilities); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Function); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Time); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Bounds); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RemoteSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*YieldSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FromCSVSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RangeSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LimitSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SortSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*FilterSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MapSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GroupSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AggregateSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_plan_planpb_fragment_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SelectorSpec); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_plan_planpb_fragment_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   18,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_plan_planpb_fragment_proto_goTypes,
+		DependencyIndexes: file_plan_planpb_fragment_proto_depIdxs,
+		MessageInfos:      file_plan_planpb_fragment_proto_msgTypes,
+	}.Build()
+	File_plan_planpb_fragment_proto = out.File
+	file_plan_planpb_fragment_proto_rawDesc = nil
+	file_plan_planpb_fragment_proto_goTypes = nil
+	file_plan_planpb_fragment_proto_depIdxs = nil
+}
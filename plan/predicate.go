@@ -0,0 +1,96 @@
+package plan
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/values"
+)
+
+// Predicate compares a column to a constant value.
+// Predicates are pushed down from a filter into a source
+// so the source only produces the rows that match.
+type Predicate struct {
+	Column   string
+	Operator ast.OperatorKind
+	Value    values.Value
+}
+
+func (p Predicate) String() string {
+	return fmt.Sprintf("r.%s %v %v", p.Column, p.Operator, p.Value)
+}
+
+// PredicateCapabilities describes which predicates a source can evaluate.
+type PredicateCapabilities struct {
+	// Columns maps a column to the operators the source supports for it.
+	Columns map[string][]ast.OperatorKind
+	// AnyColumn lists the operators the source supports for every column.
+	AnyColumn []ast.OperatorKind
+	// Accept, if set, further restricts the supported predicates,
+	// such as to the values the source can compare a column to.
+	Accept func(p Predicate) bool
+	// KeepEmpty reports whether the source can produce the tables
+	// that the predicates leave empty. The filters that keep their
+	// empty tables are not pushed into the sources that cannot.
+	KeepEmpty bool
+}
+
+// Supports reports whether the predicate can be evaluated by the source.
+func (c PredicateCapabilities) Supports(p Predicate) bool {
+	if c.Accept != nil && !c.Accept(p) {
+		return false
+	}
+	for _, op := range c.AnyColumn {
+		if op == p.Operator {
+			return true
+		}
+	}
+	for _, op := range c.Columns[p.Column] {
+		if op == p.Operator {
+			return true
+		}
+	}
+	return false
+}
+
+// RegexpPrefix returns the prefix of the strings matched by a regular
+// expression of the form ^prefix, such as the predicates of
+// strings.hasPrefix. It returns false for any other regular expression.
+func RegexpPrefix(re *regexp.Regexp) (string, bool) {
+	r, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	r = r.Simplify()
+	if r.Op != syntax.OpConcat || len(r.Sub) != 2 || r.Sub[0].Op != syntax.OpBeginText {
+		return "", false
+	}
+	lit := r.Sub[1]
+	if lit.Op != syntax.OpLiteral || lit.Flags&syntax.FoldCase != 0 {
+		return "", false
+	}
+	return string(lit.Rune), true
+}
+
+// PredicatePushDownProcedureSpec is implemented by the procedure specs
+// of sources that can filter rows themselves.
+//
+// The planner splits the predicate of a filter directly after the source
+// into the comparisons the source supports, which are pushed into the source,
+// and a residual filter for the rest. The filter is removed when the source
+// supports every comparison.
+type PredicatePushDownProcedureSpec interface {
+	ProcedureSpec
+
+	// PredicateCapabilities reports the predicates the source supports.
+	PredicateCapabilities() PredicateCapabilities
+
+	// PushDownPredicates returns a copy of the procedure spec that only
+	// produces the rows for which every predicate is true.
+	// If keepEmpty is false, tables that have no rows left after
+	// the predicates are applied must not be produced. It is only
+	// true for the sources whose capabilities have KeepEmpty set.
+	PushDownPredicates(predicates []Predicate, keepEmpty bool) PhysicalProcedureSpec
+}
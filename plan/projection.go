@@ -0,0 +1,26 @@
+package plan
+
+// ProjectionPushDownProcedureSpec is implemented by the procedure specs
+// of sources that can skip the columns that are not used downstream.
+type ProjectionPushDownProcedureSpec interface {
+	ProcedureSpec
+
+	// ProjectedColumns returns the labels of the columns the source
+	// produces, or nil if the source produces every column.
+	ProjectedColumns() []string
+
+	// PushDownProjection returns a copy of the procedure spec that only
+	// produces the columns with these labels. Labels that do not match
+	// a column are ignored.
+	PushDownProjection(columns []string) PhysicalProcedureSpec
+}
+
+// ColumnReferencer is implemented by the procedure specs of
+// transformations that produce every column of their input
+// and only read some of them.
+type ColumnReferencer interface {
+	// ReferencedColumns returns the labels of the columns the
+	// transformation reads. It returns false if those columns
+	// cannot be determined.
+	ReferencedColumns() ([]string, bool)
+}
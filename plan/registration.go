@@ -2,6 +2,7 @@ package plan
 
 import (
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/influxdata/flux"
@@ -73,12 +74,18 @@ func HasSideEffect(spec ProcedureSpec) bool {
 var ruleNameToLogicalRule = make(map[string]Rule)
 var ruleNameToPhysicalRule = make(map[string]Rule)
 
-// RegisterLogicalRules registers the rule created by createFn with the logical plan.
+// RegisterLogicalRules registers the rules with the logical planner.
+// Packages outside of Flux may use this to add their own rewrite rules.
+// Rules may implement PrioritizedRule or be wrapped with WithPriority
+// to control the order they are attempted.
 func RegisterLogicalRules(rules ...Rule) {
 	registerRule(ruleNameToLogicalRule, rules...)
 }
 
-// RegisterPhysicalRules registers the rule created by createFn with the physical plan.
+// RegisterPhysicalRules registers the rules with the physical planner.
+// Packages outside of Flux may use this to add their own rewrite rules.
+// Rules may implement PrioritizedRule or be wrapped with WithPriority
+// to control the order they are attempted.
 func RegisterPhysicalRules(rules ...Rule) {
 	registerRule(ruleNameToPhysicalRule, rules...)
 }
@@ -93,6 +100,36 @@ func registerRule(ruleMap map[string]Rule, rules ...Rule) {
 	}
 }
 
+// RegisteredLogicalRules returns the names of the registered logical rules.
+func RegisteredLogicalRules() []string {
+	return ruleNames(ruleNameToLogicalRule)
+}
+
+// RegisteredPhysicalRules returns the names of the registered physical rules.
+func RegisteredPhysicalRules() []string {
+	return ruleNames(ruleNameToPhysicalRule)
+}
+
+func ruleNames(ruleMap map[string]Rule) []string {
+	names := make([]string, 0, len(ruleMap))
+	for name := range ruleMap {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registeredRules returns the registered rules ordered by name
+// so the planner attempts them in the same order each time.
+func registeredRules(ruleMap map[string]Rule) []Rule {
+	names := ruleNames(ruleMap)
+	rules := make([]Rule, len(names))
+	for i, name := range names {
+		rules[i] = ruleMap[name]
+	}
+	return rules
+}
+
 func ClearRegisteredRules() {
 	ruleNameToLogicalRule = make(map[string]Rule)
 	ruleNameToPhysicalRule = make(map[string]Rule)
@@ -1,6 +1,9 @@
 package plan
 
-import "context"
+import (
+	"context"
+	"sort"
+)
 
 // Rule is transformation rule for a query operation
 type Rule interface {
@@ -15,3 +18,78 @@ type Rule interface {
 	// The boolean return value should be true if anything changed during the rewrite.
 	Rewrite(context.Context, Node) (Node, bool, error)
 }
+
+// PrioritizedRule is an optional interface that rules can implement to
+// control the order in which rules are attempted on a node.
+// Rules with a higher priority are attempted first. Rules that do not
+// implement this interface have a priority of zero and rules with the
+// same priority are attempted in the order of their names.
+type PrioritizedRule interface {
+	Rule
+	Priority() int
+}
+
+// WithPriority returns a rule that behaves like the given rule,
+// but is attempted with the given priority.
+// This can be used to order rules that are registered by other packages.
+func WithPriority(rule Rule, priority int) Rule {
+	return prioritizedRule{Rule: rule, priority: priority}
+}
+
+type prioritizedRule struct {
+	Rule
+	priority int
+}
+
+func (r prioritizedRule) Priority() int {
+	return r.priority
+}
+
+func rulePriority(rule Rule) int {
+	if pr, ok := rule.(PrioritizedRule); ok {
+		return pr.Priority()
+	}
+	return 0
+}
+
+// sortRules orders the rules by descending priority.
+// Rules with the same priority keep their relative order.
+func sortRules(rules []Rule) {
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rulePriority(rules[i]) > rulePriority(rules[j])
+	})
+}
+
+type disabledRulesKey struct{}
+
+// WithDisabledRules returns a context where the planner will not apply
+// the named rules. This can be used to disable rules for a single query
+// without changing the rules that are registered.
+func WithDisabledRules(ctx context.Context, names ...string) context.Context {
+	disabled := make(map[string]bool)
+	for name := range disabledRules(ctx) {
+		disabled[name] = true
+	}
+	for _, name := range names {
+		disabled[name] = true
+	}
+	return context.WithValue(ctx, disabledRulesKey{}, disabled)
+}
+
+// WithEnabledRules returns a context where the named rules are applied
+// again after being disabled with WithDisabledRules.
+func WithEnabledRules(ctx context.Context, names ...string) context.Context {
+	disabled := make(map[string]bool)
+	for name := range disabledRules(ctx) {
+		disabled[name] = true
+	}
+	for _, name := range names {
+		delete(disabled, name)
+	}
+	return context.WithValue(ctx, disabledRulesKey{}, disabled)
+}
+
+func disabledRules(ctx context.Context) map[string]bool {
+	disabled, _ := ctx.Value(disabledRulesKey{}).(map[string]bool)
+	return disabled
+}
@@ -0,0 +1,127 @@
+package plan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// RuleTrace records one evaluation of a rule on a plan node.
+type RuleTrace struct {
+	// Rule is the name of the rule.
+	Rule string `json:"rule"`
+	// Node is the ID of the node the rule was evaluated on.
+	Node string `json:"node"`
+	// Matched is true if the pattern of the rule matched the node.
+	Matched bool `json:"matched"`
+	// Fired is true if the rule rewrote the plan.
+	Fired bool `json:"fired"`
+	// Before and After are snippets of the plan rooted at the node
+	// before and after the rule was applied. Before is only set if
+	// the pattern matched and After is only set if the rule fired.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Trace records the rules evaluated by the planner.
+// A Trace is added to a context with WithTrace
+// and filled in when a query is planned with that context.
+type Trace struct {
+	mu    sync.Mutex
+	Rules []RuleTrace `json:"rules"`
+}
+
+func (t *Trace) add(rt RuleTrace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.Rules = append(t.Rules, rt)
+}
+
+// WriteText writes one entry for each rule evaluation.
+// The snippets are written for the rules that matched.
+func (t *Trace) WriteText(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var sb strings.Builder
+	for _, rt := range t.Rules {
+		status := "no match"
+		if rt.Fired {
+			status = "fired"
+		} else if rt.Matched {
+			status = "matched, not fired"
+		}
+		fmt.Fprintf(&sb, "%s on %s: %s\n", rt.Rule, rt.Node, status)
+		writeSnippet(&sb, "before", rt.Before)
+		writeSnippet(&sb, "after", rt.After)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeSnippet(sb *strings.Builder, name, snippet string) {
+	if snippet == "" {
+		return
+	}
+	fmt.Fprintf(sb, "  %s:\n", name)
+	for _, line := range strings.Split(snippet, "\n") {
+		fmt.Fprintf(sb, "    %s\n", line)
+	}
+}
+
+// WriteJSON writes the trace as indented JSON.
+func (t *Trace) WriteJSON(w io.Writer) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(t)
+}
+
+type traceKey struct{}
+
+// WithTrace returns a context where the planner
+// records every rule it evaluates in the trace.
+func WithTrace(ctx context.Context, t *Trace) context.Context {
+	return context.WithValue(ctx, traceKey{}, t)
+}
+
+func traceFromContext(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey{}).(*Trace)
+	return t
+}
+
+// snippetDepth is the number of levels of predecessors
+// included in a snippet, which covers the patterns of most rules.
+const snippetDepth = 2
+
+// formatSnippet formats the node and its predecessors,
+// one node per line with the predecessors indented below it.
+func formatSnippet(n Node) string {
+	var sb strings.Builder
+	formatSnippetNode(&sb, n, 0)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func formatSnippetNode(sb *strings.Builder, n Node, depth int) {
+	indent := strings.Repeat("  ", depth)
+	fmt.Fprintf(sb, "%s%s (%s)\n", indent, n.ID(), n.Kind())
+	if d, ok := n.ProcedureSpec().(Detailer); ok {
+		for _, line := range strings.Split(strings.TrimSpace(d.PlanDetails()), "\n") {
+			fmt.Fprintf(sb, "%s  // %s\n", indent, line)
+		}
+	}
+	if depth == snippetDepth {
+		if len(n.Predecessors()) > 0 {
+			fmt.Fprintf(sb, "%s  ...\n", indent)
+		}
+		return
+	}
+	for _, pred := range n.Predecessors() {
+		formatSnippetNode(sb, pred, depth+1)
+	}
+}
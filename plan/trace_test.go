@@ -0,0 +1,87 @@
+package plan_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+)
+
+// mergeMockRule merges a mock node into its mock predecessor.
+type mergeMockRule struct{}
+
+func (mergeMockRule) Name() string { return "mergeMockRule" }
+
+func (mergeMockRule) Pattern() plan.Pattern {
+	return plan.Pat(plantest.MockKind, plan.Pat(plantest.MockKind))
+}
+
+func (mergeMockRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	n, err := plan.MergeToPhysicalNode(node, node.Predecessors()[0], plantest.MockProcedureSpec{})
+	if err != nil {
+		return nil, false, err
+	}
+	return n, true, nil
+}
+
+func TestPhysicalPlanner_Trace(t *testing.T) {
+	p := plantest.CreatePlanSpec(&plantest.PlanSpec{
+		Nodes: []plan.Node{
+			plantest.CreatePhysicalMockNode("0"),
+			plantest.CreatePhysicalMockNode("1"),
+		},
+		Edges: [][2]int{{0, 1}},
+	})
+
+	trace := &plan.Trace{}
+	ctx := plan.WithTrace(context.Background(), trace)
+	planner := plan.NewPhysicalPlanner(plan.OnlyPhysicalRules(mergeMockRule{}), plan.DisableValidation())
+	if _, err := planner.Plan(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rule that converts logical nodes is evaluated
+	// on every node so only the merge rule is checked.
+	var got []plan.RuleTrace
+	for _, rt := range trace.Rules {
+		if rt.Rule == "mergeMockRule" {
+			got = append(got, rt)
+		}
+	}
+	want := []plan.RuleTrace{
+		{
+			Rule:    "mergeMockRule",
+			Node:    "1",
+			Matched: true,
+			Fired:   true,
+			Before:  "1 (mock)\n  0 (mock)",
+			After:   "merged_0_1 (mock)",
+		},
+		{
+			Rule: "mergeMockRule",
+			Node: "merged_0_1",
+		},
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected trace -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	var buf bytes.Buffer
+	if err := (&plan.Trace{Rules: got}).WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	wantText := `mergeMockRule on 1: fired
+  before:
+    1 (mock)
+      0 (mock)
+  after:
+    merged_0_1 (mock)
+mergeMockRule on merged_0_1: no match
+`
+	if got := buf.String(); got != wantText {
+		t.Errorf("unexpected text -want/+got:\n%s", cmp.Diff(wantText, got))
+	}
+}
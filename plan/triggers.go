@@ -1,6 +1,10 @@
 package plan
 
-import "github.com/influxdata/flux"
+import (
+	"fmt"
+
+	"github.com/influxdata/flux"
+)
 
 type TriggerSpec interface {
 	Kind() TriggerKind
@@ -17,6 +21,25 @@ const (
 	OrFinally
 )
 
+func (k TriggerKind) String() string {
+	switch k {
+	case NarrowTransformation:
+		return "narrowTransformation"
+	case AfterWatermark:
+		return "afterWatermark"
+	case Repeated:
+		return "repeated"
+	case AfterProcessingTime:
+		return "afterProcessingTime"
+	case AfterAtLeastCount:
+		return "afterAtLeastCount"
+	case OrFinally:
+		return "orFinally"
+	default:
+		return fmt.Sprintf("TriggerKind(%d)", int(k))
+	}
+}
+
 var DefaultTriggerSpec = AfterWatermarkTriggerSpec{}
 
 type TriggerAwareProcedureSpec interface {
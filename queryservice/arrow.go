@@ -0,0 +1,60 @@
+package queryservice
+
+import (
+	"bytes"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	arrowmemory "github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/internal/arrowutil"
+)
+
+// encodeChunk encodes the rows of a chunk of a table as an Arrow IPC
+// stream with the schema of the table and one record batch.
+// A nil chunk encodes the schema of the columns with no rows.
+func encodeChunk(cols []flux.ColMeta, cr flux.ColReader, mem arrowmemory.Allocator) ([]byte, error) {
+	fields := make([]arrow.Field, len(cols))
+	for j, col := range cols {
+		typ, err := arrowutil.DataType(col.Type)
+		if err != nil {
+			return nil, err
+		}
+		fields[j] = arrow.Field{Name: col.Label, Type: typ, Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	arrs := make([]array.Interface, len(cols))
+	defer func() {
+		for _, arr := range arrs {
+			if arr != nil {
+				arr.Release()
+			}
+		}
+	}()
+	var n int
+	for j := range cols {
+		if cr == nil {
+			b := array.NewBuilder(mem, fields[j].Type)
+			arrs[j] = b.NewArray()
+			b.Release()
+			continue
+		}
+		arrs[j] = arrowutil.Column(cr, j, mem)
+		n = cr.Len()
+	}
+	rec := array.NewRecord(schema, arrs, int64(n))
+	defer rec.Release()
+
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema), ipc.WithAllocator(mem))
+	if err := w.Write(rec); err != nil {
+		_ = w.Close()
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,141 @@
+package queryservice
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+)
+
+const serviceName = "influxdata.flux.queryservice.QueryService"
+
+// QueryServiceServer is the server API of the QueryService.
+type QueryServiceServer interface {
+	// Query executes a query and sends the frames of its results to the stream.
+	Query(*QueryRequest, FrameSender) error
+	// Cancel cancels a query that is executing
+	// if it was sent by the same client.
+	Cancel(context.Context, *CancelRequest) (*CancelResponse, error)
+}
+
+// FrameSender is the server stream of a Query RPC.
+type FrameSender interface {
+	Send(*Frame) error
+	grpc.ServerStream
+}
+
+// RegisterQueryServiceServer registers the implementation
+// of the QueryService with a gRPC server.
+func RegisterQueryServiceServer(s grpc.ServiceRegistrar, srv QueryServiceServer) {
+	s.RegisterService(&serviceDesc, srv)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*QueryServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Cancel",
+			Handler:    cancelHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Query",
+			Handler:       queryHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "queryservice/queryservice.proto",
+}
+
+func queryHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(QueryRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(QueryServiceServer).Query(req, frameSender{stream})
+}
+
+type frameSender struct {
+	grpc.ServerStream
+}
+
+func (s frameSender) Send(f *Frame) error {
+	return s.ServerStream.SendMsg(f)
+}
+
+func cancelHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	req := new(CancelRequest)
+	if err := dec(req); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(QueryServiceServer).Cancel(ctx, req)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/Cancel",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(QueryServiceServer).Cancel(ctx, req.(*CancelRequest))
+	}
+	return interceptor(ctx, req, info, handler)
+}
+
+// QueryServiceClient is the client API of the QueryService.
+type QueryServiceClient interface {
+	// Query executes a query and returns the stream of the frames of its results.
+	Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (FrameReceiver, error)
+	// Cancel cancels a query of the client that is executing.
+	Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error)
+}
+
+// FrameReceiver is the client stream of a Query RPC.
+// Recv returns io.EOF after the last frame of a query that succeeded.
+type FrameReceiver interface {
+	Recv() (*Frame, error)
+	grpc.ClientStream
+}
+
+// NewQueryServiceClient creates a client of the QueryService of a connection.
+func NewQueryServiceClient(cc grpc.ClientConnInterface) QueryServiceClient {
+	return queryServiceClient{cc: cc}
+}
+
+type queryServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func (c queryServiceClient) Query(ctx context.Context, in *QueryRequest, opts ...grpc.CallOption) (FrameReceiver, error) {
+	stream, err := c.cc.NewStream(ctx, &serviceDesc.Streams[0], "/"+serviceName+"/Query", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return frameReceiver{stream}, nil
+}
+
+type frameReceiver struct {
+	grpc.ClientStream
+}
+
+func (r frameReceiver) Recv() (*Frame, error) {
+	f := new(Frame)
+	if err := r.ClientStream.RecvMsg(f); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (c queryServiceClient) Cancel(ctx context.Context, in *CancelRequest, opts ...grpc.CallOption) (*CancelResponse, error) {
+	out := new(CancelResponse)
+	if err := c.cc.Invoke(ctx, "/"+serviceName+"/Cancel", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
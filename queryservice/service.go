@@ -0,0 +1,213 @@
+// Package queryservice implements a gRPC service that executes Flux
+// queries and streams their results as Arrow record batches.
+//
+// The service is defined in queryservice.proto. Programmatic clients
+// read the columns of the results directly from the Arrow buffers
+// rather than parsing the rows of annotated CSV.
+package queryservice
+
+//go:generate protoc -I.. --go_out=.. --go_opt=paths=source_relative queryservice/queryservice.proto
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/control"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/grpcutil"
+	"github.com/influxdata/flux/lang"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/runtime"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	grpccodes "google.golang.org/grpc/codes"
+)
+
+// Service implements the QueryService with the runtime of Flux.
+type Service struct {
+	deps context.Context
+
+	mu      sync.Mutex
+	queries map[queryKey]context.CancelFunc
+}
+
+// queryKey identifies a query that is executing. The ids of the
+// queries are only unique among the queries of the same client,
+// so a client cannot cancel the queries of another client.
+type queryKey struct {
+	client string
+	id     string
+}
+
+// New creates a Service. The queries read their dependencies
+// from the values of ctx, such as those injected by flux.Dependencies,
+// and are canceled when their RPC is canceled.
+func New(ctx context.Context) *Service {
+	return &Service{
+		deps:    ctx,
+		queries: make(map[queryKey]context.CancelFunc),
+	}
+}
+
+// Query implements QueryServiceServer.
+func (s *Service) Query(req *QueryRequest, stream FrameSender) error {
+	if req.Query == "" {
+		return status.Error(grpccodes.InvalidArgument, "query is required")
+	}
+	id := req.QueryId
+	if id == "" {
+		id = newQueryID()
+	}
+	ctx, cancel := context.WithCancel(grpcutil.WithValues(stream.Context(), s.deps))
+	defer cancel()
+	key := queryKey{client: clientOf(ctx), id: id}
+	if err := s.add(key, cancel); err != nil {
+		return grpcutil.ToStatus(err)
+	}
+	defer s.remove(key)
+
+	if err := stream.Send(&Frame{QueryId: id}); err != nil {
+		return err
+	}
+	if err := s.execute(ctx, id, req, stream); err != nil {
+		if ctx.Err() != nil && stream.Context().Err() == nil {
+			return status.Errorf(grpccodes.Canceled, "query %q was canceled", id)
+		}
+		return grpcutil.ToStatus(err)
+	}
+	return nil
+}
+
+func (s *Service) execute(ctx context.Context, id string, req *QueryRequest, stream FrameSender) error {
+	now := time.Now()
+	if req.Now != 0 {
+		now = time.Unix(0, req.Now)
+	}
+	program, err := lang.FluxCompiler{Now: now, Query: req.Query}.Compile(ctx, runtime.Default)
+	if err != nil {
+		return err
+	}
+	alloc := &memory.Allocator{}
+	q, err := control.Start(ctx, program, alloc)
+	if err != nil {
+		return err
+	}
+	defer q.Done()
+
+	for res := range q.Results() {
+		if err := sendResult(id, res, stream, alloc); err != nil {
+			q.Cancel()
+			return err
+		}
+	}
+	q.Done()
+	return q.Err()
+}
+
+// sendResult sends a frame for each chunk of the tables of a result.
+func sendResult(id string, res flux.Result, stream FrameSender, alloc *memory.Allocator) error {
+	mem := arrow.NewAllocator(alloc)
+	var index int64
+	return res.Tables().Do(func(tbl flux.Table) error {
+		key := tbl.Key().Cols()
+		groupKey := make([]string, len(key))
+		for j, col := range key {
+			groupKey[j] = col.Label
+		}
+		send := func(cr flux.ColReader) error {
+			data, err := encodeChunk(tbl.Cols(), cr, mem)
+			if err != nil {
+				return err
+			}
+			return stream.Send(&Frame{
+				QueryId:  id,
+				Result:   res.Name(),
+				Table:    index,
+				GroupKey: groupKey,
+				Arrow:    data,
+			})
+		}
+
+		sent := false
+		if err := tbl.Do(func(cr flux.ColReader) error {
+			if cr.Len() == 0 {
+				return nil
+			}
+			sent = true
+			return send(cr)
+		}); err != nil {
+			return err
+		}
+		if !sent {
+			if err := send(nil); err != nil {
+				return err
+			}
+		}
+		index++
+		return nil
+	})
+}
+
+// Cancel implements QueryServiceServer.
+// A query can only be canceled by the client that executes it,
+// see clientOf.
+func (s *Service) Cancel(ctx context.Context, req *CancelRequest) (*CancelResponse, error) {
+	s.mu.Lock()
+	cancel, ok := s.queries[queryKey{client: clientOf(ctx), id: req.QueryId}]
+	s.mu.Unlock()
+	if !ok {
+		return nil, status.Errorf(grpccodes.NotFound, "query %q is not executing", req.QueryId)
+	}
+	cancel()
+	return &CancelResponse{}, nil
+}
+
+func (s *Service) add(key queryKey, cancel context.CancelFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.queries[key]; ok {
+		return errors.Newf(codes.AlreadyExists, "query %q is already executing", key.id)
+	}
+	s.queries[key] = cancel
+	return nil
+}
+
+func (s *Service) remove(key queryKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.queries, key)
+}
+
+// clientOf identifies the client of an RPC. The client of a connection
+// authenticated with a TLS client certificate is the subject of the
+// certificate, so the client may cancel its queries from any of its
+// connections. Otherwise, the client is the address of the connection.
+func clientOf(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) > 0 {
+		return "cert:" + info.State.VerifiedChains[0][0].Subject.String()
+	}
+	if p.Addr == nil {
+		return ""
+	}
+	return "addr:" + p.Addr.String()
+}
+
+func newQueryID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,221 @@
+package queryservice_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"sort"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/queryservice"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// newTestClients serves a Service and creates clients of it
+// that each have their own connection.
+func newTestClients(t *testing.T, n int) []queryservice.QueryServiceClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer()
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	queryservice.RegisterQueryServiceServer(srv, queryservice.New(ctx))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	clients := make([]queryservice.QueryServiceClient, n)
+	for i := range clients {
+		conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		clients[i] = queryservice.NewQueryServiceClient(conn)
+	}
+	return clients
+}
+
+func newTestClient(t *testing.T) queryservice.QueryServiceClient {
+	t.Helper()
+	return newTestClients(t, 1)[0]
+}
+
+func TestService_Query(t *testing.T) {
+	client := newTestClient(t)
+	stream, err := client.Query(context.Background(), &queryservice.QueryRequest{
+		QueryId: "q1",
+		Query: `
+import "array"
+
+array.from(rows: [
+    {host: "a", _value: 1.5},
+    {host: "a", _value: 2.0},
+    {host: "b", _value: 3.5},
+])
+    |> group(columns: ["host"])
+`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.QueryId != "q1" || len(first.Arrow) != 0 {
+		t.Fatalf("unexpected first frame: %v", first)
+	}
+
+	type table struct {
+		Table    int64
+		GroupKey []string
+		Columns  []string
+		Hosts    []string
+		Values   []float64
+	}
+	var got []table
+	for {
+		f, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		r, err := ipc.NewReader(bytes.NewReader(f.Arrow))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tbl := table{Table: f.Table, GroupKey: f.GroupKey}
+		for _, field := range r.Schema().Fields() {
+			tbl.Columns = append(tbl.Columns, field.Name)
+		}
+		sort.Strings(tbl.Columns)
+		for r.Next() {
+			rec := r.Record()
+			hosts := rec.Column(r.Schema().FieldIndices("host")[0]).(*array.String)
+			values := rec.Column(r.Schema().FieldIndices("_value")[0]).(*array.Float64)
+			for i := 0; i < int(rec.NumRows()); i++ {
+				tbl.Hosts = append(tbl.Hosts, hosts.Value(i))
+				tbl.Values = append(tbl.Values, values.Value(i))
+			}
+		}
+		r.Release()
+		got = append(got, tbl)
+	}
+
+	want := []table{
+		{Table: 0, GroupKey: []string{"host"}, Columns: []string{"_value", "host"}, Hosts: []string{"a", "a"}, Values: []float64{1.5, 2.0}},
+		{Table: 1, GroupKey: []string{"host"}, Columns: []string{"_value", "host"}, Hosts: []string{"b"}, Values: []float64{3.5}},
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected tables -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestService_QueryError(t *testing.T) {
+	client := newTestClient(t)
+	stream, err := client.Query(context.Background(), &queryservice.QueryRequest{
+		Query: `from(bucket: 1)`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	} else if f.QueryId == "" {
+		t.Error("expected a generated query id")
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestService_Cancel(t *testing.T) {
+	client := newTestClient(t)
+	if _, err := client.Cancel(context.Background(), &queryservice.CancelRequest{QueryId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	stream, err := client.Query(context.Background(), &queryservice.QueryRequest{
+		QueryId: "q1",
+		Query: `
+import "generate"
+
+generate.from(count: 1000000000, fn: (n) => n, start: 2021-01-01T00:00:00Z, stop: 2021-01-02T00:00:00Z)
+    |> sum()
+`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Cancel(context.Background(), &queryservice.CancelRequest{QueryId: "q1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestService_CancelOtherClient(t *testing.T) {
+	clients := newTestClients(t, 2)
+	owner, other := clients[0], clients[1]
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := owner.Query(ctx, &queryservice.QueryRequest{
+		QueryId: "q1",
+		Query: `
+import "generate"
+
+generate.from(count: 1000000000, fn: (n) => n, start: 2021-01-01T00:00:00Z, stop: 2021-01-02T00:00:00Z)
+    |> sum()
+`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Another client can neither cancel the query
+	// nor is it kept from using the same id.
+	if _, err := other.Cancel(context.Background(), &queryservice.CancelRequest{QueryId: "q1"}); status.Code(err) != codes.NotFound {
+		t.Errorf("unexpected error: %v", err)
+	}
+	otherStream, err := other.Query(context.Background(), &queryservice.QueryRequest{
+		QueryId: "q1",
+		Query:   `import "array" array.from(rows: [{_value: 1}])`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := otherStream.Recv(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := owner.Cancel(context.Background(), &queryservice.CancelRequest{QueryId: "q1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stream.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
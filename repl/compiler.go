@@ -6,6 +6,7 @@ import (
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
 )
 
 // CompilerType specific to the Flux REPL
@@ -16,9 +17,9 @@ type Compiler struct {
 	Spec *flux.Spec `json:"spec"`
 }
 
-func (c Compiler) Compile(ctx context.Context, runtime flux.Runtime) (flux.Program, error) {
+func (c Compiler) Compile(ctx context.Context, _ flux.Runtime) (flux.Program, error) {
 	planner := plan.PlannerBuilder{}.Build()
-	ps, err := planner.Plan(ctx, c.Spec)
+	ps, err := runtime.GetCompileCache(ctx).Plan(ctx, c.Spec, planner.Plan)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,219 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const debugHelp = `Commands:
+  step, s          evaluate the next statement, entering functions
+  next, n          evaluate the next statement without entering functions
+  continue, c      evaluate until the next breakpoint
+  print, p <name>  print the value of a name
+  scope            print the names defined by the current function
+  stack, bt        print the call stack
+  quit, q          stop the evaluation`
+
+// debugger stops the evaluation of an input at breakpoints,
+// or after each statement when stepping, and reads commands
+// that inspect the evaluation until it continues.
+type debugger struct {
+	// lines and functions are the breakpoints. A line breakpoint stops
+	// at the statements that start on the line of the input and a function
+	// breakpoint stops at the first statement of each call to the function.
+	lines     map[int]bool
+	functions map[string]bool
+
+	// step stops at the next statement. next stops at the next
+	// statement whose call stack is not deeper than nextDepth.
+	step      bool
+	next      bool
+	nextDepth int
+	// depth is the depth of the call stack of the last
+	// statement, which tells when a function is entered.
+	depth int
+
+	// top is the scope of the REPL. The names
+	// it defines are not printed by the scope command.
+	top values.Scope
+	// readLine reads a command.
+	readLine func(prompt string) string
+	w        io.Writer
+}
+
+func newDebugger(top values.Scope, readLine func(prompt string) string, w io.Writer) *debugger {
+	return &debugger{
+		lines:     make(map[int]bool),
+		functions: make(map[string]bool),
+		top:       top,
+		readLine:  readLine,
+		w:         w,
+	}
+}
+
+// active reports whether the debugger needs to see the statements.
+func (d *debugger) active() bool {
+	return d.step || len(d.lines) > 0 || len(d.functions) > 0
+}
+
+// reset forgets the state of the last evaluation.
+func (d *debugger) reset() {
+	d.step, d.next, d.nextDepth, d.depth = false, false, 0, 0
+}
+
+func (d *debugger) Break(ctx context.Context, stmt semantic.Statement, scope values.Scope) error {
+	stack := interpreter.Stack(ctx)
+	entered := len(stack) > d.depth
+	d.depth = len(stack)
+
+	loc := stmt.Location()
+	var reason string
+	switch {
+	case d.step:
+		reason = "step"
+	case d.next && len(stack) <= d.nextDepth:
+		reason = "next"
+	case loc.File == "" && d.lines[loc.Start.Line]:
+		reason = "breakpoint"
+	case entered && len(stack) > 0 && d.functions[stack[0].FunctionName]:
+		reason = fmt.Sprintf("breakpoint in %s", stack[0].FunctionName)
+	default:
+		return nil
+	}
+	fmt.Fprintf(d.w, "%s @%s: %s\n", reason, loc, firstLine(loc.Source))
+
+	for {
+		fields := strings.Fields(d.readLine("(debug) "))
+		if len(fields) == 0 {
+			fields = []string{"help"}
+		}
+		switch cmd, args := fields[0], fields[1:]; cmd {
+		case "step", "s":
+			d.step, d.next = true, false
+			return nil
+		case "next", "n":
+			d.step, d.next, d.nextDepth = false, true, len(stack)
+			return nil
+		case "continue", "c":
+			d.step, d.next = false, false
+			return nil
+		case "quit", "q":
+			return errors.New(codes.Canceled, "evaluation stopped by the debugger")
+		case "print", "p":
+			if len(args) != 1 {
+				fmt.Fprintln(d.w, "usage: print <name>")
+				continue
+			}
+			if v, ok := scope.Lookup(args[0]); ok {
+				values.Display(d.w, v)
+				fmt.Fprintln(d.w)
+			} else {
+				fmt.Fprintf(d.w, "%s is not defined\n", args[0])
+			}
+		case "scope":
+			d.printScope(scope)
+		case "stack", "bt":
+			for _, e := range stack {
+				fmt.Fprintf(d.w, "%s @%s: %s\n", e.FunctionName, e.Location, firstLine(e.Location.Source))
+			}
+		default:
+			fmt.Fprintln(d.w, debugHelp)
+		}
+	}
+}
+
+// printScope prints the names defined in the scope
+// and its parents up to the scope of the REPL.
+func (d *debugger) printScope(scope values.Scope) {
+	var names []string
+	vs := make(map[string]values.Value)
+	for s := scope; s != nil && s != d.top; s = s.Pop() {
+		s.LocalRange(func(k string, v values.Value) {
+			if _, ok := vs[k]; !ok {
+				names = append(names, k)
+				vs[k] = v
+			}
+		})
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(d.w, "%s = ", name)
+		values.Display(d.w, vs[name])
+		fmt.Fprintln(d.w)
+	}
+}
+
+// breakCommand implements the :break command, which lists the
+// breakpoints or adds a breakpoint on a line or a function.
+func (d *debugger) breakCommand(args []string) error {
+	switch len(args) {
+	case 0:
+		d.printBreakpoints()
+		return nil
+	case 1:
+		if line, err := strconv.Atoi(args[0]); err == nil {
+			d.lines[line] = true
+		} else {
+			d.functions[args[0]] = true
+		}
+		return nil
+	default:
+		return fmt.Errorf("usage: :break [<line> | <function>]")
+	}
+}
+
+// clearCommand implements the :clear command, which
+// removes one breakpoint or all of them.
+func (d *debugger) clearCommand(args []string) error {
+	switch len(args) {
+	case 0:
+		d.lines = make(map[int]bool)
+		d.functions = make(map[string]bool)
+		return nil
+	case 1:
+		if line, err := strconv.Atoi(args[0]); err == nil {
+			delete(d.lines, line)
+		} else {
+			delete(d.functions, args[0])
+		}
+		return nil
+	default:
+		return fmt.Errorf("usage: :clear [<line> | <function>]")
+	}
+}
+
+func (d *debugger) printBreakpoints() {
+	lines := make([]int, 0, len(d.lines))
+	for line := range d.lines {
+		lines = append(lines, line)
+	}
+	sort.Ints(lines)
+	for _, line := range lines {
+		fmt.Fprintf(d.w, "line %d\n", line)
+	}
+	functions := make([]string, 0, len(d.functions))
+	for fn := range d.functions {
+		functions = append(functions, fn)
+	}
+	sort.Strings(functions)
+	for _, fn := range functions {
+		fmt.Fprintf(d.w, "function %s\n", fn)
+	}
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i] + " ..."
+	}
+	return s
+}
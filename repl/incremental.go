@@ -0,0 +1,621 @@
+package repl
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"reflect"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/stdlib/csv"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	cachedTablesKind  = "replCachedTables"
+	captureTablesKind = "replCaptureTables"
+)
+
+func init() {
+	execute.RegisterSource(cachedTablesKind, createCachedTablesSource)
+	execute.RegisterTransformation(captureTablesKind, createCaptureTablesTransformation)
+}
+
+// staticSources are the kinds of sources whose output only depends on
+// their procedure spec and, for files, the modification time of the file.
+// Only plans that read from these sources have their tables reused.
+var staticSources = map[plan.ProcedureKind]bool{
+	csv.FromCSVKind: true,
+	"array.from":    true,
+}
+
+// incrementalExecutor reuses the tables produced by the parts of a plan
+// that have not changed since the previous execution.
+//
+// Each node in a plan is identified by a fingerprint of its procedure spec
+// and the fingerprints of its predecessors. When a node has the same
+// fingerprint as a node from the previous execution, the node and its
+// predecessors are replaced by a source that produces the tables that were
+// captured during the previous execution. The tables produced by the other
+// nodes are captured so they can be reused by the next execution.
+type incrementalExecutor struct {
+	mu sync.Mutex
+	// tables are the tables captured during the
+	// previous executions, keyed by fingerprint.
+	tables map[string][]flux.BufferedTable
+	// next are the tables that will be kept
+	// once the current executions are committed.
+	next map[string][]flux.BufferedTable
+	// pending is the number of captures added by prepare
+	// that have not finished since the last commit.
+	pending int
+}
+
+func newIncrementalExecutor() *incrementalExecutor {
+	return &incrementalExecutor{
+		tables: make(map[string][]flux.BufferedTable),
+		next:   make(map[string][]flux.BufferedTable),
+	}
+}
+
+// prepare rewrites the plan so that it reuses any tables that
+// were captured by previous executions and captures the tables
+// of the nodes that could be reused by the next one.
+func (e *incrementalExecutor) prepare(ctx context.Context, ps *plan.Spec) error {
+	fp := &planFingerprinter{
+		ctx:          ctx,
+		fingerprints: make(map[plan.Node]string),
+	}
+	roots := make([]plan.Node, 0, len(ps.Roots))
+	for root := range ps.Roots {
+		roots = append(roots, root)
+	}
+
+	visited := make(map[plan.Node]bool)
+	var rewrite func(n plan.Node) error
+	rewrite = func(n plan.Node) error {
+		if visited[n] {
+			return nil
+		}
+		visited[n] = true
+
+		key := fp.fingerprint(n)
+		if key != "" {
+			e.mu.Lock()
+			tables, ok := e.tables[key]
+			if ok {
+				e.next[key] = tables
+			}
+			e.mu.Unlock()
+			if ok {
+				replaceWithCachedTables(ps, n, tables)
+				return nil
+			}
+		}
+
+		for _, pred := range n.Predecessors() {
+			if err := rewrite(pred); err != nil {
+				return err
+			}
+		}
+		if key != "" {
+			addCaptureTables(ps, n, &captureTablesProcedureSpec{
+				key:  key,
+				exec: e,
+			})
+			e.mu.Lock()
+			e.pending++
+			e.mu.Unlock()
+		}
+		return nil
+	}
+	for _, root := range roots {
+		if err := rewrite(root); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// commit makes the tables that were reused or captured since the
+// last commit available to the next execution and releases the rest.
+// It must be called once the query is done, so that every capture has
+// finished. If the execution failed or a capture did not finish, such
+// as when the query was canceled, the captured tables are discarded.
+func (e *incrementalExecutor) commit(success bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.pending > 0 {
+		success = false
+	}
+	e.pending = 0
+	release, keep := e.tables, e.next
+	if !success {
+		release, keep = e.next, e.tables
+	}
+	for key, tables := range release {
+		if _, ok := keep[key]; ok {
+			continue
+		}
+		for _, tbl := range tables {
+			tbl.Done()
+		}
+	}
+	e.tables = keep
+	e.next = make(map[string][]flux.BufferedTable)
+}
+
+// release releases all of the tables held by the executor.
+func (e *incrementalExecutor) release() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, m := range []map[string][]flux.BufferedTable{e.tables, e.next} {
+		for _, tables := range m {
+			for _, tbl := range tables {
+				tbl.Done()
+			}
+		}
+	}
+	e.tables = make(map[string][]flux.BufferedTable)
+	e.next = make(map[string][]flux.BufferedTable)
+	e.pending = 0
+}
+
+// capture keeps the tables captured for the fingerprint until the
+// next commit. The tables of a capture that failed are nil.
+func (e *incrementalExecutor) capture(key string, tables []flux.BufferedTable, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pending--
+	if err != nil {
+		return
+	}
+	if _, ok := e.next[key]; ok {
+		// The same fingerprint appeared twice in
+		// the plan so keep the first set of tables.
+		for _, tbl := range tables {
+			tbl.Done()
+		}
+		return
+	}
+	e.next[key] = tables
+}
+
+// replaceWithCachedTables replaces n with a node that produces the tables.
+// The predecessors of n are no longer reachable from n afterwards.
+func replaceWithCachedTables(ps *plan.Spec, n plan.Node, tables []flux.BufferedTable) {
+	cached := plan.CreatePhysicalNode(n.ID(), &cachedTablesProcedureSpec{tables: tables})
+	cached.SetBounds(n.Bounds())
+
+	for _, pred := range n.Predecessors() {
+		succs := pred.Successors()
+		pred.ClearSuccessors()
+		for _, succ := range succs {
+			if succ != n {
+				pred.AddSuccessors(succ)
+			}
+		}
+	}
+	for _, succ := range n.Successors() {
+		preds := succ.Predecessors()
+		for i, pred := range preds {
+			if pred == n {
+				preds[i] = cached
+			}
+		}
+		cached.AddSuccessors(succ)
+	}
+	if _, ok := ps.Roots[n]; ok {
+		ps.Replace(n, cached)
+	}
+}
+
+// addCaptureTables adds a node that captures the tables produced by n.
+// The node is added as a root of the plan so that it is executed.
+func addCaptureTables(ps *plan.Spec, n plan.Node, spec *captureTablesProcedureSpec) {
+	capture := plan.CreatePhysicalNode(n.ID()+"_capture", spec)
+	capture.SetBounds(n.Bounds())
+	n.AddSuccessors(capture)
+	capture.AddPredecessors(n)
+	ps.Roots[capture] = struct{}{}
+}
+
+// planFingerprinter computes the fingerprints of the nodes in a plan.
+// A node has an empty fingerprint when its tables cannot be reused.
+type planFingerprinter struct {
+	ctx          context.Context
+	fingerprints map[plan.Node]string
+}
+
+func (p *planFingerprinter) fingerprint(n plan.Node) string {
+	if key, ok := p.fingerprints[n]; ok {
+		return key
+	}
+	key := p.computeFingerprint(n)
+	p.fingerprints[n] = key
+	return key
+}
+
+func (p *planFingerprinter) computeFingerprint(n plan.Node) string {
+	spec := n.ProcedureSpec()
+	if _, ok := spec.(plan.YieldProcedureSpec); ok {
+		return ""
+	} else if plan.HasSideEffect(spec) {
+		return ""
+	}
+
+	h := sha256.New()
+	preds := n.Predecessors()
+	if len(preds) == 0 {
+		if !staticSources[n.Kind()] {
+			return ""
+		}
+		// The contents of a file may change without any change
+		// to the script, so its modification time is included.
+		if spec, ok := spec.(*csv.FromCSVProcedureSpec); ok && spec.File != "" {
+			fi, err := filesystem.Stat(p.ctx, spec.File)
+			if err != nil {
+				return ""
+			}
+			fmt.Fprintf(h, "file:%s:%d:%d;", spec.File, fi.ModTime().UnixNano(), fi.Size())
+		}
+	}
+	for _, pred := range preds {
+		key := p.fingerprint(pred)
+		if key == "" {
+			return ""
+		}
+		fmt.Fprintf(h, "pred:%s;", key)
+	}
+	if b := n.Bounds(); b != nil {
+		fmt.Fprintf(h, "bounds:%d:%d;", b.Start, b.Stop)
+	}
+
+	fmt.Fprintf(h, "kind:%s;", n.Kind())
+	w := &specWriter{h: h, visited: make(map[uintptr]bool)}
+	if !w.write(reflect.ValueOf(spec)) {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+var (
+	timeType             = reflect.TypeOf(time.Time{})
+	regexpType           = reflect.TypeOf((*regexp.Regexp)(nil))
+	resolvedFunctionType = reflect.TypeOf(interpreter.ResolvedFunction{})
+	valueType            = reflect.TypeOf((*values.Value)(nil)).Elem()
+	locType              = reflect.TypeOf(semantic.Loc{})
+)
+
+// specWriter writes a description of a procedure spec to a hash.
+// It reports false when the spec contains something that cannot
+// be described, such as a channel or a user defined function.
+type specWriter struct {
+	h       hash.Hash
+	visited map[uintptr]bool
+}
+
+func (w *specWriter) write(v reflect.Value) bool {
+	if !v.IsValid() {
+		fmt.Fprint(w.h, "invalid;")
+		return true
+	}
+
+	switch v.Type() {
+	case timeType:
+		if !v.CanInterface() {
+			return false
+		}
+		fmt.Fprintf(w.h, "time:%d;", v.Interface().(time.Time).UnixNano())
+		return true
+	case regexpType:
+		if !v.CanInterface() {
+			return false
+		}
+		fmt.Fprintf(w.h, "regexp:%v;", v.Interface())
+		return true
+	case resolvedFunctionType:
+		if !v.CanInterface() {
+			return false
+		}
+		return w.writeFunction(v.Interface().(interpreter.ResolvedFunction))
+	case locType:
+		// Locations change when unrelated lines are edited.
+		return true
+	}
+	if v.Kind() == reflect.Interface && !v.IsNil() && v.Type().Implements(valueType) && v.CanInterface() {
+		if val, ok := v.Interface().(values.Value); ok {
+			return w.writeValue(val)
+		}
+	}
+
+	fmt.Fprintf(w.h, "%s:", v.Type())
+	defer fmt.Fprint(w.h, ";")
+	switch v.Kind() {
+	case reflect.Bool:
+		fmt.Fprint(w.h, v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fmt.Fprint(w.h, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		fmt.Fprint(w.h, v.Uint())
+	case reflect.Float32, reflect.Float64:
+		fmt.Fprint(w.h, v.Float())
+	case reflect.String:
+		fmt.Fprintf(w.h, "%q", v.String())
+	case reflect.Ptr:
+		if v.IsNil() {
+			fmt.Fprint(w.h, "nil")
+			return true
+		}
+		if w.visited[v.Pointer()] {
+			return false
+		}
+		w.visited[v.Pointer()] = true
+		defer delete(w.visited, v.Pointer())
+		return w.write(v.Elem())
+	case reflect.Interface:
+		if v.IsNil() {
+			fmt.Fprint(w.h, "nil")
+			return true
+		}
+		return w.write(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fmt.Fprintf(w.h, "%s=", v.Type().Field(i).Name)
+			if !w.write(v.Field(i)) {
+				return false
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			fmt.Fprint(w.h, "nil")
+			return true
+		}
+		fmt.Fprintf(w.h, "%d:", v.Len())
+		for i := 0; i < v.Len(); i++ {
+			if !w.write(v.Index(i)) {
+				return false
+			}
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		fmt.Fprintf(w.h, "%d:", len(keys))
+		for _, k := range keys {
+			if !w.write(k) || !w.write(v.MapIndex(k)) {
+				return false
+			}
+		}
+	default:
+		// Functions, channels and unsafe pointers
+		// cannot be described.
+		return false
+	}
+	return true
+}
+
+// writeFunction writes a description of a function that includes the
+// values of the identifiers that it references from its scope.
+func (w *specWriter) writeFunction(fn interpreter.ResolvedFunction) bool {
+	if fn.Fn == nil {
+		fmt.Fprint(w.h, "fn:nil;")
+		return true
+	}
+	fmt.Fprintf(w.h, "fn:%v;", semantic.Formatted(fn.Fn))
+	if fn.Scope == nil {
+		return true
+	}
+
+	names := make(map[string]bool)
+	semantic.Walk(semantic.CreateVisitor(func(n semantic.Node) {
+		if id, ok := n.(*semantic.IdentifierExpression); ok {
+			names[id.Name] = true
+		}
+	}), fn.Fn)
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	for _, name := range sorted {
+		v, ok := fn.Scope.Lookup(name)
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(w.h, "ref:%s=", name)
+		if !w.writeValue(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeValue writes a description of a Flux value.
+func (w *specWriter) writeValue(v values.Value) bool {
+	switch v := v.(type) {
+	case values.Package:
+		// Packages are registered once and do not change.
+		fmt.Fprintf(w.h, "package:%s;", v.Path())
+		return true
+	case *values.Option:
+		// Options, such as now, may be changed
+		// without changing the script.
+		return false
+	case values.Function:
+		// Builtin functions are registered once so they are
+		// identified by their address, but functions defined in
+		// the script are values that may reference other values
+		// that are not part of their definition.
+		if reflect.ValueOf(v).Kind() != reflect.Ptr || v.HasSideEffect() {
+			return false
+		}
+		fmt.Fprintf(w.h, "builtin:%p;", v)
+		return true
+	}
+	if v.IsNull() {
+		fmt.Fprint(w.h, "null;")
+		return true
+	}
+	switch v.Type().Nature() {
+	case semantic.Array, semantic.Object, semantic.Dictionary:
+		// Composite values may contain functions and
+		// their display string does not describe them.
+		if containsFunction(v) {
+			return false
+		}
+	}
+	fmt.Fprintf(w.h, "value:%s:%s;", v.Type(), values.DisplayString(v))
+	return true
+}
+
+func containsFunction(v values.Value) (found bool) {
+	switch v.Type().Nature() {
+	case semantic.Function:
+		return true
+	case semantic.Array:
+		v.Array().Range(func(i int, v values.Value) {
+			found = found || containsFunction(v)
+		})
+	case semantic.Object:
+		v.Object().Range(func(name string, v values.Value) {
+			found = found || containsFunction(v)
+		})
+	case semantic.Dictionary:
+		v.Dict().Range(func(k, v values.Value) {
+			found = found || containsFunction(k) || containsFunction(v)
+		})
+	}
+	return found
+}
+
+// cachedTablesProcedureSpec produces tables
+// that were captured by a previous execution.
+type cachedTablesProcedureSpec struct {
+	plan.DefaultCost
+	tables []flux.BufferedTable
+}
+
+func (s *cachedTablesProcedureSpec) Kind() plan.ProcedureKind {
+	return cachedTablesKind
+}
+
+func (s *cachedTablesProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createCachedTablesSource(ps plan.ProcedureSpec, id execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := ps.(*cachedTablesProcedureSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", ps)
+	}
+	return &cachedTablesSource{
+		id:     id,
+		tables: spec.tables,
+	}, nil
+}
+
+type cachedTablesSource struct {
+	execute.ExecutionNode
+	id     execute.DatasetID
+	tables []flux.BufferedTable
+	ts     execute.TransformationSet
+}
+
+func (s *cachedTablesSource) AddTransformation(t execute.Transformation) {
+	s.ts = append(s.ts, t)
+}
+
+func (s *cachedTablesSource) Run(ctx context.Context) {
+	var err error
+	for _, tbl := range s.tables {
+		if err = s.ts.Process(s.id, tbl.Copy()); err != nil {
+			break
+		}
+	}
+	s.ts.Finish(s.id, err)
+}
+
+// captureTablesProcedureSpec captures the tables produced
+// by its predecessor so they can be reused.
+type captureTablesProcedureSpec struct {
+	plan.DefaultCost
+	key  string
+	exec *incrementalExecutor
+}
+
+func (s *captureTablesProcedureSpec) Kind() plan.ProcedureKind {
+	return captureTablesKind
+}
+
+func (s *captureTablesProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createCaptureTablesTransformation(id execute.DatasetID, mode execute.AccumulationMode, ps plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	spec, ok := ps.(*captureTablesProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", ps)
+	}
+	t := &captureTablesTransformation{
+		key:  spec.key,
+		exec: spec.exec,
+	}
+	return t, execute.NewPassthroughDataset(id), nil
+}
+
+type captureTablesTransformation struct {
+	execute.ExecutionNode
+	key    string
+	exec   *incrementalExecutor
+	tables []flux.BufferedTable
+}
+
+func (t *captureTablesTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return errors.New(codes.Unimplemented, "cannot capture retracted tables")
+}
+
+func (t *captureTablesTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	buffered, err := execute.CopyTable(tbl)
+	if err != nil {
+		return err
+	}
+	t.tables = append(t.tables, buffered)
+	return nil
+}
+
+func (t *captureTablesTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return nil
+}
+
+func (t *captureTablesTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return nil
+}
+
+func (t *captureTablesTransformation) Finish(id execute.DatasetID, err error) {
+	if err != nil {
+		for _, tbl := range t.tables {
+			tbl.Done()
+		}
+		t.tables = nil
+	}
+	t.exec.capture(t.key, t.tables, err)
+}
@@ -0,0 +1,181 @@
+package repl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/table/static"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/stdlib/csv"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+const incrementalCSV = `#datatype,string,long,long
+#group,false,false,false
+#default,_result,,
+,result,table,_value
+,,0,1
+,,0,2
+`
+
+// newIncrementalPlan creates the plan of
+// csv.from(csv: text) |> limit(n: n) |> yield().
+func newIncrementalPlan(text string, n int64) (*plan.Spec, plan.Node) {
+	from := plan.CreatePhysicalNode("from", &csv.FromCSVProcedureSpec{CSV: text})
+	limit := plan.CreatePhysicalNode("limit", &universe.LimitProcedureSpec{N: n})
+	yield := plan.CreatePhysicalNode("yield", &universe.YieldProcedureSpec{Name: "_result"})
+	from.AddSuccessors(limit)
+	limit.AddPredecessors(from)
+	limit.AddSuccessors(yield)
+	yield.AddPredecessors(limit)
+	ps := plan.NewPlanSpec()
+	ps.Roots[yield] = struct{}{}
+	return ps, yield
+}
+
+// runCaptures runs the captures of the plan as the execution
+// of the plan would, with each capture finishing with err.
+func runCaptures(t *testing.T, ps *plan.Spec, err error) int {
+	t.Helper()
+	n := 0
+	for root := range ps.Roots {
+		if root.Kind() != captureTablesKind {
+			continue
+		}
+		tr, _, cerr := createCaptureTablesTransformation(execute.DatasetID{}, execute.DiscardingMode, root.ProcedureSpec(), nil)
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+		tbl := static.Table{static.Ints("_value", 1, 2)}.Table(memory.DefaultAllocator)
+		if perr := tr.Process(execute.DatasetID{}, tbl); perr != nil {
+			t.Fatal(perr)
+		}
+		tr.Finish(execute.DatasetID{}, err)
+		n++
+	}
+	return n
+}
+
+// kinds returns the kinds of the nodes from the yield to the source.
+func kinds(n plan.Node) []plan.ProcedureKind {
+	var ks []plan.ProcedureKind
+	for {
+		ks = append(ks, n.Kind())
+		if len(n.Predecessors()) == 0 {
+			return ks
+		}
+		n = n.Predecessors()[0]
+	}
+}
+
+func equalKinds(a, b []plan.ProcedureKind) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestIncrementalExecutor(t *testing.T) {
+	const (
+		yieldKind = universe.YieldKind
+		limitKind = universe.LimitKind
+		fromKind  = csv.FromCSVKind
+	)
+	for _, tc := range []struct {
+		name string
+		// text and n are the arguments of the next execution.
+		text string
+		n    int64
+		// err is the error that the captures of the
+		// first execution finish with.
+		err error
+		// skip does not run the captures of the first execution,
+		// as when its query was canceled before they finished.
+		skip bool
+		// success is whether the first execution is committed
+		// as a success.
+		success bool
+		want    []plan.ProcedureKind
+	}{
+		{
+			name:    "reuse",
+			text:    incrementalCSV,
+			n:       1,
+			success: true,
+			want:    []plan.ProcedureKind{yieldKind, cachedTablesKind},
+		},
+		{
+			name:    "changed transformation",
+			text:    incrementalCSV,
+			n:       2,
+			success: true,
+			want:    []plan.ProcedureKind{yieldKind, limitKind, cachedTablesKind},
+		},
+		{
+			name:    "changed source",
+			text:    incrementalCSV + ",,0,3\n",
+			n:       1,
+			success: true,
+			want:    []plan.ProcedureKind{yieldKind, limitKind, fromKind},
+		},
+		{
+			name: "failed execution",
+			text: incrementalCSV,
+			n:    1,
+			want: []plan.ProcedureKind{yieldKind, limitKind, fromKind},
+		},
+		{
+			name:    "failed captures",
+			text:    incrementalCSV,
+			n:       1,
+			err:     errors.New(codes.Internal, "expected"),
+			success: true,
+			want:    []plan.ProcedureKind{yieldKind, limitKind, fromKind},
+		},
+		{
+			name:    "unfinished captures",
+			text:    incrementalCSV,
+			n:       1,
+			skip:    true,
+			success: true,
+			want:    []plan.ProcedureKind{yieldKind, limitKind, fromKind},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			e := newIncrementalExecutor()
+			defer e.release()
+
+			ps, _ := newIncrementalPlan(incrementalCSV, 1)
+			if err := e.prepare(ctx, ps); err != nil {
+				t.Fatal(err)
+			}
+			if !tc.skip {
+				if want, got := 2, runCaptures(t, ps, tc.err); want != got {
+					t.Fatalf("unexpected number of captures -want/+got:\n\t- %d\n\t+ %d", want, got)
+				}
+			}
+			e.commit(tc.success)
+
+			ps, yield := newIncrementalPlan(tc.text, tc.n)
+			if err := e.prepare(ctx, ps); err != nil {
+				t.Fatal(err)
+			}
+			if got := kinds(yield); !equalKinds(tc.want, got) {
+				t.Errorf("unexpected plan -want/+got:\n\t- %v\n\t+ %v", tc.want, got)
+			}
+			runCaptures(t, ps, nil)
+			e.commit(true)
+		})
+	}
+}
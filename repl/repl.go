@@ -4,23 +4,32 @@ package repl
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/c-bata/go-prompt"
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/deadline"
+	"github.com/influxdata/flux/dependencies/feature"
 	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/fluxdoc"
+	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/internal/spec"
 	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/lang"
 	"github.com/influxdata/flux/libflux/go/libflux"
 	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/resultcache"
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
@@ -34,29 +43,119 @@ type REPL struct {
 	itrp     *interpreter.Interpreter
 	analyzer *libflux.Analyzer
 	importer interpreter.Importer
+	// prelude lists the paths of the packages
+	// whose values are in scope.
+	prelude []string
 
 	cancelMu   sync.Mutex
 	cancelFunc context.CancelFunc
+
+	// profilers are the names of the profilers
+	// that are enabled for each query.
+	profilers []string
+	// profileOutput is the path the operator profile
+	// is written to in pprof format, if set.
+	profileOutput string
+
+	// cache stores the results of queries, if set.
+	cache resultcache.Cache
+	// history contains the normalized text of each input
+	// that has been evaluated. The state of the REPL is
+	// determined by its history so it is used to identify
+	// the results of a query in the cache.
+	history []string
+
+	// timeout is the maximum amount of time
+	// each query may run, if set.
+	timeout time.Duration
+
+	// incremental reuses the tables produced by the parts
+	// of a query that did not change since the last time
+	// it was executed, if set. It is only used when watching.
+	incremental *incrementalExecutor
+
+	// planTrace is where Explain writes the rules
+	// evaluated by the planner, if set.
+	planTrace io.Writer
+
+	// disabledRules are the names of the planner
+	// rules that are not applied to queries.
+	disabledRules []string
+
+	// stalePackages are the packages that changed on disk
+	// and have not been imported again yet.
+	stalePackages []*interpreter.Package
+
+	// debug stops the evaluation at breakpoints.
+	debug *debugger
+
+	// dir is the directory of the script file being evaluated,
+	// which relative imports are resolved from. The working
+	// directory is used for the other inputs.
+	dir string
+}
+
+// Options configure a REPL created with NewWithOptions.
+type Options struct {
+	// Importer imports the packages of the prelude and the packages
+	// imported by the scripts. By default, packages are imported from
+	// the standard library and relative to the script files.
+	// SetFluxPath replaces the importer.
+	Importer interpreter.Importer
+	// Prelude lists the paths of the packages whose values are in scope
+	// without being imported. It defaults to runtime.PreludeList.
+	Prelude []string
 }
 
+// New creates a REPL for the standard library and its prelude.
 func New(ctx context.Context, deps flux.Dependencies) *REPL {
+	r, err := NewWithOptions(ctx, deps, Options{})
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// NewWithOptions creates a REPL with the importer and prelude of the options,
+// so embedders with their own packages or a restricted environment can reuse it.
+// The values of packages that are left out of the prelude are still known
+// to the type checker, but scripts fail when they refer to them.
+func NewWithOptions(ctx context.Context, deps flux.Dependencies, opts Options) (*REPL, error) {
+	analyzer, err := runtime.NewAnalyzer()
+	if err != nil {
+		return nil, err
+	}
+	importer := opts.Importer
+	if importer == nil {
+		importer = runtime.NewPathImporter(nil, analyzer)
+	}
+	prelude := opts.Prelude
+	if prelude == nil {
+		prelude = runtime.PreludeList
+	}
+
 	scope := values.NewScope()
-	importer := runtime.StdLib()
-	for _, p := range runtime.PreludeList {
+	for _, p := range prelude {
 		pkg, err := importer.ImportPackageObject(p)
 		if err != nil {
-			panic(err)
+			analyzer.Free()
+			return nil, err
 		}
 		pkg.Range(scope.Set)
 	}
+	readLine := func(p string) string {
+		return prompt.Input(p, func(prompt.Document) []prompt.Suggest { return nil })
+	}
 	return &REPL{
 		ctx:      ctx,
 		deps:     deps,
 		scope:    scope,
 		itrp:     interpreter.NewInterpreter(nil, &lang.ExecOptsConfig{}),
-		analyzer: libflux.NewAnalyzer(),
+		analyzer: analyzer,
 		importer: importer,
-	}
+		prelude:  prelude,
+		debug:    newDebugger(scope, readLine, os.Stdout),
+	}, nil
 }
 
 func (r *REPL) Run() {
@@ -94,6 +193,137 @@ func (r *REPL) clearCancel() {
 	r.setCancel(nil)
 }
 
+// EnableProfilers enables the named profilers for every query executed by the REPL.
+// The profiler results are printed after the query results.
+// Calling it with no names disables profiling.
+func (r *REPL) EnableProfilers(names ...string) {
+	r.profilers = names
+}
+
+// SetProfileOutput sets a file that the operator profile is written to,
+// in the format understood by pprof, after each query is executed.
+// The operator profiler must be enabled for the file to be written.
+func (r *REPL) SetProfileOutput(path string) {
+	r.profileOutput = path
+}
+
+// SetResultCache sets a cache for the results of queries executed by the REPL.
+// A query is only read from the cache when everything that was evaluated
+// before it and the value of now are identical, so the cache is most useful
+// when the now option is set to a fixed time.
+func (r *REPL) SetResultCache(c resultcache.Cache) {
+	r.cache = c
+}
+
+// SetTimeout sets the maximum amount of time each query may run.
+// A timeout that is not positive lets queries run until they
+// are canceled.
+func (r *REPL) SetTimeout(timeout time.Duration) {
+	r.timeout = timeout
+}
+
+// SetPlannerTrace sets the writer Explain writes the rules evaluated
+// by the planner to, along with the plan before and after each rule
+// that matched. The trace is written apart from the plan so it does not
+// corrupt a plan written as JSON or DOT. A nil writer disables the trace.
+func (r *REPL) SetPlannerTrace(w io.Writer) {
+	r.planTrace = w
+}
+
+// SetFluxPath sets the directories that packages which are not part
+// of the standard library are imported from. Packages imported with
+// a path relative to a script file, such as "./util", are imported
+// from the directory of the script.
+func (r *REPL) SetFluxPath(dirs []string) {
+	r.importer = runtime.NewPathImporter(dirs, r.analyzer)
+}
+
+// SetDisabledRules sets the planner rules that are not applied to the queries
+// executed or explained by the REPL, in addition to the rules disabled
+// with the options of the planner package.
+func (r *REPL) SetDisabledRules(names ...string) {
+	r.disabledRules = names
+}
+
+// SetFeatureFlags overrides the values of feature flags, by key,
+// for the queries of the REPL.
+func (r *REPL) SetFeatureFlags(flags map[string]interface{}) {
+	r.ctx = feature.Override(r.ctx, flags)
+}
+
+// planContext returns a context where the rules disabled
+// in the REPL are not applied by the planner and the
+// feature flags set with the options of the planner
+// package are overridden.
+func (r *REPL) planContext(ctx context.Context) (context.Context, error) {
+	flags, err := lang.FeatureFlags(r.scope)
+	if err != nil {
+		return nil, err
+	}
+	ctx = feature.Override(ctx, flags)
+
+	rules, err := lang.DisabledRules(r.scope)
+	if err != nil {
+		return nil, err
+	}
+	rules = append(rules, r.disabledRules...)
+	if len(rules) == 0 {
+		return ctx, nil
+	}
+	return plan.WithDisabledRules(ctx, rules...), nil
+}
+
+// setCommand implements the :set command, which
+// changes a setting of the REPL.
+func (r *REPL) setCommand(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: :set <setting> <value>")
+	}
+	switch name, value := args[0], args[1]; name {
+	case "timeout":
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid timeout %q: %v", value, err)
+		}
+		r.SetTimeout(timeout)
+		return nil
+	case "feature":
+		flags, err := feature.ParseOverrides([]string{value})
+		if err != nil {
+			return err
+		}
+		r.SetFeatureFlags(flags)
+		return nil
+	default:
+		return fmt.Errorf("unknown setting %q", name)
+	}
+}
+
+// profileCommand implements the :profile command.
+//
+// With no arguments it toggles the query and operator profilers,
+// with "off" it disables profiling and otherwise it enables
+// the named profilers.
+func (r *REPL) profileCommand(args []string) error {
+	switch {
+	case len(args) == 0 && len(r.profilers) > 0,
+		len(args) == 1 && args[0] == "off":
+		r.EnableProfilers()
+		fmt.Println("Profiling disabled")
+		return nil
+	case len(args) == 0:
+		args = []string{"query", "operator"}
+	}
+	for _, name := range args {
+		if _, ok := execute.AllProfilers[name]; !ok {
+			return fmt.Errorf("unknown profiler %q", name)
+		}
+	}
+	r.EnableProfilers(args...)
+	fmt.Println("Profiling enabled:", strings.Join(args, ", "))
+	return nil
+}
+
 func (r *REPL) completer(d prompt.Document) []prompt.Suggest {
 	names := make([]string, 0, r.scope.Size())
 	r.scope.Range(func(k string, v values.Value) {
@@ -101,10 +331,20 @@ func (r *REPL) completer(d prompt.Document) []prompt.Suggest {
 	})
 	sort.Strings(names)
 
+	word := d.GetWordBeforeCursor()
+	if i := strings.LastIndex(word, "."); i > 0 {
+		if v, ok := r.scope.Lookup(word[:i]); ok {
+			if pkg, ok := v.(*interpreter.Package); ok {
+				return prompt.FilterHasPrefix(memberSuggestions(word[:i], pkg), word, true)
+			}
+		}
+	}
+
 	s := make([]prompt.Suggest, 0, len(names))
 	for _, n := range names {
 		if n == "_" || !strings.HasPrefix(n, "_") {
-			s = append(s, prompt.Suggest{Text: n})
+			v, _ := r.scope.Lookup(n)
+			s = append(s, prompt.Suggest{Text: n, Description: r.docHint(n, v)})
 		}
 	}
 	if d.Text == "" || strings.HasPrefix(d.Text, "@") {
@@ -123,16 +363,110 @@ func (r *REPL) completer(d prompt.Document) []prompt.Suggest {
 		}
 	}
 
-	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+	return prompt.FilterHasPrefix(s, word, true)
+}
+
+// memberSuggestions suggests the members of an imported package
+// with the headlines of their documentation.
+func memberSuggestions(name string, pkg *interpreter.Package) []prompt.Suggest {
+	doc, _ := fluxdoc.LookupPackage(pkg.Path())
+	var s []prompt.Suggest
+	pkg.Range(func(k string, v values.Value) {
+		var hint string
+		if doc != nil {
+			if m, ok := doc.Members[k]; ok {
+				hint = oneLine(m.Headline)
+			}
+		}
+		s = append(s, prompt.Suggest{Text: name + "." + k, Description: hint})
+	})
+	sort.Slice(s, func(i, j int) bool {
+		return s[i].Text < s[j].Text
+	})
+	return s
+}
+
+// docHint returns the headline of the documentation of a value in scope.
+// Imported packages are documented by their package docs and the other
+// documented values come from the packages of the prelude.
+func (r *REPL) docHint(name string, v values.Value) string {
+	if pkg, ok := v.(*interpreter.Package); ok {
+		if doc, err := fluxdoc.LookupPackage(pkg.Path()); err == nil {
+			return oneLine(doc.Headline)
+		}
+		return ""
+	}
+	for _, path := range r.prelude {
+		if m, err := fluxdoc.Lookup(path, name); err == nil {
+			return oneLine(m.Headline)
+		}
+	}
+	return ""
+}
+
+// oneLine joins the lines of a headline so it fits in a suggestion.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
 }
 
 func (r *REPL) Input(t string) error {
+	if strings.HasPrefix(t, ":") {
+		return r.executeCommand(t)
+	}
 	return r.executeLine(t)
 }
 
+// executeCommand processes a REPL command, which is a line
+// that starts with a colon.
+func (r *REPL) executeCommand(t string) error {
+	fields := strings.Fields(strings.TrimPrefix(t, ":"))
+	if len(fields) == 0 {
+		return fmt.Errorf("missing command")
+	}
+	switch cmd, args := fields[0], fields[1:]; cmd {
+	case "explain":
+		// The expression is taken from the raw input so
+		// that whitespace inside of strings is preserved.
+		expr := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(t, ":")), cmd))
+		format := "text"
+		if len(args) > 1 && (args[0] == "json" || args[0] == "dot") {
+			format = args[0]
+			expr = strings.TrimSpace(strings.TrimPrefix(expr, format))
+		}
+		if expr == "" {
+			return fmt.Errorf("usage: :explain [json|dot] <expression>")
+		}
+		return r.Explain(os.Stdout, expr, format)
+	case "break":
+		return r.debug.breakCommand(args)
+	case "clear":
+		return r.debug.clearCommand(args)
+	case "step":
+		// The input is taken from the raw input like for :explain.
+		input := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(t, ":")), cmd))
+		if input == "" {
+			return fmt.Errorf("usage: :step <input>")
+		}
+		r.debug.step = true
+		defer r.debug.reset()
+		return r.executeLine(input)
+	case "profile":
+		return r.profileCommand(args)
+	case "set":
+		return r.setCommand(args)
+	case "watch":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: :watch <file>")
+		}
+		return r.Watch(r.ctx, args[0], DefaultWatchInterval)
+	default:
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
 // input processes a line of input and prints the result.
 func (r *REPL) input(t string) {
-	if err := r.executeLine(t); err != nil {
+	if err := r.Input(t); err != nil {
 		fmt.Println("Error:", err)
 	}
 }
@@ -147,46 +481,84 @@ func (r *REPL) Eval(t string) ([]interpreter.SideEffect, error) {
 		if err != nil {
 			return nil, err
 		}
+		defer r.setDir(filepath.Dir(t[1:]))()
 		t = q
 	}
 
+	if err := r.reloadPackages(); err != nil {
+		return nil, err
+	}
 	pkg, err := r.analyzeLine(t)
 	if err != nil {
-		return nil, err
+		return nil, errors.WithSourceFrames(err, t)
 	}
 
 	deps := execute.DefaultExecutionDependencies()
 	r.ctx = deps.Inject(r.ctx)
 
-	return r.itrp.Eval(r.ctx, pkg, r.scope, r.importer)
+	ctx := r.ctx
+	if r.debug.active() {
+		ctx = interpreter.WithDebugger(ctx, r.debug)
+		defer r.debug.reset()
+	}
+	ses, err := r.itrp.Eval(ctx, pkg, r.scope, r.importer)
+	if err != nil {
+		return nil, errors.WithSourceFrames(err, t)
+	}
+	if err := runtime.ValidateOptions(r.scope); err != nil {
+		return nil, err
+	}
+	if r.cache != nil {
+		r.history = append(r.history, normalizeScript(t))
+	}
+	return ses, nil
+}
+
+// normalizeScript formats the script so that changes to whitespace
+// and comments do not change how the script is identified.
+func normalizeScript(t string) string {
+	ast := libflux.ParseString(t)
+	defer ast.Free()
+	if s, err := ast.Format(); err == nil {
+		return s
+	}
+	return strings.TrimSpace(t)
 }
 
 // executeLine processes a line of input.
 // If the input evaluates to a valid value, that value is returned.
 func (r *REPL) executeLine(t string) error {
+	// The script is loaded here so the errors of its
+	// queries can show the lines of the script.
+	if t != "" && t[0] == '@' {
+		q, err := LoadQuery(t)
+		if err != nil {
+			return err
+		}
+		defer r.setDir(filepath.Dir(t[1:]))()
+		t = q
+	}
 	ses, err := r.Eval(t)
 	if err != nil {
 		return err
 	}
 
-	for _, se := range ses {
+	for i, se := range ses {
 		if _, ok := se.Node.(*semantic.ExpressionStatement); ok {
-			if t, ok := se.Value.(*flux.TableObject); ok {
-				now, ok := r.scope.Lookup("now")
-				if !ok {
-					return fmt.Errorf("now option not set")
-				}
-				ctx := r.deps.Inject(context.TODO())
-				nowTime, err := now.Function().Call(ctx, nil)
+			if to, ok := se.Value.(*flux.TableObject); ok {
+				s, err := r.spec(to)
 				if err != nil {
 					return err
 				}
-				s, err := spec.FromTableObject(r.ctx, t, nowTime.Time().Time())
-				if err != nil {
-					return err
+				var key *resultcache.Key
+				if r.cache != nil {
+					k := resultcache.NewKey(strings.Join(r.history, "\n"), s.Now, map[string]string{
+						"statement": strconv.Itoa(i),
+					})
+					key = &k
 				}
-				if err := r.doQuery(r.ctx, s, r.deps); err != nil {
-					return err
+				if err := r.doQuery(r.ctx, s, r.deps, key); err != nil {
+					return errors.WithSourceFrames(err, t)
 				}
 			} else {
 				values.Display(os.Stdout, se.Value)
@@ -197,8 +569,150 @@ func (r *REPL) executeLine(t string) error {
 	return nil
 }
 
+// spec creates the query specification for the table object
+// using the current value of the now option.
+func (r *REPL) spec(t *flux.TableObject) (*flux.Spec, error) {
+	now, ok := r.scope.Lookup("now")
+	if !ok {
+		return nil, fmt.Errorf("now option not set")
+	}
+	ctx := r.deps.Inject(r.ctx)
+	nowTime, err := now.Function().Call(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return spec.FromTableObject(r.ctx, t, nowTime.Time().Time())
+}
+
+// Explain evaluates the input and writes the plan of each query
+// it produces to w without executing the queries.
+// The format is "text", "json" or "dot". The text and JSON formats
+// describe the physical plan and the DOT format describes both
+// the logical and the physical plan in a single graph.
+func (r *REPL) Explain(w io.Writer, t string, format string) error {
+	if format != "text" && format != "json" && format != "dot" {
+		return fmt.Errorf("unknown explain format %q", format)
+	}
+
+	ses, err := r.Eval(t)
+	if err != nil {
+		return err
+	}
+	for _, se := range ses {
+		if _, ok := se.Node.(*semantic.ExpressionStatement); !ok {
+			continue
+		}
+		to, ok := se.Value.(*flux.TableObject)
+		if !ok {
+			continue
+		}
+		s, err := r.spec(to)
+		if err != nil {
+			return err
+		}
+		ctx, err := r.planContext(r.ctx)
+		if err != nil {
+			return err
+		}
+		var trace *plan.Trace
+		if r.planTrace != nil {
+			trace = &plan.Trace{}
+			ctx = plan.WithTrace(ctx, trace)
+		}
+		lp := plan.NewLogicalPlanner()
+		ip, err := lp.CreateInitialPlan(s)
+		if err != nil {
+			return err
+		}
+		ps, err := lp.Plan(ctx, ip)
+		if err != nil {
+			return err
+		}
+		// The logical plan is explained before the physical
+		// planner rewrites it.
+		logical := plan.Explain(ps)
+		if ps, err = plan.NewPhysicalPlanner().Plan(ctx, ps); err != nil {
+			return err
+		}
+		e := plan.Explain(ps)
+		switch format {
+		case "json":
+			err = e.WriteJSON(w)
+		case "dot":
+			err = plan.WriteDOTPlans(w, logical, e)
+		default:
+			err = e.WriteText(w)
+		}
+		if err != nil {
+			return err
+		}
+		if trace == nil {
+			continue
+		}
+		if format == "json" {
+			err = trace.WriteJSON(r.planTrace)
+		} else {
+			err = trace.WriteText(r.planTrace)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reloadPackages imports the packages that changed on disk again
+// and binds the names bound to the old packages to the new ones.
+// A package that fails to import is imported again by the next call
+// so the session picks up the fix.
+func (r *REPL) reloadPackages() error {
+	imp, ok := r.importer.(*runtime.PathImporter)
+	if !ok {
+		return nil
+	}
+	r.stalePackages = append(r.stalePackages, imp.Reload()...)
+	for len(r.stalePackages) > 0 {
+		old := r.stalePackages[0]
+		var names []string
+		r.scope.Range(func(k string, v values.Value) {
+			if v == values.Value(old) {
+				names = append(names, k)
+			}
+		})
+		sort.Strings(names)
+		for _, name := range names {
+			// The import is analyzed and evaluated like an input
+			// so the analyzer also learns the type of the new package.
+			pkg, err := r.analyzeLine(fmt.Sprintf("import %s %q", name, old.Path()))
+			if err != nil {
+				return fmt.Errorf("failed to reload package %q: %v", old.Path(), err)
+			}
+			if _, err := r.itrp.Eval(r.ctx, pkg, r.scope, r.importer); err != nil {
+				return fmt.Errorf("failed to reload package %q: %v", old.Path(), err)
+			}
+		}
+		r.stalePackages = r.stalePackages[1:]
+	}
+	return nil
+}
+
+// setDir sets the directory that relative imports are resolved
+// from and returns a function that restores the previous one.
+func (r *REPL) setDir(dir string) func() {
+	prev := r.dir
+	r.dir = dir
+	return func() { r.dir = prev }
+}
+
 func (r *REPL) analyzeLine(t string) (*semantic.Package, error) {
-	pkg, err := r.analyzer.Analyze(libflux.ParseString(t))
+	astPkg := libflux.ParseString(t)
+	if imp, ok := r.importer.(*runtime.PathImporter); ok {
+		var err error
+		if astPkg, err = imp.Resolve(astPkg, r.dir); err != nil {
+			return nil, err
+		}
+	}
+	pkg, err := r.analyzer.Analyze(astPkg)
 	if err != nil {
 		return nil, err
 	}
@@ -207,10 +721,37 @@ func (r *REPL) analyzeLine(t string) (*semantic.Package, error) {
 	if err != nil {
 		return nil, err
 	}
-	return semantic.DeserializeFromFlatBuffer(bs)
+	semPkg, err := semantic.DeserializeFromFlatBuffer(bs)
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.CheckOptions(semPkg); err != nil {
+		return nil, err
+	}
+	return semPkg, nil
 }
 
-func (r *REPL) doQuery(ctx context.Context, spec *flux.Spec, deps flux.Dependencies) error {
+// doQuery executes the query and prints its results.
+// If the key is set, the results are read from and written to the result cache.
+func (r *REPL) doQuery(ctx context.Context, spec *flux.Spec, deps flux.Dependencies, key *resultcache.Key) (err error) {
+	if key != nil {
+		data, ok, err := r.cache.Get(*key)
+		if err != nil {
+			return err
+		}
+		if ok {
+			results, err := resultcache.DecodeResults(data)
+			if err != nil {
+				return err
+			}
+			return printResults(results)
+		}
+	}
+
+	if r.timeout > 0 {
+		ctx = deadline.Inject(ctx, r.timeout)
+	}
+
 	// Setup cancel context
 	ctx, cancelFunc := context.WithCancel(ctx)
 	r.setCancel(cancelFunc)
@@ -221,30 +762,118 @@ func (r *REPL) doQuery(ctx context.Context, spec *flux.Spec, deps flux.Dependenc
 		Spec: spec,
 	}
 
+	ctx, err = r.planContext(ctx)
+	if err != nil {
+		return err
+	}
 	program, err := c.Compile(ctx, runtime.Default)
 	if err != nil {
 		return err
 	}
+	var qry flux.Query
+	if p, ok := program.(*lang.Program); ok && r.incremental != nil {
+		if err := r.incremental.prepare(ctx, p.PlanSpec); err != nil {
+			return err
+		}
+		// The captured tables are committed once the query is
+		// done, which waits for every capture to finish.
+		defer func() {
+			if qry != nil {
+				qry.Done()
+			}
+			r.incremental.commit(err == nil)
+		}()
+	}
 	alloc := &memory.Allocator{}
 
-	qry, err := program.Start(deps.Inject(ctx), alloc)
+	// Profilers collect data for a single query
+	// so they are configured again for each query.
+	if len(r.profilers) > 0 && execute.HaveExecutionDependencies(ctx) {
+		(&lang.ExecOptsConfig{}).ConfigureProfiler(ctx, r.profilers)
+	}
+
+	qry, err = program.Start(deps.Inject(ctx), alloc)
 	if err != nil {
 		return err
 	}
 	defer qry.Done()
 
-	for result := range qry.Results() {
-		tables := result.Tables()
-		fmt.Println("Result:", result.Name())
-		if err := tables.Do(func(tbl flux.Table) error {
-			_, err := execute.NewFormatter(tbl, nil).WriteTo(os.Stdout)
-			return err
-		}); err != nil {
+	if key == nil {
+		for result := range qry.Results() {
+			if err := execute.FormatResult(os.Stdout, result); err != nil {
+				return err
+			}
+		}
+		qry.Done()
+		if err := qry.Err(); err != nil {
 			return err
 		}
+		return r.printProfilerResults(ctx, qry)
 	}
+
+	// The results are encoded so they can be cached and then
+	// the encoded results are printed so the output is the
+	// same whether or not the results came from the cache.
+	data, err := resultcache.EncodeResults(flux.NewResultIteratorFromQuery(qry))
 	qry.Done()
-	return qry.Err()
+	if err != nil {
+		return err
+	} else if err := qry.Err(); err != nil {
+		return err
+	}
+	if err := r.cache.Set(*key, data); err != nil {
+		return err
+	}
+	results, err := resultcache.DecodeResults(data)
+	if err != nil {
+		return err
+	}
+	if err := printResults(results); err != nil {
+		return err
+	}
+	return r.printProfilerResults(ctx, qry)
+}
+
+func printResults(results flux.ResultIterator) error {
+	defer results.Release()
+	for results.More() {
+		if err := execute.FormatResult(os.Stdout, results.Next()); err != nil {
+			return err
+		}
+	}
+	return results.Err()
+}
+
+// printProfilerResults prints the results of any profilers enabled for
+// the query and writes the operator profile to the profile output.
+func (r *REPL) printProfilerResults(ctx context.Context, qry flux.Query) error {
+	if len(r.profilers) == 0 {
+		return nil
+	}
+	if r.profileOutput != "" && execute.HaveExecutionDependencies(ctx) {
+		if p := execute.GetExecutionDependencies(ctx).ExecutionOptions.OperatorProfiler; p != nil {
+			if err := writeProfile(r.profileOutput, p); err != nil {
+				return err
+			}
+		}
+	}
+	results, err := qry.ProfilerResults()
+	if err != nil || results == nil {
+		return err
+	}
+	return printResults(results)
+}
+
+func writeProfile(path string, p *execute.OperatorProfiler) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := p.WriteProfile(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 func getFluxFiles(path string) ([]string, error) {
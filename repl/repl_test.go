@@ -0,0 +1,104 @@
+package repl_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/repl"
+	"github.com/influxdata/flux/runtime"
+)
+
+// recordingImporter imports the packages of the
+// standard library and records their paths.
+type recordingImporter struct {
+	mu    sync.Mutex
+	paths []string
+}
+
+func (imp *recordingImporter) ImportPackageObject(path string) (*interpreter.Package, error) {
+	imp.mu.Lock()
+	imp.paths = append(imp.paths, path)
+	imp.mu.Unlock()
+	return runtime.StdLib().ImportPackageObject(path)
+}
+
+func (imp *recordingImporter) imported(path string) bool {
+	imp.mu.Lock()
+	defer imp.mu.Unlock()
+	for _, p := range imp.paths {
+		if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+func evalValue(t *testing.T, r *repl.REPL, script string) string {
+	t.Helper()
+	ses, err := r.Eval(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ses) == 0 {
+		t.Fatalf("script %q has no value", script)
+	}
+	return ses[len(ses)-1].Value.Str()
+}
+
+func TestNewWithOptions_Importer(t *testing.T) {
+	imp := &recordingImporter{}
+	ctx := context.Background()
+	r, err := repl.NewWithOptions(ctx, flux.NewDefaultDependencies(), repl.Options{
+		Importer: imp,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range runtime.PreludeList {
+		if !imp.imported(path) {
+			t.Errorf("prelude package %q was not imported with the importer", path)
+		}
+	}
+
+	if want, got := "A", evalValue(t, r, "import \"strings\"\nstrings.toUpper(v: \"a\")"); want != got {
+		t.Errorf("unexpected value -want/+got:\n\t- %q\n\t+ %q", want, got)
+	}
+	if !imp.imported("strings") {
+		t.Error("package imported by the script was not imported with the importer")
+	}
+}
+
+func TestNewWithOptions_Prelude(t *testing.T) {
+	imp := &recordingImporter{}
+	ctx := context.Background()
+	r, err := repl.NewWithOptions(ctx, flux.NewDefaultDependencies(), repl.Options{
+		Importer: imp,
+		Prelude:  []string{"universe"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := []string{"universe"}, imp.paths; !cmp.Equal(want, got) {
+		t.Errorf("unexpected imports -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	// The values of the prelude are in scope.
+	if want, got := "2", evalValue(t, r, `string(v: length(arr: [1, 2]))`); want != got {
+		t.Errorf("unexpected value -want/+got:\n\t- %q\n\t+ %q", want, got)
+	}
+	// The values of the packages that were left out are not.
+	if _, err := r.Eval(`buckets()`); err == nil {
+		t.Error("expected an error referring to a value that is not in the prelude")
+	}
+
+	if _, err := repl.NewWithOptions(ctx, flux.NewDefaultDependencies(), repl.Options{
+		Prelude: []string{"no/such/package"},
+	}); err == nil {
+		t.Error("expected an error for a prelude package that does not exist")
+	}
+}
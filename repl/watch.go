@@ -0,0 +1,79 @@
+package repl
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultWatchInterval is how often a watched file is checked for changes.
+const DefaultWatchInterval = 500 * time.Millisecond
+
+// Watch executes the script in the file and executes it again each time the
+// file changes until the context is canceled or the watch is interrupted.
+//
+// Each execution evaluates the script in a new scope, as if it was executed
+// on its own, but the tables produced by the parts of the script that read
+// from static sources and did not change are reused from the previous
+// execution. Errors from an execution are printed and do not stop the watch.
+func (r *REPL) Watch(ctx context.Context, path string, interval time.Duration) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	r.setCancel(cancel)
+	defer r.clearCancel()
+
+	exec := newIncrementalExecutor()
+	defer exec.release()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last os.FileInfo
+	for {
+		fi, err := os.Stat(path)
+		if err != nil && last == nil {
+			return err
+		}
+		// Editors often replace a file when it is saved so an error
+		// after the first execution is ignored until the file exists.
+		if err == nil && (last == nil || !fi.ModTime().Equal(last.ModTime()) || fi.Size() != last.Size()) {
+			last = fi
+			fmt.Printf("==> %s (%s)\n", path, fi.ModTime().Format(time.RFC3339))
+			start := time.Now()
+			if err := r.watchExecute(ctx, path, exec); err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				fmt.Println("Error:", err)
+			} else {
+				fmt.Printf("==> done in %v\n", time.Since(start).Round(time.Millisecond))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// watchExecute executes the script in the file in a new
+// REPL that shares the configuration of this REPL.
+func (r *REPL) watchExecute(ctx context.Context, path string, exec *incrementalExecutor) error {
+	script, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	w := New(ctx, r.deps)
+	w.profilers = r.profilers
+	w.profileOutput = r.profileOutput
+	w.cache = r.cache
+	w.timeout = r.timeout
+	w.incremental = exec
+	w.dir = filepath.Dir(path)
+	return w.executeLine(string(script))
+}
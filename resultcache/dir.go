@@ -0,0 +1,57 @@
+package resultcache
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Dir is a Cache that stores each value as a file in a directory.
+// It persists values between processes and is typically used as
+// the next Cache of an LRU.
+type Dir struct {
+	path string
+}
+
+// NewDir creates a Dir that stores values in the directory at path.
+// The directory is created if it does not exist.
+func NewDir(path string) (*Dir, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	return &Dir{path: path}, nil
+}
+
+func (d *Dir) Get(key Key) ([]byte, bool, error) {
+	value, err := ioutil.ReadFile(d.filename(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (d *Dir) Set(key Key, value []byte) error {
+	// Write to a temporary file and rename it so a concurrent
+	// reader never observes a partially written value.
+	f, err := ioutil.TempFile(d.path, key.String()+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(value); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		_ = os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), d.filename(key))
+}
+
+func (d *Dir) filename(key Key) string {
+	return filepath.Join(d.path, key.String())
+}
@@ -0,0 +1,101 @@
+package resultcache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// LRU is an in-memory Cache that evicts the least recently used
+// entries when the size of the stored values exceeds a byte budget.
+//
+// An LRU may be layered on top of another Cache, such as a Dir.
+// Values are written through to the next Cache and values that
+// are missing from memory are read from the next Cache.
+type LRU struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	entries  map[Key]*list.Element
+	order    *list.List
+	next     Cache
+}
+
+type lruEntry struct {
+	key   Key
+	value []byte
+}
+
+// NewLRU creates an LRU that stores up to maxBytes of values in memory.
+// The next Cache is optional and may be nil.
+func NewLRU(maxBytes int64, next Cache) *LRU {
+	return &LRU{
+		maxBytes: maxBytes,
+		entries:  make(map[Key]*list.Element),
+		order:    list.New(),
+		next:     next,
+	}
+}
+
+func (c *LRU) Get(key Key) ([]byte, bool, error) {
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		value := e.Value.(*lruEntry).value
+		c.mu.Unlock()
+		return value, true, nil
+	}
+	c.mu.Unlock()
+
+	if c.next == nil {
+		return nil, false, nil
+	}
+	value, ok, err := c.next.Get(key)
+	if err != nil || !ok {
+		return nil, false, err
+	}
+	c.add(key, value)
+	return value, true, nil
+}
+
+func (c *LRU) Set(key Key, value []byte) error {
+	c.add(key, value)
+	if c.next != nil {
+		return c.next.Set(key, value)
+	}
+	return nil
+}
+
+// Size returns the number of bytes stored in memory.
+func (c *LRU) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *LRU) add(key Key, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.remove(e)
+	}
+	// A value that is larger than the entire budget
+	// would evict everything else, so it is not kept.
+	if int64(len(value)) > c.maxBytes {
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{
+		key:   key,
+		value: value,
+	})
+	c.size += int64(len(value))
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *LRU) remove(e *list.Element) {
+	entry := c.order.Remove(e).(*lruEntry)
+	delete(c.entries, entry.key)
+	c.size -= int64(len(entry.value))
+}
@@ -0,0 +1,82 @@
+// Package resultcache implements a cache for the encoded results of a query.
+//
+// Results are keyed by the normalized text of the query, any parameters that
+// influence the results, and the time that was used for now(). This makes the
+// cache useful when the same query is executed repeatedly with a fixed now,
+// such as when iterating on a query in the REPL.
+package resultcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/csv"
+)
+
+// Key identifies a set of cached results.
+type Key [sha256.Size]byte
+
+// NewKey creates a Key from the normalized text of a script, the time
+// used for now() and any parameters that influence the results.
+func NewKey(script string, now time.Time, params map[string]string) Key {
+	h := sha256.New()
+	writeString := func(s string) {
+		var n [binary.MaxVarintLen64]byte
+		_, _ = h.Write(n[:binary.PutUvarint(n[:], uint64(len(s)))])
+		_, _ = h.Write([]byte(s))
+	}
+	writeString(script)
+	writeString(now.UTC().Format(time.RFC3339Nano))
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		writeString(name)
+		writeString(params[name])
+	}
+
+	var k Key
+	copy(k[:], h.Sum(nil))
+	return k
+}
+
+// String returns the Key encoded as a hex string.
+func (k Key) String() string {
+	return hex.EncodeToString(k[:])
+}
+
+// Cache stores encoded results.
+type Cache interface {
+	// Get returns the encoded results for the key.
+	// The boolean reports whether the key was found.
+	Get(key Key) ([]byte, bool, error)
+
+	// Set stores the encoded results for the key.
+	Set(key Key, value []byte) error
+}
+
+// EncodeResults encodes the results so they can be stored in a Cache.
+// The results are consumed by encoding them.
+func EncodeResults(results flux.ResultIterator) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := csv.NewMultiResultEncoder(csv.DefaultEncoderConfig())
+	if _, err := enc.Encode(&buf, results); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeResults decodes results that were encoded with EncodeResults.
+func DecodeResults(data []byte) (flux.ResultIterator, error) {
+	dec := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{})
+	return dec.Decode(ioutil.NopCloser(bytes.NewReader(data)))
+}
@@ -0,0 +1,145 @@
+package resultcache_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/resultcache"
+)
+
+var now = time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+func TestNewKey(t *testing.T) {
+	k := resultcache.NewKey("a", now, map[string]string{"x": "1", "y": "2"})
+	if got := resultcache.NewKey("a", now, map[string]string{"y": "2", "x": "1"}); got != k {
+		t.Error("expected key to be independent of the parameter order")
+	}
+	for _, other := range []resultcache.Key{
+		resultcache.NewKey("b", now, map[string]string{"x": "1", "y": "2"}),
+		resultcache.NewKey("a", now.Add(time.Second), map[string]string{"x": "1", "y": "2"}),
+		resultcache.NewKey("a", now, map[string]string{"x": "12"}),
+		resultcache.NewKey("a", now, nil),
+	} {
+		if other == k {
+			t.Errorf("expected key %s to differ", other)
+		}
+	}
+}
+
+func TestLRU(t *testing.T) {
+	c := resultcache.NewLRU(10, nil)
+	k1 := resultcache.NewKey("1", now, nil)
+	k2 := resultcache.NewKey("2", now, nil)
+	k3 := resultcache.NewKey("3", now, nil)
+
+	mustSet(t, c, k1, "aaaa")
+	mustSet(t, c, k2, "bbbb")
+	// Use k1 so k2 is the least recently used.
+	mustGet(t, c, k1, "aaaa")
+	mustSet(t, c, k3, "cccc")
+
+	if _, ok, _ := c.Get(k2); ok {
+		t.Error("expected least recently used key to be evicted")
+	}
+	mustGet(t, c, k1, "aaaa")
+	mustGet(t, c, k3, "cccc")
+	if want, got := int64(8), c.Size(); want != got {
+		t.Errorf("unexpected size -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	// Values larger than the budget are not stored.
+	k4 := resultcache.NewKey("4", now, nil)
+	mustSet(t, c, k4, "ddddddddddd")
+	if _, ok, _ := c.Get(k4); ok {
+		t.Error("expected value larger than the budget to be ignored")
+	}
+	mustGet(t, c, k1, "aaaa")
+}
+
+func TestLRU_Dir(t *testing.T) {
+	path, err := ioutil.TempDir("", "resultcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(path)
+
+	dir, err := resultcache.NewDir(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := resultcache.NewKey("1", now, nil)
+	mustSet(t, resultcache.NewLRU(10, dir), k, "aaaa")
+
+	// A new LRU reads the value that was written through to the directory.
+	c := resultcache.NewLRU(10, dir)
+	mustGet(t, c, k, "aaaa")
+	if want, got := int64(4), c.Size(); want != got {
+		t.Errorf("unexpected size -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+	if _, ok, err := c.Get(resultcache.NewKey("2", now, nil)); err != nil || ok {
+		t.Errorf("expected missing key, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestEncodeResults(t *testing.T) {
+	want := strings.Join([]string{
+		"#datatype,string,long,string,long",
+		"#group,false,false,true,false",
+		"#default,_result,,,",
+		",result,table,t0,_value",
+		",,0,a,1",
+		",,0,a,2",
+		"",
+		"",
+	}, "\r\n")
+	results, err := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{}).
+		Decode(ioutil.NopCloser(strings.NewReader(want)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := resultcache.EncodeResults(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := resultcache.DecodeResults(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := resultcache.EncodeResults(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, got) {
+		t.Errorf("unexpected results after decoding -want/+got:\n\t- %q\n\t+ %q", data, got)
+	}
+	if !strings.Contains(string(data), ",,0,a,2") {
+		t.Errorf("unexpected encoded results: %q", data)
+	}
+}
+
+func mustSet(t *testing.T, c resultcache.Cache, k resultcache.Key, value string) {
+	t.Helper()
+	if err := c.Set(k, []byte(value)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustGet(t *testing.T, c resultcache.Cache, k resultcache.Key, want string) {
+	t.Helper()
+	got, ok, err := c.Get(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Fatalf("expected key %s to be present", k)
+	}
+	if string(got) != want {
+		t.Errorf("unexpected value -want/+got:\n\t- %q\n\t+ %q", want, got)
+	}
+}
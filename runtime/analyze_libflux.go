@@ -16,14 +16,39 @@ func AnalyzeSource(fluxSrc string) (*semantic.Package, error) {
 func AnalyzePackage(astPkg flux.ASTHandle) (*semantic.Package, error) {
 	hdl := astPkg.(*libflux.ASTPkg)
 	defer hdl.Free()
-	sem, err := libflux.Analyze(hdl)
-	if err != nil {
-		return nil, err
+	var sem *libflux.SemanticPkg
+	if len(Default.sourcePaths) == 0 {
+		var err error
+		if sem, err = libflux.Analyze(hdl); err != nil {
+			return nil, err
+		}
+	} else {
+		analyzer, err := NewAnalyzer()
+		if err != nil {
+			return nil, err
+		}
+		defer analyzer.Free()
+		if sem, err = analyzer.Analyze(hdl); err != nil {
+			return nil, err
+		}
 	}
 	defer sem.Free()
 	bs, err := sem.MarshalFB()
 	if err != nil {
 		return nil, err
 	}
-	return semantic.DeserializeFromFlatBuffer(bs)
+	semPkg, err := semantic.DeserializeFromFlatBuffer(bs)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckOptions(semPkg); err != nil {
+		return nil, err
+	}
+	return semPkg, nil
+}
+
+// NewAnalyzer returns an analyzer for scripts that may import
+// the packages registered with RegisterPackage.
+func NewAnalyzer() (*libflux.Analyzer, error) {
+	return Default.newAnalyzer()
 }
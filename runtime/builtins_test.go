@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/libflux/go/libflux"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
 )
@@ -118,3 +119,80 @@ func Test_options(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestRegisterPackage(t *testing.T) {
+	const geo = `
+package geo
+
+builtin area : (lat: [float], lon: [float]) => float
+`
+	const alerts = `
+package alerts
+
+import "mycompany/geo"
+
+large = (lat, lon) => geo.area(lat, lon) > 100.0
+`
+	area := values.NewFunction("area", semantic.BasicFloat, nil, false)
+
+	r := &runtime{}
+	if err := r.RegisterPackage("mycompany/geo", geo); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RegisterPackage("mycompany/alerts", alerts); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RegisterPackage("mycompany/geo", geo); err == nil {
+		t.Error("expected error registering a package twice")
+	}
+	if err := r.RegisterPackage("strings", "package strings\n"); err == nil {
+		t.Error("expected error registering a standard library package")
+	}
+	if err := r.RegisterPackage("mycompany/invalid", "package invalid\n\nx = ("); err == nil {
+		t.Error("expected error registering an invalid package")
+	}
+	if err := r.RegisterPackageValue("mycompany/geo", "area", area); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Finalize(); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"mycompany/geo", "mycompany/alerts"} {
+		if _, ok := r.pkgs[path]; !ok {
+			t.Errorf("package %q was not compiled", path)
+		}
+	}
+	if err := r.RegisterPackage("mycompany/late", "package late\n"); err == nil {
+		t.Error("expected error registering a package after finalizing")
+	}
+
+	analyzer, err := r.newAnalyzer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer analyzer.Free()
+	if _, err := analyzer.Analyze(libflux.ParseString(`
+import "mycompany/alerts"
+
+alerts.large(lat: [1.0], lon: [2.0])
+`)); err != nil {
+		t.Errorf("unexpected error analyzing a script importing a registered package: %v", err)
+	}
+	if _, err := analyzer.Analyze(libflux.ParseString(`
+import "mycompany/geo"
+
+geo.area(lat: 1.0, lon: 2.0)
+`)); err == nil {
+		t.Error("expected type error calling a registered builtin")
+	}
+}
+
+func TestRegisterPackage_MissingValue(t *testing.T) {
+	r := &runtime{}
+	if err := r.RegisterPackage("mycompany/geo", "package geo\n\nbuiltin area : (lat: [float], lon: [float]) => float\n"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Finalize(); err == nil {
+		t.Error("expected error finalizing a package without the values of its builtins")
+	}
+}
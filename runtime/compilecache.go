@@ -0,0 +1,204 @@
+package runtime
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/internal/feature"
+	pkgfeature "github.com/influxdata/flux/internal/pkg/feature"
+	"github.com/influxdata/flux/libflux/go/libflux"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+)
+
+type compileCacheKey struct{}
+
+// CompileCache caches the semantic graphs of the scripts evaluated
+// by the runtime, so evaluating the same script again, such as a task
+// or a watched file, does not analyze it again. It also caches the
+// physical plans of the queries, so the same query is not planned again.
+//
+// Scripts are keyed by a hash of their syntax tree, the packages
+// registered with the runtime and the values of the feature flags,
+// so a change to any of these compiles the script again. Plans are
+// keyed by plan.CacheKey, which includes the time of the query
+// because the procedures of a plan may depend on it.
+//
+// The semantic graph of a script is shared by every evaluation of the
+// script and must not be modified. Each query is given its own copy
+// of a cached plan.
+type CompileCache struct {
+	scripts *lruCache
+	plans   *lruCache
+}
+
+// NewCompileCache creates a CompileCache that keeps the semantic
+// graphs of up to maxEntries scripts and the plans of up to maxEntries
+// queries, evicting the least recently used ones.
+func NewCompileCache(maxEntries int) *CompileCache {
+	return &CompileCache{
+		scripts: newLRUCache(maxEntries),
+		plans:   newLRUCache(maxEntries),
+	}
+}
+
+// Inject will inject the CompileCache into the dependency chain.
+func (c *CompileCache) Inject(ctx context.Context) context.Context {
+	return context.WithValue(ctx, compileCacheKey{}, c)
+}
+
+// GetCompileCache returns the CompileCache injected into the
+// context, or nil if there is none.
+func GetCompileCache(ctx context.Context) *CompileCache {
+	c, _ := ctx.Value(compileCacheKey{}).(*CompileCache)
+	return c
+}
+
+// Len returns the number of scripts in the cache.
+func (c *CompileCache) Len() int {
+	return c.scripts.len()
+}
+
+// Purge removes every script and plan from the cache.
+func (c *CompileCache) Purge() {
+	c.scripts.purge()
+	c.plans.purge()
+}
+
+// Plan returns a copy of the physical plan of spec from the cache,
+// or plans spec with plan and caches the result. The plan is not
+// cached if plan.CacheKey cannot compute a key for spec.
+// A nil CompileCache plans every spec.
+func (c *CompileCache) Plan(ctx context.Context, spec *flux.Spec, planFn func(context.Context, *flux.Spec) (*plan.Spec, error)) (*plan.Spec, error) {
+	if c == nil {
+		return planFn(ctx, spec)
+	}
+	key, ok := plan.CacheKey(ctx, spec)
+	if !ok {
+		return planFn(ctx, spec)
+	}
+	if ps, ok := c.plans.get(key); ok {
+		return ps.(*plan.Spec).Copy(), nil
+	}
+	ps, err := planFn(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	// The cached plan is a copy, so the query
+	// cannot modify it while it is executed.
+	c.plans.add(key, ps.Copy())
+	return ps, nil
+}
+
+// lruCache is a set of values keyed by hashes that
+// evicts the least recently used values.
+type lruCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[[sha256.Size]byte]*list.Element
+	order      *list.List
+}
+
+type lruEntry struct {
+	key   [sha256.Size]byte
+	value interface{}
+}
+
+func newLRUCache(maxEntries int) *lruCache {
+	return &lruCache{
+		maxEntries: maxEntries,
+		entries:    make(map[[sha256.Size]byte]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *lruCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *lruCache) purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[[sha256.Size]byte]*list.Element)
+	c.order.Init()
+}
+
+func (c *lruCache) get(key [sha256.Size]byte) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return e.Value.(*lruEntry).value, true
+}
+
+func (c *lruCache) add(key [sha256.Size]byte, value interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxEntries <= 0 {
+		return
+	}
+	if e, ok := c.entries[key]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, value: value})
+	for c.order.Len() > c.maxEntries {
+		entry := c.order.Remove(c.order.Back()).(*lruEntry)
+		delete(c.entries, entry.key)
+	}
+}
+
+// compileCacheKeyFor computes the key of the script
+// with the packages and feature flags in effect.
+func (r *runtime) compileCacheKeyFor(ctx context.Context, astPkg flux.ASTHandle) ([sha256.Size]byte, error) {
+	bs, err := astPkg.(*libflux.ASTPkg).MarshalFB()
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	h := sha256.New()
+	_, _ = h.Write(bs)
+	for _, path := range r.sourcePaths {
+		_, _ = fmt.Fprintf(h, "\x00%s\x00%s", path, r.sources[path])
+	}
+	flagger := pkgfeature.GetFlagger(ctx)
+	for _, flag := range feature.Flags() {
+		_, _ = fmt.Fprintf(h, "\x00%s=%v", flag.Key(), flagger.FlagValue(ctx, flag))
+	}
+	var key [sha256.Size]byte
+	copy(key[:], h.Sum(nil))
+	return key, nil
+}
+
+// analyzeCached analyzes the script, or returns its semantic
+// graph from the CompileCache in the context if there is one.
+func (r *runtime) analyzeCached(ctx context.Context, astPkg flux.ASTHandle) (*semantic.Package, error) {
+	c := GetCompileCache(ctx)
+	if c == nil {
+		return AnalyzePackage(astPkg)
+	}
+	key, err := r.compileCacheKeyFor(ctx, astPkg)
+	if err != nil {
+		return nil, err
+	}
+	if semPkg, ok := c.scripts.get(key); ok {
+		// AnalyzePackage takes ownership of the syntax
+		// tree, so it is released here instead.
+		astPkg.(*libflux.ASTPkg).Free()
+		return semPkg.(*semantic.Package), nil
+	}
+	semPkg, err := AnalyzePackage(astPkg)
+	if err != nil {
+		return nil, err
+	}
+	c.scripts.add(key, semPkg)
+	return semPkg, nil
+}
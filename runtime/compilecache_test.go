@@ -0,0 +1,51 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux/semantic"
+)
+
+func TestCompileCache(t *testing.T) {
+	c := NewCompileCache(2)
+	ctx := c.Inject(context.Background())
+
+	eval := func(src string) *semantic.Package {
+		t.Helper()
+		astPkg, err := Parse(src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		semPkg, err := Default.analyzeCached(ctx, astPkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return semPkg
+	}
+
+	a := eval(`x = 1`)
+	if got := eval(`x = 1`); got != a {
+		t.Error("expected the script to be read from the cache")
+	}
+	if got := eval(`x = 2`); got == a {
+		t.Error("expected a different script to be analyzed")
+	}
+	if want, got := 2, c.Len(); want != got {
+		t.Errorf("unexpected number of scripts -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	// The least recently used script is evicted.
+	eval(`x = 3`)
+	if want, got := 2, c.Len(); want != got {
+		t.Errorf("unexpected number of scripts -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+	if got := eval(`x = 1`); got == a {
+		t.Error("expected the evicted script to be analyzed again")
+	}
+
+	c.Purge()
+	if want, got := 0, c.Len(); want != got {
+		t.Errorf("unexpected number of scripts -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+}
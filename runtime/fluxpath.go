@@ -0,0 +1,472 @@
+package runtime
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/libflux/go/libflux"
+	"github.com/influxdata/flux/semantic"
+)
+
+// FluxPathEnv is the environment variable that lists the directories
+// searched for packages that are not part of the standard library.
+// The directories are separated like the directories in PATH.
+const FluxPathEnv = "FLUXPATH"
+
+// FluxPath returns the directories listed in FLUXPATH.
+func FluxPath() []string {
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv(FluxPathEnv)) {
+		if dir != "" {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+type fluxPathKey struct{}
+
+// WithFluxPath returns a context where the scripts evaluated by the
+// runtime may import packages from the directories, like the packages
+// in FLUXPATH. The packages are imported again for each script, so a
+// service that evaluates the scripts of many clients picks up the
+// changes to their files and the clients do not share their options.
+func WithFluxPath(ctx context.Context, dirs []string) context.Context {
+	return context.WithValue(ctx, fluxPathKey{}, dirs)
+}
+
+func fluxPathFromContext(ctx context.Context) []string {
+	dirs, _ := ctx.Value(fluxPathKey{}).([]string)
+	return dirs
+}
+
+// PathImporter imports packages from the .flux files in a list of directories.
+// The package with the import path "a/b" is made of the files in the
+// directory "a/b" of the first directory in the list that has it.
+// Files that end with _test.flux are not part of the package.
+// Import paths of the standard library are imported from the standard library.
+//
+// Import paths that start with "./" or "../" are relative to the directory
+// of the importing file. They are only allowed in files loaded from disk:
+// Resolve replaces them with the absolute path of the directory, which is
+// the path the package is imported with. Any other import path must stay
+// inside the directories of the list, so a script cannot import an absolute
+// path or a path that leaves its directory with "..".
+//
+// Packages are type checked with the analyzer given to NewPathImporter,
+// which must also be used to analyze the scripts that import them.
+// Each package is compiled and evaluated once and cached
+// until Reload finds that its files changed.
+type PathImporter struct {
+	dirs     []string
+	analyzer *libflux.Analyzer
+	stdlib   interpreter.Importer
+	// pkgs contains the packages that were imported.
+	// A nil package is being imported and is used
+	// to detect cyclical imports.
+	pkgs map[string]*interpreter.Package
+	// files contains the state of the files
+	// of each package when it was imported.
+	files map[string][]fileState
+	// imports contains the packages that are not part
+	// of the standard library imported by each package.
+	imports map[string][]string
+	// local contains the absolute paths that relative
+	// imports of files loaded from disk were resolved to.
+	// They are the only absolute paths that can be imported.
+	local map[string]bool
+}
+
+// fileState is the state of a file that is compared
+// to find the files that changed.
+type fileState struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+// NewPathImporter creates an importer for the packages in the directories.
+func NewPathImporter(dirs []string, analyzer *libflux.Analyzer) *PathImporter {
+	return &PathImporter{
+		dirs:     dirs,
+		analyzer: analyzer,
+		stdlib:   StdLib(),
+		pkgs:     make(map[string]*interpreter.Package),
+		files:    make(map[string][]fileState),
+		imports:  make(map[string][]string),
+		local:    make(map[string]bool),
+	}
+}
+
+func (imp *PathImporter) ImportPackageObject(path string) (*interpreter.Package, error) {
+	if isStdlib(path) {
+		return imp.stdlib.ImportPackageObject(path)
+	}
+	if p, ok := imp.pkgs[path]; ok {
+		if p == nil {
+			return nil, errors.Newf(codes.Invalid, "detected cyclical import for package path %q", path)
+		}
+		return p, nil
+	}
+	imp.pkgs[path] = nil
+
+	p, err := imp.importPackage(path)
+	if err != nil {
+		delete(imp.pkgs, path)
+		delete(imp.files, path)
+		delete(imp.imports, path)
+		return nil, err
+	}
+	imp.pkgs[path] = p
+	return p, nil
+}
+
+// Reload removes the packages whose files changed since they were
+// imported, and the packages that import them, from the cache so they
+// are imported again the next time they are used. It returns the
+// packages that were removed sorted by path.
+//
+// Values that were already computed from a removed package,
+// such as the package bound to a name in a scope, are not updated.
+func (imp *PathImporter) Reload() []*interpreter.Package {
+	stale := make(map[string]bool)
+	for path, files := range imp.files {
+		if current, err := imp.stat(path); err != nil || !sameFiles(files, current) {
+			stale[path] = true
+		}
+	}
+	// A package that imports a stale package is stale too.
+	for changed := true; changed; {
+		changed = false
+		for path, imports := range imp.imports {
+			if stale[path] {
+				continue
+			}
+			for _, dep := range imports {
+				if stale[dep] {
+					stale[path] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	removed := make([]*interpreter.Package, 0, len(stale))
+	for path := range stale {
+		if p := imp.pkgs[path]; p != nil {
+			removed = append(removed, p)
+		}
+		delete(imp.pkgs, path)
+		delete(imp.files, path)
+		delete(imp.imports, path)
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		return removed[i].Path() < removed[j].Path()
+	})
+	return removed
+}
+
+// Prepare imports the packages imported by the AST that are not part
+// of the standard library so the analyzer can type check the AST.
+// It must be called before the AST is analyzed.
+func (imp *PathImporter) Prepare(astPkg *libflux.ASTPkg) error {
+	paths, err := importPaths(astPkg)
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if isRelativeImport(path) {
+			return errors.Newf(codes.Invalid, "relative import path %q is only allowed in files", path)
+		}
+	}
+	return imp.importAll(paths)
+}
+
+// analyze prepares the packages imported by the script and analyzes
+// it with the analyzer of the importer. The AST is consumed.
+func (imp *PathImporter) analyze(astPkg *libflux.ASTPkg) (*semantic.Package, error) {
+	if err := imp.Prepare(astPkg); err != nil {
+		astPkg.Free()
+		return nil, err
+	}
+	sem, err := imp.analyzer.Analyze(astPkg)
+	if err != nil {
+		return nil, err
+	}
+	defer sem.Free()
+	bs, err := sem.MarshalFB()
+	if err != nil {
+		return nil, err
+	}
+	semPkg, err := semantic.DeserializeFromFlatBuffer(bs)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckOptions(semPkg); err != nil {
+		return nil, err
+	}
+	return semPkg, nil
+}
+
+// Resolve replaces the relative import paths of the AST with the absolute
+// paths of the directories they refer to from dir, which is the directory
+// of the script, and prepares the imported packages like Prepare.
+// The AST is consumed and the resolved AST is returned.
+func (imp *PathImporter) Resolve(astPkg *libflux.ASTPkg, dir string) (*libflux.ASTPkg, error) {
+	astPkg, err := imp.resolveImports(astPkg, dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := imp.Prepare(astPkg); err != nil {
+		return nil, err
+	}
+	return astPkg, nil
+}
+
+func (imp *PathImporter) importAll(paths []string) error {
+	for _, path := range paths {
+		if _, err := imp.ImportPackageObject(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (imp *PathImporter) importPackage(path string) (*interpreter.Package, error) {
+	files, err := imp.stat(path)
+	if err != nil {
+		return nil, err
+	}
+	imp.files[path] = files
+
+	var astPkg *libflux.ASTPkg
+	for _, file := range files {
+		src, err := ioutil.ReadFile(file.name)
+		if err != nil {
+			return nil, err
+		}
+		fileAST := libflux.Parse(file.name, string(src))
+		if err := fileAST.GetError(); err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "failed to parse %s", file.name)
+		}
+		if astPkg == nil {
+			astPkg = fileAST
+			continue
+		}
+		if err := libflux.MergePackages(astPkg, fileAST); err != nil {
+			return nil, err
+		}
+	}
+
+	// The packages imported by this package must be
+	// known to the analyzer before it is analyzed.
+	astPkg, err = imp.resolveImports(astPkg, filepath.Dir(files[0].name))
+	if err != nil {
+		return nil, err
+	}
+	paths, err := importPaths(astPkg)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		if !isStdlib(p) {
+			imp.imports[path] = append(imp.imports[path], p)
+		}
+	}
+	if err := imp.importAll(paths); err != nil {
+		return nil, err
+	}
+
+	sem, err := imp.analyzer.AnalyzePackage(path, astPkg)
+	if err != nil {
+		return nil, errors.Wrapf(err, codes.Inherit, "failed to analyze package %q", path)
+	}
+	defer sem.Free()
+	bs, err := sem.MarshalFB()
+	if err != nil {
+		return nil, err
+	}
+	semPkg, err := semantic.DeserializeFromFlatBuffer(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := Default.newScopeFor(path, imp)
+	if err != nil {
+		return nil, err
+	}
+	itrp := interpreter.NewInterpreter(nil, nil)
+	if _, err := itrp.Eval(context.Background(), semPkg, scope, imp); err != nil {
+		return nil, err
+	}
+	return interpreter.NewPackageWithValues(itrp.PackageName(), path, newObjectFromScope(scope)), nil
+}
+
+// stat returns the state of the files of the package.
+func (imp *PathImporter) stat(path string) ([]fileState, error) {
+	names, err := imp.findFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]fileState, len(names))
+	for i, name := range names {
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		files[i] = fileState{
+			name:    name,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		}
+	}
+	return files, nil
+}
+
+func sameFiles(a, b []fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].name != b[i].name || a[i].size != b[i].size || !a[i].modTime.Equal(b[i].modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// findFiles returns the files of the package in the first directory that has it.
+// The files of a package imported with a relative path are in the directory
+// that the path was resolved to.
+func (imp *PathImporter) findFiles(path string) ([]string, error) {
+	var pkgDirs []string
+	local := imp.local[path]
+	if local {
+		pkgDirs = []string{filepath.FromSlash(path)}
+	} else {
+		if err := checkImportPath(path); err != nil {
+			return nil, err
+		}
+		for _, dir := range imp.dirs {
+			pkgDirs = append(pkgDirs, filepath.Join(dir, filepath.FromSlash(path)))
+		}
+	}
+	for _, pkgDir := range pkgDirs {
+		entries, err := ioutil.ReadDir(pkgDir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		var files []string
+		for _, e := range entries {
+			name := e.Name()
+			if e.IsDir() || !strings.HasSuffix(name, ".flux") || strings.HasSuffix(name, "_test.flux") {
+				continue
+			}
+			files = append(files, filepath.Join(pkgDir, name))
+		}
+		if len(files) > 0 {
+			sort.Strings(files)
+			return files, nil
+		}
+	}
+	if local {
+		return nil, errors.Newf(codes.NotFound, "package %q not found", path)
+	}
+	return nil, errors.Newf(codes.NotFound, "package %q not found in the standard library or %s", path, FluxPathEnv)
+}
+
+func isStdlib(path string) bool {
+	_, ok := Default.pkgs[path]
+	return ok
+}
+
+// isRelativeImport reports whether the import path
+// is relative to the directory of the importing file.
+func isRelativeImport(path string) bool {
+	return strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../")
+}
+
+// checkImportPath returns an error if the import path is absolute or
+// leaves the directory it is joined to once it is cleaned.
+func checkImportPath(path string) error {
+	clean := pathpkg.Clean(filepath.ToSlash(path))
+	if pathpkg.IsAbs(clean) || filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return errors.Newf(codes.Invalid, "import path %q must not be absolute", path)
+	}
+	if clean == ".." || strings.HasPrefix(clean, "../") || isRelativeImport(path) {
+		return errors.Newf(codes.Invalid, "import path %q must not be relative", path)
+	}
+	return nil
+}
+
+// resolveImports replaces the relative import paths of the AST with
+// the absolute paths of the directories they refer to from dir, and
+// allows the importer to import those paths.
+// The AST is consumed if it has relative imports.
+func (imp *PathImporter) resolveImports(astPkg *libflux.ASTPkg, dir string) (*libflux.ASTPkg, error) {
+	bs, err := astPkg.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var pkg ast.Package
+	if err := json.Unmarshal(bs, &pkg); err != nil {
+		return nil, err
+	}
+	resolved := false
+	for _, f := range pkg.Files {
+		for _, decl := range f.Imports {
+			if !isRelativeImport(decl.Path.Value) {
+				continue
+			}
+			abs, err := filepath.Abs(filepath.Join(dir, filepath.FromSlash(decl.Path.Value)))
+			if err != nil {
+				return nil, errors.Wrapf(err, codes.Invalid, "invalid import path %q", decl.Path.Value)
+			}
+			decl.Path.Value = filepath.ToSlash(abs)
+			imp.local[decl.Path.Value] = true
+			resolved = true
+		}
+	}
+	if !resolved {
+		return astPkg, nil
+	}
+	astPkg.Free()
+	if bs, err = json.Marshal(&pkg); err != nil {
+		return nil, err
+	}
+	return libflux.ParseJSON(bs)
+}
+
+// importPaths returns the paths imported by the files of the AST.
+func importPaths(astPkg *libflux.ASTPkg) ([]string, error) {
+	bs, err := astPkg.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var pkg ast.Package
+	if err := json.Unmarshal(bs, &pkg); err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, f := range pkg.Files {
+		for _, imp := range f.Imports {
+			paths = append(paths, imp.Path.Value)
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,284 @@
+package runtime_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/libflux/go/libflux"
+	"github.com/influxdata/flux/runtime"
+)
+
+func writePackage(t *testing.T, dir, path string, files map[string]string) {
+	t.Helper()
+	pkgDir := filepath.Join(dir, filepath.FromSlash(path))
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range files {
+		if err := ioutil.WriteFile(filepath.Join(pkgDir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPathImporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fluxpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writePackage(t, dir, "mycompany/math", map[string]string{
+		"math.flux":      "package math\n\ndouble = (x) => x * 2\n",
+		"math_test.flux": "package math_test\n\nthis = does not parse\n",
+	})
+	writePackage(t, dir, "mycompany/alerts", map[string]string{
+		"alerts.flux": "package alerts\n\nimport \"strings\"\nimport \"mycompany/math\"\n\nlimit = math.double(x: 21)\nname = strings.toUpper(v: \"cpu\")\n",
+	})
+	writePackage(t, dir, "cycle/a", map[string]string{
+		"a.flux": "package a\n\nimport \"cycle/b\"\n\nx = b.x\n",
+	})
+	writePackage(t, dir, "cycle/b", map[string]string{
+		"b.flux": "package b\n\nimport \"cycle/a\"\n\nx = a.x\n",
+	})
+
+	analyzer := libflux.NewAnalyzer()
+	defer analyzer.Free()
+	imp := runtime.NewPathImporter([]string{filepath.Join(dir, "missing"), dir}, analyzer)
+
+	pkg, err := imp.ImportPackageObject("mycompany/alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "alerts", pkg.Name(); want != got {
+		t.Errorf("unexpected package name -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+	if v, ok := pkg.Get("limit"); !ok {
+		t.Error("limit is not defined")
+	} else if want, got := int64(42), v.Int(); want != got {
+		t.Errorf("unexpected limit -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+	if v, ok := pkg.Get("name"); !ok {
+		t.Error("name is not defined")
+	} else if want, got := "CPU", v.Str(); want != got {
+		t.Errorf("unexpected name -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+
+	// The dependency was imported once and is reused.
+	math, err := imp.ImportPackageObject("mycompany/math")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := imp.ImportPackageObject("mycompany/math"); again != math {
+		t.Error("package was imported again")
+	}
+
+	if _, err := imp.ImportPackageObject("mycompany/missing"); err == nil {
+		t.Error("expected error importing a missing package")
+	} else if want, got := codes.NotFound, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+	if _, err := imp.ImportPackageObject("cycle/a"); err == nil {
+		t.Error("expected error importing a cyclical package")
+	}
+}
+
+func TestPathImporter_Relative(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fluxpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writePackage(t, dir, "project/util", map[string]string{
+		"util.flux": "package util\n\nimport \"../lib/math\"\n\nlimit = math.double(x: 21)\n",
+	})
+	writePackage(t, dir, "project/lib/math", map[string]string{
+		"math.flux": "package math\n\ndouble = (x) => x * 2\n",
+	})
+
+	analyzer := libflux.NewAnalyzer()
+	defer analyzer.Free()
+	imp := runtime.NewPathImporter(nil, analyzer)
+
+	astPkg := libflux.ParseString("import \"./util\"\n\nx = util.limit\n")
+	astPkg, err = imp.Resolve(astPkg, filepath.Join(dir, "project"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := analyzer.Analyze(astPkg); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.ToSlash(filepath.Join(dir, "project", "util"))
+	pkg, err := imp.ImportPackageObject(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := pkg.Get("limit"); !ok {
+		t.Error("limit is not defined")
+	} else if want, got := int64(42), v.Int(); want != got {
+		t.Errorf("unexpected limit -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	astPkg = libflux.ParseString("import \"./missing\"\n")
+	if _, err := imp.Resolve(astPkg, dir); err == nil {
+		t.Error("expected error importing a missing package")
+	} else if want, got := codes.NotFound, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
+func TestFluxPath(t *testing.T) {
+	old := os.Getenv(runtime.FluxPathEnv)
+	defer os.Setenv(runtime.FluxPathEnv, old)
+
+	os.Setenv(runtime.FluxPathEnv, "/a"+string(os.PathListSeparator)+string(os.PathListSeparator)+"/b")
+	got := runtime.FluxPath()
+	if len(got) != 2 || got[0] != "/a" || got[1] != "/b" {
+		t.Errorf("unexpected directories: %v", got)
+	}
+}
+
+func TestPathImporter_Reload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fluxpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writePackage(t, dir, "mycompany/math", map[string]string{
+		"math.flux": "package math\n\nfactor = 2\n",
+	})
+	writePackage(t, dir, "mycompany/alerts", map[string]string{
+		"alerts.flux": "package alerts\n\nimport \"mycompany/math\"\n\nlimit = 21 * math.factor\n",
+	})
+	writePackage(t, dir, "mycompany/other", map[string]string{
+		"other.flux": "package other\n\nx = 1\n",
+	})
+
+	analyzer := libflux.NewAnalyzer()
+	defer analyzer.Free()
+	imp := runtime.NewPathImporter([]string{dir}, analyzer)
+	for _, path := range []string{"mycompany/alerts", "mycompany/other"} {
+		if _, err := imp.ImportPackageObject(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if removed := imp.Reload(); len(removed) != 0 {
+		t.Errorf("unexpected reload of unchanged packages: %v", removed)
+	}
+
+	writePackage(t, dir, "mycompany/math", map[string]string{
+		"math.flux": "package math\n\nfactor = 3\n",
+	})
+	// Make sure the change is detected on file systems
+	// with a coarse modification time.
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(filepath.Join(dir, "mycompany", "math", "math.flux"), future, future); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, p := range imp.Reload() {
+		got = append(got, p.Path())
+	}
+	if want := []string{"mycompany/alerts", "mycompany/math"}; !cmp.Equal(want, got) {
+		t.Errorf("unexpected reloaded packages -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	pkg, err := imp.ImportPackageObject("mycompany/alerts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := pkg.Get("limit"); !ok {
+		t.Error("limit is not defined")
+	} else if want, got := int64(63), v.Int(); want != got {
+		t.Errorf("unexpected limit -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+}
+
+func TestWithFluxPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fluxpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writePackage(t, dir, "mycompany/math", map[string]string{
+		"math.flux": "package math\n\nfactor = 2\n",
+	})
+	ctx := runtime.WithFluxPath(context.Background(), []string{dir})
+	src := "import \"mycompany/math\"\n\nx = 21 * math.factor\n"
+
+	_, scope, err := runtime.Eval(ctx, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := scope.Lookup("x"); !ok {
+		t.Error("x is not defined")
+	} else if want, got := int64(42), v.Int(); want != got {
+		t.Errorf("unexpected x -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	// The package is imported again for each script,
+	// so the changes to its files are picked up.
+	writePackage(t, dir, "mycompany/math", map[string]string{
+		"math.flux": "package math\n\nfactor = 3\n",
+	})
+	_, scope, err = runtime.Eval(ctx, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := scope.Lookup("x"); !ok {
+		t.Error("x is not defined")
+	} else if want, got := int64(63), v.Int(); want != got {
+		t.Errorf("unexpected x -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	if _, _, err := runtime.Eval(context.Background(), src); err == nil {
+		t.Error("expected an error importing a package without a flux path")
+	}
+}
+
+func TestWithFluxPath_ImportOutsideDirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fluxpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writePackage(t, dir, "fluxpath/mycompany/math", map[string]string{
+		"math.flux": "package math\n\nfactor = 2\n",
+	})
+	writePackage(t, dir, "secret", map[string]string{
+		"secret.flux": "package secret\n\nfactor = 3\n",
+	})
+	ctx := runtime.WithFluxPath(context.Background(), []string{filepath.Join(dir, "fluxpath")})
+
+	// A script sent by a client cannot import packages
+	// outside of the directories of the flux path.
+	for _, path := range []string{
+		filepath.ToSlash(filepath.Join(dir, "secret")),
+		"../secret",
+		"./mycompany/math",
+		"mycompany/../../secret",
+	} {
+		t.Run(path, func(t *testing.T) {
+			src := "import secret \"" + path + "\"\n\nx = secret.factor\n"
+			if _, _, err := runtime.Eval(ctx, src); err == nil {
+				t.Error("expected an error importing a package outside of the flux path")
+			} else if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
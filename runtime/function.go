@@ -0,0 +1,124 @@
+package runtime
+
+import (
+	"context"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// Function is a Flux function that is called from Go with Go values,
+// so embedders can reuse functions written in Flux without building
+// Flux values by hand.
+//
+// The arguments are converted to Flux values with values.EncodeAs,
+// guided by the types of the arguments of the function, and the result
+// is converted back to Go with values.Decode.
+type Function struct {
+	name string
+	fn   values.Function
+}
+
+// LookupFunction returns the function with the given name from
+// a package of the standard library or a registered package.
+func LookupFunction(pkgpath, name string) (*Function, error) {
+	pkg, err := StdLib().ImportPackageObject(pkgpath)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := pkg.Get(name)
+	if !ok {
+		return nil, errors.Newf(codes.NotFound, "package %q has no value %q", pkgpath, name)
+	}
+	return newFunction(pkgpath+"."+name, v)
+}
+
+// LookupScopeFunction returns the function with the given name from
+// the scope, such as the scope returned by Eval, so functions defined
+// by a script can be called after the script is evaluated.
+func LookupScopeFunction(scope values.Scope, name string) (*Function, error) {
+	v, ok := scope.Lookup(name)
+	if !ok {
+		return nil, errors.Newf(codes.NotFound, "%q is not defined", name)
+	}
+	return newFunction(name, v)
+}
+
+func newFunction(name string, v values.Value) (*Function, error) {
+	if typ := v.Type().Nature(); typ != semantic.Function {
+		return nil, errors.Newf(codes.Invalid, "%s has type %s; expected function", name, typ)
+	}
+	return &Function{name: name, fn: v.Function()}, nil
+}
+
+// Type returns the type of the function.
+func (f *Function) Type() semantic.MonoType {
+	return f.fn.Type()
+}
+
+// Call calls the function with the arguments converted from Go values
+// and returns the result converted to a Go value.
+func (f *Function) Call(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	obj, err := f.arguments(args)
+	if err != nil {
+		return nil, err
+	}
+	v, err := f.fn.Call(ctx, obj)
+	if err != nil {
+		return nil, errors.Wrapf(err, codes.Inherit, "error calling function %s", f.name)
+	}
+	return toGo(v)
+}
+
+// arguments converts the arguments to a record with the
+// types of the arguments of the function.
+func (f *Function) arguments(args map[string]interface{}) (values.Object, error) {
+	fnType := f.fn.Type()
+	n, err := fnType.NumArguments()
+	if err != nil {
+		return nil, err
+	}
+	types := make(map[string]semantic.MonoType, n)
+	for i := 0; i < n; i++ {
+		arg, err := fnType.Argument(i)
+		if err != nil {
+			return nil, err
+		}
+		name := string(arg.Name())
+		if types[name], err = arg.TypeOf(); err != nil {
+			return nil, err
+		}
+		if _, ok := args[name]; !ok && !arg.Optional() {
+			return nil, errors.Newf(codes.Invalid, "missing required argument %q of function %s", name, f.name)
+		}
+	}
+
+	vals := make(map[string]values.Value, len(args))
+	for name, arg := range args {
+		typ, ok := types[name]
+		if !ok {
+			return nil, errors.Newf(codes.Invalid, "function %s has no argument %q", f.name, name)
+		}
+		v, err := values.EncodeAs(arg, typ)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Inherit, "argument %q", name)
+		}
+		vals[name] = v
+	}
+	return values.NewObjectWithValues(vals), nil
+}
+
+// toGo converts the Flux value to a Go value.
+func toGo(v values.Value) (interface{}, error) {
+	if _, ok := v.(*flux.TableObject); ok {
+		return nil, errors.New(codes.Invalid, "cannot convert a stream of tables to a Go value; run the script as a query to read its tables")
+	}
+	var out interface{}
+	if err := values.Decode(v, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
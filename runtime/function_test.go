@@ -0,0 +1,83 @@
+package runtime_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	"github.com/influxdata/flux/runtime"
+)
+
+func TestFunction_Stdlib(t *testing.T) {
+	fn, err := runtime.LookupFunction("strings", "joinStr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := fn.Call(context.Background(), map[string]interface{}{
+		"arr": []string{"a", "b", "c"},
+		"v":   ",",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "a,b,c"; !cmp.Equal(want, got) {
+		t.Errorf("unexpected result -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestFunction_Script(t *testing.T) {
+	ctx := dependenciestest.Default().Inject(context.Background())
+	_, scope, err := runtime.Eval(ctx, `
+		score = (r, weight=2.0) => ({
+			name: r.name,
+			score: r.value * weight,
+			late: r.time > 2021-01-01T00:00:00Z,
+			tags: r.tags,
+		})
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn, err := runtime.LookupScopeFunction(scope, "score")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fn.Call(ctx, map[string]interface{}{
+		"r": map[string]interface{}{
+			"name": "cpu",
+			// An int is converted to the float the function expects.
+			"value": 3,
+			"time":  time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC),
+			"tags":  []string{"a", "b"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{
+		"name":  "cpu",
+		"score": 6.0,
+		"late":  true,
+		"tags":  []interface{}{"a", "b"},
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected result -want/+got:\n%s", cmp.Diff(want, got))
+	}
+
+	if _, err := fn.Call(ctx, map[string]interface{}{}); err == nil {
+		t.Error("expected an error for a missing argument")
+	} else if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	if _, err := runtime.LookupScopeFunction(scope, "missing"); err == nil {
+		t.Error("expected an error for an undefined function")
+	} else if want, got := codes.NotFound, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
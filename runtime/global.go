@@ -18,6 +18,27 @@ func RegisterPackageValue(pkgpath, name string, value values.Value) {
 	}
 }
 
+// RegisterPackage registers a package that is not part of the standard library
+// so it can be imported by Flux scripts. The source is the Flux source of the
+// package, which declares the type of each value implemented in Go with a
+// builtin statement:
+//
+//	runtime.RegisterPackage("mycompany/geo", `
+//	    package geo
+//
+//	    builtin area : (lat: [float], lon: [float]) => float
+//	`)
+//
+// The type of a builtin value can then be looked up with LookupBuiltinType
+// and its implementation is registered with RegisterPackageValue.
+// The source may import the standard library and packages registered before it.
+// Packages must be registered before FinalizeBuiltIns is called.
+func RegisterPackage(pkgpath, source string) {
+	if err := Default.RegisterPackage(pkgpath, source); err != nil {
+		panic(err)
+	}
+}
+
 // ReplacePackageValue replaces a value for an identifier in a builtin package
 func ReplacePackageValue(pkgpath, name string, value values.Value) {
 	if err := Default.ReplacePackageValue(pkgpath, name, value); err != nil {
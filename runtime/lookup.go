@@ -19,8 +19,12 @@ type envKey struct {
 }
 
 // LookupBuiltinType returns the type of the builtin value for a given
-// Flux stdlib package. Returns an error if lookup fails.
+// Flux stdlib package or package registered with RegisterPackage.
+// Returns an error if lookup fails.
 func LookupBuiltinType(pkg, name string) (semantic.MonoType, error) {
+	if _, ok := Default.sources[pkg]; ok {
+		return Default.lookupRegisteredType(pkg, name)
+	}
 	key := envKey{
 		Package: pkg,
 		Prop:    name,
@@ -41,6 +45,38 @@ func LookupBuiltinType(pkg, name string) (semantic.MonoType, error) {
 	return monotype, nil
 }
 
+// lookupRegisteredType returns the type of a value of a registered package
+// from the type of a script that refers to it.
+func (r *runtime) lookupRegisteredType(pkg, name string) (semantic.MonoType, error) {
+	analyzer, err := r.newAnalyzer()
+	if err != nil {
+		return semantic.MonoType{}, err
+	}
+	defer analyzer.Free()
+	astPkg := libflux.ParseString(fmt.Sprintf("import pkg %q\nv = pkg.%s", pkg, name))
+	sem, err := analyzer.Analyze(astPkg)
+	if err != nil {
+		return semantic.MonoType{}, errors.Wrapf(err, codes.Internal, "failed to look up %v %v", pkg, name)
+	}
+	defer sem.Free()
+	bs, err := sem.MarshalFB()
+	if err != nil {
+		return semantic.MonoType{}, err
+	}
+	semPkg, err := semantic.DeserializeFromFlatBuffer(bs)
+	if err != nil {
+		return semantic.MonoType{}, err
+	}
+	for _, f := range semPkg.Files {
+		for _, stmt := range f.Body {
+			if a, ok := stmt.(*semantic.NativeVariableAssignment); ok && a.Identifier.Name == "v" {
+				return a.Init.TypeOf(), nil
+			}
+		}
+	}
+	return semantic.MonoType{}, errors.Newf(codes.Internal, "failed to look up %v %v", pkg, name)
+}
+
 // MustLookupBuiltinType validates that call to LookupBuiltInType was
 // successful. If there is an error with lookup, then panic.
 func MustLookupBuiltinType(pkg, name string) semantic.MonoType {
@@ -0,0 +1,306 @@
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// OptionSpec declares the values an option accepts.
+type OptionSpec struct {
+	// Type is the type of the values of the option.
+	// Type variables match any type.
+	Type semantic.MonoType
+	// Validate returns an error if a value of the right
+	// type is not valid for the option, if set.
+	Validate func(v values.Value) error
+}
+
+type optionKey struct {
+	pkgpath, name string
+}
+
+// DeclareOption declares the values accepted by an option of a package.
+// An option statement that assigns a value of the wrong type to the option
+// is an error when the script is analyzed. A value that is not valid is an
+// error when the script is analyzed if the value is a literal and otherwise
+// when the script is evaluated. Options of a prelude package are
+// set with an option statement that does not name the package.
+// Options must be declared before FinalizeBuiltIns is called.
+func DeclareOption(pkgpath, name string, spec OptionSpec) {
+	if err := Default.DeclareOption(pkgpath, name, spec); err != nil {
+		panic(err)
+	}
+}
+
+func (r *runtime) DeclareOption(pkgpath, name string, spec OptionSpec) error {
+	if r.finalized {
+		return errors.Newf(codes.Internal, "already finalized, cannot declare option %q %q", pkgpath, name)
+	}
+	key := optionKey{pkgpath: pkgpath, name: name}
+	if _, ok := r.options[key]; ok {
+		return errors.Newf(codes.Internal, "duplicate option declaration %q %q", pkgpath, name)
+	}
+	if r.options == nil {
+		r.options = make(map[optionKey]OptionSpec)
+	}
+	r.options[key] = spec
+	return nil
+}
+
+// lookupOption returns the declaration of the option set with
+// the name, or the name of a prelude package if pkgpath is empty.
+func (r *runtime) lookupOption(pkgpath, name string) (OptionSpec, string, bool) {
+	if pkgpath != "" {
+		spec, ok := r.options[optionKey{pkgpath: pkgpath, name: name}]
+		return spec, pkgpath, ok
+	}
+	for _, path := range PreludeList {
+		if spec, ok := r.options[optionKey{pkgpath: path, name: name}]; ok {
+			return spec, path, true
+		}
+	}
+	return OptionSpec{}, "", false
+}
+
+// OneOf returns a validation function for string options
+// that only accept one of the values.
+func OneOf(allowed ...string) func(v values.Value) error {
+	return func(v values.Value) error {
+		for _, s := range allowed {
+			if v.Str() == s {
+				return nil
+			}
+		}
+		return errors.Newf(codes.Invalid, "got %q, expected one of %s", v.Str(), strings.Join(allowed, ", "))
+	}
+}
+
+// PositiveDuration is a validation function for duration
+// options that only accept positive durations.
+func PositiveDuration(v values.Value) error {
+	if d := v.Duration(); !d.IsPositive() {
+		return errors.Newf(codes.Invalid, "got %v, expected a positive duration", d)
+	}
+	return nil
+}
+
+// CheckOptions returns an error if an option statement of the package
+// assigns a value to a declared option that the option does not accept.
+// The values are only validated if they are literals.
+func CheckOptions(pkg *semantic.Package) error {
+	return Default.checkOptions(pkg)
+}
+
+func (r *runtime) checkOptions(pkg *semantic.Package) error {
+	if len(r.options) == 0 {
+		return nil
+	}
+	for _, file := range pkg.Files {
+		for _, stmt := range file.Body {
+			opt, ok := stmt.(*semantic.OptionStatement)
+			if !ok {
+				continue
+			}
+			if err := r.checkOption(file, opt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *runtime) checkOption(file *semantic.File, opt *semantic.OptionStatement) error {
+	var (
+		pkgpath, name string
+		init          semantic.Expression
+	)
+	switch a := opt.Assignment.(type) {
+	case *semantic.NativeVariableAssignment:
+		name, init = a.Identifier.Name, a.Init
+	case *semantic.MemberAssignment:
+		ident, ok := a.Member.Object.(*semantic.IdentifierExpression)
+		if !ok {
+			return nil
+		}
+		pkgpath = importPath(file, ident.Name)
+		if pkgpath == "" {
+			return nil
+		}
+		name, init = a.Member.Property, a.Init
+	default:
+		return nil
+	}
+	spec, pkgpath, ok := r.lookupOption(pkgpath, name)
+	if !ok {
+		return nil
+	}
+
+	if typ := init.TypeOf(); !matchType(spec.Type, typ) {
+		return errors.Newf(codes.Invalid, "%s: option %s must be of type %s, got %s", opt.Location(), optionName(pkgpath, name), spec.Type, typ)
+	}
+	if spec.Validate == nil || !isConstant(init) {
+		return nil
+	}
+	v, err := evalConstant(init)
+	if err != nil {
+		// The error is reported when the script is evaluated.
+		return nil
+	}
+	if err := spec.Validate(v); err != nil {
+		return errors.Wrapf(err, codes.Invalid, "%s: invalid value for option %s", opt.Location(), optionName(pkgpath, name))
+	}
+	return nil
+}
+
+// ValidateOptions returns an error if a declared option of a package
+// imported into the scope, or of the prelude, has a value that
+// is not valid.
+func ValidateOptions(scope values.Scope) error {
+	return Default.validateOptions(scope)
+}
+
+func (r *runtime) validateOptions(scope values.Scope) error {
+	if len(r.options) == 0 {
+		return nil
+	}
+	pkgs := make(map[string]values.Package)
+	scope.Range(func(k string, v values.Value) {
+		if pkg, ok := v.(values.Package); ok {
+			pkgs[pkg.Path()] = pkg
+		}
+	})
+	for key, spec := range r.options {
+		if spec.Validate == nil {
+			continue
+		}
+		var (
+			v  values.Value
+			ok bool
+		)
+		if r.IsPreludePackage(key.pkgpath) {
+			v, ok = scope.Lookup(key.name)
+		} else if pkg, found := pkgs[key.pkgpath]; found {
+			v, ok = pkg.Get(key.name)
+		}
+		if !ok {
+			continue
+		}
+		if opt, isOpt := v.(*values.Option); isOpt {
+			v = opt.Value
+		}
+		if err := spec.Validate(v); err != nil {
+			return errors.Wrapf(err, codes.Invalid, "invalid value for option %s", optionName(key.pkgpath, key.name))
+		}
+	}
+	return nil
+}
+
+func optionName(pkgpath, name string) string {
+	for _, path := range PreludeList {
+		if path == pkgpath {
+			return name
+		}
+	}
+	return fmt.Sprintf("%s.%s", pkgpath[strings.LastIndex(pkgpath, "/")+1:], name)
+}
+
+// importPath returns the path of the package imported
+// with the name by the file or an empty string.
+func importPath(file *semantic.File, name string) string {
+	for _, imp := range file.Imports {
+		path := imp.Path.Value
+		alias := path[strings.LastIndex(path, "/")+1:]
+		if imp.As != nil {
+			alias = imp.As.Name
+		}
+		if alias == name {
+			return path
+		}
+	}
+	return ""
+}
+
+// matchType reports whether a value of type actual
+// can be assigned to an option of type expected.
+func matchType(expected, actual semantic.MonoType) bool {
+	if expected.Kind() == semantic.Var || actual.Kind() == semantic.Var {
+		return true
+	}
+	if expected.Nature() != actual.Nature() {
+		return false
+	}
+	switch expected.Nature() {
+	case semantic.Array:
+		e, err := expected.ElemType()
+		if err != nil {
+			return true
+		}
+		a, err := actual.ElemType()
+		if err != nil {
+			return true
+		}
+		return matchType(e, a)
+	case semantic.Dictionary:
+		ek, err := expected.KeyType()
+		if err != nil {
+			return true
+		}
+		ak, err := actual.KeyType()
+		if err != nil {
+			return true
+		}
+		ev, err := expected.ValueType()
+		if err != nil {
+			return true
+		}
+		av, err := actual.ValueType()
+		if err != nil {
+			return true
+		}
+		return matchType(ek, ak) && matchType(ev, av)
+	}
+	return true
+}
+
+// isConstant reports whether the expression only contains literals
+// so it can be evaluated before the script is evaluated.
+func isConstant(expr semantic.Expression) bool {
+	constant := true
+	semantic.Walk(semantic.CreateVisitor(func(n semantic.Node) {
+		switch n.(type) {
+		case *semantic.IdentifierExpression,
+			*semantic.MemberExpression,
+			*semantic.IndexExpression,
+			*semantic.CallExpression,
+			*semantic.FunctionExpression:
+			constant = false
+		}
+	}), expr)
+	return constant
+}
+
+func evalConstant(expr semantic.Expression) (values.Value, error) {
+	pkg := &semantic.Package{
+		Package: interpreter.PackageMain,
+		Files: []*semantic.File{{
+			Body: []semantic.Statement{
+				&semantic.ExpressionStatement{Expression: expr},
+			},
+		}},
+	}
+	ses, err := interpreter.NewInterpreter(nil, nil).Eval(context.Background(), pkg, values.NewScope(), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(ses) != 1 {
+		return nil, errors.New(codes.Internal, "constant expression did not produce a value")
+	}
+	return ses[0].Value, nil
+}
@@ -0,0 +1,137 @@
+package runtime
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/libflux/go/libflux"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// analyzeAndCheckOptions analyzes the source and checks its options.
+// The analyzer itself may reject an option with the wrong type.
+func analyzeAndCheckOptions(r *runtime, src string) error {
+	sem, err := libflux.Analyze(libflux.ParseString(src))
+	if err != nil {
+		return err
+	}
+	defer sem.Free()
+	bs, err := sem.MarshalFB()
+	if err != nil {
+		return err
+	}
+	pkg, err := semantic.DeserializeFromFlatBuffer(bs)
+	if err != nil {
+		return err
+	}
+	return r.checkOptions(pkg)
+}
+
+func TestCheckOptions(t *testing.T) {
+	r := &runtime{}
+	if err := r.DeclareOption("planner", "disabledRules", OptionSpec{
+		Type: semantic.NewArrayType(semantic.BasicString),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DeclareOption("universe", "mode", OptionSpec{
+		Type:     semantic.BasicString,
+		Validate: OneOf("fast", "slow"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DeclareOption("universe", "mode", OptionSpec{}); err == nil {
+		t.Error("expected error declaring an option twice")
+	}
+
+	for _, tc := range []struct {
+		name    string
+		src     string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			src: `
+import "planner"
+option planner.disabledRules = ["a", "b"]
+option mode = "fast"`,
+		},
+		{
+			name: "empty array",
+			src: `
+import "planner"
+option planner.disabledRules = []`,
+		},
+		{
+			name: "wrong type",
+			src: `
+import "planner"
+option planner.disabledRules = "a"`,
+			wantErr: true,
+		},
+		{
+			name: "wrong element type",
+			src: `
+import p "planner"
+option p.disabledRules = [1, 2]`,
+			wantErr: true,
+		},
+		{
+			name: "invalid literal",
+			src: `
+option mode = "warp"`,
+			wantErr: true,
+		},
+		{
+			name: "value is validated when evaluated",
+			src: `
+warp = "warp"
+option mode = warp`,
+		},
+		{
+			name: "undeclared option",
+			src: `
+option other = 1`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := analyzeAndCheckOptions(r, tc.src)
+			if !tc.wantErr {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+				t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+			}
+		})
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	r := &runtime{}
+	if err := r.DeclareOption("universe", "every", OptionSpec{
+		Type:     semantic.BasicDuration,
+		Validate: PositiveDuration,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	scope := values.NewScope()
+	opt := &values.Option{Value: values.NewDuration(values.ConvertDurationNsecs(1))}
+	scope.Set("every", opt)
+	if err := r.validateOptions(scope); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	opt.Value = values.NewDuration(values.ConvertDurationNsecs(-1))
+	if err := r.validateOptions(scope); err == nil {
+		t.Error("expected error validating a negative duration")
+	}
+}
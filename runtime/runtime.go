@@ -22,8 +22,16 @@ var Default = &runtime{}
 // runtime contains the flux runtime for interpreting and
 // executing queries.
 type runtime struct {
-	pkgs      map[string]*semantic.Package
-	builtins  map[string]map[string]values.Value
+	pkgs     map[string]*semantic.Package
+	builtins map[string]map[string]values.Value
+	// sources contains the Flux source of the packages
+	// registered with RegisterPackage by their path.
+	sources map[string]string
+	// sourcePaths lists the paths of the registered packages
+	// in the order they were registered.
+	sourcePaths []string
+	// options contains the declared options.
+	options   map[optionKey]OptionSpec
 	finalized bool
 }
 
@@ -84,6 +92,59 @@ func (r *runtime) registerPackageValue(pkgpath, name string, value values.Value,
 	return nil
 }
 
+func (r *runtime) RegisterPackage(pkgpath, source string) error {
+	if r.finalized {
+		return errors.Newf(codes.Internal, "already finalized, cannot register package %q", pkgpath)
+	}
+	if _, err := fs.Stat(embed.FS, "stdlib/"+pkgpath+".fc"); err == nil {
+		return errors.Newf(codes.Internal, "package %q is part of the standard library", pkgpath)
+	}
+	if _, ok := r.sources[pkgpath]; ok {
+		return errors.Newf(codes.Internal, "duplicate package %q", pkgpath)
+	}
+	astPkg := libflux.ParseString(source)
+	defer astPkg.Free()
+	if err := astPkg.GetError(); err != nil {
+		return errors.Wrapf(err, codes.Internal, "invalid source for package %q", pkgpath)
+	}
+
+	if r.sources == nil {
+		r.sources = make(map[string]string)
+	}
+	r.sources[pkgpath] = source
+	r.sourcePaths = append(r.sourcePaths, pkgpath)
+	return nil
+}
+
+// newAnalyzer returns an analyzer that knows the types
+// of the packages registered with RegisterPackage.
+func (r *runtime) newAnalyzer() (*libflux.Analyzer, error) {
+	analyzer := libflux.NewAnalyzer()
+	for _, path := range r.sourcePaths {
+		if _, err := r.analyzeSource(analyzer, path); err != nil {
+			analyzer.Free()
+			return nil, err
+		}
+	}
+	return analyzer, nil
+}
+
+// analyzeSource analyzes the source of a registered package
+// so the analyzer can type check the packages that import it.
+func (r *runtime) analyzeSource(analyzer *libflux.Analyzer, pkgpath string) (*semantic.Package, error) {
+	astPkg := libflux.Parse(pkgpath+".flux", r.sources[pkgpath])
+	sem, err := analyzer.AnalyzePackage(pkgpath, astPkg)
+	if err != nil {
+		return nil, errors.Wrapf(err, codes.Inherit, "failed to analyze package %q", pkgpath)
+	}
+	defer sem.Free()
+	bs, err := sem.MarshalFB()
+	if err != nil {
+		return nil, err
+	}
+	return semantic.DeserializeFromFlatBuffer(bs)
+}
+
 func (r *runtime) Prelude() values.Scope {
 	if !r.finalized {
 		panic("builtins not finalized")
@@ -97,14 +158,34 @@ func (r *runtime) Prelude() values.Scope {
 }
 
 func (r *runtime) Eval(ctx context.Context, astPkg flux.ASTHandle, es interpreter.ExecOptsConfig, opts ...flux.ScopeMutator) ([]interpreter.SideEffect, values.Scope, error) {
-	semPkg, err := AnalyzePackage(astPkg)
-	if err != nil {
-		return nil, nil, err
+	var (
+		semPkg *semantic.Package
+		imp    interpreter.Importer
+		err    error
+	)
+	if dirs := fluxPathFromContext(ctx); len(dirs) > 0 {
+		// The packages are imported for this script only, so the
+		// changes to their files are picked up by the next script
+		// and the scripts do not share the options of the packages.
+		analyzer, err := r.newAnalyzer()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer analyzer.Free()
+		pathImp := NewPathImporter(dirs, analyzer)
+		if semPkg, err = pathImp.analyze(astPkg.(*libflux.ASTPkg)); err != nil {
+			return nil, nil, err
+		}
+		imp = pathImp
+	} else {
+		if semPkg, err = r.analyzeCached(ctx, astPkg); err != nil {
+			return nil, nil, err
+		}
+		imp = &importer{r: r}
 	}
 
 	// Construct the initial scope for this package.
-	importer := &importer{r: r}
-	scope, err := r.newScopeFor("main", importer)
+	scope, err := r.newScopeFor("main", imp)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -116,10 +197,13 @@ func (r *runtime) Eval(ctx context.Context, astPkg flux.ASTHandle, es interprete
 
 	// Execute the interpreter over the package.
 	itrp := interpreter.NewInterpreter(nil, es)
-	sideEffects, err := itrp.Eval(ctx, semPkg, scope, importer)
+	sideEffects, err := itrp.Eval(ctx, semPkg, scope, imp)
 	if err != nil {
 		return nil, nil, err
 	}
+	if err := r.validateOptions(scope); err != nil {
+		return nil, nil, err
+	}
 	return sideEffects, scope, nil
 }
 
@@ -199,6 +283,18 @@ func (r *runtime) Finalize() error {
 		return err
 	}
 
+	// Registered packages are analyzed in the order they were
+	// registered so a package can import the packages before it.
+	analyzer := libflux.NewAnalyzer()
+	defer analyzer.Free()
+	for _, path := range r.sourcePaths {
+		semPkg, err := r.analyzeSource(analyzer, path)
+		if err != nil {
+			return err
+		}
+		r.pkgs[path] = semPkg
+	}
+
 	for path, pkg := range r.builtins {
 		semPkg, ok := r.pkgs[path]
 		if !ok {
@@ -208,6 +304,16 @@ func (r *runtime) Finalize() error {
 			return err
 		}
 	}
+	// A registered package may declare builtins
+	// without registering any of their values.
+	for _, path := range r.sourcePaths {
+		if _, ok := r.builtins[path]; ok {
+			continue
+		}
+		if err := validatePackageBuiltins(nil, r.pkgs[path]); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -0,0 +1,84 @@
+package source
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+// pushDownRangeRule merges a range into the source before it
+// when the spec of the source implements RangePushDown.
+type pushDownRangeRule struct {
+	kind plan.ProcedureKind
+}
+
+func (r pushDownRangeRule) Name() string {
+	return string(r.kind) + ".PushDownRangeRule"
+}
+
+func (r pushDownRangeRule) Pattern() plan.Pattern {
+	return plan.Pat(universe.RangeKind, plan.Pat(r.kind))
+}
+
+func (r pushDownRangeRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	fromNode := node.Predecessors()[0]
+	fromSpec := fromNode.ProcedureSpec().(sourceSpec).source()
+	if _, ok := fromSpec.Spec.(RangePushDown); !ok || !fromSpec.Bounds.IsEmpty() || fromSpec.Limited || len(fromNode.Successors()) != 1 {
+		return node, false, nil
+	}
+
+	rangeSpec := node.ProcedureSpec().(*universe.RangeProcedureSpec)
+	if rangeSpec.TimeColumn != execute.DefaultTimeColLabel ||
+		rangeSpec.StartColumn != execute.DefaultStartColLabel ||
+		rangeSpec.StopColumn != execute.DefaultStopColLabel {
+		return node, false, nil
+	}
+	spec, ok := fromSpec.Spec.Copy().(RangePushDown).PushDownRange(rangeSpec.Bounds)
+	if !ok {
+		return node, false, nil
+	}
+	ns := *fromSpec
+	ns.Spec, ns.Bounds = spec, rangeSpec.Bounds
+	return merge(node, fromNode, wrap(&ns))
+}
+
+// pushDownLimitRule merges a limit into the source before it
+// when the spec of the source implements LimitPushDown.
+type pushDownLimitRule struct {
+	kind plan.ProcedureKind
+}
+
+func (r pushDownLimitRule) Name() string {
+	return string(r.kind) + ".PushDownLimitRule"
+}
+
+func (r pushDownLimitRule) Pattern() plan.Pattern {
+	return plan.Pat(universe.LimitKind, plan.Pat(r.kind))
+}
+
+func (r pushDownLimitRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	fromNode := node.Predecessors()[0]
+	fromSpec := fromNode.ProcedureSpec().(sourceSpec).source()
+	if _, ok := fromSpec.Spec.(LimitPushDown); !ok || len(fromNode.Successors()) != 1 {
+		return node, false, nil
+	}
+
+	limitSpec := node.ProcedureSpec().(*universe.LimitProcedureSpec)
+	spec, ok := fromSpec.Spec.Copy().(LimitPushDown).PushDownLimit(limitSpec.N, limitSpec.Offset)
+	if !ok {
+		return node, false, nil
+	}
+	ns := *fromSpec
+	ns.Spec, ns.Limited = spec, true
+	return merge(node, fromNode, wrap(&ns))
+}
+
+func merge(node, fromNode plan.Node, spec plan.PhysicalProcedureSpec) (plan.Node, bool, error) {
+	n, err := plan.MergeToPhysicalNode(node, fromNode, spec)
+	if err != nil {
+		return nil, false, err
+	}
+	return n, true, nil
+}
@@ -0,0 +1,336 @@
+package source
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/flux/values"
+)
+
+const testKind = "test/storage.from"
+
+// pushDownSpec is a source spec that records
+// the transformations pushed down into it.
+type pushDownSpec struct {
+	Bounds     flux.Bounds
+	Predicates []plan.Predicate
+	Limit      int64
+	Offset     int64
+}
+
+func (s *pushDownSpec) Copy() Spec {
+	ns := *s
+	ns.Predicates = append([]plan.Predicate(nil), s.Predicates...)
+	return &ns
+}
+
+func (s *pushDownSpec) Read(ctx context.Context, a execute.Administration, f func(flux.Table) error) error {
+	return nil
+}
+
+func (s *pushDownSpec) PushDownRange(bounds flux.Bounds) (Spec, bool) {
+	s.Bounds = bounds
+	return s, true
+}
+
+func (s *pushDownSpec) PredicateCapabilities() plan.PredicateCapabilities {
+	return plan.PredicateCapabilities{
+		Columns: map[string][]ast.OperatorKind{
+			"host": {ast.EqualOperator},
+		},
+	}
+}
+
+func (s *pushDownSpec) PushDownPredicates(predicates []plan.Predicate, keepEmpty bool) Spec {
+	s.Predicates = append(s.Predicates, predicates...)
+	return s
+}
+
+func (s *pushDownSpec) PushDownLimit(n, offset int64) (Spec, bool) {
+	if s.Limit != 0 {
+		return s, false
+	}
+	s.Limit, s.Offset = n, offset
+	return s, true
+}
+
+// readSpec is a source spec that cannot push down any transformation.
+type readSpec struct{}
+
+func (s readSpec) Copy() Spec {
+	return s
+}
+
+func (s readSpec) Read(ctx context.Context, a execute.Administration, f func(flux.Table) error) error {
+	return nil
+}
+
+// projectionSpec is a source spec that can skip columns.
+type projectionSpec struct {
+	Columns []string
+}
+
+func (s *projectionSpec) Copy() Spec {
+	ns := *s
+	return &ns
+}
+
+func (s *projectionSpec) Read(ctx context.Context, a execute.Administration, f func(flux.Table) error) error {
+	return nil
+}
+
+func (s *projectionSpec) ProjectedColumns() []string {
+	return s.Columns
+}
+
+func (s *projectionSpec) PushDownProjection(columns []string) Spec {
+	s.Columns = columns
+	return s
+}
+
+func TestPushDownRules(t *testing.T) {
+	rules := []plan.Rule{
+		pushDownRangeRule{kind: testKind},
+		universe.PushDownPredicatesRule{},
+		pushDownLimitRule{kind: testKind},
+	}
+	from := func(spec Spec) plan.PhysicalProcedureSpec {
+		return wrap(&procedureSpec{SourceKind: testKind, Spec: spec})
+	}
+	bounds := flux.Bounds{
+		Start: flux.Time{IsRelative: true, Relative: -time.Hour},
+		Stop:  flux.Now,
+	}
+	rangeSpec := &universe.RangeProcedureSpec{
+		Bounds:      bounds,
+		TimeColumn:  "_time",
+		StartColumn: "_start",
+		StopColumn:  "_stop",
+	}
+	filterFn := interpreter.ResolvedFunction{
+		Fn: executetest.FunctionExpression(t, `(r) => r.host == "a"`),
+	}
+	filterSpec := &universe.FilterProcedureSpec{Fn: filterFn}
+	hostEq := plan.Predicate{Column: "host", Operator: ast.EqualOperator, Value: values.NewString("a")}
+	limitSpec := &universe.LimitProcedureSpec{N: 10, Offset: 5}
+
+	tests := []plantest.RuleTestCase{
+		{
+			Name:  "range",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&pushDownSpec{})),
+					plan.CreatePhysicalNode("range", rangeSpec),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("merged_from_range", &procedureSpec{
+						SourceKind: testKind,
+						Spec:       &pushDownSpec{Bounds: bounds},
+						Bounds:     bounds,
+					}),
+				},
+			},
+		},
+		{
+			Name:  "range with other columns",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&pushDownSpec{})),
+					plan.CreatePhysicalNode("range", &universe.RangeProcedureSpec{
+						Bounds:      bounds,
+						TimeColumn:  "time",
+						StartColumn: "_start",
+						StopColumn:  "_stop",
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "range filter limit",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&pushDownSpec{})),
+					plan.CreatePhysicalNode("range", rangeSpec),
+					plan.CreatePhysicalNode("filter", filterSpec),
+					plan.CreatePhysicalNode("limit", limitSpec),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}, {2, 3}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("merged_from_range_filter_limit", &procedureSpec{
+						SourceKind: testKind,
+						Spec: &pushDownSpec{
+							Bounds:     bounds,
+							Predicates: []plan.Predicate{hostEq},
+							Limit:      10,
+							Offset:     5,
+						},
+						Bounds:  bounds,
+						Limited: true,
+					}),
+				},
+			},
+		},
+		{
+			Name:  "filter after limit",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&pushDownSpec{})),
+					plan.CreatePhysicalNode("limit", limitSpec),
+					plan.CreatePhysicalNode("filter", filterSpec),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("merged_from_limit", &procedureSpec{
+						SourceKind: testKind,
+						Spec:       &pushDownSpec{Limit: 10, Offset: 5},
+						Limited:    true,
+					}),
+					plan.CreatePhysicalNode("filter", filterSpec),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name:  "filter with unsupported predicate",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&pushDownSpec{})),
+					plan.CreatePhysicalNode("filter", &universe.FilterProcedureSpec{
+						Fn: interpreter.ResolvedFunction{
+							Fn: executetest.FunctionExpression(t, `(r) => r.host == "a" and r._value > 1.0`),
+						},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", &procedureSpec{
+						SourceKind: testKind,
+						Spec:       &pushDownSpec{Predicates: []plan.Predicate{hostEq}},
+					}),
+					plan.CreatePhysicalNode("filter", &universe.FilterProcedureSpec{
+						Fn: interpreter.ResolvedFunction{
+							Fn: executetest.FunctionExpression(t, `(r) => r._value > 1.0`),
+						},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name:  "filter keeps empty tables",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&pushDownSpec{})),
+					plan.CreatePhysicalNode("filter", &universe.FilterProcedureSpec{
+						Fn:              filterFn,
+						KeepEmptyTables: true,
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "limit refused",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&pushDownSpec{Limit: 1})),
+					plan.CreatePhysicalNode("limit", limitSpec),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "source without push down",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(readSpec{})),
+					plan.CreatePhysicalNode("range", rangeSpec),
+					plan.CreatePhysicalNode("filter", filterSpec),
+					plan.CreatePhysicalNode("limit", limitSpec),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}, {2, 3}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "source with multiple successors",
+			Rules: rules,
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&pushDownSpec{})),
+					plan.CreatePhysicalNode("range", rangeSpec),
+					plan.CreatePhysicalNode("limit", limitSpec),
+				},
+				Edges: [][2]int{{0, 1}, {0, 2}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "projection",
+			Rules: []plan.Rule{universe.PushDownProjectionRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&projectionSpec{})),
+					plan.CreatePhysicalNode("keep", &universe.SchemaMutationProcedureSpec{
+						Mutations: []universe.SchemaMutation{
+							&universe.KeepOpSpec{Columns: []string{"_value", "_time"}},
+						},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(&projectionSpec{
+						Columns: []string{"_time", "_value"},
+					})),
+					plan.CreatePhysicalNode("keep", &universe.SchemaMutationProcedureSpec{
+						Mutations: []universe.SchemaMutation{
+							&universe.KeepOpSpec{Columns: []string{"_value", "_time"}},
+						},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc, cmp.AllowUnexported(projectionProcedureSpec{}))
+		})
+	}
+}
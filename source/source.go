@@ -0,0 +1,293 @@
+// Package source lets the programs that embed Flux expose their own
+// storage as a source function, like from, without changing the
+// standard library or the planner.
+//
+// A source is registered with Register before runtime.FinalizeBuiltIns
+// is called, usually in the init function of the package that implements it:
+//
+//	func init() {
+//	    source.Register(source.Source{
+//	        Package:   "mycompany/storage",
+//	        Name:      "from",
+//	        Signature: "(table: string) => [A] where A: Record",
+//	        New: func(args flux.Arguments, a *flux.Administration) (source.Spec, error) {
+//	            table, err := args.GetRequiredString("table")
+//	            if err != nil {
+//	                return nil, err
+//	            }
+//	            return &Spec{Table: table}, nil
+//	        },
+//	    })
+//	}
+//
+// The function can then be imported and called by Flux scripts:
+//
+//	import "mycompany/storage"
+//
+//	storage.from(table: "cpu") |> range(start: -1h)
+//
+// The Spec of a call to the function describes what the source reads and
+// reads the tables when the query is executed. A Spec may also implement
+// RangePushDown, PredicatePushDown, LimitPushDown or ProjectionPushDown so the
+// planner merges the transformations that follow the source into it and the
+// storage does the work of those transformations.
+package source
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/values"
+)
+
+// Source describes a source function implemented by an embedder.
+type Source struct {
+	// Package is the import path of the Flux package of the function.
+	Package string
+	// Name is the name of the function in the package.
+	Name string
+	// Signature is the Flux type of the function, such as
+	// "(table: string) => [A] where A: Record". When it is set, the package
+	// is registered with the function as its only builtin value. When it is
+	// empty, the package must have been registered with runtime.RegisterPackage
+	// and declare the function with a builtin statement.
+	Signature string
+	// New creates the spec of a call to the function from its arguments.
+	New func(args flux.Arguments, a *flux.Administration) (Spec, error)
+}
+
+// Kind returns the kind of the operations and procedures of the source.
+func (s Source) Kind() string {
+	return s.Package + "." + s.Name
+}
+
+// Spec is the spec of a call to a source function. It is copied by the
+// planner before a transformation is pushed down into it, so the methods
+// that push down transformations may modify the spec and return it.
+type Spec interface {
+	// Copy returns a copy of the spec.
+	Copy() Spec
+
+	// Read reads the tables of the source and calls f with each table.
+	// It stops and returns the error of f if f fails.
+	Read(ctx context.Context, a execute.Administration, f func(flux.Table) error) error
+}
+
+// RangePushDown is implemented by the specs of sources
+// that can do the work of range.
+type RangePushDown interface {
+	// PushDownRange returns a spec that only reads the rows whose _time
+	// is within the bounds and adds the _start and _stop columns of the
+	// bounds to the group key of each table, as range does.
+	// It returns false if the range cannot be pushed down.
+	PushDownRange(bounds flux.Bounds) (Spec, bool)
+}
+
+// PredicatePushDown is implemented by the specs of sources
+// that can do the work of filter for some of its predicates.
+type PredicatePushDown interface {
+	// PredicateCapabilities reports the predicates the source can evaluate.
+	// The filter keeps the predicates that are not supported.
+	PredicateCapabilities() plan.PredicateCapabilities
+
+	// PushDownPredicates returns a spec that only reads the rows for
+	// which every predicate is true. If keepEmpty is false, the tables
+	// that are left empty are dropped, as filter does.
+	PushDownPredicates(predicates []plan.Predicate, keepEmpty bool) Spec
+}
+
+// LimitPushDown is implemented by the specs of sources
+// that can do the work of limit.
+type LimitPushDown interface {
+	// PushDownLimit returns a spec that skips the first offset rows
+	// of each table and reads at most n of the rows that follow,
+	// as limit does. Once a limit has been pushed down, the ranges
+	// and filters that follow it are no longer pushed down.
+	// It returns false if the limit cannot be pushed down.
+	PushDownLimit(n, offset int64) (Spec, bool)
+}
+
+// ProjectionPushDown is implemented by the specs of sources
+// that can skip the columns that are not used by the query.
+type ProjectionPushDown interface {
+	// ProjectedColumns returns the labels of the columns the source
+	// reads, or nil if the source reads every column.
+	ProjectedColumns() []string
+
+	// PushDownProjection returns a spec that only reads the columns
+	// with these labels. Labels that do not match a column are ignored.
+	PushDownProjection(columns []string) Spec
+}
+
+// Register registers a source function so it can be called by Flux scripts
+// and planned and executed like the sources of the standard library.
+// It panics if the source is invalid or has already been registered.
+// Sources must be registered before runtime.FinalizeBuiltIns is called.
+func Register(s Source) {
+	if s.Package == "" || s.Name == "" {
+		panic(errors.New(codes.Internal, "a source requires a package and a name"))
+	} else if s.New == nil {
+		panic(errors.Newf(codes.Internal, "source %q requires a function to create its spec", s.Kind()))
+	}
+	if s.Signature != "" {
+		runtime.RegisterPackage(s.Package, fmt.Sprintf("package %s\n\nbuiltin %s : %s\n", path.Base(s.Package), s.Name, s.Signature))
+	}
+
+	kind := s.Kind()
+	signature := runtime.MustLookupBuiltinType(s.Package, s.Name)
+	createOpSpec := func(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+		spec, err := s.New(args, a)
+		if err != nil {
+			return nil, err
+		}
+		return &operationSpec{OpKind: flux.OperationKind(kind), Spec: spec}, nil
+	}
+	runtime.RegisterPackageValue(s.Package, s.Name, flux.MustValue(flux.FunctionValue(kind, createOpSpec, signature)))
+	flux.RegisterOpSpec(flux.OperationKind(kind), func() flux.OperationSpec {
+		return &operationSpec{OpKind: flux.OperationKind(kind)}
+	})
+	plan.RegisterProcedureSpec(plan.ProcedureKind(kind), newProcedure, flux.OperationKind(kind))
+	plan.RegisterPhysicalRules(
+		pushDownRangeRule{kind: plan.ProcedureKind(kind)},
+		pushDownLimitRule{kind: plan.ProcedureKind(kind)},
+	)
+	execute.RegisterSource(plan.ProcedureKind(kind), createSource)
+}
+
+type operationSpec struct {
+	OpKind flux.OperationKind
+	Spec   Spec
+}
+
+func (s *operationSpec) Kind() flux.OperationKind {
+	return s.OpKind
+}
+
+// procedureSpec is the procedure spec of a registered source.
+type procedureSpec struct {
+	plan.DefaultCost
+	SourceKind plan.ProcedureKind
+	Spec       Spec
+	// Bounds are the bounds of the range that has been pushed down.
+	Bounds flux.Bounds
+	// Limited reports whether a limit has been pushed down,
+	// after which the rows can no longer be filtered.
+	Limited bool
+}
+
+func newProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*operationSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return wrap(&procedureSpec{SourceKind: plan.ProcedureKind(spec.OpKind), Spec: spec.Spec}), nil
+}
+
+// wrap returns the procedure spec of a source. The sources whose spec
+// implements ProjectionPushDown are wrapped by a procedure spec that
+// implements plan.ProjectionPushDownProcedureSpec.
+func wrap(ps *procedureSpec) plan.PhysicalProcedureSpec {
+	if _, ok := ps.Spec.(ProjectionPushDown); ok {
+		return &projectionProcedureSpec{procedureSpec: ps}
+	}
+	return ps
+}
+
+func (s *procedureSpec) Kind() plan.ProcedureKind {
+	return s.SourceKind
+}
+
+func (s *procedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	ns.Spec = s.Spec.Copy()
+	return &ns
+}
+
+func (s *procedureSpec) source() *procedureSpec {
+	return s
+}
+
+// TimeBounds implements plan.BoundsAwareProcedureSpec.
+func (s *procedureSpec) TimeBounds(predecessorBounds *plan.Bounds) *plan.Bounds {
+	if s.Bounds.IsEmpty() {
+		return predecessorBounds
+	}
+	b := &plan.Bounds{
+		Start: values.ConvertTime(s.Bounds.Start.Time(s.Bounds.Now)),
+		Stop:  values.ConvertTime(s.Bounds.Stop.Time(s.Bounds.Now)),
+	}
+	if predecessorBounds != nil {
+		b = b.Intersect(predecessorBounds)
+	}
+	return b
+}
+
+// PredicateCapabilities implements plan.PredicatePushDownProcedureSpec.
+// No predicate is pushed down once a limit has been pushed down.
+func (s *procedureSpec) PredicateCapabilities() plan.PredicateCapabilities {
+	spec, ok := s.Spec.(PredicatePushDown)
+	if !ok || s.Limited {
+		return plan.PredicateCapabilities{}
+	}
+	return spec.PredicateCapabilities()
+}
+
+// PushDownPredicates implements plan.PredicatePushDownProcedureSpec.
+func (s *procedureSpec) PushDownPredicates(predicates []plan.Predicate, keepEmpty bool) plan.PhysicalProcedureSpec {
+	ns := *s
+	ns.Spec = s.Spec.Copy().(PredicatePushDown).PushDownPredicates(predicates, keepEmpty)
+	return wrap(&ns)
+}
+
+// projectionProcedureSpec is the procedure spec
+// of a source that implements ProjectionPushDown.
+type projectionProcedureSpec struct {
+	*procedureSpec
+}
+
+func (s *projectionProcedureSpec) Copy() plan.ProcedureSpec {
+	return &projectionProcedureSpec{procedureSpec: s.procedureSpec.Copy().(*procedureSpec)}
+}
+
+// ProjectedColumns implements plan.ProjectionPushDownProcedureSpec.
+func (s *projectionProcedureSpec) ProjectedColumns() []string {
+	return s.Spec.(ProjectionPushDown).ProjectedColumns()
+}
+
+// PushDownProjection implements plan.ProjectionPushDownProcedureSpec.
+func (s *projectionProcedureSpec) PushDownProjection(columns []string) plan.PhysicalProcedureSpec {
+	ns := *s.procedureSpec
+	ns.Spec = s.Spec.Copy().(ProjectionPushDown).PushDownProjection(columns)
+	return wrap(&ns)
+}
+
+// sourceSpec is implemented by both procedure specs of a source.
+type sourceSpec interface {
+	plan.PhysicalProcedureSpec
+	source() *procedureSpec
+}
+
+func createSource(ps plan.ProcedureSpec, id execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := ps.(sourceSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", ps)
+	}
+	return execute.CreateSourceFromIterator(&sourceIterator{spec: spec.source().Spec, a: a}, id)
+}
+
+// sourceIterator reads the tables of a source spec.
+type sourceIterator struct {
+	spec Spec
+	a    execute.Administration
+}
+
+func (s *sourceIterator) Do(ctx context.Context, f func(flux.Table) error) error {
+	return s.spec.Read(ctx, s.a, f)
+}
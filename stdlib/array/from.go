@@ -80,6 +80,17 @@ func (s *FromProcedureSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// Statistics implements plan.StatisticsProcedureSpec.
+func (s *FromProcedureSpec) Statistics() (plan.Statistics, bool) {
+	if s.Rows == nil {
+		return plan.Statistics{}, false
+	}
+	return plan.Statistics{
+		Cardinality:      int64(s.Rows.Len()),
+		GroupCardinality: 1,
+	}, true
+}
+
 func createFromSource(ps plan.ProcedureSpec, id execute.DatasetID, a execute.Administration) (execute.Source, error) {
 	spec := ps.(*FromProcedureSpec)
 	return &tableSource{
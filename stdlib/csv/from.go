@@ -10,15 +10,22 @@ import (
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/flux/dependencies/filesystem"
+	"github.com/influxdata/flux/dependencies/tablebuffer"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/planpb"
 	"github.com/influxdata/flux/runtime"
+	"google.golang.org/protobuf/proto"
 )
 
 const FromCSVKind = "fromCSV"
 
+// DefaultBufferSize is the default number of rows in each
+// buffer of the tables decoded from csv.
+const DefaultBufferSize = tablebuffer.DefaultSize
+
 type FromCSVOpSpec struct {
 	CSV  string `json:"csv"`
 	File string `json:"file"`
@@ -35,6 +42,11 @@ func init() {
 	runtime.RegisterPackageValue("csv", "from", flux.MustValue(flux.FunctionValue(FromCSVKind, createFromCSVOpSpec, fromCSVSignature)))
 	flux.RegisterOpSpec(FromCSVKind, newFromCSVOp)
 	plan.RegisterProcedureSpec(FromCSVKind, newFromCSVProcedure, FromCSVKind)
+	plan.RegisterFragmentSpec(FromCSVKind, plan.FragmentSpecCodec{
+		NewMessage:  func() proto.Message { return new(planpb.FromCSVSpec) },
+		ToMessage:   marshalFromCSV,
+		FromMessage: unmarshalFromCSV,
+	})
 	execute.RegisterSource(FromCSVKind, createFromCSVSource)
 }
 
@@ -86,6 +98,33 @@ type FromCSVProcedureSpec struct {
 	CSV  string
 	File string
 	Mode string
+	// Columns are the labels of the columns to decode.
+	// If nil, every column is decoded.
+	Columns []string
+}
+
+func marshalFromCSV(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+	s := spec.(*FromCSVProcedureSpec)
+	return &planpb.FromCSVSpec{
+		Csv:       s.CSV,
+		File:      s.File,
+		Mode:      s.Mode,
+		Columns:   s.Columns,
+		Projected: s.Columns != nil,
+	}, nil
+}
+
+func unmarshalFromCSV(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+	pb := m.(*planpb.FromCSVSpec)
+	spec := &FromCSVProcedureSpec{
+		CSV:  pb.Csv,
+		File: pb.File,
+		Mode: pb.Mode,
+	}
+	if pb.Projected {
+		spec.Columns = append([]string{}, pb.Columns...)
+	}
+	return spec, nil
 }
 
 func newFromCSVProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
@@ -110,9 +149,38 @@ func (s *FromCSVProcedureSpec) Copy() plan.ProcedureSpec {
 	ns.CSV = s.CSV
 	ns.File = s.File
 	ns.Mode = s.Mode
+	if s.Columns != nil {
+		ns.Columns = make([]string, len(s.Columns))
+		copy(ns.Columns, s.Columns)
+	}
 	return ns
 }
 
+// ProjectedColumns implements plan.ProjectionPushDownProcedureSpec.
+func (s *FromCSVProcedureSpec) ProjectedColumns() []string {
+	return s.Columns
+}
+
+// PushDownProjection implements plan.ProjectionPushDownProcedureSpec.
+func (s *FromCSVProcedureSpec) PushDownProjection(columns []string) plan.PhysicalProcedureSpec {
+	ns := s.Copy().(*FromCSVProcedureSpec)
+	ns.Columns = columns
+	return ns
+}
+
+// Statistics implements plan.StatisticsProcedureSpec.
+// Only the size of inline CSV data is known. Each line
+// is counted as a row, including annotations and headers.
+func (s *FromCSVProcedureSpec) Statistics() (plan.Statistics, bool) {
+	if s.File != "" {
+		return plan.Statistics{}, false
+	}
+	return plan.Statistics{
+		Cardinality: int64(strings.Count(s.CSV, "\n")),
+		Bytes:       int64(len(s.CSV)),
+	}, true
+}
+
 func createFromCSVSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
 	spec, ok := prSpec.(*FromCSVProcedureSpec)
 	if !ok {
@@ -141,6 +209,7 @@ func CreateSource(spec *FromCSVProcedureSpec, dsid execute.DatasetID, a execute.
 		getDataStream: getDataStream,
 		alloc:         a.Allocator(),
 		mode:          spec.Mode,
+		columns:       spec.Columns,
 	}
 
 	return &csvSource, nil
@@ -153,6 +222,7 @@ type CSVSource struct {
 	ts            []execute.Transformation
 	alloc         *memory.Allocator
 	mode          string
+	columns       []string
 }
 
 func (c *CSVSource) AddTransformation(t execute.Transformation) {
@@ -171,8 +241,10 @@ func (c *CSVSource) Run(ctx context.Context) {
 		// are not read-only. They contain mutable state and therefore
 		// cannot be shared among goroutines.
 		config := csv.ResultDecoderConfig{
-			Allocator: c.alloc,
-			Context:   ctx,
+			MaxBufferCount: tablebuffer.GetSize(ctx, DefaultBufferSize),
+			Allocator:      c.alloc,
+			Context:        ctx,
+			Columns:        c.columns,
 		}
 		switch c.mode {
 		case rawMode:
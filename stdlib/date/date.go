@@ -390,6 +390,22 @@ func init() {
 					return nil, errors.New(codes.Invalid, "missing argument unit")
 				}
 
+				if _, ok := args.Get("location"); ok && values.IsTimeable(v) && u.Type().Nature() == semantic.Duration {
+					t, err := getTime(ctx, args)
+					if err != nil {
+						return nil, err
+					}
+					loc, err := getLocation(args)
+					if err != nil {
+						return nil, err
+					}
+					start, err := truncateInLocation(t, u.Duration(), loc)
+					if err != nil {
+						return nil, err
+					}
+					return values.NewTime(start), nil
+				}
+
 				if values.IsTimeable(v) && u.Type().Nature() == semantic.Duration {
 					if v.Type().Nature() == semantic.Time {
 						w, err := execute.NewWindow(u.Duration(), u.Duration(), execute.Duration{})
@@ -417,6 +433,21 @@ func init() {
 				return nil, errors.New(codes.FailedPrecondition, fmt.Sprintf("cannot truncate argument t of type %v to unit %v", v.Type().Nature(), u))
 			}, false,
 		),
+		"convertTimezone": values.NewFunction(
+			"convertTimezone",
+			runtime.MustLookupBuiltinType("date", "convertTimezone"),
+			convertTimezone, false,
+		),
+		"isWeekend": values.NewFunction(
+			"isWeekend",
+			runtime.MustLookupBuiltinType("date", "isWeekend"),
+			isWeekend, false,
+		),
+		"addBusinessDays": values.NewFunction(
+			"addBusinessDays",
+			runtime.MustLookupBuiltinType("date", "addBusinessDays"),
+			addBusinessDays, false,
+		),
 	}
 
 	runtime.RegisterPackageValue("date", "second", SpecialFns["second"])
@@ -433,4 +464,7 @@ func init() {
 	runtime.RegisterPackageValue("date", "microsecond", SpecialFns["microsecond"])
 	runtime.RegisterPackageValue("date", "nanosecond", SpecialFns["nanosecond"])
 	runtime.RegisterPackageValue("date", "truncate", SpecialFns["truncate"])
+	runtime.RegisterPackageValue("date", "convertTimezone", SpecialFns["convertTimezone"])
+	runtime.RegisterPackageValue("date", "isWeekend", SpecialFns["isWeekend"])
+	runtime.RegisterPackageValue("date", "addBusinessDays", SpecialFns["addBusinessDays"])
 }
@@ -3,6 +3,7 @@ package date
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/influxdata/flux/dependencies/dependenciestest"
 	"github.com/influxdata/flux/values"
@@ -221,3 +222,207 @@ func TestTruncateNilErrors(t *testing.T) {
 		}
 	})
 }
+
+func mustParseTime(t *testing.T, s string) values.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values.ConvertTime(v)
+}
+
+func locationRecord(name string) values.Object {
+	return values.NewObjectWithValues(map[string]values.Value{
+		"zone":   values.NewString(name),
+		"offset": values.NewDuration(values.ConvertDurationNsecs(0)),
+	})
+}
+
+func TestTruncateInLocation(t *testing.T) {
+	testCases := []struct {
+		name     string
+		time     string
+		unit     string
+		location string
+		want     string
+	}{
+		{
+			name:     "day before DST",
+			time:     "2021-03-14T04:30:00Z",
+			unit:     "1d",
+			location: "America/New_York",
+			want:     "2021-03-13T05:00:00Z",
+		},
+		{
+			name:     "day of DST",
+			time:     "2021-03-14T12:00:00Z",
+			unit:     "1d",
+			location: "America/New_York",
+			want:     "2021-03-14T05:00:00Z",
+		},
+		{
+			name:     "day after DST",
+			time:     "2021-03-15T12:00:00Z",
+			unit:     "1d",
+			location: "America/New_York",
+			want:     "2021-03-15T04:00:00Z",
+		},
+		{
+			name:     "month",
+			time:     "2021-03-15T12:00:00Z",
+			unit:     "1mo",
+			location: "Asia/Tokyo",
+			want:     "2021-02-28T15:00:00Z",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			unit, err := values.ParseDuration(tc.unit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fluxArg := values.NewObjectWithValues(map[string]values.Value{
+				"t":        values.NewTime(mustParseTime(t, tc.time)),
+				"unit":     values.NewDuration(unit),
+				"location": locationRecord(tc.location),
+			})
+			got, err := SpecialFns["truncate"].Call(dependenciestest.Default().Inject(context.Background()), fluxArg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustParseTime(t, tc.want); want != got.Time() {
+				t.Errorf("expected %v, got %v", want, got.Time())
+			}
+		})
+	}
+}
+
+func TestConvertTimezone(t *testing.T) {
+	testCases := []struct {
+		name string
+		time string
+		from string
+		to   string
+		want string
+	}{
+		{
+			name: "from UTC",
+			time: "2021-03-14T12:00:00Z",
+			to:   "America/New_York",
+			want: "2021-03-14T08:00:00Z",
+		},
+		{
+			name: "before DST",
+			time: "2021-03-13T12:00:00Z",
+			to:   "America/New_York",
+			want: "2021-03-13T07:00:00Z",
+		},
+		{
+			name: "between zones",
+			time: "2021-03-14T12:00:00Z",
+			from: "Asia/Tokyo",
+			to:   "Europe/Paris",
+			want: "2021-03-14T04:00:00Z",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			args := map[string]values.Value{
+				"t":  values.NewTime(mustParseTime(t, tc.time)),
+				"to": values.NewString(tc.to),
+			}
+			if tc.from != "" {
+				args["from"] = values.NewString(tc.from)
+			}
+			got, err := SpecialFns["convertTimezone"].Call(dependenciestest.Default().Inject(context.Background()), values.NewObjectWithValues(args))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustParseTime(t, tc.want); want != got.Time() {
+				t.Errorf("expected %v, got %v", want, got.Time())
+			}
+		})
+	}
+}
+
+func TestConvertTimezoneUnknownZone(t *testing.T) {
+	fluxArg := values.NewObjectWithValues(map[string]values.Value{
+		"t":  values.NewTime(mustParseTime(t, "2021-03-14T12:00:00Z")),
+		"to": values.NewString("Mars/Olympus_Mons"),
+	})
+	if _, err := SpecialFns["convertTimezone"].Call(dependenciestest.Default().Inject(context.Background()), fluxArg); err == nil {
+		t.Error("expected an error for an unknown time zone")
+	}
+}
+
+func TestIsWeekend(t *testing.T) {
+	testCases := []struct {
+		name     string
+		time     string
+		location string
+		want     bool
+	}{
+		{name: "friday", time: "2021-03-12T12:00:00Z", want: false},
+		{name: "saturday", time: "2021-03-13T02:00:00Z", want: true},
+		{name: "friday in location", time: "2021-03-13T02:00:00Z", location: "America/New_York", want: false},
+		{name: "monday in location", time: "2021-03-14T20:00:00Z", location: "Asia/Tokyo", want: false},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			args := map[string]values.Value{
+				"t": values.NewTime(mustParseTime(t, tc.time)),
+			}
+			if tc.location != "" {
+				args["location"] = locationRecord(tc.location)
+			}
+			got, err := SpecialFns["isWeekend"].Call(dependenciestest.Default().Inject(context.Background()), values.NewObjectWithValues(args))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.want != got.Bool() {
+				t.Errorf("expected %v, got %v", tc.want, got.Bool())
+			}
+		})
+	}
+}
+
+func TestAddBusinessDays(t *testing.T) {
+	testCases := []struct {
+		name     string
+		time     string
+		n        int64
+		location string
+		want     string
+	}{
+		{name: "zero", time: "2021-03-13T09:00:00Z", n: 0, want: "2021-03-13T09:00:00Z"},
+		{name: "over weekend", time: "2021-03-12T09:00:00Z", n: 1, want: "2021-03-15T09:00:00Z"},
+		{name: "weeks", time: "2021-03-10T09:00:00Z", n: 12, want: "2021-03-26T09:00:00Z"},
+		{name: "from saturday", time: "2021-03-13T09:00:00Z", n: 5, want: "2021-03-19T09:00:00Z"},
+		{name: "backwards", time: "2021-03-15T09:00:00Z", n: -6, want: "2021-03-05T09:00:00Z"},
+		{name: "backwards from sunday", time: "2021-03-14T09:00:00Z", n: -1, want: "2021-03-12T09:00:00Z"},
+		{name: "over DST", time: "2021-03-12T14:00:00Z", n: 1, location: "America/New_York", want: "2021-03-15T13:00:00Z"},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			args := map[string]values.Value{
+				"t": values.NewTime(mustParseTime(t, tc.time)),
+				"n": values.NewInt(tc.n),
+			}
+			if tc.location != "" {
+				args["location"] = locationRecord(tc.location)
+			}
+			got, err := SpecialFns["addBusinessDays"].Call(dependenciestest.Default().Inject(context.Background()), values.NewObjectWithValues(args))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := mustParseTime(t, tc.want); want != got.Time() {
+				t.Errorf("expected %v, got %v", want, got.Time())
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package date
+
+import (
+	"context"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/zoneinfo"
+	"github.com/influxdata/flux/interval"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// location is a location record as returned by the timezone package,
+// a named zone with an additional offset.
+type location struct {
+	name   string
+	zone   *zoneinfo.Location
+	offset values.Duration
+}
+
+var utc = location{name: "UTC", zone: zoneinfo.UTC}
+
+// getLocation reads the optional location argument, it defaults to UTC.
+func getLocation(args values.Object) (location, error) {
+	v, ok := args.Get("location")
+	if !ok {
+		return utc, nil
+	}
+	if v.Type().Nature() != semantic.Object {
+		return location{}, errors.Newf(codes.Invalid, "location must be a record, got %v", v.Type().Nature())
+	}
+	name, ok := v.Object().Get("zone")
+	if !ok {
+		return location{}, errors.New(codes.Invalid, "zone property missing from location record")
+	} else if got := name.Type().Nature(); got != semantic.String {
+		return location{}, errors.Newf(codes.Invalid, "zone property for location must be of type %s, got %s", semantic.String, got)
+	}
+	zone, err := loadZone(name.Str())
+	if err != nil {
+		return location{}, err
+	}
+	loc := location{name: name.Str(), zone: zone}
+	if offset, ok := v.Object().Get("offset"); ok {
+		if got := offset.Type().Nature(); got != semantic.Duration {
+			return location{}, errors.Newf(codes.Invalid, "offset property for location must be of type %s, got %s", semantic.Duration, got)
+		}
+		loc.offset = offset.Duration()
+	}
+	return loc, nil
+}
+
+func loadZone(name string) (*zoneinfo.Location, error) {
+	zone, err := zoneinfo.LoadLocation(name)
+	if err != nil {
+		return nil, errors.Wrapf(err, codes.Invalid, "invalid time zone %q", name)
+	}
+	return zone, nil
+}
+
+// clock returns the clock time of t in the location as a UTC time.
+func (l location) clock(t values.Time) values.Time {
+	return values.Time(l.zone.FromLocalClock(int64(t))).Add(l.offset)
+}
+
+// instant returns the time whose clock time in the location is c.
+// Clock times skipped by a zone transition resolve to the transition,
+// and clock times that happen twice resolve to the earliest time.
+func (l location) instant(c values.Time) values.Time {
+	return values.Time(l.zone.ToLocalClock(int64(c.Add(l.offset.Mul(-1)))))
+}
+
+// getTime reads the t argument, durations are relative to now.
+func getTime(ctx context.Context, args values.Object) (values.Time, error) {
+	v, ok := args.Get("t")
+	if !ok {
+		return 0, errors.New(codes.Invalid, "missing argument t")
+	}
+	if v == nil {
+		return 0, errors.New(codes.FailedPrecondition, "argument t was nil")
+	}
+	switch v.Type().Nature() {
+	case semantic.Time:
+		return v.Time(), nil
+	case semantic.Duration:
+		deps := execute.GetExecutionDependencies(ctx)
+		return values.ConvertTime(*deps.Now).Add(v.Duration()), nil
+	default:
+		return 0, errors.Newf(codes.FailedPrecondition, "cannot convert argument t of type %v to time", v.Type().Nature())
+	}
+}
+
+// truncateInLocation truncates t to the unit using the clock time of the location,
+// so that units of a day or more start at midnight across daylight saving time changes.
+func truncateInLocation(t values.Time, unit values.Duration, loc location) (values.Time, error) {
+	l, err := interval.LoadLocation(loc.name)
+	if err != nil {
+		return 0, err
+	}
+	l.Offset = loc.offset
+	w, err := interval.NewWindowInLocation(unit, unit, values.Duration{}, l)
+	if err != nil {
+		return 0, err
+	}
+	return w.GetLatestBounds(t).Start(), nil
+}
+
+func convertTimezone(ctx context.Context, args values.Object) (values.Value, error) {
+	t, err := getTime(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	from := zoneinfo.UTC
+	if v, ok := args.Get("from"); ok {
+		if from, err = loadZone(v.Str()); err != nil {
+			return nil, err
+		}
+	}
+	v, ok := args.Get("to")
+	if !ok {
+		return nil, errors.New(codes.Invalid, "missing argument to")
+	}
+	to, err := loadZone(v.Str())
+	if err != nil {
+		return nil, err
+	}
+	return values.NewTime(values.Time(to.FromLocalClock(from.ToLocalClock(int64(t))))), nil
+}
+
+func isWeekend(ctx context.Context, args values.Object) (values.Value, error) {
+	t, err := getTime(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := getLocation(args)
+	if err != nil {
+		return nil, err
+	}
+	return values.NewBool(weekend(loc.clock(t).Time().Weekday())), nil
+}
+
+func addBusinessDays(ctx context.Context, args values.Object) (values.Value, error) {
+	t, err := getTime(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := args.Get("n")
+	if !ok {
+		return nil, errors.New(codes.Invalid, "missing argument n")
+	}
+	loc, err := getLocation(args)
+	if err != nil {
+		return nil, err
+	}
+	c := businessDays(loc.clock(t).Time(), int(v.Int()))
+	return values.NewTime(loc.instant(values.ConvertTime(c))), nil
+}
+
+func weekend(d time.Weekday) bool {
+	return d == time.Saturday || d == time.Sunday
+}
+
+// businessDays adds n business days to the clock time c,
+// the time of day is kept. A clock time on a weekend first moves
+// back to the previous business day when n is positive, or forward
+// to the next business day when n is negative.
+func businessDays(c time.Time, n int) time.Time {
+	if n == 0 {
+		return c
+	}
+	step := 1
+	if n < 0 {
+		step, n = -1, -n
+	}
+	for weekend(c.Weekday()) {
+		c = c.AddDate(0, 0, -step)
+	}
+	c = c.AddDate(0, 0, step*7*(n/5))
+	for n %= 5; n > 0; {
+		c = c.AddDate(0, 0, step)
+		if !weekend(c.Weekday()) {
+			n--
+		}
+	}
+	return c
+}
@@ -134,10 +134,52 @@ func Remove(args interpreter.Arguments) (values.Value, error) {
 	return dict.Remove(key), nil
 }
 
+// Keys will return the keys of a Dictionary
+// in ascending order.
+func Keys(args interpreter.Arguments) (values.Value, error) {
+	dict, err := args.GetRequiredDictionary("dict")
+	if err != nil {
+		return nil, err
+	}
+
+	keyType, err := dict.Type().KeyType()
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]values.Value, 0, dict.Len())
+	dict.Range(func(key, value values.Value) {
+		elements = append(elements, key)
+	})
+	return values.NewArrayWithBacking(semantic.NewArrayType(keyType), elements), nil
+}
+
+// Values will return the values of a Dictionary
+// in the ascending order of their keys.
+func Values(args interpreter.Arguments) (values.Value, error) {
+	dict, err := args.GetRequiredDictionary("dict")
+	if err != nil {
+		return nil, err
+	}
+
+	valueType, err := dict.Type().ValueType()
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]values.Value, 0, dict.Len())
+	dict.Range(func(key, value values.Value) {
+		elements = append(elements, value)
+	})
+	return values.NewArrayWithBacking(semantic.NewArrayType(valueType), elements), nil
+}
+
 func init() {
 	b := function.ForPackage(pkgpath)
 	b.Register("fromList", FromList)
 	b.Register("get", Get)
 	b.Register("insert", Insert)
+	b.Register("keys", Keys)
 	b.Register("remove", Remove)
+	b.Register("values", Values)
 }
@@ -185,3 +185,74 @@ func TestRemove(t *testing.T) {
 		t.Errorf("unexpected values -want/+got:\n%s", cmp.Diff(want, got))
 	}
 }
+
+func TestKeys(t *testing.T) {
+	args := interpreter.NewArguments(values.NewObjectWithValues(
+		map[string]values.Value{
+			"dict": func() values.Dictionary {
+				dictType := semantic.NewDictType(semantic.BasicString, semantic.BasicInt)
+				b := values.NewDictBuilder(dictType)
+				b.Insert(values.NewString("c"), values.NewInt(12))
+				b.Insert(values.NewString("a"), values.NewInt(4))
+				b.Insert(values.NewString("b"), values.NewInt(8))
+				return b.Dict()
+			}(),
+		},
+	))
+
+	v, err := dict.Keys(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Should be an array of strings.
+	if want, got := semantic.NewArrayType(semantic.BasicString), v.Type(); !want.Equal(got) {
+		t.Fatalf("unexpected type -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	var got []string
+	v.Array().Range(func(i int, v values.Value) {
+		got = append(got, v.Str())
+	})
+
+	want := []string{"a", "b", "c"}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected keys -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestValues(t *testing.T) {
+	args := interpreter.NewArguments(values.NewObjectWithValues(
+		map[string]values.Value{
+			"dict": func() values.Dictionary {
+				dictType := semantic.NewDictType(semantic.BasicString, semantic.BasicInt)
+				b := values.NewDictBuilder(dictType)
+				b.Insert(values.NewString("c"), values.NewInt(4))
+				b.Insert(values.NewString("a"), values.NewInt(12))
+				b.Insert(values.NewString("b"), values.NewInt(8))
+				return b.Dict()
+			}(),
+		},
+	))
+
+	v, err := dict.Values(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// Should be an array of ints.
+	if want, got := semantic.NewArrayType(semantic.BasicInt), v.Type(); !want.Equal(got) {
+		t.Fatalf("unexpected type -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	var got []int64
+	v.Array().Range(func(i int, v values.Value) {
+		got = append(got, v.Int())
+	})
+
+	// Values are in the order of their keys.
+	want := []int64{12, 8, 4}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected values -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
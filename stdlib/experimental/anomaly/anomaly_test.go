@@ -0,0 +1,133 @@
+package anomaly_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/stdlib/experimental/anomaly"
+)
+
+func input() []flux.Table {
+	return []flux.Table{&executetest.Table{
+		KeyCols: []string{"host"},
+		ColMeta: []flux.ColMeta{
+			{Label: "host", Type: flux.TString},
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: flux.TFloat},
+		},
+		Data: [][]interface{}{
+			{"a", execute.Time(0), 10.0},
+			{"a", execute.Time(1), 11.0},
+			{"a", execute.Time(2), 9.0},
+			{"a", execute.Time(3), 10.0},
+			{"a", execute.Time(4), 12.0},
+			{"a", execute.Time(5), 10.0},
+			{"a", execute.Time(6), 50.0},
+			{"a", execute.Time(7), 11.0},
+			{"a", execute.Time(8), 9.0},
+			{"a", execute.Time(9), 10.0},
+			{"a", execute.Time(10), nil},
+		},
+	}}
+}
+
+func output() []*executetest.Table {
+	return []*executetest.Table{{
+		KeyCols: []string{"host"},
+		ColMeta: []flux.ColMeta{
+			{Label: "host", Type: flux.TString},
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_value", Type: flux.TFloat},
+			{Label: "score", Type: flux.TFloat},
+			{Label: "isAnomaly", Type: flux.TBool},
+		},
+		Data: [][]interface{}{
+			{"a", execute.Time(0), 10.0, 0.0, false},
+			{"a", execute.Time(1), 11.0, 0.6744907594765952, false},
+			{"a", execute.Time(2), 9.0, 0.6744907594765952, false},
+			{"a", execute.Time(3), 10.0, 0.0, false},
+			{"a", execute.Time(4), 12.0, 1.3489815189531904, false},
+			{"a", execute.Time(5), 10.0, 0.0, false},
+			{"a", execute.Time(6), 50.0, 26.97963037906381, true},
+			{"a", execute.Time(7), 11.0, 0.6744907594765952, false},
+			{"a", execute.Time(8), 9.0, 0.6744907594765952, false},
+			{"a", execute.Time(9), 10.0, 0.0, false},
+			{"a", execute.Time(10), nil, nil, false},
+		},
+	}}
+}
+
+func TestMAD_Process(t *testing.T) {
+	executetest.ProcessTestHelper(
+		t,
+		input(),
+		output(),
+		nil,
+		func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+			return anomaly.NewMADTransformation(d, c, &anomaly.MADProcedureSpec{
+				Column:    "_value",
+				Threshold: anomaly.DefaultMADThreshold,
+			})
+		},
+	)
+}
+
+func TestSeasonalESD_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *anomaly.SeasonalESDProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "not seasonal",
+			spec: &anomaly.SeasonalESDProcedureSpec{
+				Column:       "_value",
+				Alpha:        anomaly.DefaultESDAlpha,
+				MaxAnomalies: 0.2,
+			},
+			data: input(),
+			want: output(),
+		},
+		{
+			name: "too few periods",
+			spec: &anomaly.SeasonalESDProcedureSpec{
+				Column:       "_value",
+				Period:       6,
+				Alpha:        anomaly.DefaultESDAlpha,
+				MaxAnomalies: anomaly.DefaultESDMaxAnomalies,
+			},
+			data:    input(),
+			wantErr: errors.New(codes.FailedPrecondition, "anomaly.seasonalESD needs at least two periods of 6 values, got 10 values"),
+		},
+		{
+			name: "missing column",
+			spec: &anomaly.SeasonalESDProcedureSpec{
+				Column:       "usage",
+				Alpha:        anomaly.DefaultESDAlpha,
+				MaxAnomalies: anomaly.DefaultESDMaxAnomalies,
+			},
+			data:    input(),
+			wantErr: errors.New(codes.FailedPrecondition, "anomaly.seasonalESD: column \"usage\" does not exist"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return anomaly.NewSeasonalESDTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package anomaly
+
+import (
+	"math"
+	"sort"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+const (
+	pkgpath = "experimental/anomaly"
+
+	scoreColLabel     = "score"
+	isAnomalyColLabel = "isAnomaly"
+
+	// madScale makes the median absolute deviation a consistent
+	// estimator of the standard deviation of normally distributed values.
+	madScale = 1.4826
+)
+
+// detector scores the values of a table and reports which ones are anomalies.
+// The values do not contain nulls.
+type detector func(xs []float64) (scores []float64, anomalies []bool, err error)
+
+// detectTransformation adds the score and isAnomaly columns to each table.
+// The detectors need all the values of a table, so the values are
+// collected while the table is copied, and the new columns are
+// appended once the whole table has been read.
+type detectTransformation struct {
+	execute.ExecutionNode
+	d      execute.Dataset
+	cache  execute.TableBuilderCache
+	name   string
+	column string
+	detect detector
+}
+
+func newDetectTransformation(d execute.Dataset, cache execute.TableBuilderCache, name, column string, detect detector) *detectTransformation {
+	return &detectTransformation{
+		d:      d,
+		cache:  cache,
+		name:   name,
+		column: column,
+		detect: detect,
+	}
+}
+
+func (t *detectTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *detectTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	cols := tbl.Cols()
+	vi := execute.ColIdx(t.column, cols)
+	if vi < 0 {
+		return errors.Newf(codes.FailedPrecondition, "%s: column %q does not exist", t.name, t.column)
+	}
+	switch typ := cols[vi].Type; typ {
+	case flux.TFloat, flux.TInt, flux.TUInt:
+	default:
+		return errors.Newf(codes.FailedPrecondition, "%s: cannot detect anomalies in %v values; expected numeric values", t.name, typ)
+	}
+	for _, label := range []string{scoreColLabel, isAnomalyColLabel} {
+		if execute.HasCol(label, cols) {
+			return errors.Newf(codes.FailedPrecondition, "%s: table already has a %q column", t.name, label)
+		}
+	}
+
+	b, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition, "%s: duplicate table with key: %v", t.name, tbl.Key())
+	}
+	if err := execute.AddTableCols(tbl, b); err != nil {
+		return err
+	}
+	si, err := b.AddCol(flux.ColMeta{Label: scoreColLabel, Type: flux.TFloat})
+	if err != nil {
+		return err
+	}
+	ai, err := b.AddCol(flux.ColMeta{Label: isAnomalyColLabel, Type: flux.TBool})
+	if err != nil {
+		return err
+	}
+
+	// valid records whether each row has a value,
+	// the rows without a value are not scored.
+	var (
+		xs    []float64
+		valid []bool
+	)
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		for j := range cols {
+			if err := execute.AppendCol(j, j, cr, b); err != nil {
+				return err
+			}
+		}
+		for i, l := 0, cr.Len(); i < l; i++ {
+			var (
+				x  float64
+				ok bool
+			)
+			switch cols[vi].Type {
+			case flux.TFloat:
+				vs := cr.Floats(vi)
+				x, ok = vs.Value(i), vs.IsValid(i)
+			case flux.TInt:
+				vs := cr.Ints(vi)
+				x, ok = float64(vs.Value(i)), vs.IsValid(i)
+			case flux.TUInt:
+				vs := cr.UInts(vi)
+				x, ok = float64(vs.Value(i)), vs.IsValid(i)
+			}
+			if ok {
+				xs = append(xs, x)
+			}
+			valid = append(valid, ok)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	var (
+		scores    []float64
+		anomalies []bool
+	)
+	if len(xs) > 0 {
+		scores, anomalies, err = t.detect(xs)
+		if err != nil {
+			return err
+		}
+	}
+	k := 0
+	for _, ok := range valid {
+		if !ok {
+			if err := b.AppendNil(si); err != nil {
+				return err
+			}
+			if err := b.AppendBool(ai, false); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := b.AppendFloat(si, scores[k]); err != nil {
+			return err
+		}
+		if err := b.AppendBool(ai, anomalies[k]); err != nil {
+			return err
+		}
+		k++
+	}
+	return nil
+}
+
+func (t *detectTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *detectTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *detectTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+// median returns the median of the values, the values are not modified.
+func median(xs []float64) float64 {
+	sorted := make([]float64, len(xs))
+	copy(sorted, xs)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// robustScores returns the median of the values, their scaled median absolute
+// deviation, and the distance of each value to the median divided by the deviation.
+// When the deviation is zero, the values that are not the median have an infinite score.
+func robustScores(xs []float64) (med, mad float64, scores []float64) {
+	med = median(xs)
+	deviations := make([]float64, len(xs))
+	for i, x := range xs {
+		deviations[i] = math.Abs(x - med)
+	}
+	mad = madScale * median(deviations)
+	scores = make([]float64, len(xs))
+	for i, d := range deviations {
+		scores[i] = score(d, mad)
+	}
+	return med, mad, scores
+}
+
+func score(deviation, mad float64) float64 {
+	if mad == 0 {
+		if deviation == 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return deviation / mad
+}
@@ -0,0 +1,209 @@
+package anomaly
+
+import (
+	"math"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+const (
+	SeasonalESDKind = pkgpath + ".seasonalESD"
+
+	DefaultESDAlpha        = 0.05
+	DefaultESDMaxAnomalies = 0.1
+)
+
+type SeasonalESDOpSpec struct {
+	Column       string  `json:"column"`
+	Period       int64   `json:"period"`
+	Alpha        float64 `json:"alpha"`
+	MaxAnomalies float64 `json:"maxAnomalies"`
+}
+
+func init() {
+	seasonalESDSignature := runtime.MustLookupBuiltinType(pkgpath, "seasonalESD")
+
+	runtime.RegisterPackageValue(pkgpath, "seasonalESD", flux.MustValue(flux.FunctionValue(SeasonalESDKind, createSeasonalESDOpSpec, seasonalESDSignature)))
+	flux.RegisterOpSpec(SeasonalESDKind, newSeasonalESDOp)
+	plan.RegisterProcedureSpec(SeasonalESDKind, newSeasonalESDProcedure, SeasonalESDKind)
+	execute.RegisterTransformation(SeasonalESDKind, createSeasonalESDTransformation)
+}
+
+func createSeasonalESDOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := &SeasonalESDOpSpec{
+		Column:       execute.DefaultValueColLabel,
+		Alpha:        DefaultESDAlpha,
+		MaxAnomalies: DefaultESDMaxAnomalies,
+	}
+	if column, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = column
+	}
+	if period, ok, err := args.GetInt("period"); err != nil {
+		return nil, err
+	} else if ok {
+		if period < 0 {
+			return nil, errors.Newf(codes.Invalid, "period cannot be negative, got %d", period)
+		}
+		spec.Period = period
+	}
+	if alpha, ok, err := args.GetFloat("alpha"); err != nil {
+		return nil, err
+	} else if ok {
+		if alpha <= 0 || alpha >= 1 {
+			return nil, errors.Newf(codes.Invalid, "alpha must be between 0 and 1, got %v", alpha)
+		}
+		spec.Alpha = alpha
+	}
+	if maxAnomalies, ok, err := args.GetFloat("maxAnomalies"); err != nil {
+		return nil, err
+	} else if ok {
+		if maxAnomalies <= 0 || maxAnomalies > 0.5 {
+			return nil, errors.Newf(codes.Invalid, "maxAnomalies must be greater than 0 and at most 0.5, got %v", maxAnomalies)
+		}
+		spec.MaxAnomalies = maxAnomalies
+	}
+	return spec, nil
+}
+
+func newSeasonalESDOp() flux.OperationSpec {
+	return new(SeasonalESDOpSpec)
+}
+
+func (s *SeasonalESDOpSpec) Kind() flux.OperationKind {
+	return SeasonalESDKind
+}
+
+type SeasonalESDProcedureSpec struct {
+	plan.DefaultCost
+	Column       string
+	Period       int64
+	Alpha        float64
+	MaxAnomalies float64
+}
+
+func newSeasonalESDProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*SeasonalESDOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &SeasonalESDProcedureSpec{
+		Column:       spec.Column,
+		Period:       spec.Period,
+		Alpha:        spec.Alpha,
+		MaxAnomalies: spec.MaxAnomalies,
+	}, nil
+}
+
+func (s *SeasonalESDProcedureSpec) Kind() plan.ProcedureKind {
+	return SeasonalESDKind
+}
+func (s *SeasonalESDProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createSeasonalESDTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*SeasonalESDProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewSeasonalESDTransformation(d, cache, s)
+	return t, d, nil
+}
+
+func NewSeasonalESDTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *SeasonalESDProcedureSpec) execute.Transformation {
+	return newDetectTransformation(d, cache, "anomaly.seasonalESD", spec.Column, seasonalESDDetector(int(spec.Period), spec.Alpha, spec.MaxAnomalies))
+}
+
+// seasonalESDDetector implements the seasonal hybrid ESD test.
+// The seasonal component, the median of the values at the same position
+// in each period, is removed from the values, then the generalized ESD test
+// finds the anomalies among the residuals using their median and MAD
+// instead of their mean and standard deviation.
+func seasonalESDDetector(period int, alpha, maxAnomalies float64) detector {
+	return func(xs []float64) ([]float64, []bool, error) {
+		n := len(xs)
+		residuals := make([]float64, n)
+		copy(residuals, xs)
+		if period > 1 {
+			if n < 2*period {
+				return nil, nil, errors.Newf(codes.FailedPrecondition,
+					"anomaly.seasonalESD needs at least two periods of %d values, got %d values", period, n)
+			}
+			med := median(xs)
+			phase := make([]float64, 0, n/period+1)
+			for p := 0; p < period; p++ {
+				phase = phase[:0]
+				for i := p; i < n; i += period {
+					phase = append(phase, xs[i])
+				}
+				seasonal := median(phase) - med
+				for i := p; i < n; i += period {
+					residuals[i] -= seasonal
+				}
+			}
+		}
+
+		_, _, scores := robustScores(residuals)
+		anomalies := make([]bool, n)
+
+		// candidates are the indexes of the residuals still in the test,
+		// the most extreme one is removed at each step.
+		candidates := make([]int, n)
+		for i := range candidates {
+			candidates[i] = i
+		}
+		removed := make([]int, 0)
+		numAnomalies := 0
+		values := make([]float64, 0, n)
+		for step := 0; step < int(maxAnomalies*float64(n)); step++ {
+			m := len(candidates)
+			if m < 3 {
+				break
+			}
+			values = values[:0]
+			for _, i := range candidates {
+				values = append(values, residuals[i])
+			}
+			med, mad, _ := robustScores(values)
+			if mad == 0 {
+				break
+			}
+			extreme, r := 0, -1.0
+			for k, i := range candidates {
+				if d := math.Abs(residuals[i] - med); d > r {
+					extreme, r = k, d
+				}
+			}
+			r /= mad
+
+			p := 1 - alpha/(2*float64(m))
+			t := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: float64(m - 2)}.Quantile(p)
+			lambda := float64(m-1) * t / math.Sqrt((float64(m-2)+t*t)*float64(m))
+
+			removed = append(removed, candidates[extreme])
+			candidates = append(candidates[:extreme], candidates[extreme+1:]...)
+			if r > lambda {
+				numAnomalies = len(removed)
+			}
+		}
+		for _, i := range removed[:numAnomalies] {
+			anomalies[i] = true
+		}
+		return scores, anomalies, nil
+	}
+}
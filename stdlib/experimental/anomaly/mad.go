@@ -0,0 +1,115 @@
+package anomaly
+
+import (
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const (
+	MADKind = pkgpath + ".mad"
+
+	DefaultMADThreshold = 3.0
+)
+
+type MADOpSpec struct {
+	Column    string  `json:"column"`
+	Threshold float64 `json:"threshold"`
+}
+
+func init() {
+	madSignature := runtime.MustLookupBuiltinType(pkgpath, "mad")
+
+	runtime.RegisterPackageValue(pkgpath, "mad", flux.MustValue(flux.FunctionValue(MADKind, createMADOpSpec, madSignature)))
+	flux.RegisterOpSpec(MADKind, newMADOp)
+	plan.RegisterProcedureSpec(MADKind, newMADProcedure, MADKind)
+	execute.RegisterTransformation(MADKind, createMADTransformation)
+}
+
+func createMADOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := &MADOpSpec{
+		Column:    execute.DefaultValueColLabel,
+		Threshold: DefaultMADThreshold,
+	}
+	if column, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = column
+	}
+	if threshold, ok, err := args.GetFloat("threshold"); err != nil {
+		return nil, err
+	} else if ok {
+		if threshold <= 0 {
+			return nil, errors.Newf(codes.Invalid, "threshold must be positive, got %v", threshold)
+		}
+		spec.Threshold = threshold
+	}
+	return spec, nil
+}
+
+func newMADOp() flux.OperationSpec {
+	return new(MADOpSpec)
+}
+
+func (s *MADOpSpec) Kind() flux.OperationKind {
+	return MADKind
+}
+
+type MADProcedureSpec struct {
+	plan.DefaultCost
+	Column    string
+	Threshold float64
+}
+
+func newMADProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*MADOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &MADProcedureSpec{
+		Column:    spec.Column,
+		Threshold: spec.Threshold,
+	}, nil
+}
+
+func (s *MADProcedureSpec) Kind() plan.ProcedureKind {
+	return MADKind
+}
+func (s *MADProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createMADTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*MADProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewMADTransformation(d, cache, s)
+	return t, d, nil
+}
+
+func NewMADTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *MADProcedureSpec) execute.Transformation {
+	return newDetectTransformation(d, cache, "anomaly.mad", spec.Column, madDetector(spec.Threshold))
+}
+
+// madDetector flags the values whose score is at least the threshold.
+func madDetector(threshold float64) detector {
+	return func(xs []float64) ([]float64, []bool, error) {
+		_, _, scores := robustScores(xs)
+		anomalies := make([]bool, len(xs))
+		for i, s := range scores {
+			anomalies[i] = s >= threshold
+		}
+		return scores, anomalies, nil
+	}
+}
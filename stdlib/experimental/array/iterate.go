@@ -0,0 +1,57 @@
+package array
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/values"
+)
+
+const pkgpath = "experimental/array"
+
+// Iterate calls fn n times, passing the state returned by each call to
+// the next one. The calls are made in a loop so, unlike a chain of nested
+// calls, they do not grow the call stack.
+func Iterate(ctx context.Context, args interpreter.Arguments) (values.Value, error) {
+	n, err := args.GetRequiredInt("n")
+	if err != nil {
+		return nil, err
+	} else if n < 0 {
+		return nil, errors.Newf(codes.Invalid, "n must not be negative, got %d", n)
+	}
+	state, err := args.GetRequired("init")
+	if err != nil {
+		return nil, err
+	}
+	fn, err := args.GetRequiredFunction("fn")
+	if err != nil {
+		return nil, err
+	}
+
+	for i := int64(0); i < n; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, errors.Wrap(err, codes.Canceled)
+		}
+		state, err = fn.Call(ctx, values.NewObjectWithValues(map[string]values.Value{
+			"state": state,
+			"i":     values.NewInt(i),
+		}))
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Inherit, "error at iteration %d", i)
+		}
+	}
+	return state, nil
+}
+
+func init() {
+	runtime.RegisterPackageValue(pkgpath, "iterate", values.NewFunction(
+		"iterate",
+		runtime.MustLookupBuiltinType(pkgpath, "iterate"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			return interpreter.DoFunctionCallContext(Iterate, ctx, args)
+		}, false,
+	))
+}
@@ -0,0 +1,66 @@
+package array_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/stdlib/experimental/array"
+	"github.com/influxdata/flux/values"
+)
+
+// sumFn adds the index of each call to the state.
+var sumFn = values.NewFunction(
+	"sum",
+	semantic.NewFunctionType(semantic.BasicInt, []semantic.ArgumentType{
+		{Name: []byte("state"), Type: semantic.BasicInt},
+		{Name: []byte("i"), Type: semantic.BasicInt},
+	}),
+	func(ctx context.Context, args values.Object) (values.Value, error) {
+		state, _ := args.Get("state")
+		i, _ := args.Get("i")
+		return values.NewInt(state.Int() + i.Int()), nil
+	}, false,
+)
+
+func iterateArgs(n int64) interpreter.Arguments {
+	return interpreter.NewArguments(values.NewObjectWithValues(map[string]values.Value{
+		"n":    values.NewInt(n),
+		"init": values.NewInt(0),
+		"fn":   sumFn,
+	}))
+}
+
+func TestIterate(t *testing.T) {
+	// The number of calls is far beyond the
+	// maximum depth of nested calls.
+	n := int64(10 * interpreter.DefaultMaxCallDepth)
+	v, err := array.Iterate(context.Background(), iterateArgs(n))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want, got := n*(n-1)/2, v.Int(); want != got {
+		t.Errorf("unexpected result -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+
+	v, err = array.Iterate(context.Background(), iterateArgs(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if want, got := int64(0), v.Int(); want != got {
+		t.Errorf("unexpected result -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+}
+
+func TestIterate_Negative(t *testing.T) {
+	_, err := array.Iterate(context.Background(), iterateArgs(-1))
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
@@ -0,0 +1,228 @@
+package array
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	modePopulation = "population"
+	modeSample     = "sample"
+
+	methodExactMean     = "exact_mean"
+	methodExactSelector = "exact_selector"
+)
+
+// numbers returns the elements of a numeric array as floats.
+// Null elements, which arrays built with findColumn may contain, are skipped.
+func numbers(args interpreter.Arguments) ([]float64, error) {
+	v, err := args.GetRequired("arr")
+	if err != nil {
+		return nil, err
+	}
+	arr := v.Array()
+	if arr.Len() == 0 {
+		return nil, nil
+	}
+	elemType, err := arr.Type().ElemType()
+	if err != nil {
+		return nil, err
+	}
+	nature := elemType.Nature()
+	if nature != semantic.Int && nature != semantic.UInt && nature != semantic.Float {
+		return nil, errors.Newf(codes.Invalid, "arr must be an array of numbers, got elements of type %v", elemType)
+	}
+	xs := make([]float64, 0, arr.Len())
+	arr.Range(func(i int, v values.Value) {
+		if v.IsNull() {
+			return
+		}
+		switch nature {
+		case semantic.Int:
+			xs = append(xs, float64(v.Int()))
+		case semantic.UInt:
+			xs = append(xs, float64(v.UInt()))
+		default:
+			xs = append(xs, v.Float())
+		}
+	})
+	return xs, nil
+}
+
+// Sum returns the sum of the elements of an array with the type of its elements.
+func Sum(args interpreter.Arguments) (values.Value, error) {
+	v, err := args.GetRequired("arr")
+	if err != nil {
+		return nil, err
+	}
+	arr := v.Array()
+	elemType, err := arr.Type().ElemType()
+	if err != nil {
+		return nil, err
+	}
+	switch elemType.Nature() {
+	case semantic.Int:
+		var sum int64
+		arr.Range(func(i int, v values.Value) {
+			if !v.IsNull() {
+				sum += v.Int()
+			}
+		})
+		return values.NewInt(sum), nil
+	case semantic.UInt:
+		var sum uint64
+		arr.Range(func(i int, v values.Value) {
+			if !v.IsNull() {
+				sum += v.UInt()
+			}
+		})
+		return values.NewUInt(sum), nil
+	case semantic.Float:
+		var sum float64
+		arr.Range(func(i int, v values.Value) {
+			if !v.IsNull() {
+				sum += v.Float()
+			}
+		})
+		return values.NewFloat(sum), nil
+	default:
+		return nil, errors.Newf(codes.Invalid, "arr must be an array of numbers, got elements of type %v", elemType)
+	}
+}
+
+// Mean returns the mean of the elements of an array, or null if it is empty.
+func Mean(args interpreter.Arguments) (values.Value, error) {
+	xs, err := numbers(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(xs) == 0 {
+		return values.NewNull(semantic.BasicFloat), nil
+	}
+	return values.NewFloat(mean(xs)), nil
+}
+
+func mean(xs []float64) float64 {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	return sum / float64(len(xs))
+}
+
+// Stddev returns the standard deviation of the elements of an array,
+// or null if there are not enough elements.
+func Stddev(args interpreter.Arguments) (values.Value, error) {
+	mode, ok, err := args.GetString("mode")
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		mode = modeSample
+	} else if mode != modePopulation && mode != modeSample {
+		return nil, errors.Newf(codes.Invalid, "%q is not a valid standard deviation mode", mode)
+	}
+	xs, err := numbers(args)
+	if err != nil {
+		return nil, err
+	}
+
+	n := float64(len(xs))
+	if mode == modeSample {
+		n--
+	}
+	if n <= 0 {
+		return values.NewNull(semantic.BasicFloat), nil
+	}
+	m := mean(xs)
+	var sum float64
+	for _, x := range xs {
+		sum += (x - m) * (x - m)
+	}
+	return values.NewFloat(math.Sqrt(sum / n)), nil
+}
+
+// Percentile returns the value below which the percentage p of the
+// elements of an array fall, or null if it is empty.
+func Percentile(args interpreter.Arguments) (values.Value, error) {
+	p, err := args.GetRequiredFloat("p")
+	if err != nil {
+		return nil, err
+	} else if p < 0 || p > 100 {
+		return nil, errors.Newf(codes.Invalid, "p must be between 0 and 100, got %v", p)
+	}
+	method, ok, err := args.GetString("method")
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		method = methodExactMean
+	} else if method != methodExactMean && method != methodExactSelector {
+		return nil, errors.Newf(codes.Invalid, "unknown method %q, must be %q or %q", method, methodExactMean, methodExactSelector)
+	}
+	xs, err := numbers(args)
+	if err != nil {
+		return nil, err
+	}
+	if len(xs) == 0 {
+		return values.NewNull(semantic.BasicFloat), nil
+	}
+	sort.Float64s(xs)
+
+	q := p / 100
+	if method == methodExactSelector {
+		// the smallest element with at least q of the elements lower or equal
+		index := int(math.Ceil(q * float64(len(xs))))
+		if index > 0 {
+			index--
+		}
+		return values.NewFloat(xs[index]), nil
+	}
+	x := q * float64(len(xs)-1)
+	x0, x1 := math.Floor(x), math.Ceil(x)
+	if x0 == x1 {
+		return values.NewFloat(xs[int(x0)]), nil
+	}
+	// linear interpolation between the closest ranks
+	return values.NewFloat(xs[int(x0)]*(x1-x) + xs[int(x1)]*(x-x0)), nil
+}
+
+func init() {
+	runtime.RegisterPackageValue(pkgpath, "sum", values.NewFunction(
+		"sum",
+		runtime.MustLookupBuiltinType(pkgpath, "sum"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			return interpreter.DoFunctionCall(Sum, args)
+		}, false,
+	))
+
+	runtime.RegisterPackageValue(pkgpath, "mean", values.NewFunction(
+		"mean",
+		runtime.MustLookupBuiltinType(pkgpath, "mean"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			return interpreter.DoFunctionCall(Mean, args)
+		}, false,
+	))
+
+	runtime.RegisterPackageValue(pkgpath, "stddev", values.NewFunction(
+		"stddev",
+		runtime.MustLookupBuiltinType(pkgpath, "stddev"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			return interpreter.DoFunctionCall(Stddev, args)
+		}, false,
+	))
+
+	runtime.RegisterPackageValue(pkgpath, "percentile", values.NewFunction(
+		"percentile",
+		runtime.MustLookupBuiltinType(pkgpath, "percentile"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			return interpreter.DoFunctionCall(Percentile, args)
+		}, false,
+	))
+}
@@ -0,0 +1,144 @@
+package array_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/stdlib/experimental/array"
+	"github.com/influxdata/flux/values"
+)
+
+func statsArgs(arr values.Array, kv ...interface{}) interpreter.Arguments {
+	m := map[string]values.Value{"arr": arr}
+	for i := 0; i < len(kv); i += 2 {
+		m[kv[i].(string)] = values.New(kv[i+1])
+	}
+	return interpreter.NewArguments(values.NewObjectWithValues(m))
+}
+
+func floats(xs ...float64) values.Array {
+	elements := make([]values.Value, len(xs))
+	for i, x := range xs {
+		elements[i] = values.NewFloat(x)
+	}
+	return values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicFloat), elements)
+}
+
+func ints(xs ...int64) values.Array {
+	elements := make([]values.Value, len(xs))
+	for i, x := range xs {
+		elements[i] = values.NewInt(x)
+	}
+	return values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicInt), elements)
+}
+
+func TestStats(t *testing.T) {
+	withNull := values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicFloat), []values.Value{
+		values.NewFloat(1), values.NewNull(semantic.BasicFloat), values.NewFloat(3),
+	})
+	testCases := []struct {
+		name string
+		fn   func(interpreter.Arguments) (values.Value, error)
+		args interpreter.Arguments
+		want values.Value
+	}{
+		{
+			name: "sum ints",
+			fn:   array.Sum,
+			args: statsArgs(ints(1, 2, 3, 4)),
+			want: values.NewInt(10),
+		},
+		{
+			name: "sum floats with null",
+			fn:   array.Sum,
+			args: statsArgs(withNull),
+			want: values.NewFloat(4),
+		},
+		{
+			name: "mean",
+			fn:   array.Mean,
+			args: statsArgs(ints(1, 2, 3, 4)),
+			want: values.NewFloat(2.5),
+		},
+		{
+			name: "mean empty",
+			fn:   array.Mean,
+			args: statsArgs(floats()),
+			want: values.NewNull(semantic.BasicFloat),
+		},
+		{
+			name: "stddev population",
+			fn:   array.Stddev,
+			args: statsArgs(floats(2, 4, 4, 4, 5, 5, 7, 9), "mode", "population"),
+			want: values.NewFloat(2),
+		},
+		{
+			name: "stddev sample",
+			fn:   array.Stddev,
+			args: statsArgs(ints(1, 3)),
+			want: values.NewFloat(1.4142135623730951),
+		},
+		{
+			name: "stddev sample single element",
+			fn:   array.Stddev,
+			args: statsArgs(ints(1)),
+			want: values.NewNull(semantic.BasicFloat),
+		},
+		{
+			name: "percentile exact mean",
+			fn:   array.Percentile,
+			args: statsArgs(ints(40, 10, 30, 20), "p", 50.0),
+			want: values.NewFloat(25),
+		},
+		{
+			name: "percentile exact selector",
+			fn:   array.Percentile,
+			args: statsArgs(ints(40, 10, 30, 20), "p", 50.0, "method", "exact_selector"),
+			want: values.NewFloat(20),
+		},
+		{
+			name: "percentile max",
+			fn:   array.Percentile,
+			args: statsArgs(floats(3, 1, 2), "p", 100.0),
+			want: values.NewFloat(3),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.fn(tc.args)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if tc.want.IsNull() {
+				if !got.IsNull() {
+					t.Errorf("expected null, got %v", got)
+				}
+			} else if !tc.want.Equal(got) {
+				t.Errorf("unexpected result -want/+got:\n\t- %v\n\t+ %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestStats_Invalid(t *testing.T) {
+	for name, args := range map[string]interpreter.Arguments{
+		"percentile out of range": statsArgs(ints(1, 2), "p", 101.0),
+		"unknown method":          statsArgs(ints(1, 2), "p", 50.0, "method", "estimate_tdigest"),
+	} {
+		_, err := array.Percentile(args)
+		if err == nil {
+			t.Fatalf("%s: expected error", name)
+		}
+		if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+			t.Errorf("%s: unexpected error code -want/+got:\n\t- %v\n\t+ %v", name, want, got)
+		}
+	}
+	_, err := array.Stddev(statsArgs(ints(1, 2), "mode", "unbiased"))
+	if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
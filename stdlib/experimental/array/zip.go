@@ -0,0 +1,102 @@
+package array
+
+import (
+	"context"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+// getArray returns the array argument with the name, whatever its element type.
+func getArray(args interpreter.Arguments, name string) (values.Array, error) {
+	v, err := args.GetRequired(name)
+	if err != nil {
+		return nil, err
+	}
+	return v.Array(), nil
+}
+
+// Zip returns an array of records that pair the elements of
+// the arrays a and b with the same index.
+func Zip(args interpreter.Arguments) (values.Value, error) {
+	a, err := getArray(args, "a")
+	if err != nil {
+		return nil, err
+	}
+	b, err := getArray(args, "b")
+	if err != nil {
+		return nil, err
+	}
+	if a.Len() != b.Len() {
+		return nil, errors.Newf(codes.Invalid, "arrays must have the same length, got %d and %d", a.Len(), b.Len())
+	}
+	aType, err := a.Type().ElemType()
+	if err != nil {
+		return nil, err
+	}
+	bType, err := b.Type().ElemType()
+	if err != nil {
+		return nil, err
+	}
+
+	typ := semantic.NewObjectType([]semantic.PropertyType{
+		{Key: []byte("a"), Value: aType},
+		{Key: []byte("b"), Value: bType},
+	})
+	elements := make([]values.Value, a.Len())
+	for i := range elements {
+		record := values.NewObject(typ)
+		record.Set("a", a.Get(i))
+		record.Set("b", b.Get(i))
+		elements[i] = record
+	}
+	return values.NewArrayWithBacking(semantic.NewArrayType(typ), elements), nil
+}
+
+// Enumerate returns an array of records that pair the
+// elements of an array with their index.
+func Enumerate(args interpreter.Arguments) (values.Value, error) {
+	arr, err := getArray(args, "arr")
+	if err != nil {
+		return nil, err
+	}
+	elemType, err := arr.Type().ElemType()
+	if err != nil {
+		return nil, err
+	}
+
+	typ := semantic.NewObjectType([]semantic.PropertyType{
+		{Key: []byte("index"), Value: semantic.BasicInt},
+		{Key: []byte("value"), Value: elemType},
+	})
+	elements := make([]values.Value, arr.Len())
+	arr.Range(func(i int, v values.Value) {
+		record := values.NewObject(typ)
+		record.Set("index", values.NewInt(int64(i)))
+		record.Set("value", v)
+		elements[i] = record
+	})
+	return values.NewArrayWithBacking(semantic.NewArrayType(typ), elements), nil
+}
+
+func init() {
+	runtime.RegisterPackageValue(pkgpath, "zip", values.NewFunction(
+		"zip",
+		runtime.MustLookupBuiltinType(pkgpath, "zip"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			return interpreter.DoFunctionCall(Zip, args)
+		}, false,
+	))
+
+	runtime.RegisterPackageValue(pkgpath, "enumerate", values.NewFunction(
+		"enumerate",
+		runtime.MustLookupBuiltinType(pkgpath, "enumerate"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			return interpreter.DoFunctionCall(Enumerate, args)
+		}, false,
+	))
+}
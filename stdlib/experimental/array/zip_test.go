@@ -0,0 +1,74 @@
+package array_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/stdlib/experimental/array"
+	"github.com/influxdata/flux/values"
+)
+
+func strs(xs ...string) values.Array {
+	elements := make([]values.Value, len(xs))
+	for i, x := range xs {
+		elements[i] = values.NewString(x)
+	}
+	return values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicString), elements)
+}
+
+func TestZip(t *testing.T) {
+	args := interpreter.NewArguments(values.NewObjectWithValues(map[string]values.Value{
+		"a": strs("x", "y"),
+		"b": ints(1, 2),
+	}))
+	v, err := array.Zip(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantType := semantic.NewArrayType(semantic.NewObjectType([]semantic.PropertyType{
+		{Key: []byte("a"), Value: semantic.BasicString},
+		{Key: []byte("b"), Value: semantic.BasicInt},
+	}))
+	if !wantType.Equal(v.Type()) {
+		t.Fatalf("unexpected type -want/+got:\n\t- %v\n\t+ %v", wantType, v.Type())
+	}
+	want := values.NewArrayWithBacking(wantType, []values.Value{
+		values.NewObjectWithValues(map[string]values.Value{"a": values.NewString("x"), "b": values.NewInt(1)}),
+		values.NewObjectWithValues(map[string]values.Value{"a": values.NewString("y"), "b": values.NewInt(2)}),
+	})
+	if !want.Equal(v) {
+		t.Errorf("unexpected result -want/+got:\n\t- %v\n\t+ %v", want, v)
+	}
+
+	args = interpreter.NewArguments(values.NewObjectWithValues(map[string]values.Value{
+		"a": strs("x", "y"),
+		"b": ints(1),
+	}))
+	if _, err := array.Zip(args); err == nil {
+		t.Error("expected an error for arrays of different lengths")
+	}
+}
+
+func TestEnumerate(t *testing.T) {
+	args := interpreter.NewArguments(values.NewObjectWithValues(map[string]values.Value{
+		"arr": floats(0.5, 1.5),
+	}))
+	v, err := array.Enumerate(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	wantType := semantic.NewArrayType(semantic.NewObjectType([]semantic.PropertyType{
+		{Key: []byte("index"), Value: semantic.BasicInt},
+		{Key: []byte("value"), Value: semantic.BasicFloat},
+	}))
+	want := values.NewArrayWithBacking(wantType, []values.Value{
+		values.NewObjectWithValues(map[string]values.Value{"index": values.NewInt(0), "value": values.NewFloat(0.5)}),
+		values.NewObjectWithValues(map[string]values.Value{"index": values.NewInt(1), "value": values.NewFloat(1.5)}),
+	})
+	if !want.Equal(v) {
+		t.Errorf("unexpected result -want/+got:\n\t- %v\n\t+ %v", want, v)
+	}
+}
@@ -1,197 +1,151 @@
 package bigtable
 
 import (
+	"regexp"
 	"time"
 
 	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/dependencies/bigtable"
+	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/stdlib/universe"
 )
 
-func AddFilterToNode(queryNode plan.Node, filterNode plan.Node) (plan.Node, bool) {
-	querySpec := queryNode.ProcedureSpec().(*FromBigtableProcedureSpec)
-	filterSpec := filterNode.ProcedureSpec().(*universe.FilterProcedureSpec)
-
-	body, ok := filterSpec.Fn.Fn.GetFunctionBodyExpression()
-	if !ok {
-		return filterNode, false
-	}
-
-	switch body := body.(type) {
-	case *semantic.BinaryExpression:
-		switch body.Operator {
-		case ast.EqualOperator:
-			// Look for a Single Row filter
-			if isRRowKey(body.Left) {
-				if name, ok := body.Right.(*semantic.StringLiteral); ok {
-					querySpec.RowSet = bigtable.SingleRow(name.Value)
-					return queryNode, true
-				}
-			}
-			// Look for a Family filter
-			if isRFamily(body.Left) {
-				if family, ok := body.Right.(*semantic.StringLiteral); ok {
-					querySpec.Filter = bigtable.ChainFilters(querySpec.Filter, bigtable.FamilyFilter(family.Value))
-					return queryNode, true
-				}
-			}
-		case ast.GreaterThanEqualOperator:
-			// Look for an Infinite Range filter (>=)
-			if isRRowKey(body.Left) {
-				if name, ok := body.Right.(*semantic.StringLiteral); ok {
-					querySpec.RowSet = bigtable.InfiniteRange(name.Value)
-					return queryNode, true
-				}
-			}
-			// Filter from startTime with no upper bound
-			if isRTime(body.Left) {
-				if startTime, ok := body.Right.(*semantic.DateTimeLiteral); ok {
-					querySpec.Filter = bigtable.ChainFilters(querySpec.Filter, bigtable.TimestampRangeFilter(startTime.Value, time.Time{}))
-					return queryNode, true
-				}
-			}
-		case ast.LessThanOperator:
-			// Filter to endTime with no lower bound
-			if isRTime(body.Left) {
-				if endTime, ok := body.Right.(*semantic.DateTimeLiteral); ok {
-					querySpec.Filter = bigtable.ChainFilters(querySpec.Filter, bigtable.TimestampRangeFilter(time.Time{}, endTime.Value))
-					return queryNode, true
-				}
-			}
-		}
-	case *semantic.LogicalExpression:
-		// Look for a Range filter
-		if begin, end, ok := getRange(body); ok {
-			querySpec.RowSet = bigtable.NewRange(begin, end)
-			return queryNode, true
-		}
-		// Look for Timestamp Range filter
-		if startTime, endTime, ok := getTimeRange(body); ok {
-			querySpec.Filter = bigtable.ChainFilters(querySpec.Filter, bigtable.TimestampRangeFilter(startTime, endTime))
-			return queryNode, true
-		}
-	// Look for a Prefix filter
-	case *semantic.CallExpression:
-		if prefix, ok := getPrefix(body); ok {
-			querySpec.RowSet = bigtable.PrefixRange(prefix)
-			return queryNode, true
-		}
+// PredicateCapabilities reports the predicates that Bigtable can evaluate:
+// ranges and prefixes of the row key, a column family and a time range.
+// Nothing is pushed down once the rows have been limited.
+func (s *FromBigtableProcedureSpec) PredicateCapabilities() plan.PredicateCapabilities {
+	if len(s.ReadOptions) > 0 {
+		return plan.PredicateCapabilities{}
 	}
-
-	return filterNode, false
-}
-
-func AddLimitToNode(queryNode plan.Node, limitNode plan.Node) (plan.Node, bool) {
-	querySpec := queryNode.ProcedureSpec().(*FromBigtableProcedureSpec)
-	limitSpec := limitNode.ProcedureSpec().(*universe.LimitProcedureSpec)
-
-	if limitSpec.Offset != 0 {
-		return limitNode, false
+	return plan.PredicateCapabilities{
+		Columns: map[string][]ast.OperatorKind{
+			"rowKey": {
+				ast.EqualOperator,
+				ast.GreaterThanOperator,
+				ast.GreaterThanEqualOperator,
+				ast.LessThanOperator,
+				ast.LessThanEqualOperator,
+				ast.RegexpMatchOperator,
+			},
+			"family":                    {ast.EqualOperator},
+			execute.DefaultTimeColLabel: {ast.GreaterThanEqualOperator, ast.LessThanOperator},
+		},
+		Accept: acceptPredicate,
 	}
-
-	querySpec.ReadOptions = append(querySpec.ReadOptions, bigtable.LimitRows(limitSpec.N))
-	return queryNode, true
 }
 
-func getRange(logic *semantic.LogicalExpression) (string, string, bool) {
-	if logic.Operator == ast.AndOperator {
-		if left, ok := logic.Left.(*semantic.BinaryExpression); ok {
-			if right, ok := logic.Right.(*semantic.BinaryExpression); ok {
-				if isRRowKey(left.Left) && isRRowKey(right.Left) {
-					if left.Operator == ast.GreaterThanEqualOperator && right.Operator == ast.LessThanOperator {
-						if leftVal, ok := left.Right.(*semantic.StringLiteral); ok {
-							if rightVal, ok := right.Right.(*semantic.StringLiteral); ok {
-								return leftVal.Value, rightVal.Value, ok
-							}
-						}
-					} else if left.Operator == ast.LessThanOperator && right.Operator == ast.GreaterThanEqualOperator {
-						if leftVal, ok := left.Right.(*semantic.StringLiteral); ok {
-							if rightVal, ok := right.Right.(*semantic.StringLiteral); ok {
-								return rightVal.Value, leftVal.Value, ok
-							}
-						}
-					}
-				}
-
+// acceptPredicate reports whether Bigtable can compare the column to the value.
+// Row keys can only match a regular expression that is a literal prefix,
+// and time ranges are bounded by milliseconds after the epoch.
+func acceptPredicate(p plan.Predicate) bool {
+	switch p.Column {
+	case "rowKey":
+		if p.Operator == ast.RegexpMatchOperator {
+			if p.Value.Type().Nature() != semantic.Regexp {
+				return false
 			}
+			_, ok := plan.RegexpPrefix(p.Value.Regexp())
+			return ok
+		}
+		// An empty row key limit is unbounded.
+		return p.Value.Type().Nature() == semantic.String &&
+			!(p.Operator == ast.LessThanOperator && p.Value.Str() == "")
+	case "family":
+		return p.Value.Type().Nature() == semantic.String
+	default:
+		if p.Value.Type().Nature() != semantic.Time {
+			return false
 		}
+		t := p.Value.Time().Time()
+		return t.UnixNano() > 0 && t.Nanosecond()%int(time.Millisecond) == 0
 	}
-	return "", "", false
 }
 
-func getTimeRange(logic *semantic.LogicalExpression) (time.Time, time.Time, bool) {
-	if logic.Operator == ast.AndOperator {
-		if left, ok := logic.Left.(*semantic.BinaryExpression); ok {
-			if right, ok := logic.Right.(*semantic.BinaryExpression); ok {
-				if isRTime(left.Left) && isRTime(right.Left) {
-					if left.Operator == ast.GreaterThanEqualOperator && right.Operator == ast.LessThanOperator {
-						if leftVal, ok := left.Right.(*semantic.DateTimeLiteral); ok {
-							if rightVal, ok := right.Right.(*semantic.DateTimeLiteral); ok {
-								return leftVal.Value, rightVal.Value, ok
-							}
-						}
-					} else if left.Operator == ast.LessThanOperator && right.Operator == ast.GreaterThanEqualOperator {
-						if leftVal, ok := left.Right.(*semantic.DateTimeLiteral); ok {
-							if rightVal, ok := right.Right.(*semantic.DateTimeLiteral); ok {
-								return rightVal.Value, leftVal.Value, ok
-							}
-						}
-					}
-				}
+// PushDownPredicates narrows the row key range and chains
+// a row filter for each of the predicates.
+func (s *FromBigtableProcedureSpec) PushDownPredicates(predicates []plan.Predicate, keepEmpty bool) plan.PhysicalProcedureSpec {
+	ns := s.Copy().(*FromBigtableProcedureSpec)
+	for _, p := range predicates {
+		switch p.Column {
+		case "rowKey":
+			ns.pushDownRowKey(p)
+		case "family":
+			ns.Filter = bigtable.ChainFilters(ns.Filter, bigtable.FamilyFilter(regexp.QuoteMeta(p.Value.Str())))
+		case execute.DefaultTimeColLabel:
+			t := p.Value.Time().Time()
+			if p.Operator == ast.GreaterThanEqualOperator {
+				ns.Filter = bigtable.ChainFilters(ns.Filter, bigtable.TimestampRangeFilter(t, time.Time{}))
+			} else {
+				ns.Filter = bigtable.ChainFilters(ns.Filter, bigtable.TimestampRangeFilter(time.Time{}, t))
 			}
 		}
 	}
-	return time.Time{}, time.Time{}, false
+	return ns
 }
 
-func getPrefix(callExpression *semantic.CallExpression) (string, bool) {
-	if callee, ok := callExpression.Callee.(*semantic.MemberExpression); ok && callee.Property == "hasPrefix" {
-		var rowKey bool
-		prefix := ""
-		for _, prop := range callExpression.Arguments.Properties {
-			if key, ok := prop.Key.(*semantic.Identifier); ok {
-				if isRRowKey(prop.Value) {
-					rowKey = true
-				} else if key.Name == "prefix" {
-					if val, ok := prop.Value.(*semantic.StringLiteral); ok {
-						prefix = val.Value
-					}
-				}
-			}
-		}
-		return prefix, prefix != "" && rowKey
+// pushDownRowKey intersects the row key range with the keys
+// that satisfy the predicate.
+func (s *FromBigtableProcedureSpec) pushDownRowKey(p plan.Predicate) {
+	var start, limit string
+	switch p.Operator {
+	case ast.EqualOperator:
+		start, limit = p.Value.Str(), p.Value.Str()+"\x00"
+	case ast.GreaterThanOperator:
+		start = p.Value.Str() + "\x00"
+	case ast.GreaterThanEqualOperator:
+		start = p.Value.Str()
+	case ast.LessThanOperator:
+		limit = p.Value.Str()
+	case ast.LessThanEqualOperator:
+		limit = p.Value.Str() + "\x00"
+	case ast.RegexpMatchOperator:
+		prefix, _ := plan.RegexpPrefix(p.Value.Regexp())
+		start, limit = prefix, prefixSuccessor(prefix)
+	}
+	if start > s.RowKeyStart {
+		s.RowKeyStart = start
+	}
+	if limit != "" && (s.RowKeyLimit == "" || limit < s.RowKeyLimit) {
+		s.RowKeyLimit = limit
 	}
-	return "", false
 }
 
-// helper function to identify `r.rowKey`
-func isRRowKey(i interface{}) bool {
-	if exp, ok := i.(*semantic.MemberExpression); ok {
-		if obj, ok := exp.Object.(*semantic.IdentifierExpression); ok && obj.Name == "r" {
-			return exp.Property == "rowKey"
-		}
+// rowSet returns the rows in the row key range.
+func (s *FromBigtableProcedureSpec) rowSet() bigtable.RowSet {
+	switch {
+	case s.RowKeyLimit == "":
+		return bigtable.InfiniteRange(s.RowKeyStart)
+	case s.RowKeyLimit <= s.RowKeyStart:
+		// An empty row list reads no rows.
+		return bigtable.RowList{}
+	default:
+		return bigtable.NewRange(s.RowKeyStart, s.RowKeyLimit)
 	}
-	return false
 }
 
-// helper function to identify `r.family`
-func isRFamily(i interface{}) bool {
-	if exp, ok := i.(*semantic.MemberExpression); ok {
-		if obj, ok := exp.Object.(*semantic.IdentifierExpression); ok && obj.Name == "r" {
-			return exp.Property == "family"
-		}
+// prefixSuccessor returns the smallest key that is greater than
+// every key with the prefix, or "" if there is none.
+func prefixSuccessor(prefix string) string {
+	n := len(prefix) - 1
+	for n >= 0 && prefix[n] == '\xff' {
+		n--
 	}
-	return false
+	if n < 0 {
+		return ""
+	}
+	return prefix[:n] + string([]byte{prefix[n] + 1})
 }
 
-// helper function to identify `r._time`
-func isRTime(i interface{}) bool {
-	if exp, ok := i.(*semantic.MemberExpression); ok {
-		if obj, ok := exp.Object.(*semantic.IdentifierExpression); ok && obj.Name == "r" {
-			return exp.Property == "_time"
-		}
+func AddLimitToNode(queryNode plan.Node, limitNode plan.Node) (plan.Node, bool) {
+	querySpec := queryNode.ProcedureSpec().(*FromBigtableProcedureSpec)
+	limitSpec := limitNode.ProcedureSpec().(*universe.LimitProcedureSpec)
+
+	if limitSpec.Offset != 0 {
+		return limitNode, false
 	}
-	return false
+
+	querySpec.ReadOptions = append(querySpec.ReadOptions, bigtable.LimitRows(limitSpec.N))
+	return queryNode, true
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/mock"
 	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
 	fbt "github.com/influxdata/flux/stdlib/experimental/bigtable"
 	"github.com/influxdata/flux/stdlib/universe"
 	"github.com/influxdata/flux/values"
@@ -116,7 +117,6 @@ func TestBigtableDecode(t *testing.T) {
 }
 
 func TestNodeRewrite(t *testing.T) {
-	now := time.Now()
 	testCases := []struct {
 		name        string
 		queryNode   plan.Node
@@ -126,192 +126,188 @@ func TestNodeRewrite(t *testing.T) {
 		wantBool    bool
 	}{
 		{
-			name:      "|> filter(fn: (r) => r.rowKey == ... )",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `(r) => r.rowKey == "single row"`),
-					},
-				},
-			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{RowSet: bigtable.SingleRow("single row")}},
+			name:        "|> limit(n: ...)",
+			queryNode:   &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter(), ReadOptions: make([]bigtable.ReadOption, 0)}},
+			rewriteNode: &plan.PhysicalPlanNode{Spec: &universe.LimitProcedureSpec{N: 4, Offset: 0}},
+			rewriteFunc: fbt.AddLimitToNode,
+			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{ReadOptions: []bigtable.ReadOption{bigtable.LimitRows(4)}, Filter: bigtable.PassAllFilter()}},
 			wantBool:    true,
 		},
 		{
-			name:      "|> filter(fn: (r) => r.rowKey >= ... )",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `(r) => r.rowKey >= "greater than or equal"`),
-					},
-				},
-			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{RowSet: bigtable.InfiniteRange("greater than or equal")}},
-			wantBool:    true,
+			name:        "|> limit(n: ..., offset: 2)",
+			queryNode:   &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter(), ReadOptions: make([]bigtable.ReadOption, 0)}},
+			rewriteNode: &plan.PhysicalPlanNode{Spec: &universe.LimitProcedureSpec{N: 4, Offset: 2}},
+			rewriteFunc: fbt.AddLimitToNode,
+			wantNode:    &plan.PhysicalPlanNode{Spec: &universe.LimitProcedureSpec{N: 4, Offset: 2}},
+			wantBool:    false,
 		},
-		{
-			name:      "|> filter(fn: (r) => r._time >= ... )",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter()}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `(r) => r._time >= %s`, now.Format(time.RFC3339Nano)),
-					},
-				},
+	}
+
+	filterTransformer := cmp.Transformer("", func(in bigtable.Filter) string {
+		if in != nil {
+			return in.String()
+		}
+		return ""
+	})
+
+	readOptionTransformer := cmp.Transformer("", func(in []bigtable.ReadOption) int {
+		return len(in)
+	})
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			gotNode, gotBool := tc.rewriteFunc(tc.queryNode, tc.rewriteNode)
+			if gotBool != tc.wantBool {
+				t.Fatalf("unexpected result -want/+got\n\n%s\n\n", cmp.Diff(gotBool, tc.wantBool))
+			}
+			if !cmp.Equal(tc.wantNode.ProcedureSpec(), gotNode.ProcedureSpec(), filterTransformer, readOptionTransformer) {
+				t.Fatalf("unexpected result -want/+got\n\n%s\n\n", cmp.Diff(tc.wantNode.ProcedureSpec(), gotNode.ProcedureSpec(), filterTransformer, readOptionTransformer))
+			}
+		})
+	}
+}
+
+func TestPushDownPredicates(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	stop := start.Add(time.Hour)
+
+	from := func(spec fbt.FromBigtableProcedureSpec) *fbt.FromBigtableProcedureSpec {
+		if spec.Filter == nil {
+			spec.Filter = bigtable.PassAllFilter()
+		}
+		return &spec
+	}
+	filter := func(fn string, args ...interface{}) *universe.FilterProcedureSpec {
+		return &universe.FilterProcedureSpec{
+			Fn: interpreter.ResolvedFunction{
+				Fn: executetest.FunctionExpression(t, fn, args...),
 			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.ChainFilters(bigtable.PassAllFilter(), bigtable.TimestampRangeFilter(now, time.Time{}))}},
-			wantBool:    true,
-		},
-		{
-			name:      "|> filter(fn: (r) => r._time < ... )",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter()}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `(r) => r._time < %s`, now.Format(time.RFC3339Nano)),
-					},
+		}
+	}
+	pushed := func(fn string, want *fbt.FromBigtableProcedureSpec) plantest.RuleTestCase {
+		return plantest.RuleTestCase{
+			Name:  fn,
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(fbt.FromBigtableProcedureSpec{})),
+					plan.CreatePhysicalNode("filter", filter(fn)),
 				},
+				Edges: [][2]int{{0, 1}},
 			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.ChainFilters(bigtable.PassAllFilter(), bigtable.TimestampRangeFilter(time.Time{}, now))}},
-			wantBool:    true,
-		},
-		{
-			name:      "|> filter(fn: (r) => r.rowKey >= ... and r.rowKey < ...)",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter()}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `(r) => r.rowKey >= "start" and r.rowKey < "end"`),
-					},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("merged_from_filter", want),
 				},
 			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{RowSet: bigtable.NewRange("start", "end"), Filter: bigtable.PassAllFilter()}},
-			wantBool:    true,
-		},
+		}
+	}
+
+	tests := []plantest.RuleTestCase{
+		pushed(`(r) => r.rowKey == "single row"`, from(fbt.FromBigtableProcedureSpec{
+			RowKeyStart: "single row",
+			RowKeyLimit: "single row\x00",
+		})),
+		pushed(`(r) => r.rowKey >= "a" and r.rowKey < "m"`, from(fbt.FromBigtableProcedureSpec{
+			RowKeyStart: "a",
+			RowKeyLimit: "m",
+		})),
+		pushed(`(r) => "m" > r.rowKey and r.rowKey > "a" and r.rowKey <= "k"`, from(fbt.FromBigtableProcedureSpec{
+			RowKeyStart: "a\x00",
+			RowKeyLimit: "k\x00",
+		})),
+		pushed(`(r) => r.rowKey > "m" and r.rowKey < "a"`, from(fbt.FromBigtableProcedureSpec{
+			RowKeyStart: "m\x00",
+			RowKeyLimit: "a",
+		})),
+		pushed(`import "strings"
+(r) => strings.hasPrefix(v: r.rowKey, prefix: "the prefix")`, from(fbt.FromBigtableProcedureSpec{
+			RowKeyStart: "the prefix",
+			RowKeyLimit: "the prefiy",
+		})),
+		pushed(`(r) => r.rowKey =~ /^row/`, from(fbt.FromBigtableProcedureSpec{
+			RowKeyStart: "row",
+			RowKeyLimit: "rox",
+		})),
+		pushed(`(r) => r.family == "fam.ily"`, from(fbt.FromBigtableProcedureSpec{
+			Filter: bigtable.ChainFilters(bigtable.PassAllFilter(), bigtable.FamilyFilter(`fam\.ily`)),
+		})),
 		{
-			name:      "|> filter(fn: (r) => r.rowKey < ... and r.rowKey >= ...)",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter()}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `(r) => r.rowKey < "end" and r.rowKey >= "start"`),
-					},
+			Name:  "time range",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(fbt.FromBigtableProcedureSpec{})),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r._time >= %s and r._time < %s`, start.Format(time.RFC3339Nano), stop.Format(time.RFC3339Nano))),
 				},
+				Edges: [][2]int{{0, 1}},
 			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{RowSet: bigtable.NewRange("start", "end"), Filter: bigtable.PassAllFilter()}},
-			wantBool:    true,
-		},
-		{
-			name:      "|> filter(fn: (r) => r._time >= ...)",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter()}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `(r) => r._time >= %s`, now.Format(time.RFC3339Nano)),
-					},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("merged_from_filter", &fbt.FromBigtableProcedureSpec{
+						Filter: bigtable.ChainFilters(
+							bigtable.ChainFilters(bigtable.PassAllFilter(), bigtable.TimestampRangeFilter(start, time.Time{})),
+							bigtable.TimestampRangeFilter(time.Time{}, stop),
+						),
+					}),
 				},
 			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.ChainFilters(bigtable.PassAllFilter(), bigtable.TimestampRangeFilter(now, time.Time{}))}},
-			wantBool:    true,
 		},
 		{
-			name:      "|> filter(fn: (r) => r._time >= ...)",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter()}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `(r) => r._time >= %s`, now.Format(time.RFC3339Nano)),
-					},
+			Name:  "unsupported predicates",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(fbt.FromBigtableProcedureSpec{})),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.rowKey == "a" and r.rowKey =~ /row/ and r._time >= %s`, start.Add(time.Microsecond).Format(time.RFC3339Nano))),
 				},
+				Edges: [][2]int{{0, 1}},
 			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.ChainFilters(bigtable.PassAllFilter(), bigtable.TimestampRangeFilter(now, time.Time{}))}},
-			wantBool:    true,
-		},
-		{
-			name:      "|> filter(fn: (r) => strings.hasPrefix(v: r.rowKey, prefix: ...)",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter()}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `
-import "strings"
-(r) => strings.hasPrefix(v: r.rowKey, prefix: "the prefix")
-`),
-					},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from(fbt.FromBigtableProcedureSpec{
+						RowKeyStart: "a",
+						RowKeyLimit: "a\x00",
+					})),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.rowKey =~ /row/ and r._time >= %s`, start.Add(time.Microsecond).Format(time.RFC3339Nano))),
 				},
+				Edges: [][2]int{{0, 1}},
 			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{RowSet: bigtable.PrefixRange("the prefix"), Filter: bigtable.PassAllFilter()}},
-			wantBool:    true,
 		},
 		{
-			name:      "|> filter(fn: (r) => r.family == ...)",
-			queryNode: &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter()}},
-			rewriteNode: &plan.PhysicalPlanNode{
-				Spec: &universe.FilterProcedureSpec{
-					Fn: interpreter.ResolvedFunction{
-						Fn: executetest.FunctionExpression(t, `(r) => r.family == "family"`),
-					},
+			Name:  "after limit",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", &fbt.FromBigtableProcedureSpec{
+						Filter:      bigtable.PassAllFilter(),
+						ReadOptions: []bigtable.ReadOption{bigtable.LimitRows(4)},
+					}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.rowKey == "a"`)),
 				},
+				Edges: [][2]int{{0, 1}},
 			},
-			rewriteFunc: fbt.AddFilterToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.ChainFilters(bigtable.PassAllFilter(), bigtable.FamilyFilter("family"))}},
-			wantBool:    true,
-		},
-		{
-			name:        "|> limit(n: ...)",
-			queryNode:   &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter(), ReadOptions: make([]bigtable.ReadOption, 0)}},
-			rewriteNode: &plan.PhysicalPlanNode{Spec: &universe.LimitProcedureSpec{N: 4, Offset: 0}},
-			rewriteFunc: fbt.AddLimitToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{ReadOptions: []bigtable.ReadOption{bigtable.LimitRows(4)}, Filter: bigtable.PassAllFilter()}},
-			wantBool:    true,
-		},
-		{
-			name:        "|> limit(n: ..., offset: 2)",
-			queryNode:   &plan.PhysicalPlanNode{Spec: &fbt.FromBigtableProcedureSpec{Filter: bigtable.PassAllFilter(), ReadOptions: make([]bigtable.ReadOption, 0)}},
-			rewriteNode: &plan.PhysicalPlanNode{Spec: &universe.LimitProcedureSpec{N: 4, Offset: 2}},
-			rewriteFunc: fbt.AddLimitToNode,
-			wantNode:    &plan.PhysicalPlanNode{Spec: &universe.LimitProcedureSpec{N: 4, Offset: 2}},
-			wantBool:    false,
+			NoChange: true,
 		},
 	}
 
-	rowRangeTransformer := cmp.Transformer("", func(in bigtable.RowRange) string {
-		return in.String()
-	})
-
 	filterTransformer := cmp.Transformer("", func(in bigtable.Filter) string {
 		if in != nil {
 			return in.String()
 		}
 		return ""
 	})
-
 	readOptionTransformer := cmp.Transformer("", func(in []bigtable.ReadOption) int {
 		return len(in)
 	})
 
-	for _, tc := range testCases {
+	for _, tc := range tests {
 		tc := tc
-
-		t.Run(tc.name, func(t *testing.T) {
-			gotNode, gotBool := tc.rewriteFunc(tc.queryNode, tc.rewriteNode)
-			if gotBool != tc.wantBool {
-				t.Fatalf("unexpected result -want/+got\n\n%s\n\n", cmp.Diff(gotBool, tc.wantBool))
-			}
-			if !cmp.Equal(tc.wantNode.ProcedureSpec(), gotNode.ProcedureSpec(), rowRangeTransformer, filterTransformer, readOptionTransformer) {
-				t.Fatalf("unexpected result -want/+got\n\n%s\n\n", cmp.Diff(tc.wantNode.ProcedureSpec(), gotNode.ProcedureSpec(), rowRangeTransformer, filterTransformer, readOptionTransformer))
-			}
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc, filterTransformer, readOptionTransformer)
 		})
 	}
 }
@@ -28,7 +28,7 @@ func init() {
 	runtime.RegisterPackageValue("experimental/bigtable", "from", flux.MustValue(flux.FunctionValue(FromBigtableKind, createFromBigtableOpSpec, fromBigtableSignature)))
 	flux.RegisterOpSpec(FromBigtableKind, newFromBigtableOp)
 	plan.RegisterProcedureSpec(FromBigtableKind, newFromBigtableProcedure, FromBigtableKind)
-	plan.RegisterPhysicalRules(BigtableFilterRewriteRule{}, BigtableLimitRewriteRule{})
+	plan.RegisterPhysicalRules(BigtableLimitRewriteRule{})
 	execute.RegisterSource(FromBigtableKind, createFromBigtableSource)
 }
 
@@ -77,8 +77,10 @@ type FromBigtableProcedureSpec struct {
 	Instance string
 	Table    string
 
-	// Used by BigtableFilterRewriteRule
-	RowSet      bigtable.RowSet
+	// RowKeyStart and RowKeyLimit are the range [start, limit)
+	// of the row keys read. An empty RowKeyLimit is unbounded.
+	RowKeyStart string
+	RowKeyLimit string
 	Filter      bigtable.Filter
 	ReadOptions []bigtable.ReadOption
 }
@@ -109,7 +111,8 @@ func (s *FromBigtableProcedureSpec) Copy() plan.ProcedureSpec {
 	ns.Project = s.Project
 	ns.Instance = s.Instance
 	ns.Table = s.Table
-	ns.RowSet = s.RowSet
+	ns.RowKeyStart = s.RowKeyStart
+	ns.RowKeyLimit = s.RowKeyLimit
 	ns.Filter = s.Filter
 	ns.ReadOptions = make([]bigtable.ReadOption, 0)
 	ns.ReadOptions = append(ns.ReadOptions, s.ReadOptions...)
@@ -236,23 +239,6 @@ func (c *BigtableDecoder) Close() error {
 	return c.client.Close()
 }
 
-type BigtableFilterRewriteRule struct{}
-
-func (r BigtableFilterRewriteRule) Name() string {
-	return "BigtableFilterRewriteRule"
-}
-
-func (r BigtableFilterRewriteRule) Pattern() plan.Pattern {
-	return plan.Pat(universe.FilterKind, plan.Pat(FromBigtableKind))
-}
-
-func (r BigtableFilterRewriteRule) Rewrite(ctx context.Context, filter plan.Node) (plan.Node, bool, error) {
-	query := filter.Predecessors()[0]
-
-	node, changed := AddFilterToNode(query, filter)
-	return node, changed, nil
-}
-
 type BigtableLimitRewriteRule struct{}
 
 func (r BigtableLimitRewriteRule) Name() string {
@@ -89,13 +89,8 @@ func NewBigtableRowReader(ctx context.Context, c *BigtableDecoder) (execute.RowR
 
 	reader.familyIndex = -1
 
-	// bigtable.InfiniteRange(start) returns the RowRange consisting of all keys at least as large as start
-	// "" is the lexicographically smallest byte string - gets all rows
-	if c.spec.RowSet == nil {
-		c.spec.RowSet = bigtable.InfiniteRange("")
-	}
 	c.spec.ReadOptions = append(c.spec.ReadOptions, bigtable.RowFilter(c.spec.Filter))
-	if err := c.tbl.ReadRows(ctx, c.spec.RowSet, func(r bigtable.Row) bool {
+	if err := c.tbl.ReadRows(ctx, c.spec.rowSet(), func(r bigtable.Row) bool {
 		for family := range r {
 			if _, ok := reader.rowsByFamily[family]; !ok {
 				reader.rowsByFamily[family] = make([]FamilyRow, 0)
@@ -256,12 +256,16 @@ func generateS2CellLatLonFunc() values.Function {
 }
 
 func init() {
+	runtime.RegisterPackageValue("experimental/geo", "geohashDecode", generateGeohashDecodeFunc())
+	runtime.RegisterPackageValue("experimental/geo", "geohashEncode", generateGeohashEncodeFunc())
 	runtime.RegisterPackageValue("experimental/geo", "getGrid", generateGetGridFunc())
+	runtime.RegisterPackageValue("experimental/geo", "haversineDistance", generateHaversineDistanceFunc())
 	runtime.RegisterPackageValue("experimental/geo", "getLevel", generateGetLevelFunc())
 	runtime.RegisterPackageValue("experimental/geo", "s2CellIDToken", generateS2CellIDTokenFunc())
 	runtime.RegisterPackageValue("experimental/geo", "s2CellLatLon", generateS2CellLatLonFunc())
 	runtime.RegisterPackageValue("experimental/geo", "stContains", generateSTContainsFunc())
 	runtime.RegisterPackageValue("experimental/geo", "stDistance", generateSTDistanceFunc())
+	runtime.RegisterPackageValue("experimental/geo", "stIntersects", generateSTIntersectsFunc())
 	runtime.RegisterPackageValue("experimental/geo", "stLength", generateSTLengthFunc())
 }
 
@@ -4,11 +4,15 @@ import "github.com/influxdata/flux/values"
 
 // TODO(ales.pour@bonitoo.io): This is exposed so the tests have access to the functions.
 var Functions = map[string]values.Function{
-	"getGrid":       generateGetGridFunc(),
-	"getLevel":      generateGetLevelFunc(),
-	"s2CellIDToken": generateS2CellIDTokenFunc(),
-	"s2CellLatLon":  generateS2CellLatLonFunc(),
-	"stContains":    generateSTContainsFunc(),
-	"stDistance":    generateSTDistanceFunc(),
-	"stLength":      generateSTLengthFunc(),
+	"geohashDecode":     generateGeohashDecodeFunc(),
+	"geohashEncode":     generateGeohashEncodeFunc(),
+	"getGrid":           generateGetGridFunc(),
+	"getLevel":          generateGetLevelFunc(),
+	"haversineDistance": generateHaversineDistanceFunc(),
+	"s2CellIDToken":     generateS2CellIDTokenFunc(),
+	"s2CellLatLon":      generateS2CellLatLonFunc(),
+	"stContains":        generateSTContainsFunc(),
+	"stDistance":        generateSTDistanceFunc(),
+	"stIntersects":      generateSTIntersectsFunc(),
+	"stLength":          generateSTLengthFunc(),
 }
@@ -0,0 +1,141 @@
+package geo
+
+import (
+	"context"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+	// MaxGeohashPrecision is the maximum number of characters of a geohash,
+	// which locates a point within a few centimeters.
+	MaxGeohashPrecision = 12
+)
+
+func generateGeohashEncodeFunc() values.Function {
+	geohashEncodeSignature := runtime.MustLookupBuiltinType("experimental/geo", "geohashEncode")
+	return values.NewFunction(
+		"geohashEncode",
+		geohashEncodeSignature,
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			a := interpreter.NewArguments(args)
+			lat, err := a.GetRequiredFloat("lat")
+			if err != nil {
+				return nil, err
+			}
+			lon, err := a.GetRequiredFloat("lon")
+			if err != nil {
+				return nil, err
+			}
+			precision, ok, err := a.GetInt("precision")
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				precision = MaxGeohashPrecision
+			}
+			if precision < 1 || precision > MaxGeohashPrecision {
+				return nil, errors.Newf(codes.Invalid, "precision value must be [1, %d]", MaxGeohashPrecision)
+			}
+			if lat < -90 || lat > 90 || lon < -180 || lon > 180 {
+				return nil, errors.Newf(codes.Invalid, "invalid point (%v, %v) - lat must be [-90, 90] and lon [-180, 180]", lat, lon)
+			}
+			return values.NewString(geohashEncode(lat, lon, int(precision))), nil
+		}, false,
+	)
+}
+
+func generateGeohashDecodeFunc() values.Function {
+	geohashDecodeSignature := runtime.MustLookupBuiltinType("experimental/geo", "geohashDecode")
+	return values.NewFunction(
+		"geohashDecode",
+		geohashDecodeSignature,
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			a := interpreter.NewArguments(args)
+			hash, err := a.GetRequiredString("hash")
+			if err != nil {
+				return nil, err
+			}
+			lat, lon, err := geohashDecode(hash)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewObjectWithValues(map[string]values.Value{
+				"lat": values.NewFloat(lat),
+				"lon": values.NewFloat(lon),
+			}), nil
+		}, false,
+	)
+}
+
+// Returns geohash of given point, bits alternate between longitude and latitude starting with longitude
+func geohashEncode(lat, lon float64, precision int) string {
+	latLo, latHi := -90.0, 90.0
+	lonLo, lonHi := -180.0, 180.0
+	var sb strings.Builder
+	even := true
+	for sb.Len() < precision {
+		var idx int
+		for bit := 0; bit < 5; bit++ {
+			idx <<= 1
+			if even {
+				if mid := (lonLo + lonHi) / 2; lon >= mid {
+					idx |= 1
+					lonLo = mid
+				} else {
+					lonHi = mid
+				}
+			} else {
+				if mid := (latLo + latHi) / 2; lat >= mid {
+					idx |= 1
+					latLo = mid
+				} else {
+					latHi = mid
+				}
+			}
+			even = !even
+		}
+		sb.WriteByte(geohashAlphabet[idx])
+	}
+	return sb.String()
+}
+
+// Returns center of the cell of given geohash
+func geohashDecode(hash string) (lat, lon float64, err error) {
+	if len(hash) == 0 || len(hash) > MaxGeohashPrecision {
+		return 0, 0, errors.Newf(codes.Invalid, "invalid geohash %q - must have 1 to %d characters", hash, MaxGeohashPrecision)
+	}
+	latLo, latHi := -90.0, 90.0
+	lonLo, lonHi := -180.0, 180.0
+	even := true
+	for _, c := range strings.ToLower(hash) {
+		idx := strings.IndexRune(geohashAlphabet, c)
+		if idx < 0 {
+			return 0, 0, errors.Newf(codes.Invalid, "invalid geohash %q - unexpected character %q", hash, c)
+		}
+		for bit := 4; bit >= 0; bit-- {
+			set := idx&(1<<uint(bit)) != 0
+			if even {
+				if mid := (lonLo + lonHi) / 2; set {
+					lonLo = mid
+				} else {
+					lonHi = mid
+				}
+			} else {
+				if mid := (latLo + latHi) / 2; set {
+					latLo = mid
+				} else {
+					latHi = mid
+				}
+			}
+			even = !even
+		}
+	}
+	return (latLo + latHi) / 2, (lonLo + lonHi) / 2, nil
+}
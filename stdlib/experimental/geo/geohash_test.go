@@ -0,0 +1,154 @@
+package geo_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/experimental/geo"
+	"github.com/influxdata/flux/values"
+)
+
+func TestGeohash_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name:    "encode no args",
+			Raw:     `import "experimental/geo" geo.geohashEncode()`,
+			WantErr: true, // missing required parameter(s)
+		},
+		{
+			Name:    "decode invalid arg",
+			Raw:     `import "experimental/geo" geo.geohashDecode(token: "u4pru")`,
+			WantErr: true, // missing required parameter(s)
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestGeohashEncode_Process(t *testing.T) {
+	testCases := []struct {
+		name         string
+		lat          float64
+		lon          float64
+		precision    int64
+		want         string
+		wantErr      bool
+		errSubstring string
+	}{
+		{
+			name: "default precision",
+			lat:  57.64911,
+			lon:  10.40744,
+			want: "u4pruydqqvj8",
+		},
+		{
+			name:      "precision 7",
+			lat:       40.7128,
+			lon:       -74.0060,
+			precision: 7,
+			want:      "dr5regw",
+		},
+		{
+			name:         "invalid precision",
+			lat:          40.7128,
+			lon:          -74.0060,
+			precision:    13,
+			wantErr:      true,
+			errSubstring: "precision value must be [1, 12]",
+		},
+		{
+			name:         "invalid point",
+			lat:          91.0,
+			lon:          -74.0060,
+			wantErr:      true,
+			errSubstring: "invalid point",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		geohashEncode := geo.Functions["geohashEncode"]
+		args := map[string]values.Value{
+			"lat": values.NewFloat(tc.lat),
+			"lon": values.NewFloat(tc.lon),
+		}
+		if tc.precision != 0 {
+			args["precision"] = values.NewInt(tc.precision)
+		}
+		result, err := geohashEncode.Call(context.Background(), values.NewObjectWithValues(args))
+		if err != nil {
+			if !tc.wantErr {
+				t.Error(err.Error())
+			}
+			if tc.errSubstring != "" && !strings.Contains(err.Error(), tc.errSubstring) {
+				t.Errorf("[%s] expected error with '%s', got '%v'", tc.name, tc.errSubstring, err)
+			}
+		} else if tc.wantErr {
+			t.Errorf("[%s] expected error, got %v", tc.name, result)
+		} else if tc.want != result.Str() {
+			t.Errorf("[%s] expected %v, got %v", tc.name, tc.want, result.Str())
+		}
+	}
+}
+
+func TestGeohashDecode_Process(t *testing.T) {
+	testCases := []struct {
+		name         string
+		hash         string
+		want         values.Value
+		wantErr      bool
+		errSubstring string
+	}{
+		{
+			name: "precision 11",
+			hash: "u4pruydqqvj",
+			want: roundPoint(pointToValue(57.649111, 10.40744)),
+		},
+		{
+			name: "upper case",
+			hash: "DR5REGW",
+			want: roundPoint(pointToValue(40.713272, -74.005966)),
+		},
+		{
+			name:         "invalid character",
+			hash:         "u4pa",
+			wantErr:      true,
+			errSubstring: "unexpected character 'a'",
+		},
+		{
+			name:         "empty",
+			hash:         "",
+			wantErr:      true,
+			errSubstring: "invalid geohash",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		geohashDecode := geo.Functions["geohashDecode"]
+		owv := values.NewObjectWithValues(map[string]values.Value{
+			"hash": values.NewString(tc.hash),
+		})
+		result, err := geohashDecode.Call(context.Background(), owv)
+		if err != nil {
+			if !tc.wantErr {
+				t.Error(err.Error())
+			}
+			if tc.errSubstring != "" && !strings.Contains(err.Error(), tc.errSubstring) {
+				t.Errorf("[%s] expected error with '%s', got '%v'", tc.name, tc.errSubstring, err)
+			}
+		} else if tc.wantErr {
+			t.Errorf("[%s] expected error, got %v", tc.name, result)
+		} else if !tc.want.Equal(roundPoint(result)) {
+			t.Errorf("[%s] expected %v, got %v", tc.name, tc.want, roundPoint(result))
+		}
+	}
+}
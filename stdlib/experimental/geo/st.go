@@ -72,6 +72,21 @@ func generateSTContainsFunc() values.Function {
 						break
 					}
 				}
+			case polygon:
+				switch r := geom1.(type) {
+				case box: // represent box as polygon
+					retVal = getS2LoopRegion(boxToPolygon(r)).Contains(getS2LoopRegion(v))
+				case polygon:
+					retVal = getS2LoopRegion(r).Contains(getS2LoopRegion(v))
+				default: // a cap is convex, it contains the polygon when it contains all its vertices
+					retVal = true
+					for _, p := range v.points {
+						if !region.ContainsPoint(p) {
+							retVal = false
+							break
+						}
+					}
+				}
 			default:
 				return nil, errors.Newf(codes.Invalid, "unsupported geometry type: %T", geom2)
 			}
@@ -162,6 +177,79 @@ func generateSTDistanceFunc() values.Function {
 	)
 }
 
+func generateSTIntersectsFunc() values.Function {
+	stIntersectsSignature := runtime.MustLookupBuiltinType("experimental/geo", "stIntersects")
+	return values.NewFunction(
+		"stIntersects",
+		stIntersectsSignature,
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			a := interpreter.NewArguments(args)
+			unitsArg, err := a.GetRequiredObject("units")
+			if err != nil {
+				return nil, err
+			}
+			units, err := parseUnitsArgument(unitsArg)
+			if err != nil {
+				return nil, err
+			}
+
+			geom1Arg, err := a.GetRequiredObject("region")
+			if err != nil {
+				return nil, err
+			}
+
+			geom2Arg, err := a.GetRequiredObject("geometry")
+			if err != nil {
+				return nil, err
+			}
+
+			geom1, err := parseGeometryArgument("region", geom1Arg, units)
+			if err != nil {
+				return nil, err
+			}
+
+			geom2, err := parseGeometryArgument("geometry", geom2Arg, units)
+			if err != nil {
+				return nil, err
+			}
+
+			retVal, err := intersects(geom1, geom2)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewBool(retVal), nil
+		}, false,
+	)
+}
+
+func generateHaversineDistanceFunc() values.Function {
+	haversineDistanceSignature := runtime.MustLookupBuiltinType("experimental/geo", "haversineDistance")
+	return values.NewFunction(
+		"haversineDistance",
+		haversineDistanceSignature,
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			a := interpreter.NewArguments(args)
+			unitsArg, err := a.GetRequiredObject("units")
+			if err != nil {
+				return nil, err
+			}
+			units, err := parseUnitsArgument(unitsArg)
+			if err != nil {
+				return nil, err
+			}
+
+			var coords [4]float64
+			for i, name := range []string{"lat1", "lon1", "lat2", "lon2"} {
+				if coords[i], err = a.GetRequiredFloat(name); err != nil {
+					return nil, err
+				}
+			}
+
+			return values.NewFloat(units.distanceToUser(haversine(coords[0], coords[1], coords[2], coords[3]))), nil
+		}, false,
+	)
+}
+
 func generateSTLengthFunc() values.Function {
 	stLengthSignature := runtime.MustLookupBuiltinType("experimental/geo", "stLength")
 	return values.NewFunction(
@@ -255,3 +343,99 @@ func minDistanceToShapeIndex(index *s2.ShapeIndex, index2 *s2.ShapeIndex) s1.Ang
 	}
 	return distance
 }
+
+// Returns central angle in radians between two points specified in decimal degrees
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	dPhi := phi2 - phi1
+	dLambda := (lon2 - lon1) * math.Pi / 180
+	h := math.Pow(math.Sin(dPhi/2), 2) + math.Cos(phi1)*math.Cos(phi2)*math.Pow(math.Sin(dLambda/2), 2)
+	return 2 * math.Asin(math.Sqrt(math.Min(h, 1)))
+}
+
+// Returns whether the geometries have at least one point in common
+func intersects(geom1, geom2 interface{}) (bool, error) {
+	switch v := geom1.(type) {
+	case box:
+		if p, ok := geom2.(point); ok {
+			return getS2RectRegion(v).ContainsPoint(getS2Point(p)), nil
+		}
+		return loopIntersects(getS2LoopRegion(boxToPolygon(v)), geom2)
+	case polygon:
+		return loopIntersects(getS2LoopRegion(v), geom2)
+	case circle:
+		center := getS2Point(v.point)
+		var distance s1.Angle
+		switch g := geom2.(type) {
+		case point:
+			distance = center.Distance(getS2Point(g))
+		case polyline:
+			distance = minDistanceToPoint(shapeToIndex(s2.PolylineFromLatLngs(g.latlngs)), center)
+		case polygon:
+			loop := getS2LoopRegion(g)
+			if loop.ContainsPoint(center) {
+				return true, nil
+			}
+			distance = minDistanceToPoint(shapeToIndex(loop), center)
+		default:
+			return intersects(geom2, geom1)
+		}
+		return distance <= s1.Angle(v.radius), nil
+	case point:
+		switch g := geom2.(type) {
+		case point:
+			return getS2Point(v).ApproxEqual(getS2Point(g)), nil
+		case polyline:
+			index := shapeToIndex(s2.PolylineFromLatLngs(g.latlngs))
+			return minDistanceToPoint(index, getS2Point(v)) <= intersectionMargin, nil
+		default:
+			return intersects(geom2, geom1)
+		}
+	case polyline:
+		switch g := geom2.(type) {
+		case polyline:
+			return s2.PolylineFromLatLngs(v.latlngs).Intersects(s2.PolylineFromLatLngs(g.latlngs)), nil
+		default:
+			return intersects(geom2, geom1)
+		}
+	default:
+		return false, errors.Newf(codes.Invalid, "unsupported region type: %T", geom1)
+	}
+}
+
+// Maximum distance of a point from a polyline to be considered on it (about 1 mm)
+const intersectionMargin = s1.Angle(1e-10)
+
+// Returns whether the loop has at least one point in common with the geometry
+func loopIntersects(loop *s2.Loop, geom interface{}) (bool, error) {
+	switch g := geom.(type) {
+	case point:
+		return loop.ContainsPoint(getS2Point(g)), nil
+	case polyline:
+		line := s2.PolylineFromLatLngs(g.latlngs)
+		for i, p := range *line {
+			if loop.ContainsPoint(p) {
+				return true, nil
+			}
+			if i == 0 {
+				continue
+			}
+			crosser := s2.NewChainEdgeCrosser((*line)[i-1], p, loop.Vertex(0))
+			for j := 1; j <= loop.NumVertices(); j++ {
+				if crosser.ChainCrossingSign(loop.Vertex(j)) != s2.DoNotCross {
+					return true, nil
+				}
+			}
+		}
+		return false, nil
+	case polygon:
+		return loop.Intersects(getS2LoopRegion(g)), nil
+	case box:
+		return loop.Intersects(getS2LoopRegion(boxToPolygon(g))), nil
+	case circle:
+		return intersects(g, polygon{points: loop.Vertices()})
+	default:
+		return false, errors.Newf(codes.Invalid, "unsupported geometry type: %T", geom)
+	}
+}
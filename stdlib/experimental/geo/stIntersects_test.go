@@ -0,0 +1,176 @@
+package geo_test
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/stdlib/experimental/geo"
+	"github.com/influxdata/flux/values"
+)
+
+func TestSTIntersects_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name:    "no args",
+			Raw:     `import "experimental/geo" geo.intersects()`,
+			WantErr: true, // missing required parameter(s)
+		},
+		{
+			Name:    "missing geometry arg",
+			Raw:     `import "experimental/geo" geo.intersects(region: { lat: 40.5, lon: -74.5, radius: 15.0 })`,
+			WantErr: true, // missing required parameter(s)
+		},
+		{
+			Name:    "invalid args - invalid polygon",
+			Raw:     `import "experimental/geo" geo.intersects(region: { points: [{ lat: 40.5, lon: -74.5 }] }, geometry: {lat: 40.5, lon: -74.5})`,
+			WantErr: true, // polygon must have at least 3 points
+		},
+		{
+			Name:    "invalid args - invalid units",
+			Raw:     `import "experimental/geo" geo.intersects(region: { lat: 40.5, lon: -74.5, radius: 15.0 }, geometry: {lat: 40.5, lon: -74.5}, units: { distance: "yd" })`,
+			WantErr: true, // unsupported unit
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestSTIntersects_Process(t *testing.T) {
+	polygonToValue := func(points ...float64) values.Value {
+		array := values.NewArray(semantic.NewArrayType(pointT))
+		for i := 0; i < len(points); i += 2 {
+			array.Append(pointToValue(points[i], points[i+1]))
+		}
+		return values.NewObjectWithValues(map[string]values.Value{
+			"points": array,
+		})
+	}
+	// polygon in Brooklyn
+	brooklyn := polygonToValue(40.671659, -73.936631, 40.706543, -73.749177, 40.791333, -73.880327)
+	testCases := []struct {
+		name     string
+		fn       string
+		region   values.Value
+		geometry values.Value
+		want     bool
+	}{
+		{
+			name:     "polygon intersects polygon",
+			fn:       "stIntersects",
+			region:   brooklyn,
+			geometry: polygonToValue(40.70, -73.80, 40.60, -73.70, 40.65, -73.60),
+			want:     true,
+		},
+		{
+			name:     "polygon not intersects polygon",
+			fn:       "stIntersects",
+			region:   brooklyn,
+			geometry: polygonToValue(40.60, -74.10, 40.50, -74.00, 40.55, -73.90),
+			want:     false,
+		},
+		{
+			name:     "polygon intersects point",
+			fn:       "stIntersects",
+			region:   brooklyn,
+			geometry: pointToValue(40.702594, -73.909699),
+			want:     true,
+		},
+		{
+			name:   "polygon intersects crossing linestring",
+			fn:     "stIntersects",
+			region: brooklyn,
+			geometry: values.NewObjectWithValues(map[string]values.Value{
+				"linestring": values.NewString("-73.85 40.60, -73.85 40.80"),
+			}),
+			want: true,
+		},
+		{
+			name: "circle intersects polygon",
+			fn:   "stIntersects",
+			region: values.NewObjectWithValues(map[string]values.Value{
+				"lat":    values.NewFloat(40.72),
+				"lon":    values.NewFloat(-73.84),
+				"radius": values.NewFloat(1.0),
+			}),
+			geometry: brooklyn,
+			want:     true,
+		},
+		{
+			name:     "polygon contains polygon",
+			fn:       "stContains",
+			region:   brooklyn,
+			geometry: polygonToValue(40.70, -73.86, 40.71, -73.84, 40.72, -73.85),
+			want:     true,
+		},
+		{
+			name:     "polygon not contains intersecting polygon",
+			fn:       "stContains",
+			region:   brooklyn,
+			geometry: polygonToValue(40.70, -73.80, 40.60, -73.70, 40.65, -73.60),
+			want:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		fn := geo.Functions[tc.fn]
+		owv := values.NewObjectWithValues(map[string]values.Value{
+			"region":   tc.region,
+			"geometry": tc.geometry,
+			"units":    unitsToValue(map[string]string{"distance": "km"}),
+		})
+		result, err := fn.Call(context.Background(), owv)
+		if err != nil {
+			t.Error(err.Error())
+		} else if tc.want != result.Bool() {
+			t.Errorf("[%s] expected %v (%T), got %v (%T)", tc.name, tc.want, tc.want, result, result)
+		}
+	}
+}
+
+func TestHaversineDistance_Process(t *testing.T) {
+	testCases := []struct {
+		name  string
+		units string
+		want  float64
+	}{
+		{
+			name:  "km",
+			units: "km",
+			want:  5570.23,
+		},
+		{
+			name:  "mile",
+			units: "mile",
+			want:  3461.18,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		haversineDistance := geo.Functions["haversineDistance"]
+		// New York - London
+		owv := values.NewObjectWithValues(map[string]values.Value{
+			"lat1":  values.NewFloat(40.7128),
+			"lon1":  values.NewFloat(-74.0060),
+			"lat2":  values.NewFloat(51.5074),
+			"lon2":  values.NewFloat(-0.1278),
+			"units": unitsToValue(map[string]string{"distance": tc.units}),
+		})
+		result, err := haversineDistance.Call(context.Background(), owv)
+		if err != nil {
+			t.Error(err.Error())
+		} else if got := math.Round(result.Float()*100) / 100; tc.want != got {
+			t.Errorf("[%s] expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+}
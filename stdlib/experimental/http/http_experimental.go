@@ -11,9 +11,12 @@ import (
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
+	depshttp "github.com/influxdata/flux/dependencies/http"
+	depsurl "github.com/influxdata/flux/dependencies/url"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/semantic"
+	fluxhttp "github.com/influxdata/flux/stdlib/http"
 	"github.com/influxdata/flux/values"
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/log"
@@ -38,7 +41,7 @@ var get = values.NewFunction(
 		if err != nil {
 			return nil, err
 		}
-		if err := validator.Validate(u); err != nil {
+		if err := depsurl.ForPackage(validator, depsurl.HTTPPackage).Validate(u); err != nil {
 			return nil, errors.New(codes.Invalid, "no such host")
 		}
 
@@ -75,6 +78,11 @@ var get = values.NewFunction(
 			}
 		}
 
+		override, err := fluxhttp.GetRetryOverride(args)
+		if err != nil {
+			return nil, err
+		}
+
 		// Perform request
 		dc, err := deps.HTTPClient()
 		if err != nil {
@@ -86,7 +94,8 @@ var get = values.NewFunction(
 			s.SetTag("url", req.URL.String())
 			defer s.Finish()
 
-			ccctx, cncl := context.WithTimeout(cctx, theTimeout.Duration())
+			// the timeout applies to all of the attempts
+			ccctx, cncl := context.WithTimeout(depshttp.WithRetryOverride(cctx, override), theTimeout.Duration())
 			defer cncl()
 
 			req = req.WithContext(ccctx)
@@ -354,6 +354,9 @@ NEXT:
 	// incremented by one.
 	//
 	for k := j; ta == b.time(k); k++ {
+		if err := execute.CheckCancel(c.ctx, k); err != nil {
+			return nil, err
+		}
 
 		// Evaluate fn over both input rows
 		obj, err := c.fn.Eval(c.ctx, a.record(i), b.record(k))
@@ -0,0 +1,531 @@
+package join
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	pkgpath = "experimental/join"
+
+	TablesKind = "experimental/join.tables"
+
+	MethodInner = "inner"
+	MethodFull  = "full"
+)
+
+func init() {
+	tablesSignature := runtime.MustLookupBuiltinType(pkgpath, "tables")
+	runtime.RegisterPackageValue(pkgpath, "tables", flux.MustValue(flux.FunctionValue("tables", createTablesOpSpec, tablesSignature)))
+	flux.RegisterOpSpec(TablesKind, newTablesOp)
+	plan.RegisterProcedureSpec(TablesKind, newTablesProcedure, TablesKind)
+	execute.RegisterTransformation(TablesKind, createTablesTransformation)
+}
+
+// TablesOpSpec joins the tables of several streams.
+type TablesOpSpec struct {
+	TableNames map[flux.OperationID]string `json:"tableNames"`
+	On         []string                    `json:"on"`
+	Method     string                      `json:"method"`
+
+	// names and operations are the streams sorted by name,
+	// they are only set when the spec is created from Flux.
+	names      []string
+	operations []*flux.TableObject
+}
+
+func createTablesOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	spec := new(TablesOpSpec)
+
+	if array, err := args.GetRequiredArray("on", semantic.String); err != nil {
+		return nil, err
+	} else if array.Len() == 0 {
+		return nil, errors.New(codes.Invalid, "at least one column in 'on' column list is required")
+	} else if spec.On, err = interpreter.ToStringArray(array); err != nil {
+		return nil, err
+	}
+
+	if method, ok, err := args.GetString("method"); err != nil {
+		return nil, err
+	} else if !ok {
+		spec.Method = MethodInner
+	} else if method != MethodInner && method != MethodFull {
+		return nil, errors.Newf(codes.Invalid, "%s is not a valid join method, must be %q or %q", method, MethodInner, MethodFull)
+	} else {
+		spec.Method = method
+	}
+
+	tables, err := args.GetRequiredObject("tables")
+	if err != nil {
+		return nil, err
+	}
+	if tables.Len() < 2 {
+		return nil, errors.Newf(codes.Invalid, "at least two streams are required, got %d", tables.Len())
+	}
+	streams := make(map[string]*flux.TableObject, tables.Len())
+	tables.Range(func(name string, v values.Value) {
+		if err != nil {
+			return
+		}
+		table, ok := v.(*flux.TableObject)
+		if !ok {
+			err = errors.Newf(codes.Invalid, "expected %q to be a table stream, got %v", name, v.Type().Nature())
+			return
+		}
+		spec.names = append(spec.names, name)
+		streams[name] = table
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Add the parents in a consistent order, the parents of the
+	// transformation are matched with the sorted names.
+	sort.Strings(spec.names)
+	for _, name := range spec.names {
+		spec.operations = append(spec.operations, streams[name])
+		a.AddParent(streams[name])
+	}
+	spec.TableNames = make(map[flux.OperationID]string, len(spec.names))
+	return spec, nil
+}
+
+func (s *TablesOpSpec) IDer(ider flux.IDer) {
+	for i, name := range s.names {
+		s.TableNames[ider.ID(s.operations[i])] = name
+	}
+}
+
+func newTablesOp() flux.OperationSpec {
+	return new(TablesOpSpec)
+}
+
+func (s *TablesOpSpec) Kind() flux.OperationKind {
+	return TablesKind
+}
+
+type TablesProcedureSpec struct {
+	plan.DefaultCost
+	TableNames []string `json:"table_names"`
+	On         []string `json:"on"`
+	Method     string   `json:"method"`
+}
+
+func newTablesProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*TablesOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+
+	tableNames := make([]string, 0, len(spec.TableNames))
+	for _, name := range spec.TableNames {
+		tableNames = append(tableNames, name)
+	}
+	sort.Strings(tableNames)
+
+	return &TablesProcedureSpec{
+		TableNames: tableNames,
+		On:         append([]string(nil), spec.On...),
+		Method:     spec.Method,
+	}, nil
+}
+
+func (s *TablesProcedureSpec) Kind() plan.ProcedureKind {
+	return TablesKind
+}
+
+func (s *TablesProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(TablesProcedureSpec)
+	*ns = *s
+	ns.TableNames = append([]string(nil), s.TableNames...)
+	ns.On = append([]string(nil), s.On...)
+	return ns
+}
+
+func createTablesTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*TablesProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	parents := a.Parents()
+	if len(parents) != len(s.TableNames) {
+		return nil, nil, errors.Newf(codes.Internal, "expected %d parents, got %d", len(s.TableNames), len(parents))
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewTablesTransformation(d, cache, s, parents)
+	return t, d, nil
+}
+
+// stream is the buffered rows of an input stream.
+type stream struct {
+	name     string
+	finished bool
+
+	cols     []flux.ColMeta
+	colIndex map[string]int
+	rows     [][]values.Value
+	// key is the columns that are in the group key of all the tables of the stream.
+	key map[string]bool
+}
+
+type tablesTransformation struct {
+	execute.ExecutionNode
+	mu sync.Mutex
+
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	on      []string
+	method  string
+	parents []execute.DatasetID
+	streams map[execute.DatasetID]*stream
+	err     error
+}
+
+// NewTablesTransformation returns a transformation that joins the streams of the parents,
+// which are named by the sorted table names of the spec.
+func NewTablesTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *TablesProcedureSpec, parents []execute.DatasetID) *tablesTransformation {
+	t := &tablesTransformation{
+		d:       d,
+		cache:   cache,
+		on:      spec.On,
+		method:  spec.Method,
+		parents: parents,
+		streams: make(map[execute.DatasetID]*stream, len(parents)),
+	}
+	for i, id := range parents {
+		t.streams[id] = &stream{
+			name:     spec.TableNames[i],
+			colIndex: make(map[string]int),
+		}
+	}
+	return t
+}
+
+func (t *tablesTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+// Process buffers the rows of a table, the streams are joined when they are all finished.
+func (t *tablesTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := t.streams[id]
+	cols := tbl.Cols()
+	indexes := make([]int, len(cols))
+	for j, c := range cols {
+		idx, ok := s.colIndex[c.Label]
+		if !ok {
+			idx = len(s.cols)
+			s.colIndex[c.Label] = idx
+			s.cols = append(s.cols, c)
+		} else if s.cols[idx].Type != c.Type {
+			tbl.Done()
+			return errors.Newf(codes.Invalid, "column %q of stream %q has conflicting types %v and %v", c.Label, s.name, s.cols[idx].Type, c.Type)
+		}
+		indexes[j] = idx
+	}
+
+	key := make(map[string]bool, len(tbl.Key().Cols()))
+	for _, c := range tbl.Key().Cols() {
+		if s.key == nil || s.key[c.Label] {
+			key[c.Label] = true
+		}
+	}
+	s.key = key
+
+	return tbl.Do(func(cr flux.ColReader) error {
+		for i := 0; i < cr.Len(); i++ {
+			row := make([]values.Value, len(s.cols))
+			for j := range cols {
+				row[indexes[j]] = execute.ValueForRow(cr, i, j)
+			}
+			s.rows = append(s.rows, row)
+		}
+		return nil
+	})
+}
+
+func (t *tablesTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return nil
+}
+
+func (t *tablesTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return nil
+}
+
+func (t *tablesTransformation) Finish(id execute.DatasetID, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Only report the first error that occurs.
+	if t.err == nil && err != nil {
+		t.err = err
+	}
+	t.streams[id].finished = true
+	for _, s := range t.streams {
+		if !s.finished {
+			return
+		}
+	}
+
+	if t.err == nil {
+		t.err = t.join()
+	}
+	t.d.Finish(t.err)
+}
+
+// value returns the value of a column of a row, or nil when the stream does not have the column.
+func (s *stream) value(row []values.Value, label string) values.Value {
+	if row == nil {
+		return nil
+	}
+	j, ok := s.colIndex[label]
+	if !ok || j >= len(row) {
+		return nil
+	}
+	return row[j]
+}
+
+// match is the rows of each stream that have the same values in the on columns.
+type match struct {
+	key  flux.GroupKey
+	rows [][][]values.Value
+}
+
+func (t *tablesTransformation) join() error {
+	streams := make([]*stream, len(t.parents))
+	for i, id := range t.parents {
+		streams[i] = t.streams[id]
+	}
+
+	// The on columns must have the same type in every stream.
+	onCols := make([]flux.ColMeta, len(t.on))
+	for i, label := range t.on {
+		onCols[i] = flux.ColMeta{Label: label, Type: flux.TInvalid}
+		for _, s := range streams {
+			j, ok := s.colIndex[label]
+			if !ok {
+				continue
+			}
+			if typ := s.cols[j].Type; onCols[i].Type == flux.TInvalid {
+				onCols[i].Type = typ
+			} else if onCols[i].Type != typ {
+				return errors.Newf(codes.Invalid, "join column %q has conflicting types %v and %v", label, onCols[i].Type, typ)
+			}
+		}
+		if onCols[i].Type == flux.TInvalid {
+			// none of the streams have the column, so no rows match
+			return nil
+		}
+	}
+
+	// Group the rows by the values of the on columns, in the order they are first seen.
+	lookup := execute.NewRandomAccessGroupLookup()
+	var matches []*match
+	var unmatched [][]values.Value
+	var unmatchedStreams []int
+	for i, s := range streams {
+	rows:
+		for _, row := range s.rows {
+			vs := make([]values.Value, len(onCols))
+			for k, c := range onCols {
+				v := s.value(row, c.Label)
+				if v == nil || v.IsNull() {
+					// null values do not match any row
+					if t.method == MethodFull {
+						unmatched = append(unmatched, row)
+						unmatchedStreams = append(unmatchedStreams, i)
+					}
+					continue rows
+				}
+				vs[k] = v
+			}
+			key := execute.NewGroupKey(onCols, vs)
+			m := lookup.LookupOrCreate(key, func() interface{} {
+				m := &match{key: key, rows: make([][][]values.Value, len(streams))}
+				matches = append(matches, m)
+				return m
+			}).(*match)
+			m.rows[i] = append(m.rows[i], row)
+		}
+	}
+
+	b := newOutputBuilder(t, streams, onCols)
+	for _, m := range matches {
+		if t.method == MethodInner {
+			complete := true
+			for _, rows := range m.rows {
+				complete = complete && len(rows) > 0
+			}
+			if !complete {
+				continue
+			}
+		}
+		if err := b.appendProduct(m.rows); err != nil {
+			return err
+		}
+	}
+	for k, row := range unmatched {
+		rows := make([][][]values.Value, len(streams))
+		rows[unmatchedStreams[k]] = [][]values.Value{row}
+		if err := b.appendProduct(rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// outputBuilder appends the joined rows to the tables of the output.
+type outputBuilder struct {
+	t       *tablesTransformation
+	streams []*stream
+	on      map[string]bool
+
+	cols []flux.ColMeta
+	// sources are the stream and the column of each output column,
+	// the stream is -1 for the on columns.
+	sources []struct {
+		stream int
+		label  string
+	}
+	keyCols []flux.ColMeta
+}
+
+func newOutputBuilder(t *tablesTransformation, streams []*stream, onCols []flux.ColMeta) *outputBuilder {
+	b := &outputBuilder{
+		t:       t,
+		streams: streams,
+		on:      make(map[string]bool, len(onCols)),
+	}
+	for _, c := range onCols {
+		b.on[c.Label] = true
+	}
+
+	// The columns that are in several streams are renamed after their stream.
+	count := make(map[string]int)
+	for _, s := range streams {
+		for _, c := range s.cols {
+			count[c.Label]++
+		}
+	}
+	type source = struct {
+		stream int
+		label  string
+	}
+	sources := make(map[string]source)
+	types := make(map[string]flux.ColType)
+	for _, c := range onCols {
+		sources[c.Label] = source{stream: -1, label: c.Label}
+		types[c.Label] = c.Type
+	}
+	for i, s := range streams {
+		for _, c := range s.cols {
+			if b.on[c.Label] {
+				continue
+			}
+			label := c.Label
+			if count[label] > 1 {
+				label = fmt.Sprintf("%s_%s", c.Label, s.name)
+			}
+			sources[label] = source{stream: i, label: c.Label}
+			types[label] = c.Type
+		}
+	}
+	labels := make([]string, 0, len(sources))
+	for label := range sources {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		b.cols = append(b.cols, flux.ColMeta{Label: label, Type: types[label]})
+		b.sources = append(b.sources, sources[label])
+	}
+
+	// The output is grouped by the on columns that are in the group key of every stream.
+	for _, c := range onCols {
+		inKey := true
+		for _, s := range streams {
+			inKey = inKey && s.key[c.Label]
+		}
+		if inKey {
+			b.keyCols = append(b.keyCols, c)
+		}
+	}
+	return b
+}
+
+// onValue returns the value of an on column from the first stream with a row.
+func (b *outputBuilder) onValue(rows [][]values.Value, label string) values.Value {
+	for i, row := range rows {
+		if v := b.streams[i].value(row, label); v != nil && !v.IsNull() {
+			return v
+		}
+	}
+	return nil
+}
+
+// appendProduct appends the cross product of the rows of the streams,
+// a stream without rows has a row of null values in the product.
+func (b *outputBuilder) appendProduct(rows [][][]values.Value) error {
+	product := [][][]values.Value{make([][]values.Value, 0, len(rows))}
+	for _, rs := range rows {
+		if len(rs) == 0 {
+			rs = [][]values.Value{nil}
+		}
+		next := make([][][]values.Value, 0, len(product)*len(rs))
+		for _, p := range product {
+			for _, r := range rs {
+				next = append(next, append(append(make([][]values.Value, 0, len(rows)), p...), r))
+			}
+		}
+		product = next
+	}
+
+	for _, p := range product {
+		keyValues := make([]values.Value, len(b.keyCols))
+		for k, c := range b.keyCols {
+			keyValues[k] = b.onValue(p, c.Label)
+			if keyValues[k] == nil {
+				keyValues[k] = values.NewNull(flux.SemanticType(c.Type))
+			}
+		}
+		builder, created := b.t.cache.TableBuilder(execute.NewGroupKey(b.keyCols, keyValues))
+		if created {
+			for _, c := range b.cols {
+				if _, err := builder.AddCol(c); err != nil {
+					return err
+				}
+			}
+		}
+		for j, src := range b.sources {
+			var v values.Value
+			if src.stream < 0 {
+				v = b.onValue(p, src.label)
+			} else {
+				v = b.streams[src.stream].value(p[src.stream], src.label)
+			}
+			if v == nil {
+				if err := builder.AppendNil(j); err != nil {
+					return err
+				}
+			} else if err := builder.AppendValue(j, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package join_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/experimental/join"
+)
+
+func TestTables_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name:    "missing on",
+			Raw:     `import "experimental/join" a = from(bucket: "a") b = from(bucket: "b") join.tables(tables: {a: a, b: b})`,
+			WantErr: true,
+		},
+		{
+			Name:    "single stream",
+			Raw:     `import "experimental/join" a = from(bucket: "a") join.tables(tables: {a: a}, on: ["_time"])`,
+			WantErr: true,
+		},
+		{
+			Name:    "invalid method",
+			Raw:     `import "experimental/join" a = from(bucket: "a") b = from(bucket: "b") join.tables(tables: {a: a, b: b}, on: ["_time"], method: "left")`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
+
+func TestTables_Process(t *testing.T) {
+	// cpu and disk are grouped by host, mem is not grouped.
+	cpu := []*executetest.Table{
+		{
+			KeyCols: []string{"host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "host", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), 10.0, "a"},
+				{execute.Time(2), 20.0, "a"},
+			},
+		},
+		{
+			KeyCols: []string{"host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "host", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), 30.0, "b"},
+			},
+		},
+	}
+	disk := []*executetest.Table{
+		{
+			KeyCols: []string{"host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "host", Type: flux.TString},
+				{Label: "path", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), 0.5, "a", "/"},
+				{execute.Time(3), 0.7, "a", "/"},
+			},
+		},
+	}
+	mem := []*executetest.Table{
+		{
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TInt},
+				{Label: "host", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), int64(100), "a"},
+				{execute.Time(1), int64(300), "b"},
+				{execute.Time(2), int64(200), nil},
+			},
+		},
+	}
+	cols := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "_value_cpu", Type: flux.TFloat},
+		{Label: "_value_disk", Type: flux.TFloat},
+		{Label: "_value_mem", Type: flux.TInt},
+		{Label: "host", Type: flux.TString},
+		{Label: "path", Type: flux.TString},
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		on     []string
+		want   []*executetest.Table
+	}{
+		{
+			name:   "inner",
+			method: join.MethodInner,
+			on:     []string{"_time", "host"},
+			want: []*executetest.Table{
+				{
+					ColMeta: cols,
+					Data: [][]interface{}{
+						{execute.Time(1), 10.0, 0.5, int64(100), "a", "/"},
+					},
+				},
+			},
+		},
+		{
+			name:   "full",
+			method: join.MethodFull,
+			on:     []string{"_time", "host"},
+			want: []*executetest.Table{
+				{
+					ColMeta: cols,
+					Data: [][]interface{}{
+						{execute.Time(1), 10.0, 0.5, int64(100), "a", "/"},
+						{execute.Time(2), 20.0, nil, nil, "a", nil},
+						{execute.Time(1), 30.0, nil, int64(300), "b", nil},
+						{execute.Time(3), nil, 0.7, nil, "a", "/"},
+						{execute.Time(2), nil, nil, int64(200), nil, nil},
+					},
+				},
+			},
+		},
+		{
+			name:   "full on time",
+			method: join.MethodFull,
+			on:     []string{"_time"},
+			want: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value_cpu", Type: flux.TFloat},
+						{Label: "_value_disk", Type: flux.TFloat},
+						{Label: "_value_mem", Type: flux.TInt},
+						{Label: "host_cpu", Type: flux.TString},
+						{Label: "host_disk", Type: flux.TString},
+						{Label: "host_mem", Type: flux.TString},
+						{Label: "path", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(1), 10.0, 0.5, int64(100), "a", "a", "a", "/"},
+						{execute.Time(1), 10.0, 0.5, int64(300), "a", "a", "b", "/"},
+						{execute.Time(1), 30.0, 0.5, int64(100), "b", "a", "a", "/"},
+						{execute.Time(1), 30.0, 0.5, int64(300), "b", "a", "b", "/"},
+						{execute.Time(2), 20.0, nil, int64(200), "a", nil, nil, nil},
+						{execute.Time(3), nil, 0.7, nil, nil, "a", nil, "/"},
+					},
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			spec := &join.TablesProcedureSpec{
+				TableNames: []string{"cpu", "disk", "mem"},
+				On:         tc.on,
+				Method:     tc.method,
+			}
+			parents := []execute.DatasetID{
+				executetest.RandomDatasetID(),
+				executetest.RandomDatasetID(),
+				executetest.RandomDatasetID(),
+			}
+			c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+			c.SetTriggerSpec(plan.DefaultTriggerSpec)
+			d := executetest.NewDataset(executetest.RandomDatasetID())
+			jt := join.NewTablesTransformation(d, c, spec, parents)
+
+			for i, tables := range [][]*executetest.Table{cpu, disk, mem} {
+				for _, tbl := range tables {
+					if err := jt.Process(parents[i], tbl); err != nil {
+						t.Fatal(err)
+					}
+				}
+				jt.Finish(parents[i], nil)
+			}
+
+			got, err := executetest.TablesFromCache(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+			executetest.NormalizeTables(got)
+			executetest.NormalizeTables(tc.want)
+			sort.Sort(executetest.SortedTables(got))
+			sort.Sort(executetest.SortedTables(tc.want))
+			if !cmp.Equal(tc.want, got) {
+				t.Errorf("unexpected tables -want/+got\n%s", cmp.Diff(tc.want, got))
+			}
+		})
+	}
+}
@@ -0,0 +1,470 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	kafkadeps "github.com/influxdata/flux/dependencies/kafka"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	pkgpath  = "experimental/kafka"
+	FromKind = pkgpath + ".from"
+)
+
+const (
+	rawFormat  = "raw"
+	jsonFormat = "json"
+)
+
+// The labels of the columns with the metadata of the messages.
+const (
+	topicColLabel     = "topic"
+	partitionColLabel = "partition"
+	offsetColLabel    = "offset"
+	keyColLabel       = "key"
+)
+
+// noOffset marks the offsets that are not set.
+const noOffset = -1
+
+type FromOpSpec struct {
+	Brokers       []string  `json:"brokers"`
+	Topic         string    `json:"topic"`
+	Partitions    []int     `json:"partitions,omitempty"`
+	Start         flux.Time `json:"start"`
+	Stop          flux.Time `json:"stop"`
+	StartOffset   int64     `json:"startOffset"`
+	StopOffset    int64     `json:"stopOffset"`
+	Limit         int64     `json:"limit,omitempty"`
+	Format        string    `json:"format"`
+	TLS           bool      `json:"tls,omitempty"`
+	SASLMechanism string    `json:"saslMechanism,omitempty"`
+	Username      string    `json:"username,omitempty"`
+	Password      string    `json:"password,omitempty"`
+}
+
+func init() {
+	fromSignature := runtime.MustLookupBuiltinType(pkgpath, "from")
+	runtime.RegisterPackageValue(pkgpath, "from", flux.MustValue(flux.FunctionValue(FromKind, createFromOpSpec, fromSignature)))
+	flux.RegisterOpSpec(FromKind, newFromOp)
+	plan.RegisterProcedureSpec(FromKind, newFromProcedure, FromKind)
+	execute.RegisterSource(FromKind, createFromSource)
+}
+
+func createFromOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	spec := &FromOpSpec{
+		StartOffset: noOffset,
+		StopOffset:  noOffset,
+		Format:      rawFormat,
+	}
+
+	brokers, err := args.GetRequiredArray("brokers", semantic.String)
+	if err != nil {
+		return nil, err
+	}
+	if brokers.Len() < 1 {
+		return nil, errors.New(codes.Invalid, "at least one broker is required")
+	}
+	brokers.Range(func(i int, v values.Value) {
+		spec.Brokers = append(spec.Brokers, v.Str())
+	})
+	if spec.Topic, err = args.GetRequiredString("topic"); err != nil {
+		return nil, err
+	}
+	if spec.Topic == "" {
+		return nil, errors.New(codes.Invalid, "invalid topic name")
+	}
+
+	if partitions, ok, err := args.GetArray("partitions", semantic.Int); err != nil {
+		return nil, err
+	} else if ok {
+		var perr error
+		partitions.Range(func(i int, v values.Value) {
+			if p := v.Int(); p < 0 {
+				perr = errors.Newf(codes.Invalid, "invalid partition %d", p)
+			} else {
+				spec.Partitions = append(spec.Partitions, int(p))
+			}
+		})
+		if perr != nil {
+			return nil, perr
+		}
+	}
+
+	if spec.Start, _, err = args.GetTime("start"); err != nil {
+		return nil, err
+	}
+	if spec.Stop, _, err = args.GetTime("stop"); err != nil {
+		return nil, err
+	}
+	if offset, ok, err := args.GetInt("startOffset"); err != nil {
+		return nil, err
+	} else if ok {
+		if offset < 0 {
+			return nil, errors.Newf(codes.Invalid, "invalid start offset %d", offset)
+		}
+		if !spec.Start.IsZero() {
+			return nil, errors.New(codes.Invalid, "start and startOffset cannot both be set")
+		}
+		spec.StartOffset = offset
+	}
+	if offset, ok, err := args.GetInt("stopOffset"); err != nil {
+		return nil, err
+	} else if ok {
+		if offset < 0 {
+			return nil, errors.Newf(codes.Invalid, "invalid stop offset %d", offset)
+		}
+		if !spec.Stop.IsZero() {
+			return nil, errors.New(codes.Invalid, "stop and stopOffset cannot both be set")
+		}
+		spec.StopOffset = offset
+	}
+
+	if limit, ok, err := args.GetInt("limit"); err != nil {
+		return nil, err
+	} else if ok {
+		if limit <= 0 {
+			return nil, errors.Newf(codes.Invalid, "limit must be positive, got %d", limit)
+		}
+		spec.Limit = limit
+	}
+
+	if format, ok, err := args.GetString("format"); err != nil {
+		return nil, err
+	} else if ok {
+		if format != rawFormat && format != jsonFormat {
+			return nil, errors.Newf(codes.Invalid, "invalid format %q, must be %q or %q", format, rawFormat, jsonFormat)
+		}
+		spec.Format = format
+	}
+
+	if spec.TLS, _, err = args.GetBool("tls"); err != nil {
+		return nil, err
+	}
+	for name, field := range map[string]*string{
+		"saslMechanism": &spec.SASLMechanism,
+		"username":      &spec.Username,
+		"password":      &spec.Password,
+	} {
+		if v, ok, err := args.GetString(name); err != nil {
+			return nil, err
+		} else if ok {
+			*field = v
+		}
+	}
+	if spec.SASLMechanism != "" {
+		if _, err := kafkadeps.NewSASLMechanism(spec.SASLMechanism, spec.Username, spec.Password); err != nil {
+			return nil, err
+		}
+	}
+	return spec, nil
+}
+
+func newFromOp() flux.OperationSpec {
+	return new(FromOpSpec)
+}
+
+func (s *FromOpSpec) Kind() flux.OperationKind {
+	return FromKind
+}
+
+type FromProcedureSpec struct {
+	plan.DefaultCost
+	Brokers     []string
+	Topic       string
+	Partitions  []int
+	Start       time.Time
+	Stop        time.Time
+	StartOffset int64
+	StopOffset  int64
+	Limit       int64
+	Format      string
+	Options     kafkadeps.Options
+}
+
+func newFromProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*FromOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	ps := &FromProcedureSpec{
+		Brokers:     spec.Brokers,
+		Topic:       spec.Topic,
+		Partitions:  spec.Partitions,
+		StartOffset: spec.StartOffset,
+		StopOffset:  spec.StopOffset,
+		Limit:       spec.Limit,
+		Format:      spec.Format,
+		Options: kafkadeps.Options{
+			TLS:           spec.TLS,
+			SASLMechanism: spec.SASLMechanism,
+			Username:      spec.Username,
+			Password:      spec.Password,
+		},
+	}
+	if !spec.Start.IsZero() {
+		ps.Start = spec.Start.Time(pa.Now())
+	}
+	if !spec.Stop.IsZero() {
+		ps.Stop = spec.Stop.Time(pa.Now())
+	}
+	return ps, nil
+}
+
+func (s *FromProcedureSpec) Kind() plan.ProcedureKind {
+	return FromKind
+}
+
+func (s *FromProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	ns.Brokers = append([]string(nil), s.Brokers...)
+	ns.Partitions = append([]int(nil), s.Partitions...)
+	return &ns
+}
+
+func createFromSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*FromProcedureSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", prSpec)
+	}
+	return CreateSource(spec, dsid, a)
+}
+
+func CreateSource(spec *FromProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	ctx := a.Context()
+	validator, err := flux.GetDependencies(ctx).URLValidator()
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range spec.Brokers {
+		u, err := url.Parse(b)
+		if err != nil {
+			return nil, errors.Newf(codes.Invalid, "invalid kafka broker url: %v", err)
+		}
+		if err := validator.Validate(u); err != nil {
+			return nil, errors.Newf(codes.Invalid, "kafka broker url did not pass validation: %v", err)
+		}
+	}
+	dialer, err := kafkadeps.GetDialer(ctx).Dialer(ctx, spec.Options)
+	if err != nil {
+		return nil, err
+	}
+	return execute.CreateSourceFromIterator(&topicIterator{
+		spec:   spec,
+		reader: DefaultReaderFactory(spec.Brokers, spec.Topic, dialer),
+		alloc:  a.Allocator(),
+	}, dsid)
+}
+
+var _ execute.SourceIterator = (*topicIterator)(nil)
+
+// topicIterator reads the messages of each partition in turn,
+// each partition is a table.
+type topicIterator struct {
+	spec   *FromProcedureSpec
+	reader Reader
+	alloc  *memory.Allocator
+}
+
+func (t *topicIterator) Do(ctx context.Context, f func(flux.Table) error) error {
+	partitions := t.spec.Partitions
+	if len(partitions) == 0 {
+		var err error
+		if partitions, err = t.reader.Partitions(ctx); err != nil {
+			return errors.Wrap(err, codes.Inherit, "error in kafka.from()")
+		}
+		sort.Ints(partitions)
+	}
+	for _, p := range partitions {
+		tbl, err := t.readPartition(ctx, p)
+		if err != nil {
+			return errors.Wrapf(err, codes.Inherit, "error in kafka.from() reading partition %d", p)
+		}
+		if err := f(tbl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// offsets returns the range of offsets of the messages to read from a partition,
+// the start offset is inclusive and the end offset is exclusive.
+func (t *topicIterator) offsets(ctx context.Context, partition int) (start, end int64, err error) {
+	first, last, err := t.reader.Offsets(ctx, partition)
+	if err != nil {
+		return 0, 0, err
+	}
+	start, end = first, last
+	switch {
+	case t.spec.StartOffset != noOffset:
+		if t.spec.StartOffset > start {
+			start = t.spec.StartOffset
+		}
+	case !t.spec.Start.IsZero():
+		if start, err = t.reader.OffsetAt(ctx, partition, t.spec.Start); err != nil {
+			return 0, 0, err
+		}
+	}
+	if t.spec.StopOffset != noOffset && t.spec.StopOffset < end {
+		end = t.spec.StopOffset
+	}
+	return start, end, nil
+}
+
+func (t *topicIterator) readPartition(ctx context.Context, partition int) (flux.Table, error) {
+	start, end, err := t.offsets(ctx, partition)
+	if err != nil {
+		return nil, err
+	}
+
+	key := execute.NewGroupKey(
+		[]flux.ColMeta{
+			{Label: topicColLabel, Type: flux.TString},
+			{Label: partitionColLabel, Type: flux.TInt},
+		},
+		[]values.Value{
+			values.NewString(t.spec.Topic),
+			values.NewInt(int64(partition)),
+		},
+	)
+	b := execute.NewColListTableBuilder(key, t.alloc)
+	if err := execute.AddTableKeyCols(key, b); err != nil {
+		return nil, err
+	}
+	for _, col := range []flux.ColMeta{
+		{Label: execute.DefaultTimeColLabel, Type: flux.TTime},
+		{Label: offsetColLabel, Type: flux.TInt},
+		{Label: keyColLabel, Type: flux.TString},
+	} {
+		if _, err := b.AddCol(col); err != nil {
+			return nil, err
+		}
+	}
+	if t.spec.Format == rawFormat {
+		if _, err := b.AddCol(flux.ColMeta{Label: execute.DefaultValueColLabel, Type: flux.TString}); err != nil {
+			return nil, err
+		}
+	}
+
+	if start < end {
+		var n int64
+		errStop := errors.New(codes.Canceled, "stop reading")
+		err := t.reader.ReadMessages(ctx, partition, start, end, func(msg kafka.Message) error {
+			if !t.spec.Stop.IsZero() && !msg.Time.Before(t.spec.Stop) {
+				return errStop
+			}
+			if err := t.appendMessage(b, msg); err != nil {
+				return errors.Wrapf(err, codes.Inherit, "invalid message at offset %d", msg.Offset)
+			}
+			if n++; t.spec.Limit > 0 && n >= t.spec.Limit {
+				return errStop
+			}
+			return nil
+		})
+		if err != nil && err != errStop {
+			b.Release()
+			return nil, err
+		}
+	}
+	return b.Table()
+}
+
+func (t *topicIterator) appendMessage(b *execute.ColListTableBuilder, msg kafka.Message) error {
+	row := map[string]values.Value{
+		topicColLabel:               values.NewString(t.spec.Topic),
+		partitionColLabel:           values.NewInt(int64(msg.Partition)),
+		execute.DefaultTimeColLabel: values.NewTime(values.ConvertTime(msg.Time)),
+		offsetColLabel:              values.NewInt(msg.Offset),
+		keyColLabel:                 values.NewString(string(msg.Key)),
+	}
+	if t.spec.Format == rawFormat {
+		row[execute.DefaultValueColLabel] = values.NewString(string(msg.Value))
+	} else if err := decodeJSON(b, msg.Value, row); err != nil {
+		return err
+	}
+	for j, col := range b.Cols() {
+		v, ok := row[col.Label]
+		if !ok || v == nil {
+			if err := b.AppendNil(j); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := b.AppendValue(j, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeJSON decodes the JSON object of a message to the values of a row.
+// The properties are columns that are added as they appear, with the type of their first value.
+// Numbers are floats and nested values are JSON encoded strings.
+func decodeJSON(b *execute.ColListTableBuilder, data []byte, row map[string]values.Value) error {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return errors.Wrap(err, codes.Invalid, "the message is not a JSON object")
+	}
+	labels := make([]string, 0, len(obj))
+	for label := range obj {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	for _, label := range labels {
+		if _, ok := row[label]; ok {
+			return errors.Newf(codes.Invalid, "property %q conflicts with a column of the message metadata", label)
+		}
+		v, err := jsonValue(obj[label])
+		if err != nil {
+			return errors.Wrapf(err, codes.Inherit, "invalid property %q", label)
+		}
+		row[label] = v
+		if v == nil {
+			continue
+		}
+		typ := flux.ColumnType(v.Type())
+		if j := execute.ColIdx(label, b.Cols()); j < 0 {
+			if _, err := b.AddCol(flux.ColMeta{Label: label, Type: typ}); err != nil {
+				return err
+			}
+		} else if b.Cols()[j].Type != typ {
+			return errors.Newf(codes.Invalid, "property %q is a %s, expected a %s", label, typ, b.Cols()[j].Type)
+		}
+	}
+	return nil
+}
+
+// jsonValue converts a JSON value to a flux value, null values are nil.
+func jsonValue(data json.RawMessage) (values.Value, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid JSON")
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		return values.NewFloat(v), nil
+	case string:
+		return values.NewString(v), nil
+	case bool:
+		return values.NewBool(v), nil
+	default:
+		return values.NewString(string(data)), nil
+	}
+}
@@ -0,0 +1,243 @@
+package kafka_test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/mock"
+	"github.com/influxdata/flux/querytest"
+	fkafka "github.com/influxdata/flux/stdlib/experimental/kafka"
+	"github.com/segmentio/kafka-go"
+)
+
+// memTopic is a topic with the messages of each partition in memory,
+// the offsets of the messages of a partition start at 10.
+type memTopic map[int][]kafka.Message
+
+const firstOffset = 10
+
+func (m memTopic) Partitions(ctx context.Context) ([]int, error) {
+	var partitions []int
+	for p := range m {
+		partitions = append(partitions, p)
+	}
+	sort.Ints(partitions)
+	return partitions, nil
+}
+
+func (m memTopic) Offsets(ctx context.Context, partition int) (int64, int64, error) {
+	msgs, ok := m[partition]
+	if !ok {
+		return 0, 0, errors.Newf(codes.NotFound, "unknown partition %d", partition)
+	}
+	return firstOffset, firstOffset + int64(len(msgs)), nil
+}
+
+func (m memTopic) OffsetAt(ctx context.Context, partition int, t time.Time) (int64, error) {
+	msgs := m[partition]
+	i := sort.Search(len(msgs), func(i int) bool {
+		return !msgs[i].Time.Before(t)
+	})
+	return firstOffset + int64(i), nil
+}
+
+func (m memTopic) ReadMessages(ctx context.Context, partition int, start, end int64, fn func(kafka.Message) error) error {
+	for i, msg := range m[partition] {
+		msg.Partition = partition
+		msg.Offset = firstOffset + int64(i)
+		if msg.Offset < start || msg.Offset >= end {
+			continue
+		}
+		if err := fn(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestFrom(t *testing.T) {
+	ts := func(sec int64) time.Time {
+		return time.Unix(sec, 0).UTC()
+	}
+	topic := memTopic{
+		0: {
+			{Time: ts(1), Key: []byte("a"), Value: []byte(`{"host": "a", "value": 1}`)},
+			{Time: ts(2), Key: []byte("b"), Value: []byte(`{"host": "b", "ok": true, "tags": {"x": 1}}`)},
+			{Time: ts(3), Key: []byte("c"), Value: []byte(`{"host": "c", "value": 3.5}`)},
+		},
+		1: {
+			{Time: ts(4), Value: []byte(`{"host": "d", "value": 4}`)},
+		},
+	}
+	fkafka.DefaultReaderFactory = func(brokers []string, topicName string, dialer *kafka.Dialer) fkafka.Reader {
+		return topic
+	}
+
+	metaCols := []flux.ColMeta{
+		{Label: "topic", Type: flux.TString},
+		{Label: "partition", Type: flux.TInt},
+		{Label: "_time", Type: flux.TTime},
+		{Label: "offset", Type: flux.TInt},
+		{Label: "key", Type: flux.TString},
+	}
+	withCols := func(cols ...flux.ColMeta) []flux.ColMeta {
+		return append(append([]flux.ColMeta(nil), metaCols...), cols...)
+	}
+	sec := func(n int64) execute.Time {
+		return execute.Time(n * int64(time.Second))
+	}
+
+	tests := []struct {
+		name    string
+		spec    *fkafka.FromProcedureSpec
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "raw",
+			spec: &fkafka.FromProcedureSpec{StartOffset: -1, StopOffset: -1, Format: "raw"},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"topic", "partition"},
+					ColMeta: withCols(flux.ColMeta{Label: "_value", Type: flux.TString}),
+					Data: [][]interface{}{
+						{"t", int64(0), sec(1), int64(10), "a", `{"host": "a", "value": 1}`},
+						{"t", int64(0), sec(2), int64(11), "b", `{"host": "b", "ok": true, "tags": {"x": 1}}`},
+						{"t", int64(0), sec(3), int64(12), "c", `{"host": "c", "value": 3.5}`},
+					},
+				},
+				{
+					KeyCols: []string{"topic", "partition"},
+					ColMeta: withCols(flux.ColMeta{Label: "_value", Type: flux.TString}),
+					Data: [][]interface{}{
+						{"t", int64(1), sec(4), int64(10), "", `{"host": "d", "value": 4}`},
+					},
+				},
+			},
+		},
+		{
+			name: "json",
+			spec: &fkafka.FromProcedureSpec{Partitions: []int{0}, StartOffset: -1, StopOffset: -1, Format: "json"},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"topic", "partition"},
+					ColMeta: withCols(
+						flux.ColMeta{Label: "host", Type: flux.TString},
+						flux.ColMeta{Label: "value", Type: flux.TFloat},
+						flux.ColMeta{Label: "ok", Type: flux.TBool},
+						flux.ColMeta{Label: "tags", Type: flux.TString},
+					),
+					Data: [][]interface{}{
+						{"t", int64(0), sec(1), int64(10), "a", "a", 1.0, nil, nil},
+						{"t", int64(0), sec(2), int64(11), "b", "b", nil, true, `{"x": 1}`},
+						{"t", int64(0), sec(3), int64(12), "c", "c", 3.5, nil, nil},
+					},
+				},
+			},
+		},
+		{
+			name: "offsets",
+			spec: &fkafka.FromProcedureSpec{Partitions: []int{0}, StartOffset: 11, StopOffset: 12, Format: "raw"},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"topic", "partition"},
+					ColMeta: withCols(flux.ColMeta{Label: "_value", Type: flux.TString}),
+					Data: [][]interface{}{
+						{"t", int64(0), sec(2), int64(11), "b", `{"host": "b", "ok": true, "tags": {"x": 1}}`},
+					},
+				},
+			},
+		},
+		{
+			name: "times and limit",
+			spec: &fkafka.FromProcedureSpec{
+				Start:       ts(2),
+				Stop:        ts(4),
+				StartOffset: -1,
+				StopOffset:  -1,
+				Limit:       1,
+				Format:      "raw",
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"topic", "partition"},
+					ColMeta: withCols(flux.ColMeta{Label: "_value", Type: flux.TString}),
+					Data: [][]interface{}{
+						{"t", int64(0), sec(2), int64(11), "b", `{"host": "b", "ok": true, "tags": {"x": 1}}`},
+					},
+				},
+				{
+					KeyCols:   []string{"topic", "partition"},
+					KeyValues: []interface{}{"t", int64(1)},
+					ColMeta:   withCols(flux.ColMeta{Label: "_value", Type: flux.TString}),
+				},
+			},
+		},
+		{
+			name:    "unknown partition",
+			spec:    &fkafka.FromProcedureSpec{Partitions: []int{5}, StartOffset: -1, StopOffset: -1, Format: "raw"},
+			wantErr: errors.New(codes.NotFound, "error in kafka.from() reading partition 5: unknown partition 5"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.spec.Brokers = []string{"localhost:9092"}
+			test.spec.Topic = "t"
+			executetest.RunSourceHelper(t,
+				test.want,
+				test.wantErr,
+				func(id execute.DatasetID) execute.Source {
+					ctx := dependenciestest.Default().Inject(context.Background())
+					a := mock.AdministrationWithContext(ctx)
+					s, err := fkafka.CreateSource(test.spec, id, a)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return s
+				},
+			)
+		})
+	}
+}
+
+func TestFrom_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name:    "no brokers",
+			Raw:     `import "experimental/kafka" kafka.from(brokers: [], topic: "t")`,
+			WantErr: true,
+		},
+		{
+			Name:    "start and start offset",
+			Raw:     `import "experimental/kafka" kafka.from(brokers: ["localhost:9092"], topic: "t", start: -1h, startOffset: 10)`,
+			WantErr: true,
+		},
+		{
+			Name:    "invalid format",
+			Raw:     `import "experimental/kafka" kafka.from(brokers: ["localhost:9092"], topic: "t", format: "avro")`,
+			WantErr: true,
+		},
+		{
+			Name:    "invalid sasl mechanism",
+			Raw:     `import "experimental/kafka" kafka.from(brokers: ["localhost:9092"], topic: "t", saslMechanism: "gssapi", username: "u")`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
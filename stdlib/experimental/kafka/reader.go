@@ -0,0 +1,147 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/segmentio/kafka-go"
+)
+
+// maxBatchBytes is the maximum size of the batches of messages read from the brokers.
+const maxBatchBytes = 10 << 20
+
+// Reader reads the messages of the partitions of a topic.
+type Reader interface {
+	// Partitions returns the partitions of the topic.
+	Partitions(ctx context.Context) ([]int, error)
+	// Offsets returns the offset of the first message of a partition
+	// and the offset after its last message.
+	Offsets(ctx context.Context, partition int) (first, last int64, err error)
+	// OffsetAt returns the offset of the first message of a partition at or after a time.
+	OffsetAt(ctx context.Context, partition int, t time.Time) (int64, error)
+	// ReadMessages calls fn with the messages of a partition in order, from the start offset
+	// until the end offset, which is exclusive, or until fn returns an error.
+	ReadMessages(ctx context.Context, partition int, start, end int64, fn func(kafka.Message) error) error
+}
+
+// DefaultReaderFactory makes the Reader of a topic, it is injectable for testing.
+var DefaultReaderFactory = func(brokers []string, topic string, dialer *kafka.Dialer) Reader {
+	return &connReader{
+		brokers: brokers,
+		topic:   topic,
+		dialer:  dialer,
+	}
+}
+
+// connReader reads the messages with connections to the leaders of the partitions.
+type connReader struct {
+	brokers []string
+	topic   string
+	dialer  *kafka.Dialer
+}
+
+func (r *connReader) Partitions(ctx context.Context) ([]int, error) {
+	var err error
+	for _, broker := range r.brokers {
+		var partitions []kafka.Partition
+		if partitions, err = r.dialer.LookupPartitions(ctx, "tcp", broker, r.topic); err == nil {
+			ids := make([]int, len(partitions))
+			for i, p := range partitions {
+				ids[i] = p.ID
+			}
+			return ids, nil
+		}
+	}
+	return nil, errors.Wrapf(err, codes.Unavailable, "failed to look up the partitions of topic %q", r.topic)
+}
+
+// dial connects to the leader of a partition.
+func (r *connReader) dial(ctx context.Context, partition int) (*kafka.Conn, error) {
+	var err error
+	for _, broker := range r.brokers {
+		var conn *kafka.Conn
+		if conn, err = r.dialer.DialLeader(ctx, "tcp", broker, r.topic, partition); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, errors.Wrapf(err, codes.Unavailable, "failed to connect to the leader of partition %d", partition)
+}
+
+func (r *connReader) Offsets(ctx context.Context, partition int) (int64, int64, error) {
+	conn, err := r.dial(ctx, partition)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer func() { _ = conn.Close() }()
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return 0, 0, errors.Wrap(err, codes.Unavailable, "failed to read the offsets")
+	}
+	return first, last, nil
+}
+
+func (r *connReader) OffsetAt(ctx context.Context, partition int, t time.Time) (int64, error) {
+	conn, err := r.dial(ctx, partition)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = conn.Close() }()
+	offset, err := conn.ReadOffset(t)
+	if err != nil {
+		return 0, errors.Wrapf(err, codes.Unavailable, "failed to read the offset at %v", t)
+	}
+	return offset, nil
+}
+
+func (r *connReader) ReadMessages(ctx context.Context, partition int, start, end int64, fn func(kafka.Message) error) error {
+	conn, err := r.dial(ctx, partition)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	if _, err := conn.Seek(start, kafka.SeekAbsolute); err != nil {
+		return errors.Wrapf(err, codes.Invalid, "failed to seek offset %d", start)
+	}
+
+	offset := start
+	for offset < end {
+		deadline, ok := ctx.Deadline()
+		if !ok {
+			deadline = time.Now().Add(r.dialer.Timeout)
+		}
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			return err
+		}
+		batch := conn.ReadBatch(1, maxBatchBytes)
+		read := false
+		for offset < end {
+			msg, err := batch.ReadMessage()
+			if err != nil {
+				break
+			}
+			read = true
+			offset = msg.Offset + 1
+			if msg.Offset >= end {
+				break
+			}
+			if err := fn(msg); err != nil {
+				_ = batch.Close()
+				return err
+			}
+		}
+		if err := batch.Close(); err != nil && err != io.EOF {
+			return errors.Wrapf(err, codes.Unavailable, "failed to read the messages after offset %d", offset)
+		}
+		if !read {
+			// the remaining offsets have no messages, eg. the markers of transactions
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -25,6 +25,9 @@ type CommonMQTTOpSpec struct {
 	Retain      bool          `json:"retain"`
 	Timeout     time.Duration `json:"timeout"`
 	NoKeepAlive bool          `json:"noKeepAlive"`
+	TLSCA       string        `json:"tlsCA,omitempty"`
+	TLSCert     string        `json:"tlsCert,omitempty"`
+	TLSKey      string        `json:"tlsKey,omitempty"`
 }
 
 func (o *CommonMQTTOpSpec) ReadArgs(args flux.Arguments) error {
@@ -64,11 +67,10 @@ func (o *CommonMQTTOpSpec) ReadArgs(args flux.Arguments) error {
 	if err != nil {
 		return err
 	}
-	if !ok || qos < 0 || qos > 3 {
-		o.QoS = 0
-	} else {
-		o.QoS = qos
+	if ok && (qos < 0 || qos > 2) {
+		return errors.Newf(codes.Invalid, "qos must be 0, 1 or 2, got %d", qos)
 	}
+	o.QoS = qos
 
 	retain, ok, err := args.GetBool("retain")
 	if err != nil {
@@ -86,6 +88,21 @@ func (o *CommonMQTTOpSpec) ReadArgs(args flux.Arguments) error {
 		o.Timeout = values.Duration(timeout).Duration()
 	}
 
+	for name, field := range map[string]*string{
+		"tlsCA":   &o.TLSCA,
+		"tlsCert": &o.TLSCert,
+		"tlsKey":  &o.TLSKey,
+	} {
+		if v, ok, err := args.GetString(name); err != nil {
+			return err
+		} else if ok {
+			*field = v
+		}
+	}
+	if (o.TLSCert == "") != (o.TLSKey == "") {
+		return errors.New(codes.Invalid, "tlsCert and tlsKey must be set together")
+	}
+
 	return nil
 }
 
@@ -95,6 +112,10 @@ func publish(ctx context.Context, topic, message string, spec *CommonMQTTOpSpec)
 		Username: spec.Username,
 		Password: spec.Password,
 		Timeout:  spec.Timeout,
+
+		CACert:     spec.TLSCA,
+		ClientCert: spec.TLSCert,
+		ClientKey:  spec.TLSKey,
 	}
 	provider := mqtt.GetDialer(ctx)
 	client, err := provider.Dial(ctx, []string{spec.Broker}, options)
@@ -136,8 +136,10 @@ func (o *ToMQTTOpSpec) UnmarshalJSON(b []byte) (err error) {
 	if err != nil {
 		return err
 	}
-	if !(u.Scheme == "tcp" || u.Scheme == "ws" || u.Scheme == "tls") {
-		return errors.Newf(codes.Invalid, "scheme must be tcp or ws or tls but was %s", u.Scheme)
+	switch u.Scheme {
+	case "tcp", "ws", "wss", "tls", "ssl", "tcps":
+	default:
+		return errors.Newf(codes.Invalid, "scheme must be tcp, tls, ssl, tcps, ws or wss but was %s", u.Scheme)
 	}
 	return nil
 }
@@ -161,12 +163,16 @@ func (o *ToMQTTProcedureSpec) Copy() plan.ProcedureSpec {
 		Spec: &ToMQTTOpSpec{
 			CommonMQTTOpSpec: CommonMQTTOpSpec{
 				Broker:      s.Broker,
+				ClientID:    s.ClientID,
 				QoS:         s.QoS,
 				Retain:      s.Retain,
 				Username:    s.Username,
 				Password:    s.Password,
 				Timeout:     s.Timeout,
 				NoKeepAlive: s.NoKeepAlive,
+				TLSCA:       s.TLSCA,
+				TLSCert:     s.TLSCert,
+				TLSKey:      s.TLSKey,
 			},
 			Topic:        s.Topic,
 			Name:         s.Name,
@@ -369,7 +375,9 @@ func (t *ToMQTTTransformation) Process(id execute.DatasetID, tbl flux.Table) err
 			topic = m.createTopic(message)
 		}
 		spec := &t.spec.Spec.CommonMQTTOpSpec
-		publish(t.ctx, topic, message, spec)
+		if _, err := publish(t.ctx, topic, message, spec); err != nil {
+			return err
+		}
 	}
 
 	return nil
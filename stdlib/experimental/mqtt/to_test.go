@@ -93,6 +93,20 @@ import "experimental/mqtt"
 from(bucket:"mybucket") |> mqtt.to(broker: "tcp://iot.eclipse.org:1883", username: "tester")`,
 			WantErr: true, // password is required with username
 		},
+		{
+			Name: "from bucket with invalid qos",
+			Raw: `
+import "experimental/mqtt"
+from(bucket:"mybucket") |> mqtt.to(broker: "tcp://iot.eclipse.org:1883", qos: 3)`,
+			WantErr: true,
+		},
+		{
+			Name: "from bucket with certificate without key",
+			Raw: `
+import "experimental/mqtt"
+from(bucket:"mybucket") |> mqtt.to(broker: "ssl://iot.eclipse.org:8883", tlsCert: "cert")`,
+			WantErr: true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -0,0 +1,278 @@
+package oauth2
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	depsurl "github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const packagePath = "experimental/oauth2"
+
+func init() {
+	runtime.RegisterPackageValue(packagePath, "clientCredentials", values.NewFunction(
+		"clientCredentials",
+		runtime.MustLookupBuiltinType(packagePath, "clientCredentials"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			return interpreter.DoFunctionCallContext(clientCredentials, ctx, args)
+		},
+		false,
+	))
+	runtime.RegisterPackageValue(packagePath, "refreshToken", values.NewFunction(
+		"refreshToken",
+		runtime.MustLookupBuiltinType(packagePath, "refreshToken"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			return interpreter.DoFunctionCallContext(refreshToken, ctx, args)
+		},
+		false,
+	))
+}
+
+// client holds the arguments shared by the flows.
+type client struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+}
+
+func readClient(args interpreter.Arguments) (client, error) {
+	var c client
+	var err error
+	if c.tokenURL, err = args.GetRequiredString("tokenURL"); err != nil {
+		return c, err
+	}
+	if c.clientID, err = args.GetRequiredString("clientID"); err != nil {
+		return c, err
+	}
+	if c.clientSecret, err = args.GetRequiredString("clientSecret"); err != nil {
+		return c, err
+	}
+	if scopes, ok, err := args.GetArray("scopes", semantic.String); err != nil {
+		return c, err
+	} else if ok {
+		if c.scopes, err = interpreter.ToStringArray(scopes); err != nil {
+			return c, err
+		}
+	}
+	return c, nil
+}
+
+func clientCredentials(ctx context.Context, args interpreter.Arguments) (values.Value, error) {
+	c, err := readClient(args)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	if p, ok, err := args.GetObject("params"); err != nil {
+		return nil, err
+	} else if ok {
+		var rangeErr error
+		p.Range(func(k string, v values.Value) {
+			if v.Type().Nature() != semantic.String {
+				rangeErr = errors.Newf(codes.Invalid, "param %q must be a string", k)
+				return
+			}
+			params.Set(k, v.Str())
+		})
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+	}
+
+	key := cacheKey("client_credentials", c.tokenURL, c.clientID, c.clientSecret, strings.Join(c.scopes, " "), params.Encode())
+	return token(ctx, c.tokenURL, key, func(ctx context.Context, _ *oauth2.Token) (*oauth2.Token, error) {
+		config := clientcredentials.Config{
+			ClientID:       c.clientID,
+			ClientSecret:   c.clientSecret,
+			TokenURL:       c.tokenURL,
+			Scopes:         c.scopes,
+			EndpointParams: params,
+		}
+		return config.Token(ctx)
+	})
+}
+
+func refreshToken(ctx context.Context, args interpreter.Arguments) (values.Value, error) {
+	c, err := readClient(args)
+	if err != nil {
+		return nil, err
+	}
+	rt, err := args.GetRequiredString("refreshToken")
+	if err != nil {
+		return nil, err
+	}
+
+	key := cacheKey("refresh_token", c.tokenURL, c.clientID, c.clientSecret, strings.Join(c.scopes, " "), rt)
+	return token(ctx, c.tokenURL, key, func(ctx context.Context, cached *oauth2.Token) (*oauth2.Token, error) {
+		config := oauth2.Config{
+			ClientID:     c.clientID,
+			ClientSecret: c.clientSecret,
+			Endpoint:     oauth2.Endpoint{TokenURL: c.tokenURL},
+			Scopes:       c.scopes,
+		}
+		// the token endpoint may have rotated the refresh token
+		t := &oauth2.Token{RefreshToken: rt}
+		if cached != nil && cached.RefreshToken != "" {
+			t.RefreshToken = cached.RefreshToken
+		}
+		return config.TokenSource(ctx, t).Token()
+	})
+}
+
+// token returns the cached token for key if it is still valid,
+// or obtains a new one with fetch using the HTTP client of the dependencies.
+func token(ctx context.Context, tokenURL, key string, fetch func(context.Context, *oauth2.Token) (*oauth2.Token, error)) (values.Value, error) {
+	cached, ok := tokens.get(key)
+	if ok && cached.Valid() {
+		return newTokenObject(cached), nil
+	}
+
+	u, err := url.Parse(tokenURL)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid tokenURL")
+	}
+	deps := flux.GetDependencies(ctx)
+	validator, err := deps.URLValidator()
+	if err != nil {
+		return nil, err
+	}
+	if err := depsurl.ForPackage(validator, depsurl.HTTPPackage).Validate(u); err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "tokenURL did not pass url validation")
+	}
+	dc, err := deps.HTTPClient()
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Aborted, "missing client in oauth2")
+	}
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
+		Transport: doerTransport{doer: dc},
+	})
+
+	t, err := fetch(ctx, cached)
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Unauthenticated, "failed to obtain an oauth2 token")
+	}
+	tokens.put(key, t)
+	return newTokenObject(t), nil
+}
+
+func newTokenObject(t *oauth2.Token) values.Object {
+	expiry := values.NewNull(semantic.BasicTime)
+	if !t.Expiry.IsZero() {
+		expiry = values.NewTime(values.ConvertTime(t.Expiry))
+	}
+	return values.NewObjectWithValues(map[string]values.Value{
+		"accessToken": values.NewString(t.AccessToken),
+		"tokenType":   values.NewString(t.Type()),
+		"expiry":      expiry,
+		"headers": values.NewObjectWithValues(map[string]values.Value{
+			"Authorization": values.NewString(t.Type() + " " + t.AccessToken),
+		}),
+	})
+}
+
+// doerTransport sends the token requests with the HTTP client of the dependencies,
+// which validates the addresses it connects to.
+type doerTransport struct {
+	doer interface {
+		Do(*http.Request) (*http.Response, error)
+	}
+}
+
+func (t doerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.doer.Do(req)
+}
+
+// cacheKey hashes the parts of a key so that the secrets
+// are not used as keys of the cache.
+func cacheKey(parts ...string) string {
+	h := sha256.New()
+	for _, p := range parts {
+		_, _ = h.Write([]byte(p))
+		_, _ = h.Write([]byte{0})
+	}
+	return string(h.Sum(nil))
+}
+
+// maxCachedTokens is the number of tokens that are cached.
+// The least recently used tokens are evicted first.
+const maxCachedTokens = 1000
+
+// tokenCache caches the tokens of all queries.
+type tokenCache struct {
+	mu     sync.Mutex
+	max    int
+	tokens map[string]*list.Element
+	// lru holds the cached tokens, from the most
+	// to the least recently used.
+	lru *list.List
+}
+
+type cachedToken struct {
+	key   string
+	token *oauth2.Token
+}
+
+var tokens = newTokenCache(maxCachedTokens)
+
+func newTokenCache(max int) *tokenCache {
+	return &tokenCache{
+		max:    max,
+		tokens: make(map[string]*list.Element),
+		lru:    list.New(),
+	}
+}
+
+func (c *tokenCache) get(key string) (*oauth2.Token, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.tokens[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cachedToken).token, true
+}
+
+func (c *tokenCache) put(key string, t *oauth2.Token) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.tokens[key]; ok {
+		elem.Value.(*cachedToken).token = t
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.tokens[key] = c.lru.PushFront(&cachedToken{key: key, token: t})
+
+	// drop the tokens that expired and cannot be refreshed,
+	// then the least recently used tokens
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		if cached := elem.Value.(*cachedToken); !cached.token.Valid() && cached.token.RefreshToken == "" {
+			c.remove(elem)
+		}
+		elem = next
+	}
+	for c.lru.Len() > c.max {
+		c.remove(c.lru.Back())
+	}
+}
+
+func (c *tokenCache) remove(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.tokens, elem.Value.(*cachedToken).key)
+}
@@ -0,0 +1,45 @@
+package oauth2
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+func TestTokenCache_Evict(t *testing.T) {
+	c := newTokenCache(2)
+	refreshable := &oauth2.Token{AccessToken: "a", RefreshToken: "r", Expiry: time.Now().Add(time.Hour)}
+	c.put("a", refreshable)
+	c.put("b", &oauth2.Token{AccessToken: "b", Expiry: time.Now().Add(time.Hour)})
+	// a is used more recently than b, so b is evicted
+	if _, ok := c.get("a"); !ok {
+		t.Fatal("expected token a to be cached")
+	}
+	c.put("c", &oauth2.Token{AccessToken: "c", Expiry: time.Now().Add(time.Hour)})
+	if _, ok := c.get("b"); ok {
+		t.Error("expected the least recently used token b to be evicted")
+	}
+
+	// tokens with refresh tokens are evicted too
+	c.put("d", &oauth2.Token{AccessToken: "d"})
+	for _, key := range []string{"a", "b"} {
+		if _, ok := c.get(key); ok {
+			t.Errorf("expected token %s to be evicted", key)
+		}
+	}
+	for _, key := range []string{"c", "d"} {
+		if _, ok := c.get(key); !ok {
+			t.Errorf("expected token %s to be cached", key)
+		}
+	}
+
+	// expired tokens that cannot be refreshed are dropped
+	c.put("e", &oauth2.Token{AccessToken: "e", Expiry: time.Now().Add(-time.Hour)})
+	if _, ok := c.get("e"); ok {
+		t.Error("expected the expired token e to be dropped")
+	}
+	if want, got := 2, c.lru.Len(); want != got {
+		t.Errorf("unexpected number of cached tokens -want/+got:\n\t- %d\n\t+ %d", want, got)
+	}
+}
@@ -0,0 +1,107 @@
+package oauth2_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/influxdata/flux"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/runtime"
+)
+
+func TestClientCredentials(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		if want, got := "client_credentials", r.PostForm.Get("grant_type"); want != got {
+			t.Errorf("unexpected grant_type want: %q got: %q", want, got)
+		}
+		if want, got := "read write", r.PostForm.Get("scope"); want != got {
+			t.Errorf("unexpected scope want: %q got: %q", want, got)
+		}
+		if want, got := "api", r.PostForm.Get("audience"); want != got {
+			t.Errorf("unexpected audience want: %q got: %q", want, got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"abc","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer ts.Close()
+
+	script := fmt.Sprintf(`
+import "experimental/oauth2"
+import "internal/testutil"
+
+token = () => oauth2.clientCredentials(tokenURL: "%s", clientID: "id", clientSecret: "secret", scopes: ["read", "write"], params: {audience: "api"})
+
+a = token()
+b = token()
+a.accessToken == "abc" or testutil.fail()
+a.headers.Authorization == "Bearer abc" or testutil.fail()
+b.accessToken == "abc" or testutil.fail()
+`, ts.URL)
+
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	if _, _, err := runtime.Eval(ctx, script); err != nil {
+		t.Fatal("evaluation of oauth2.clientCredentials failed: ", err)
+	}
+	if want, got := 1, requests; want != got {
+		t.Errorf("unexpected number of token requests want: %d got: %d", want, got)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(400)
+			return
+		}
+		if want, got := "refresh_token", r.PostForm.Get("grant_type"); want != got {
+			t.Errorf("unexpected grant_type want: %q got: %q", want, got)
+		}
+		if want, got := "r1", r.PostForm.Get("refresh_token"); want != got {
+			t.Errorf("unexpected refresh_token want: %q got: %q", want, got)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"xyz","token_type":"Bearer","refresh_token":"r2","expires_in":3600}`))
+	}))
+	defer ts.Close()
+
+	script := fmt.Sprintf(`
+import "experimental/oauth2"
+import "internal/testutil"
+
+token = oauth2.refreshToken(tokenURL: "%s", clientID: "id", clientSecret: "secret", refreshToken: "r1")
+token.accessToken == "xyz" or testutil.fail()
+`, ts.URL)
+
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	if _, _, err := runtime.Eval(ctx, script); err != nil {
+		t.Fatal("evaluation of oauth2.refreshToken failed: ", err)
+	}
+}
+
+func TestClientCredentials_Unauthorized(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(401)
+		_, _ = w.Write([]byte(`{"error":"invalid_client"}`))
+	}))
+	defer ts.Close()
+
+	script := fmt.Sprintf(`
+import "experimental/oauth2"
+
+oauth2.clientCredentials(tokenURL: "%s", clientID: "id", clientSecret: "wrong")
+`, ts.URL)
+
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	if _, _, err := runtime.Eval(ctx, script); err == nil {
+		t.Fatal("expected an error for an unauthorized client")
+	}
+}
@@ -0,0 +1,262 @@
+package objectstore
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/csv"
+	"github.com/influxdata/flux/dependencies/objectstore"
+	"github.com/influxdata/flux/dependencies/tablebuffer"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const (
+	pkgpath  = "experimental/objectstore"
+	FromKind = pkgpath + ".from"
+)
+
+const (
+	csvFormat  = "csv"
+	jsonFormat = "json"
+
+	annotationMode = "annotations"
+	rawMode        = "raw"
+)
+
+type FromOpSpec struct {
+	Bucket       string `json:"bucket"`
+	Key          string `json:"key,omitempty"`
+	Prefix       string `json:"prefix,omitempty"`
+	Provider     string `json:"provider,omitempty"`
+	Region       string `json:"region,omitempty"`
+	Endpoint     string `json:"endpoint,omitempty"`
+	AccessKey    string `json:"accessKey,omitempty"`
+	SecretKey    string `json:"secretKey,omitempty"`
+	SessionToken string `json:"sessionToken,omitempty"`
+	Format       string `json:"format"`
+	Mode         string `json:"mode"`
+}
+
+func init() {
+	fromSignature := runtime.MustLookupBuiltinType(pkgpath, "from")
+	runtime.RegisterPackageValue(pkgpath, "from", flux.MustValue(flux.FunctionValue(FromKind, createFromOpSpec, fromSignature)))
+	flux.RegisterOpSpec(FromKind, newFromOp)
+	plan.RegisterProcedureSpec(FromKind, newFromProcedure, FromKind)
+	execute.RegisterSource(FromKind, createFromSource)
+}
+
+func createFromOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	spec := new(FromOpSpec)
+	var err error
+	if spec.Bucket, err = args.GetRequiredString("bucket"); err != nil {
+		return nil, err
+	}
+	for name, field := range map[string]*string{
+		"key":          &spec.Key,
+		"prefix":       &spec.Prefix,
+		"provider":     &spec.Provider,
+		"region":       &spec.Region,
+		"endpoint":     &spec.Endpoint,
+		"accessKey":    &spec.AccessKey,
+		"secretKey":    &spec.SecretKey,
+		"sessionToken": &spec.SessionToken,
+	} {
+		if v, ok, err := args.GetString(name); err != nil {
+			return nil, err
+		} else if ok {
+			*field = v
+		}
+	}
+	if (spec.Key == "") == (spec.Prefix == "") {
+		return nil, errors.New(codes.Invalid, "must provide exactly one of the parameters key or prefix")
+	}
+
+	spec.Format = csvFormat
+	if format, ok, err := args.GetString("format"); err != nil {
+		return nil, err
+	} else if ok {
+		if format != csvFormat && format != jsonFormat {
+			return nil, errors.Newf(codes.Invalid, "invalid format %q, must be %q or %q", format, csvFormat, jsonFormat)
+		}
+		spec.Format = format
+	}
+
+	spec.Mode = annotationMode
+	if mode, ok, err := args.GetString("mode"); err != nil {
+		return nil, err
+	} else if ok {
+		if mode != annotationMode && mode != rawMode {
+			return nil, errors.Newf(codes.Invalid, "invalid mode %q, must be %q or %q", mode, annotationMode, rawMode)
+		}
+		spec.Mode = mode
+	}
+	return spec, nil
+}
+
+func newFromOp() flux.OperationSpec {
+	return new(FromOpSpec)
+}
+
+func (s *FromOpSpec) Kind() flux.OperationKind {
+	return FromKind
+}
+
+type FromProcedureSpec struct {
+	plan.DefaultCost
+	Config objectstore.Config
+	Key    string
+	Prefix string
+	Format string
+	Mode   string
+}
+
+func newFromProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*FromOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &FromProcedureSpec{
+		Config: objectstore.Config{
+			Provider: spec.Provider,
+			Bucket:   spec.Bucket,
+			Region:   spec.Region,
+			Endpoint: spec.Endpoint,
+			Credentials: objectstore.Credentials{
+				AccessKey:    spec.AccessKey,
+				SecretKey:    spec.SecretKey,
+				SessionToken: spec.SessionToken,
+			},
+		},
+		Key:    spec.Key,
+		Prefix: spec.Prefix,
+		Format: spec.Format,
+		Mode:   spec.Mode,
+	}, nil
+}
+
+func (s *FromProcedureSpec) Kind() plan.ProcedureKind {
+	return FromKind
+}
+
+func (s *FromProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func createFromSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*FromProcedureSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", prSpec)
+	}
+	return CreateSource(spec, dsid, a)
+}
+
+func CreateSource(spec *FromProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	return execute.CreateSourceFromIterator(&objectIterator{
+		spec:     spec,
+		provider: objectstore.GetProvider(a.Context()),
+		alloc:    a.Allocator(),
+	}, dsid)
+}
+
+var _ execute.SourceIterator = (*objectIterator)(nil)
+
+// objectIterator decodes the tables of each object in turn.
+type objectIterator struct {
+	spec     *FromProcedureSpec
+	provider objectstore.Provider
+	alloc    *memory.Allocator
+}
+
+func (o *objectIterator) Do(ctx context.Context, f func(flux.Table) error) error {
+	bucket, err := o.provider.Bucket(ctx, o.spec.Config)
+	if err != nil {
+		return errors.Wrap(err, codes.Inherit, "error in objectstore.from()")
+	}
+	keys := []string{o.spec.Key}
+	if o.spec.Prefix != "" {
+		if keys, err = bucket.List(ctx, o.spec.Prefix); err != nil {
+			return errors.Wrap(err, codes.Inherit, "error in objectstore.from()")
+		}
+	}
+	for _, key := range keys {
+		if err := o.read(ctx, bucket, key, f); err != nil {
+			return errors.Wrapf(err, codes.Inherit, "error in objectstore.from() reading %q", key)
+		}
+	}
+	return nil
+}
+
+func (o *objectIterator) read(ctx context.Context, bucket objectstore.Bucket, key string, f func(flux.Table) error) error {
+	rc, err := bucket.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	r, err := decompress(rc)
+	if err != nil {
+		return err
+	}
+	if o.spec.Format == jsonFormat {
+		return readJSON(ctx, r, o.alloc, f)
+	}
+	return o.readCSV(ctx, r, f)
+}
+
+func (o *objectIterator) readCSV(ctx context.Context, r io.Reader, f func(flux.Table) error) error {
+	decoder := csv.NewMultiResultDecoder(csv.ResultDecoderConfig{
+		NoAnnotations:  o.spec.Mode == rawMode,
+		MaxBufferCount: tablebuffer.GetSize(ctx, tablebuffer.DefaultSize),
+		Allocator:      o.alloc,
+		Context:        ctx,
+	})
+	results, err := decoder.Decode(ioutil.NopCloser(skipBOM(r)))
+	if err != nil {
+		return err
+	}
+	defer results.Release()
+
+	if !results.More() {
+		return results.Err()
+	}
+	if err := results.Next().Tables().Do(f); err != nil {
+		return err
+	}
+	if results.More() {
+		return errors.New(codes.FailedPrecondition, "objectstore.from() can only parse 1 result per object")
+	}
+	return results.Err()
+}
+
+// decompress returns a reader of the decompressed content
+// if the content is compressed with gzip.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "invalid gzip content")
+		}
+		return gr, nil
+	}
+	return br, nil
+}
+
+// skipBOM skips the UTF-8 byte order mark that some applications add to CSV files.
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(3); err == nil && bom[0] == 0xef && bom[1] == 0xbb && bom[2] == 0xbf {
+		_, _ = br.Discard(3)
+	}
+	return br
+}
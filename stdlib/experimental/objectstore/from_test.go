@@ -0,0 +1,188 @@
+package objectstore_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/objectstore"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/mock"
+	"github.com/influxdata/flux/querytest"
+	fobjectstore "github.com/influxdata/flux/stdlib/experimental/objectstore"
+)
+
+// memBucket is a bucket of in-memory objects.
+type memBucket map[string]string
+
+func (b memBucket) Bucket(ctx context.Context, config objectstore.Config) (objectstore.Bucket, error) {
+	return b, nil
+}
+
+func (b memBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	for k := range b {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (b memBucket) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	content, ok := b[key]
+	if !ok {
+		return nil, errors.Newf(codes.NotFound, "object %q not found", key)
+	}
+	return ioutil.NopCloser(strings.NewReader(content)), nil
+}
+
+func gzipped(t *testing.T, s string) string {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(s)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestFrom(t *testing.T) {
+	bucket := memBucket{
+		"data/a.csv":    "#datatype,string,long,string,double\n#group,false,false,true,false\n#default,_result,,,\n,result,table,host,_value\n,,0,a,1\n,,0,a,2\n",
+		"data/b.csv.gz": gzipped(t, "#datatype,string,long,string,double\n#group,false,false,true,false\n#default,_result,,,\n,result,table,host,_value\n,,0,b,3\n"),
+		"raw.csv":       "\xef\xbb\xbfhost,value\na,1\nb,2\n",
+		"rows.json":     "{\"host\": \"a\", \"value\": 1, \"ok\": true}\n{\"host\": \"b\", \"value\": 2.5}\n",
+	}
+
+	tests := []struct {
+		name    string
+		spec    *fobjectstore.FromProcedureSpec
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "prefix",
+			spec: &fobjectstore.FromProcedureSpec{Prefix: "data/", Format: "csv", Mode: "annotations"},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []flux.ColMeta{
+						{Label: "host", Type: flux.TString},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{"a", 1.0},
+						{"a", 2.0},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: []flux.ColMeta{
+						{Label: "host", Type: flux.TString},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{"b", 3.0},
+					},
+				},
+			},
+		},
+		{
+			name: "raw",
+			spec: &fobjectstore.FromProcedureSpec{Key: "raw.csv", Format: "csv", Mode: "raw"},
+			want: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "host", Type: flux.TString},
+						{Label: "value", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{"a", "1"},
+						{"b", "2"},
+					},
+				},
+			},
+		},
+		{
+			name: "json",
+			spec: &fobjectstore.FromProcedureSpec{Key: "rows.json", Format: "json"},
+			want: []*executetest.Table{
+				{
+					ColMeta: []flux.ColMeta{
+						{Label: "host", Type: flux.TString},
+						{Label: "ok", Type: flux.TBool},
+						{Label: "value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{"a", true, 1.0},
+						{"b", nil, 2.5},
+					},
+				},
+			},
+		},
+		{
+			name:    "missing object",
+			spec:    &fobjectstore.FromProcedureSpec{Key: "missing.csv", Format: "csv", Mode: "annotations"},
+			wantErr: errors.New(codes.NotFound, `error in objectstore.from() reading "missing.csv": object "missing.csv" not found`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executetest.RunSourceHelper(t,
+				test.want,
+				test.wantErr,
+				func(id execute.DatasetID) execute.Source {
+					ctx := objectstore.Inject(context.Background(), bucket)
+					a := mock.AdministrationWithContext(ctx)
+					s, err := fobjectstore.CreateSource(test.spec, id, a)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return s
+				},
+			)
+		})
+	}
+}
+
+func TestFrom_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name:    "no key or prefix",
+			Raw:     `import "experimental/objectstore" objectstore.from(bucket: "b")`,
+			WantErr: true,
+		},
+		{
+			Name:    "key and prefix",
+			Raw:     `import "experimental/objectstore" objectstore.from(bucket: "b", key: "a.csv", prefix: "a")`,
+			WantErr: true,
+		},
+		{
+			Name:    "invalid format",
+			Raw:     `import "experimental/objectstore" objectstore.from(bucket: "b", key: "a.xml", format: "xml")`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
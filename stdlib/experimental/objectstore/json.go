@@ -0,0 +1,203 @@
+package objectstore
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/array"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/tablebuffer"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/execute/table"
+	"github.com/influxdata/flux/memory"
+)
+
+// jsonRows decodes a stream of JSON objects, either newline-delimited or in an array.
+type jsonRows struct {
+	dec   *json.Decoder
+	array bool
+}
+
+func newJSONRows(r io.Reader) (*jsonRows, error) {
+	br := bufio.NewReader(r)
+	rows := &jsonRows{}
+	for {
+		c, err := br.ReadByte()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			continue
+		}
+		rows.array = c == '['
+		_ = br.UnreadByte()
+		break
+	}
+	rows.dec = json.NewDecoder(br)
+	if rows.array {
+		// consume the opening bracket so that the elements are decoded one at a time
+		if _, err := rows.dec.Token(); err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "invalid JSON")
+		}
+	}
+	return rows, nil
+}
+
+// next returns the next row, or false at the end of the stream.
+func (j *jsonRows) next() (map[string]interface{}, bool, error) {
+	if j.array && !j.dec.More() {
+		return nil, false, nil
+	}
+	var v interface{}
+	if err := j.dec.Decode(&v); err == io.EOF {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, errors.Wrap(err, codes.Invalid, "invalid JSON")
+	}
+	row, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, false, errors.Newf(codes.Invalid, "expected a JSON object, got %T", v)
+	}
+	return row, true, nil
+}
+
+// readJSON decodes the JSON objects of r to a table, which is streamed
+// in buffers as the objects are decoded. The columns and their types
+// are those of the first object.
+func readJSON(ctx context.Context, r io.Reader, alloc *memory.Allocator, f func(flux.Table) error) error {
+	rows, err := newJSONRows(r)
+	if err != nil {
+		return err
+	}
+	first, ok, err := rows.next()
+	if err != nil || !ok {
+		return err
+	}
+
+	labels := make([]string, 0, len(first))
+	for label := range first {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	cols := make([]flux.ColMeta, len(labels))
+	for i, label := range labels {
+		cols[i] = flux.ColMeta{Label: label, Type: jsonColType(first[label])}
+	}
+
+	bufferSize := tablebuffer.GetSize(ctx, tablebuffer.DefaultSize)
+	tbl, err := table.StreamWithContext(ctx, execute.NewGroupKey(nil, nil), cols, func(ctx context.Context, w *table.StreamWriter) error {
+		builders := make([]array.Builder, len(cols))
+		for i, col := range cols {
+			builders[i] = arrow.NewBuilder(col.Type, alloc)
+		}
+		defer func() {
+			for _, b := range builders {
+				b.Release()
+			}
+		}()
+		flush := func() error {
+			vs := make([]array.Interface, len(builders))
+			for i, b := range builders {
+				// NewArray resets the builder for the next buffer
+				vs[i] = b.NewArray()
+			}
+			return w.Write(vs)
+		}
+
+		row, n := first, 0
+		for {
+			if err := appendJSONRow(cols, builders, row); err != nil {
+				return err
+			}
+			if n++; n == bufferSize {
+				if err := flush(); err != nil {
+					return err
+				}
+				n = 0
+			}
+			next, ok, err := rows.next()
+			if err != nil {
+				return err
+			} else if !ok {
+				break
+			}
+			row = next
+		}
+		return flush()
+	})
+	if err != nil {
+		return err
+	}
+	return f(tbl)
+}
+
+// jsonColType returns the column type of a JSON value.
+// Nested values are encoded to strings.
+func jsonColType(v interface{}) flux.ColType {
+	switch v.(type) {
+	case float64:
+		return flux.TFloat
+	case bool:
+		return flux.TBool
+	default:
+		return flux.TString
+	}
+}
+
+func appendJSONRow(cols []flux.ColMeta, builders []array.Builder, row map[string]interface{}) error {
+	found := 0
+	for i, col := range cols {
+		v, ok := row[col.Label]
+		if ok {
+			found++
+		}
+		if v == nil {
+			builders[i].AppendNull()
+			continue
+		}
+		var err error
+		switch col.Type {
+		case flux.TFloat:
+			f, ok := v.(float64)
+			if !ok {
+				return errors.Newf(codes.Invalid, "expected a number for key %q, got %T", col.Label, v)
+			}
+			err = arrow.AppendFloat(builders[i], f)
+		case flux.TBool:
+			b, ok := v.(bool)
+			if !ok {
+				return errors.Newf(codes.Invalid, "expected a boolean for key %q, got %T", col.Label, v)
+			}
+			err = arrow.AppendBool(builders[i], b)
+		default:
+			s, ok := v.(string)
+			if !ok {
+				data, jerr := json.Marshal(v)
+				if jerr != nil {
+					return errors.Wrapf(jerr, codes.Internal, "cannot encode the value of key %q", col.Label)
+				}
+				s = string(data)
+			}
+			err = arrow.AppendString(builders[i], s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if found < len(row) {
+		for label := range row {
+			if execute.ColIdx(label, cols) < 0 {
+				return errors.Newf(codes.Invalid, "unexpected key %q, the keys must be those of the first JSON object", label)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package experimental
+
+import (
+	"context"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/array"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/execute/table"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/values"
+)
+
+const SessionizeKind = "sessionize"
+
+// DefaultSessionColLabel is the default column of the session ids.
+const DefaultSessionColLabel = "_session"
+
+type SessionizeOpSpec struct {
+	Timeout    flux.Duration `json:"timeout"`
+	Column     string        `json:"column"`
+	TimeColumn string        `json:"timeColumn"`
+}
+
+func init() {
+	sessionizeSignature := runtime.MustLookupBuiltinType("experimental", "sessionize")
+
+	runtime.RegisterPackageValue("experimental", "sessionize", flux.MustValue(flux.FunctionValue(SessionizeKind, createSessionizeOpSpec, sessionizeSignature)))
+	flux.RegisterOpSpec(SessionizeKind, newSessionizeOp)
+	plan.RegisterProcedureSpec(SessionizeKind, newSessionizeProcedure, SessionizeKind)
+	execute.RegisterTransformation(SessionizeKind, createSessionizeTransformation)
+}
+
+func createSessionizeOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := &SessionizeOpSpec{
+		Column:     DefaultSessionColLabel,
+		TimeColumn: execute.DefaultTimeColLabel,
+	}
+
+	timeout, err := args.GetRequiredDuration("timeout")
+	if err != nil {
+		return nil, err
+	} else if !timeout.IsPositive() {
+		return nil, errors.Newf(codes.Invalid, "timeout must be positive, got %v", timeout)
+	}
+	spec.Timeout = timeout
+
+	if col, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = col
+	}
+
+	if col, ok, err := args.GetString("timeColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.TimeColumn = col
+	}
+	return spec, nil
+}
+
+func newSessionizeOp() flux.OperationSpec {
+	return new(SessionizeOpSpec)
+}
+
+func (s *SessionizeOpSpec) Kind() flux.OperationKind {
+	return SessionizeKind
+}
+
+type SessionizeProcedureSpec struct {
+	plan.DefaultCost
+	Timeout    flux.Duration
+	Column     string
+	TimeColumn string
+}
+
+func newSessionizeProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*SessionizeOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &SessionizeProcedureSpec{
+		Timeout:    spec.Timeout,
+		Column:     spec.Column,
+		TimeColumn: spec.TimeColumn,
+	}, nil
+}
+
+func (s *SessionizeProcedureSpec) Kind() plan.ProcedureKind {
+	return SessionizeKind
+}
+
+func (s *SessionizeProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *SessionizeProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createSessionizeTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*SessionizeProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	t, d := NewSessionizeTransformation(a.Context(), s, id, a.Allocator())
+	return t, d, nil
+}
+
+type sessionizeTransformation struct {
+	execute.ExecutionNode
+	d     *execute.PassthroughDataset
+	ctx   context.Context
+	spec  *SessionizeProcedureSpec
+	alloc *memory.Allocator
+}
+
+func NewSessionizeTransformation(ctx context.Context, spec *SessionizeProcedureSpec, id execute.DatasetID, alloc *memory.Allocator) (execute.Transformation, execute.Dataset) {
+	t := &sessionizeTransformation{
+		d:     execute.NewPassthroughDataset(id),
+		ctx:   ctx,
+		spec:  spec,
+		alloc: alloc,
+	}
+	return t, t.d
+}
+
+func (t *sessionizeTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *sessionizeTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	timeIdx := execute.ColIdx(t.spec.TimeColumn, tbl.Cols())
+	if timeIdx < 0 {
+		return errors.Newf(codes.FailedPrecondition, "column %q does not exist", t.spec.TimeColumn)
+	} else if typ := tbl.Cols()[timeIdx].Type; typ != flux.TTime {
+		return errors.Newf(codes.FailedPrecondition, "column %q has type %v; expected time", t.spec.TimeColumn, typ)
+	}
+	if execute.ColIdx(t.spec.Column, tbl.Cols()) >= 0 {
+		return errors.Newf(codes.FailedPrecondition, "session column %q already exists", t.spec.Column)
+	}
+
+	cols := make([]flux.ColMeta, len(tbl.Cols()), len(tbl.Cols())+1)
+	copy(cols, tbl.Cols())
+	cols = append(cols, flux.ColMeta{Label: t.spec.Column, Type: flux.TInt})
+
+	var (
+		session int64
+		last    values.Time
+	)
+	out, err := table.StreamWithContext(t.ctx, tbl.Key(), cols, func(ctx context.Context, w *table.StreamWriter) error {
+		return tbl.Do(func(cr flux.ColReader) error {
+			if cr.Len() == 0 {
+				return nil
+			}
+			times := cr.Times(timeIdx)
+			b := array.NewIntBuilder(t.alloc)
+			b.Resize(cr.Len())
+			for i := 0; i < cr.Len(); i++ {
+				if times.IsNull(i) {
+					b.Release()
+					return errors.Newf(codes.FailedPrecondition, "null value in column %q", t.spec.TimeColumn)
+				}
+				ts := values.Time(times.Value(i))
+				// A session ends when the next row is more than timeout after the last one.
+				if session == 0 || last.Add(t.spec.Timeout) < ts {
+					session++
+				}
+				last = ts
+				b.Append(session)
+			}
+
+			vs := make([]array.Interface, len(cols))
+			for j := range cr.Cols() {
+				vs[j] = table.Values(cr, j)
+				vs[j].Retain()
+			}
+			vs[len(vs)-1] = b.NewArray()
+			return w.Write(vs)
+		})
+	})
+	if err != nil {
+		return err
+	}
+	return t.d.Process(out)
+}
+
+func (t *sessionizeTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *sessionizeTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *sessionizeTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
@@ -0,0 +1,130 @@
+package experimental_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/stdlib/experimental"
+)
+
+func TestSessionize_Process(t *testing.T) {
+	spec := &experimental.SessionizeProcedureSpec{
+		Timeout:    flux.ConvertDuration(10),
+		Column:     experimental.DefaultSessionColLabel,
+		TimeColumn: "_time",
+	}
+	testCases := []struct {
+		name    string
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "sessions per table",
+			data: []flux.Table{
+				&executetest.Table{
+					KeyCols: []string{"user"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "user", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(0), "a"},
+						{execute.Time(5), "a"},
+						{execute.Time(15), "a"},
+						{execute.Time(26), "a"},
+						{execute.Time(27), "a"},
+						{execute.Time(100), "a"},
+					},
+				},
+				&executetest.Table{
+					KeyCols: []string{"user"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "user", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{execute.Time(50), "b"},
+						{execute.Time(61), "b"},
+					},
+				},
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"user"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "user", Type: flux.TString},
+						{Label: "_session", Type: flux.TInt},
+					},
+					Data: [][]interface{}{
+						{execute.Time(0), "a", int64(1)},
+						{execute.Time(5), "a", int64(1)},
+						{execute.Time(15), "a", int64(1)},
+						{execute.Time(26), "a", int64(2)},
+						{execute.Time(27), "a", int64(2)},
+						{execute.Time(100), "a", int64(3)},
+					},
+				},
+				{
+					KeyCols: []string{"user"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_time", Type: flux.TTime},
+						{Label: "user", Type: flux.TString},
+						{Label: "_session", Type: flux.TInt},
+					},
+					Data: [][]interface{}{
+						{execute.Time(50), "b", int64(1)},
+						{execute.Time(61), "b", int64(2)},
+					},
+				},
+			},
+		},
+		{
+			name: "null time",
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0)},
+					{nil},
+				},
+			}},
+			wantErr: errors.New(codes.FailedPrecondition, `null value in column "_time"`),
+		},
+		{
+			name: "existing session column",
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_session", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), int64(1)},
+				},
+			}},
+			wantErr: errors.New(codes.FailedPrecondition, `session column "_session" already exists`),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper2(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(id execute.DatasetID, alloc *memory.Allocator) (execute.Transformation, execute.Dataset) {
+					return experimental.NewSessionizeTransformation(context.Background(), spec, id, alloc)
+				},
+			)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package experimental
+
+import (
+	"fmt"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const UnpivotKind = "unpivotExperimental"
+
+type UnpivotOpSpec struct {
+	OtherColumns []string `json:"otherColumns"`
+}
+
+func init() {
+	unpivotSignature := runtime.MustLookupBuiltinType("experimental", "unpivot")
+
+	runtime.RegisterPackageValue("experimental", "unpivot", flux.MustValue(flux.FunctionValue(UnpivotKind, createUnpivotOpSpec, unpivotSignature)))
+	flux.RegisterOpSpec(UnpivotKind, newUnpivotOp)
+	plan.RegisterProcedureSpec(UnpivotKind, newUnpivotProcedure, UnpivotKind)
+	execute.RegisterTransformation(UnpivotKind, createUnpivotTransformation)
+}
+
+func createUnpivotOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := &UnpivotOpSpec{
+		OtherColumns: []string{execute.DefaultTimeColLabel},
+	}
+	if array, ok, err := args.GetArrayAllowEmpty("otherColumns", semantic.String); err != nil {
+		return nil, err
+	} else if ok {
+		spec.OtherColumns, err = interpreter.ToStringArray(array)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return spec, nil
+}
+
+func newUnpivotOp() flux.OperationSpec {
+	return new(UnpivotOpSpec)
+}
+
+func (s *UnpivotOpSpec) Kind() flux.OperationKind {
+	return UnpivotKind
+}
+
+type UnpivotProcedureSpec struct {
+	plan.DefaultCost
+	OtherColumns []string
+}
+
+func newUnpivotProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	s, ok := qs.(*UnpivotOpSpec)
+	if !ok {
+		return nil, fmt.Errorf("invalid spec type %T", qs)
+	}
+	p := &UnpivotProcedureSpec{
+		OtherColumns: s.OtherColumns,
+	}
+	return p, nil
+}
+
+func (s *UnpivotProcedureSpec) Kind() plan.ProcedureKind {
+	return UnpivotKind
+}
+func (s *UnpivotProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(UnpivotProcedureSpec)
+	ns.OtherColumns = make([]string, len(s.OtherColumns))
+	copy(ns.OtherColumns, s.OtherColumns)
+	return ns
+}
+
+func createUnpivotTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*UnpivotProcedureSpec)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewUnpivotTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type unpivotTransformation struct {
+	execute.ExecutionNode
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	otherColumns []string
+}
+
+func NewUnpivotTransformation(
+	d execute.Dataset,
+	cache execute.TableBuilderCache,
+	spec *UnpivotProcedureSpec,
+) execute.Transformation {
+	return &unpivotTransformation{
+		d:            d,
+		cache:        cache,
+		otherColumns: spec.OtherColumns,
+	}
+}
+
+func (t *unpivotTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+// unpivotColumn is a column of the input table that becomes
+// the _value column of an output table.
+type unpivotColumn struct {
+	idx     int
+	builder execute.TableBuilder
+	// colMap maps the builder columns to the input columns.
+	colMap []int
+}
+
+func (t *unpivotTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	key := tbl.Key()
+	for _, label := range []string{"_field", execute.DefaultValueColLabel} {
+		if key.HasCol(label) || (execute.ContainsStr(t.otherColumns, label) && execute.HasCol(label, tbl.Cols())) {
+			return errors.Newf(codes.Invalid, "unpivot cannot create the %s column, it is already in the group key or in otherColumns", label)
+		}
+	}
+
+	var other []flux.ColMeta
+	columns := make([]*unpivotColumn, 0, len(tbl.Cols()))
+	for j, c := range tbl.Cols() {
+		if key.HasCol(c.Label) {
+			continue
+		}
+		if execute.ContainsStr(t.otherColumns, c.Label) {
+			other = append(other, c)
+			continue
+		}
+		columns = append(columns, &unpivotColumn{idx: j})
+	}
+
+	for _, col := range columns {
+		c := tbl.Cols()[col.idx]
+		outKey, err := execute.NewGroupKeyBuilder(key).
+			AddKeyValue("_field", values.NewString(c.Label)).
+			Build()
+		if err != nil {
+			return err
+		}
+		builder, created := t.cache.TableBuilder(outKey)
+		if created {
+			if err := execute.AddTableKeyCols(outKey, builder); err != nil {
+				return err
+			}
+			for _, o := range other {
+				if _, err := builder.AddCol(o); err != nil {
+					return err
+				}
+			}
+			if _, err := builder.AddCol(flux.ColMeta{
+				Label: execute.DefaultValueColLabel,
+				Type:  c.Type,
+			}); err != nil {
+				return err
+			}
+		}
+		cols := builder.Cols()
+		if typ := cols[len(cols)-1].Type; typ != c.Type {
+			return errors.Newf(codes.Invalid, "unpivot found column %q with type %v, but the other values of the field have type %v", c.Label, c.Type, typ)
+		}
+		for _, o := range other {
+			if !execute.HasCol(o.Label, cols) {
+				return errors.Newf(codes.Invalid, "unpivot found column %q in a table of field %q that did not have it before", o.Label, c.Label)
+			}
+		}
+		col.builder = builder
+		col.colMap = execute.ColMap(nil, builder, tbl.Cols())
+	}
+
+	return tbl.Do(func(cr flux.ColReader) error {
+		for _, col := range columns {
+			cols := col.builder.Cols()
+			for i, l := 0, cr.Len(); i < l; i++ {
+				v := execute.ValueForRow(cr, i, col.idx)
+				if v.IsNull() {
+					continue
+				}
+				for j, c := range cols {
+					var cv values.Value
+					switch {
+					case j == len(cols)-1:
+						cv = v
+					case col.builder.Key().HasCol(c.Label):
+						cv = col.builder.Key().LabelValue(c.Label)
+					case col.colMap[j] >= 0:
+						cv = execute.ValueForRow(cr, i, col.colMap[j])
+					default:
+						cv = values.NewNull(flux.SemanticType(c.Type))
+					}
+					if err := col.builder.AppendValue(j, cv); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func (t *unpivotTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *unpivotTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *unpivotTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
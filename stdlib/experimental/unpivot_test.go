@@ -0,0 +1,136 @@
+package experimental_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/stdlib/experimental"
+)
+
+func TestUnpivot_Process(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *experimental.UnpivotProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "pivoted fields",
+			spec: &experimental.UnpivotProcedureSpec{
+				OtherColumns: []string{"_time"},
+			},
+			data: []flux.Table{&executetest.Table{
+				KeyCols: []string{"_measurement"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_measurement", Type: flux.TString},
+					{Label: "_time", Type: flux.TTime},
+					{Label: "usage", Type: flux.TFloat},
+					{Label: "count", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{"cpu", execute.Time(1), 2.0, int64(4)},
+					{"cpu", execute.Time(2), nil, int64(5)},
+				},
+			}},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"_field", "_measurement"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_field", Type: flux.TString},
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TFloat},
+					},
+					Data: [][]interface{}{
+						{"usage", "cpu", execute.Time(1), 2.0},
+					},
+				},
+				{
+					KeyCols: []string{"_field", "_measurement"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_field", Type: flux.TString},
+						{Label: "_measurement", Type: flux.TString},
+						{Label: "_time", Type: flux.TTime},
+						{Label: "_value", Type: flux.TInt},
+					},
+					Data: [][]interface{}{
+						{"count", "cpu", execute.Time(1), int64(4)},
+						{"count", "cpu", execute.Time(2), int64(5)},
+					},
+				},
+			},
+		},
+		{
+			name: "no other columns",
+			spec: &experimental.UnpivotProcedureSpec{},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "a", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), "x"},
+				},
+			}},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"_field"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_field", Type: flux.TString},
+						{Label: "_value", Type: flux.TTime},
+					},
+					Data: [][]interface{}{
+						{"_time", execute.Time(1)},
+					},
+				},
+				{
+					KeyCols: []string{"_field"},
+					ColMeta: []flux.ColMeta{
+						{Label: "_field", Type: flux.TString},
+						{Label: "_value", Type: flux.TString},
+					},
+					Data: [][]interface{}{
+						{"a", "x"},
+					},
+				},
+			},
+		},
+		{
+			name: "field in group key",
+			spec: &experimental.UnpivotProcedureSpec{
+				OtherColumns: []string{"_time"},
+			},
+			data: []flux.Table{&executetest.Table{
+				KeyCols: []string{"_field"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_field", Type: flux.TString},
+					{Label: "_time", Type: flux.TTime},
+					{Label: "a", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{"f", execute.Time(1), 1.0},
+				},
+			}},
+			wantErr: errors.New(codes.Invalid, "unpivot cannot create the _field column, it is already in the group key or in otherColumns"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return experimental.NewUnpivotTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
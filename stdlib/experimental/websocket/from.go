@@ -0,0 +1,493 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	fluxhttp "github.com/influxdata/flux/dependencies/http"
+	depsurl "github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	pkgpath  = "experimental/websocket"
+	FromKind = pkgpath + ".from"
+)
+
+const (
+	defaultEvery   = 10 * time.Second
+	defaultTimeout = 30 * time.Second
+	// maxMessageSize is the size of the largest message that is read.
+	maxMessageSize = 10 << 20
+)
+
+type FromOpSpec struct {
+	URL        string            `json:"url"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	TimeColumn string            `json:"timeColumn,omitempty"`
+	Every      flux.Duration     `json:"every"`
+	Stop       flux.Time         `json:"stop"`
+	Limit      int64             `json:"limit,omitempty"`
+	Timeout    flux.Duration     `json:"timeout"`
+}
+
+func init() {
+	fromSignature := runtime.MustLookupBuiltinType(pkgpath, "from")
+	runtime.RegisterPackageValue(pkgpath, "from", flux.MustValue(flux.FunctionValue(FromKind, createFromOpSpec, fromSignature)))
+	flux.RegisterOpSpec(FromKind, newFromOp)
+	plan.RegisterProcedureSpec(FromKind, newFromProcedure, FromKind)
+	execute.RegisterSource(FromKind, createFromSource)
+}
+
+func createFromOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	spec := &FromOpSpec{
+		Every:   flux.ConvertDuration(defaultEvery),
+		Timeout: flux.ConvertDuration(defaultTimeout),
+	}
+	var err error
+	if spec.URL, err = args.GetRequiredString("url"); err != nil {
+		return nil, err
+	}
+	if headers, ok, err := args.GetObject("headers"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Headers = make(map[string]string)
+		var rangeErr error
+		headers.Range(func(k string, v values.Value) {
+			if v.Type().Nature() == semantic.String {
+				spec.Headers[k] = v.Str()
+			} else {
+				rangeErr = errors.Newf(codes.Invalid, "header value %q must be a string", k)
+			}
+		})
+		if rangeErr != nil {
+			return nil, rangeErr
+		}
+	}
+	if spec.Message, _, err = args.GetString("message"); err != nil {
+		return nil, err
+	}
+	if spec.TimeColumn, _, err = args.GetString("timeColumn"); err != nil {
+		return nil, err
+	}
+	if every, ok, err := args.GetDuration("every"); err != nil {
+		return nil, err
+	} else if ok {
+		if every.Duration() <= 0 {
+			return nil, errors.New(codes.Invalid, "every must be a positive duration")
+		}
+		spec.Every = every
+	}
+	if timeout, ok, err := args.GetDuration("timeout"); err != nil {
+		return nil, err
+	} else if ok {
+		if timeout.Duration() <= 0 {
+			return nil, errors.New(codes.Invalid, "timeout must be a positive duration")
+		}
+		spec.Timeout = timeout
+	}
+	if spec.Stop, _, err = args.GetTime("stop"); err != nil {
+		return nil, err
+	}
+	if limit, ok, err := args.GetInt("limit"); err != nil {
+		return nil, err
+	} else if ok {
+		if limit <= 0 {
+			return nil, errors.Newf(codes.Invalid, "limit must be positive, got %d", limit)
+		}
+		spec.Limit = limit
+	}
+	if spec.Stop.IsZero() && spec.Limit == 0 {
+		return nil, errors.New(codes.Invalid, "stop or limit is required to end the stream")
+	}
+	return spec, nil
+}
+
+func newFromOp() flux.OperationSpec {
+	return new(FromOpSpec)
+}
+
+func (s *FromOpSpec) Kind() flux.OperationKind {
+	return FromKind
+}
+
+type FromProcedureSpec struct {
+	plan.DefaultCost
+	URL        string
+	Headers    map[string]string
+	Message    string
+	TimeColumn string
+	Every      time.Duration
+	Stop       time.Time
+	Limit      int64
+	Timeout    time.Duration
+}
+
+func newFromProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*FromOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	ps := &FromProcedureSpec{
+		URL:        spec.URL,
+		Headers:    spec.Headers,
+		Message:    spec.Message,
+		TimeColumn: spec.TimeColumn,
+		Every:      spec.Every.Duration(),
+		Limit:      spec.Limit,
+		Timeout:    spec.Timeout.Duration(),
+	}
+	if !spec.Stop.IsZero() {
+		ps.Stop = spec.Stop.Time(pa.Now())
+	}
+	return ps, nil
+}
+
+func (s *FromProcedureSpec) Kind() plan.ProcedureKind {
+	return FromKind
+}
+
+func (s *FromProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	if s.Headers != nil {
+		ns.Headers = make(map[string]string, len(s.Headers))
+		for k, v := range s.Headers {
+			ns.Headers[k] = v
+		}
+	}
+	return &ns
+}
+
+func createFromSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	spec, ok := prSpec.(*FromProcedureSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", prSpec)
+	}
+	return CreateSource(spec, dsid, a)
+}
+
+func CreateSource(spec *FromProcedureSpec, dsid execute.DatasetID, a execute.Administration) (execute.Source, error) {
+	u, err := url.Parse(spec.URL)
+	if err != nil {
+		return nil, errors.Newf(codes.Invalid, "invalid websocket url: %v", err)
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, errors.Newf(codes.Invalid, "invalid websocket url %q, the scheme must be ws or wss", spec.URL)
+	}
+	validator, err := flux.GetDependencies(a.Context()).URLValidator()
+	if err != nil {
+		return nil, err
+	}
+	// the websocket is opened with an HTTP request.
+	validator = depsurl.ForPackage(validator, depsurl.HTTPPackage)
+	if err := validator.Validate(u); err != nil {
+		return nil, errors.Newf(codes.Invalid, "websocket url did not pass validation: %v", err)
+	}
+	return execute.CreateSourceFromIterator(&streamIterator{
+		spec:   spec,
+		alloc:  a.Allocator(),
+		dialer: fluxhttp.NewDialer(validator),
+	}, dsid)
+}
+
+var _ execute.SourceIterator = (*streamIterator)(nil)
+
+// streamIterator reads the messages of a websocket
+// and emits a table with the rows of the messages received in each period.
+type streamIterator struct {
+	spec  *FromProcedureSpec
+	alloc *memory.Allocator
+	// dialer validates the IPs that the websocket connects to.
+	dialer *net.Dialer
+}
+
+func (s *streamIterator) dial(ctx context.Context) (*websocket.Conn, error) {
+	header := make(http.Header, len(s.spec.Headers))
+	for k, v := range s.spec.Headers {
+		header.Set(k, v)
+	}
+	dialer := &websocket.Dialer{
+		Proxy: http.ProxyFromEnvironment,
+		// the bytes of the connection are taken from
+		// the quota of the HTTP requests of the query.
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := s.dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return fluxhttp.QuotaConn(ctx, conn), nil
+		},
+		HandshakeTimeout: s.spec.Timeout,
+	}
+	conn, resp, err := dialer.DialContext(ctx, s.spec.URL, header)
+	if err != nil {
+		if resp != nil {
+			return nil, errors.Newf(codes.Unavailable, "websocket handshake failed: %s", resp.Status)
+		}
+		return nil, wrapConnError(err, "failed to connect to the websocket")
+	}
+	conn.SetReadLimit(maxMessageSize)
+	return conn, nil
+}
+
+func (s *streamIterator) Do(ctx context.Context, f func(flux.Table) error) error {
+	if !s.spec.Stop.IsZero() && !time.Now().Before(s.spec.Stop) {
+		return nil
+	}
+	streamCtx := ctx
+	if !s.spec.Stop.IsZero() {
+		var cancel context.CancelFunc
+		streamCtx, cancel = context.WithDeadline(ctx, s.spec.Stop)
+		defer cancel()
+	}
+
+	conn, err := s.dial(streamCtx)
+	if err != nil {
+		return errors.Wrap(err, codes.Inherit, "error in websocket.from()")
+	}
+	defer func() { _ = conn.Close() }()
+	if s.spec.Message != "" {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(s.spec.Message)); err != nil {
+			return errors.Wrap(err, codes.Unavailable, "error in websocket.from() sending the message")
+		}
+	}
+
+	// the messages are read until the connection is closed
+	msgs := make(chan []byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				readErr <- err
+				return
+			}
+			select {
+			case msgs <- data:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(s.spec.Every)
+	defer ticker.Stop()
+	b := newBatch(time.Now())
+	var n int64
+	for {
+		select {
+		case data := <-msgs:
+			if err := b.appendMessage(data, time.Now(), s.spec.TimeColumn); err != nil {
+				return errors.Wrap(err, codes.Inherit, "error in websocket.from()")
+			}
+			if n++; s.spec.Limit > 0 && n >= s.spec.Limit {
+				s.close(conn)
+				return s.flush(b, time.Now(), f)
+			}
+		case now := <-ticker.C:
+			if err := s.flush(b, now, f); err != nil {
+				return err
+			}
+			b = newBatch(now)
+		case err := <-readErr:
+			if err := s.flush(b, time.Now(), f); err != nil {
+				return err
+			}
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			} else if err == websocket.ErrReadLimit {
+				return errors.Newf(codes.ResourceExhausted, "error in websocket.from(): message is larger than the limit of %d bytes", maxMessageSize)
+			}
+			return wrapConnError(err, "error in websocket.from() reading the messages")
+		case <-streamCtx.Done():
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			// the stop time is reached
+			s.close(conn)
+			return s.flush(b, s.spec.Stop, f)
+		}
+	}
+}
+
+// wrapConnError wraps an error of the connection. The websocket is
+// unavailable unless the error has a code, such as when the
+// quota of the query is exhausted.
+func wrapConnError(err error, msg string) error {
+	code := codes.Unavailable
+	if errors.Code(err) != codes.Unknown {
+		code = codes.Inherit
+	}
+	return errors.Wrap(err, code, msg)
+}
+
+// close closes the websocket connection cleanly.
+func (s *streamIterator) close(conn *websocket.Conn) {
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+}
+
+// flush emits the table of a batch if it has rows.
+func (s *streamIterator) flush(b *batch, stop time.Time, f func(flux.Table) error) error {
+	if len(b.rows) == 0 {
+		return nil
+	}
+	tbl, err := b.table(stop, s.alloc)
+	if err != nil {
+		return err
+	}
+	return f(tbl)
+}
+
+// batch contains the rows of the messages received in a period.
+// The properties of the messages are columns that are added as they appear,
+// with the type of their first value.
+type batch struct {
+	start time.Time
+	cols  []flux.ColMeta
+	rows  []map[string]values.Value
+}
+
+func newBatch(start time.Time) *batch {
+	return &batch{
+		start: start,
+		cols: []flux.ColMeta{
+			{Label: execute.DefaultStartColLabel, Type: flux.TTime},
+			{Label: execute.DefaultStopColLabel, Type: flux.TTime},
+			{Label: execute.DefaultTimeColLabel, Type: flux.TTime},
+		},
+	}
+}
+
+// appendMessage appends the rows of a message, which is a JSON object or an array of JSON objects.
+func (b *batch) appendMessage(data []byte, received time.Time, timeColumn string) error {
+	var objs []map[string]json.RawMessage
+	if err := json.Unmarshal(data, &objs); err != nil {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return errors.Wrap(err, codes.Invalid, "the message is not a JSON object or an array of JSON objects")
+		}
+		objs = append(objs, obj)
+	}
+	for _, obj := range objs {
+		if err := b.appendRow(obj, received, timeColumn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *batch) appendRow(obj map[string]json.RawMessage, received time.Time, timeColumn string) error {
+	row := make(map[string]values.Value, len(obj)+1)
+	row[execute.DefaultTimeColLabel] = values.NewTime(values.ConvertTime(received))
+	if timeColumn != "" {
+		var ts string
+		if err := json.Unmarshal(obj[timeColumn], &ts); err != nil {
+			return errors.Newf(codes.Invalid, "the time property %q must be an RFC3339 string", timeColumn)
+		}
+		t, err := time.Parse(time.RFC3339Nano, ts)
+		if err != nil {
+			return errors.Wrapf(err, codes.Invalid, "invalid time property %q", timeColumn)
+		}
+		row[execute.DefaultTimeColLabel] = values.NewTime(values.ConvertTime(t))
+		delete(obj, timeColumn)
+	}
+
+	labels := make([]string, 0, len(obj))
+	for label := range obj {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		switch label {
+		case execute.DefaultStartColLabel, execute.DefaultStopColLabel, execute.DefaultTimeColLabel:
+			return errors.Newf(codes.Invalid, "property %q conflicts with a column of the tables", label)
+		}
+		v, err := jsonValue(obj[label])
+		if err != nil {
+			return errors.Wrapf(err, codes.Inherit, "invalid property %q", label)
+		}
+		if v == nil {
+			continue
+		}
+		typ := flux.ColumnType(v.Type())
+		if j := execute.ColIdx(label, b.cols); j < 0 {
+			b.cols = append(b.cols, flux.ColMeta{Label: label, Type: typ})
+		} else if b.cols[j].Type != typ {
+			return errors.Newf(codes.Invalid, "property %q is a %s, expected a %s", label, typ, b.cols[j].Type)
+		}
+		row[label] = v
+	}
+	b.rows = append(b.rows, row)
+	return nil
+}
+
+// table returns the table of the batch, grouped by the start and stop of the period.
+func (b *batch) table(stop time.Time, alloc *memory.Allocator) (flux.Table, error) {
+	start, end := values.NewTime(values.ConvertTime(b.start)), values.NewTime(values.ConvertTime(stop))
+	key := execute.NewGroupKey(
+		[]flux.ColMeta{b.cols[0], b.cols[1]},
+		[]values.Value{start, end},
+	)
+	builder := execute.NewColListTableBuilder(key, alloc)
+	for _, col := range b.cols {
+		if _, err := builder.AddCol(col); err != nil {
+			return nil, err
+		}
+	}
+	for _, row := range b.rows {
+		row[execute.DefaultStartColLabel] = start
+		row[execute.DefaultStopColLabel] = end
+		for j, col := range b.cols {
+			v, ok := row[col.Label]
+			if !ok {
+				if err := builder.AppendNil(j); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if err := builder.AppendValue(j, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return builder.Table()
+}
+
+// jsonValue converts a JSON value to a flux value, null values are nil.
+// Numbers are floats and nested values are JSON encoded strings.
+func jsonValue(data json.RawMessage) (values.Value, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "invalid JSON")
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		return values.NewFloat(v), nil
+	case string:
+		return values.NewString(v), nil
+	case bool:
+		return values.NewBool(v), nil
+	default:
+		return values.NewString(string(data)), nil
+	}
+}
@@ -0,0 +1,239 @@
+package websocket_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/gorilla/websocket"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	fluxhttp "github.com/influxdata/flux/dependencies/http"
+	depsurl "github.com/influxdata/flux/dependencies/url"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/mock"
+	"github.com/influxdata/flux/querytest"
+	fwebsocket "github.com/influxdata/flux/stdlib/experimental/websocket"
+)
+
+// newTestServer returns a websocket server that waits for the subscription
+// and sends the messages.
+func newTestServer(t *testing.T, messages ...string) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Token secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != "subscribe" {
+			t.Errorf("unexpected subscription %q: %v", msg, err)
+			return
+		}
+		for _, msg := range messages {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(msg)); err != nil {
+				return
+			}
+		}
+		// wait for the client to close the connection
+		_, _, _ = conn.ReadMessage()
+	}))
+}
+
+func TestFrom(t *testing.T) {
+	ts := newTestServer(t,
+		`{"time": "2021-01-01T00:00:00Z", "symbol": "a", "price": 1}`,
+		`[{"time": "2021-01-01T00:00:01Z", "symbol": "b", "price": 2.5, "extra": {"x": true}}, {"time": "2021-01-01T00:00:02Z", "symbol": "c"}]`,
+		`{"time": "2021-01-01T00:00:03Z", "symbol": "d", "price": 4}`,
+	)
+	defer ts.Close()
+
+	spec := &fwebsocket.FromProcedureSpec{
+		URL:        "ws" + strings.TrimPrefix(ts.URL, "http"),
+		Headers:    map[string]string{"Authorization": "Token secret"},
+		Message:    "subscribe",
+		TimeColumn: "time",
+		Every:      time.Hour,
+		Limit:      2,
+		Timeout:    time.Second,
+	}
+	store := executetest.NewDataStore()
+	ctx := dependenciestest.Default().Inject(context.Background())
+	s, err := fwebsocket.CreateSource(spec, executetest.RandomDatasetID(), mock.AdministrationWithContext(ctx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddTransformation(store)
+	s.Run(context.Background())
+	if err := store.Err(); err != nil {
+		t.Fatal(err)
+	}
+	got, err := executetest.TablesFromCache(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected one table, got %d", len(got))
+	}
+	// the bounds of the batch are the times at which it was read, drop them
+	tbl := got[0]
+	if want := []string{"_start", "_stop"}; !cmp.Equal(want, tbl.KeyCols) {
+		t.Errorf("unexpected group key -want/+got:\n%s", cmp.Diff(want, tbl.KeyCols))
+	}
+	tbl.ColMeta = tbl.ColMeta[2:]
+	for i := range tbl.Data {
+		tbl.Data[i] = tbl.Data[i][2:]
+	}
+
+	want := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "price", Type: flux.TFloat},
+		{Label: "symbol", Type: flux.TString},
+		{Label: "extra", Type: flux.TString},
+	}
+	if !cmp.Equal(want, tbl.ColMeta) {
+		t.Errorf("unexpected columns -want/+got:\n%s", cmp.Diff(want, tbl.ColMeta))
+	}
+	sec := func(n int64) execute.Time {
+		return execute.Time(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC).UnixNano() + n*int64(time.Second))
+	}
+	wantData := [][]interface{}{
+		{sec(0), 1.0, "a", nil},
+		{sec(1), 2.5, "b", `{"x": true}`},
+		{sec(2), nil, "c", nil},
+	}
+	if !cmp.Equal(wantData, tbl.Data) {
+		t.Errorf("unexpected rows -want/+got:\n%s", cmp.Diff(wantData, tbl.Data))
+	}
+}
+
+func TestFrom_Unauthorized(t *testing.T) {
+	ts := newTestServer(t)
+	defer ts.Close()
+
+	executetest.RunSourceHelper(t,
+		nil,
+		errors.New(codes.Unavailable, "error in websocket.from(): websocket handshake failed: 401 Unauthorized"),
+		func(id execute.DatasetID) execute.Source {
+			ctx := dependenciestest.Default().Inject(context.Background())
+			s, err := fwebsocket.CreateSource(&fwebsocket.FromProcedureSpec{
+				URL:     "ws" + strings.TrimPrefix(ts.URL, "http"),
+				Every:   time.Hour,
+				Limit:   1,
+				Timeout: time.Second,
+			}, id, mock.AdministrationWithContext(ctx))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return s
+		},
+	)
+}
+
+// denyIPValidator allows every url but denies every IP
+// so only the IPs of the connections are denied.
+type denyIPValidator struct{}
+
+func (denyIPValidator) Validate(*url.URL) error { return nil }
+
+func (denyIPValidator) ValidateIP(ip net.IP) error {
+	return errors.Newf(codes.Invalid, "ip %s is denied", ip)
+}
+
+func TestFrom_Limits(t *testing.T) {
+	ts := newTestServer(t, strings.Repeat(`{"symbol": "a"}`, 100))
+	defer ts.Close()
+
+	for _, tt := range []struct {
+		name      string
+		validator depsurl.Validator
+		maxBytes  int64
+		wantCode  codes.Code
+		wantErr   string
+	}{
+		{
+			name:      "denied ip",
+			validator: denyIPValidator{},
+			wantCode:  codes.Unavailable,
+			wantErr:   "is denied",
+		},
+		{
+			name:      "query quota",
+			validator: depsurl.PassValidator{},
+			maxBytes:  1000,
+			wantCode:  codes.ResourceExhausted,
+			wantErr:   "more than the limit of 1000 bytes",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := dependenciestest.Default()
+			deps.Deps.URLValidator = tt.validator
+			ctx := deps.Inject(context.Background())
+			s, err := fwebsocket.CreateSource(&fwebsocket.FromProcedureSpec{
+				URL:     "ws" + strings.TrimPrefix(ts.URL, "http"),
+				Headers: map[string]string{"Authorization": "Token secret"},
+				Message: "subscribe",
+				Every:   time.Hour,
+				Limit:   1,
+				Timeout: time.Second,
+			}, executetest.RandomDatasetID(), mock.AdministrationWithContext(ctx))
+			if err != nil {
+				t.Fatal(err)
+			}
+			store := executetest.NewDataStore()
+			s.AddTransformation(store)
+			runCtx := fluxhttp.WithQueryQuota(fluxhttp.InjectMaxQueryBytes(context.Background(), tt.maxBytes))
+			s.Run(runCtx)
+
+			err = store.Err()
+			if got := errors.Code(err); got != tt.wantCode {
+				t.Fatalf("unexpected error code -want/+got:\n\t- %v\n\t+ %v (%v)", tt.wantCode, got, err)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error to contain %q, got %q", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestFrom_NewQuery(t *testing.T) {
+	tests := []querytest.NewQueryTestCase{
+		{
+			Name:    "no stop or limit",
+			Raw:     `import "experimental/websocket" websocket.from(url: "ws://localhost:8080")`,
+			WantErr: true,
+		},
+		{
+			Name:    "invalid every",
+			Raw:     `import "experimental/websocket" websocket.from(url: "ws://localhost:8080", limit: 1, every: 0s)`,
+			WantErr: true,
+		},
+		{
+			Name:    "invalid header",
+			Raw:     `import "experimental/websocket" websocket.from(url: "ws://localhost:8080", limit: 1, headers: {x: 1})`,
+			WantErr: true,
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			querytest.NewQueryTestHelper(t, tc)
+		})
+	}
+}
@@ -0,0 +1,464 @@
+package xml
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	pkgpath = "experimental/xml"
+
+	// DefaultAttributePrefix is the prefix of the record properties of attributes.
+	DefaultAttributePrefix = "@"
+	// TextKey is the record property of the text of elements that also
+	// have attributes or child elements.
+	TextKey = "#text"
+)
+
+func init() {
+	runtime.RegisterPackageValue(pkgpath, "parse", values.NewFunction(
+		"parse",
+		runtime.MustLookupBuiltinType(pkgpath, "parse"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			arguments := interpreter.NewArguments(args)
+			data, err := getData(arguments)
+			if err != nil {
+				return nil, err
+			}
+			prefix, ok, err := arguments.GetString("attributePrefix")
+			if err != nil {
+				return nil, err
+			} else if !ok {
+				prefix = DefaultAttributePrefix
+			}
+			root, err := decode(data)
+			if err != nil {
+				return nil, err
+			}
+			v, err := root.toValue(prefix)
+			if err != nil {
+				return nil, err
+			}
+			return values.NewObjectWithValues(map[string]values.Value{root.name: v}), nil
+		},
+		false,
+	))
+	runtime.RegisterPackageValue(pkgpath, "query", values.NewFunction(
+		"query",
+		runtime.MustLookupBuiltinType(pkgpath, "query"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			arguments := interpreter.NewArguments(args)
+			data, err := getData(arguments)
+			if err != nil {
+				return nil, err
+			}
+			path, err := arguments.GetRequiredString("path")
+			if err != nil {
+				return nil, err
+			}
+			steps, err := compilePath(path)
+			if err != nil {
+				return nil, err
+			}
+			root, err := decode(data)
+			if err != nil {
+				return nil, err
+			}
+			results := query(root, steps)
+			vals := make([]values.Value, len(results))
+			for i, r := range results {
+				vals[i] = values.NewString(r)
+			}
+			return values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicString), vals), nil
+		},
+		false,
+	))
+}
+
+func getData(args interpreter.Arguments) ([]byte, error) {
+	v, err := args.GetRequired("data")
+	if err != nil {
+		return nil, err
+	}
+	switch n := v.Type().Nature(); n {
+	case semantic.Bytes:
+		return v.Bytes(), nil
+	case semantic.String:
+		return []byte(v.Str()), nil
+	default:
+		return nil, errors.Newf(codes.Invalid, "cannot parse XML from %v, expected bytes or string", n)
+	}
+}
+
+// node is an element of an XML document.
+// Namespaces are ignored, elements and attributes are known by their local names.
+type node struct {
+	name     string
+	attrs    []xml.Attr
+	children []*node
+	text     strings.Builder
+}
+
+// content returns the text of the element without its leading and trailing spaces.
+func (n *node) content() string {
+	return strings.TrimSpace(n.text.String())
+}
+
+// decode reads the root element of an XML document.
+func decode(data []byte) (*node, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		root  *node
+		stack []*node
+	)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "failed to parse XML")
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &node{name: t.Name.Local, attrs: t.Attr}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			} else if root != nil {
+				return nil, errors.New(codes.Invalid, "failed to parse XML: document has more than one root element")
+			} else {
+				root = n
+			}
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		}
+	}
+	if root == nil {
+		return nil, errors.New(codes.Invalid, "failed to parse XML: document has no root element")
+	}
+	return root, nil
+}
+
+// toValue converts an element into a Flux value.
+// An element with neither attributes nor child elements is its text.
+// The other elements are records, with a property for each attribute,
+// named with the attribute prefix, a property for each child element name,
+// that is an array when the element has several children with the name,
+// and the TextKey property when the element has text.
+func (n *node) toValue(prefix string) (values.Value, error) {
+	if len(n.attrs) == 0 && len(n.children) == 0 {
+		return values.NewString(n.content()), nil
+	}
+	props := make(map[string]values.Value, len(n.attrs)+len(n.children)+1)
+	set := func(k string, v values.Value) error {
+		if _, ok := props[k]; ok {
+			return errors.Newf(codes.Invalid, "element %q has more than one property named %q", n.name, k)
+		}
+		props[k] = v
+		return nil
+	}
+	for _, a := range n.attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		if err := set(prefix+a.Name.Local, values.NewString(a.Value)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Group the children by name in the order of the first child with each name.
+	var names []string
+	groups := make(map[string][]values.Value)
+	for _, c := range n.children {
+		v, err := c.toValue(prefix)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := groups[c.name]; !ok {
+			names = append(names, c.name)
+		}
+		groups[c.name] = append(groups[c.name], v)
+	}
+	for _, name := range names {
+		vs := groups[name]
+		if len(vs) == 1 {
+			if err := set(name, vs[0]); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		arr, err := newArray(name, vs)
+		if err != nil {
+			return nil, err
+		}
+		if err := set(name, arr); err != nil {
+			return nil, err
+		}
+	}
+	if text := n.content(); text != "" {
+		if err := set(TextKey, values.NewString(text)); err != nil {
+			return nil, err
+		}
+	}
+	return values.NewObjectWithValues(props), nil
+}
+
+func newArray(name string, vs []values.Value) (values.Value, error) {
+	vs, err := unify(name, vs)
+	if err != nil {
+		return nil, err
+	}
+	return values.NewArrayWithBacking(semantic.NewArrayType(vs[0].Type()), vs), nil
+}
+
+// unify converts values of elements with the same name to the same type,
+// so that they can be the elements of an array, or the properties of records in an array.
+// Strings become records with the TextKey property when some of the values are records,
+// values become arrays with one element when some of the values are arrays,
+// and the properties missing from some of the records are null.
+func unify(name string, vs []values.Value) ([]values.Value, error) {
+	var hasRecord, hasArray bool
+	same := true
+	for _, v := range vs {
+		switch v.Type().Nature() {
+		case semantic.Object:
+			hasRecord = true
+		case semantic.Array:
+			hasArray = true
+		}
+		same = same && v.Type().Equal(vs[0].Type())
+	}
+	if same {
+		return vs, nil
+	}
+
+	switch {
+	case hasArray:
+		var elems []values.Value
+		for _, v := range vs {
+			if v.IsNull() {
+				continue
+			} else if v.Type().Nature() == semantic.Array {
+				v.Array().Range(func(_ int, e values.Value) {
+					elems = append(elems, e)
+				})
+			} else {
+				elems = append(elems, v)
+			}
+		}
+		elems, err := unify(name, elems)
+		if err != nil {
+			return nil, err
+		} else if len(elems) == 0 {
+			return nil, errors.Newf(codes.Invalid, "elements named %q have different types %v and %v", name, vs[0].Type(), vs[len(vs)-1].Type())
+		}
+		typ := semantic.NewArrayType(elems[0].Type())
+		unified := make([]values.Value, len(vs))
+		for i, v := range vs {
+			n := 1
+			if v.IsNull() {
+				n = 0
+			} else if v.Type().Nature() == semantic.Array {
+				n = v.Array().Len()
+			}
+			unified[i] = values.NewArrayWithBacking(typ, elems[:n:n])
+			elems = elems[n:]
+		}
+		return unified, nil
+	case hasRecord:
+		records := make([]values.Object, len(vs))
+		for i, v := range vs {
+			switch {
+			case v.IsNull():
+				records[i] = values.NewObjectWithValues(nil)
+			case v.Type().Nature() == semantic.Object:
+				records[i] = v.Object()
+			case v.Type().Nature() == semantic.String:
+				records[i] = values.NewObjectWithValues(map[string]values.Value{TextKey: v})
+			default:
+				return nil, errors.Newf(codes.Invalid, "elements named %q have different types %v and %v", name, vs[0].Type(), v.Type())
+			}
+		}
+
+		// Unify the values of each property, in the order of the first record with the property.
+		var keys []string
+		props := make(map[string][]values.Value)
+		for _, r := range records {
+			r.Range(func(k string, v values.Value) {
+				if _, ok := props[k]; !ok {
+					keys = append(keys, k)
+				}
+				props[k] = append(props[k], v)
+			})
+		}
+		types := make(map[string]semantic.MonoType, len(keys))
+		for _, k := range keys {
+			pvs, err := unify(k, props[k])
+			if err != nil {
+				return nil, err
+			}
+			props[k] = pvs
+			types[k] = pvs[0].Type()
+		}
+		unified := make([]values.Value, len(records))
+		for i, r := range records {
+			obj := make(map[string]values.Value, len(keys))
+			for _, k := range keys {
+				if _, ok := r.Get(k); ok {
+					obj[k], props[k] = props[k][0], props[k][1:]
+				} else {
+					obj[k] = values.NewNull(types[k])
+				}
+			}
+			unified[i] = values.NewObjectWithValues(obj)
+		}
+		return unified, nil
+	default:
+		return nil, errors.Newf(codes.Invalid, "elements named %q have different types %v and %v", name, vs[0].Type(), vs[len(vs)-1].Type())
+	}
+}
+
+// step is a step of a path.
+type step struct {
+	// descendant is set when the step matches any descendant,
+	// instead of the children, of the current elements.
+	descendant bool
+	// name is the name of the matched elements, "*" matches any name.
+	name string
+	// index is the position, starting at 1, of the matched element
+	// among the matches of the step for each current element, 0 matches all.
+	index int
+	// attr is the name of the attribute selected by the last step,
+	// the last step selects the text of the elements when it is empty.
+	attr string
+}
+
+// compilePath parses a path such as /catalog/book[2]/@id or //price/text().
+// Paths are absolute, made of /name or //name steps, where name is an element name or *,
+// followed by an optional [n] position, and optionally end with /@name or /text().
+func compilePath(path string) ([]step, error) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, errors.Newf(codes.Invalid, "invalid path %q: paths must start with /", path)
+	}
+	var steps []step
+	for rest := path; rest != ""; {
+		var s step
+		if strings.HasPrefix(rest, "//") {
+			s.descendant = true
+			rest = rest[2:]
+		} else if strings.HasPrefix(rest, "/") {
+			rest = rest[1:]
+		} else {
+			return nil, errors.Newf(codes.Invalid, "invalid path %q", path)
+		}
+		end := strings.Index(rest, "/")
+		if end < 0 {
+			end = len(rest)
+		}
+		token := rest[:end]
+		rest = rest[end:]
+		switch {
+		case token == "":
+			return nil, errors.Newf(codes.Invalid, "invalid path %q: empty step", path)
+		case token == "text()" || strings.HasPrefix(token, "@"):
+			if rest != "" || len(steps) == 0 || s.descendant {
+				return nil, errors.Newf(codes.Invalid, "invalid path %q: %s must be the last step", path, token)
+			}
+			if token != "text()" {
+				if token == "@" {
+					return nil, errors.Newf(codes.Invalid, "invalid path %q: missing attribute name", path)
+				}
+				steps[len(steps)-1].attr = token[1:]
+			}
+			continue
+		}
+		s.name = token
+		if i := strings.Index(token, "["); i >= 0 {
+			if !strings.HasSuffix(token, "]") {
+				return nil, errors.Newf(codes.Invalid, "invalid path %q: unterminated position in %s", path, token)
+			}
+			index, err := strconv.Atoi(token[i+1 : len(token)-1])
+			if err != nil || index < 1 {
+				return nil, errors.Newf(codes.Invalid, "invalid path %q: position must be a positive integer in %s", path, token)
+			}
+			s.name, s.index = token[:i], index
+		}
+		steps = append(steps, s)
+	}
+	if len(steps) == 0 {
+		return nil, errors.Newf(codes.Invalid, "invalid path %q: no steps", path)
+	}
+	return steps, nil
+}
+
+// query returns the text of the elements, or the values of the attributes, selected by the steps.
+func query(root *node, steps []step) []string {
+	// The first step applies to a document node whose only child is the root.
+	current := []*node{{children: []*node{root}}}
+	for _, s := range steps {
+		var next []*node
+		for _, n := range current {
+			var matches []*node
+			if s.descendant {
+				matches = descendants(n, s.name, matches)
+			} else {
+				for _, c := range n.children {
+					if s.name == "*" || c.name == s.name {
+						matches = append(matches, c)
+					}
+				}
+			}
+			if s.index > 0 {
+				if s.index > len(matches) {
+					continue
+				}
+				matches = matches[s.index-1 : s.index]
+			}
+			next = append(next, matches...)
+		}
+		current = next
+	}
+
+	last := steps[len(steps)-1]
+	results := make([]string, 0, len(current))
+	for _, n := range current {
+		if last.attr == "" {
+			results = append(results, n.content())
+			continue
+		}
+		for _, a := range n.attrs {
+			if a.Name.Local == last.attr {
+				results = append(results, a.Value)
+				break
+			}
+		}
+	}
+	return results
+}
+
+// descendants appends the descendants of n with the name, in document order.
+func descendants(n *node, name string, matches []*node) []*node {
+	for _, c := range n.children {
+		if name == "*" || c.name == name {
+			matches = append(matches, c)
+		}
+		matches = descendants(c, name, matches)
+	}
+	return matches
+}
@@ -0,0 +1,172 @@
+package xml
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const station = `<?xml version="1.0"?>
+<station xmlns="http://example.com/weather" id="12">
+  <name>North</name>
+  <reading unit="C">21.5</reading>
+  <reading unit="F">71.6</reading>
+  <reading>22.0</reading>
+  <sensor><model>T1</model></sensor>
+  <sensor><model>T2</model><serial>42</serial></sensor>
+</station>`
+
+func TestParse(t *testing.T) {
+	root, err := decode([]byte(station))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := root.toValue(DefaultAttributePrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reading := func(unit values.Value, text string) values.Value {
+		return values.NewObjectWithValues(map[string]values.Value{
+			"@unit": unit,
+			"#text": values.NewString(text),
+		})
+	}
+	sensor := func(model string, serial values.Value) values.Value {
+		return values.NewObjectWithValues(map[string]values.Value{
+			"model":  values.NewString(model),
+			"serial": serial,
+		})
+	}
+	readings := []values.Value{
+		reading(values.NewString("C"), "21.5"),
+		reading(values.NewString("F"), "71.6"),
+		reading(values.NewNull(semantic.BasicString), "22.0"),
+	}
+	sensors := []values.Value{
+		sensor("T1", values.NewNull(semantic.BasicString)),
+		sensor("T2", values.NewString("42")),
+	}
+	want := values.NewObjectWithValues(map[string]values.Value{
+		"@id":     values.NewString("12"),
+		"name":    values.NewString("North"),
+		"reading": values.NewArrayWithBacking(semantic.NewArrayType(readings[0].Type()), readings),
+		"sensor":  values.NewArrayWithBacking(semantic.NewArrayType(sensors[1].Type()), sensors),
+	})
+	if !equal(want, got) {
+		t.Errorf("unexpected value\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+// equal compares values like Value.Equal, except that nulls of the same type are equal.
+func equal(l, r values.Value) bool {
+	if !l.Type().Equal(r.Type()) {
+		return false
+	} else if l.IsNull() || r.IsNull() {
+		return l.IsNull() && r.IsNull()
+	}
+	switch l.Type().Nature() {
+	case semantic.Object:
+		eq := true
+		l.Object().Range(func(k string, v values.Value) {
+			rv, _ := r.Object().Get(k)
+			eq = eq && equal(v, rv)
+		})
+		return eq
+	case semantic.Array:
+		if l.Array().Len() != r.Array().Len() {
+			return false
+		}
+		for i := 0; i < l.Array().Len(); i++ {
+			if !equal(l.Array().Get(i), r.Array().Get(i)) {
+				return false
+			}
+		}
+		return true
+	default:
+		return l.Equal(r)
+	}
+}
+
+func TestParse_AttributePrefix(t *testing.T) {
+	root, err := decode([]byte(`<a b="1"><b>2</b></a>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := root.toValue(""); err == nil {
+		t.Error("expected an error for an attribute and an element with the same property")
+	}
+	got, err := root.toValue("_")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := values.NewObjectWithValues(map[string]values.Value{
+		"_b": values.NewString("1"),
+		"b":  values.NewString("2"),
+	})
+	if !want.Equal(got) {
+		t.Errorf("unexpected value\nwant: %v\ngot:  %v", want, got)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, data := range []string{
+		``,
+		`<a>`,
+		`<a></a><b></b>`,
+	} {
+		if _, err := decode([]byte(data)); err == nil {
+			t.Errorf("expected an error for %q", data)
+		}
+	}
+}
+
+func TestQuery(t *testing.T) {
+	root, err := decode([]byte(station))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		path string
+		want []string
+	}{
+		{path: "/station/name", want: []string{"North"}},
+		{path: "/station/@id", want: []string{"12"}},
+		{path: "/station/reading/text()", want: []string{"21.5", "71.6", "22.0"}},
+		{path: "/station/reading/@unit", want: []string{"C", "F"}},
+		{path: "/station/reading[2]", want: []string{"71.6"}},
+		{path: "/station/reading[4]", want: []string{}},
+		{path: "//model", want: []string{"T1", "T2"}},
+		{path: "/station/*/model", want: []string{"T1", "T2"}},
+		{path: "//sensor[1]/model", want: []string{"T1"}},
+		{path: "/other", want: []string{}},
+	} {
+		steps, err := compilePath(tc.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.path, err)
+		}
+		if got := query(root, steps); !cmp.Equal(tc.want, got) {
+			t.Errorf("%s: unexpected result -want/+got:\n%s", tc.path, cmp.Diff(tc.want, got))
+		}
+	}
+}
+
+func TestCompilePath_Errors(t *testing.T) {
+	for _, path := range []string{
+		"",
+		"station",
+		"/station//",
+		"/@id",
+		"/station/@id/name",
+		"/station/@",
+		"/station[0]",
+		"/station[x]",
+		"/station[1",
+	} {
+		if _, err := compilePath(path); err == nil {
+			t.Errorf("expected an error for %q", path)
+		}
+	}
+}
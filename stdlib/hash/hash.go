@@ -0,0 +1,141 @@
+package hash
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	gohash "hash"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/function"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const (
+	pkgpath = "hash"
+
+	DefaultEncoding  = "hex"
+	DefaultAlgorithm = "sha256"
+)
+
+// algorithms are the hash functions known by their name.
+var algorithms = map[string]func() gohash.Hash{
+	"md5":    md5.New,
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+}
+
+func init() {
+	b := function.ForPackage(pkgpath)
+	b.Register("md5", digestFunc("md5"))
+	b.Register("sha1", digestFunc("sha1"))
+	b.Register("sha256", digestFunc("sha256"))
+	b.Register("hmac", HMAC)
+}
+
+// digestFunc returns the function that computes the digest of a value using the named hash function.
+func digestFunc(algorithm string) function.Definition {
+	return func(args interpreter.Arguments) (values.Value, error) {
+		data, err := getBytes(args, "v")
+		if err != nil {
+			return nil, err
+		}
+		h := algorithms[algorithm]()
+		h.Write(data)
+		return encode(args, h.Sum(nil))
+	}
+}
+
+// HMAC computes the keyed-hash message authentication code of a value.
+func HMAC(args interpreter.Arguments) (values.Value, error) {
+	data, err := getBytes(args, "v")
+	if err != nil {
+		return nil, err
+	}
+	key, err := getBytes(args, "key")
+	if err != nil {
+		return nil, err
+	}
+	if keyEncoding, ok, err := args.GetString("keyEncoding"); err != nil {
+		return nil, err
+	} else if ok {
+		if key, err = decode(keyEncoding, key); err != nil {
+			return nil, err
+		}
+	}
+	algorithm, ok, err := args.GetString("algorithm")
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		algorithm = DefaultAlgorithm
+	}
+	newHash, ok := algorithms[algorithm]
+	if !ok {
+		return nil, errors.Newf(codes.Invalid, "unknown hash algorithm %q, expected one of md5, sha1 or sha256", algorithm)
+	}
+	h := hmac.New(newHash, key)
+	h.Write(data)
+	return encode(args, h.Sum(nil))
+}
+
+// getBytes returns the bytes of a string or bytes argument.
+func getBytes(args interpreter.Arguments, name string) ([]byte, error) {
+	v, err := args.GetRequired(name)
+	if err != nil {
+		return nil, err
+	}
+	switch n := v.Type().Nature(); n {
+	case semantic.String:
+		return []byte(v.Str()), nil
+	case semantic.Bytes:
+		return v.Bytes(), nil
+	default:
+		return nil, errors.Newf(codes.Invalid, "%s must be a string or bytes, got %v", name, n)
+	}
+}
+
+// encode returns the digest as a string, using the encoding argument.
+func encode(args interpreter.Arguments, digest []byte) (values.Value, error) {
+	encoding, ok, err := args.GetString("encoding")
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		encoding = DefaultEncoding
+	}
+	switch encoding {
+	case "hex":
+		return values.NewString(hex.EncodeToString(digest)), nil
+	case "base64":
+		return values.NewString(base64.StdEncoding.EncodeToString(digest)), nil
+	default:
+		return nil, errors.Newf(codes.Invalid, "unknown encoding %q, expected hex or base64", encoding)
+	}
+}
+
+// decode decodes a key using the key encoding, "raw" keys are used as is.
+func decode(encoding string, key []byte) ([]byte, error) {
+	switch encoding {
+	case "raw":
+		return key, nil
+	case "hex":
+		decoded, err := hex.DecodeString(string(key))
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "invalid hex key")
+		}
+		return decoded, nil
+	case "base64":
+		decoded, err := base64.StdEncoding.DecodeString(string(key))
+		if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "invalid base64 key")
+		}
+		return decoded, nil
+	default:
+		return nil, errors.Newf(codes.Invalid, "unknown key encoding %q, expected raw, hex or base64", encoding)
+	}
+}
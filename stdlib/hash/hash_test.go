@@ -0,0 +1,104 @@
+package hash_test
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/stdlib/hash"
+	"github.com/influxdata/flux/values"
+)
+
+func TestHMAC(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args map[string]values.Value
+		want string
+	}{
+		{
+			// RFC 4231, test case 2.
+			name: "sha256",
+			args: map[string]values.Value{
+				"v":   values.NewString("what do ya want for nothing?"),
+				"key": values.NewString("Jefe"),
+			},
+			want: "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843",
+		},
+		{
+			// RFC 2202, test case 2.
+			name: "md5",
+			args: map[string]values.Value{
+				"v":         values.NewBytes([]byte("what do ya want for nothing?")),
+				"key":       values.NewBytes([]byte("Jefe")),
+				"algorithm": values.NewString("md5"),
+			},
+			want: "750c783e6ab0b503eaa86e310a5db738",
+		},
+		{
+			// RFC 2202, test case 1.
+			name: "hex key",
+			args: map[string]values.Value{
+				"v":           values.NewString("Hi There"),
+				"key":         values.NewString("0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b"),
+				"keyEncoding": values.NewString("hex"),
+				"algorithm":   values.NewString("sha1"),
+				"encoding":    values.NewString("base64"),
+			},
+			want: "thcxhlUFcmTii8C2+zeMjvFGvgA=",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := hash.HMAC(interpreter.NewArguments(values.NewObjectWithValues(tc.args)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Str() != tc.want {
+				t.Errorf("unexpected HMAC -want/+got:\n\t- %s\n\t+ %s", tc.want, got.Str())
+			}
+		})
+	}
+}
+
+func TestHMAC_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args map[string]values.Value
+		want string
+	}{
+		{
+			name: "algorithm",
+			args: map[string]values.Value{
+				"v":         values.NewString("a"),
+				"key":       values.NewString("b"),
+				"algorithm": values.NewString("crc32"),
+			},
+			want: `unknown hash algorithm "crc32", expected one of md5, sha1 or sha256`,
+		},
+		{
+			name: "encoding",
+			args: map[string]values.Value{
+				"v":        values.NewString("a"),
+				"key":      values.NewString("b"),
+				"encoding": values.NewString("base32"),
+			},
+			want: `unknown encoding "base32", expected hex or base64`,
+		},
+		{
+			name: "key",
+			args: map[string]values.Value{
+				"v":   values.NewString("a"),
+				"key": values.NewInt(1),
+			},
+			want: `key must be a string or bytes, got int`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := hash.HMAC(interpreter.NewArguments(values.NewObjectWithValues(tc.args)))
+			if err == nil {
+				t.Fatalf("expected error: %s", tc.want)
+			}
+			if err.Error() != tc.want {
+				t.Errorf("unexpected error -want/+got:\n\t- %s\n\t+ %s", tc.want, err)
+			}
+		})
+	}
+}
@@ -6,9 +6,12 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
+	fluxhttp "github.com/influxdata/flux/dependencies/http"
+	"github.com/influxdata/flux/dependencies/url"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/iocounter"
 	"github.com/influxdata/flux/runtime"
@@ -58,8 +61,25 @@ func init() {
 				}
 			}
 
+			// Read the timeout and the retry parameters
+			var timeout time.Duration
+			if tv, ok := args.Get("timeout"); ok && !tv.IsNull() {
+				timeout = tv.Duration().Duration()
+			}
+			override, err := GetRetryOverride(args)
+			if err != nil {
+				return nil, err
+			}
+
 			// Perform request
 			deps := flux.GetDependencies(ctx)
+			validator, err := deps.URLValidator()
+			if err != nil {
+				return nil, err
+			}
+			if err := url.ForPackage(validator, url.HTTPPackage).Validate(req.URL); err != nil {
+				return nil, err
+			}
 			dc, err := deps.HTTPClient()
 			if err != nil {
 				return nil, errors.Wrap(err, codes.Aborted, "missing client in http.post")
@@ -70,6 +90,15 @@ func init() {
 				s.SetTag("url", req.URL.String())
 				defer s.Finish()
 
+				// the client retries the request per its policy and the
+				// override, and the timeout applies to all of the attempts
+				cctx = fluxhttp.WithRetryOverride(cctx, override)
+				if timeout > 0 {
+					var cancel context.CancelFunc
+					cctx, cancel = context.WithTimeout(cctx, timeout)
+					defer cancel()
+				}
+
 				req = req.WithContext(cctx)
 				response, err := dc.Do(req)
 				if err != nil {
@@ -86,3 +86,32 @@ http.post(url:"http://127.1.1.1/path/a/b/c", headers: {x:"a",y:"b",z:"c"}, data:
 		t.Errorf("unexpected cause of failure, got err: %v", err)
 	}
 }
+
+func TestPost_Retries(t *testing.T) {
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(500)
+			return
+		}
+		w.WriteHeader(204)
+	}))
+	defer ts.Close()
+
+	script := fmt.Sprintf(`
+import "http"
+import "internal/testutil"
+
+status = http.post(url:"%s", data: bytes(v: "body"), maxAttempts: 3, retryStatusCodes: [500], backoff: 1ms, timeout: 10s)
+status == 204 or testutil.fail()
+`, ts.URL)
+
+	ctx := flux.NewDefaultDependencies().Inject(context.Background())
+	if _, _, err := runtime.Eval(ctx, script); err != nil {
+		t.Fatal("evaluation of http.post failed: ", err)
+	}
+	if want, got := 3, attempts; want != got {
+		t.Errorf("unexpected number of attempts want: %d got: %d", want, got)
+	}
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/http"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/values"
+)
+
+// GetRetryOverride reads the retry parameters shared by the functions
+// that send HTTP requests: maxAttempts, retryStatusCodes, backoff and maxBackoff.
+// The override sets the parameters that are set on the retry policy of the
+// HTTP client, see http.WithRetryOverride. It is nil if none are set.
+func GetRetryOverride(args values.Object) (func(p *http.RetryPolicy), error) {
+	var (
+		set                 bool
+		maxAttempts         int
+		statusCodes         []int
+		backoff, maxBackoff *time.Duration
+	)
+	if v, ok := args.Get("maxAttempts"); ok && !v.IsNull() {
+		if v.Int() < 1 {
+			return nil, errors.Newf(codes.Invalid, "maxAttempts must be at least 1, got %d", v.Int())
+		}
+		maxAttempts, set = int(v.Int()), true
+	}
+	if v, ok := args.Get("retryStatusCodes"); ok && !v.IsNull() {
+		arr := v.Array()
+		statusCodes = make([]int, 0, arr.Len())
+		arr.Range(func(i int, v values.Value) {
+			statusCodes = append(statusCodes, int(v.Int()))
+		})
+		set = true
+	}
+	for _, d := range []struct {
+		name string
+		v    **time.Duration
+	}{
+		{name: "backoff", v: &backoff},
+		{name: "maxBackoff", v: &maxBackoff},
+	} {
+		if v, ok := args.Get(d.name); ok && !v.IsNull() {
+			dur := v.Duration().Duration()
+			if dur < 0 {
+				return nil, errors.Newf(codes.Invalid, "%s must not be negative, got %v", d.name, dur)
+			}
+			*d.v, set = &dur, true
+		}
+	}
+	if !set {
+		return nil, nil
+	}
+	return func(p *http.RetryPolicy) {
+		if maxAttempts > 0 {
+			p.MaxAttempts = maxAttempts
+		}
+		if statusCodes != nil {
+			p.StatusCodes = statusCodes
+		}
+		if backoff != nil {
+			p.InitialBackoff = *backoff
+		}
+		if maxBackoff != nil {
+			p.MaxBackoff = *maxBackoff
+		}
+	}, nil
+}
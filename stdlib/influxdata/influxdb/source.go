@@ -15,11 +15,17 @@ import (
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/csv"
 	"github.com/influxdata/flux/dependencies/influxdb"
+	"github.com/influxdata/flux/dependencies/tablebuffer"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/memory"
 )
 
+// DefaultBufferSize is the default number of rows in each buffer of the
+// tables read from a remote influxdb. It matches the buffer size used by
+// the influxdb storage engine so the tables are not reorganized.
+const DefaultBufferSize = 1000
+
 type ProcedureSpec interface {
 	GetOrg() *NameOrID
 	GetHost() *string
@@ -91,7 +97,7 @@ func (s *source) run(ctx context.Context) error {
 		}
 		return s.parseError(data)
 	}
-	return s.processResults(resp.Body)
+	return s.processResults(ctx, resp.Body)
 }
 
 func (s *source) validateHost(host string) error {
@@ -174,10 +180,13 @@ func (s *source) newRequestBody() ([]byte, error) {
 	return json.Marshal(req)
 }
 
-func (s *source) processResults(r io.ReadCloser) error {
+func (s *source) processResults(ctx context.Context, r io.ReadCloser) error {
 	defer func() { _ = r.Close() }()
 
-	config := csv.ResultDecoderConfig{Allocator: s.mem}
+	config := csv.ResultDecoderConfig{
+		MaxBufferCount: tablebuffer.GetSize(ctx, DefaultBufferSize),
+		Allocator:      s.mem,
+	}
 	dec := csv.NewMultiResultDecoder(config)
 	results, err := dec.Decode(r)
 	if err != nil {
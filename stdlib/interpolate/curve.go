@@ -0,0 +1,180 @@
+package interpolate
+
+import (
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/values"
+)
+
+// curveFunc returns the interpolated value at x, where x is between
+// the points i and i+1 of the fitted curve.
+type curveFunc func(i int, x float64) float64
+
+// curveFitter fits a curve through the points of a table.
+// The x values are strictly increasing and there are at least two points.
+type curveFitter func(xs, ys []float64) curveFunc
+
+// curveTransformation inserts rows at regular intervals, and estimates the
+// values of the inserted rows with a curve fitted through all the points of a table.
+// Unlike the linear interpolation it needs every point of the table,
+// so the tables are buffered before they are interpolated.
+type curveTransformation struct {
+	execute.ExecutionNode
+	d      execute.Dataset
+	cache  execute.TableBuilderCache
+	name   string
+	fit    curveFitter
+	window execute.Window
+}
+
+func newCurveTransformation(d execute.Dataset, cache execute.TableBuilderCache, name string, every flux.Duration, fit curveFitter) *curveTransformation {
+	return &curveTransformation{
+		d:     d,
+		cache: cache,
+		name:  name,
+		fit:   fit,
+		window: execute.Window{
+			Every:  every,
+			Period: every,
+		},
+	}
+}
+
+func (t *curveTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *curveTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	key, columns := tbl.Key(), tbl.Cols()
+
+	for _, c := range columns {
+		if key.HasCol(c.Label) {
+			continue
+		}
+		if c.Label == execute.DefaultTimeColLabel {
+			continue
+		}
+		if c.Label == execute.DefaultValueColLabel {
+			continue
+		}
+		return errors.Newf(codes.FailedPrecondition,
+			"interpolate.%s requires column %q to be in group key", t.name, c.Label,
+		)
+	}
+
+	b, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition,
+			"duplicate table with key: %v", tbl.Key(),
+		)
+	}
+
+	if err := execute.AddTableCols(tbl, b); err != nil {
+		return err
+	}
+
+	ti := execute.ColIdx("_time", columns)
+	if ti < 0 {
+		return errors.New(codes.FailedPrecondition,
+			"_time column does not exist",
+		)
+	}
+
+	vi := execute.ColIdx("_value", columns)
+	if vi < 0 {
+		return errors.New(codes.FailedPrecondition,
+			"_value column does not exist",
+		)
+	}
+
+	if ty := columns[vi].Type; ty != flux.TFloat {
+		return errors.Newf(codes.FailedPrecondition,
+			"cannot interpolate %v values; expected float values", ty,
+		)
+	}
+
+	var (
+		times []int64
+		ys    []float64
+	)
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		tc := cr.Times(ti)
+		vc := cr.Floats(vi)
+		for i := 0; i < cr.Len(); i++ {
+			if tc.IsNull(i) {
+				return errors.Newf(codes.FailedPrecondition,
+					"null _time found during %s interpolation", t.name,
+				)
+			}
+			if vc.IsNull(i) {
+				return errors.Newf(codes.FailedPrecondition,
+					"null _value found during %s interpolation", t.name,
+				)
+			}
+			if n := len(times); n > 0 && tc.Value(i) <= times[n-1] {
+				return errors.Newf(codes.FailedPrecondition,
+					"%s interpolation requires strictly increasing _time values", t.name,
+				)
+			}
+			times = append(times, tc.Value(i))
+			ys = append(ys, vc.Value(i))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	fn := appendFn(b, ti, vi)
+	appendRow := func(x int64, y float64) error {
+		if err := fn(x, y); err != nil {
+			return err
+		}
+		return execute.AppendKeyValues(key, b)
+	}
+
+	if len(times) < 2 {
+		for i := range times {
+			if err := appendRow(times[i], ys[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// The curve is fitted on the time elapsed since the first point,
+	// which keeps the x values small enough to be precise as floats.
+	xs := make([]float64, len(times))
+	for i := range times {
+		xs[i] = float64(times[i] - times[0])
+	}
+	curve := t.fit(xs, ys)
+
+	for i := range times {
+		if err := appendRow(times[i], ys[i]); err != nil {
+			return err
+		}
+		if i == len(times)-1 {
+			break
+		}
+		xi := int64(t.window.GetEarliestBounds(values.Time(times[i])).Stop)
+		for xi < times[i+1] {
+			if err := appendRow(xi, curve(i, float64(xi-times[0]))); err != nil {
+				return err
+			}
+			xi = int64(execute.Time(xi).Add(t.window.Every))
+		}
+	}
+	return nil
+}
+
+func (t *curveTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+func (t *curveTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+func (t *curveTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
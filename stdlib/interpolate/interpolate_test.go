@@ -459,3 +459,213 @@ func TestLinearInterpolate(t *testing.T) {
 		})
 	}
 }
+
+func TestSplineInterpolate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *interpolate.SplineInterpolateProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "curve",
+			spec: &interpolate.SplineInterpolateProcedureSpec{
+				Every: flux.ConvertDuration(1 * time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				KeyCols: []string{"_field"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_field", Type: flux.TString},
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{"a", execute.Time(0), 0.0},
+					{"a", execute.Time(2), 2.0},
+					{"a", execute.Time(4), 0.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols: []string{"_field"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_field", Type: flux.TString},
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{"a", execute.Time(0), 0.0},
+					{"a", execute.Time(1), 1.375},
+					{"a", execute.Time(2), 2.0},
+					{"a", execute.Time(3), 1.375},
+					{"a", execute.Time(4), 0.0},
+				},
+			}},
+		},
+		{
+			name: "irregular linear",
+			spec: &interpolate.SplineInterpolateProcedureSpec{
+				Every: flux.ConvertDuration(2 * time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+					{execute.Time(3), 3.0},
+					{execute.Time(9), 9.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+					{execute.Time(2), 2.0},
+					{execute.Time(3), 3.0},
+					{execute.Time(4), 4.0},
+					{execute.Time(6), 6.0},
+					{execute.Time(8), 8.0},
+					{execute.Time(9), 9.0},
+				},
+			}},
+		},
+		{
+			name: "unordered times",
+			spec: &interpolate.SplineInterpolateProcedureSpec{
+				Every: flux.ConvertDuration(1 * time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(2), 2.0},
+					{execute.Time(1), 1.0},
+				},
+			}},
+			wantErr: fmt.Errorf("spline interpolation requires strictly increasing _time values"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return interpolate.NewSplineTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
+
+func TestPolynomialInterpolate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		spec    *interpolate.PolynomialInterpolateProcedureSpec
+		data    []flux.Table
+		want    []*executetest.Table
+		wantErr error
+	}{
+		{
+			name: "quadratic",
+			spec: &interpolate.PolynomialInterpolateProcedureSpec{
+				Every:  flux.ConvertDuration(1 * time.Nanosecond),
+				Degree: 2,
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 0.0},
+					{execute.Time(1), 1.0},
+					{execute.Time(4), 16.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(0), 0.0},
+					{execute.Time(1), 1.0},
+					{execute.Time(2), 4.0},
+					{execute.Time(3), 9.0},
+					{execute.Time(4), 16.0},
+				},
+			}},
+		},
+		{
+			name: "fewer points than degree",
+			spec: &interpolate.PolynomialInterpolateProcedureSpec{
+				Every:  flux.ConvertDuration(5 * time.Nanosecond),
+				Degree: 3,
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+					{execute.Time(9), 9.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+					{execute.Time(5), 5.0},
+					{execute.Time(9), 9.0},
+				},
+			}},
+		},
+		{
+			name: "nulls",
+			spec: &interpolate.PolynomialInterpolateProcedureSpec{
+				Every:  flux.ConvertDuration(5 * time.Nanosecond),
+				Degree: 3,
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+					{execute.Time(5), nil},
+				},
+			}},
+			wantErr: fmt.Errorf("null _value found during polynomial interpolation"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				tc.wantErr,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return interpolate.NewPolynomialTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
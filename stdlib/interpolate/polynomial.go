@@ -0,0 +1,161 @@
+package interpolate
+
+import (
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const PolynomialInterpolateKind = "polynomialInterpolateKind"
+
+// DefaultPolynomialDegree is the degree of the polynomials used by interpolate.polynomial.
+const DefaultPolynomialDegree = 3
+
+type PolynomialInterpolateOpSpec struct {
+	Every  flux.Duration `json:"every"`
+	Degree int64         `json:"degree"`
+}
+
+func init() {
+	runtime.RegisterPackageValue("interpolate", "polynomial",
+		flux.MustValue(flux.FunctionValue("polynomial",
+			createPolynomialOpSpec,
+			runtime.MustLookupBuiltinType("interpolate", "polynomial"),
+		)),
+	)
+	flux.RegisterOpSpec(PolynomialInterpolateKind,
+		func() flux.OperationSpec {
+			return new(PolynomialInterpolateOpSpec)
+		},
+	)
+	plan.RegisterProcedureSpec(
+		PolynomialInterpolateKind,
+		newPolynomialProcedure,
+		PolynomialInterpolateKind,
+	)
+	execute.RegisterTransformation(
+		PolynomialInterpolateKind,
+		createPolynomialTransformation,
+	)
+}
+
+func createPolynomialOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	every, err := args.GetRequiredDuration("every")
+	if err != nil {
+		return nil, err
+	}
+
+	degree, ok, err := args.GetInt("degree")
+	if err != nil {
+		return nil, err
+	} else if !ok {
+		degree = DefaultPolynomialDegree
+	} else if degree < 1 {
+		return nil, errors.Newf(codes.Invalid, "degree must be at least 1, got %d", degree)
+	}
+
+	return &PolynomialInterpolateOpSpec{
+		Every:  every,
+		Degree: degree,
+	}, nil
+}
+
+func (s *PolynomialInterpolateOpSpec) Kind() flux.OperationKind {
+	return PolynomialInterpolateKind
+}
+
+type PolynomialInterpolateProcedureSpec struct {
+	plan.DefaultCost
+	Every  flux.Duration `json:"every"`
+	Degree int64         `json:"degree"`
+}
+
+func newPolynomialProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*PolynomialInterpolateOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+
+	return &PolynomialInterpolateProcedureSpec{
+		Every:  spec.Every,
+		Degree: spec.Degree,
+	}, nil
+}
+
+func (s *PolynomialInterpolateProcedureSpec) Kind() plan.ProcedureKind {
+	return PolynomialInterpolateKind
+}
+func (s *PolynomialInterpolateProcedureSpec) Copy() plan.ProcedureSpec {
+	return &PolynomialInterpolateProcedureSpec{
+		Every:  s.Every,
+		Degree: s.Degree,
+	}
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *PolynomialInterpolateProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createPolynomialTransformation(
+	id execute.DatasetID,
+	mode execute.AccumulationMode,
+	spec plan.ProcedureSpec,
+	a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*PolynomialInterpolateProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewPolynomialTransformation(d, cache, s)
+	return t, d, nil
+}
+
+func NewPolynomialTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *PolynomialInterpolateProcedureSpec) execute.Transformation {
+	return newCurveTransformation(d, cache, "polynomial", spec.Every, polynomialFitter(int(spec.Degree)))
+}
+
+// polynomialFitter interpolates the values between two points with the polynomial
+// of the given degree that passes through the degree+1 points nearest to them.
+// Using the nearest points instead of all the points of a table avoids
+// the oscillations of high degree polynomials.
+func polynomialFitter(degree int) curveFitter {
+	return func(xs, ys []float64) curveFunc {
+		size := degree + 1
+		if size > len(xs) {
+			size = len(xs)
+		}
+		return func(i int, x float64) float64 {
+			start := i + 1 - size/2
+			if start < 0 {
+				start = 0
+			} else if start > len(xs)-size {
+				start = len(xs) - size
+			}
+			return lagrange(xs[start:start+size], ys[start:start+size], x)
+		}
+	}
+}
+
+// lagrange evaluates at x the polynomial that passes through the points.
+func lagrange(xs, ys []float64, x float64) float64 {
+	var y float64
+	for j := range xs {
+		l := ys[j]
+		for k := range xs {
+			if k != j {
+				l *= (x - xs[k]) / (xs[j] - xs[k])
+			}
+		}
+		y += l
+	}
+	return y
+}
@@ -0,0 +1,143 @@
+package interpolate
+
+import (
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+)
+
+const SplineInterpolateKind = "splineInterpolateKind"
+
+type SplineInterpolateOpSpec struct {
+	Every flux.Duration `json:"every"`
+}
+
+func init() {
+	runtime.RegisterPackageValue("interpolate", "spline",
+		flux.MustValue(flux.FunctionValue("spline",
+			createSplineOpSpec,
+			runtime.MustLookupBuiltinType("interpolate", "spline"),
+		)),
+	)
+	flux.RegisterOpSpec(SplineInterpolateKind,
+		func() flux.OperationSpec {
+			return new(SplineInterpolateOpSpec)
+		},
+	)
+	plan.RegisterProcedureSpec(
+		SplineInterpolateKind,
+		newSplineProcedure,
+		SplineInterpolateKind,
+	)
+	execute.RegisterTransformation(
+		SplineInterpolateKind,
+		createSplineTransformation,
+	)
+}
+
+func createSplineOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	every, err := args.GetRequiredDuration("every")
+	if err != nil {
+		return nil, err
+	}
+
+	return &SplineInterpolateOpSpec{
+		Every: every,
+	}, nil
+}
+
+func (s *SplineInterpolateOpSpec) Kind() flux.OperationKind {
+	return SplineInterpolateKind
+}
+
+type SplineInterpolateProcedureSpec struct {
+	plan.DefaultCost
+	Every flux.Duration `json:"every"`
+}
+
+func newSplineProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*SplineInterpolateOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+
+	return &SplineInterpolateProcedureSpec{
+		Every: spec.Every,
+	}, nil
+}
+
+func (s *SplineInterpolateProcedureSpec) Kind() plan.ProcedureKind {
+	return SplineInterpolateKind
+}
+func (s *SplineInterpolateProcedureSpec) Copy() plan.ProcedureSpec {
+	return &SplineInterpolateProcedureSpec{
+		Every: s.Every,
+	}
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *SplineInterpolateProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createSplineTransformation(
+	id execute.DatasetID,
+	mode execute.AccumulationMode,
+	spec plan.ProcedureSpec,
+	a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*SplineInterpolateProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewSplineTransformation(d, cache, s)
+	return t, d, nil
+}
+
+func NewSplineTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *SplineInterpolateProcedureSpec) execute.Transformation {
+	return newCurveTransformation(d, cache, "spline", spec.Every, fitSpline)
+}
+
+// fitSpline fits a natural cubic spline through the points,
+// the second derivative of the curve is zero at the first and last points.
+func fitSpline(xs, ys []float64) curveFunc {
+	n := len(xs)
+	h := make([]float64, n-1)
+	for i := range h {
+		h[i] = xs[i+1] - xs[i]
+	}
+
+	// The second derivatives m of the inner points are the solution of a
+	// tridiagonal system, that is solved with the Thomas algorithm.
+	m := make([]float64, n)
+	if n > 2 {
+		c := make([]float64, n)
+		d := make([]float64, n)
+		for i := 1; i < n-1; i++ {
+			a := h[i-1]
+			b := 2 * (h[i-1] + h[i])
+			r := 6 * ((ys[i+1]-ys[i])/h[i] - (ys[i]-ys[i-1])/h[i-1])
+			w := b - a*c[i-1]
+			c[i] = h[i] / w
+			d[i] = (r - a*d[i-1]) / w
+		}
+		for i := n - 2; i > 0; i-- {
+			m[i] = d[i] - c[i]*m[i+1]
+		}
+	}
+
+	return func(i int, x float64) float64 {
+		a, b := xs[i+1]-x, x-xs[i]
+		return m[i]*a*a*a/(6*h[i]) + m[i+1]*b*b*b/(6*h[i]) +
+			(ys[i]/h[i]-m[i]*h[i]/6)*a +
+			(ys[i+1]/h[i]-m[i+1]*h[i]/6)*b
+	}
+}
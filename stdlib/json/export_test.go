@@ -0,0 +1,3 @@
+package json
+
+var Parse = parse
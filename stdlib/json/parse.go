@@ -0,0 +1,136 @@
+package json
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+func init() {
+	runtime.RegisterPackageValue("json", "parse", values.NewFunction(
+		"parse",
+		runtime.MustLookupBuiltinType("json", "parse"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			v, ok := args.Get("data")
+			if !ok {
+				return nil, errors.New(codes.Invalid, "missing parameter \"data\"")
+			}
+			var data []byte
+			switch n := v.Type().Nature(); n {
+			case semantic.Bytes:
+				data = v.Bytes()
+			case semantic.String:
+				data = []byte(v.Str())
+			default:
+				return nil, errors.Newf(codes.Invalid, "cannot parse JSON from %v, expected bytes or string", n)
+			}
+			return parse(data)
+		},
+		false,
+	))
+}
+
+// parse decodes a single JSON value into a Flux value.
+func parse(data []byte) (values.Value, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var i interface{}
+	if err := dec.Decode(&i); err != nil {
+		return nil, errors.Wrap(err, codes.Invalid, "failed to parse JSON")
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New(codes.Invalid, "failed to parse JSON: unexpected data after the JSON value")
+	}
+	return parseValue(i)
+}
+
+// parseValue converts a value produced by a json.Decoder using numbers into a Flux value.
+// Numbers without a fraction or an exponent that fit in an int are ints,
+// the other numbers are floats.
+func parseValue(i interface{}) (values.Value, error) {
+	switch t := i.(type) {
+	case nil:
+		return values.Null, nil
+	case string:
+		return values.NewString(t), nil
+	case bool:
+		return values.NewBool(t), nil
+	case json.Number:
+		if !strings.ContainsAny(t.String(), ".eE") {
+			if n, err := t.Int64(); err == nil {
+				return values.NewInt(n), nil
+			}
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "invalid JSON number %s", t)
+		}
+		return values.NewFloat(f), nil
+	case []interface{}:
+		return parseArray(t)
+	case map[string]interface{}:
+		vals := make(map[string]values.Value, len(t))
+		for k, v := range t {
+			val, err := parseValue(v)
+			if err != nil {
+				return nil, err
+			}
+			vals[k] = val
+		}
+		return values.NewObjectWithValues(vals), nil
+	}
+	return nil, errors.Newf(codes.Internal, "unsupported JSON type %T", i)
+}
+
+// parseArray converts a JSON array into a Flux array.
+// The elements must have the same type, except that
+// the ints of an array that also has floats become floats,
+// and that null elements are nulls of the element type.
+// An array without elements that are not null is an array of strings.
+func parseArray(elems []interface{}) (values.Value, error) {
+	vals := make([]values.Value, len(elems))
+	var elemType semantic.MonoType
+	for i, e := range elems {
+		val, err := parseValue(e)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+		if val.IsNull() {
+			continue
+		}
+		switch {
+		case elemType.Nature() == semantic.Invalid:
+			elemType = val.Type()
+		case val.Type().Equal(elemType):
+		case isNumber(val.Type()) && isNumber(elemType):
+			elemType = semantic.BasicFloat
+		default:
+			return nil, errors.Newf(codes.Invalid, "array values must all be the same type, found %v and %v", elemType, val.Type())
+		}
+	}
+	if elemType.Nature() == semantic.Invalid {
+		elemType = semantic.BasicString
+	}
+	for i, val := range vals {
+		if val.IsNull() {
+			vals[i] = values.NewNull(elemType)
+		} else if elemType.Nature() == semantic.Float && val.Type().Nature() == semantic.Int {
+			vals[i] = values.NewFloat(float64(val.Int()))
+		}
+	}
+	return values.NewArrayWithBacking(semantic.NewArrayType(elemType), vals), nil
+}
+
+func isNumber(t semantic.MonoType) bool {
+	n := t.Nature()
+	return n == semantic.Int || n == semantic.Float
+}
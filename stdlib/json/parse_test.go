@@ -0,0 +1,147 @@
+package json_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/stdlib/json"
+	"github.com/influxdata/flux/values"
+)
+
+func TestJSONParse(t *testing.T) {
+	script := `
+import "json"
+import "internal/testutil"
+
+o = json.parse(data: bytes(v: "{\"a\": 1, \"b\": {\"x\": [1.5, 2]}, \"c\": \"string\"}"))
+(o.a == 1 and o.b.x[1] == 2.0 and o.c == "string") or testutil.fail()
+json.parse(data: "[true, false]")[1] == false or testutil.fail()
+`
+	ctx := dependenciestest.Default().Inject(context.Background())
+	if _, _, err := runtime.Eval(ctx, script); err != nil {
+		t.Fatal("evaluation of json.parse failed: ", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		want    values.Value
+		wantErr error
+	}{
+		{
+			name: "record",
+			data: `{"a":"x","b":1,"c":true,"d":1.5,"e":{"f":[1,2]}}`,
+			want: values.NewObjectWithValues(map[string]values.Value{
+				"a": values.NewString("x"),
+				"b": values.NewInt(1),
+				"c": values.NewBool(true),
+				"d": values.NewFloat(1.5),
+				"e": values.NewObjectWithValues(map[string]values.Value{
+					"f": values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicInt), []values.Value{
+						values.NewInt(1),
+						values.NewInt(2),
+					}),
+				}),
+			}),
+		},
+		{
+			name: "numbers",
+			data: `[1, 2.5, 1e3, 9223372036854775808]`,
+			want: values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicFloat), []values.Value{
+				values.NewFloat(1),
+				values.NewFloat(2.5),
+				values.NewFloat(1000),
+				values.NewFloat(9223372036854775808),
+			}),
+		},
+		{
+			name: "bare int",
+			data: `-42`,
+			want: values.NewInt(-42),
+		},
+		{
+			name: "bare string",
+			data: ` "a" `,
+			want: values.NewString("a"),
+		},
+		{
+			name:    "mixed array",
+			data:    `[1,false]`,
+			wantErr: errors.New(codes.Invalid, "array values must all be the same type, found int and bool"),
+		},
+		{
+			name:    "invalid",
+			data:    `{"a":`,
+			wantErr: errors.New(codes.Invalid, "failed to parse JSON: unexpected EOF"),
+		},
+		{
+			name:    "trailing data",
+			data:    `{} {}`,
+			wantErr: errors.New(codes.Invalid, "failed to parse JSON: unexpected data after the JSON value"),
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := json.Parse([]byte(tc.data))
+			if tc.wantErr != nil {
+				if err == nil {
+					t.Fatalf("expected error: %s", tc.wantErr)
+				}
+				if want, got := tc.wantErr.Error(), err.Error(); want != got {
+					t.Fatalf("unexpected error -want/+got:\n\t- %s\n\t+ %s", want, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tc.want.Equal(got) {
+				t.Errorf("unequal values\nwant: %v\ngot:  %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestParseNull(t *testing.T) {
+	got, err := json.Parse([]byte(`{"a":null,"b":[1,null]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, ok := got.Object().Get("a")
+	if !ok || !a.IsNull() {
+		t.Errorf("expected property a to be null, got %v", a)
+	}
+	b, _ := got.Object().Get("b")
+	if want, got := semantic.NewArrayType(semantic.BasicInt), b.Type(); !want.Equal(got) {
+		t.Fatalf("unexpected type -want/+got: %v/%v", want, got)
+	}
+	if v := b.Array().Get(1); !v.IsNull() {
+		t.Errorf("expected the second element to be null, got %v", v)
+	}
+}
+
+func TestParseUntypedArray(t *testing.T) {
+	for _, data := range []string{`[]`, `[null, null]`} {
+		got, err := json.Parse([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, got := semantic.NewArrayType(semantic.BasicString), got.Type(); !want.Equal(got) {
+			t.Errorf("unexpected type of %s -want/+got: %v/%v", data, want, got)
+		}
+		got.Array().Range(func(i int, v values.Value) {
+			if !v.IsNull() || v.Type().Nature() != semantic.String {
+				t.Errorf("expected element %d of %s to be a null string, got %v", i, data, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	protocol "github.com/influxdata/line-protocol"
+)
+
+// The formats of the messages.
+const (
+	FormatLineProtocol = "lineprotocol"
+	FormatJSON         = "json"
+	FormatAvro         = "avro"
+)
+
+// messageEncoder encodes the rows of the tables to the values of the messages.
+type messageEncoder interface {
+	encode(cr flux.ColReader, i int) ([]byte, error)
+}
+
+func newMessageEncoder(spec *ToKafkaOpSpec, cols []flux.ColMeta) (messageEncoder, error) {
+	switch spec.Format {
+	case FormatLineProtocol, "":
+		return newLineProtocolEncoder(spec, cols)
+	case FormatJSON:
+		return jsonEncoder{}, nil
+	case FormatAvro:
+		return avroEncoder{schemaID: spec.AvroSchemaID}, nil
+	default:
+		return nil, errors.Newf(codes.Invalid, "unsupported kafka message format %q", spec.Format)
+	}
+}
+
+// lineProtocolEncoder encodes each row to a line of line protocol.
+type lineProtocolEncoder struct {
+	buf                bytes.Buffer
+	e                  *protocol.Encoder
+	m                  toKafkaMetric
+	timeColLabel       string
+	measurementNameCol string
+	isTag              []bool
+	isValue            []bool
+}
+
+func newLineProtocolEncoder(spec *ToKafkaOpSpec, cols []flux.ColMeta) (*lineProtocolEncoder, error) {
+	enc := &lineProtocolEncoder{
+		timeColLabel: spec.TimeColumn,
+		m:            toKafkaMetric{name: spec.Name},
+	}
+	enc.e = protocol.NewEncoder(&enc.buf)
+	enc.e.FailOnFieldErr(true)
+	enc.e.SetFieldSortOrder(protocol.SortFields)
+
+	timeColIdx := execute.ColIdx(enc.timeColLabel, cols)
+	if timeColIdx < 0 {
+		return nil, errors.New(codes.FailedPrecondition, "could not get time column")
+	}
+	if typ := cols[timeColIdx].Type; typ != flux.TTime {
+		return nil, errors.Newf(codes.FailedPrecondition, "column %s is not of type %s", enc.timeColLabel, typ)
+	}
+	if spec.Name == "" {
+		enc.measurementNameCol = spec.NameColumn
+	}
+	// check if each col is a tag or value and cache this value for the loop
+	enc.isTag = make([]bool, len(cols))
+	enc.isValue = make([]bool, len(cols))
+	for i, col := range cols {
+		enc.isValue[i] = containsSorted(spec.ValueColumns, col.Label)
+		enc.isTag[i] = containsSorted(spec.TagColumns, col.Label)
+	}
+	return enc, nil
+}
+
+func containsSorted(labels []string, label string) bool {
+	i := sort.SearchStrings(labels, label)
+	return i < len(labels) && labels[i] == label
+}
+
+func (enc *lineProtocolEncoder) encode(cr flux.ColReader, i int) ([]byte, error) {
+	m := &enc.m
+	m.truncateTagsAndFields()
+	for j, col := range cr.Cols() {
+		switch {
+		case col.Label == enc.timeColLabel:
+			m.t = values.Time(cr.Times(j).Value(i)).Time()
+		case enc.measurementNameCol != "" && enc.measurementNameCol == col.Label:
+			if col.Type != flux.TString {
+				return nil, errors.New(codes.FailedPrecondition, "invalid type for measurement column")
+			}
+			m.name = cr.Strings(j).Value(i)
+		case enc.isTag[j]:
+			if col.Type != flux.TString {
+				return nil, errors.New(codes.FailedPrecondition, "invalid type for measurement column")
+			}
+			m.tags = append(m.tags, &protocol.Tag{Key: col.Label, Value: cr.Strings(j).Value(i)})
+		case enc.isValue[j]:
+			switch col.Type {
+			case flux.TFloat:
+				m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: cr.Floats(j).Value(i)})
+			case flux.TInt:
+				m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: cr.Ints(j).Value(i)})
+			case flux.TUInt:
+				m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: cr.UInts(j).Value(i)})
+			case flux.TString:
+				m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: cr.Strings(j).Value(i)})
+			case flux.TTime:
+				m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: values.Time(cr.Times(j).Value(i))})
+			case flux.TBool:
+				m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: cr.Bools(j).Value(i)})
+			default:
+				return nil, errors.Newf(codes.FailedPrecondition, "invalid type for column %s", col.Label)
+			}
+		}
+	}
+	enc.buf.Reset()
+	if _, err := enc.e.Encode(m); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), bytes.TrimSuffix(enc.buf.Bytes(), []byte("\n"))...), nil
+}
+
+// jsonEncoder encodes each row to a JSON object with a property per column.
+// Times are RFC3339 strings and null values are null.
+type jsonEncoder struct{}
+
+func (jsonEncoder) encode(cr flux.ColReader, i int) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for j, col := range cr.Cols() {
+		if j > 0 {
+			buf.WriteByte(',')
+		}
+		label, err := json.Marshal(col.Label)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(label)
+		buf.WriteByte(':')
+
+		v := execute.ValueForRow(cr, i, j)
+		if v.IsNull() {
+			buf.WriteString("null")
+			continue
+		}
+		var data []byte
+		switch col.Type {
+		case flux.TFloat:
+			f := v.Float()
+			if math.IsNaN(f) || math.IsInf(f, 0) {
+				return nil, errors.Newf(codes.FailedPrecondition, "cannot encode the float %v of column %s to JSON", f, col.Label)
+			}
+			data = strconv.AppendFloat(nil, f, 'g', -1, 64)
+		case flux.TInt:
+			data = strconv.AppendInt(nil, v.Int(), 10)
+		case flux.TUInt:
+			data = strconv.AppendUint(nil, v.UInt(), 10)
+		case flux.TBool:
+			data = strconv.AppendBool(nil, v.Bool())
+		case flux.TString:
+			data, err = json.Marshal(v.Str())
+		case flux.TTime:
+			data, err = json.Marshal(v.Time().Time().Format(time.RFC3339Nano))
+		default:
+			return nil, errors.Newf(codes.FailedPrecondition, "invalid type for column %s", col.Label)
+		}
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(data)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// avroEncoder encodes each row to the binary encoding of an Avro record
+// with a nullable field per column, in the order of the columns.
+// The fields are unions of null and long, double, string or boolean.
+// Times are longs with the timestamp-micros logical type.
+//
+// The message starts with the header of the Confluent wire format
+// when the ID of the schema in the schema registry is set.
+type avroEncoder struct {
+	schemaID int64
+}
+
+func (enc avroEncoder) encode(cr flux.ColReader, i int) ([]byte, error) {
+	var buf []byte
+	if enc.schemaID > 0 {
+		buf = append(buf, 0)
+		buf = append(buf, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(buf[1:], uint32(enc.schemaID))
+	}
+	for j, col := range cr.Cols() {
+		v := execute.ValueForRow(cr, i, j)
+		if v.IsNull() {
+			// the index of the null branch of the union
+			buf = appendAvroLong(buf, 0)
+			continue
+		}
+		buf = appendAvroLong(buf, 1)
+		switch col.Type {
+		case flux.TFloat:
+			buf = append(buf, 0, 0, 0, 0, 0, 0, 0, 0)
+			binary.LittleEndian.PutUint64(buf[len(buf)-8:], math.Float64bits(v.Float()))
+		case flux.TInt:
+			buf = appendAvroLong(buf, v.Int())
+		case flux.TUInt:
+			u := v.UInt()
+			if u > math.MaxInt64 {
+				return nil, errors.Newf(codes.FailedPrecondition, "the unsigned integer %d of column %s overflows an avro long", u, col.Label)
+			}
+			buf = appendAvroLong(buf, int64(u))
+		case flux.TBool:
+			if v.Bool() {
+				buf = append(buf, 1)
+			} else {
+				buf = append(buf, 0)
+			}
+		case flux.TString:
+			s := v.Str()
+			buf = appendAvroLong(buf, int64(len(s)))
+			buf = append(buf, s...)
+		case flux.TTime:
+			buf = appendAvroLong(buf, int64(v.Time())/int64(time.Microsecond))
+		default:
+			return nil, errors.Newf(codes.FailedPrecondition, "invalid type for column %s", col.Label)
+		}
+	}
+	return buf, nil
+}
+
+// appendAvroLong appends the zig-zag variable length encoding of a long.
+func appendAvroLong(buf []byte, n int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutVarint(b[:], n)]...)
+}
+
+// keyString returns the representation of a value in the keys of the messages.
+func keyString(v values.Value) string {
+	if v.IsNull() {
+		return ""
+	}
+	switch v.Type().Nature() {
+	case semantic.String:
+		return v.Str()
+	case semantic.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case semantic.UInt:
+		return strconv.FormatUint(v.UInt(), 10)
+	case semantic.Float:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case semantic.Bool:
+		return strconv.FormatBool(v.Bool())
+	case semantic.Time:
+		return v.Time().Time().Format(time.RFC3339Nano)
+	default:
+		return ""
+	}
+}
@@ -1,24 +1,24 @@
 package kafka
 
 import (
-	"bufio"
 	"context"
 	"encoding/binary"
 	"io"
+	"math"
 	"net/url"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/cespare/xxhash/v2"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
+	kafkadeps "github.com/influxdata/flux/dependencies/kafka"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
-	"github.com/influxdata/flux/internal/pkg/syncutil"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/semantic"
-	"github.com/influxdata/flux/values"
 	protocol "github.com/influxdata/line-protocol"
 	"github.com/segmentio/kafka-go"
 )
@@ -38,6 +38,17 @@ type ToKafkaOpSpec struct {
 	TagColumns   []string `json:"tagColumns"`
 	ValueColumns []string `json:"valueColumns"`
 	MsgBufSize   int      `json:"msgBufferSize"` // the maximim number of messages to buffer before sending to kafka, the library we use defaults to 100
+
+	Format          string   `json:"format,omitempty"` // the format of the messages, line protocol if it is empty
+	AvroSchemaID    int64    `json:"avroSchemaID,omitempty"`
+	KeyColumns      []string `json:"keyColumns,omitempty"`
+	PartitionColumn string   `json:"partitionColumn,omitempty"`
+	TLS             bool     `json:"tls,omitempty"`
+	SASLMechanism   string   `json:"saslMechanism,omitempty"`
+	Username        string   `json:"username,omitempty"`
+	// Password is never encoded so that it is not
+	// exposed when the spec is logged or stored.
+	Password string `json:"-"`
 }
 
 func init() {
@@ -133,9 +144,9 @@ func (o *ToKafkaOpSpec) ReadArgs(args flux.Arguments) error {
 		o.ValueColumns = append(o.ValueColumns, execute.DefaultValueColLabel)
 	} else {
 		for i := 0; i < valueColumns.Len(); i++ {
-			o.TagColumns = append(o.ValueColumns, valueColumns.Get(i).Str())
+			o.ValueColumns = append(o.ValueColumns, valueColumns.Get(i).Str())
 		}
-		sort.Strings(o.TagColumns)
+		sort.Strings(o.ValueColumns)
 	}
 
 	msgBufSize, ok, err := args.GetInt("msgBufferSize")
@@ -147,7 +158,53 @@ func (o *ToKafkaOpSpec) ReadArgs(args flux.Arguments) error {
 		o.MsgBufSize = 0 // so the library will set it  to the default
 	}
 
-	return err
+	if o.Format, _, err = args.GetString("format"); err != nil {
+		return err
+	}
+	switch o.Format {
+	case "", FormatLineProtocol, FormatJSON, FormatAvro:
+	default:
+		return errors.Newf(codes.Invalid, "invalid format %q, must be one of %q, %q or %q", o.Format, FormatLineProtocol, FormatJSON, FormatAvro)
+	}
+	if o.AvroSchemaID, ok, err = args.GetInt("avroSchemaID"); err != nil {
+		return err
+	} else if ok && (o.Format != FormatAvro || o.AvroSchemaID <= 0 || o.AvroSchemaID > math.MaxUint32) {
+		return errors.New(codes.Invalid, "avroSchemaID must be a positive schema ID and requires the avro format")
+	}
+	keyColumns, ok, err := args.GetArray("keyColumns", semantic.String)
+	if err != nil {
+		return err
+	}
+	if ok {
+		for i := 0; i < keyColumns.Len(); i++ {
+			o.KeyColumns = append(o.KeyColumns, keyColumns.Get(i).Str())
+		}
+	}
+	if o.PartitionColumn, _, err = args.GetString("partitionColumn"); err != nil {
+		return err
+	}
+	if o.PartitionColumn != "" && o.Balancer != "" {
+		return errors.New(codes.Invalid, "balancer and partitionColumn cannot both be set")
+	}
+
+	if o.TLS, _, err = args.GetBool("tls"); err != nil {
+		return err
+	}
+	if o.SASLMechanism, _, err = args.GetString("saslMechanism"); err != nil {
+		return err
+	}
+	if o.Username, _, err = args.GetString("username"); err != nil {
+		return err
+	}
+	if o.Password, _, err = args.GetString("password"); err != nil {
+		return err
+	}
+	if o.SASLMechanism != "" {
+		if _, err := kafkadeps.NewSASLMechanism(o.SASLMechanism, o.Username, o.Password); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 func createToKafkaOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
 	if err := a.AddParentFromArgs(args); err != nil {
@@ -185,26 +242,51 @@ func (o *ToKafkaProcedureSpec) Copy() plan.ProcedureSpec {
 			TimeColumn:   s.TimeColumn,
 			TagColumns:   append([]string(nil), s.TagColumns...),
 			ValueColumns: append([]string(nil), s.ValueColumns...),
+			MsgBufSize:   s.MsgBufSize,
+
+			Format:          s.Format,
+			AvroSchemaID:    s.AvroSchemaID,
+			KeyColumns:      append([]string(nil), s.KeyColumns...),
+			PartitionColumn: s.PartitionColumn,
+			TLS:             s.TLS,
+			SASLMechanism:   s.SASLMechanism,
+			Username:        s.Username,
+			Password:        s.Password,
 		},
 	}
+	res.balancer = newBalancer(res.Spec)
+	return res
+}
+
+func newBalancer(s *ToKafkaOpSpec) kafka.Balancer {
+	if s.PartitionColumn != "" {
+		return partitionBalancer{}
+	}
 	switch s.Balancer {
 	case "hash", "": //hash is default for compatibility with enterprise
-		res.balancer = &kafka.Hash{}
-
+		return &kafka.Hash{}
 	case "round-robin":
-		res.balancer = &kafka.RoundRobin{}
-
+		return &kafka.RoundRobin{}
 	case "least-bytes":
-		res.balancer = &kafka.LeastBytes{}
+		return &kafka.LeastBytes{}
 	}
-	return res
+	return nil
+}
+
+// partitionBalancer writes the messages to the partitions set by the partition column.
+// The partition of each message is its value modulo the number of partitions of the topic.
+type partitionBalancer struct{}
+
+func (partitionBalancer) Balance(msg kafka.Message, partitions ...int) int {
+	return partitions[msg.Partition%len(partitions)]
 }
+
 func newToKafkaProcedure(qs flux.OperationSpec, a plan.Administration) (plan.ProcedureSpec, error) {
 	spec, ok := qs.(*ToKafkaOpSpec)
 	if !ok && spec != nil {
 		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
 	}
-	return &ToKafkaProcedureSpec{Spec: spec}, nil
+	return &ToKafkaProcedureSpec{Spec: spec, balancer: newBalancer(spec)}, nil
 }
 func createToKafkaTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
 	s, ok := spec.(*ToKafkaProcedureSpec)
@@ -215,14 +297,27 @@ func createToKafkaTransformation(id execute.DatasetID, mode execute.Accumulation
 	d := execute.NewDataset(id, mode, cache)
 	deps := flux.GetDependencies(a.Context())
 	t, err := NewToKafkaTransformation(d, deps, cache, s)
-	return t, d, err
+	if err != nil {
+		return nil, nil, err
+	}
+	t.dialer, err = kafkadeps.GetDialer(a.Context()).Dialer(a.Context(), kafkadeps.Options{
+		TLS:           s.Spec.TLS,
+		SASLMechanism: s.Spec.SASLMechanism,
+		Username:      s.Spec.Username,
+		Password:      s.Spec.Password,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, d, nil
 }
 
 type ToKafkaTransformation struct {
 	execute.ExecutionNode
-	d     execute.Dataset
-	cache execute.TableBuilderCache
-	spec  *ToKafkaProcedureSpec
+	d      execute.Dataset
+	cache  execute.TableBuilderCache
+	spec   *ToKafkaProcedureSpec
+	dialer *kafka.Dialer
 }
 
 func (t *ToKafkaTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
@@ -273,20 +368,54 @@ func (m *toKafkaMetric) Time() time.Time {
 	return m.t
 }
 
-type idxType struct {
-	Idx  int
-	Type flux.ColType
-}
+// defaultBatchSize is the number of messages written at once
+// when the size of the message buffer is not set.
+const defaultBatchSize = 100
 
 func (t *ToKafkaTransformation) Process(id execute.DatasetID, tbl flux.Table) (err error) {
+	spec := t.spec.Spec
+	batchSize := spec.MsgBufSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	cols := tbl.Cols()
+	enc, err := newMessageEncoder(spec, cols)
+	if err != nil {
+		return err
+	}
+	keyIdxs := make([]int, len(spec.KeyColumns))
+	for i, label := range spec.KeyColumns {
+		if keyIdxs[i] = execute.ColIdx(label, cols); keyIdxs[i] < 0 {
+			return errors.Newf(codes.FailedPrecondition, "could not get key column %s", label)
+		}
+	}
+	partitionIdx := -1
+	if spec.PartitionColumn != "" {
+		partitionIdx = execute.ColIdx(spec.PartitionColumn, cols)
+		if partitionIdx < 0 {
+			return errors.Newf(codes.FailedPrecondition, "could not get partition column %s", spec.PartitionColumn)
+		}
+		if typ := cols[partitionIdx].Type; typ != flux.TInt && typ != flux.TUInt {
+			return errors.Newf(codes.FailedPrecondition, "partition column %s is not an integer, got %s", spec.PartitionColumn, typ)
+		}
+	}
+
+	builder, new := t.cache.TableBuilder(tbl.Key())
+	if new {
+		if err := execute.AddTableCols(tbl, builder); err != nil {
+			return err
+		}
+	}
+
 	w := DefaultKafkaWriterFactory(kafka.WriterConfig{
-		Brokers:       t.spec.Spec.Brokers,
-		Topic:         t.spec.Spec.Topic,
+		Brokers:       spec.Brokers,
+		Topic:         spec.Topic,
+		Dialer:        t.dialer,
 		Balancer:      t.spec.balancer,
-		BatchSize:     t.spec.Spec.MsgBufSize,
-		QueueCapacity: t.spec.Spec.MsgBufSize,
+		BatchSize:     spec.MsgBufSize,
+		QueueCapacity: spec.MsgBufSize,
 	})
-
 	defer func() {
 		err2 := w.Close()
 		// don't overwrite current error
@@ -299,132 +428,65 @@ func (t *ToKafkaTransformation) Process(id execute.DatasetID, tbl flux.Table) (e
 			return
 		}
 	}()
-	pr, pw := io.Pipe() // TODO: replce the pipe with something faster
-	// I'd like a linereader in line-protocol
-	m := &toKafkaMetric{}
-	e := protocol.NewEncoder(pw)
-	e.FailOnFieldErr(true)
-	e.SetFieldSortOrder(protocol.SortFields)
-	cols := tbl.Cols()
-	labels := make(map[string]idxType, len(cols))
-	for i, col := range cols {
-		labels[col.Label] = idxType{Idx: i, Type: col.Type}
-	}
-	// do time
-	timeColLabel := t.spec.Spec.TimeColumn
-	timeColIdx, ok := labels[timeColLabel]
-	if !ok {
-		return errors.New(codes.FailedPrecondition, "could not get time column")
-	}
-	if timeColIdx.Type != flux.TTime {
-		return errors.Newf(codes.FailedPrecondition, "column %s is not of type %s", timeColLabel, timeColIdx.Type)
-	}
-	var measurementNameCol string
-	if t.spec.Spec.Name == "" {
-		measurementNameCol = t.spec.Spec.NameColumn
-	}
-	// check if each col is a tag or value and cache this value for the loop
-	colMetadatas := tbl.Cols()
-	isTag := make([]bool, len(colMetadatas))
-	isValue := make([]bool, len(colMetadatas))
-	for i, col := range colMetadatas {
-		isValue[i] = sort.SearchStrings(t.spec.Spec.ValueColumns, col.Label) < len(t.spec.Spec.ValueColumns) && t.spec.Spec.ValueColumns[sort.SearchStrings(t.spec.Spec.ValueColumns, col.Label)] == col.Label
-		isTag[i] = sort.SearchStrings(t.spec.Spec.TagColumns, col.Label) < len(t.spec.Spec.TagColumns) && t.spec.Spec.TagColumns[sort.SearchStrings(t.spec.Spec.TagColumns, col.Label)] == col.Label
-	}
-	m.name = t.spec.Spec.Name
 
-	builder, new := t.cache.TableBuilder(tbl.Key())
-	if new {
-		if err := execute.AddTableCols(tbl, builder); err != nil {
-			return err
-		}
-	}
-
-	var wg syncutil.WaitGroup
-	wg.Do(func() error {
-		if err := tbl.Do(func(er flux.ColReader) error {
-			l := er.Len()
-			for i := 0; i < l; i++ {
-				m.truncateTagsAndFields()
-				for j, col := range er.Cols() {
-					switch {
-					case col.Label == timeColLabel:
-						m.t = values.Time(er.Times(j).Value(i)).Time()
-					case measurementNameCol != "" && measurementNameCol == col.Label:
-						if col.Type != flux.TString {
-							return errors.New(codes.FailedPrecondition, "invalid type for measurement column")
-						}
-						m.name = er.Strings(j).Value(i)
-					case isTag[j]:
-						if col.Type != flux.TString {
-							return errors.New(codes.FailedPrecondition, "invalid type for measurement column")
-						}
-						m.tags = append(m.tags, &protocol.Tag{Key: col.Label, Value: er.Strings(j).Value(i)})
-					case isValue[j]:
-						switch col.Type {
-						case flux.TFloat:
-							m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: er.Floats(j).Value(i)})
-						case flux.TInt:
-							m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: er.Ints(j).Value(i)})
-						case flux.TUInt:
-							m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: er.UInts(j).Value(i)})
-						case flux.TString:
-							m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: er.Strings(j).Value(i)})
-						case flux.TTime:
-							m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: values.Time(er.Times(j).Value(i))})
-						case flux.TBool:
-							m.fields = append(m.fields, &protocol.Field{Key: col.Label, Value: er.Bools(j).Value(i)})
-						default:
-							return errors.Newf(codes.FailedPrecondition, "invalid type for column %s", col.Label)
-						}
-					}
-				}
-				_, err := e.Encode(m)
-				if err != nil {
-					return err
+	msgs := make([]kafka.Message, 0, batchSize)
+	keyParts := make([]string, len(keyIdxs))
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		for i, l := 0, cr.Len(); i < l; i++ {
+			v, err := enc.encode(cr, i)
+			if err != nil {
+				return err
+			}
+			msg := kafka.Message{Value: v}
+			if len(keyIdxs) > 0 {
+				for k, j := range keyIdxs {
+					keyParts[k] = keyString(execute.ValueForRow(cr, i, j))
 				}
-				if err := execute.AppendRecord(i, er, builder); err != nil {
+				msg.Key = []byte(strings.Join(keyParts, ","))
+			} else {
+				msg.Key = make([]byte, 8)
+				binary.LittleEndian.PutUint64(msg.Key, xxhash.Sum64(v))
+			}
+			if partitionIdx >= 0 {
+				if msg.Partition, err = partitionValue(cr, i, partitionIdx); err != nil {
 					return err
 				}
 			}
-			return nil
-		}); err != nil {
-			_ = pw.Close()
-			return err
-		}
-		return pw.Close()
-	})
-	// write the data to kafka
-	{
-		scan := bufio.NewScanner(pr)
-		msgBuf := make([]kafka.Message, 128)
-		i := 0
-		// todo, make this a little more async
-		for scan.Scan() {
-			v := append([]byte(nil), scan.Bytes()...) // we do this since scan.Bytes()'s result can be overwritten by calls to Scan()
-			if cap(msgBuf[i].Key) != 8 {
-				msgBuf[i].Key = make([]byte, 8)
-			}
-			binary.LittleEndian.PutUint64(msgBuf[i].Key, xxhash.Sum64(v))
-			msgBuf[i].Value = v
-			if i == t.spec.Spec.MsgBufSize-1 {
-				if err = w.WriteMessages(context.Background(), msgBuf...); err != nil {
+			msgs = append(msgs, msg)
+			if len(msgs) == batchSize {
+				if err := w.WriteMessages(context.Background(), msgs...); err != nil {
 					return err
 				}
-				msgBuf = msgBuf[:0]
-				i = 0
+				msgs = make([]kafka.Message, 0, batchSize)
+			}
+			if err := execute.AppendRecord(i, cr, builder); err != nil {
+				return err
 			}
-			i++
-		}
-		// send the remainder of the messages
-		if len(msgBuf) > 0 {
-			err = w.WriteMessages(context.Background(), msgBuf[:i]...)
 		}
-	}
-	if err := wg.Wait(); err != nil {
+		return nil
+	}); err != nil {
 		return err
 	}
-	return err
+	// send the remainder of the messages
+	if len(msgs) > 0 {
+		return w.WriteMessages(context.Background(), msgs...)
+	}
+	return nil
+}
+
+// partitionValue returns the partition of a row, which is a non negative integer.
+func partitionValue(cr flux.ColReader, i, j int) (int, error) {
+	v := execute.ValueForRow(cr, i, j)
+	if v.IsNull() {
+		return 0, errors.Newf(codes.FailedPrecondition, "null value in partition column %s", cr.Cols()[j].Label)
+	}
+	if v.Type().Nature() == semantic.UInt {
+		return int(v.UInt() % math.MaxInt32), nil
+	}
+	if p := v.Int(); p >= 0 {
+		return int(p % math.MaxInt32), nil
+	}
+	return 0, errors.Newf(codes.FailedPrecondition, "negative value in partition column %s", cr.Cols()[j].Label)
 }
 
 func (t *ToKafkaTransformation) UpdateWatermark(id execute.DatasetID, pt execute.Time) error {
@@ -2,6 +2,8 @@ package kafka_test
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"sync"
 	"testing"
 
@@ -61,6 +63,23 @@ func TestToKafka_NewQuery(t *testing.T) {
 	}
 }
 
+func TestToKafkaOpSpec_Password(t *testing.T) {
+	spec := &fkafka.ToKafkaOpSpec{
+		Brokers:       []string{"brokerurl:8989"},
+		Topic:         "totallynotfaketopic",
+		SASLMechanism: "plain",
+		Username:      "user",
+		Password:      "secret",
+	}
+	data, err := json.Marshal(spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("expected the password not to be encoded, got %s", data)
+	}
+}
+
 type kafkaMock struct {
 	sync.Mutex
 	data [][]kafka.Message
@@ -593,3 +612,98 @@ func TestToKafka_NewTransformation(t *testing.T) {
 	}
 	test.Run(t)
 }
+
+func TestToKafka_Process_Formats(t *testing.T) {
+	data := &kafkaMock{}
+	fkafka.DefaultKafkaWriterFactory = func(_ kafka.WriterConfig) fkafka.KafkaWriter {
+		return data
+	}
+
+	input := func() *executetest.Table {
+		return &executetest.Table{
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "host", Type: flux.TString},
+				{Label: "shard", Type: flux.TInt},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1000), 2.0, "a", int64(1)},
+				{execute.Time(2000), nil, "b", int64(4)},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name string
+		spec *fkafka.ToKafkaOpSpec
+		want [][]kafka.Message
+	}{
+		{
+			name: "json with key columns",
+			spec: &fkafka.ToKafkaOpSpec{
+				Format:     fkafka.FormatJSON,
+				KeyColumns: []string{"host", "shard"},
+			},
+			want: [][]kafka.Message{{
+				{Key: []byte("a,1"), Value: []byte(`{"_time":"1970-01-01T00:00:00.000001Z","_value":2,"host":"a","shard":1}`)},
+				{Key: []byte("b,4"), Value: []byte(`{"_time":"1970-01-01T00:00:00.000002Z","_value":null,"host":"b","shard":4}`)},
+			}},
+		},
+		{
+			name: "avro with partition column",
+			spec: &fkafka.ToKafkaOpSpec{
+				Format:          fkafka.FormatAvro,
+				AvroSchemaID:    7,
+				KeyColumns:      []string{"host"},
+				PartitionColumn: "shard",
+			},
+			want: [][]kafka.Message{{
+				{
+					Key:       []byte("a"),
+					Partition: 1,
+					Value: []byte{
+						0x00, 0x00, 0x00, 0x00, 0x07, // header with the schema ID
+						0x02, 0x02, // _time
+						0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x40, // _value
+						0x02, 0x02, 'a', // host
+						0x02, 0x02, // shard
+					},
+				},
+				{
+					Key:       []byte("b"),
+					Partition: 4,
+					Value: []byte{
+						0x00, 0x00, 0x00, 0x00, 0x07,
+						0x02, 0x04,
+						0x00,
+						0x02, 0x02, 'b',
+						0x02, 0x08,
+					},
+				},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			tc.spec.Brokers = []string{"brokerurl:8989"}
+			tc.spec.Topic = "totallynotfaketopic"
+			executetest.ProcessTestHelper(
+				t,
+				[]flux.Table{input()},
+				[]*executetest.Table{input()},
+				nil,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					t, _ := fkafka.NewToKafkaTransformation(d, dependenciestest.Default(), c, &fkafka.ToKafkaProcedureSpec{Spec: tc.spec})
+					return t
+				},
+			)
+			if !cmp.Equal(tc.want, data.data) {
+				t.Errorf("unexpected messages -want/+got:\n%s", cmp.Diff(tc.want, data.data))
+			}
+			data.reset()
+		})
+	}
+}
@@ -30,6 +30,7 @@ import (
 	_ "github.com/influxdata/flux/stdlib/dict"
 	_ "github.com/influxdata/flux/stdlib/experimental"
 	_ "github.com/influxdata/flux/stdlib/experimental/aggregate"
+	_ "github.com/influxdata/flux/stdlib/experimental/anomaly"
 	_ "github.com/influxdata/flux/stdlib/experimental/array"
 	_ "github.com/influxdata/flux/stdlib/experimental/bigtable"
 	_ "github.com/influxdata/flux/stdlib/experimental/bitwise"
@@ -37,15 +38,22 @@ import (
 	_ "github.com/influxdata/flux/stdlib/experimental/geo"
 	_ "github.com/influxdata/flux/stdlib/experimental/http"
 	_ "github.com/influxdata/flux/stdlib/experimental/influxdb"
+	_ "github.com/influxdata/flux/stdlib/experimental/join"
 	_ "github.com/influxdata/flux/stdlib/experimental/json"
+	_ "github.com/influxdata/flux/stdlib/experimental/kafka"
 	_ "github.com/influxdata/flux/stdlib/experimental/mqtt"
+	_ "github.com/influxdata/flux/stdlib/experimental/oauth2"
+	_ "github.com/influxdata/flux/stdlib/experimental/objectstore"
 	_ "github.com/influxdata/flux/stdlib/experimental/oee"
 	_ "github.com/influxdata/flux/stdlib/experimental/prometheus"
 	_ "github.com/influxdata/flux/stdlib/experimental/query"
 	_ "github.com/influxdata/flux/stdlib/experimental/record"
 	_ "github.com/influxdata/flux/stdlib/experimental/table"
 	_ "github.com/influxdata/flux/stdlib/experimental/usage"
+	_ "github.com/influxdata/flux/stdlib/experimental/websocket"
+	_ "github.com/influxdata/flux/stdlib/experimental/xml"
 	_ "github.com/influxdata/flux/stdlib/generate"
+	_ "github.com/influxdata/flux/stdlib/hash"
 	_ "github.com/influxdata/flux/stdlib/http"
 	_ "github.com/influxdata/flux/stdlib/influxdata/influxdb"
 	_ "github.com/influxdata/flux/stdlib/influxdata/influxdb/monitor"
@@ -70,6 +78,7 @@ import (
 	_ "github.com/influxdata/flux/stdlib/pushbullet"
 	_ "github.com/influxdata/flux/stdlib/regexp"
 	_ "github.com/influxdata/flux/stdlib/runtime"
+	_ "github.com/influxdata/flux/stdlib/sample"
 	_ "github.com/influxdata/flux/stdlib/sampledata"
 	_ "github.com/influxdata/flux/stdlib/slack"
 	_ "github.com/influxdata/flux/stdlib/socket"
@@ -80,4 +89,6 @@ import (
 	_ "github.com/influxdata/flux/stdlib/testing/expect"
 	_ "github.com/influxdata/flux/stdlib/timezone"
 	_ "github.com/influxdata/flux/stdlib/universe"
+	_ "github.com/influxdata/flux/stdlib/uuid"
+	_ "github.com/influxdata/flux/stdlib/yaml"
 )
@@ -0,0 +1,16 @@
+package planner
+
+import (
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+)
+
+const pkgpath = "planner"
+
+func init() {
+	for _, name := range []string{"disableLogicalRules", "disablePhysicalRules", "disabledRules", "featureFlags"} {
+		runtime.DeclareOption(pkgpath, name, runtime.OptionSpec{
+			Type: semantic.NewArrayType(semantic.BasicString),
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package profiler
+
+import (
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const pkgpath = "profiler"
+
+func init() {
+	runtime.DeclareOption(pkgpath, "enabledProfilers", runtime.OptionSpec{
+		Type:     semantic.NewArrayType(semantic.BasicString),
+		Validate: validateProfilers,
+	})
+}
+
+// validateProfilers returns an error if a profiler is unknown.
+// The empty name is the default value of the option.
+func validateProfilers(v values.Value) error {
+	var err error
+	v.Array().Range(func(i int, name values.Value) {
+		if err != nil || name.Str() == "" {
+			return
+		}
+		if _, ok := execute.AllProfilers[name.Str()]; !ok {
+			err = errors.Newf(codes.Invalid, "unknown profiler %q", name.Str())
+		}
+	})
+	return err
+}
@@ -3,6 +3,7 @@ package regexp
 import (
 	"context"
 	"regexp"
+	"strconv"
 
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/internal/errors"
@@ -153,6 +154,61 @@ func init() {
 			},
 			false,
 		),
+		"findStringSubmatch": values.NewFunction(
+			"findStringSubmatch",
+			runtime.MustLookupBuiltinType("regexp", "findStringSubmatch"),
+			func(ctx context.Context, args values.Object) (values.Value, error) {
+				v, ok := args.Get("v")
+				r, okk := args.Get("r")
+				if !ok || !okk {
+					return nil, errors.New(codes.Invalid, "missing argument")
+				}
+
+				if !v.IsNull() && !r.IsNull() && v.Type().Nature() == semantic.String && r.Type().Nature() == semantic.Regexp {
+					value := r.Regexp().FindStringSubmatch(v.Str())
+					arr := values.NewArray(semantic.NewArrayType(semantic.BasicString))
+					for _, z := range value {
+						arr.Append(values.NewString(z))
+					}
+					return arr, nil
+				}
+				return nil, errors.Newf(codes.Invalid, "cannot execute function containing argument r of type %v value %v and argument v of type %v value %v", r.Type().Nature(), r, v.Type().Nature(), v)
+			},
+			false,
+		),
+		"extract": values.NewFunction(
+			"extract",
+			runtime.MustLookupBuiltinType("regexp", "extract"),
+			func(ctx context.Context, args values.Object) (values.Value, error) {
+				v, ok := args.Get("v")
+				r, okk := args.Get("r")
+				if !ok || !okk {
+					return nil, errors.New(codes.Invalid, "missing argument")
+				}
+
+				if !v.IsNull() && !r.IsNull() && v.Type().Nature() == semantic.String && r.Type().Nature() == semantic.Regexp {
+					re := r.Regexp()
+					builder := values.NewDictBuilder(semantic.NewDictType(semantic.BasicString, semantic.BasicString))
+					if match := re.FindStringSubmatch(v.Str()); match != nil {
+						// named groups are keyed by their name, the other groups by their position
+						for i, name := range re.SubexpNames() {
+							if i == 0 {
+								continue
+							}
+							if name == "" {
+								name = strconv.Itoa(i)
+							}
+							if err := builder.Insert(values.NewString(name), values.NewString(match[i])); err != nil {
+								return nil, err
+							}
+						}
+					}
+					return builder.Dict(), nil
+				}
+				return nil, errors.Newf(codes.Invalid, "cannot execute function containing argument r of type %v value %v and argument v of type %v value %v", r.Type().Nature(), r, v.Type().Nature(), v)
+			},
+			false,
+		),
 		"getString": values.NewFunction(
 			"getString",
 			runtime.MustLookupBuiltinType("regexp", "getString"),
@@ -179,5 +235,7 @@ func init() {
 	runtime.RegisterPackageValue("regexp", "matchRegexpString", SpecialFns["matchRegexpString"])
 	runtime.RegisterPackageValue("regexp", "replaceAllString", SpecialFns["replaceAllString"])
 	runtime.RegisterPackageValue("regexp", "splitRegexp", SpecialFns["splitRegexp"])
+	runtime.RegisterPackageValue("regexp", "findStringSubmatch", SpecialFns["findStringSubmatch"])
+	runtime.RegisterPackageValue("regexp", "extract", SpecialFns["extract"])
 	runtime.RegisterPackageValue("regexp", "getString", SpecialFns["getString"])
 }
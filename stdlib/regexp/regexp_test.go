@@ -155,6 +155,76 @@ func TestSplitRegexp(t *testing.T) {
 	}
 }
 
+func TestFindStringSubmatch(t *testing.T) {
+	fluxFunc := SpecialFns["findStringSubmatch"]
+	re := regexp.MustCompile(`(\w+)@(\w+)\.com`)
+	for _, v := range []string{"mail gopher@example.com", "no address"} {
+		fluxArg := values.NewObjectWithValues(map[string]values.Value{"r": values.NewRegexp(re), "v": values.NewString(v)})
+		want := re.FindStringSubmatch(v)
+		got, err := fluxFunc.Call(dependenciestest.Default().Inject(context.Background()), fluxArg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		arr := values.NewArray(semantic.NewArrayType(semantic.BasicString))
+		for _, z := range want {
+			arr.Append(values.NewString(z))
+		}
+		if !arr.Equal(got.Array()) {
+			t.Errorf("input %s: expected %v, got %v", v, want, got.Array())
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	fluxFunc := SpecialFns["extract"]
+	testCases := []struct {
+		re   string
+		v    string
+		want map[string]string
+	}{
+		{
+			re:   `(?P<host>\w+):(?P<port>\d+)`,
+			v:    "connect to db:5432",
+			want: map[string]string{"host": "db", "port": "5432"},
+		},
+		{
+			re:   `(\w+)=(?P<value>\d+)(ms)?`,
+			v:    "latency=15ms",
+			want: map[string]string{"1": "latency", "value": "15", "3": "ms"},
+		},
+		{
+			re:   `(?P<host>\w+):(?P<port>\d+)`,
+			v:    "no address",
+			want: map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		fluxArg := values.NewObjectWithValues(map[string]values.Value{"r": values.NewRegexp(regexp.MustCompile(tc.re)), "v": values.NewString(tc.v)})
+		got, err := fluxFunc.Call(dependenciestest.Default().Inject(context.Background()), fluxArg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotMap := make(map[string]string)
+		got.Dict().Range(func(key, value values.Value) {
+			gotMap[key.Str()] = value.Str()
+		})
+		if !cmp.Equal(tc.want, gotMap) {
+			t.Errorf("input %s: unexpected groups -want/+got:\n%s", tc.v, cmp.Diff(tc.want, gotMap))
+		}
+	}
+}
+
+func TestExtractNullV(t *testing.T) {
+	fluxFunc := SpecialFns["extract"]
+	stringNullV := values.NewNull(semantic.BasicString)
+	fluxArg := values.NewObjectWithValues(map[string]values.Value{"r": values.NewRegexp(regexp.MustCompile("a*")), "v": stringNullV})
+	wantErr := errors.New(codes.Invalid, "cannot execute function containing argument r of type regexp value a* and argument v of type string value <nil>")
+	_, err := fluxFunc.Call(dependenciestest.Default().Inject(context.Background()), fluxArg)
+	if !cmp.Equal(wantErr, err) {
+		t.Errorf("input %s: expected %v, got %v", stringNullV, wantErr, err)
+	}
+}
+
 func TestGetString(t *testing.T) {
 	fluxFunc := SpecialFns["getString"]
 	re := regexp.MustCompile("a*")
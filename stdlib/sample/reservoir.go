@@ -0,0 +1,213 @@
+package sample
+
+import (
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/values"
+)
+
+const ReservoirKind = "reservoirSample"
+
+type ReservoirOpSpec struct {
+	N    int64  `json:"n"`
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+func init() {
+	runtime.RegisterPackageValue("sample", "reservoir",
+		flux.MustValue(flux.FunctionValue("reservoir",
+			createReservoirOpSpec,
+			runtime.MustLookupBuiltinType("sample", "reservoir"),
+		)),
+	)
+	flux.RegisterOpSpec(ReservoirKind, newReservoirOp)
+	plan.RegisterProcedureSpec(ReservoirKind, newReservoirProcedure, ReservoirKind)
+	execute.RegisterTransformation(ReservoirKind, createReservoirTransformation)
+}
+
+func createReservoirOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	n, err := args.GetRequiredInt("n")
+	if err != nil {
+		return nil, err
+	} else if n <= 0 {
+		return nil, errors.Newf(codes.Invalid, "n must be positive, got %d", n)
+	}
+	spec := &ReservoirOpSpec{N: n}
+
+	if seed, ok, err := args.GetInt("seed"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Seed = &seed
+	}
+	return spec, nil
+}
+
+func newReservoirOp() flux.OperationSpec {
+	return new(ReservoirOpSpec)
+}
+
+func (s *ReservoirOpSpec) Kind() flux.OperationKind {
+	return ReservoirKind
+}
+
+type ReservoirProcedureSpec struct {
+	plan.DefaultCost
+	N    int64
+	Seed *int64
+}
+
+func newReservoirProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*ReservoirOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &ReservoirProcedureSpec{
+		N:    spec.N,
+		Seed: spec.Seed,
+	}, nil
+}
+
+func (s *ReservoirProcedureSpec) Kind() plan.ProcedureKind {
+	return ReservoirKind
+}
+
+func (s *ReservoirProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	if s.Seed != nil {
+		seed := *s.Seed
+		ns.Seed = &seed
+	}
+	return &ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *ReservoirProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createReservoirTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*ReservoirProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewReservoirTransformation(d, cache, s)
+	return t, d, nil
+}
+
+type reservoirTransformation struct {
+	execute.ExecutionNode
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	n   int64
+	rng *rand.Rand
+}
+
+// NewReservoirTransformation creates a transformation that samples
+// the rows of each table. Without a seed in the spec, the random
+// number generator is seeded with the current time.
+func NewReservoirTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *ReservoirProcedureSpec) *reservoirTransformation {
+	seed := time.Now().UnixNano()
+	if spec.Seed != nil {
+		seed = *spec.Seed
+	}
+	return &reservoirTransformation{
+		d:     d,
+		cache: cache,
+		n:     spec.N,
+		rng:   rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (t *reservoirTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+// sampledRow is a row of the reservoir and its index in the input table.
+type sampledRow struct {
+	index  int64
+	values []values.Value
+}
+
+func (t *reservoirTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	b, created := t.cache.TableBuilder(tbl.Key())
+	if !created {
+		return errors.Newf(codes.FailedPrecondition, "duplicate table with key: %v", tbl.Key())
+	}
+	if err := execute.AddTableCols(tbl, b); err != nil {
+		return err
+	}
+
+	// Algorithm R: the first n rows fill the reservoir, then the
+	// row with index i replaces a random row of the reservoir with
+	// probability n/(i+1). Only the values of the rows that enter
+	// the reservoir are read.
+	var (
+		reservoir []sampledRow
+		seen      int64
+	)
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		for i, l := 0, cr.Len(); i < l; i++ {
+			slot := seen
+			if seen >= t.n {
+				slot = t.rng.Int63n(seen + 1)
+			}
+			if slot < t.n {
+				row := sampledRow{
+					index:  seen,
+					values: make([]values.Value, len(cr.Cols())),
+				}
+				for j := range row.values {
+					row.values[j] = execute.ValueForRow(cr, i, j)
+				}
+				if slot < int64(len(reservoir)) {
+					reservoir[slot] = row
+				} else {
+					reservoir = append(reservoir, row)
+				}
+			}
+			seen++
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	sort.Slice(reservoir, func(i, j int) bool {
+		return reservoir[i].index < reservoir[j].index
+	})
+	for _, row := range reservoir {
+		for j, v := range row.values {
+			if err := b.AppendValue(j, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *reservoirTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *reservoirTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *reservoirTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
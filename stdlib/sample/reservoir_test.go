@@ -0,0 +1,119 @@
+package sample_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/stdlib/sample"
+)
+
+func TestReservoir_Process(t *testing.T) {
+	seed := int64(1)
+	executetest.ProcessTestHelper(
+		t,
+		[]flux.Table{&executetest.Table{
+			KeyCols: []string{"t0"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "t0", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), 2.0, "a"},
+				{execute.Time(2), nil, "a"},
+				{execute.Time(3), 4.0, "a"},
+			},
+		}},
+		[]*executetest.Table{{
+			KeyCols: []string{"t0"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "t0", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), 2.0, "a"},
+				{execute.Time(2), nil, "a"},
+				{execute.Time(3), 4.0, "a"},
+			},
+		}},
+		nil,
+		func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+			return sample.NewReservoirTransformation(d, c, &sample.ReservoirProcedureSpec{N: 5, Seed: &seed})
+		},
+	)
+}
+
+// reservoir samples a table with the values 0 to size-1 and returns the sampled values.
+func reservoir(t *testing.T, size, n, seed int64) []int64 {
+	t.Helper()
+	data := &executetest.Table{
+		ColMeta: []flux.ColMeta{{Label: "_value", Type: flux.TInt}},
+	}
+	for i := int64(0); i < size; i++ {
+		data.Data = append(data.Data, []interface{}{i})
+	}
+
+	d := executetest.NewDataset(executetest.RandomDatasetID())
+	c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+	c.SetTriggerSpec(plan.DefaultTriggerSpec)
+	tx := sample.NewReservoirTransformation(d, c, &sample.ReservoirProcedureSpec{N: n, Seed: &seed})
+	if err := tx.Process(executetest.RandomDatasetID(), data); err != nil {
+		t.Fatal(err)
+	}
+
+	tables, err := executetest.TablesFromCache(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("expected one table, got %d", len(tables))
+	}
+	var got []int64
+	for _, row := range tables[0].Data {
+		got = append(got, row[0].(int64))
+	}
+	return got
+}
+
+func TestReservoir_Sample(t *testing.T) {
+	got := reservoir(t, 1000, 10, 7)
+	if len(got) != 10 {
+		t.Fatalf("expected 10 rows, got %d", len(got))
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i] <= got[i-1] {
+			t.Fatalf("sampled rows are not in input order: %v", got)
+		}
+	}
+
+	// The same seed returns the same sample.
+	if again := reservoir(t, 1000, 10, 7); !cmp.Equal(got, again) {
+		t.Errorf("unexpected sample for the same seed -want/+got:\n%s", cmp.Diff(got, again))
+	}
+}
+
+func TestReservoir_Uniform(t *testing.T) {
+	const (
+		size   = 20
+		n      = 5
+		trials = 2000
+	)
+	var counts [size]int
+	for seed := int64(0); seed < trials; seed++ {
+		for _, v := range reservoir(t, size, n, seed) {
+			counts[v]++
+		}
+	}
+	// Each row is sampled with probability n/size.
+	want := trials * n / size
+	for i, count := range counts {
+		if count < want*8/10 || count > want*12/10 {
+			t.Errorf("row %d was sampled %d times, want about %d", i, count, want)
+		}
+	}
+}
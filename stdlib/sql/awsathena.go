@@ -34,6 +34,7 @@ import (
 //     "s3://myorgqueryresults/?accessID=AKIAJLO3F...&db=dbname&missingAsDefault=false&missingAsEmptyString=false&region=us-west-1&secretAccessKey=NnQ7MUMp9PYZsmD47c%2BSsXGOFsd%2F...&WGRemoteCreation=false"
 
 type AwsAthenaRowReader struct {
+	columnScanner
 	Cursor      *sql.Rows
 	columns     []interface{}
 	columnTypes []flux.ColType
@@ -55,11 +56,7 @@ func (m *AwsAthenaRowReader) Next() bool {
 			return false
 		}
 		m.columns = make([]interface{}, len(columnNames))
-		columnPointers := make([]interface{}, len(columnNames))
-		for i := 0; i < len(columnNames); i++ {
-			columnPointers[i] = &m.columns[i]
-		}
-		if err := m.Cursor.Scan(columnPointers...); err != nil {
+		if err := m.scan(m.Cursor, m.columns); err != nil {
 			return false
 		}
 	}
@@ -22,6 +22,7 @@ import (
 //   https://github.com/bonitoo-io/go-sql-bigquery#connection-string
 
 type BigQueryRowReader struct {
+	columnScanner
 	Cursor      *sql.Rows
 	columns     []interface{}
 	columnTypes []flux.ColType
@@ -43,11 +44,7 @@ func (m *BigQueryRowReader) Next() bool {
 			return false
 		}
 		m.columns = make([]interface{}, len(columnNames))
-		columnPointers := make([]interface{}, len(columnNames))
-		for i := 0; i < len(columnNames); i++ {
-			columnPointers[i] = &m.columns[i]
-		}
-		if err := m.Cursor.Scan(columnPointers...); err != nil {
+		if err := m.scan(m.Cursor, m.columns); err != nil {
 			return false
 		}
 	}
@@ -0,0 +1,302 @@
+package sql
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/ClickHouse/clickhouse-go"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/values"
+)
+
+// ClickHouse support.
+// Notes:
+// * the v1 driver is used since it is a database/sql driver and is compatible with the Go version of this module
+// * Nullable(T) and LowCardinality(T) columns are mapped to the type of T, nulls are returned by the driver as nil
+// * Date, DateTime and DateTime64 are mapped to TTime, the driver applies the timezone of the column
+// * Decimal values are returned by the driver as scaled integers, they are mapped to TFloat using the scale of the column
+// * Flux has no array columns, so Array(T), Tuple and other composite values are read as JSON-encoded strings
+// * ClickHouse has no transactions. The driver batches the rows of an INSERT statement prepared within a transaction
+//   and sends them as a single block on commit, so `sql.to` writes every batch as one block.
+// * tables created by `sql.to` use the MergeTree engine and all of their columns are Nullable
+
+type ClickHouseRowReader struct {
+	columnScanner
+	Cursor      *sql.Rows
+	columns     []interface{}
+	columnTypes []flux.ColType
+	columnNames []string
+	scales      []int
+}
+
+// Next prepares ClickHouseRowReader to return rows
+func (m *ClickHouseRowReader) Next() bool {
+	next := m.Cursor.Next()
+	if next {
+		columnNames, err := m.Cursor.Columns()
+		if err != nil {
+			return false
+		}
+		m.columns = make([]interface{}, len(columnNames))
+		if err := m.scan(m.Cursor, m.columns); err != nil {
+			return false
+		}
+	}
+	return next
+}
+
+func (m *ClickHouseRowReader) GetNextRow() ([]values.Value, error) {
+	row := make([]values.Value, len(m.columns))
+	for i, col := range m.columns {
+		if col == nil {
+			row[i] = values.NewNull(flux.SemanticType(m.columnTypes[i]))
+			continue
+		}
+		// decimals are returned as integers or 128-bit little endian bytes
+		if m.scales != nil && m.scales[i] >= 0 {
+			f, err := clickhouseDecimalToFloat(col, m.scales[i])
+			if err != nil {
+				return nil, err
+			}
+			row[i] = values.NewFloat(f)
+			continue
+		}
+		switch col := col.(type) {
+		case int8:
+			row[i] = values.NewInt(int64(col))
+		case int16:
+			row[i] = values.NewInt(int64(col))
+		case int32:
+			row[i] = values.NewInt(int64(col))
+		case int64:
+			row[i] = values.NewInt(col)
+		case uint8:
+			if m.columnTypes[i] == flux.TBool {
+				row[i] = values.NewBool(col != 0)
+			} else {
+				row[i] = values.NewUInt(uint64(col))
+			}
+		case uint16:
+			row[i] = values.NewUInt(uint64(col))
+		case uint32:
+			row[i] = values.NewUInt(uint64(col))
+		case uint64:
+			row[i] = values.NewUInt(col)
+		case float32:
+			row[i] = values.NewFloat(float64(col))
+		case float64:
+			row[i] = values.NewFloat(col)
+		case bool:
+			row[i] = values.NewBool(col)
+		case string:
+			row[i] = values.NewString(col)
+		case []byte:
+			row[i] = values.NewString(string(col))
+		case time.Time:
+			row[i] = values.NewTime(values.ConvertTime(col))
+		case fmt.Stringer: // IPv4, IPv6
+			row[i] = values.NewString(col.String())
+		default:
+			if k := reflect.TypeOf(col).Kind(); k != reflect.Slice && k != reflect.Array && k != reflect.Map {
+				execute.PanicUnknownType(flux.TInvalid)
+			}
+			b, err := json.Marshal(col)
+			if err != nil {
+				return nil, errors.Wrapf(err, codes.Internal, "cannot encode value of column %s", m.columnNames[i])
+			}
+			row[i] = values.NewString(string(b))
+		}
+	}
+	return row, nil
+}
+
+func (m *ClickHouseRowReader) InitColumnNames(n []string) {
+	m.columnNames = n
+}
+
+func (m *ClickHouseRowReader) InitColumnTypes(types []*sql.ColumnType) {
+	fluxTypes := make([]flux.ColType, len(types))
+	m.scales = make([]int, len(types))
+	for i := 0; i < len(types); i++ {
+		fluxTypes[i], m.scales[i] = clickhouseColumnType(types[i].DatabaseTypeName())
+	}
+	m.columnTypes = fluxTypes
+}
+
+func (m *ClickHouseRowReader) ColumnNames() []string {
+	return m.columnNames
+}
+
+func (m *ClickHouseRowReader) ColumnTypes() []flux.ColType {
+	return m.columnTypes
+}
+
+func (m *ClickHouseRowReader) SetColumns(i []interface{}) {
+	m.columns = i
+}
+
+func (m *ClickHouseRowReader) Close() error {
+	if err := m.Cursor.Err(); err != nil {
+		return err
+	}
+	return m.Cursor.Close()
+}
+
+func NewClickHouseRowReader(r *sql.Rows) (execute.RowReader, error) {
+	reader := &ClickHouseRowReader{
+		Cursor: r,
+	}
+	cols, err := r.Columns()
+	if err != nil {
+		return nil, err
+	}
+	reader.InitColumnNames(cols)
+
+	types, err := r.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	reader.InitColumnTypes(types)
+	return reader, nil
+}
+
+// clickhouseColumnType returns the flux column type of a ClickHouse type, and the scale
+// of the type if it is a Decimal or -1 otherwise.
+func clickhouseColumnType(dbType string) (flux.ColType, int) {
+	dbType = clickhouseUnwrapType(dbType, "Nullable")
+	dbType = clickhouseUnwrapType(dbType, "LowCardinality")
+	switch dbType {
+	case "Int8", "Int16", "Int32", "Int64":
+		return flux.TInt, -1
+	case "UInt8", "UInt16", "UInt32", "UInt64":
+		return flux.TUInt, -1
+	case "Float32", "Float64":
+		return flux.TFloat, -1
+	case "Bool", "Boolean":
+		return flux.TBool, -1
+	case "Date":
+		return flux.TTime, -1
+	}
+	switch {
+	case strings.HasPrefix(dbType, "DateTime"):
+		return flux.TTime, -1
+	case strings.HasPrefix(dbType, "Decimal"):
+		return flux.TFloat, clickhouseDecimalScale(dbType)
+	default:
+		// String, FixedString, UUID, Enum, IPv4, IPv6 as well as the composite types
+		return flux.TString, -1
+	}
+}
+
+// clickhouseUnwrapType returns the type T of a wrapper type such as Nullable(T).
+func clickhouseUnwrapType(dbType, wrapper string) string {
+	if strings.HasPrefix(dbType, wrapper+"(") && strings.HasSuffix(dbType, ")") {
+		return dbType[len(wrapper)+1 : len(dbType)-1]
+	}
+	return dbType
+}
+
+// clickhouseDecimalScale returns the scale of Decimal(P, S), Decimal32(S), Decimal64(S) and Decimal128(S).
+func clickhouseDecimalScale(dbType string) int {
+	open, close := strings.Index(dbType, "("), strings.LastIndex(dbType, ")")
+	if open < 0 || close < open {
+		return 0
+	}
+	args := strings.Split(dbType[open+1:close], ",")
+	if len(args) == 1 && strings.HasPrefix(dbType, "Decimal(") {
+		// Decimal(P) has a scale of 0
+		return 0
+	}
+	scale, err := strconv.Atoi(strings.TrimSpace(args[len(args)-1]))
+	if err != nil {
+		return 0
+	}
+	return scale
+}
+
+// clickhouseDecimalToFloat converts a scaled decimal value returned by the driver to a float.
+func clickhouseDecimalToFloat(v interface{}, scale int) (float64, error) {
+	var unscaled *big.Int
+	switch v := v.(type) {
+	case int32:
+		unscaled = big.NewInt(int64(v))
+	case int64:
+		unscaled = big.NewInt(v)
+	case []byte:
+		// 128-bit two's complement, little endian
+		be := make([]byte, len(v))
+		for i := range v {
+			be[len(v)-1-i] = v[i]
+		}
+		unscaled = new(big.Int).SetBytes(be)
+		if len(be) > 0 && be[0]&0x80 != 0 {
+			unscaled.Sub(unscaled, new(big.Int).Lsh(big.NewInt(1), uint(8*len(be))))
+		}
+	default:
+		return 0, errors.Newf(codes.Internal, "unexpected decimal value of type %T", v)
+	}
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(unscaled), big.NewFloat(math.Pow10(scale))).Float64()
+	return f, nil
+}
+
+var fluxToClickHouse = map[flux.ColType]string{
+	flux.TFloat:  "Float64",
+	flux.TInt:    "Int64",
+	flux.TUInt:   "UInt64",
+	flux.TString: "String",
+	flux.TBool:   "UInt8",
+	flux.TTime:   "DateTime64(9, 'UTC')",
+}
+
+// ClickHouseColumnTranslateFunc translates flux colTypes into their corresponding ClickHouse column type
+func ClickHouseColumnTranslateFunc() translationFunc {
+	return func(f flux.ColType, colName string) (string, error) {
+		s, found := fluxToClickHouse[f]
+		if !found {
+			return "", errors.Newf(codes.Invalid, "ClickHouse does not support column type %s", f.String())
+		}
+		// flux columns may always contain nulls
+		return colName + " Nullable(" + s + ")", nil
+	}
+}
+
+// clickhouseCreateTable returns the statement that creates the table written to by sql.to.
+func clickhouseCreateTable(table string, cols []string) string {
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s) ENGINE = MergeTree() ORDER BY tuple()", table, strings.Join(cols, ","))
+}
+
+// clickhouseInsert writes a batch of rows as a single block.
+// The values of the rows are laid out one row after the other in valueArgs.
+func clickhouseInsert(db *sql.DB, s *ToSQLOpSpec, colNames []string, valueArgs []interface{}) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s (%s) VALUES", s.Table, strings.Join(colNames, ",")))
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	for i := 0; i+len(colNames) <= len(valueArgs); i += len(colNames) {
+		if _, err := stmt.Exec(valueArgs[i : i+len(colNames)]...); err != nil {
+			_ = stmt.Close()
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err := stmt.Close(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,125 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/values"
+)
+
+func TestClickHouse_ColumnType(t *testing.T) {
+	testCases := []struct {
+		dbType string
+		want   flux.ColType
+		scale  int
+	}{
+		{dbType: "Int8", want: flux.TInt, scale: -1},
+		{dbType: "Nullable(Int64)", want: flux.TInt, scale: -1},
+		{dbType: "UInt32", want: flux.TUInt, scale: -1},
+		{dbType: "Float32", want: flux.TFloat, scale: -1},
+		{dbType: "Nullable(Float64)", want: flux.TFloat, scale: -1},
+		{dbType: "LowCardinality(String)", want: flux.TString, scale: -1},
+		{dbType: "FixedString(16)", want: flux.TString, scale: -1},
+		{dbType: "UUID", want: flux.TString, scale: -1},
+		{dbType: "Array(String)", want: flux.TString, scale: -1},
+		{dbType: "Date", want: flux.TTime, scale: -1},
+		{dbType: "DateTime('UTC')", want: flux.TTime, scale: -1},
+		{dbType: "Nullable(DateTime64(3, 'Europe/Berlin'))", want: flux.TTime, scale: -1},
+		{dbType: "Decimal(9, 2)", want: flux.TFloat, scale: 2},
+		{dbType: "Decimal(9)", want: flux.TFloat, scale: 0},
+		{dbType: "Nullable(Decimal64(4))", want: flux.TFloat, scale: 4},
+	}
+	for _, tc := range testCases {
+		got, scale := clickhouseColumnType(tc.dbType)
+		if got != tc.want || scale != tc.scale {
+			t.Errorf("unexpected type of %s -want/+got:\n\t- %v (%d)\n\t+ %v (%d)", tc.dbType, tc.want, tc.scale, got, scale)
+		}
+	}
+}
+
+func TestClickHouse_DecimalToFloat(t *testing.T) {
+	testCases := []struct {
+		v     interface{}
+		scale int
+		want  float64
+	}{
+		{v: int32(12345), scale: 2, want: 123.45},
+		{v: int64(-5), scale: 1, want: -0.5},
+		{v: []byte{0x39, 0x30, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, scale: 3, want: 12.345},
+		{v: []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, scale: 0, want: -1},
+	}
+	for _, tc := range testCases {
+		got, err := clickhouseDecimalToFloat(tc.v, tc.scale)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("unexpected value of %v -want/+got:\n\t- %v\n\t+ %v", tc.v, tc.want, got)
+		}
+	}
+}
+
+func TestClickHouseParsing(t *testing.T) {
+	testCases := []struct {
+		name string
+		data *sqlmock.Rows
+		want [][]values.Value
+	}{
+		{
+			name: "ints",
+			data: sqlmock.NewRows([]string{"column"}).AddRow(int8(6)).AddRow(int32(-1)).AddRow(int64(643)),
+			want: [][]values.Value{{values.NewInt(6)}, {values.NewInt(-1)}, {values.NewInt(643)}},
+		},
+		{
+			name: "uints",
+			data: sqlmock.NewRows([]string{"column"}).AddRow(uint16(6)).AddRow(uint64(643)),
+			want: [][]values.Value{{values.NewUInt(6)}, {values.NewUInt(643)}},
+		},
+		{
+			name: "floats",
+			data: sqlmock.NewRows([]string{"column"}).AddRow(float32(1.5)).AddRow(float64(42)),
+			want: [][]values.Value{{values.NewFloat(1.5)}, {values.NewFloat(42)}},
+		},
+		{
+			name: "timestamp",
+			data: sqlmock.NewRows([]string{"column"}).AddRow(createTestTimes()[0]),
+			want: [][]values.Value{{values.NewTime(values.ConvertTime(createTestTimes()[0].(time.Time)))}},
+		},
+		{
+			name: "arrays",
+			data: sqlmock.NewRows([]string{"column"}).AddRow([]string{"a", "b"}).AddRow([]int64{1, 2}),
+			want: [][]values.Value{{values.NewString(`["a","b"]`)}, {values.NewString(`[1,2]`)}},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			TestReader, err := NewClickHouseRowReader(mockRowsToSQLRows(tc.data))
+			if !cmp.Equal(nil, err) {
+				t.Fatalf("unexpected result -want/+got\n\n%s\n\n", cmp.Diff(nil, err))
+			}
+			i := 0
+			for TestReader.Next() {
+				row, _ := TestReader.GetNextRow()
+				if !cmp.Equal(tc.want[i], row) {
+					t.Fatalf("unexpected result -want/+got\n\n%s\n\n", cmp.Diff(tc.want[i], row))
+				}
+				i++
+			}
+			if i != len(tc.want) {
+				t.Fatalf("unexpected number of rows -want/+got\n\t- %d\n\t+ %d", len(tc.want), i)
+			}
+		})
+	}
+}
+
+func TestClickHouse_CreateTable(t *testing.T) {
+	want := "CREATE TABLE IF NOT EXISTS metrics (_time Nullable(DateTime64(9, 'UTC')),_value Nullable(Float64)) ENGINE = MergeTree() ORDER BY tuple()"
+	got := clickhouseCreateTable("metrics", []string{"_time Nullable(DateTime64(9, 'UTC'))", "_value Nullable(Float64)"})
+	if want != got {
+		t.Errorf("unexpected statement -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
@@ -0,0 +1,119 @@
+package sql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+)
+
+// The modes of sql.to for rows that conflict with existing rows,
+// i.e. rows with the same primary or unique key.
+const (
+	// ConflictError fails the write, this is the default.
+	ConflictError = "error"
+	// ConflictIgnore keeps the existing row.
+	ConflictIgnore = "ignore"
+	// ConflictUpdate updates the existing row with the values of the new row.
+	ConflictUpdate = "update"
+)
+
+// validateConflict checks that the driver supports the conflict mode.
+// Drivers that use ON CONFLICT need the key columns to update rows.
+func validateConflict(driverName, onConflict string, keyColumns []string) error {
+	switch onConflict {
+	case "":
+		return nil
+	case ConflictIgnore:
+		switch driverName {
+		case "postgres", "sqlite3", "mysql", "sqlmock":
+			return nil
+		}
+	case ConflictUpdate:
+		switch driverName {
+		case "postgres", "sqlite3", "sqlmock":
+			if len(keyColumns) == 0 {
+				return errors.Newf(codes.Invalid, "keyColumns are required to update conflicting rows with %s", driverName)
+			}
+			return nil
+		case "mysql", "hdb":
+			return nil
+		}
+	default:
+		return errors.Newf(codes.Invalid, "invalid onConflict %q, must be one of %q, %q or %q", onConflict, ConflictError, ConflictIgnore, ConflictUpdate)
+	}
+	return errors.Newf(codes.Invalid, "onConflict %q is not supported for %s", onConflict, driverName)
+}
+
+// primaryKeyClause returns the PRIMARY KEY clause of the key columns
+// for the tables created by sql.to, or an empty string.
+// MySQL cannot use TEXT columns in keys, so its tables must be created beforehand.
+func primaryKeyClause(s *ToSQLOpSpec) string {
+	if len(s.KeyColumns) == 0 {
+		return ""
+	}
+	switch s.DriverName {
+	case "postgres", "sqlite3":
+		return fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(s.KeyColumns, ","))
+	case "hdb":
+		keys := make([]string, len(s.KeyColumns))
+		for i, k := range s.KeyColumns {
+			keys[i] = hdbEscapeName(k, true)
+		}
+		return fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(keys, ","))
+	default:
+		return ""
+	}
+}
+
+// addConflictClause adds the driver specific conflict clause to an INSERT statement.
+func addConflictClause(s *ToSQLOpSpec, colNames []string, query string) string {
+	if s.OnConflict != ConflictUpdate && s.OnConflict != ConflictIgnore {
+		return query
+	}
+	var updates []string
+	if s.OnConflict == ConflictUpdate {
+		for _, c := range colNames {
+			if !containsStr(s.KeyColumns, c) {
+				updates = append(updates, c)
+			}
+		}
+	}
+	switch s.DriverName {
+	case "mysql":
+		if len(updates) == 0 {
+			return strings.Replace(query, "INSERT INTO", "INSERT IGNORE INTO", 1)
+		}
+		set := make([]string, len(updates))
+		for i, c := range updates {
+			set[i] = fmt.Sprintf("%s=VALUES(%s)", c, c)
+		}
+		return query + " ON DUPLICATE KEY UPDATE " + strings.Join(set, ",")
+	case "hdb":
+		// SAP HANA replaces the row with the same primary key
+		return strings.Replace(query, "INSERT INTO", "UPSERT", 1) + " WITH PRIMARY KEY"
+	default: // postgres, sqlite3
+		target := ""
+		if len(s.KeyColumns) > 0 {
+			target = fmt.Sprintf(" (%s)", strings.Join(s.KeyColumns, ","))
+		}
+		if len(updates) == 0 {
+			return query + " ON CONFLICT" + target + " DO NOTHING"
+		}
+		set := make([]string, len(updates))
+		for i, c := range updates {
+			set[i] = fmt.Sprintf("%s=excluded.%s", c, c)
+		}
+		return query + " ON CONFLICT" + target + " DO UPDATE SET " + strings.Join(set, ",")
+	}
+}
+
+func containsStr(strs []string, str string) bool {
+	for _, s := range strs {
+		if s == str {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package sql
+
+import (
+	"testing"
+)
+
+func TestAddConflictClause(t *testing.T) {
+	const insert = "INSERT INTO t (_time,host,_value) VALUES (?,?,?)"
+	cols := []string{"_time", "host", "_value"}
+	testCases := []struct {
+		name string
+		spec ToSQLOpSpec
+		want string
+	}{
+		{
+			name: "error",
+			spec: ToSQLOpSpec{DriverName: "postgres"},
+			want: insert,
+		},
+		{
+			name: "postgres ignore",
+			spec: ToSQLOpSpec{DriverName: "postgres", OnConflict: ConflictIgnore},
+			want: insert + " ON CONFLICT DO NOTHING",
+		},
+		{
+			name: "postgres update",
+			spec: ToSQLOpSpec{DriverName: "postgres", OnConflict: ConflictUpdate, KeyColumns: []string{"_time", "host"}},
+			want: insert + " ON CONFLICT (_time,host) DO UPDATE SET _value=excluded._value",
+		},
+		{
+			name: "sqlite update of keys only",
+			spec: ToSQLOpSpec{DriverName: "sqlite3", OnConflict: ConflictUpdate, KeyColumns: cols},
+			want: insert + " ON CONFLICT (_time,host,_value) DO NOTHING",
+		},
+		{
+			name: "mysql ignore",
+			spec: ToSQLOpSpec{DriverName: "mysql", OnConflict: ConflictIgnore},
+			want: "INSERT IGNORE INTO t (_time,host,_value) VALUES (?,?,?)",
+		},
+		{
+			name: "mysql update",
+			spec: ToSQLOpSpec{DriverName: "mysql", OnConflict: ConflictUpdate, KeyColumns: []string{"_time"}},
+			want: insert + " ON DUPLICATE KEY UPDATE host=VALUES(host),_value=VALUES(_value)",
+		},
+		{
+			name: "hdb update",
+			spec: ToSQLOpSpec{DriverName: "hdb", OnConflict: ConflictUpdate},
+			want: "UPSERT t (_time,host,_value) VALUES (?,?,?) WITH PRIMARY KEY",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			if got := addConflictClause(&tc.spec, cols, insert); tc.want != got {
+				t.Errorf("unexpected statement -want/+got:\n\t- %s\n\t+ %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateConflict(t *testing.T) {
+	testCases := []struct {
+		driverName string
+		onConflict string
+		keyColumns []string
+		wantErr    string
+	}{
+		{driverName: "snowflake", onConflict: ""},
+		{driverName: "postgres", onConflict: ConflictIgnore},
+		{driverName: "postgres", onConflict: ConflictUpdate, keyColumns: []string{"host"}},
+		{driverName: "mysql", onConflict: ConflictUpdate},
+		{driverName: "postgres", onConflict: ConflictUpdate, wantErr: "keyColumns are required to update conflicting rows with postgres"},
+		{driverName: "hdb", onConflict: ConflictIgnore, wantErr: `onConflict "ignore" is not supported for hdb`},
+		{driverName: "snowflake", onConflict: ConflictUpdate, wantErr: `onConflict "update" is not supported for snowflake`},
+		{driverName: "postgres", onConflict: "replace", wantErr: `invalid onConflict "replace", must be one of "error", "ignore" or "update"`},
+	}
+	for _, tc := range testCases {
+		err := validateConflict(tc.driverName, tc.onConflict, tc.keyColumns)
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("unexpected error for %s %q: %s", tc.driverName, tc.onConflict, err)
+			}
+		} else if err == nil || err.Error() != tc.wantErr {
+			t.Errorf("unexpected error for %s %q -want/+got:\n\t- %s\n\t+ %v", tc.driverName, tc.onConflict, tc.wantErr, err)
+		}
+	}
+}
+
+func TestPrimaryKeyClause(t *testing.T) {
+	for _, tc := range []struct {
+		spec ToSQLOpSpec
+		want string
+	}{
+		{spec: ToSQLOpSpec{DriverName: "postgres"}, want: ""},
+		{spec: ToSQLOpSpec{DriverName: "sqlite3", KeyColumns: []string{"_time", "host"}}, want: "PRIMARY KEY (_time,host)"},
+		{spec: ToSQLOpSpec{DriverName: "hdb", KeyColumns: []string{"host"}}, want: `PRIMARY KEY ("HOST")`},
+		{spec: ToSQLOpSpec{DriverName: "mysql", KeyColumns: []string{"host"}}, want: ""},
+	} {
+		if got := primaryKeyClause(&tc.spec); tc.want != got {
+			t.Errorf("unexpected clause for %s -want/+got:\n\t- %s\n\t+ %s", tc.spec.DriverName, tc.want, got)
+		}
+	}
+}
@@ -5,13 +5,18 @@ import (
 	"database/sql"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/array"
+	"github.com/influxdata/flux/arrow"
 	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/sqlpool"
+	"github.com/influxdata/flux/dependencies/tablebuffer"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/execute/table"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/runtime"
-	_ "github.com/lib/pq"
+	"github.com/influxdata/flux/values"
 	_ "github.com/vertica/vertica-sql-go"
 )
 
@@ -68,6 +73,9 @@ type FromSQLProcedureSpec struct {
 	DriverName     string
 	DataSourceName string
 	Query          string
+	// Columns are the labels of the columns to read.
+	// If nil, every column is read.
+	Columns []string
 }
 
 func newFromSQLProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
@@ -92,6 +100,22 @@ func (s *FromSQLProcedureSpec) Copy() plan.ProcedureSpec {
 	ns.DriverName = s.DriverName
 	ns.DataSourceName = s.DataSourceName
 	ns.Query = s.Query
+	if s.Columns != nil {
+		ns.Columns = make([]string, len(s.Columns))
+		copy(ns.Columns, s.Columns)
+	}
+	return ns
+}
+
+// ProjectedColumns implements plan.ProjectionPushDownProcedureSpec.
+func (s *FromSQLProcedureSpec) ProjectedColumns() []string {
+	return s.Columns
+}
+
+// PushDownProjection implements plan.ProjectionPushDownProcedureSpec.
+func (s *FromSQLProcedureSpec) PushDownProjection(columns []string) plan.PhysicalProcedureSpec {
+	ns := s.Copy().(*FromSQLProcedureSpec)
+	ns.Columns = columns
 	return ns
 }
 
@@ -132,6 +156,10 @@ func createFromSQLSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a ex
 		newRowReader = NewBigQueryRowReader
 	case "hdb":
 		newRowReader = NewHdbRowReader
+	case "clickhouse":
+		newRowReader = NewClickHouseRowReader
+	case oracleDriverName:
+		newRowReader = NewOracleRowReader
 	default:
 		return nil, errors.Newf(codes.Invalid, "sql driver %s not supported", spec.DriverName)
 	}
@@ -142,9 +170,12 @@ func createFromSQLSource(prSpec plan.ProcedureSpec, dsid execute.DatasetID, a ex
 			_ = rows.Close()
 			return nil, err
 		}
+		if spec.Columns != nil {
+			reader = newProjectedRowReader(reader, spec.Columns)
+		}
 		return read(ctx, reader, a.Allocator())
 	}
-	iterator := &sqlIterator{spec: spec, id: dsid, read: readFn}
+	iterator := &sqlIterator{spec: spec, id: dsid, read: readFn, pool: sqlpool.Get(a.Context())}
 	return execute.CreateSourceFromIterator(iterator, dsid)
 }
 
@@ -154,27 +185,28 @@ type sqlIterator struct {
 	spec *FromSQLProcedureSpec
 	id   execute.DatasetID
 	read func(ctx context.Context, rows *sql.Rows) (flux.Table, error)
+	pool *sqlpool.Pool
 }
 
-func (c *sqlIterator) connect(ctx context.Context) (*sql.DB, error) {
-	db, err := getOpenFunc(c.spec.DriverName, c.spec.DataSourceName)()
+func (c *sqlIterator) connect(ctx context.Context) (*sql.DB, func() error, error) {
+	db, release, err := openDB(c.pool, c.spec.DriverName, c.spec.DataSourceName)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	if err := db.Ping(); err != nil {
-		_ = db.Close()
-		return nil, err
+	if err := db.PingContext(ctx); err != nil {
+		_ = release()
+		return nil, nil, err
 	}
-	return db, nil
+	return db, release, nil
 }
 
 func (c *sqlIterator) Do(ctx context.Context, f func(flux.Table) error) error {
 	// Connect to the database so we can execute the query.
-	db, err := c.connect(ctx)
+	db, release, err := c.connect(ctx)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = db.Close() }()
+	defer func() { _ = release() }()
 
 	rows, err := db.QueryContext(ctx, c.spec.Query)
 	if err != nil {
@@ -182,43 +214,165 @@ func (c *sqlIterator) Do(ctx context.Context, f func(flux.Table) error) error {
 	}
 	defer func() { _ = rows.Close() }()
 
-	table, err := c.read(ctx, rows)
+	tbl, err := c.read(ctx, rows)
 	if err != nil {
 		return err
 	}
-	return f(table)
+	return f(tbl)
 }
 
 // read will use the RowReader to construct a flux.Table.
+// The rows are streamed in buffers of the size configured
+// with the tablebuffer dependency.
 func read(ctx context.Context, reader execute.RowReader, alloc *memory.Allocator) (flux.Table, error) {
-	// Ensure that the reader is always freed so the underlying
-	// cursor can be returned.
-	defer func() { _ = reader.Close() }()
+	if err := ctx.Err(); err != nil {
+		_ = reader.Close()
+		return nil, err
+	}
 
-	groupKey := execute.NewGroupKey(nil, nil)
-	builder := execute.NewColListTableBuilder(groupKey, alloc)
+	cols := make([]flux.ColMeta, len(reader.ColumnTypes()))
 	for i, dataType := range reader.ColumnTypes() {
-		if _, err := builder.AddCol(flux.ColMeta{Label: reader.ColumnNames()[i], Type: dataType}); err != nil {
-			return nil, err
-		}
+		cols[i] = flux.ColMeta{Label: reader.ColumnNames()[i], Type: dataType}
 	}
-	for reader.Next() {
-		row, err := reader.GetNextRow()
-		if err != nil {
-			return nil, err
+	bufferSize := tablebuffer.GetSize(ctx, tablebuffer.DefaultSize)
+	return table.StreamWithContext(ctx, execute.NewGroupKey(nil, nil), cols, func(ctx context.Context, w *table.StreamWriter) error {
+		// Ensure that the reader is always freed so the underlying
+		// cursor can be returned.
+		defer func() { _ = reader.Close() }()
+
+		builders := make([]array.Builder, len(cols))
+		for i, col := range cols {
+			builders[i] = arrow.NewBuilder(col.Type, alloc)
+		}
+		defer func() {
+			for _, b := range builders {
+				b.Release()
+			}
+		}()
+		flush := func() error {
+			vs := make([]array.Interface, len(builders))
+			for i, b := range builders {
+				// NewArray resets the builder for the next buffer
+				vs[i] = b.NewArray()
+			}
+			return w.Write(vs)
 		}
 
-		for i, col := range row {
-			if err := builder.AppendValue(i, col); err != nil {
-				return nil, err
+		n := 0
+		for reader.Next() {
+			// The driver may not check the context while it reads
+			// rows it has already fetched so check it here in order
+			// to stop reading a large result when the query is canceled.
+			if err := ctx.Err(); err != nil {
+				return err
 			}
+			row, err := reader.GetNextRow()
+			if err != nil {
+				return err
+			}
+
+			for i, col := range row {
+				if err := arrow.AppendValue(builders[i], col); err != nil {
+					return err
+				}
+			}
+			if n++; n == bufferSize {
+				if err := flush(); err != nil {
+					return err
+				}
+				n = 0
+			}
+		}
+
+		// An error may have been encountered while reading.
+		// This will get reported when we go to close the reader.
+		if err := reader.Close(); err != nil {
+			return err
 		}
+		return flush()
+	})
+}
+
+// columnScanner is embedded by the row readers of the drivers to scan
+// the values of a row. The values of the skipped columns are discarded
+// without being converted, so they are read as nulls.
+type columnScanner struct {
+	skip []bool
+}
+
+// skipColumns sets the columns whose values are not scanned.
+func (s *columnScanner) skipColumns(skip []bool) {
+	s.skip = skip
+}
+
+func (s *columnScanner) scan(cursor *sql.Rows, columns []interface{}) error {
+	pointers := make([]interface{}, len(columns))
+	for i := range columns {
+		if i < len(s.skip) && s.skip[i] {
+			pointers[i] = discardValue{}
+		} else {
+			pointers[i] = &columns[i]
+		}
+	}
+	return cursor.Scan(pointers...)
+}
+
+// discardValue is a sql.Scanner that discards the value of a column.
+type discardValue struct{}
+
+func (discardValue) Scan(interface{}) error { return nil }
+
+// projectedRowReader only returns the values of some of the columns
+// read by a RowReader so the values of the other columns are not
+// added to the table. The row readers of the drivers do not scan
+// the values of the other columns.
+type projectedRowReader struct {
+	execute.RowReader
+	indices []int
+	names   []string
+	types   []flux.ColType
+}
+
+func newProjectedRowReader(reader execute.RowReader, columns []string) *projectedRowReader {
+	keep := make(map[string]bool, len(columns))
+	for _, label := range columns {
+		keep[label] = true
+	}
+	r := &projectedRowReader{RowReader: reader}
+	types := reader.ColumnTypes()
+	names := reader.ColumnNames()
+	skip := make([]bool, len(names))
+	for i, name := range names {
+		if keep[name] {
+			r.indices = append(r.indices, i)
+			r.names = append(r.names, name)
+			r.types = append(r.types, types[i])
+		} else {
+			skip[i] = true
+		}
+	}
+	if s, ok := reader.(interface{ skipColumns([]bool) }); ok {
+		s.skipColumns(skip)
 	}
+	return r
+}
 
-	// An error may have been encountered while reading.
-	// This will get reported when we go to close the reader.
-	if err := reader.Close(); err != nil {
+func (r *projectedRowReader) GetNextRow() ([]values.Value, error) {
+	row, err := r.RowReader.GetNextRow()
+	if err != nil {
 		return nil, err
 	}
-	return builder.Table()
+	projected := make([]values.Value, len(r.indices))
+	for j, i := range r.indices {
+		projected[j] = row[i]
+	}
+	return projected, nil
+}
+
+func (r *projectedRowReader) ColumnNames() []string {
+	return r.names
+}
+
+func (r *projectedRowReader) ColumnTypes() []flux.ColType {
+	return r.types
 }
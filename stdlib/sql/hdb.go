@@ -31,6 +31,7 @@ import (
 //   `sql.to` target table and column names are assumed in / converted to uppercase.
 
 type HdbRowReader struct {
+	columnScanner
 	Cursor      *sql.Rows
 	columns     []interface{}
 	columnTypes []flux.ColType
@@ -52,11 +53,7 @@ func (m *HdbRowReader) Next() bool {
 			return false
 		}
 		m.columns = make([]interface{}, len(columnNames))
-		columnPointers := make([]interface{}, len(columnNames))
-		for i := 0; i < len(columnNames); i++ {
-			columnPointers[i] = &m.columns[i]
-		}
-		if err := m.Cursor.Scan(columnPointers...); err != nil {
+		if err := m.scan(m.Cursor, m.columns); err != nil {
 			return false
 		}
 	}
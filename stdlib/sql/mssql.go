@@ -18,6 +18,7 @@ import (
 // Microsoft SQL Server support.
 
 type MssqlRowReader struct {
+	columnScanner
 	Cursor      *sql.Rows
 	columns     []interface{}
 	columnTypes []flux.ColType
@@ -39,11 +40,7 @@ func (m *MssqlRowReader) Next() bool {
 			return false
 		}
 		m.columns = make([]interface{}, len(columnNames))
-		columnPointers := make([]interface{}, len(columnNames))
-		for i := 0; i < len(columnNames); i++ {
-			columnPointers[i] = &m.columns[i]
-		}
-		if err := m.Cursor.Scan(columnPointers...); err != nil {
+		if err := m.scan(m.Cursor, m.columns); err != nil {
 			return false
 		}
 	}
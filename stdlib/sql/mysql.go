@@ -14,6 +14,7 @@ import (
 )
 
 type MySQLRowReader struct {
+	columnScanner
 	Cursor      *sql.Rows
 	columns     []interface{}
 	columnTypes []flux.ColType
@@ -34,11 +35,7 @@ func (m *MySQLRowReader) Next() bool {
 			return false
 		}
 		m.columns = make([]interface{}, len(columnNames))
-		columnPointers := make([]interface{}, len(columnNames))
-		for i := 0; i < len(columnNames); i++ {
-			columnPointers[i] = &m.columns[i]
-		}
-		if err := m.Cursor.Scan(columnPointers...); err != nil {
+		if err := m.scan(m.Cursor, m.columns); err != nil {
 			return false
 		}
 	}
@@ -2,6 +2,8 @@ package sql
 
 import (
 	"database/sql"
+
+	"github.com/influxdata/flux/dependencies/sqlpool"
 )
 
 // There are cases when database connection cannot be open with `sql.Open(driverName, dsn)`.
@@ -34,11 +36,31 @@ func defaultOpenFunction(driverName, dataSourceName string) openFunc {
 // and for SQL server with SQL or Windows authentication, it simply returns `defaultOpenFunction`.
 // For Azure SQL Server with Azure AD authentication, it returns a function that authenticates
 // against Azure AD and uses connector with access token to open DB connection.
+// For Oracle, it returns a function that reports an error if the driver is not built in.
 func getOpenFunc(driverName, dataSourceName string) openFunc {
 	switch driverName {
 	case "mssql", "sqlserver":
 		return mssqlOpenFunction(driverName, dataSourceName)
+	case oracleDriverName:
+		return oracleOpenFunction(dataSourceName)
 	default:
 		return defaultOpenFunction(driverName, dataSourceName)
 	}
 }
+
+// openDB opens the database of a driver and a data source name and returns
+// the function that releases it. With a pool, the database is shared with
+// the other calls that connect to the same data source and releasing it keeps
+// its connections open until the pool evicts it, without a pool releasing it
+// closes the database.
+func openDB(pool *sqlpool.Pool, driverName, dataSourceName string) (db *sql.DB, release func() error, err error) {
+	open := getOpenFunc(driverName, dataSourceName)
+	if pool != nil {
+		return pool.Open(driverName, dataSourceName, open)
+	}
+	db, err = open()
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, db.Close, nil
+}
@@ -0,0 +1,263 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/values"
+)
+
+// Oracle support.
+// Notes:
+// * the driver (github.com/sijms/go-ora) is only built in with the `oracle` build tag (see oracle_driver.go),
+//   without it sql.from and sql.to report that the driver is not available
+// * NUMBER columns with a scale of 0 are mapped to TInt when the driver reports the scale, other NUMBER columns to TFloat
+// * DATE and TIMESTAMP columns are mapped to TTime
+// * Oracle has no boolean column type, booleans are written as NUMBER(1) and unsigned integers as NUMBER(20)
+// * bind variables are positional (:1, :2, ...) and multi-row inserts use INSERT ALL
+// * there is no CREATE TABLE IF NOT EXISTS, so the error raised for an existing table (ORA-00955) is ignored
+
+const oracleDriverName = "oracle"
+
+type OracleRowReader struct {
+	columnScanner
+	Cursor      *sql.Rows
+	columns     []interface{}
+	columnTypes []flux.ColType
+	columnNames []string
+}
+
+// Next prepares OracleRowReader to return rows
+func (m *OracleRowReader) Next() bool {
+	next := m.Cursor.Next()
+	if next {
+		columnNames, err := m.Cursor.Columns()
+		if err != nil {
+			return false
+		}
+		m.columns = make([]interface{}, len(columnNames))
+		if err := m.scan(m.Cursor, m.columns); err != nil {
+			return false
+		}
+	}
+	return next
+}
+
+func (m *OracleRowReader) GetNextRow() ([]values.Value, error) {
+	row := make([]values.Value, len(m.columns))
+	for i, col := range m.columns {
+		switch col := col.(type) {
+		case int64:
+			if m.columnTypes[i] == flux.TFloat {
+				row[i] = values.NewFloat(float64(col))
+			} else {
+				row[i] = values.NewInt(col)
+			}
+		case float64:
+			if m.columnTypes[i] == flux.TInt {
+				row[i] = values.NewInt(int64(col))
+			} else {
+				row[i] = values.NewFloat(col)
+			}
+		case string:
+			v, err := oracleParseString(col, m.columnTypes[i])
+			if err != nil {
+				return nil, err
+			}
+			row[i] = v
+		case []byte:
+			row[i] = values.NewString(string(col))
+		case time.Time:
+			row[i] = values.NewTime(values.ConvertTime(col))
+		case fmt.Stringer: // numbers of some drivers
+			v, err := oracleParseString(col.String(), m.columnTypes[i])
+			if err != nil {
+				return nil, err
+			}
+			row[i] = v
+		case nil:
+			row[i] = values.NewNull(flux.SemanticType(m.columnTypes[i]))
+		default:
+			execute.PanicUnknownType(flux.TInvalid)
+		}
+	}
+	return row, nil
+}
+
+// oracleParseString converts a string to a value of the column type.
+// Numbers may be returned as strings to preserve their precision.
+func oracleParseString(s string, typ flux.ColType) (values.Value, error) {
+	switch typ {
+	case flux.TInt:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "cannot convert %q to an integer", s)
+		}
+		return values.NewInt(i), nil
+	case flux.TFloat:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Invalid, "cannot convert %q to a float", s)
+		}
+		return values.NewFloat(f), nil
+	default:
+		return values.NewString(s), nil
+	}
+}
+
+func (m *OracleRowReader) InitColumnNames(n []string) {
+	m.columnNames = n
+}
+
+func (m *OracleRowReader) InitColumnTypes(types []*sql.ColumnType) {
+	fluxTypes := make([]flux.ColType, len(types))
+	for i := 0; i < len(types); i++ {
+		fluxTypes[i] = oracleColumnType(types[i])
+	}
+	m.columnTypes = fluxTypes
+}
+
+// oracleColumnType maps the type of a column to a flux type.
+// The type names differ by driver, eg. go-ora names TIMESTAMP columns TimeStampDTY.
+func oracleColumnType(ct *sql.ColumnType) flux.ColType {
+	dbType := strings.ToUpper(ct.DatabaseTypeName())
+	switch {
+	case dbType == "NUMBER" || dbType == "VARNUM":
+		if precision, scale, ok := ct.DecimalSize(); ok && precision > 0 && scale == 0 {
+			return flux.TInt
+		}
+		return flux.TFloat
+	case dbType == "FLOAT" || strings.Contains(dbType, "BINARY_") || strings.HasPrefix(dbType, "IBFLOAT") ||
+		strings.HasPrefix(dbType, "IBDOUBLE") || dbType == "BFLOAT" || dbType == "BDOUBLE":
+		return flux.TFloat
+	case dbType == "DATE" || strings.HasPrefix(dbType, "TIMESTAMP"):
+		return flux.TTime
+	default:
+		return flux.TString
+	}
+}
+
+func (m *OracleRowReader) ColumnNames() []string {
+	return m.columnNames
+}
+
+func (m *OracleRowReader) ColumnTypes() []flux.ColType {
+	return m.columnTypes
+}
+
+func (m *OracleRowReader) SetColumns(i []interface{}) {
+	m.columns = i
+}
+
+func (m *OracleRowReader) Close() error {
+	if err := m.Cursor.Err(); err != nil {
+		return err
+	}
+	return m.Cursor.Close()
+}
+
+func NewOracleRowReader(r *sql.Rows) (execute.RowReader, error) {
+	reader := &OracleRowReader{
+		Cursor: r,
+	}
+	cols, err := r.Columns()
+	if err != nil {
+		return nil, err
+	}
+	reader.InitColumnNames(cols)
+
+	types, err := r.ColumnTypes()
+	if err != nil {
+		return nil, err
+	}
+	reader.InitColumnTypes(types)
+	return reader, nil
+}
+
+var fluxToOracle = map[flux.ColType]string{
+	flux.TFloat:  "BINARY_DOUBLE",
+	flux.TInt:    "NUMBER(19)",
+	flux.TUInt:   "NUMBER(20)",
+	flux.TString: "VARCHAR2(4000)",
+	flux.TBool:   "NUMBER(1)",
+	flux.TTime:   "TIMESTAMP(9)",
+}
+
+// OracleColumnTranslateFunc translates flux colTypes into their corresponding Oracle column type
+func OracleColumnTranslateFunc() translationFunc {
+	return func(f flux.ColType, colName string) (string, error) {
+		s, found := fluxToOracle[f]
+		if !found {
+			return "", errors.Newf(codes.Invalid, "Oracle does not support column type %s", f.String())
+		}
+		return colName + " " + s, nil
+	}
+}
+
+// oracleOpenFunction opens the database if the driver is built in.
+func oracleOpenFunction(dataSourceName string) openFunc {
+	return func() (*sql.DB, error) {
+		for _, name := range sql.Drivers() {
+			if name == oracleDriverName {
+				return sql.Open(oracleDriverName, dataSourceName)
+			}
+		}
+		return nil, errors.New(codes.Unimplemented, "the oracle driver is not available, flux must be built with the oracle build tag")
+	}
+}
+
+// oracleAddIfNotExist wraps a CREATE TABLE statement in a PL/SQL block that ignores
+// the error raised when the table already exists.
+func oracleAddIfNotExist(query string) string {
+	return fmt.Sprintf("BEGIN EXECUTE IMMEDIATE '%s'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -955 THEN RAISE; END IF; END;", strings.ReplaceAll(query, "'", "''"))
+}
+
+// oracleInsertAll returns an INSERT ALL statement for rows rows with positional bind variables.
+func oracleInsertAll(table string, colNames []string, rows int) string {
+	var b strings.Builder
+	b.WriteString("INSERT ALL")
+	into := fmt.Sprintf(" INTO %s (%s) VALUES ", table, strings.Join(colNames, ","))
+	n := 1
+	for i := 0; i < rows; i++ {
+		b.WriteString(into)
+		b.WriteString("(")
+		for j := range colNames {
+			if j > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(&b, ":%d", n)
+			n++
+		}
+		b.WriteString(")")
+	}
+	b.WriteString(" SELECT 1 FROM DUAL")
+	return b.String()
+}
+
+// oracleConvertArgs converts the values that cannot be bound to Oracle bind variables.
+func oracleConvertArgs(args []interface{}) []interface{} {
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case bool:
+			if arg {
+				converted[i] = int64(1)
+			} else {
+				converted[i] = int64(0)
+			}
+		case uint64:
+			// NUMBER(20) holds any uint64, the string is converted by the database
+			converted[i] = strconv.FormatUint(arg, 10)
+		default:
+			converted[i] = arg
+		}
+	}
+	return converted
+}
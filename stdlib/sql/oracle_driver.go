@@ -0,0 +1,8 @@
+//go:build oracle
+// +build oracle
+
+package sql
+
+// The oracle driver is only built in with the oracle build tag,
+// see the notes in oracle.go.
+import _ "github.com/sijms/go-ora"
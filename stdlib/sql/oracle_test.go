@@ -0,0 +1,92 @@
+package sql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/values"
+)
+
+func TestOracle_InsertAll(t *testing.T) {
+	want := "INSERT ALL INTO metrics (a,b) VALUES (:1,:2) INTO metrics (a,b) VALUES (:3,:4) SELECT 1 FROM DUAL"
+	if got := oracleInsertAll("metrics", []string{"a", "b"}, 2); want != got {
+		t.Errorf("unexpected statement -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestOracle_IfNoExist(t *testing.T) {
+	want := "BEGIN EXECUTE IMMEDIATE 'CREATE TABLE metrics (a VARCHAR2(4000) DEFAULT ''x'')'; EXCEPTION WHEN OTHERS THEN IF SQLCODE != -955 THEN RAISE; END IF; END;"
+	if got := oracleAddIfNotExist("CREATE TABLE metrics (a VARCHAR2(4000) DEFAULT 'x')"); want != got {
+		t.Errorf("unexpected statement -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestOracle_ConvertArgs(t *testing.T) {
+	want := []interface{}{int64(1), int64(0), "18446744073709551615", 1.5, nil}
+	got := oracleConvertArgs([]interface{}{true, false, uint64(18446744073709551615), 1.5, nil})
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected arguments -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestOracleParsing(t *testing.T) {
+	testCases := []struct {
+		name string
+		data *sqlmock.Rows
+		want [][]values.Value
+	}{
+		{
+			name: "strings",
+			data: sqlmock.NewRows([]string{"column"}).AddRow("6").AddRow([]byte("643")),
+			want: [][]values.Value{{values.NewString("6")}, {values.NewString("643")}},
+		},
+		{
+			name: "timestamp",
+			data: sqlmock.NewRows([]string{"column"}).AddRow(createTestTimes()[0]),
+			want: [][]values.Value{{values.NewTime(values.ConvertTime(createTestTimes()[0].(time.Time)))}},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			TestReader, err := NewOracleRowReader(mockRowsToSQLRows(tc.data))
+			if !cmp.Equal(nil, err) {
+				t.Fatalf("unexpected result -want/+got\n\n%s\n\n", cmp.Diff(nil, err))
+			}
+			i := 0
+			for TestReader.Next() {
+				row, _ := TestReader.GetNextRow()
+				if !cmp.Equal(tc.want[i], row) {
+					t.Fatalf("unexpected result -want/+got\n\n%s\n\n", cmp.Diff(tc.want[i], row))
+				}
+				i++
+			}
+		})
+	}
+}
+
+func TestOracle_ParseString(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		typ  flux.ColType
+		want values.Value
+	}{
+		{s: "42", typ: flux.TInt, want: values.NewInt(42)},
+		{s: "4.25", typ: flux.TFloat, want: values.NewFloat(4.25)},
+		{s: "abc", typ: flux.TString, want: values.NewString("abc")},
+	} {
+		got, err := oracleParseString(tc.s, tc.typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !tc.want.Equal(got) {
+			t.Errorf("unexpected value -want/+got:\n\t- %v\n\t+ %v", tc.want, got)
+		}
+	}
+	if _, err := oracleParseString("abc", flux.TInt); err == nil {
+		t.Error("expected an error converting a string that is not a number")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !js
+// +build !js
+
+package sql
+
+// The postgres driver does not build for WebAssembly,
+// so sql.from and sql.to do not support postgres there.
+import _ "github.com/lib/pq"
@@ -10,6 +10,7 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/go-cmp/cmp"
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/tablebuffer"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
 	"github.com/influxdata/flux/memory"
@@ -132,6 +133,98 @@ func TestFromRowReader(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("Projected columns", func(t *testing.T) {
+		var rr execute.RowReader = &MockRowReader{row: 0}
+		rr.(*MockRowReader).InitColumnTypes(nil)
+		rr = newProjectedRowReader(rr, []string{"bool", "int"})
+		table, err := read(context.Background(), rr, &memory.Allocator{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := executetest.ConvertTable(table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := &executetest.Table{
+			ColMeta: []flux.ColMeta{
+				{Label: "int", Type: flux.TInt},
+				{Label: "bool", Type: flux.TBool},
+			},
+			Data: [][]interface{}{
+				{int64(42), true},
+				{nil, nil},
+			},
+		}
+		want.Normalize()
+		got.Normalize()
+		if !cmp.Equal(want, got) {
+			t.Fatalf("unexpected result -want/+got\n\n%s\n\n", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("Buffer size", func(t *testing.T) {
+		var rr execute.RowReader = &MockRowReader{row: 0}
+		rr.(*MockRowReader).InitColumnTypes(nil)
+		ctx := tablebuffer.Inject(context.Background(), 1)
+		table, err := read(ctx, rr, &memory.Allocator{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []int
+		if err := table.Do(func(cr flux.ColReader) error {
+			got = append(got, cr.Len())
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if want := []int{1, 1}; !cmp.Equal(want, got) {
+			t.Fatalf("unexpected buffer lengths -want/+got\n\n%s\n\n", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		var rr execute.RowReader = &MockRowReader{row: 0}
+		rr.(*MockRowReader).InitColumnTypes(nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := read(ctx, rr, &memory.Allocator{}); err != context.Canceled {
+			t.Fatalf("expected read to be canceled, got %v", err)
+		}
+	})
+}
+
+func TestProjectedRowReader_SkipColumns(t *testing.T) {
+	rows := sqlmock.NewRows([]string{"a", "b", "c"}).
+		AddRow("x", "not read", int64(1)).
+		AddRow("y", "not read", int64(2))
+	reader, err := NewPostgresRowReader(mockRowsToSQLRows(rows))
+	if err != nil {
+		t.Fatal(err)
+	}
+	projected := newProjectedRowReader(reader, []string{"a", "c"})
+
+	want := [][]values.Value{
+		{values.NewString("x"), values.NewInt(1)},
+		{values.NewString("y"), values.NewInt(2)},
+	}
+	var got [][]values.Value
+	for projected.Next() {
+		// The value of the column that is not projected is not scanned.
+		if v := reader.(*PostgresRowReader).columns[1]; v != nil {
+			t.Errorf("expected the skipped column not to be scanned, got %v", v)
+		}
+		row, err := projected.GetNextRow()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, row)
+	}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected rows -want/+got:\n%s", cmp.Diff(want, got))
+	}
 }
 
 func TestMySqlParsing(t *testing.T) {
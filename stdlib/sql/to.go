@@ -7,10 +7,13 @@ import (
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/dependencies/sqlpool"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
 )
 
@@ -24,6 +27,11 @@ type ToSQLOpSpec struct {
 	DataSourceName string `json:"dataSourcename,omitempty"`
 	Table          string `json:"table,omitempty"`
 	BatchSize      int    `json:"batchSize,omitempty"`
+	// OnConflict is the conflict mode, empty for ConflictError.
+	OnConflict string   `json:"onConflict,omitempty"`
+	KeyColumns []string `json:"keyColumns,omitempty"`
+	// NoTransaction commits each batch on its own rather than all of them at once.
+	NoTransaction bool `json:"noTransaction,omitempty"`
 }
 
 func init() {
@@ -72,7 +80,29 @@ func (o *ToSQLOpSpec) ReadArgs(args flux.Arguments) error {
 		o.BatchSize = int(b)
 	}
 
-	return err
+	if c, ok, err := args.GetString("onConflict"); err != nil {
+		return err
+	} else if ok && c != ConflictError {
+		o.OnConflict = c
+	}
+	if cols, ok, err := args.GetArray("keyColumns", semantic.String); err != nil {
+		return err
+	} else if ok {
+		if o.KeyColumns, err = interpreter.ToStringArray(cols); err != nil {
+			return err
+		}
+	}
+	if err := validateConflict(o.DriverName, o.OnConflict, o.KeyColumns); err != nil {
+		return err
+	}
+
+	if tx, ok, err := args.GetBool("transactional"); err != nil {
+		return err
+	} else if ok {
+		o.NoTransaction = !tx
+	}
+
+	return nil
 }
 
 func createToSQLOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
@@ -107,6 +137,9 @@ func (o *ToSQLProcedureSpec) Copy() plan.ProcedureSpec {
 			DataSourceName: s.DataSourceName,
 			Table:          s.Table,
 			BatchSize:      s.BatchSize,
+			OnConflict:     s.OnConflict,
+			KeyColumns:     s.KeyColumns,
+			NoTransaction:  s.NoTransaction,
 		},
 	}
 	return res
@@ -128,7 +161,7 @@ func createToSQLTransformation(id execute.DatasetID, mode execute.AccumulationMo
 	cache := execute.NewTableBuilderCache(a.Allocator())
 	d := execute.NewDataset(id, mode, cache)
 	deps := flux.GetDependencies(a.Context())
-	t, err := NewToSQLTransformation(d, deps, cache, s)
+	t, err := newToSQLTransformation(d, deps, sqlpool.Get(a.Context()), cache, s)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -142,6 +175,8 @@ type ToSQLTransformation struct {
 	spec  *ToSQLProcedureSpec
 	db    *sql.DB
 	tx    *sql.Tx
+	// release releases the database when the transformation is done.
+	release func() error
 }
 
 func (t *ToSQLTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
@@ -149,6 +184,12 @@ func (t *ToSQLTransformation) RetractTable(id execute.DatasetID, key flux.GroupK
 }
 
 func NewToSQLTransformation(d execute.Dataset, deps flux.Dependencies, cache execute.TableBuilderCache, spec *ToSQLProcedureSpec) (*ToSQLTransformation, error) {
+	return newToSQLTransformation(d, deps, nil, cache, spec)
+}
+
+// newToSQLTransformation creates the transformation with the
+// database of the pool, or with a database of its own without a pool.
+func newToSQLTransformation(d execute.Dataset, deps flux.Dependencies, pool *sqlpool.Pool, cache execute.TableBuilderCache, spec *ToSQLProcedureSpec) (*ToSQLTransformation, error) {
 	validator, err := deps.URLValidator()
 	if err != nil {
 		return nil, err
@@ -158,23 +199,25 @@ func NewToSQLTransformation(d execute.Dataset, deps flux.Dependencies, cache exe
 	}
 
 	// validate the data driver name and source name.
-	db, err := getOpenFunc(spec.Spec.DriverName, spec.Spec.DataSourceName)()
+	db, release, err := openDB(pool, spec.Spec.DriverName, spec.Spec.DataSourceName)
 	if err != nil {
 		return nil, err
 	}
 	var tx *sql.Tx
-	if supportsTx(spec.Spec.DriverName) {
+	if supportsTx(spec.Spec.DriverName) && !spec.Spec.NoTransaction {
 		tx, err = db.Begin()
 		if err != nil {
+			_ = release()
 			return nil, err
 		}
 	}
 	return &ToSQLTransformation{
-		d:     d,
-		cache: cache,
-		spec:  spec,
-		db:    db,
-		tx:    tx,
+		d:       d,
+		cache:   cache,
+		spec:    spec,
+		db:      db,
+		tx:      tx,
+		release: release,
 	}, nil
 }
 
@@ -189,7 +232,13 @@ func (t *ToSQLTransformation) Process(id execute.DatasetID, tbl flux.Table) (err
 		return err
 	}
 	for i := range valStrings {
-		if err := ExecuteQueries(t.tx, t.spec.Spec, colNames, &valStrings[i], &valArgs[i]); err != nil {
+		if t.spec.Spec.DriverName == "clickhouse" {
+			if err := clickhouseInsert(t.db, t.spec.Spec, colNames, valArgs[i]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := t.executeBatch(colNames, &valStrings[i], &valArgs[i]); err != nil {
 			return err
 		}
 	}
@@ -197,6 +246,23 @@ func (t *ToSQLTransformation) Process(id execute.DatasetID, tbl flux.Table) (err
 	return err
 }
 
+// executeBatch executes the insert of a batch within the transaction of the query,
+// or within a transaction of its own if each batch is committed on its own.
+func (t *ToSQLTransformation) executeBatch(colNames []string, valueStrings *[]string, valueArgs *[]interface{}) error {
+	if t.tx != nil || !supportsTx(t.spec.Spec.DriverName) {
+		return ExecuteQueries(t.tx, t.spec.Spec, colNames, valueStrings, valueArgs)
+	}
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	// ExecuteQueries rolls back the transaction if the insert fails
+	if err := ExecuteQueries(tx, t.spec.Spec, colNames, valueStrings, valueArgs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
 func (t *ToSQLTransformation) UpdateWatermark(id execute.DatasetID, pt execute.Time) error {
 	return t.d.UpdateWatermark(pt)
 }
@@ -206,7 +272,7 @@ func (t *ToSQLTransformation) UpdateProcessingTime(id execute.DatasetID, pt exec
 }
 
 func (t *ToSQLTransformation) Finish(id execute.DatasetID, err error) {
-	if supportsTx(t.spec.Spec.DriverName) {
+	if t.tx != nil {
 		var txErr error
 		if err == nil {
 			txErr = t.tx.Commit()
@@ -216,7 +282,9 @@ func (t *ToSQLTransformation) Finish(id execute.DatasetID, err error) {
 		if txErr != nil {
 			err = errors.Wrap(err, codes.Inherit, txErr)
 		}
-		if dbErr := t.db.Close(); dbErr != nil {
+	}
+	if t.spec.Spec.DriverName != "sqlmock" {
+		if dbErr := t.release(); dbErr != nil {
 			err = errors.Wrap(err, codes.Inherit, dbErr)
 		}
 	}
@@ -279,13 +347,18 @@ func getTranslationFunc(driverName string) (func() translationFunc, error) {
 		return BigQueryColumnTranslateFunc, nil
 	case "hdb":
 		return HdbColumnTranslateFunc, nil
+	case "clickhouse":
+		return ClickHouseColumnTranslateFunc, nil
+	case oracleDriverName:
+		return OracleColumnTranslateFunc, nil
 	default:
 		return nil, errors.Newf(codes.Internal, "invalid driverName: %s", driverName)
 	}
 }
 
 func supportsTx(driverName string) bool {
-	return driverName != "sqlmock" && driverName != "awsathena"
+	// ClickHouse has no transactions, its driver uses them to batch inserts (see clickhouse.go)
+	return driverName != "sqlmock" && driverName != "awsathena" && driverName != "clickhouse"
 }
 
 func CreateInsertComponents(t *ToSQLTransformation, tbl flux.Table) (colNames []string, valStringArray [][]string, valArgsArray [][]interface{}, err error) {
@@ -318,6 +391,10 @@ func CreateInsertComponents(t *ToSQLTransformation, tbl flux.Table) (colNames []
 		}
 	}
 
+	if pk := primaryKeyClause(t.spec.Spec); pk != "" {
+		newSQLTableCols = append(newSQLTableCols, pk)
+	}
+
 	// Creates the placeholders for values in the query
 	// eg: (?,?)
 	valuePlaceHolders := fmt.Sprintf("(%s)", strings.Join(questionMarks, ","))
@@ -347,10 +424,18 @@ func CreateInsertComponents(t *ToSQLTransformation, tbl flux.Table) (colNames []
 				q = hdbAddIfNotExist(t.spec.Spec.Table, q)
 				// SAP HANA does not support INSERT/UPDATE batching via a single SQL command
 				batchSize = 1
+			} else if t.spec.Spec.DriverName == oracleDriverName { // Oracle does not support IF NOT EXIST
+				q = oracleAddIfNotExist(fmt.Sprintf("CREATE TABLE %s (%s)", t.spec.Spec.Table, strings.Join(newSQLTableCols, ",")))
+			} else if t.spec.Spec.DriverName == "clickhouse" { // ClickHouse requires a table engine
+				q = clickhouseCreateTable(t.spec.Spec.Table, newSQLTableCols)
 			} else {
 				q = fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", t.spec.Spec.Table, strings.Join(newSQLTableCols, ","))
 			}
-			_, err = t.tx.Exec(q)
+			if t.tx != nil {
+				_, err = t.tx.Exec(q)
+			} else {
+				_, err = t.db.Exec(q)
+			}
 			if err != nil {
 				return err
 			}
@@ -442,13 +527,20 @@ func ExecuteQueries(tx *sql.Tx, s *ToSQLOpSpec, colNames []string, valueStrings
 	}
 
 	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s", s.Table, strings.Join(colNames, ","), concatValueStrings)
+	query = addConflictClause(s, colNames, query)
 	if isMssqlDriver(s.DriverName) && mssqlCheckParameter(s.DataSourceName, mssqlIdentityInsertEnabled) {
 		prologue := fmt.Sprintf("DECLARE @tableHasIdentity INT = OBJECTPROPERTY(OBJECT_ID('%s'), 'TableHasIdentity'); IF @tableHasIdentity = 1 BEGIN SET IDENTITY_INSERT %s ON END", s.Table, s.Table)
 		epilogue := fmt.Sprintf("IF @tableHasIdentity = 1 BEGIN SET IDENTITY_INSERT %s OFF END", s.Table)
 		query = strings.Join([]string{prologue, query, epilogue}, "; ")
 	}
+	args := *valueArgs
+	// Oracle uses :n for placeholders and does not support multi-row VALUES
+	if s.DriverName == oracleDriverName {
+		query = oracleInsertAll(s.Table, colNames, len(*valueStrings))
+		args = oracleConvertArgs(args)
+	}
 	if s.DriverName != "sqlmock" {
-		_, err := tx.Exec(query, *valueArgs...)
+		_, err := tx.Exec(query, args...)
 		if err != nil {
 			// this err which is extremely helpful as it comes from the SQL driver should be
 			// bubbled up further up the stack so user can see the issue
@@ -107,6 +107,20 @@ func TestTranslationDriverReturn(t *testing.T) {
 		t.Fail()
 	}
 
+	// verify that valid returns expected happiness for ClickHouse
+	_, err = getTranslationFunc("clickhouse")
+	if !cmp.Equal(nil, err) {
+		t.Log(cmp.Diff(nil, err))
+		t.Fail()
+	}
+
+	// verify that valid returns expected happiness for Oracle
+	_, err = getTranslationFunc("oracle")
+	if !cmp.Equal(nil, err) {
+		t.Log(cmp.Diff(nil, err))
+		t.Fail()
+	}
+
 }
 
 func TestSqliteTranslation(t *testing.T) {
@@ -394,3 +408,89 @@ func TestHdbTranslation(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestClickHouseTranslation(t *testing.T) {
+	clickhouseTypeTranslations := map[string]flux.ColType{
+		"Float64":              flux.TFloat,
+		"Int64":                flux.TInt,
+		"UInt64":               flux.TUInt,
+		"String":               flux.TString,
+		"DateTime64(9, 'UTC')": flux.TTime,
+		"UInt8":                flux.TBool,
+	}
+
+	columnLabel := "apples"
+	// verify that valid returns expected happiness for clickhouse
+	sqlT, err := getTranslationFunc("clickhouse")
+	if !cmp.Equal(nil, err) {
+		t.Log(cmp.Diff(nil, err))
+		t.Fail()
+	}
+
+	for dbTypeString, fluxType := range clickhouseTypeTranslations {
+		v, err := sqlT()(fluxType, columnLabel)
+		if !cmp.Equal(nil, err) {
+			t.Log(cmp.Diff(nil, err))
+			t.Fail()
+		}
+		if !cmp.Equal(columnLabel+" Nullable("+dbTypeString+")", v) {
+			t.Log(cmp.Diff(columnLabel+" Nullable("+dbTypeString+")", v))
+			t.Fail()
+		}
+	}
+
+	// test no match
+	var _unsupportedType flux.ColType = 666
+	_, err = sqlT()(_unsupportedType, columnLabel)
+	if cmp.Equal(nil, err) {
+		t.Log(cmp.Diff(nil, err))
+		t.Fail()
+	}
+	if !cmp.Equal("ClickHouse does not support column type unknown", err.Error()) {
+		t.Log(cmp.Diff("ClickHouse does not support column type unknown", err.Error()))
+		t.Fail()
+	}
+}
+
+func TestOracleTranslation(t *testing.T) {
+	oracleTypeTranslations := map[string]flux.ColType{
+		"BINARY_DOUBLE":  flux.TFloat,
+		"NUMBER(19)":     flux.TInt,
+		"NUMBER(20)":     flux.TUInt,
+		"VARCHAR2(4000)": flux.TString,
+		"TIMESTAMP(9)":   flux.TTime,
+		"NUMBER(1)":      flux.TBool,
+	}
+
+	columnLabel := "apples"
+	// verify that valid returns expected happiness for oracle
+	sqlT, err := getTranslationFunc("oracle")
+	if !cmp.Equal(nil, err) {
+		t.Log(cmp.Diff(nil, err))
+		t.Fail()
+	}
+
+	for dbTypeString, fluxType := range oracleTypeTranslations {
+		v, err := sqlT()(fluxType, columnLabel)
+		if !cmp.Equal(nil, err) {
+			t.Log(cmp.Diff(nil, err))
+			t.Fail()
+		}
+		if !cmp.Equal(columnLabel+" "+dbTypeString, v) {
+			t.Log(cmp.Diff(columnLabel+" "+dbTypeString, v))
+			t.Fail()
+		}
+	}
+
+	// test no match
+	var _unsupportedType flux.ColType = 666
+	_, err = sqlT()(_unsupportedType, columnLabel)
+	if cmp.Equal(nil, err) {
+		t.Log(cmp.Diff(nil, err))
+		t.Fail()
+	}
+	if !cmp.Equal("Oracle does not support column type unknown", err.Error()) {
+		t.Log(cmp.Diff("Oracle does not support column type unknown", err.Error()))
+		t.Fail()
+	}
+}
@@ -1,6 +1,7 @@
 package sql_test
 
 import (
+	"database/sql"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -413,6 +414,40 @@ func TestSqlite3To(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "upsert",
+			Raw:  `import "sql" from(bucket: "mybucket") |> sql.to(driverName:"sqlite3", dataSourceName:"file::memory:", table:"TestTable", onConflict: "update", keyColumns: ["_time"], transactional: false)`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "from0",
+						Spec: &influxdb.FromOpSpec{
+							Bucket: influxdb.NameOrID{Name: "mybucket"},
+						},
+					},
+					{
+						ID: "toSQL1",
+						Spec: &fsql.ToSQLOpSpec{
+							DriverName:     "sqlite3",
+							DataSourceName: "file::memory:",
+							Table:          "TestTable",
+							BatchSize:      fsql.DefaultBatchSize,
+							OnConflict:     fsql.ConflictUpdate,
+							KeyColumns:     []string{"_time"},
+							NoTransaction:  true,
+						},
+					},
+				},
+				Edges: []flux.Edge{
+					{Parent: "from0", Child: "toSQL1"},
+				},
+			},
+		},
+		{
+			Name:    "update without key columns",
+			Raw:     `import "sql" from(bucket: "mybucket") |> sql.to(driverName:"sqlite3", dataSourceName:"file::memory:", table:"TestTable", onConflict: "update")`,
+			WantErr: true,
+		},
 	}
 	for _, tc := range tests {
 		tc := tc
@@ -688,3 +723,64 @@ func TestToSQLite3_Process(t *testing.T) {
 		})
 	}
 }
+
+func TestToSQLite3_Upsert(t *testing.T) {
+	dsn := "file:upsert?mode=memory&cache=shared"
+	// keep the in-memory database alive between the writes
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	write := func(data [][]interface{}) {
+		d := executetest.NewDataset(executetest.RandomDatasetID())
+		c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+		c.SetTriggerSpec(plan.DefaultTriggerSpec)
+		transformation, err := fsql.NewToSQLTransformation(d, dependenciestest.Default(), c, &fsql.ToSQLProcedureSpec{
+			Spec: &fsql.ToSQLOpSpec{
+				DriverName:     "sqlite3",
+				DataSourceName: dsn,
+				Table:          "upserts",
+				BatchSize:      10000,
+				OnConflict:     fsql.ConflictUpdate,
+				KeyColumns:     []string{"host"},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = transformation.Process(executetest.RandomDatasetID(), executetest.MustCopyTable(&executetest.Table{
+			ColMeta: []flux.ColMeta{
+				{Label: "host", Type: flux.TString},
+				{Label: "_value", Type: flux.TInt},
+			},
+			Data: data,
+		}))
+		transformation.Finish(executetest.RandomDatasetID(), err)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	write([][]interface{}{{"a", int64(1)}, {"b", int64(2)}})
+	write([][]interface{}{{"b", int64(3)}, {"c", int64(4)}})
+
+	rows, err := db.Query("SELECT host, _value FROM upserts ORDER BY host")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var got [][]interface{}
+	for rows.Next() {
+		var host string
+		var value int64
+		if err := rows.Scan(&host, &value); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, []interface{}{host, value})
+	}
+	want := [][]interface{}{{"a", int64(1)}, {"b", int64(3)}, {"c", int64(4)}}
+	if !cmp.Equal(want, got) {
+		t.Errorf("unexpected rows -want/+got:\n%s", cmp.Diff(want, got))
+	}
+}
@@ -3,6 +3,7 @@ package strings
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"unicode"
 	"unicode/utf8"
@@ -473,8 +474,101 @@ func init() {
 				return values.NewString(strings.Join(newStringArray, argVals[1].Str())), nil
 			}, false,
 		),
+		"format": values.NewFunction(
+			"format",
+			runtime.MustLookupBuiltinType("strings", "format"),
+			func(ctx context.Context, args values.Object) (values.Value, error) {
+				t, ok := args.Get("t")
+				if !ok {
+					return nil, fmt.Errorf("missing argument %q", "t")
+				}
+				if t.IsNull() || t.Type().Nature() != semantic.String {
+					return nil, fmt.Errorf("expected argument %q to be of type %v, got type %v value %v", "t", semantic.String, t.Type().Nature(), t)
+				}
+				vals, ok := args.Get("values")
+				if !ok {
+					return nil, fmt.Errorf("missing argument %q", "values")
+				}
+				if vals.Type().Nature() != semantic.Object {
+					return nil, fmt.Errorf("expected argument %q to be of type %v, got type %v value %v", "values", semantic.Object, vals.Type().Nature(), vals)
+				}
+
+				str, err := format(t.Str(), vals.Object())
+				if err != nil {
+					return nil, err
+				}
+				return values.NewString(str), nil
+			}, false,
+		),
 	}
 
 	runtime.RegisterPackageValue("strings", "joinStr", SpecialFns["joinStr"])
+	runtime.RegisterPackageValue("strings", "format", SpecialFns["format"])
 
 }
+
+// format replaces the ${name} placeholders of the template with the
+// properties of the record, "$$" is replaced with a single "$".
+func format(t string, record values.Object) (string, error) {
+	var sb strings.Builder
+	for {
+		i := strings.IndexByte(t, '$')
+		if i < 0 || i == len(t)-1 {
+			sb.WriteString(t)
+			return sb.String(), nil
+		}
+		sb.WriteString(t[:i])
+		switch t[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			t = t[i+2:]
+			continue
+		case '{':
+		default:
+			sb.WriteByte('$')
+			t = t[i+1:]
+			continue
+		}
+		end := strings.IndexByte(t[i:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in template at position %d", i)
+		}
+		name := t[i+2 : i+end]
+		v, ok := record.Get(name)
+		if !ok {
+			return "", fmt.Errorf("template placeholder %q has no value", name)
+		}
+		str, err := formatValue(v)
+		if err != nil {
+			return "", fmt.Errorf("cannot format placeholder %q: %v", name, err)
+		}
+		sb.WriteString(str)
+		t = t[i+end+1:]
+	}
+}
+
+// formatValue converts a value to a string like the string() function,
+// null values are formatted as empty strings.
+func formatValue(v values.Value) (string, error) {
+	if v.IsNull() {
+		return "", nil
+	}
+	switch v.Type().Nature() {
+	case semantic.String:
+		return v.Str(), nil
+	case semantic.Int:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case semantic.UInt:
+		return strconv.FormatUint(v.UInt(), 10), nil
+	case semantic.Float:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case semantic.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case semantic.Time:
+		return v.Time().String(), nil
+	case semantic.Duration:
+		return v.Duration().String(), nil
+	default:
+		return "", fmt.Errorf("unsupported type %v", v.Type())
+	}
+}
@@ -1282,6 +1282,67 @@ func TestJoinStr(t *testing.T) {
 
 }
 
+func TestFormat(t *testing.T) {
+	fluxFunc := SpecialFns["format"]
+	record := values.NewObjectWithValues(map[string]values.Value{
+		"host": values.NewString("server01"),
+		"pct":  values.NewFloat(92.5),
+		"n":    values.NewInt(3),
+		"ok":   values.NewBool(false),
+		"none": values.NewNull(semantic.BasicString),
+	})
+	testCases := []struct {
+		name    string
+		t       string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "placeholders",
+			t:    "disk ${host} at ${pct}%",
+			want: "disk server01 at 92.5%",
+		},
+		{
+			name: "int bool and null values",
+			t:    "${n} checks, ok=${ok}, note=${none}",
+			want: "3 checks, ok=false, note=",
+		},
+		{
+			name: "escaped and lone dollars",
+			t:    "$$${n} costs $5$",
+			want: "$3 costs $5$",
+		},
+		{
+			name:    "missing value",
+			t:       "host ${name}",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated placeholder",
+			t:       "host ${host",
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			fluxArg := values.NewObjectWithValues(map[string]values.Value{"t": values.NewString(tc.t), "values": record})
+			got, err := fluxFunc.Call(dependenciestest.Default().Inject(context.Background()), fluxArg)
+			if err != nil {
+				if !tc.wantErr {
+					t.Fatal(err)
+				}
+				return
+			} else if tc.wantErr {
+				t.Fatalf("expected an error, got %v", got)
+			}
+			if tc.want != got.Str() {
+				t.Errorf("expected %q, got %q", tc.want, got.Str())
+			}
+		})
+	}
+}
+
 func TestStrLength(t *testing.T) {
 	testCases := []struct {
 		name string
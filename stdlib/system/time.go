@@ -2,8 +2,8 @@ package system
 
 import (
 	"context"
-	"time"
 
+	"github.com/influxdata/flux/dependencies/clock"
 	"github.com/influxdata/flux/runtime"
 	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/values"
@@ -16,7 +16,7 @@ func init() {
 		systemTimeFuncName,
 		semantic.NewFunctionType(semantic.BasicTime, nil),
 		func(ctx context.Context, args values.Object) (values.Value, error) {
-			return values.NewTime(values.ConvertTime(time.Now().UTC())), nil
+			return values.NewTime(values.ConvertTime(clock.Get(ctx).Now().UTC())), nil
 		},
 		false,
 	))
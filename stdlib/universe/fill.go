@@ -2,6 +2,7 @@ package universe
 
 import (
 	"context"
+	"math"
 	"strconv"
 
 	"github.com/influxdata/flux"
@@ -23,11 +24,23 @@ import (
 
 const FillKind = "fill"
 
+const (
+	// FillMethodLinear fills nulls with the linear interpolation
+	// of the closest non-null values before and after them.
+	FillMethodLinear = "linear"
+	// FillMethodNearest fills nulls with the non-null value
+	// that is the closest in time.
+	FillMethodNearest = "nearest"
+)
+
 type FillOpSpec struct {
-	Column      string `json:"column"`
-	Type        string `json:"type"`
-	Value       string `json:"value"`
-	UsePrevious bool   `json:"use_previous"`
+	Column      string        `json:"column"`
+	Type        string        `json:"type"`
+	Value       string        `json:"value"`
+	UsePrevious bool          `json:"use_previous"`
+	Method      string        `json:"method,omitempty"`
+	MaxGap      flux.Duration `json:"max_gap,omitempty"`
+	TimeColumn  string        `json:"time_column,omitempty"`
 }
 
 func init() {
@@ -81,17 +94,58 @@ func CreateFillOpSpec(args flux.Arguments, a *flux.Administration) (flux.Operati
 	if err != nil {
 		return nil, err
 	}
-	if prevOk == valOk {
-		return nil, errors.New(codes.Invalid, "fill requires exactly one of value or usePrevious")
+
+	method, methodOk, err := args.GetString("method")
+	if err != nil {
+		return nil, err
+	}
+
+	if n := countTrue(valOk, prevOk, methodOk); n != 1 {
+		return nil, errors.New(codes.Invalid, "fill requires exactly one of value, usePrevious or method")
 	}
 
 	if prevOk {
 		spec.UsePrevious = usePrevious
 	}
 
+	if methodOk {
+		if method != FillMethodLinear && method != FillMethodNearest {
+			return nil, errors.Newf(codes.Invalid, "unknown fill method %q, must be %q or %q", method, FillMethodLinear, FillMethodNearest)
+		}
+		spec.Method = method
+
+		spec.TimeColumn = execute.DefaultTimeColLabel
+		if col, ok, err := args.GetString("timeColumn"); err != nil {
+			return nil, err
+		} else if ok {
+			spec.TimeColumn = col
+		}
+	}
+
+	if maxGap, ok, err := args.GetDuration("maxGap"); err != nil {
+		return nil, err
+	} else if ok {
+		if !methodOk {
+			return nil, errors.New(codes.Invalid, "fill requires a method to use maxGap")
+		} else if !maxGap.IsPositive() {
+			return nil, errors.Newf(codes.Invalid, "maxGap must be positive, got %v", maxGap)
+		}
+		spec.MaxGap = maxGap
+	}
+
 	return spec, nil
 }
 
+func countTrue(bs ...bool) int {
+	n := 0
+	for _, b := range bs {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
 func newFillOp() flux.OperationSpec {
 	return new(FillOpSpec)
 }
@@ -105,6 +159,11 @@ type FillProcedureSpec struct {
 	Column      string
 	Value       values.Value
 	UsePrevious bool
+	Method      string
+	// MaxGap is the largest duration between the non-null values
+	// around nulls that a method fills. Zero means no limit.
+	MaxGap     flux.Duration
+	TimeColumn string
 }
 
 func newFillProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
@@ -116,8 +175,11 @@ func newFillProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.Proce
 	pspec := &FillProcedureSpec{
 		Column:      spec.Column,
 		UsePrevious: spec.UsePrevious,
+		Method:      spec.Method,
+		MaxGap:      spec.MaxGap,
+		TimeColumn:  spec.TimeColumn,
 	}
-	if !spec.UsePrevious {
+	if !spec.UsePrevious && spec.Method == "" {
 		switch spec.Type {
 		case "bool":
 			v, err := strconv.ParseBool(spec.Value)
@@ -203,13 +265,20 @@ func (t *fillTransformation) RetractTable(id execute.DatasetID, key flux.GroupKe
 
 func (t *fillTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
 	colIdx := execute.ColIdx(t.spec.Column, tbl.Cols())
-	if colIdx < 0 && t.spec.UsePrevious {
+	if colIdx < 0 && (t.spec.UsePrevious || t.spec.Method != "") {
 		// usePrevious was used on a column that doesn't exist. In this case, just
 		// act as a passthrough. This functionality says "I was provided a non-existent
 		// value, so the new value also doesn't exist.
 		return t.d.Process(tbl)
 	}
 	key := tbl.Key()
+	if t.spec.Method != "" {
+		if key.HasCol(t.spec.Column) {
+			// The column is constant, so there are no values to fill its nulls with.
+			return t.d.Process(tbl)
+		}
+		return t.fillWithMethod(tbl, colIdx)
+	}
 	if idx := execute.ColIdx(t.spec.Column, key.Cols()); idx >= 0 {
 		if key.IsNull(idx) {
 			var err error
@@ -302,3 +371,146 @@ func (t *fillTransformation) addNullColumn(typ flux.ColType, l int) array.Interf
 	}
 	return builder.NewArray()
 }
+
+// fillWithMethod fills the nulls of a column with values that the
+// method computes from the closest non-null values around them.
+// The table is buffered because those values may be in later buffers.
+func (t *fillTransformation) fillWithMethod(tbl flux.Table, colIdx int) error {
+	col := tbl.Cols()[colIdx]
+	if t.spec.Method == FillMethodLinear && col.Type != flux.TInt && col.Type != flux.TUInt && col.Type != flux.TFloat {
+		return errors.Newf(codes.FailedPrecondition, "cannot fill %v column %q with linear interpolation; expected a numeric column", col.Type, col.Label)
+	}
+	timeIdx := execute.ColIdx(t.spec.TimeColumn, tbl.Cols())
+	if timeIdx < 0 {
+		return errors.Newf(codes.FailedPrecondition, "fill time column %q does not exist", t.spec.TimeColumn)
+	} else if typ := tbl.Cols()[timeIdx].Type; typ != flux.TTime {
+		return errors.Newf(codes.FailedPrecondition, "fill time column %q has type %v; expected time", t.spec.TimeColumn, typ)
+	}
+
+	var (
+		buffers []flux.ColReader
+		times   []values.Time
+		vs      []values.Value
+	)
+	defer func() {
+		for _, cr := range buffers {
+			cr.Release()
+		}
+	}()
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		cr.Retain()
+		buffers = append(buffers, cr)
+		ts := cr.Times(timeIdx)
+		for i, l := 0, cr.Len(); i < l; i++ {
+			if ts.IsNull(i) {
+				return errors.Newf(codes.FailedPrecondition, "null value in fill time column %q", t.spec.TimeColumn)
+			}
+			times = append(times, values.Time(ts.Value(i)))
+			vs = append(vs, execute.ValueForRow(cr, i, colIdx))
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	filled := fillValues(t.spec.Method, t.spec.MaxGap, times, vs)
+
+	out, err := table.StreamWithContext(t.ctx, tbl.Key(), tbl.Cols(), func(ctx context.Context, w *table.StreamWriter) error {
+		offset := 0
+		for _, cr := range buffers {
+			arrs := make([]array.Interface, len(cr.Cols()))
+			for j := range cr.Cols() {
+				if j != colIdx {
+					arrs[j] = table.Values(cr, j)
+					arrs[j].Retain()
+					continue
+				}
+				b := arrow.NewBuilder(col.Type, t.alloc)
+				b.Resize(cr.Len())
+				for _, v := range filled[offset : offset+cr.Len()] {
+					if err := arrow.AppendValue(b, v); err != nil {
+						return err
+					}
+				}
+				arrs[j] = b.NewArray()
+			}
+			offset += cr.Len()
+			if err := w.Write(arrs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return t.d.Process(out)
+}
+
+// fillValues returns the values with their nulls filled by the method.
+// A null is only filled when it has non-null values on both sides that
+// are at most maxGap apart, unless maxGap is zero. Without maxGap, the nearest
+// method also fills the nulls before the first and after the last non-null value.
+func fillValues(method string, maxGap values.Duration, times []values.Time, vs []values.Value) []values.Value {
+	// next[i] is the index of the first non-null value after i, or -1.
+	next := make([]int, len(vs))
+	n := -1
+	for i := len(vs) - 1; i >= 0; i-- {
+		next[i] = n
+		if !vs[i].IsNull() {
+			n = i
+		}
+	}
+
+	filled := make([]values.Value, len(vs))
+	prev := -1
+	for i, v := range vs {
+		filled[i] = v
+		if !v.IsNull() {
+			prev = i
+			continue
+		}
+		p, n := prev, next[i]
+		if !maxGap.IsZero() && (p < 0 || n < 0 || times[p].Add(maxGap) < times[n]) {
+			continue
+		}
+		switch method {
+		case FillMethodLinear:
+			if p >= 0 && n >= 0 {
+				filled[i] = interpolateValue(vs[p], vs[n], times[p], times[n], times[i])
+			}
+		case FillMethodNearest:
+			switch {
+			case p < 0 && n < 0:
+			case p < 0:
+				filled[i] = vs[n]
+			case n < 0:
+				filled[i] = vs[p]
+			case times[n]-times[i] < times[i]-times[p]:
+				filled[i] = vs[n]
+			default:
+				filled[i] = vs[p]
+			}
+		}
+	}
+	return filled
+}
+
+// interpolateValue returns the value at time t on the line between
+// the values x0 at time t0 and x1 at time t1, rounded for integers.
+func interpolateValue(x0, x1 values.Value, t0, t1, t values.Time) values.Value {
+	if t1 == t0 {
+		return x0
+	}
+	r := float64(t-t0) / float64(t1-t0)
+	switch x0.Type().Nature() {
+	case semantic.Int:
+		return values.NewInt(x0.Int() + int64(math.Round(float64(x1.Int()-x0.Int())*r)))
+	case semantic.UInt:
+		if x1.UInt() >= x0.UInt() {
+			return values.NewUInt(x0.UInt() + uint64(math.Round(float64(x1.UInt()-x0.UInt())*r)))
+		}
+		return values.NewUInt(x0.UInt() - uint64(math.Round(float64(x0.UInt()-x1.UInt())*r)))
+	default:
+		return values.NewFloat(x0.Float() + (x1.Float()-x0.Float())*r)
+	}
+}
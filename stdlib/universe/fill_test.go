@@ -70,6 +70,19 @@ func TestFillOperation_Marshaling(t *testing.T) {
 	}
 
 	querytest.OperationMarshalingTestHelper(t, data, op)
+
+	data = []byte(`{"id":"fill","kind":"fill","spec":{"column":"_value","method":"linear","max_gap":"5m","time_column":"_time"}}`)
+	op = &flux.Operation{
+		ID: "fill",
+		Spec: &universe.FillOpSpec{
+			Column:     "_value",
+			Method:     universe.FillMethodLinear,
+			MaxGap:     flux.ConvertDuration(5 * time.Minute),
+			TimeColumn: "_time",
+		},
+	}
+
+	querytest.OperationMarshalingTestHelper(t, data, op)
 }
 
 func TestFill_NewQuery(t *testing.T) {
@@ -728,6 +741,115 @@ func TestFill_Process(t *testing.T) {
 				KeyValues: []interface{}{1.0},
 			}},
 		},
+		{
+			name: "linear",
+			spec: &universe.FillProcedureSpec{
+				Column:     "_value",
+				Method:     universe.FillMethodLinear,
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), nil},
+					{execute.Time(2), 2.0},
+					{execute.Time(3), nil},
+					{execute.Time(5), nil},
+					{execute.Time(6), 6.0},
+					{execute.Time(7), nil},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), nil},
+					{execute.Time(2), 2.0},
+					{execute.Time(3), 3.0},
+					{execute.Time(5), 5.0},
+					{execute.Time(6), 6.0},
+					{execute.Time(7), nil},
+				},
+			}},
+		},
+		{
+			name: "linear int with max gap",
+			spec: &universe.FillProcedureSpec{
+				Column:     "_value",
+				Method:     universe.FillMethodLinear,
+				MaxGap:     flux.ConvertDuration(3),
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(0)},
+					{execute.Time(2), nil},
+					{execute.Time(4), int64(10)},
+					{execute.Time(5), nil},
+					{execute.Time(8), int64(0)},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TInt},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), int64(0)},
+					{execute.Time(2), int64(3)},
+					{execute.Time(4), int64(10)},
+					{execute.Time(5), nil},
+					{execute.Time(8), int64(0)},
+				},
+			}},
+		},
+		{
+			name: "nearest",
+			spec: &universe.FillProcedureSpec{
+				Column:     "_value",
+				Method:     universe.FillMethodNearest,
+				TimeColumn: "_time",
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), nil},
+					{execute.Time(2), "a"},
+					{execute.Time(3), nil},
+					{execute.Time(4), nil},
+					{execute.Time(5), nil},
+					{execute.Time(6), "b"},
+					{execute.Time(7), nil},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), "a"},
+					{execute.Time(2), "a"},
+					{execute.Time(3), "a"},
+					{execute.Time(4), "a"},
+					{execute.Time(5), "b"},
+					{execute.Time(6), "b"},
+					{execute.Time(7), "b"},
+				},
+			}},
+		},
 	}
 	for _, tc := range testCases {
 		tc := tc
@@ -3,6 +3,7 @@ package universe
 import (
 	"context"
 	"fmt"
+	"regexp"
 
 	"github.com/apache/arrow/go/arrow/bitutil"
 	arrowmem "github.com/apache/arrow/go/arrow/memory"
@@ -41,6 +42,7 @@ func init() {
 	execute.RegisterTransformation(FilterKind, createFilterTransformation)
 	plan.RegisterPhysicalRules(
 		RemoveTrivialFilterRule{},
+		PushDownPredicatesRule{},
 	)
 }
 
@@ -140,6 +142,38 @@ func (s *FilterProcedureSpec) PlanDetails() string {
 	return "<non-Expression>"
 }
 
+// ReferencedColumns implements plan.ColumnReferencer.
+// The columns are known when the record is only used to access its members.
+func (s *FilterProcedureSpec) ReferencedColumns() ([]string, bool) {
+	if s.Fn.Fn == nil || len(s.Fn.Fn.Parameters.List) != 1 {
+		return nil, false
+	}
+	param := s.Fn.Fn.Parameters.List[0].Key.Name
+
+	var (
+		columns []string
+		members int
+		uses    int
+	)
+	semantic.Walk(semantic.CreateVisitor(func(node semantic.Node) {
+		switch n := node.(type) {
+		case *semantic.MemberExpression:
+			if id, ok := n.Object.(*semantic.IdentifierExpression); ok && id.Name == param {
+				columns = append(columns, n.Property)
+				members++
+			}
+		case *semantic.IdentifierExpression:
+			if n.Name == param {
+				uses++
+			}
+		}
+	}), s.Fn.Fn.Block)
+	if members != uses {
+		return nil, false
+	}
+	return columns, true
+}
+
 type filterTransformation struct {
 	execute.ExecutionNode
 	d               *execute.PassthroughDataset
@@ -348,6 +382,10 @@ func (t *filterTransformation) filter(fn *execute.RowPredicatePreparedFn, cr flu
 	bitset := arrowmem.NewResizableBuffer(mem)
 	bitset.Resize(l)
 	for i := 0; i < l; i++ {
+		if err := execute.CheckCancel(t.ctx, i); err != nil {
+			bitset.Release()
+			return nil, err
+		}
 		for _, j := range indices {
 			record.Set(cols[j].Label, execute.ValueForRow(cr, i, j))
 		}
@@ -478,3 +516,214 @@ func (MergeFiltersRule) Rewrite(ctx context.Context, filterNode plan.Node) (plan
 	anyNode := filterNode.Predecessors()[0]
 	return anyNode, true, nil
 }
+
+// PushDownPredicatesRule pushes the comparisons in a filter into a source
+// that implements plan.PredicatePushDownProcedureSpec.
+// The predicate is split on "and" and each comparison of a column
+// with a literal that the source supports is pushed down.
+// The filter is kept with the remaining comparisons, or removed
+// if every comparison was pushed.
+type PushDownPredicatesRule struct{}
+
+func (PushDownPredicatesRule) Name() string {
+	return "PushDownPredicatesRule"
+}
+
+func (PushDownPredicatesRule) Pattern() plan.Pattern {
+	return plan.Pat(FilterKind, plan.Any())
+}
+
+func (PushDownPredicatesRule) Rewrite(ctx context.Context, filterNode plan.Node) (plan.Node, bool, error) {
+	srcNode := filterNode.Predecessors()[0]
+	src, ok := srcNode.ProcedureSpec().(plan.PredicatePushDownProcedureSpec)
+	if !ok || len(srcNode.Successors()) != 1 {
+		return filterNode, false, nil
+	}
+
+	filterSpec := filterNode.ProcedureSpec().(*FilterProcedureSpec)
+	bodyExpr, ok := filterSpec.Fn.Fn.GetFunctionBodyExpression()
+	if !ok || len(filterSpec.Fn.Fn.Parameters.List) != 1 {
+		return filterNode, false, nil
+	}
+	param := filterSpec.Fn.Fn.Parameters.List[0].Key.Name
+
+	caps := src.PredicateCapabilities()
+	if filterSpec.KeepEmptyTables && !caps.KeepEmpty {
+		return filterNode, false, nil
+	}
+	var (
+		pushed   []plan.Predicate
+		residual []semantic.Expression
+	)
+	for _, expr := range splitConjunction(bodyExpr, nil) {
+		if p, ok := exprToPredicate(expr, param); ok && caps.Supports(p) {
+			pushed = append(pushed, p)
+			continue
+		}
+		residual = append(residual, expr)
+	}
+	if len(pushed) == 0 {
+		return filterNode, false, nil
+	}
+
+	if len(residual) == 0 {
+		newSpec := src.PushDownPredicates(pushed, filterSpec.KeepEmptyTables)
+		n, err := plan.MergeToPhysicalNode(filterNode, srcNode, newSpec)
+		if err != nil {
+			return nil, false, err
+		}
+		return n, true, nil
+	}
+
+	// A table left empty by the pushed predicates is also left empty
+	// by the residual filter, so the source keeps the same tables.
+	if err := srcNode.ReplaceSpec(src.PushDownPredicates(pushed, filterSpec.KeepEmptyTables)); err != nil {
+		return nil, false, err
+	}
+	newFilterSpec := filterSpec.Copy().(*FilterProcedureSpec)
+	ret := newFilterSpec.Fn.Fn.Block.Body[0].(*semantic.ReturnStatement)
+	ret.Argument = joinConjunction(residual)
+	if err := filterNode.ReplaceSpec(newFilterSpec); err != nil {
+		return nil, false, err
+	}
+	return filterNode, true, nil
+}
+
+// splitConjunction appends each operand of a chain of "and" expressions to exprs.
+func splitConjunction(expr semantic.Expression, exprs []semantic.Expression) []semantic.Expression {
+	if e, ok := expr.(*semantic.LogicalExpression); ok && e.Operator == ast.AndOperator {
+		exprs = splitConjunction(e.Left, exprs)
+		return splitConjunction(e.Right, exprs)
+	}
+	return append(exprs, expr)
+}
+
+// joinConjunction combines the expressions with "and".
+func joinConjunction(exprs []semantic.Expression) semantic.Expression {
+	expr := exprs[0]
+	for _, right := range exprs[1:] {
+		expr = &semantic.LogicalExpression{
+			Operator: ast.AndOperator,
+			Left:     expr,
+			Right:    right,
+		}
+	}
+	return expr
+}
+
+// exprToPredicate converts a comparison between a member of
+// the record parameter and a literal into a predicate.
+// A call to strings.hasPrefix with a literal prefix is converted
+// into a match of the regular expression ^prefix.
+func exprToPredicate(expr semantic.Expression, param string) (plan.Predicate, bool) {
+	if call, ok := expr.(*semantic.CallExpression); ok {
+		return hasPrefixToPredicate(call, param)
+	}
+	e, ok := expr.(*semantic.BinaryExpression)
+	if !ok {
+		return plan.Predicate{}, false
+	}
+	if column, ok := recordMember(e.Left, param); ok {
+		if v, ok := literalValue(e.Right); ok {
+			return plan.Predicate{Column: column, Operator: e.Operator, Value: v}, true
+		}
+		return plan.Predicate{}, false
+	}
+	if column, ok := recordMember(e.Right, param); ok {
+		op, ok := flipComparison(e.Operator)
+		if !ok {
+			return plan.Predicate{}, false
+		}
+		if v, ok := literalValue(e.Left); ok {
+			return plan.Predicate{Column: column, Operator: op, Value: v}, true
+		}
+	}
+	return plan.Predicate{}, false
+}
+
+func hasPrefixToPredicate(call *semantic.CallExpression, param string) (plan.Predicate, bool) {
+	callee, ok := call.Callee.(*semantic.MemberExpression)
+	if !ok || callee.Property != "hasPrefix" {
+		return plan.Predicate{}, false
+	}
+	if pkg, ok := callee.Object.(*semantic.IdentifierExpression); !ok || pkg.Name != "strings" {
+		return plan.Predicate{}, false
+	}
+	if call.Arguments == nil {
+		return plan.Predicate{}, false
+	}
+	var (
+		column, prefix  string
+		hasV, hasPrefix bool
+	)
+	for _, prop := range call.Arguments.Properties {
+		switch prop.Key.Key() {
+		case "v":
+			column, hasV = recordMember(prop.Value, param)
+		case "prefix":
+			if lit, ok := prop.Value.(*semantic.StringLiteral); ok {
+				prefix, hasPrefix = lit.Value, true
+			}
+		}
+	}
+	if !hasV || !hasPrefix {
+		return plan.Predicate{}, false
+	}
+	re := regexp.MustCompile("^" + regexp.QuoteMeta(prefix))
+	return plan.Predicate{
+		Column:   column,
+		Operator: ast.RegexpMatchOperator,
+		Value:    values.NewRegexp(re),
+	}, true
+}
+
+func recordMember(expr semantic.Expression, param string) (string, bool) {
+	m, ok := expr.(*semantic.MemberExpression)
+	if !ok {
+		return "", false
+	}
+	if id, ok := m.Object.(*semantic.IdentifierExpression); !ok || id.Name != param {
+		return "", false
+	}
+	return m.Property, true
+}
+
+func literalValue(expr semantic.Expression) (values.Value, bool) {
+	switch e := expr.(type) {
+	case *semantic.BooleanLiteral:
+		return values.NewBool(e.Value), true
+	case *semantic.IntegerLiteral:
+		return values.NewInt(e.Value), true
+	case *semantic.UnsignedIntegerLiteral:
+		return values.NewUInt(e.Value), true
+	case *semantic.FloatLiteral:
+		return values.NewFloat(e.Value), true
+	case *semantic.StringLiteral:
+		return values.NewString(e.Value), true
+	case *semantic.DateTimeLiteral:
+		return values.NewTime(values.ConvertTime(e.Value)), true
+	case *semantic.RegexpLiteral:
+		return values.NewRegexp(e.Value), true
+	default:
+		return nil, false
+	}
+}
+
+// flipComparison returns the operator that gives the same result
+// when the operands of a comparison are swapped.
+func flipComparison(op ast.OperatorKind) (ast.OperatorKind, bool) {
+	switch op {
+	case ast.EqualOperator, ast.NotEqualOperator:
+		return op, true
+	case ast.LessThanOperator:
+		return ast.GreaterThanOperator, true
+	case ast.LessThanEqualOperator:
+		return ast.GreaterThanEqualOperator, true
+	case ast.GreaterThanOperator:
+		return ast.LessThanOperator, true
+	case ast.GreaterThanEqualOperator:
+		return ast.LessThanEqualOperator, true
+	default:
+		return 0, false
+	}
+}
@@ -2,10 +2,12 @@ package universe_test
 
 import (
 	"context"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
 	"github.com/influxdata/flux/dependencies/dependenciestest"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
@@ -964,3 +966,203 @@ func benchmarkFilter(b *testing.B, n int, fn *semantic.FunctionExpression) {
 		},
 	)
 }
+
+// predicateSource is a source that supports equality on the host column
+// and prefix matches on the _measurement column.
+type predicateSource struct {
+	plan.DefaultCost
+	Predicates   []plan.Predicate
+	KeepEmpty    bool
+	CanKeepEmpty bool
+}
+
+func (s *predicateSource) Kind() plan.ProcedureKind {
+	return "predicateSource"
+}
+
+func (s *predicateSource) Copy() plan.ProcedureSpec {
+	ns := *s
+	ns.Predicates = append([]plan.Predicate(nil), s.Predicates...)
+	return &ns
+}
+
+func (s *predicateSource) PredicateCapabilities() plan.PredicateCapabilities {
+	return plan.PredicateCapabilities{
+		Columns: map[string][]ast.OperatorKind{
+			"host":         {ast.EqualOperator, ast.NotEqualOperator},
+			"_measurement": {ast.RegexpMatchOperator},
+		},
+		Accept: func(p plan.Predicate) bool {
+			if p.Operator != ast.RegexpMatchOperator {
+				return true
+			}
+			_, ok := plan.RegexpPrefix(p.Value.Regexp())
+			return ok
+		},
+		KeepEmpty: s.CanKeepEmpty,
+	}
+}
+
+func (s *predicateSource) PushDownPredicates(predicates []plan.Predicate, keepEmpty bool) plan.PhysicalProcedureSpec {
+	ns := s.Copy().(*predicateSource)
+	ns.Predicates = append(ns.Predicates, predicates...)
+	ns.KeepEmpty = keepEmpty
+	return ns
+}
+
+func TestFilter_PushDownPredicatesRule(t *testing.T) {
+	filter := func(fn string, keepEmpty bool) *universe.FilterProcedureSpec {
+		return &universe.FilterProcedureSpec{
+			KeepEmptyTables: keepEmpty,
+			Fn: interpreter.ResolvedFunction{
+				Fn: executetest.FunctionExpression(t, fn),
+			},
+		}
+	}
+	hostEq := plan.Predicate{Column: "host", Operator: ast.EqualOperator, Value: values.NewString("a")}
+	hostNeq := plan.Predicate{Column: "host", Operator: ast.NotEqualOperator, Value: values.NewString("b")}
+	measurementPrefix := plan.Predicate{
+		Column:   "_measurement",
+		Operator: ast.RegexpMatchOperator,
+		Value:    values.NewRegexp(regexp.MustCompile(`^cp\.u`)),
+	}
+
+	tests := []plantest.RuleTestCase{
+		{
+			Name:  "push all",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &predicateSource{}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.host == "a" and "b" != r.host`, false)),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("merged_source_filter", &predicateSource{
+						Predicates: []plan.Predicate{hostEq, hostNeq},
+					}),
+				},
+			},
+		},
+		{
+			Name:  "push all keep empty",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &predicateSource{CanKeepEmpty: true}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.host == "a"`, true)),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("merged_source_filter", &predicateSource{
+						Predicates:   []plan.Predicate{hostEq},
+						KeepEmpty:    true,
+						CanKeepEmpty: true,
+					}),
+				},
+			},
+		},
+		{
+			Name:  "keep empty unsupported",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &predicateSource{}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.host == "a"`, true)),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "push prefix",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &predicateSource{}),
+					plan.CreatePhysicalNode("filter", filter(`import "strings"
+(r) => strings.hasPrefix(v: r._measurement, prefix: "cp.u") and r._measurement =~ /c/`, false)),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &predicateSource{
+						Predicates: []plan.Predicate{measurementPrefix},
+					}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r._measurement =~ /c/`, false)),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name:  "push some",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &predicateSource{}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r._value > 5.0 and r.host == "a" and r.host =~ /c/`, false)),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &predicateSource{
+						Predicates: []plan.Predicate{hostEq},
+					}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r._value > 5.0 and r.host =~ /c/`, false)),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name:  "unsupported predicate",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &predicateSource{}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.host == "a" or r.host == "b"`, false)),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "source without pushdown",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", &influxdb.FromProcedureSpec{}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.host == "a"`, false)),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "source with multiple successors",
+			Rules: []plan.Rule{universe.PushDownPredicatesRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &predicateSource{}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.host == "a"`, false)),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{}),
+				},
+				Edges: [][2]int{{0, 1}, {0, 2}},
+			},
+			NoChange: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
@@ -0,0 +1,324 @@
+package universe
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/ast"
+	"github.com/influxdata/flux/ast/astutil"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/planpb"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"google.golang.org/protobuf/proto"
+)
+
+// The codecs of the procedure specs that can be sent
+// to remote workers in a plan fragment.
+func init() {
+	plan.RegisterFragmentSpec(YieldKind, plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.YieldSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			return &planpb.YieldSpec{Name: spec.(*YieldProcedureSpec).Name}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			return &YieldProcedureSpec{Name: m.(*planpb.YieldSpec).Name}, nil
+		},
+	})
+	plan.RegisterFragmentSpec(RangeKind, plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.RangeSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			s := spec.(*RangeProcedureSpec)
+			return &planpb.RangeSpec{
+				Bounds:      marshalBounds(s.Bounds),
+				TimeColumn:  s.TimeColumn,
+				StartColumn: s.StartColumn,
+				StopColumn:  s.StopColumn,
+			}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			pb := m.(*planpb.RangeSpec)
+			return &RangeProcedureSpec{
+				Bounds:      unmarshalBounds(pb.Bounds),
+				TimeColumn:  pb.TimeColumn,
+				StartColumn: pb.StartColumn,
+				StopColumn:  pb.StopColumn,
+			}, nil
+		},
+	})
+	plan.RegisterFragmentSpec(LimitKind, plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.LimitSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			s := spec.(*LimitProcedureSpec)
+			return &planpb.LimitSpec{N: s.N, Offset: s.Offset}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			pb := m.(*planpb.LimitSpec)
+			return &LimitProcedureSpec{N: pb.N, Offset: pb.Offset}, nil
+		},
+	})
+	plan.RegisterFragmentSpec(SortKind, plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.SortSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			s := spec.(*SortProcedureSpec)
+			return &planpb.SortSpec{Columns: s.Columns, Desc: s.Desc}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			pb := m.(*planpb.SortSpec)
+			return &SortProcedureSpec{Columns: pb.Columns, Desc: pb.Desc}, nil
+		},
+	})
+	plan.RegisterFragmentSpec(FilterKind, plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.FilterSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			s := spec.(*FilterProcedureSpec)
+			fn, err := marshalFunction(s.Fn)
+			if err != nil {
+				return nil, err
+			}
+			return &planpb.FilterSpec{Fn: fn, KeepEmptyTables: s.KeepEmptyTables}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			pb := m.(*planpb.FilterSpec)
+			fn, err := unmarshalFunction(ctx, pb.Fn)
+			if err != nil {
+				return nil, err
+			}
+			return &FilterProcedureSpec{Fn: fn, KeepEmptyTables: pb.KeepEmptyTables}, nil
+		},
+	})
+	plan.RegisterFragmentSpec(MapKind, plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.MapSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			s := spec.(*MapProcedureSpec)
+			fn, err := marshalFunction(s.Fn)
+			if err != nil {
+				return nil, err
+			}
+			return &planpb.MapSpec{Fn: fn, MergeKey: s.MergeKey}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			pb := m.(*planpb.MapSpec)
+			fn, err := unmarshalFunction(ctx, pb.Fn)
+			if err != nil {
+				return nil, err
+			}
+			return &MapProcedureSpec{Fn: fn, MergeKey: pb.MergeKey}, nil
+		},
+	})
+	plan.RegisterFragmentSpec(GroupKind, plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.GroupSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			s := spec.(*GroupProcedureSpec)
+			return &planpb.GroupSpec{Mode: int32(s.GroupMode), Columns: s.GroupKeys}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			pb := m.(*planpb.GroupSpec)
+			return &GroupProcedureSpec{GroupMode: flux.GroupMode(pb.Mode), GroupKeys: pb.Columns}, nil
+		},
+	})
+
+	plan.RegisterFragmentSpec(CountKind, aggregateCodec(
+		func(spec plan.PhysicalProcedureSpec) execute.SimpleAggregateConfig {
+			return spec.(*CountProcedureSpec).SimpleAggregateConfig
+		},
+		func(config execute.SimpleAggregateConfig) plan.PhysicalProcedureSpec {
+			return &CountProcedureSpec{SimpleAggregateConfig: config}
+		},
+	))
+	plan.RegisterFragmentSpec(SumKind, aggregateCodec(
+		func(spec plan.PhysicalProcedureSpec) execute.SimpleAggregateConfig {
+			return spec.(*SumProcedureSpec).SimpleAggregateConfig
+		},
+		func(config execute.SimpleAggregateConfig) plan.PhysicalProcedureSpec {
+			return &SumProcedureSpec{SimpleAggregateConfig: config}
+		},
+	))
+	plan.RegisterFragmentSpec(MeanKind, aggregateCodec(
+		func(spec plan.PhysicalProcedureSpec) execute.SimpleAggregateConfig {
+			return spec.(*MeanProcedureSpec).SimpleAggregateConfig
+		},
+		func(config execute.SimpleAggregateConfig) plan.PhysicalProcedureSpec {
+			return &MeanProcedureSpec{SimpleAggregateConfig: config}
+		},
+	))
+
+	plan.RegisterFragmentSpec(FirstKind, selectorCodec(
+		func(spec plan.PhysicalProcedureSpec) execute.SelectorConfig {
+			return spec.(*FirstProcedureSpec).SelectorConfig
+		},
+		func(config execute.SelectorConfig) plan.PhysicalProcedureSpec {
+			return &FirstProcedureSpec{SelectorConfig: config}
+		},
+	))
+	plan.RegisterFragmentSpec(LastKind, selectorCodec(
+		func(spec plan.PhysicalProcedureSpec) execute.SelectorConfig {
+			return spec.(*LastProcedureSpec).SelectorConfig
+		},
+		func(config execute.SelectorConfig) plan.PhysicalProcedureSpec {
+			return &LastProcedureSpec{SelectorConfig: config}
+		},
+	))
+	plan.RegisterFragmentSpec(MinKind, selectorCodec(
+		func(spec plan.PhysicalProcedureSpec) execute.SelectorConfig {
+			return spec.(*MinProcedureSpec).SelectorConfig
+		},
+		func(config execute.SelectorConfig) plan.PhysicalProcedureSpec {
+			return &MinProcedureSpec{SelectorConfig: config}
+		},
+	))
+	plan.RegisterFragmentSpec(MaxKind, selectorCodec(
+		func(spec plan.PhysicalProcedureSpec) execute.SelectorConfig {
+			return spec.(*MaxProcedureSpec).SelectorConfig
+		},
+		func(config execute.SelectorConfig) plan.PhysicalProcedureSpec {
+			return &MaxProcedureSpec{SelectorConfig: config}
+		},
+	))
+}
+
+// aggregateCodec returns the codec of an aggregate that
+// is configured by an execute.SimpleAggregateConfig.
+func aggregateCodec(
+	config func(spec plan.PhysicalProcedureSpec) execute.SimpleAggregateConfig,
+	create func(config execute.SimpleAggregateConfig) plan.PhysicalProcedureSpec,
+) plan.FragmentSpecCodec {
+	return plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.AggregateSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			return &planpb.AggregateSpec{Columns: config(spec).Columns}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			return create(execute.SimpleAggregateConfig{
+				Columns: m.(*planpb.AggregateSpec).Columns,
+			}), nil
+		},
+	}
+}
+
+// selectorCodec returns the codec of a selector that
+// is configured by an execute.SelectorConfig.
+func selectorCodec(
+	config func(spec plan.PhysicalProcedureSpec) execute.SelectorConfig,
+	create func(config execute.SelectorConfig) plan.PhysicalProcedureSpec,
+) plan.FragmentSpecCodec {
+	return plan.FragmentSpecCodec{
+		NewMessage: func() proto.Message { return new(planpb.SelectorSpec) },
+		ToMessage: func(spec plan.PhysicalProcedureSpec) (proto.Message, error) {
+			return &planpb.SelectorSpec{Column: config(spec).Column}, nil
+		},
+		FromMessage: func(ctx context.Context, m proto.Message) (plan.PhysicalProcedureSpec, error) {
+			return create(execute.SelectorConfig{
+				Column: m.(*planpb.SelectorSpec).Column,
+			}), nil
+		},
+	}
+}
+
+func marshalTime(t flux.Time) *planpb.Time {
+	pb := &planpb.Time{
+		IsRelative: t.IsRelative,
+		Relative:   int64(t.Relative),
+	}
+	if !t.Absolute.IsZero() {
+		pb.Absolute = t.Absolute.UnixNano()
+	}
+	return pb
+}
+
+func unmarshalTime(pb *planpb.Time) flux.Time {
+	t := flux.Time{
+		IsRelative: pb.GetIsRelative(),
+		Relative:   time.Duration(pb.GetRelative()),
+	}
+	if pb.GetAbsolute() != 0 {
+		t.Absolute = time.Unix(0, pb.GetAbsolute()).UTC()
+	}
+	return t
+}
+
+func marshalBounds(b flux.Bounds) *planpb.Bounds {
+	pb := &planpb.Bounds{
+		Start: marshalTime(b.Start),
+		Stop:  marshalTime(b.Stop),
+	}
+	if !b.Now.IsZero() {
+		pb.Now = b.Now.UnixNano()
+	}
+	return pb
+}
+
+func unmarshalBounds(pb *planpb.Bounds) flux.Bounds {
+	b := flux.Bounds{
+		Start: unmarshalTime(pb.GetStart()),
+		Stop:  unmarshalTime(pb.GetStop()),
+	}
+	if pb.GetNow() != 0 {
+		b.Now = time.Unix(0, pb.GetNow()).UTC()
+	}
+	return b
+}
+
+// marshalFunction converts a resolved function back to Flux source.
+// The packages that the function references are imported
+// with the names it uses for them.
+func marshalFunction(fn interpreter.ResolvedFunction) (*planpb.Function, error) {
+	if fn.Fn == nil {
+		return nil, errors.New(codes.Internal, "missing function")
+	}
+	file := &ast.File{
+		Package: &ast.PackageClause{
+			Name: &ast.Identifier{Name: "main"},
+		},
+		Body: []ast.Statement{
+			&ast.ExpressionStatement{Expression: semantic.ToAST(fn.Fn).(ast.Expression)},
+		},
+	}
+	if fn.Scope != nil {
+		fn.Scope.Range(func(k string, v values.Value) {
+			pkg, ok := v.(values.Package)
+			if !ok || pkg.Path() == "" {
+				return
+			}
+			file.Imports = append(file.Imports, &ast.ImportDeclaration{
+				As:   &ast.Identifier{Name: k},
+				Path: &ast.StringLiteral{Value: pkg.Path()},
+			})
+		})
+	}
+	sort.Slice(file.Imports, func(i, j int) bool {
+		return file.Imports[i].As.Name < file.Imports[j].As.Name
+	})
+	src, err := astutil.Format(file)
+	if err != nil {
+		return nil, err
+	}
+	return &planpb.Function{Source: src}, nil
+}
+
+// unmarshalFunction evaluates the source written by
+// marshalFunction and resolves the function again.
+func unmarshalFunction(ctx context.Context, pb *planpb.Function) (interpreter.ResolvedFunction, error) {
+	if pb == nil {
+		return interpreter.ResolvedFunction{}, errors.New(codes.Invalid, "missing function")
+	}
+	sideEffects, _, err := runtime.Eval(ctx, pb.Source)
+	if err != nil {
+		return interpreter.ResolvedFunction{}, err
+	}
+	if len(sideEffects) == 0 {
+		return interpreter.ResolvedFunction{}, errors.New(codes.Invalid, "function source does not contain a function")
+	}
+	f, ok := sideEffects[len(sideEffects)-1].Value.(values.Function)
+	if !ok {
+		return interpreter.ResolvedFunction{}, errors.New(codes.Invalid, "function source does not contain a function")
+	}
+	return interpreter.ResolveFunction(f)
+}
@@ -0,0 +1,151 @@
+package universe_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/stdlib/universe"
+	"github.com/influxdata/flux/values"
+)
+
+// resolveFunction evaluates a Flux program whose last
+// expression is a function and resolves the function.
+func resolveFunction(t *testing.T, source string) interpreter.ResolvedFunction {
+	t.Helper()
+	se, _, err := runtime.Eval(context.Background(), source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn, err := interpreter.ResolveFunction(se[len(se)-1].Value.(values.Function))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fn
+}
+
+func TestFragment_RoundTrip(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		spec plan.PhysicalProcedureSpec
+		// hasFn is set if the spec has a function that is
+		// compared by encoding it again.
+		hasFn bool
+	}{
+		{
+			name: "range",
+			spec: &universe.RangeProcedureSpec{
+				Bounds: flux.Bounds{
+					Start: flux.Time{IsRelative: true, Relative: -5 * time.Minute},
+					Stop:  flux.Time{Absolute: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+				},
+				TimeColumn:  "_time",
+				StartColumn: "_start",
+				StopColumn:  "_stop",
+			},
+		},
+		{
+			name: "limit",
+			spec: &universe.LimitProcedureSpec{N: 10, Offset: 2},
+		},
+		{
+			name: "sort",
+			spec: &universe.SortProcedureSpec{Columns: []string{"_value"}, Desc: true},
+		},
+		{
+			name: "group",
+			spec: &universe.GroupProcedureSpec{GroupMode: flux.GroupModeExcept, GroupKeys: []string{"_time", "_value"}},
+		},
+		{
+			name: "count",
+			spec: &universe.CountProcedureSpec{
+				SimpleAggregateConfig: execute.SimpleAggregateConfig{Columns: []string{"_value"}},
+			},
+		},
+		{
+			name: "mean",
+			spec: &universe.MeanProcedureSpec{
+				SimpleAggregateConfig: execute.SimpleAggregateConfig{Columns: []string{"a", "b"}},
+			},
+		},
+		{
+			name: "last",
+			spec: &universe.LastProcedureSpec{
+				SelectorConfig: execute.SelectorConfig{Column: "_value"},
+			},
+		},
+		{
+			name: "max",
+			spec: &universe.MaxProcedureSpec{
+				SelectorConfig: execute.SelectorConfig{Column: "_value"},
+			},
+		},
+		{
+			name: "yield",
+			spec: &universe.YieldProcedureSpec{Name: "mean"},
+		},
+		{
+			name: "filter",
+			spec: &universe.FilterProcedureSpec{
+				Fn:              resolveFunction(t, `(r) => r._value > 1.5 and r.host == "a"`),
+				KeepEmptyTables: true,
+			},
+			hasFn: true,
+		},
+		{
+			name: "filter with import",
+			spec: &universe.FilterProcedureSpec{
+				Fn: resolveFunction(t, `import "strings"
+(r) => strings.hasPrefix(v: r._measurement, prefix: "cpu")`),
+			},
+			hasFn: true,
+		},
+		{
+			name: "map",
+			spec: &universe.MapProcedureSpec{
+				Fn: resolveFunction(t, `(r) => ({r with _value: r._value * 2.0, unit: "${r.host}/s"})`),
+			},
+			hasFn: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			p := plan.NewPlanSpec()
+			p.Roots[plan.CreatePhysicalNode("node", tt.spec)] = struct{}{}
+			data, err := plan.MarshalFragment(p, plan.LocalCapabilities())
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := plan.UnmarshalFragment(context.Background(), data)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.hasFn {
+				var spec plan.ProcedureSpec
+				for root := range got.Roots {
+					spec = root.ProcedureSpec()
+				}
+				if !cmp.Equal(tt.spec, spec) {
+					t.Errorf("unexpected spec -want/+got:\n%s", cmp.Diff(tt.spec, spec))
+				}
+				return
+			}
+
+			// A function decodes to the same source it was encoded from.
+			again, err := plan.MarshalFragment(got, plan.LocalCapabilities())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, again) {
+				t.Error("function changed when it was decoded")
+			}
+		})
+	}
+}
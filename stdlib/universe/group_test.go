@@ -3,10 +3,13 @@ package universe_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/execute"
 	"github.com/influxdata/flux/execute/executetest"
 	"github.com/influxdata/flux/internal/gen"
@@ -782,6 +785,99 @@ func TestGroup_Process(t *testing.T) {
 	}
 }
 
+func TestGroup_StringMemoryLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "within limit", limit: 1 << 20},
+		{name: "exceeds limit", limit: 16 << 10, wantErr: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			alloc := &memory.Allocator{Limit: &tc.limit}
+			spec := &universe.GroupProcedureSpec{
+				GroupMode: flux.GroupModeBy,
+				GroupKeys: []string{"_field"},
+			}
+			tx, d, err := universe.NewGroupTransformation(context.Background(), spec, executetest.RandomDatasetID(), alloc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.SetTriggerSpec(plan.DefaultTriggerSpec)
+			d.AddTransformation(executetest.NewDataStore())
+
+			err = processWithMemoryLimit(tx, wideStringTables(64, 1024))
+			checkMemoryLimitErr(t, err, tc.wantErr)
+			if got := alloc.MaxAllocated(); got > tc.limit {
+				t.Errorf("allocated memory exceeded the limit: %d > %d", got, tc.limit)
+			}
+		})
+	}
+}
+
+// wideStringTables returns a table with n rows where each
+// value is a string of the given width. The rows are spread
+// across four fields.
+func wideStringTables(n, width int) []flux.Table {
+	tbl := &executetest.Table{
+		KeyCols: []string{"_measurement"},
+		ColMeta: []flux.ColMeta{
+			{Label: "_time", Type: flux.TTime},
+			{Label: "_measurement", Type: flux.TString},
+			{Label: "_field", Type: flux.TString},
+			{Label: "_value", Type: flux.TString},
+		},
+	}
+	value := strings.Repeat("v", width)
+	for i := 0; i < n; i++ {
+		tbl.Data = append(tbl.Data, []interface{}{
+			execute.Time(i / 4), "m0", fmt.Sprintf("f%d", i%4), value,
+		})
+	}
+	return []flux.Table{tbl}
+}
+
+// processWithMemoryLimit passes the tables through the transformation
+// and returns the error from the allocator if the memory limit is exceeded.
+func processWithMemoryLimit(tx execute.Transformation, data []flux.Table) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(error)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+	}()
+
+	parentID := executetest.RandomDatasetID()
+	for _, tbl := range data {
+		if err := tx.Process(parentID, tbl); err != nil {
+			return err
+		}
+	}
+	tx.Finish(parentID, nil)
+	return nil
+}
+
+func checkMemoryLimitErr(t *testing.T, err error, wantErr bool) {
+	t.Helper()
+	if !wantErr {
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		return
+	}
+	if err == nil {
+		t.Fatal("expected memory limit error")
+	}
+	if got, want := flux.ErrorCode(err), codes.ResourceExhausted; got != want {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+}
+
 func TestMergeGroupRule(t *testing.T) {
 	var (
 		from      = &influxdb.FromProcedureSpec{}
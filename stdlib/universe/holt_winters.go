@@ -3,6 +3,8 @@ package universe
 import (
 	"fmt"
 
+	"strconv"
+
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/array"
 	fluxarrow "github.com/influxdata/flux/arrow"
@@ -12,19 +14,39 @@ import (
 	fluxmemory "github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/plan"
 	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
 	"github.com/influxdata/flux/stdlib/universe/holt_winters"
 	"github.com/influxdata/flux/values"
 )
 
-const HoltWintersKind = "holtWinters"
+const (
+	HoltWintersKind           = "holtWinters"
+	HoltWintersParametersKind = "holtWintersParameters"
+
+	// The labels of the prediction interval columns.
+	holtWintersLowerColLabel = "lower"
+	holtWintersUpperColLabel = "upper"
+)
 
 type HoltWintersOpSpec struct {
-	WithFit    bool          `json:"with_fit"`
-	Column     string        `json:"column"`
-	TimeColumn string        `json:"time_column"`
-	N          int64         `json:"n"`
-	S          int64         `json:"s"`
-	Interval   flux.Duration `json:"interval"`
+	WithFit       bool          `json:"with_fit"`
+	Column        string        `json:"column"`
+	TimeColumn    string        `json:"time_column"`
+	N             int64         `json:"n"`
+	S             int64         `json:"s"`
+	Seasonalities []int64       `json:"seasonalities,omitempty"`
+	Confidence    float64       `json:"confidence,omitempty"`
+	Interval      flux.Duration `json:"interval"`
+}
+
+// HoltWintersParametersOpSpec fits the Holt-Winters model without forecasting,
+// and returns the parameters of the fitted model.
+type HoltWintersParametersOpSpec struct {
+	Column        string        `json:"column"`
+	TimeColumn    string        `json:"time_column"`
+	S             int64         `json:"s"`
+	Seasonalities []int64       `json:"seasonalities,omitempty"`
+	Interval      flux.Duration `json:"interval"`
 }
 
 func init() {
@@ -33,6 +55,12 @@ func init() {
 	flux.RegisterOpSpec(HoltWintersKind, newHoltWintersOp)
 	plan.RegisterProcedureSpec(HoltWintersKind, newHoltWintersProcedure, HoltWintersKind)
 	execute.RegisterTransformation(HoltWintersKind, createHoltWintersTransformation)
+
+	hwParametersSignature := runtime.MustLookupBuiltinType("universe", "holtWintersParameters")
+	runtime.RegisterPackageValue("universe", HoltWintersParametersKind, flux.MustValue(flux.FunctionValue(HoltWintersParametersKind, createHoltWintersParametersOpSpec, hwParametersSignature)))
+	flux.RegisterOpSpec(HoltWintersParametersKind, newHoltWintersParametersOp)
+	plan.RegisterProcedureSpec(HoltWintersParametersKind, newHoltWintersParametersProcedure, HoltWintersParametersKind)
+	execute.RegisterTransformation(HoltWintersParametersKind, createHoltWintersParametersTransformation)
 }
 
 func createHoltWintersOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
@@ -76,9 +104,50 @@ func createHoltWintersOpSpec(args flux.Arguments, a *flux.Administration) (flux.
 	} else {
 		spec.S = 0
 	}
+	if seasonalities, err := getHoltWintersSeasonalities(args, spec.S); err != nil {
+		return nil, err
+	} else {
+		spec.Seasonalities = seasonalities
+	}
+	if c, ok, err := args.GetFloat("confidence"); err != nil {
+		return nil, err
+	} else if ok {
+		if c <= 0 || c >= 1 {
+			return nil, errors.Newf(codes.Invalid, "confidence must be between 0 and 1, got %v", c)
+		}
+		spec.Confidence = c
+	}
 	return spec, nil
 }
 
+// getHoltWintersSeasonalities returns the seasonal periods given with the seasonalities argument.
+// The seasonality and seasonalities arguments cannot be used together.
+func getHoltWintersSeasonalities(args flux.Arguments, s int64) ([]int64, error) {
+	arr, ok, err := args.GetArray("seasonalities", semantic.Int)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if s != 0 {
+		return nil, errors.New(codes.Invalid, "cannot specify both seasonality and seasonalities")
+	}
+	seasonalities := make([]int64, 0, arr.Len())
+	var sErr error
+	arr.Range(func(i int, v values.Value) {
+		if sErr != nil {
+			return
+		}
+		if v.Int() < 0 {
+			sErr = errors.Newf(codes.Invalid, "seasonalities cannot be negative, got %d", v.Int())
+			return
+		}
+		seasonalities = append(seasonalities, v.Int())
+	})
+	if sErr != nil {
+		return nil, sErr
+	}
+	return seasonalities, nil
+}
+
 func newHoltWintersOp() flux.OperationSpec {
 	return new(HoltWintersOpSpec)
 }
@@ -89,12 +158,14 @@ func (s *HoltWintersOpSpec) Kind() flux.OperationKind {
 
 type HoltWintersProcedureSpec struct {
 	plan.DefaultCost
-	WithFit    bool
-	Column     string
-	TimeColumn string
-	N          int64
-	S          int64
-	Interval   flux.Duration
+	WithFit       bool
+	Column        string
+	TimeColumn    string
+	N             int64
+	S             int64
+	Seasonalities []int64
+	Confidence    float64
+	Interval      flux.Duration
 }
 
 func newHoltWintersProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
@@ -103,12 +174,14 @@ func newHoltWintersProcedure(qs flux.OperationSpec, pa plan.Administration) (pla
 		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
 	}
 	return &HoltWintersProcedureSpec{
-		WithFit:    spec.WithFit,
-		Column:     spec.Column,
-		TimeColumn: spec.TimeColumn,
-		N:          spec.N,
-		S:          spec.S,
-		Interval:   spec.Interval,
+		WithFit:       spec.WithFit,
+		Column:        spec.Column,
+		TimeColumn:    spec.TimeColumn,
+		N:             spec.N,
+		S:             spec.S,
+		Seasonalities: spec.Seasonalities,
+		Confidence:    spec.Confidence,
+		Interval:      spec.Interval,
 	}, nil
 }
 
@@ -118,6 +191,10 @@ func (s *HoltWintersProcedureSpec) Kind() plan.ProcedureKind {
 func (s *HoltWintersProcedureSpec) Copy() plan.ProcedureSpec {
 	ns := new(HoltWintersProcedureSpec)
 	*ns = *s
+	if s.Seasonalities != nil {
+		ns.Seasonalities = make([]int64, len(s.Seasonalities))
+		copy(ns.Seasonalities, s.Seasonalities)
+	}
 	return ns
 }
 
@@ -137,6 +214,103 @@ func createHoltWintersTransformation(id execute.DatasetID, mode execute.Accumula
 	return t, d, nil
 }
 
+func createHoltWintersParametersOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+	spec := new(HoltWintersParametersOpSpec)
+	if i, err := args.GetRequiredDuration("interval"); err != nil {
+		return nil, err
+	} else {
+		spec.Interval = i
+	}
+	if col, ok, err := args.GetString("column"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Column = col
+	} else {
+		spec.Column = execute.DefaultValueColLabel
+	}
+	if col, ok, err := args.GetString("timeColumn"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.TimeColumn = col
+	} else {
+		spec.TimeColumn = execute.DefaultTimeColLabel
+	}
+	if s, ok, err := args.GetInt("seasonality"); err != nil {
+		return nil, err
+	} else if ok {
+		spec.S = s
+	}
+	if seasonalities, err := getHoltWintersSeasonalities(args, spec.S); err != nil {
+		return nil, err
+	} else {
+		spec.Seasonalities = seasonalities
+	}
+	return spec, nil
+}
+
+func newHoltWintersParametersOp() flux.OperationSpec {
+	return new(HoltWintersParametersOpSpec)
+}
+
+func (s *HoltWintersParametersOpSpec) Kind() flux.OperationKind {
+	return HoltWintersParametersKind
+}
+
+type HoltWintersParametersProcedureSpec struct {
+	plan.DefaultCost
+	Column        string
+	TimeColumn    string
+	S             int64
+	Seasonalities []int64
+	Interval      flux.Duration
+}
+
+func newHoltWintersParametersProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*HoltWintersParametersOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &HoltWintersParametersProcedureSpec{
+		Column:        spec.Column,
+		TimeColumn:    spec.TimeColumn,
+		S:             spec.S,
+		Seasonalities: spec.Seasonalities,
+		Interval:      spec.Interval,
+	}, nil
+}
+
+func (s *HoltWintersParametersProcedureSpec) Kind() plan.ProcedureKind {
+	return HoltWintersParametersKind
+}
+func (s *HoltWintersParametersProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := new(HoltWintersParametersProcedureSpec)
+	*ns = *s
+	if s.Seasonalities != nil {
+		ns.Seasonalities = make([]int64, len(s.Seasonalities))
+		copy(ns.Seasonalities, s.Seasonalities)
+	}
+	return ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *HoltWintersParametersProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createHoltWintersParametersTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*HoltWintersParametersProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewHoltWintersParametersTransformation(d, cache, a.Allocator(), s)
+	return t, d, nil
+}
+
 type holtWintersTransformation struct {
 	execute.ExecutionNode
 	d     execute.Dataset
@@ -147,8 +321,12 @@ type holtWintersTransformation struct {
 	column     string
 	timeColumn string
 	n          int64
-	s          int64
+	seasons    []int
+	confidence float64
 	interval   values.Duration
+	// parameters is set when the transformation returns
+	// the parameters of the model instead of a forecast.
+	parameters bool
 }
 
 func NewHoltWintersTransformation(d execute.Dataset, cache execute.TableBuilderCache, alloc *fluxmemory.Allocator, spec *HoltWintersProcedureSpec) *holtWintersTransformation {
@@ -160,9 +338,36 @@ func NewHoltWintersTransformation(d execute.Dataset, cache execute.TableBuilderC
 		column:     spec.Column,
 		timeColumn: spec.TimeColumn,
 		n:          spec.N,
-		s:          spec.S,
+		seasons:    holtWintersSeasons(spec.S, spec.Seasonalities),
+		confidence: spec.Confidence,
+		interval:   values.Duration(spec.Interval),
+	}
+}
+
+func NewHoltWintersParametersTransformation(d execute.Dataset, cache execute.TableBuilderCache, alloc *fluxmemory.Allocator, spec *HoltWintersParametersProcedureSpec) *holtWintersTransformation {
+	return &holtWintersTransformation{
+		d:          d,
+		cache:      cache,
+		alloc:      alloc,
+		column:     spec.Column,
+		timeColumn: spec.TimeColumn,
+		seasons:    holtWintersSeasons(spec.S, spec.Seasonalities),
 		interval:   values.Duration(spec.Interval),
+		parameters: true,
+	}
+}
+
+// holtWintersSeasons returns the seasonal periods of the model,
+// the seasonalities replace the seasonality when they are given.
+func holtWintersSeasons(s int64, seasonalities []int64) []int {
+	if len(seasonalities) == 0 {
+		return []int{int(s)}
+	}
+	seasons := make([]int, len(seasonalities))
+	for i, s := range seasonalities {
+		seasons[i] = int(s)
 	}
+	return seasons
 }
 
 func (hwt *holtWintersTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
@@ -191,6 +396,9 @@ func (hwt *holtWintersTransformation) Process(id execute.DatasetID, tbl flux.Tab
 	if err := execute.AddTableKeyCols(tbl.Key(), builder); err != nil {
 		return err
 	}
+	if hwt.parameters {
+		return hwt.processParameters(tbl, builder, colIdx, timeIdx)
+	}
 	newTimeIdx, err := builder.AddCol(flux.ColMeta{
 		Label: execute.DefaultTimeColLabel,
 		Type:  flux.TTime,
@@ -205,6 +413,21 @@ func (hwt *holtWintersTransformation) Process(id execute.DatasetID, tbl flux.Tab
 	if err != nil {
 		return err
 	}
+	lowerIdx, upperIdx := -1, -1
+	if hwt.confidence > 0 {
+		if lowerIdx, err = builder.AddCol(flux.ColMeta{
+			Label: holtWintersLowerColLabel,
+			Type:  flux.TFloat,
+		}); err != nil {
+			return err
+		}
+		if upperIdx, err = builder.AddCol(flux.ColMeta{
+			Label: holtWintersUpperColLabel,
+			Type:  flux.TFloat,
+		}); err != nil {
+			return err
+		}
+	}
 
 	// Cleaning data for HoltWinters input.
 	vs, start, stop, err := hwt.getCleanData(tbl, colIdx, timeIdx)
@@ -213,7 +436,7 @@ func (hwt *holtWintersTransformation) Process(id execute.DatasetID, tbl flux.Tab
 	}
 
 	// Holt Winters.
-	hw := holt_winters.New(int(hwt.n), int(hwt.s), hwt.withFit, fluxarrow.NewAllocator(hwt.alloc))
+	hw := holt_winters.NewMultiSeasonal(int(hwt.n), hwt.seasons, hwt.withFit, fluxarrow.NewAllocator(hwt.alloc))
 	newVs := hw.Do(vs)
 	// don't need vs anymore
 	vs.Release()
@@ -245,9 +468,73 @@ func (hwt *holtWintersTransformation) Process(id execute.DatasetID, tbl flux.Tab
 	if err := execute.AppendKeyValuesN(tbl.Key(), builder, newVs.Len()); err != nil {
 		return err
 	}
+	if hwt.confidence > 0 {
+		lower, upper := hw.Intervals(newVs, hwt.confidence)
+		defer func() {
+			lower.Release()
+			upper.Release()
+		}()
+		if err := builder.AppendFloats(lowerIdx, lower); err != nil {
+			return err
+		}
+		if err := builder.AppendFloats(upperIdx, upper); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// processParameters fits the model to the table and appends a row with its parameters.
+// There is one gamma column per seasonal period, named gamma_<period> when there are several periods.
+// No row is appended when the table has too few values to fit the model.
+func (hwt *holtWintersTransformation) processParameters(tbl flux.Table, builder execute.TableBuilder, colIdx, timeIdx int) error {
+	var periods []int
+	for _, s := range hwt.seasons {
+		if s >= 2 {
+			periods = append(periods, s)
+		}
+	}
+	labels := []string{"alpha", "beta", "phi"}
+	for _, s := range periods {
+		if len(periods) == 1 {
+			labels = append(labels, "gamma")
+		} else {
+			labels = append(labels, "gamma_"+strconv.Itoa(s))
+		}
+	}
+	labels = append(labels, "sse", "stderr")
+	idxs := make([]int, len(labels))
+	for i, label := range labels {
+		idx, err := builder.AddCol(flux.ColMeta{
+			Label: label,
+			Type:  flux.TFloat,
+		})
+		if err != nil {
+			return err
+		}
+		idxs[i] = idx
+	}
+
+	vs, _, _, err := hwt.getCleanData(tbl, colIdx, timeIdx)
+	if err != nil {
+		return err
+	}
+	defer vs.Release()
+	hw := holt_winters.NewMultiSeasonal(0, hwt.seasons, false, fluxarrow.NewAllocator(hwt.alloc))
+	if !hw.Fit(vs) {
+		return nil
+	}
+	model := hw.Model()
+	params := append([]float64{model.Alpha, model.Beta, model.Phi}, model.Gammas...)
+	params = append(params, model.SSE, model.StdErr)
+	for i, v := range params {
+		if err := builder.AppendFloat(idxs[i], v); err != nil {
+			return err
+		}
+	}
+	return execute.AppendKeyValues(tbl.Key(), builder)
+}
+
 // getCleanData returns cleaned data (using the value and time column), and the first and last valid timestamps.
 // Below are the cleaning criteria.
 // Rows that have a null timestamp get discarded.
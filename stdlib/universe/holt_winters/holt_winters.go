@@ -7,15 +7,20 @@ import (
 	"github.com/influxdata/flux/array"
 	"github.com/influxdata/flux/arrow"
 	"github.com/influxdata/flux/internal/mutable"
+	"gonum.org/v1/gonum/stat/distuv"
 )
 
 // HoltWinters forecasts a series into the future.
 // This is done using the Holt-Winters damped method.
 //    1. The initial values are calculated using a SSE.
 //    2. The series is forecast into the future using the iterative relations.
+// When there are several seasonal periods, for example a daily and a weekly one,
+// the multiplicative multi-seasonal variant of the method is used.
 type HoltWinters struct {
-	n              int
-	s              int
+	n int
+	s int
+	// seasons are the seasonal periods when there are more than one.
+	seasons        []int
 	seasonal       bool
 	includeFitData bool
 	// NelderMead optimizer
@@ -24,9 +29,23 @@ type HoltWinters struct {
 	epsilon float64
 
 	vs    *array.Float
+	model Model
 	alloc memory.Allocator
 }
 
+// Model holds the parameters of the model fitted to a dataset.
+type Model struct {
+	Alpha float64
+	Beta  float64
+	Phi   float64
+	// Gammas are the seasonal smoothing parameters, one per seasonal period.
+	Gammas []float64
+	// SSE is the sum of the squared errors of the fitted values.
+	SSE float64
+	// StdErr is the standard deviation of the errors of the fitted values.
+	StdErr float64
+}
+
 const (
 	// Arbitrary weight for initializing some intial guesses.
 	// This should be in the  range [0,1]
@@ -47,24 +66,106 @@ const (
 // HoltWinters uses the given allocator for memory tracking purposes,
 // and in order to build its result.
 func New(n, s int, withFit bool, alloc memory.Allocator) *HoltWinters {
-	seasonal := s >= 2
-	return &HoltWinters{
+	return NewMultiSeasonal(n, []int{s}, withFit, alloc)
+}
+
+// NewMultiSeasonal creates a new HoltWinters with several seasonal periods.
+// The periods shorter than two points are ignored.
+func NewMultiSeasonal(n int, seasons []int, withFit bool, alloc memory.Allocator) *HoltWinters {
+	r := &HoltWinters{
 		n:              n,
-		s:              s,
-		seasonal:       seasonal,
 		includeFitData: withFit,
 		optim:          NewOptimizer(alloc),
 		epsilon:        hwDefaultEpsilon,
 		alloc:          alloc,
 	}
+	for _, s := range seasons {
+		if s >= 2 {
+			r.seasons = append(r.seasons, s)
+			if s > r.s {
+				r.s = s
+			}
+		}
+	}
+	r.seasonal = len(r.seasons) > 0
+	if len(r.seasons) == 1 {
+		r.seasons = nil
+	}
+	return r
+}
+
+// Model returns the parameters of the model fitted by the last call to Do or Fit.
+func (r *HoltWinters) Model() Model {
+	return r.model
 }
 
 // Do returns the points generated by the HoltWinters algorithm given a dataset.
 func (r *HoltWinters) Do(vs *array.Float) *array.Float {
+	if r.n <= 0 {
+		return arrow.NewFloat(nil, nil)
+	}
+	bestParams := r.fit(vs)
+	if bestParams == nil {
+		return arrow.NewFloat(nil, nil)
+	}
+
+	// Final forecast
+	fcast := func() *mutable.Float64Array {
+		fcast := r.forecast(bestParams, false)
+		// Now that bestParams have been used to generate the final forecast, they can be released.
+		defer bestParams.Release()
+		return fcast
+	}()
+	return fcast.NewFloat64Array()
+}
+
+// Fit fits the model to the dataset without forecasting, the parameters are returned by Model.
+// It returns false when the dataset is too small to fit a model.
+func (r *HoltWinters) Fit(vs *array.Float) bool {
+	bestParams := r.fit(vs)
+	if bestParams == nil {
+		return false
+	}
+	bestParams.Release()
+	return true
+}
+
+// Intervals returns the lower and upper bounds of the prediction intervals of the values returned by Do,
+// at the given confidence level. The intervals assume that the errors are normally distributed,
+// and the width of the intervals of the forecast values grows with the square root of the horizon.
+func (r *HoltWinters) Intervals(fcast *array.Float, confidence float64) (lower, upper *array.Float) {
+	z := distuv.UnitNormal.Quantile((1 + confidence) / 2)
+	fitted := 0
+	if r.includeFitData {
+		fitted = r.vs.Len()
+	}
+	lb := array.NewFloatBuilder(r.alloc)
+	ub := array.NewFloatBuilder(r.alloc)
+	lb.Reserve(fcast.Len())
+	ub.Reserve(fcast.Len())
+	for i := 0; i < fcast.Len(); i++ {
+		width := z * r.model.StdErr
+		if i >= fitted {
+			width *= math.Sqrt(float64(i - fitted + 1))
+		}
+		lb.Append(fcast.Value(i) - width)
+		ub.Append(fcast.Value(i) + width)
+	}
+	return lb.NewFloatArray(), ub.NewFloatArray()
+}
+
+// fit returns the best parameters for the dataset,
+// or nil if the dataset is too small to fit a model.
+// It is responsibility of the caller to Release the parameters.
+func (r *HoltWinters) fit(vs *array.Float) *mutable.Float64Array {
 	r.vs = vs
+	r.model = Model{}
 	l := vs.Len() // l is the length of both times and values
-	if l < 2 || r.seasonal && l < r.s || r.n <= 0 {
-		return arrow.NewFloat(nil, nil)
+	if l < 2 || r.seasonal && l < r.s {
+		return nil
+	}
+	if r.seasons != nil {
+		return r.optimize(r.initMultiSeasonalParams())
 	}
 	m := r.s
 
@@ -102,10 +203,9 @@ func (r *HoltWinters) Do(vs *array.Float) *array.Float {
 	}
 	// These parameters will be used by the Optimizer to generate new parameters
 	// basing on the `sse` function and changing alpha, beta, gamma, and phi.
-	// As such, they will be used over and over in the optimization loop above, and they can only
-	// be released at the end of this function.
+	// As such, they will be used over and over in the optimization loop, and they are
+	// released by optimize at the end of it.
 	initParams := mutable.NewFloat64Array(r.alloc)
-	defer initParams.Release()
 	initParams.Resize(size)
 	initParams.Set(4, l0)
 	initParams.Set(5, b0)
@@ -119,6 +219,15 @@ func (r *HoltWinters) Do(vs *array.Float) *array.Float {
 		}
 	}
 
+	return r.optimize(initParams)
+}
+
+// optimize returns the parameters that minimize the SSE,
+// starting from a grid of guesses for alpha, beta, gamma, and phi.
+// The initial parameters are released.
+func (r *HoltWinters) optimize(initParams *mutable.Float64Array) *mutable.Float64Array {
+	defer initParams.Release()
+
 	// Determine best fit for the various parameters
 	minSSE := math.Inf(1)
 	var bestParams *mutable.Float64Array
@@ -130,6 +239,9 @@ func (r *HoltWinters) Do(vs *array.Float) *array.Float {
 					initParams.Set(1, beta)
 					initParams.Set(2, gamma)
 					initParams.Set(3, phi)
+					for k := 1; k < len(r.seasons); k++ {
+						initParams.Set(r.gammaIdx(k), gamma)
+					}
 					// Optimize creates new parameters every time it is called.
 					sse, newParams := r.optim.Optimize(r.sse, initParams, r.epsilon, 1)
 					if sse < minSSE || bestParams == nil {
@@ -149,14 +261,23 @@ func (r *HoltWinters) Do(vs *array.Float) *array.Float {
 		}
 	}
 
-	// Final forecast
-	fcast := func() *mutable.Float64Array {
-		fcast := r.forecast(bestParams, false)
-		// Now that bestParams have been used to generate the final forecast, they can be released.
-		defer bestParams.Release()
-		return fcast
-	}()
-	return fcast.NewFloat64Array()
+	r.constrain(bestParams)
+	r.model = Model{
+		Alpha: bestParams.Value(0),
+		Beta:  bestParams.Value(1),
+		Phi:   bestParams.Value(3),
+		SSE:   minSSE,
+	}
+	if r.seasonal {
+		r.model.Gammas = []float64{bestParams.Value(2)}
+		for k := 1; k < len(r.seasons); k++ {
+			r.model.Gammas = append(r.model.Gammas, bestParams.Value(r.gammaIdx(k)))
+		}
+	}
+	if valid := r.vs.Len() - r.vs.NullN(); valid > 1 {
+		r.model.StdErr = math.Sqrt(minSSE / float64(valid-1))
+	}
+	return bestParams
 }
 
 // Using the recursive relations compute the next values
@@ -182,6 +303,9 @@ func (r *HoltWinters) forecast(params *mutable.Float64Array, onlyFit bool) *muta
 	}
 	// constrain parameters
 	r.constrain(params)
+	if r.seasons != nil {
+		return r.forecastMultiSeasonal(params, h, onlyFit)
+	}
 
 	yT := r.vs.Value(0)
 
@@ -301,4 +425,161 @@ func (r *HoltWinters) constrain(x *mutable.Float64Array) {
 	if x.Value(3) < 0 {
 		x.Set(3, 0)
 	}
+	// the gammas of the other seasons
+	for k := 1; k < len(r.seasons); k++ {
+		if x.Value(r.gammaIdx(k)) > 1 {
+			x.Set(r.gammaIdx(k), 1)
+		}
+		if x.Value(r.gammaIdx(k)) < 0 {
+			x.Set(r.gammaIdx(k), 0)
+		}
+	}
+}
+
+// gammaIdx returns the index of the gamma of the k-th season in the parameters.
+// The gamma of the first season is at the same index as for a single season,
+// the other ones follow the initial level and trend.
+func (r *HoltWinters) gammaIdx(k int) int {
+	if k == 0 {
+		return 2
+	}
+	return 5 + k
+}
+
+// seasonalsStart returns the index of the first seasonal value in the parameters,
+// the seasonal values of each season follow each other.
+func (r *HoltWinters) seasonalsStart() int {
+	return 5 + len(r.seasons)
+}
+
+// initMultiSeasonalParams returns the starting guesses of the multi-seasonal model.
+// The level is the mean of the longest season, and the seasonal values
+// are the mean ratios of the values to the level and the shorter seasons.
+// It is responsibility of the caller to Release the parameters.
+func (r *HoltWinters) initMultiSeasonalParams() *mutable.Float64Array {
+	vs := r.vs
+	m := r.s
+
+	l0, count := 0.0, 0
+	for i := 0; i < m; i++ {
+		if vs.IsValid(i) {
+			l0 += vs.Value(i)
+			count++
+		}
+	}
+	if count > 0 {
+		l0 /= float64(count)
+	}
+
+	b0 := 0.0
+	for i := 0; i < m && m+i < vs.Len(); i++ {
+		if vs.IsValid(i) && vs.IsValid(m+i) {
+			b0 += 1 / float64(m*m) * (vs.Value(m+i) - vs.Value(i))
+		}
+	}
+
+	size := r.seasonalsStart()
+	for _, s := range r.seasons {
+		size += s
+	}
+	params := mutable.NewFloat64Array(r.alloc)
+	params.Resize(size)
+	params.Set(4, l0)
+	params.Set(5, b0)
+
+	// ratios are the values of the longest season divided by
+	// the level and the seasonal values computed so far.
+	ratios := make([]float64, m)
+	valid := make([]bool, m)
+	for i := 0; i < m; i++ {
+		if vs.IsValid(i) && l0 != 0 {
+			ratios[i], valid[i] = vs.Value(i)/l0, true
+		}
+	}
+	start := r.seasonalsStart()
+	for _, s := range r.seasons {
+		for j := 0; j < s; j++ {
+			sum, count := 0.0, 0
+			for i := j; i < m; i += s {
+				if valid[i] {
+					sum += ratios[i]
+					count++
+				}
+			}
+			sj := 1.0
+			if count > 0 && sum != 0 {
+				sj = sum / float64(count)
+			}
+			params.Set(start+j, sj)
+			for i := j; i < m; i += s {
+				ratios[i] /= sj
+			}
+		}
+		start += s
+	}
+	return params
+}
+
+// forecastMultiSeasonal forecasts the data with the multi-seasonal model,
+// whose seasonal values multiply each other.
+// The fitted values are the one step ahead forecasts of the values of the dataset,
+// and the missing values are replaced with their forecast.
+func (r *HoltWinters) forecastMultiSeasonal(params *mutable.Float64Array, h int, onlyFit bool) *mutable.Float64Array {
+	alpha, beta, phi := params.Value(0), params.Value(1), params.Value(3)
+	lT, bT := params.Value(4), params.Value(5)
+
+	// seasonals are ring buffers of the seasonal values of each season,
+	// the value for the time t is at the index t % period.
+	seasonals := make([][]float64, len(r.seasons))
+	start := r.seasonalsStart()
+	for k, s := range r.seasons {
+		seasonals[k] = make([]float64, s)
+		for j := range seasonals[k] {
+			seasonals[k][j] = params.Value(start + j)
+		}
+		start += s
+	}
+	seasonal := func(t int) float64 {
+		sT := 1.0
+		for k, s := range r.seasons {
+			sT *= seasonals[k][t%s]
+		}
+		return sT
+	}
+
+	l := r.vs.Len()
+	size := h
+	if onlyFit || r.includeFitData {
+		size += l
+	}
+	fcast := mutable.NewFloat64Array(r.alloc)
+	fcast.Reserve(size)
+
+	for t := 0; t < l; t++ {
+		sT := seasonal(t)
+		yTh := (lT + phi*bT) * sT
+		if onlyFit || r.includeFitData {
+			fcast.Append(yTh)
+		}
+		yT := yTh
+		if r.vs.IsValid(t) {
+			yT = r.vs.Value(t)
+		}
+
+		lTp, bTp := lT, bT
+		lT = alpha*(yT/sT) + (1-alpha)*(lTp+phi*bTp)
+		bT = beta*(lT-lTp) + (1-beta)*phi*bTp
+		for k, s := range r.seasons {
+			sk := seasonals[k][t%s]
+			gamma := params.Value(r.gammaIdx(k))
+			seasonals[k][t%s] = gamma*(yT/((lTp+phi*bTp)*(sT/sk))) + (1-gamma)*sk
+		}
+	}
+
+	phiH := 0.0
+	for i := 1; i <= h; i++ {
+		phiH += math.Pow(phi, float64(i))
+		fcast.Append((lT + phiH*bT) * seasonal(l-1+i))
+	}
+	return fcast
 }
@@ -0,0 +1,135 @@
+package holt_winters_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/influxdata/flux/array"
+	"github.com/influxdata/flux/arrow"
+	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/stdlib/universe/holt_winters"
+)
+
+// doubleSeasonal returns a value with a season of 4 points and a season of 12 points.
+func doubleSeasonal(i int) float64 {
+	return 100 * (1 + 0.2*math.Sin(float64(i)*2*math.Pi/4)) * (1 + 0.1*math.Cos(float64(i)*2*math.Pi/12))
+}
+
+func TestHoltWinters_MultiSeasonal(t *testing.T) {
+	alloc := &memory.Allocator{}
+	defer func() {
+		if m := alloc.Allocated(); m != 0 {
+			t.Errorf("HoltWinters is using memory after finishing: %d", m)
+		}
+	}()
+	mem := arrow.NewAllocator(alloc)
+
+	b := array.NewFloatBuilder(mem)
+	for i := 0; i < 48; i++ {
+		b.Append(doubleSeasonal(i))
+	}
+	vs := b.NewFloatArray()
+	defer vs.Release()
+
+	hw := holt_winters.NewMultiSeasonal(12, []int{4, 12}, false, mem)
+	fcast := hw.Do(vs)
+	defer fcast.Release()
+	if want, got := 12, fcast.Len(); want != got {
+		t.Fatalf("unexpected number of forecast values -want/+got: %d/%d", want, got)
+	}
+	for i := 0; i < fcast.Len(); i++ {
+		if want, got := doubleSeasonal(48+i), fcast.Value(i); math.Abs(want-got) > 1e-6 {
+			t.Errorf("unexpected forecast at %d -want/+got: %v/%v", i, want, got)
+		}
+	}
+
+	model := hw.Model()
+	if want, got := 2, len(model.Gammas); want != got {
+		t.Fatalf("unexpected number of gammas -want/+got: %d/%d", want, got)
+	}
+	for _, p := range append([]float64{model.Alpha, model.Beta, model.Phi}, model.Gammas...) {
+		if p < 0 || p > 1 {
+			t.Errorf("parameter out of range: %v", p)
+		}
+	}
+
+	lower, upper := hw.Intervals(fcast, 0.95)
+	defer lower.Release()
+	defer upper.Release()
+	for i := 0; i < fcast.Len(); i++ {
+		if lower.Value(i) > fcast.Value(i) || upper.Value(i) < fcast.Value(i) {
+			t.Errorf("forecast %v is not within the interval [%v, %v]", fcast.Value(i), lower.Value(i), upper.Value(i))
+		}
+	}
+}
+
+func TestHoltWinters_Intervals(t *testing.T) {
+	alloc := &memory.Allocator{}
+	defer func() {
+		if m := alloc.Allocated(); m != 0 {
+			t.Errorf("HoltWinters is using memory after finishing: %d", m)
+		}
+	}()
+	mem := arrow.NewAllocator(alloc)
+
+	b := array.NewFloatBuilder(mem)
+	for i, v := range []float64{10, 12, 9, 14, 11, 13, 10, 15, 12, 14} {
+		b.Append(v + float64(i)*0.5)
+	}
+	vs := b.NewFloatArray()
+	defer vs.Release()
+
+	hw := holt_winters.New(5, 0, true, mem)
+	fcast := hw.Do(vs)
+	defer fcast.Release()
+	if hw.Model().StdErr <= 0 {
+		t.Fatalf("expected a positive standard error, got %v", hw.Model().StdErr)
+	}
+
+	lower, upper := hw.Intervals(fcast, 0.9)
+	defer lower.Release()
+	defer upper.Release()
+	width := func(i int) float64 {
+		return upper.Value(i) - lower.Value(i)
+	}
+	// the fitted values have the same width, and the width of the
+	// forecast values grows with the horizon.
+	for i := 1; i < vs.Len(); i++ {
+		if math.Abs(width(i)-width(0)) > 1e-9 {
+			t.Errorf("unexpected width of fitted value %d: %v, want %v", i, width(i), width(0))
+		}
+	}
+	for i := vs.Len() + 1; i < fcast.Len(); i++ {
+		if width(i) <= width(i-1) {
+			t.Errorf("expected the width of forecast value %d to grow, got %v after %v", i, width(i), width(i-1))
+		}
+	}
+}
+
+func TestHoltWinters_Fit(t *testing.T) {
+	alloc := &memory.Allocator{}
+	defer func() {
+		if m := alloc.Allocated(); m != 0 {
+			t.Errorf("HoltWinters is using memory after finishing: %d", m)
+		}
+	}()
+	mem := arrow.NewAllocator(alloc)
+
+	b := array.NewFloatBuilder(mem)
+	b.Append(1)
+	b.Append(2)
+	b.Append(3)
+	vs := b.NewFloatArray()
+	defer vs.Release()
+
+	if holt_winters.NewMultiSeasonal(0, []int{2, 4}, false, mem).Fit(vs) {
+		t.Error("expected the fit to fail with less values than the longest season")
+	}
+	hw := holt_winters.NewMultiSeasonal(0, nil, false, mem)
+	if !hw.Fit(vs) {
+		t.Fatal("expected the fit to succeed")
+	}
+	if got := hw.Model().Gammas; len(got) != 0 {
+		t.Errorf("expected no gammas without seasons, got %v", got)
+	}
+}
@@ -99,6 +99,58 @@ func TestHoltWinters_NewQuery(t *testing.T) {
 				},
 			},
 		},
+		{
+			Name: "holt winters seasonalities",
+			Raw:  `from(bucket:"mydb") |> range(start:-1h) |> holtWinters(n: 24, seasonalities: [24, 168], confidence: 0.9, interval: 1h)`,
+			Want: &flux.Spec{
+				Operations: []*flux.Operation{
+					{
+						ID: "from0",
+						Spec: &influxdb.FromOpSpec{
+							Bucket: influxdb.NameOrID{Name: "mydb"},
+						},
+					},
+					{
+						ID: "range1",
+						Spec: &universe.RangeOpSpec{
+							Start: flux.Time{
+								Relative:   -1 * time.Hour,
+								IsRelative: true,
+							},
+							Stop:        flux.Now,
+							TimeColumn:  "_time",
+							StartColumn: "_start",
+							StopColumn:  "_stop",
+						},
+					},
+					{
+						ID: "holtWinters2",
+						Spec: &universe.HoltWintersOpSpec{
+							Column:        execute.DefaultValueColLabel,
+							TimeColumn:    execute.DefaultTimeColLabel,
+							N:             24,
+							Seasonalities: []int64{24, 168},
+							Confidence:    0.9,
+							Interval:      flux.ConvertDuration(time.Hour),
+						},
+					},
+				},
+				Edges: []flux.Edge{
+					{Parent: "from0", Child: "range1"},
+					{Parent: "range1", Child: "holtWinters2"},
+				},
+			},
+		},
+		{
+			Name:    "holt winters seasonality and seasonalities",
+			Raw:     `from(bucket:"mydb") |> range(start:-1h) |> holtWinters(n: 24, seasonality: 24, seasonalities: [24, 168], interval: 1h)`,
+			WantErr: true,
+		},
+		{
+			Name:    "holt winters invalid confidence",
+			Raw:     `from(bucket:"mydb") |> range(start:-1h) |> holtWinters(n: 24, confidence: 95.0, interval: 1h)`,
+			WantErr: true,
+		},
 		{
 			Name:    "holt winters blank",
 			Raw:     `from(bucket:"mydb") |> range(start:-1h) |> holtWinters()`,
@@ -890,3 +942,96 @@ func TestHoltWinters_Process(t *testing.T) {
 		})
 	}
 }
+
+func TestHoltWintersParameters_Process(t *testing.T) {
+	testCases := []struct {
+		name string
+		spec *universe.HoltWintersParametersProcedureSpec
+		data []flux.Table
+		want []*executetest.Table
+	}{
+		{
+			name: "too few values",
+			spec: &universe.HoltWintersParametersProcedureSpec{
+				Column:     "_value",
+				TimeColumn: "_time",
+				S:          4,
+				Interval:   flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				KeyCols: []string{"t0"},
+				ColMeta: []flux.ColMeta{
+					{Label: "t0", Type: flux.TString},
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{"a", execute.Time(1), 1.0},
+					{"a", execute.Time(2), 2.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				KeyCols:   []string{"t0"},
+				KeyValues: []interface{}{"a"},
+				ColMeta: []flux.ColMeta{
+					{Label: "t0", Type: flux.TString},
+					{Label: "alpha", Type: flux.TFloat},
+					{Label: "beta", Type: flux.TFloat},
+					{Label: "phi", Type: flux.TFloat},
+					{Label: "gamma", Type: flux.TFloat},
+					{Label: "sse", Type: flux.TFloat},
+					{Label: "stderr", Type: flux.TFloat},
+				},
+			}},
+		},
+		{
+			name: "multiple seasons",
+			spec: &universe.HoltWintersParametersProcedureSpec{
+				Column:        "_value",
+				TimeColumn:    "_time",
+				Seasonalities: []int64{2, 4},
+				Interval:      flux.ConvertDuration(time.Nanosecond),
+			},
+			data: []flux.Table{&executetest.Table{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 1.0},
+				},
+			}},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "alpha", Type: flux.TFloat},
+					{Label: "beta", Type: flux.TFloat},
+					{Label: "phi", Type: flux.TFloat},
+					{Label: "gamma_2", Type: flux.TFloat},
+					{Label: "gamma_4", Type: flux.TFloat},
+					{Label: "sse", Type: flux.TFloat},
+					{Label: "stderr", Type: flux.TFloat},
+				},
+			}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			alloc := &memory.Allocator{}
+			executetest.ProcessTestHelper(
+				t,
+				tc.data,
+				tc.want,
+				nil,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return universe.NewHoltWintersParametersTransformation(d, c, alloc, tc.spec)
+				},
+			)
+
+			if m := alloc.Allocated(); m != 0 {
+				t.Errorf("HoltWinters is using memory after finishing: %d", m)
+			}
+		})
+	}
+}
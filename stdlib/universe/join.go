@@ -1,6 +1,7 @@
 package universe
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"sort"
@@ -221,6 +222,7 @@ func createMergeJoinTransformation(id execute.DatasetID, mode execute.Accumulati
 	}
 
 	cache := NewMergeJoinCache(a.Allocator(), parents, tableNames, s.On)
+	cache.ctx = a.Context()
 	d := execute.NewDataset(id, mode, cache)
 	t := NewMergeJoinTransformation(d, cache, s, parents, tableNames)
 	return t, d, nil
@@ -394,6 +396,10 @@ type MergeJoinCache struct {
 	tables      map[flux.GroupKey]flux.Table
 	alloc       *memory.Allocator
 	triggerSpec plan.TriggerSpec
+
+	// ctx is checked while joining tables so
+	// that a large join can be canceled.
+	ctx context.Context
 }
 
 type streamBuffer struct {
@@ -547,6 +553,7 @@ func NewMergeJoinCache(alloc *memory.Allocator, datasetIDs []execute.DatasetID,
 		postJoinKeys:  execute.NewGroupLookup(),
 		tables:        make(map[flux.GroupKey]flux.Table),
 		alloc:         alloc,
+		ctx:           context.Background(),
 	}
 }
 
@@ -910,10 +917,17 @@ func (c *MergeJoinCache) join(left, right *execute.ColListTableBuilder) (flux.Ta
 	missingIdxs := c.missingColIdx(left.Cols(), right.Cols())
 
 	// Perform sort merge join
+	var n int
 	for !leftSet.Empty() && !rightSet.Empty() {
 		if leftKey.EqualTrueNulls(rightKey) {
 			for l := leftSet.Start; l < leftSet.Stop; l++ {
 				for r := rightSet.Start; r < rightSet.Stop; r++ {
+					// The cross product of the matching rows
+					// may be very large so check for cancellation.
+					if err := execute.CheckCancel(c.ctx, n); err != nil {
+						return nil, err
+					}
+					n++
 
 					leftRecord := left.GetRow(l)
 					rightRecord := right.GetRow(r)
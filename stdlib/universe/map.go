@@ -166,39 +166,47 @@ func (t *mapTransformation) Process(id execute.DatasetID, tbl flux.Table) error
 				}
 			}
 
-			key := groupKeyForObject(i, cr, m, on)
-			builder, created := t.cache.TableBuilder(key)
-			if created {
-				if err := t.createSchema(fn, builder, m); err != nil {
-					return err
-				}
-			}
-
-			for j, c := range builder.Cols() {
-				v, ok := m.Get(c.Label)
-				if !ok {
-					if idx := execute.ColIdx(c.Label, tbl.Key().Cols()); t.mergeKey && idx >= 0 {
-						v = tbl.Key().Value(idx)
-					} else {
-						// This should be unreachable
-						return errors.Newf(codes.Internal, "could not find value for column %q", c.Label)
-					}
-				}
-				if !v.IsNull() && c.Type.String() != v.Type().Nature().String() {
-					return errors.Newf(codes.Invalid, "map regroups data such that column %q would include values"+
-						" of two different data types: %v, %v",
-						c.Label, c.Type, v.Type(),
-					)
-				}
-				if err := builder.AppendValue(j, v); err != nil {
-					return err
-				}
+			if err := t.appendRow(tbl.Key(), fn, i, cr, m, on); err != nil {
+				return err
 			}
 		}
 		return nil
 	})
 }
 
+// appendRow appends the object returned by the map function for row i
+// to the table for its group key.
+func (t *mapTransformation) appendRow(key flux.GroupKey, fn *execute.RowMapPreparedFn, i int, cr flux.ColReader, m values.Object, on map[string]bool) error {
+	builder, created := t.cache.TableBuilder(groupKeyForObject(i, cr, m, on))
+	if created {
+		if err := t.createSchema(fn, builder, m); err != nil {
+			return err
+		}
+	}
+
+	for j, c := range builder.Cols() {
+		v, ok := m.Get(c.Label)
+		if !ok {
+			if idx := execute.ColIdx(c.Label, key.Cols()); t.mergeKey && idx >= 0 {
+				v = key.Value(idx)
+			} else {
+				// This should be unreachable
+				return errors.Newf(codes.Internal, "could not find value for column %q", c.Label)
+			}
+		}
+		if !v.IsNull() && c.Type.String() != v.Type().Nature().String() {
+			return errors.Newf(codes.Invalid, "map regroups data such that column %q would include values"+
+				" of two different data types: %v, %v",
+				c.Label, c.Type, v.Type(),
+			)
+		}
+		if err := builder.AppendValue(j, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (t *mapTransformation) groupOn(key flux.GroupKey, m semantic.MonoType) (map[string]bool, error) {
 	on := make(map[string]bool, len(key.Cols()))
 	for _, c := range key.Cols() {
@@ -0,0 +1,378 @@
+package universe
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/compiler"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const MapFilterKind = "mapFilter"
+
+func init() {
+	execute.RegisterTransformation(MapFilterKind, createMapFilterTransformation)
+	plan.RegisterPhysicalRules(FuseMapFilterRule{})
+}
+
+// MapFilterProcedureSpec evaluates a chain of map and filter
+// functions on each row without building the tables in between.
+// It is created by FuseMapFilterRule.
+type MapFilterProcedureSpec struct {
+	plan.DefaultCost
+	// Steps are the *MapProcedureSpec and *FilterProcedureSpec
+	// that are evaluated, in order.
+	Steps []plan.ProcedureSpec
+}
+
+func (s *MapFilterProcedureSpec) Kind() plan.ProcedureKind {
+	return MapFilterKind
+}
+
+func (s *MapFilterProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := &MapFilterProcedureSpec{
+		Steps: make([]plan.ProcedureSpec, len(s.Steps)),
+	}
+	for i, step := range s.Steps {
+		ns.Steps[i] = step.Copy()
+	}
+	return ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *MapFilterProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func (s *MapFilterProcedureSpec) PlanDetails() string {
+	var sb strings.Builder
+	for i, step := range s.Steps {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		switch step := step.(type) {
+		case *MapProcedureSpec:
+			sb.WriteString("map: ")
+			if expr, ok := step.Fn.Fn.GetFunctionBodyExpression(); ok {
+				fmt.Fprintf(&sb, "%v", semantic.Formatted(expr))
+			} else {
+				sb.WriteString("<non-Expression>")
+			}
+		case *FilterProcedureSpec:
+			fmt.Fprintf(&sb, "filter: %s", step.PlanDetails())
+		}
+	}
+	return sb.String()
+}
+
+func createMapFilterTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*MapFilterProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t, err := NewMapFilterTransformation(a.Context(), s, d, cache)
+	if err != nil {
+		return nil, nil, err
+	}
+	return t, d, nil
+}
+
+type mapFilterStep struct {
+	mapFn    *execute.RowMapFn
+	filterFn *execute.RowPredicateFn
+}
+
+type mapFilterTransformation struct {
+	mapTransformation
+	steps []mapFilterStep
+}
+
+func NewMapFilterTransformation(ctx context.Context, spec *MapFilterProcedureSpec, d execute.Dataset, cache execute.TableBuilderCache) (*mapFilterTransformation, error) {
+	steps := make([]mapFilterStep, len(spec.Steps))
+	for i, step := range spec.Steps {
+		switch step := step.(type) {
+		case *MapProcedureSpec:
+			if step.MergeKey {
+				return nil, errors.New(codes.Internal, "cannot fuse map with mergeKey")
+			}
+			steps[i].mapFn = execute.NewRowMapFn(step.Fn.Fn, compiler.ToScope(step.Fn.Scope))
+		case *FilterProcedureSpec:
+			steps[i].filterFn = execute.NewRowPredicateFn(step.Fn.Fn, compiler.ToScope(step.Fn.Scope))
+		default:
+			return nil, errors.Newf(codes.Internal, "invalid step type %T", step)
+		}
+	}
+	return &mapFilterTransformation{
+		mapTransformation: mapTransformation{
+			d:     d,
+			cache: cache,
+			ctx:   ctx,
+		},
+		steps: steps,
+	}, nil
+}
+
+// preparedMapFilterStep is a step prepared for the columns of its input.
+type preparedMapFilterStep struct {
+	mapFn    *execute.RowMapPreparedFn
+	filterFn *execute.RowPredicatePreparedFn
+	// cols are the columns of the table map would have produced.
+	cols []flux.ColMeta
+}
+
+func (t *mapFilterTransformation) prepare(cols []flux.ColMeta) ([]preparedMapFilterStep, error) {
+	steps := make([]preparedMapFilterStep, len(t.steps))
+	for i, step := range t.steps {
+		if step.filterFn != nil {
+			fn, err := step.filterFn.Prepare(cols)
+			if err != nil {
+				return nil, err
+			}
+			steps[i].filterFn = fn
+			continue
+		}
+
+		fn, err := step.mapFn.Prepare(cols)
+		if err != nil {
+			return nil, err
+		}
+		cols, err = mapColumns(fn.Type())
+		if err != nil {
+			return nil, err
+		}
+		steps[i].mapFn = fn
+		steps[i].cols = cols
+	}
+	return steps, nil
+}
+
+func (t *mapFilterTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	steps, err := t.prepare(tbl.Cols())
+	if err != nil {
+		return err
+	}
+
+	// The output is grouped by the columns in the group key
+	// that are returned by every map function.
+	on := make(map[string]bool, len(tbl.Key().Cols()))
+	for _, c := range tbl.Key().Cols() {
+		on[c.Label] = true
+	}
+	var last *execute.RowMapPreparedFn
+	for _, step := range steps {
+		if step.mapFn == nil {
+			continue
+		}
+		for label := range on {
+			if execute.ColIdx(label, step.cols) < 0 {
+				delete(on, label)
+			}
+		}
+		last = step.mapFn
+	}
+	if last == nil {
+		return errors.New(codes.Internal, "fused map and filter has no map function")
+	}
+
+	return tbl.Do(func(cr flux.ColReader) error {
+		l := cr.Len()
+		for i := 0; i < l; i++ {
+			m, ok, err := t.eval(steps, i, cr)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			if err := t.appendRow(tbl.Key(), last, i, cr, m, on); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// eval evaluates the steps for row i. It returns the object returned
+// by the last map function and false if the row was filtered.
+func (t *mapFilterTransformation) eval(steps []preparedMapFilterStep, i int, cr flux.ColReader) (values.Object, bool, error) {
+	// The record is nil until a map function has been
+	// evaluated and the steps read from the row instead.
+	var m, record values.Object
+	for j, step := range steps {
+		if step.filterFn != nil {
+			var pass bool
+			var err error
+			if record == nil {
+				pass, err = step.filterFn.EvalRow(t.ctx, i, cr)
+			} else {
+				pass, err = step.filterFn.Eval(t.ctx, record)
+			}
+			if err != nil {
+				return nil, false, errors.Wrap(err, codes.Invalid, "failed to evaluate filter function")
+			}
+			if !pass {
+				return nil, false, nil
+			}
+			continue
+		}
+
+		var err error
+		if record == nil {
+			m, err = step.mapFn.Eval(t.ctx, i, cr)
+		} else {
+			m, err = step.mapFn.EvalRecord(t.ctx, record)
+		}
+		if err != nil {
+			return nil, false, errors.Wrap(err, codes.Invalid, "failed to evaluate map function")
+		}
+		if j < len(steps)-1 {
+			if record, err = recordForColumns(m, step.cols); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	return m, true, nil
+}
+
+// mapColumns returns the columns of the table built by map
+// for a function with the given return type.
+func mapColumns(typ semantic.MonoType) ([]flux.ColMeta, error) {
+	n, err := typ.NumProperties()
+	if err != nil {
+		return nil, err
+	}
+
+	natures := make(map[string]semantic.Nature, n)
+	labels := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		prop, err := typ.RecordProperty(i)
+		if err != nil {
+			return nil, err
+		}
+		// Only the first property with a name is visible.
+		if _, ok := natures[prop.Name()]; ok {
+			continue
+		}
+		ptyp, err := prop.TypeOf()
+		if err != nil {
+			return nil, err
+		}
+		natures[prop.Name()] = ptyp.Nature()
+		labels = append(labels, prop.Name())
+	}
+	sort.Strings(labels)
+
+	cols := make([]flux.ColMeta, 0, len(labels))
+	for _, label := range labels {
+		nature := natures[label]
+		if nature == semantic.Invalid {
+			continue
+		}
+		ty := execute.ConvertFromKind(nature)
+		if ty == flux.TInvalid {
+			return nil, errors.Newf(codes.Invalid, `map object property "%s" is %v type which is not supported in a flux table`, label, nature)
+		}
+		cols = append(cols, flux.ColMeta{Label: label, Type: ty})
+	}
+	return cols, nil
+}
+
+// recordForColumns returns the record the next step would read
+// from the table built by map.
+func recordForColumns(m values.Object, cols []flux.ColMeta) (values.Object, error) {
+	return values.BuildObjectWithSize(len(cols), func(set values.ObjectSetter) error {
+		for _, c := range cols {
+			v, ok := m.Get(c.Label)
+			if !ok {
+				v = values.NewNull(flux.SemanticType(c.Type))
+			}
+			set(c.Label, v)
+		}
+		return nil
+	})
+}
+
+// FuseMapFilterRule fuses consecutive map and filter nodes into a
+// single node that evaluates each row with all of their functions.
+// A map with mergeKey and a filter that keeps empty tables are not fused
+// because their output depends on the tables they receive.
+type FuseMapFilterRule struct{}
+
+func (FuseMapFilterRule) Name() string {
+	return "FuseMapFilterRule"
+}
+
+func (FuseMapFilterRule) Pattern() plan.Pattern {
+	kinds := []plan.ProcedureKind{MapKind, FilterKind, MapFilterKind}
+	return plan.OneOf(kinds, plan.OneOf(kinds, plan.Any()))
+}
+
+func (FuseMapFilterRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	pred := node.Predecessors()[0]
+	if len(pred.Successors()) != 1 {
+		return node, false, nil
+	}
+	// Leave a filter directly after a source to
+	// the rules that push it into the source.
+	if pred.Kind() == FilterKind && len(pred.Predecessors()[0].Predecessors()) == 0 {
+		return node, false, nil
+	}
+
+	steps, ok := fusableSteps(pred.ProcedureSpec())
+	if !ok {
+		return node, false, nil
+	}
+	top, ok := fusableSteps(node.ProcedureSpec())
+	if !ok {
+		return node, false, nil
+	}
+	steps = append(steps, top...)
+
+	hasMap := false
+	for _, step := range steps {
+		if _, ok := step.(*MapProcedureSpec); ok {
+			hasMap = true
+			break
+		}
+	}
+	if !hasMap {
+		return node, false, nil
+	}
+
+	n, err := plan.MergeToPhysicalNode(node, pred, &MapFilterProcedureSpec{Steps: steps})
+	if err != nil {
+		return nil, false, err
+	}
+	return n, true, nil
+}
+
+// fusableSteps returns copies of the steps for a procedure spec
+// or false if it cannot be fused.
+func fusableSteps(spec plan.ProcedureSpec) ([]plan.ProcedureSpec, bool) {
+	switch spec := spec.(type) {
+	case *MapProcedureSpec:
+		if spec.MergeKey {
+			return nil, false
+		}
+		return []plan.ProcedureSpec{spec.Copy()}, true
+	case *FilterProcedureSpec:
+		if spec.KeepEmptyTables {
+			return nil, false
+		}
+		return []plan.ProcedureSpec{spec.Copy()}, true
+	case *MapFilterProcedureSpec:
+		return spec.Copy().(*MapFilterProcedureSpec).Steps, true
+	default:
+		return nil, false
+	}
+}
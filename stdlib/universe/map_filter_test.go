@@ -0,0 +1,238 @@
+package universe_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+func TestMapFilter_Process(t *testing.T) {
+	builtIns := runtime.Prelude()
+	mapSpec := func(fn string) *universe.MapProcedureSpec {
+		return &universe.MapProcedureSpec{
+			Fn: interpreter.ResolvedFunction{
+				Scope: builtIns,
+				Fn:    executetest.FunctionExpression(t, fn),
+			},
+		}
+	}
+	filterSpec := func(fn string) *universe.FilterProcedureSpec {
+		return &universe.FilterProcedureSpec{
+			Fn: interpreter.ResolvedFunction{
+				Scope: builtIns,
+				Fn:    executetest.FunctionExpression(t, fn),
+			},
+		}
+	}
+	data := func() []flux.Table {
+		return []flux.Table{&executetest.Table{
+			KeyCols: []string{"host"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_time", Type: flux.TTime},
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "host", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{execute.Time(1), 1.0, "a"},
+				{execute.Time(2), 2.0, "a"},
+				{execute.Time(3), 3.0, "a"},
+				{execute.Time(4), 4.0, "a"},
+			},
+		}}
+	}
+
+	testCases := []struct {
+		name string
+		spec *universe.MapFilterProcedureSpec
+		want []*executetest.Table
+	}{
+		{
+			name: "filter then map",
+			spec: &universe.MapFilterProcedureSpec{
+				Steps: []plan.ProcedureSpec{
+					filterSpec(`(r) => r._value > 2.0`),
+					mapSpec(`(r) => ({r with _value: r._value * 10.0})`),
+				},
+			},
+			want: []*executetest.Table{{
+				KeyCols: []string{"host"},
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "_value", Type: flux.TFloat},
+					{Label: "host", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(3), 30.0, "a"},
+					{execute.Time(4), 40.0, "a"},
+				},
+			}},
+		},
+		{
+			name: "map filter map",
+			spec: &universe.MapFilterProcedureSpec{
+				Steps: []plan.ProcedureSpec{
+					mapSpec(`(r) => ({_time: r._time, doubled: r._value * 2.0})`),
+					filterSpec(`(r) => r.doubled < 6.0`),
+					mapSpec(`(r) => ({r with host: "b"})`),
+				},
+			},
+			want: []*executetest.Table{{
+				ColMeta: []flux.ColMeta{
+					{Label: "_time", Type: flux.TTime},
+					{Label: "doubled", Type: flux.TFloat},
+					{Label: "host", Type: flux.TString},
+				},
+				Data: [][]interface{}{
+					{execute.Time(1), 2.0, "b"},
+					{execute.Time(2), 4.0, "b"},
+				},
+			}},
+		},
+		{
+			name: "filter all",
+			spec: &universe.MapFilterProcedureSpec{
+				Steps: []plan.ProcedureSpec{
+					mapSpec(`(r) => ({r with _value: r._value * 10.0})`),
+					filterSpec(`(r) => r._value > 100.0`),
+				},
+			},
+			want: nil,
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				data(),
+				tc.want,
+				nil,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					ctx := dependenciestest.Default().Inject(context.Background())
+					f, err := universe.NewMapFilterTransformation(ctx, tc.spec, d, c)
+					if err != nil {
+						t.Fatal(err)
+					}
+					return f
+				},
+			)
+		})
+	}
+}
+
+func TestFuseMapFilterRule(t *testing.T) {
+	var (
+		from   = &influxdb.FromProcedureSpec{}
+		mapFn  = executetest.FunctionExpression(t, `(r) => ({r with _value: r._value * 2.0})`)
+		filter = func(keepEmpty bool) *universe.FilterProcedureSpec {
+			return &universe.FilterProcedureSpec{
+				KeepEmptyTables: keepEmpty,
+				Fn: interpreter.ResolvedFunction{
+					Fn: executetest.FunctionExpression(t, `(r) => r._value > 1.0`),
+				},
+			}
+		}
+		mapSpec = func(mergeKey bool) *universe.MapProcedureSpec {
+			return &universe.MapProcedureSpec{
+				MergeKey: mergeKey,
+				Fn:       interpreter.ResolvedFunction{Fn: mapFn},
+			}
+		}
+	)
+
+	tests := []plantest.RuleTestCase{
+		{
+			Name:  "map filter map",
+			Rules: []plan.Rule{universe.FuseMapFilterRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from),
+					plan.CreatePhysicalNode("map0", mapSpec(false)),
+					plan.CreatePhysicalNode("filter", filter(false)),
+					plan.CreatePhysicalNode("map1", mapSpec(false)),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}, {2, 3}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from),
+					plan.CreatePhysicalNode("merged_map0_filter_map1", &universe.MapFilterProcedureSpec{
+						Steps: []plan.ProcedureSpec{mapSpec(false), filter(false), mapSpec(false)},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name:  "filters only",
+			Rules: []plan.Rule{universe.FuseMapFilterRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from),
+					plan.CreatePhysicalNode("filter0", filter(false)),
+					plan.CreatePhysicalNode("filter1", filter(false)),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "map with mergeKey",
+			Rules: []plan.Rule{universe.FuseMapFilterRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from),
+					plan.CreatePhysicalNode("map", mapSpec(true)),
+					plan.CreatePhysicalNode("filter", filter(false)),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "filter keeps empty tables",
+			Rules: []plan.Rule{universe.FuseMapFilterRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from),
+					plan.CreatePhysicalNode("map", mapSpec(false)),
+					plan.CreatePhysicalNode("filter", filter(true)),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "map with multiple successors",
+			Rules: []plan.Rule{universe.FuseMapFilterRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", from),
+					plan.CreatePhysicalNode("map", mapSpec(false)),
+					plan.CreatePhysicalNode("filter", filter(false)),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{}),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}, {1, 3}},
+			},
+			NoChange: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
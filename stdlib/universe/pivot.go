@@ -49,6 +49,7 @@ func init() {
 
 	// optimized pivot
 	execute.RegisterTransformation(SortedPivotKind, createSortedPivotTransformation)
+	plan.RegisterPhysicalRules(PivotSortedInputRule{})
 }
 
 func createPivotOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
@@ -107,7 +108,6 @@ func (s *PivotOpSpec) Kind() flux.OperationKind {
 }
 
 type PivotProcedureSpec struct {
-	plan.DefaultCost
 	RowKey      []string
 	ColumnKey   []string
 	ValueColumn string
@@ -153,6 +153,87 @@ func (s *PivotProcedureSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// Cost implements plan.PhysicalProcedureSpec.
+// Pivot converts the row key of every row into a string to find its
+// output row and keeps every table in memory until its input finishes.
+func (s *PivotProcedureSpec) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	_, out := plan.DefaultCost{}.Cost(inStats)
+	return plan.Cost{CPU: 2 * out.Cardinality, MEM: out.Cardinality}, out
+}
+
+// Alternatives implements plan.AlternativesProcedureSpec.
+// The pivot can be done by sortedPivot when the planner has reported
+// that the input is sorted by the only row key and that the only
+// column key is part of the group key.
+func (s *PivotProcedureSpec) Alternatives() []plan.Alternative {
+	if len(s.RowKey) != 1 || len(s.ColumnKey) != 1 ||
+		s.IsSortedByFunc == nil || s.IsKeyColumnFunc == nil {
+		return nil
+	}
+	if !s.IsSortedByFunc(s.RowKey, false) || !s.IsKeyColumnFunc(s.ColumnKey[0]) {
+		return nil
+	}
+	return []plan.Alternative{{
+		Spec: &SortedPivotProcedureSpec{
+			RowKey:      []string{s.RowKey[0]},
+			ColumnKey:   []string{s.ColumnKey[0]},
+			ValueColumn: s.ValueColumn,
+		},
+	}}
+}
+
+// PivotSortedInputRule sets IsSortedByFunc and IsKeyColumnFunc of a pivot
+// whose input is sorted by a sort that follows a group, so the planner can
+// choose sortedPivot when it is cheaper.
+type PivotSortedInputRule struct{}
+
+func (PivotSortedInputRule) Name() string {
+	return "PivotSortedInputRule"
+}
+
+func (PivotSortedInputRule) Pattern() plan.Pattern {
+	return plan.Pat(PivotKind, plan.Pat(SortKind, plan.Pat(GroupKind, plan.Any())))
+}
+
+func (PivotSortedInputRule) Rewrite(ctx context.Context, pn plan.Node) (plan.Node, bool, error) {
+	spec := pn.ProcedureSpec().(*PivotProcedureSpec)
+	if spec.IsSortedByFunc != nil || spec.IsKeyColumnFunc != nil {
+		return pn, false, nil
+	}
+	sortNode := pn.Predecessors()[0]
+	sortSpec := sortNode.ProcedureSpec().(*SortProcedureSpec)
+	groupSpec := sortNode.Predecessors()[0].ProcedureSpec().(*GroupProcedureSpec)
+	if groupSpec.GroupMode != flux.GroupModeBy && groupSpec.GroupMode != flux.GroupModeExcept {
+		return pn, false, nil
+	}
+
+	spec = spec.Copy().(*PivotProcedureSpec)
+	sortCols, sortDesc := sortSpec.Columns, sortSpec.Desc
+	spec.IsSortedByFunc = func(cols []string, desc bool) bool {
+		if desc != sortDesc || len(cols) > len(sortCols) {
+			return false
+		}
+		for i, col := range cols {
+			if sortCols[i] != col {
+				return false
+			}
+		}
+		return true
+	}
+	groupMode, groupKeys := groupSpec.GroupMode, groupSpec.GroupKeys
+	spec.IsKeyColumnFunc = func(label string) bool {
+		inKeys := execute.ContainsStr(groupKeys, label)
+		if groupMode == flux.GroupModeExcept {
+			return !inKeys
+		}
+		return inKeys
+	}
+	if err := pn.ReplaceSpec(spec); err != nil {
+		return pn, false, err
+	}
+	return pn, true, nil
+}
+
 func createPivotTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
 	s, ok := spec.(*PivotProcedureSpec)
 	if !ok {
@@ -411,7 +492,6 @@ func (t *pivotTransformation) Finish(id execute.DatasetID, err error) {
 }
 
 type SortedPivotProcedureSpec struct {
-	plan.DefaultCost
 	RowKey      []string
 	ColumnKey   []string
 	ValueColumn string
@@ -431,6 +511,18 @@ func (s *SortedPivotProcedureSpec) Copy() plan.ProcedureSpec {
 	return ns
 }
 
+// Cost implements plan.PhysicalProcedureSpec.
+// The sorted pivot merges the rows of each table as they arrive
+// and only keeps the group it is building in memory.
+func (s *SortedPivotProcedureSpec) Cost(inStats []plan.Statistics) (plan.Cost, plan.Statistics) {
+	_, out := plan.DefaultCost{}.Cost(inStats)
+	mem := out.Cardinality
+	if out.GroupCardinality > 1 {
+		mem /= out.GroupCardinality
+	}
+	return plan.Cost{CPU: out.Cardinality, MEM: mem}, out
+}
+
 func createSortedPivotTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
 	s, ok := spec.(*SortedPivotProcedureSpec)
 	if !ok {
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/codes"
 	"github.com/influxdata/flux/execute"
@@ -12,6 +13,8 @@ import (
 	"github.com/influxdata/flux/internal/errors"
 	"github.com/influxdata/flux/internal/gen"
 	"github.com/influxdata/flux/memory"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
 	"github.com/influxdata/flux/querytest"
 	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
 	"github.com/influxdata/flux/stdlib/universe"
@@ -786,6 +789,148 @@ func TestPivot_Process(t *testing.T) {
 	}
 }
 
+// pivotSource is a source that knows how many rows and tables it produces.
+type pivotSource struct {
+	plan.DefaultCost
+	Rows, Tables int64
+}
+
+func (s *pivotSource) Kind() plan.ProcedureKind { return "pivotSource" }
+func (s *pivotSource) Copy() plan.ProcedureSpec { ns := *s; return &ns }
+
+func (s *pivotSource) Statistics() (plan.Statistics, bool) {
+	return plan.Statistics{Cardinality: s.Rows, GroupCardinality: s.Tables}, s.Rows > 0
+}
+
+func TestPivot_Alternatives(t *testing.T) {
+	pivot := func(rowKey ...string) *universe.PivotProcedureSpec {
+		return &universe.PivotProcedureSpec{
+			RowKey:      rowKey,
+			ColumnKey:   []string{"_field"},
+			ValueColumn: "_value",
+		}
+	}
+	sortedPivot := &universe.SortedPivotProcedureSpec{
+		RowKey:      []string{"_time"},
+		ColumnKey:   []string{"_field"},
+		ValueColumn: "_value",
+	}
+	group := func(mode flux.GroupMode, keys ...string) *universe.GroupProcedureSpec {
+		return &universe.GroupProcedureSpec{GroupMode: mode, GroupKeys: keys}
+	}
+	sortBy := func(desc bool, cols ...string) *universe.SortProcedureSpec {
+		return &universe.SortProcedureSpec{Columns: cols, Desc: desc}
+	}
+	// pivotPlan creates a pivot that follows a group and a sort.
+	pivotPlan := func(source *pivotSource, g *universe.GroupProcedureSpec, s *universe.SortProcedureSpec, p plan.PhysicalProcedureSpec) *plantest.PlanSpec {
+		return &plantest.PlanSpec{
+			Nodes: []plan.Node{
+				plan.CreatePhysicalNode("from", source),
+				plan.CreatePhysicalNode("group", g),
+				plan.CreatePhysicalNode("sort", s),
+				plan.CreatePhysicalNode("pivot", p),
+			},
+			Edges: [][2]int{{0, 1}, {1, 2}, {2, 3}},
+		}
+	}
+	stats := &pivotSource{Rows: 1000, Tables: 10}
+
+	tests := []plantest.RuleTestCase{
+		{
+			Name:   "sorted input",
+			Before: pivotPlan(stats, group(flux.GroupModeBy, "_field"), sortBy(false, "_time"), pivot("_time")),
+			After:  pivotPlan(stats, group(flux.GroupModeBy, "_field"), sortBy(false, "_time"), sortedPivot),
+		},
+		{
+			Name:   "sorted by more columns",
+			Before: pivotPlan(stats, group(flux.GroupModeBy, "host", "_field"), sortBy(false, "_time", "host"), pivot("_time")),
+			After:  pivotPlan(stats, group(flux.GroupModeBy, "host", "_field"), sortBy(false, "_time", "host"), sortedPivot),
+		},
+		{
+			Name:   "grouped by except",
+			Before: pivotPlan(stats, group(flux.GroupModeExcept, "_time", "_value"), sortBy(false, "_time"), pivot("_time")),
+			After:  pivotPlan(stats, group(flux.GroupModeExcept, "_time", "_value"), sortBy(false, "_time"), sortedPivot),
+		},
+		{
+			Name:     "sorted by another column",
+			Before:   pivotPlan(stats, group(flux.GroupModeBy, "_field"), sortBy(false, "host"), pivot("_time")),
+			NoChange: true,
+		},
+		{
+			Name:     "sorted descending",
+			Before:   pivotPlan(stats, group(flux.GroupModeBy, "_field"), sortBy(true, "_time"), pivot("_time")),
+			NoChange: true,
+		},
+		{
+			Name:     "column key not in group key",
+			Before:   pivotPlan(stats, group(flux.GroupModeBy, "host"), sortBy(false, "_time"), pivot("_time")),
+			NoChange: true,
+		},
+		{
+			Name:     "multiple row keys",
+			Before:   pivotPlan(stats, group(flux.GroupModeBy, "_field"), sortBy(false, "_time", "host"), pivot("_time", "host")),
+			NoChange: true,
+		},
+		{
+			Name:     "unknown statistics",
+			Before:   pivotPlan(&pivotSource{}, group(flux.GroupModeBy, "_field"), sortBy(false, "_time"), pivot("_time")),
+			NoChange: true,
+		},
+		{
+			Name: "unsorted input",
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("from", stats),
+					plan.CreatePhysicalNode("group", group(flux.GroupModeBy, "_field")),
+					plan.CreatePhysicalNode("pivot", pivot("_time")),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+			NoChange: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		tc.Rules = []plan.Rule{universe.PivotSortedInputRule{}}
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc,
+				cmpopts.IgnoreFields(universe.PivotProcedureSpec{}, "IsSortedByFunc", "IsKeyColumnFunc"))
+		})
+	}
+}
+
+func TestPivot_StringMemoryLimit(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "within limit", limit: 1 << 20},
+		{name: "exceeds limit", limit: 16 << 10, wantErr: true},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			alloc := &memory.Allocator{Limit: &tc.limit}
+			d := executetest.NewDataset(executetest.RandomDatasetID())
+			c := execute.NewTableBuilderCache(alloc)
+			c.SetTriggerSpec(plan.DefaultTriggerSpec)
+			tx := universe.NewPivotTransformation(d, c, &universe.PivotProcedureSpec{
+				RowKey:      []string{"_time"},
+				ColumnKey:   []string{"_field"},
+				ValueColumn: "_value",
+			})
+
+			err := processWithMemoryLimit(tx, wideStringTables(64, 1024))
+			checkMemoryLimitErr(t, err, tc.wantErr)
+			if got := alloc.MaxAllocated(); got > tc.limit {
+				t.Errorf("allocated memory exceeded the limit: %d > %d", got, tc.limit)
+			}
+		})
+	}
+}
+
 func TestSortedPivot_ProcessWithTags(t *testing.T) {
 	testCases := []struct {
 		name string
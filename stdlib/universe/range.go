@@ -74,6 +74,11 @@ type RangeProcedureSpec struct {
 	StopColumn  string
 }
 
+// ReferencedColumns implements plan.ColumnReferencer.
+func (s *RangeProcedureSpec) ReferencedColumns() ([]string, bool) {
+	return []string{s.TimeColumn, s.StartColumn, s.StopColumn}, true
+}
+
 // TimeBounds implements plan.BoundsAwareProcedureSpec
 func (s *RangeProcedureSpec) TimeBounds(predecessorBounds *plan.Bounds) *plan.Bounds {
 	bounds := &plan.Bounds{
@@ -2,6 +2,7 @@ package universe
 
 import (
 	"context"
+	"sort"
 
 	"github.com/influxdata/flux"
 	"github.com/influxdata/flux/array"
@@ -110,6 +111,7 @@ func init() {
 
 	plan.RegisterProcedureSpec(SchemaMutationKind, newSchemaMutationProcedure, SchemaMutationOps...)
 	execute.RegisterTransformation(SchemaMutationKind, createSchemaMutationTransformation)
+	plan.RegisterPhysicalRules(PushDownProjectionRule{})
 }
 
 func createRenameOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
@@ -532,3 +534,88 @@ func (m *mutateTable) Do(f func(flux.ColReader) error) error {
 		return f(buffer)
 	})
 }
+
+// PushDownProjectionRule pushes the columns used by keep into a source
+// that implements plan.ProjectionPushDownProcedureSpec.
+// The nodes between the source and keep must implement plan.ColumnReferencer
+// and the columns they read are also pushed down.
+// The keep is not removed because the source may produce
+// more than one table with the same group key.
+type PushDownProjectionRule struct{}
+
+func (PushDownProjectionRule) Name() string {
+	return "PushDownProjectionRule"
+}
+
+func (PushDownProjectionRule) Pattern() plan.Pattern {
+	return plan.Pat(SchemaMutationKind, plan.Any())
+}
+
+func (PushDownProjectionRule) Rewrite(ctx context.Context, node plan.Node) (plan.Node, bool, error) {
+	spec := node.ProcedureSpec().(*SchemaMutationProcedureSpec)
+	if len(spec.Mutations) == 0 {
+		return node, false, nil
+	}
+	keep, ok := spec.Mutations[0].(*KeepOpSpec)
+	if !ok || keep.Columns == nil {
+		return node, false, nil
+	}
+
+	used := make(map[string]bool, len(keep.Columns))
+	for _, label := range keep.Columns {
+		used[label] = true
+	}
+
+	// Walk up to the source and collect the columns
+	// read by the nodes in between.
+	srcNode := node.Predecessors()[0]
+	var src plan.ProjectionPushDownProcedureSpec
+	for {
+		if len(srcNode.Successors()) != 1 {
+			return node, false, nil
+		}
+		if s, ok := srcNode.ProcedureSpec().(plan.ProjectionPushDownProcedureSpec); ok {
+			src = s
+			break
+		}
+		cr, ok := srcNode.ProcedureSpec().(plan.ColumnReferencer)
+		if !ok || len(srcNode.Predecessors()) != 1 {
+			return node, false, nil
+		}
+		labels, ok := cr.ReferencedColumns()
+		if !ok {
+			return node, false, nil
+		}
+		for _, label := range labels {
+			used[label] = true
+		}
+		srcNode = srcNode.Predecessors()[0]
+	}
+
+	// A source that is already projected can only
+	// drop more columns.
+	if projected := src.ProjectedColumns(); projected != nil {
+		prev := make(map[string]bool, len(projected))
+		for _, label := range projected {
+			prev[label] = true
+		}
+		for label := range used {
+			if !prev[label] {
+				delete(used, label)
+			}
+		}
+		if len(used) == len(prev) {
+			return node, false, nil
+		}
+	}
+
+	columns := make([]string, 0, len(used))
+	for label := range used {
+		columns = append(columns, label)
+	}
+	sort.Strings(columns)
+	if err := srcNode.ReplaceSpec(src.PushDownProjection(columns)); err != nil {
+		return nil, false, err
+	}
+	return node, true, nil
+}
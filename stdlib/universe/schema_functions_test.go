@@ -12,6 +12,7 @@ import (
 	"github.com/influxdata/flux/interpreter"
 	"github.com/influxdata/flux/memory"
 	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/plan/plantest"
 	"github.com/influxdata/flux/querytest"
 	"github.com/influxdata/flux/stdlib/influxdata/influxdb"
 	"github.com/influxdata/flux/stdlib/universe"
@@ -1616,3 +1617,166 @@ func benchmarkSchemaMutator(b *testing.B, n int, m universe.SchemaMutation) {
 		},
 	)
 }
+
+// projectionSource is a source that can skip columns.
+type projectionSource struct {
+	plan.DefaultCost
+	Columns []string
+}
+
+func (s *projectionSource) Kind() plan.ProcedureKind {
+	return "projectionSource"
+}
+
+func (s *projectionSource) Copy() plan.ProcedureSpec {
+	ns := *s
+	return &ns
+}
+
+func (s *projectionSource) ProjectedColumns() []string {
+	return s.Columns
+}
+
+func (s *projectionSource) PushDownProjection(columns []string) plan.PhysicalProcedureSpec {
+	return &projectionSource{Columns: columns}
+}
+
+func TestPushDownProjectionRule(t *testing.T) {
+	keep := func(columns ...string) *universe.SchemaMutationProcedureSpec {
+		return &universe.SchemaMutationProcedureSpec{
+			Mutations: []universe.SchemaMutation{
+				&universe.KeepOpSpec{Columns: columns},
+			},
+		}
+	}
+	filter := func(fn string) *universe.FilterProcedureSpec {
+		return &universe.FilterProcedureSpec{
+			Fn: interpreter.ResolvedFunction{
+				Fn: executetest.FunctionExpression(t, fn),
+			},
+		}
+	}
+	rangeSpec := &universe.RangeProcedureSpec{
+		TimeColumn:  "_time",
+		StartColumn: "_start",
+		StopColumn:  "_stop",
+	}
+
+	tests := []plantest.RuleTestCase{
+		{
+			Name:  "keep",
+			Rules: []plan.Rule{universe.PushDownProjectionRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &projectionSource{}),
+					plan.CreatePhysicalNode("keep", keep("_value", "_time")),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &projectionSource{
+						Columns: []string{"_time", "_value"},
+					}),
+					plan.CreatePhysicalNode("keep", keep("_value", "_time")),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+		},
+		{
+			Name:  "range and filter",
+			Rules: []plan.Rule{universe.PushDownProjectionRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &projectionSource{}),
+					plan.CreatePhysicalNode("range", rangeSpec),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.host == "a"`)),
+					plan.CreatePhysicalNode("keep", keep("_value")),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}, {2, 3}},
+			},
+			After: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &projectionSource{
+						Columns: []string{"_start", "_stop", "_time", "_value", "host"},
+					}),
+					plan.CreatePhysicalNode("range", rangeSpec),
+					plan.CreatePhysicalNode("filter", filter(`(r) => r.host == "a"`)),
+					plan.CreatePhysicalNode("keep", keep("_value")),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}, {2, 3}},
+			},
+		},
+		{
+			Name:  "already projected",
+			Rules: []plan.Rule{universe.PushDownProjectionRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &projectionSource{
+						Columns: []string{"_value"},
+					}),
+					plan.CreatePhysicalNode("keep", keep("_value", "_time")),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "filter uses record",
+			Rules: []plan.Rule{universe.PushDownProjectionRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &projectionSource{}),
+					plan.CreatePhysicalNode("filter", filter(`(r) => {
+	s = r
+	return s._value > 0.0
+}`)),
+					plan.CreatePhysicalNode("keep", keep("_value")),
+				},
+				Edges: [][2]int{{0, 1}, {1, 2}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "keep with predicate",
+			Rules: []plan.Rule{universe.PushDownProjectionRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &projectionSource{}),
+					plan.CreatePhysicalNode("keep", &universe.SchemaMutationProcedureSpec{
+						Mutations: []universe.SchemaMutation{
+							&universe.KeepOpSpec{
+								Predicate: interpreter.ResolvedFunction{
+									Fn: executetest.FunctionExpression(t, `(column) => column == "_value"`),
+								},
+							},
+						},
+					}),
+				},
+				Edges: [][2]int{{0, 1}},
+			},
+			NoChange: true,
+		},
+		{
+			Name:  "source with multiple successors",
+			Rules: []plan.Rule{universe.PushDownProjectionRule{}},
+			Before: &plantest.PlanSpec{
+				Nodes: []plan.Node{
+					plan.CreatePhysicalNode("source", &projectionSource{}),
+					plan.CreatePhysicalNode("keep", keep("_value")),
+					plan.CreatePhysicalNode("count", &universe.CountProcedureSpec{}),
+				},
+				Edges: [][2]int{{0, 1}, {0, 2}},
+			},
+			NoChange: true,
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.Name, func(t *testing.T) {
+			t.Parallel()
+			plantest.PhysicalRuleTestHelper(t, &tc)
+		})
+	}
+}
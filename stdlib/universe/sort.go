@@ -142,7 +142,7 @@ func (t *sortTransformation) Process(id execute.DatasetID, tbl flux.Table) error
 	key := tbl.Key()
 	for _, label := range t.cols {
 		if key.HasCol(label) {
-			key = t.sortedKey(key)
+			key = sortedGroupKey(key, t.cols)
 			break
 		}
 	}
@@ -172,11 +172,13 @@ func (t *sortTransformation) Finish(id execute.DatasetID, err error) {
 	t.d.Finish(err)
 }
 
-func (t *sortTransformation) sortedKey(key flux.GroupKey) flux.GroupKey {
+// sortedGroupKey moves the columns of the group key that are
+// sorted on to its front, in the order of the sort columns.
+func sortedGroupKey(key flux.GroupKey, sortCols []string) flux.GroupKey {
 	cols := make([]flux.ColMeta, len(key.Cols()))
 	vs := make([]values.Value, len(key.Cols()))
 	j := 0
-	for _, label := range t.cols {
+	for _, label := range sortCols {
 		idx := execute.ColIdx(label, key.Cols())
 		if idx >= 0 {
 			cols[j] = key.Cols()[idx]
@@ -185,7 +187,7 @@ func (t *sortTransformation) sortedKey(key flux.GroupKey) flux.GroupKey {
 		}
 	}
 	for idx, c := range key.Cols() {
-		if !execute.ContainsStr(t.cols, c.Label) {
+		if !execute.ContainsStr(sortCols, c.Label) {
 			cols[j] = c
 			vs[j] = key.Value(idx)
 			j++
@@ -0,0 +1,402 @@
+package universe
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+const TopNKind = "_topN"
+
+const (
+	// TiesDrop keeps exactly n records, the earlier records of the
+	// input table win the ties with the nth record.
+	TiesDrop = "drop"
+	// TiesKeep keeps every record that ties with the nth record.
+	TiesKeep = "keep"
+)
+
+type TopNOpSpec struct {
+	N       int64    `json:"n"`
+	Columns []string `json:"columns"`
+	Desc    bool     `json:"desc"`
+	Ties    string   `json:"ties"`
+}
+
+func init() {
+	topNSignature := runtime.MustLookupBuiltinType("universe", TopNKind)
+
+	runtime.RegisterPackageValue("universe", TopNKind, flux.MustValue(flux.FunctionValue(TopNKind, createTopNOpSpec, topNSignature)))
+	flux.RegisterOpSpec(TopNKind, newTopNOp)
+	plan.RegisterProcedureSpec(TopNKind, newTopNProcedure, TopNKind)
+	execute.RegisterTransformation(TopNKind, createTopNTransformation)
+}
+
+func createTopNOpSpec(args flux.Arguments, a *flux.Administration) (flux.OperationSpec, error) {
+	if err := a.AddParentFromArgs(args); err != nil {
+		return nil, err
+	}
+
+	spec := &TopNOpSpec{
+		Columns: []string{execute.DefaultValueColLabel},
+		Ties:    TiesDrop,
+	}
+
+	n, err := args.GetRequiredInt("n")
+	if err != nil {
+		return nil, err
+	} else if n < 0 {
+		return nil, errors.Newf(codes.Invalid, "n must not be negative, got %d", n)
+	}
+	spec.N = n
+
+	if spec.Desc, err = args.GetRequiredBool("desc"); err != nil {
+		return nil, err
+	}
+
+	if array, ok, err := args.GetArray("columns", semantic.String); err != nil {
+		return nil, err
+	} else if ok {
+		spec.Columns, err = interpreter.ToStringArray(array)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if ties, ok, err := args.GetString("ties"); err != nil {
+		return nil, err
+	} else if ok {
+		if ties != TiesDrop && ties != TiesKeep {
+			return nil, errors.Newf(codes.Invalid, "ties must be %q or %q, got %q", TiesDrop, TiesKeep, ties)
+		}
+		spec.Ties = ties
+	}
+	return spec, nil
+}
+
+func newTopNOp() flux.OperationSpec {
+	return new(TopNOpSpec)
+}
+
+func (s *TopNOpSpec) Kind() flux.OperationKind {
+	return TopNKind
+}
+
+type TopNProcedureSpec struct {
+	plan.DefaultCost
+	N       int64
+	Columns []string
+	Desc    bool
+	Ties    string
+}
+
+func newTopNProcedure(qs flux.OperationSpec, pa plan.Administration) (plan.ProcedureSpec, error) {
+	spec, ok := qs.(*TopNOpSpec)
+	if !ok {
+		return nil, errors.Newf(codes.Internal, "invalid spec type %T", qs)
+	}
+	return &TopNProcedureSpec{
+		N:       spec.N,
+		Columns: spec.Columns,
+		Desc:    spec.Desc,
+		Ties:    spec.Ties,
+	}, nil
+}
+
+func (s *TopNProcedureSpec) Kind() plan.ProcedureKind {
+	return TopNKind
+}
+
+func (s *TopNProcedureSpec) Copy() plan.ProcedureSpec {
+	ns := *s
+	ns.Columns = make([]string, len(s.Columns))
+	copy(ns.Columns, s.Columns)
+	return &ns
+}
+
+// TriggerSpec implements plan.TriggerAwareProcedureSpec
+func (s *TopNProcedureSpec) TriggerSpec() plan.TriggerSpec {
+	return plan.NarrowTransformationTriggerSpec{}
+}
+
+func createTopNTransformation(id execute.DatasetID, mode execute.AccumulationMode, spec plan.ProcedureSpec, a execute.Administration) (execute.Transformation, execute.Dataset, error) {
+	s, ok := spec.(*TopNProcedureSpec)
+	if !ok {
+		return nil, nil, errors.Newf(codes.Internal, "invalid spec type %T", spec)
+	}
+	cache := execute.NewTableBuilderCache(a.Allocator())
+	d := execute.NewDataset(id, mode, cache)
+	t := NewTopNTransformation(d, cache, s)
+	return t, d, nil
+}
+
+// topNTransformation keeps the first n records of each table in the
+// order of the columns. Unlike sort followed by limit, it only holds
+// the n best records seen so far, in a heap whose root is the worst of them.
+type topNTransformation struct {
+	execute.ExecutionNode
+	d     execute.Dataset
+	cache execute.TableBuilderCache
+
+	n    int
+	cols []string
+	desc bool
+	ties string
+}
+
+func NewTopNTransformation(d execute.Dataset, cache execute.TableBuilderCache, spec *TopNProcedureSpec) *topNTransformation {
+	return &topNTransformation{
+		d:     d,
+		cache: cache,
+		n:     int(spec.N),
+		cols:  spec.Columns,
+		desc:  spec.Desc,
+		ties:  spec.Ties,
+	}
+}
+
+func (t *topNTransformation) RetractTable(id execute.DatasetID, key flux.GroupKey) error {
+	return t.d.RetractTable(key)
+}
+
+func (t *topNTransformation) Process(id execute.DatasetID, tbl flux.Table) error {
+	// Like sort, move the group key columns that are sorted on to the
+	// front of the key.
+	key := tbl.Key()
+	for _, label := range t.cols {
+		if key.HasCol(label) {
+			key = sortedGroupKey(key, t.cols)
+			break
+		}
+	}
+	builder, created := t.cache.TableBuilder(key)
+	if !created {
+		return errors.Newf(codes.FailedPrecondition, "found duplicate table with key: %v", tbl.Key())
+	}
+	if err := execute.AddTableCols(tbl, builder); err != nil {
+		return err
+	}
+
+	// Like sort, ignore the columns that are missing
+	// and those of the group key, which are constant.
+	sortCols := make([]int, 0, len(t.cols))
+	for _, label := range t.cols {
+		if idx := execute.ColIdx(label, tbl.Cols()); idx >= 0 && !tbl.Key().HasCol(label) {
+			sortCols = append(sortCols, idx)
+		}
+	}
+	sel := &topNSelector{
+		h:    &topNHeap{desc: t.desc},
+		n:    t.n,
+		keep: t.ties == TiesKeep,
+	}
+
+	var index int
+	if err := tbl.Do(func(cr flux.ColReader) error {
+		for i, l := 0, cr.Len(); i < l; i++ {
+			r := &topNRecord{
+				index: index,
+				key:   make([]values.Value, len(sortCols)),
+			}
+			index++
+			for k, j := range sortCols {
+				r.key[k] = execute.ValueForRow(cr, i, j)
+			}
+			// Only the records that are kept, for now, are read whole.
+			if sel.offer(r) {
+				r.values = make([]values.Value, len(cr.Cols()))
+				for j := range r.values {
+					r.values[j] = execute.ValueForRow(cr, i, j)
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	records := sel.records()
+	for _, r := range records {
+		for j, v := range r.values {
+			if err := builder.AppendValue(j, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *topNTransformation) UpdateWatermark(id execute.DatasetID, mark execute.Time) error {
+	return t.d.UpdateWatermark(mark)
+}
+
+func (t *topNTransformation) UpdateProcessingTime(id execute.DatasetID, pt execute.Time) error {
+	return t.d.UpdateProcessingTime(pt)
+}
+
+func (t *topNTransformation) Finish(id execute.DatasetID, err error) {
+	t.d.Finish(err)
+}
+
+// topNRecord is a record kept by topN, with the values
+// of its sort columns and its index in the input table.
+type topNRecord struct {
+	index  int
+	key    []values.Value
+	values []values.Value
+}
+
+// topNSelector selects the first n records it is offered in the output order.
+type topNSelector struct {
+	h    *topNHeap
+	n    int
+	keep bool
+	// ties holds the records that are equal to the root of a full heap.
+	ties []*topNRecord
+}
+
+// offer offers a record to the selector and reports whether it is kept.
+// A kept record may be evicted by the records that are offered later.
+func (s *topNSelector) offer(r *topNRecord) bool {
+	if s.h.Len() < s.n {
+		heap.Push(s.h, r)
+		return true
+	} else if s.n == 0 {
+		return false
+	}
+	switch c := s.h.compare(r, s.h.records[0]); {
+	case c > 0:
+		// Worse than all of the kept records.
+		return false
+	case c == 0 && s.keep:
+		s.ties = append(s.ties, r)
+		return true
+	case c == 0:
+		// The earlier record wins the tie.
+		return false
+	}
+	root := s.h.records[0]
+	s.h.records[0] = r
+	heap.Fix(s.h, 0)
+	if s.keep {
+		if s.h.compare(root, s.h.records[0]) == 0 {
+			s.ties = append(s.ties, root)
+		} else {
+			s.ties = s.ties[:0]
+		}
+	}
+	return true
+}
+
+// records returns the selected records in the output order.
+func (s *topNSelector) records() []*topNRecord {
+	records := append(s.h.records, s.ties...)
+	sort.Slice(records, func(i, j int) bool {
+		if c := s.h.compare(records[i], records[j]); c != 0 {
+			return c < 0
+		}
+		return records[i].index < records[j].index
+	})
+	return records
+}
+
+// topNHeap is a heap of records whose root is the last
+// record in the output order, so that it is the first
+// to be replaced by a better record.
+type topNHeap struct {
+	records []*topNRecord
+	desc    bool
+}
+
+// compare returns a negative number if x comes before y in the
+// output order and a positive number if it comes after it.
+// Like sort, null values come first in both orders.
+func (h *topNHeap) compare(x, y *topNRecord) int {
+	for k := range x.key {
+		if c := compareValues(x.key[k], y.key[k]); c != 0 {
+			if h.desc && !x.key[k].IsNull() && !y.key[k].IsNull() {
+				return -c
+			}
+			return c
+		}
+	}
+	return 0
+}
+
+func (h *topNHeap) Len() int {
+	return len(h.records)
+}
+
+func (h *topNHeap) Less(i, j int) bool {
+	if c := h.compare(h.records[i], h.records[j]); c != 0 {
+		return c > 0
+	}
+	return h.records[i].index > h.records[j].index
+}
+
+func (h *topNHeap) Swap(i, j int) {
+	h.records[i], h.records[j] = h.records[j], h.records[i]
+}
+
+func (h *topNHeap) Push(x interface{}) {
+	h.records = append(h.records, x.(*topNRecord))
+}
+
+func (h *topNHeap) Pop() interface{} {
+	n := len(h.records)
+	r := h.records[n-1]
+	h.records = h.records[:n-1]
+	return r
+}
+
+// compareValues compares two values of the same column type.
+// A null value is less than every non-null value.
+func compareValues(x, y values.Value) int {
+	if x.IsNull() || y.IsNull() {
+		switch {
+		case x.IsNull() && y.IsNull():
+			return 0
+		case x.IsNull():
+			return -1
+		default:
+			return 1
+		}
+	}
+	switch x.Type().Nature() {
+	case semantic.Int:
+		return compareOrdered(x.Int() < y.Int(), x.Int() == y.Int())
+	case semantic.UInt:
+		return compareOrdered(x.UInt() < y.UInt(), x.UInt() == y.UInt())
+	case semantic.Float:
+		return compareOrdered(x.Float() < y.Float(), x.Float() == y.Float())
+	case semantic.Bool:
+		return compareOrdered(!x.Bool() && y.Bool(), x.Bool() == y.Bool())
+	case semantic.String:
+		return strings.Compare(x.Str(), y.Str())
+	case semantic.Time:
+		return compareOrdered(x.Time() < y.Time(), x.Time() == y.Time())
+	default:
+		return 0
+	}
+}
+
+func compareOrdered(less, equal bool) int {
+	switch {
+	case less:
+		return -1
+	case equal:
+		return 0
+	default:
+		return 1
+	}
+}
@@ -0,0 +1,270 @@
+package universe_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/execute"
+	"github.com/influxdata/flux/execute/executetest"
+	"github.com/influxdata/flux/plan"
+	"github.com/influxdata/flux/querytest"
+	"github.com/influxdata/flux/stdlib/universe"
+)
+
+func TestTopNOperation_Marshaling(t *testing.T) {
+	data := []byte(`{"id":"_topN","kind":"_topN","spec":{"n":3,"columns":["_value"],"desc":true,"ties":"keep"}}`)
+	op := &flux.Operation{
+		ID: "_topN",
+		Spec: &universe.TopNOpSpec{
+			N:       3,
+			Columns: []string{"_value"},
+			Desc:    true,
+			Ties:    universe.TiesKeep,
+		},
+	}
+	querytest.OperationMarshalingTestHelper(t, data, op)
+}
+
+func TestTopN_Process(t *testing.T) {
+	colMeta := []flux.ColMeta{
+		{Label: "_time", Type: flux.TTime},
+		{Label: "_value", Type: flux.TFloat},
+		{Label: "host", Type: flux.TString},
+	}
+	data := func() []flux.Table {
+		return []flux.Table{
+			&executetest.Table{
+				KeyCols: []string{"host"},
+				ColMeta: colMeta,
+				Data: [][]interface{}{
+					{execute.Time(1), 2.0, "a"},
+					{execute.Time(2), 5.0, "a"},
+					{execute.Time(3), nil, "a"},
+					{execute.Time(4), 3.0, "a"},
+					{execute.Time(5), 5.0, "a"},
+					{execute.Time(6), 3.0, "a"},
+					{execute.Time(7), 1.0, "a"},
+				},
+			},
+			&executetest.Table{
+				KeyCols: []string{"host"},
+				ColMeta: colMeta,
+				Data: [][]interface{}{
+					{execute.Time(1), 7.0, "b"},
+				},
+			},
+		}
+	}
+	testCases := []struct {
+		name string
+		spec *universe.TopNProcedureSpec
+		want []*executetest.Table
+	}{
+		{
+			name: "top drops ties",
+			spec: &universe.TopNProcedureSpec{
+				N:       4,
+				Columns: []string{"_value"},
+				Desc:    true,
+				Ties:    universe.TiesDrop,
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: colMeta,
+					Data: [][]interface{}{
+						{execute.Time(3), nil, "a"},
+						{execute.Time(2), 5.0, "a"},
+						{execute.Time(5), 5.0, "a"},
+						{execute.Time(4), 3.0, "a"},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: colMeta,
+					Data: [][]interface{}{
+						{execute.Time(1), 7.0, "b"},
+					},
+				},
+			},
+		},
+		{
+			name: "top keeps ties",
+			spec: &universe.TopNProcedureSpec{
+				N:       4,
+				Columns: []string{"_value"},
+				Desc:    true,
+				Ties:    universe.TiesKeep,
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: colMeta,
+					Data: [][]interface{}{
+						{execute.Time(3), nil, "a"},
+						{execute.Time(2), 5.0, "a"},
+						{execute.Time(5), 5.0, "a"},
+						{execute.Time(4), 3.0, "a"},
+						{execute.Time(6), 3.0, "a"},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: colMeta,
+					Data: [][]interface{}{
+						{execute.Time(1), 7.0, "b"},
+					},
+				},
+			},
+		},
+		{
+			name: "bottom with null",
+			spec: &universe.TopNProcedureSpec{
+				N:       2,
+				Columns: []string{"_value"},
+				Desc:    false,
+				Ties:    universe.TiesKeep,
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: colMeta,
+					Data: [][]interface{}{
+						{execute.Time(3), nil, "a"},
+						{execute.Time(7), 1.0, "a"},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: colMeta,
+					Data: [][]interface{}{
+						{execute.Time(1), 7.0, "b"},
+					},
+				},
+			},
+		},
+		{
+			name: "multiple columns",
+			spec: &universe.TopNProcedureSpec{
+				N:       3,
+				Columns: []string{"_value", "_time"},
+				Desc:    true,
+				Ties:    universe.TiesKeep,
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols: []string{"host"},
+					ColMeta: colMeta,
+					Data: [][]interface{}{
+						{execute.Time(3), nil, "a"},
+						{execute.Time(5), 5.0, "a"},
+						{execute.Time(2), 5.0, "a"},
+					},
+				},
+				{
+					KeyCols: []string{"host"},
+					ColMeta: colMeta,
+					Data: [][]interface{}{
+						{execute.Time(1), 7.0, "b"},
+					},
+				},
+			},
+		},
+		{
+			name: "zero",
+			spec: &universe.TopNProcedureSpec{
+				N:       0,
+				Columns: []string{"_value"},
+				Desc:    true,
+				Ties:    universe.TiesKeep,
+			},
+			want: []*executetest.Table{
+				{
+					KeyCols:   []string{"host"},
+					KeyValues: []interface{}{"a"},
+					ColMeta:   colMeta,
+				},
+				{
+					KeyCols:   []string{"host"},
+					KeyValues: []interface{}{"b"},
+					ColMeta:   colMeta,
+				},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			executetest.ProcessTestHelper(
+				t,
+				data(),
+				tc.want,
+				nil,
+				func(d execute.Dataset, c execute.TableBuilderCache) execute.Transformation {
+					return universe.NewTopNTransformation(d, c, tc.spec)
+				},
+			)
+		})
+	}
+}
+
+func TestTopN_GroupKeyOrder(t *testing.T) {
+	// Like sort, the group key columns that are sorted
+	// on are moved to the front of the key.
+	data := func() flux.Table {
+		return &executetest.Table{
+			KeyCols: []string{"host", "region"},
+			ColMeta: []flux.ColMeta{
+				{Label: "_value", Type: flux.TFloat},
+				{Label: "host", Type: flux.TString},
+				{Label: "region", Type: flux.TString},
+			},
+			Data: [][]interface{}{
+				{1.0, "a", "west"},
+				{2.0, "a", "west"},
+			},
+		}
+	}
+	for _, tt := range []struct {
+		name    string
+		columns []string
+		want    []string
+	}{
+		{
+			name:    "value",
+			columns: []string{"_value"},
+			want:    []string{"host", "region"},
+		},
+		{
+			name:    "key column",
+			columns: []string{"region", "_value"},
+			want:    []string{"region", "host"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			d := executetest.NewDataset(executetest.RandomDatasetID())
+			c := execute.NewTableBuilderCache(executetest.UnlimitedAllocator)
+			c.SetTriggerSpec(plan.DefaultTriggerSpec)
+			tx := universe.NewTopNTransformation(d, c, &universe.TopNProcedureSpec{
+				N:       1,
+				Columns: tt.columns,
+				Desc:    true,
+				Ties:    universe.TiesDrop,
+			})
+			if err := tx.Process(executetest.RandomDatasetID(), data()); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			c.ForEach(func(key flux.GroupKey) {
+				for _, col := range key.Cols() {
+					got = append(got, col.Label)
+				}
+			})
+			if !cmp.Equal(tt.want, got) {
+				t.Errorf("unexpected group key columns -want/+got:\n%s", cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
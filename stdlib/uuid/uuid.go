@@ -0,0 +1,51 @@
+package uuid
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/internal/function"
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/values"
+)
+
+const pkgpath = "uuid"
+
+func init() {
+	b := function.ForPackage(pkgpath)
+	b.Register("v4", V4)
+	b.Register("parse", Parse)
+	b.Register("validate", Validate)
+}
+
+// V4 generates a random UUID.
+func V4(args interpreter.Arguments) (values.Value, error) {
+	u, err := uuid.NewV4()
+	if err != nil {
+		return nil, errors.Wrap(err, codes.Internal, "failed to generate UUID")
+	}
+	return values.NewString(u.String()), nil
+}
+
+// Parse returns the canonical form of a UUID.
+func Parse(args interpreter.Arguments) (values.Value, error) {
+	v, err := args.GetRequiredString("v")
+	if err != nil {
+		return nil, err
+	}
+	u, err := uuid.FromString(v)
+	if err != nil {
+		return nil, errors.Newf(codes.Invalid, "invalid UUID %q", v)
+	}
+	return values.NewString(u.String()), nil
+}
+
+// Validate reports whether a string is a UUID.
+func Validate(args interpreter.Arguments) (values.Value, error) {
+	v, err := args.GetRequiredString("v")
+	if err != nil {
+		return nil, err
+	}
+	_, err = uuid.FromString(v)
+	return values.NewBool(err == nil), nil
+}
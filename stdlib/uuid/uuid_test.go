@@ -0,0 +1,72 @@
+package uuid_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/influxdata/flux/interpreter"
+	"github.com/influxdata/flux/stdlib/uuid"
+	"github.com/influxdata/flux/values"
+)
+
+func args(v string) interpreter.Arguments {
+	return interpreter.NewArguments(values.NewObjectWithValues(map[string]values.Value{
+		"v": values.NewString(v),
+	}))
+}
+
+func TestV4(t *testing.T) {
+	pattern := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		v, err := uuid.V4(interpreter.NewArguments(values.NewObjectWithValues(nil)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pattern.MatchString(v.Str()) {
+			t.Fatalf("%q is not a version 4 UUID", v.Str())
+		}
+		if seen[v.Str()] {
+			t.Fatalf("duplicate UUID %q", v.Str())
+		}
+		seen[v.Str()] = true
+	}
+}
+
+func TestParse(t *testing.T) {
+	const want = "4a0bd0e8-7fd9-4d5a-9ce1-5e3e19bc1c42"
+	for _, v := range []string{
+		want,
+		"4A0BD0E8-7FD9-4D5A-9CE1-5E3E19BC1C42",
+		"4a0bd0e87fd94d5a9ce15e3e19bc1c42",
+		"{4a0bd0e8-7fd9-4d5a-9ce1-5e3e19bc1c42}",
+		"urn:uuid:4a0bd0e8-7fd9-4d5a-9ce1-5e3e19bc1c42",
+	} {
+		got, err := uuid.Parse(args(v))
+		if err != nil {
+			t.Errorf("%s: %v", v, err)
+			continue
+		}
+		if got.Str() != want {
+			t.Errorf("%s: unexpected UUID -want/+got:\n\t- %s\n\t+ %s", v, want, got.Str())
+		}
+		if ok, _ := uuid.Validate(args(v)); !ok.Bool() {
+			t.Errorf("%s: expected a valid UUID", v)
+		}
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, v := range []string{
+		"",
+		"4a0bd0e8",
+		"4a0bd0e8-7fd9-4d5a-9ce1-5e3e19bc1c4g",
+	} {
+		if _, err := uuid.Parse(args(v)); err == nil {
+			t.Errorf("%q: expected an error", v)
+		}
+		if ok, _ := uuid.Validate(args(v)); ok.Bool() {
+			t.Errorf("%q: expected an invalid UUID", v)
+		}
+	}
+}
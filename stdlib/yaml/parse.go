@@ -0,0 +1,170 @@
+package yaml
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/runtime"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	runtime.RegisterPackageValue("yaml", "parse", values.NewFunction(
+		"parse",
+		runtime.MustLookupBuiltinType("yaml", "parse"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			data, err := getData(args)
+			if err != nil {
+				return nil, err
+			}
+			docs, err := parse(data)
+			if err != nil {
+				return nil, err
+			}
+			if len(docs) != 1 {
+				return nil, errors.Newf(codes.Invalid, "expected a single YAML document, found %d documents", len(docs))
+			}
+			return docs[0], nil
+		},
+		false,
+	))
+	runtime.RegisterPackageValue("yaml", "parseAll", values.NewFunction(
+		"parseAll",
+		runtime.MustLookupBuiltinType("yaml", "parseAll"),
+		func(ctx context.Context, args values.Object) (values.Value, error) {
+			data, err := getData(args)
+			if err != nil {
+				return nil, err
+			}
+			docs, err := parse(data)
+			if err != nil {
+				return nil, err
+			}
+			return newArray(docs)
+		},
+		false,
+	))
+}
+
+func getData(args values.Object) ([]byte, error) {
+	v, ok := args.Get("data")
+	if !ok {
+		return nil, errors.New(codes.Invalid, "missing parameter \"data\"")
+	}
+	switch n := v.Type().Nature(); n {
+	case semantic.Bytes:
+		return v.Bytes(), nil
+	case semantic.String:
+		return []byte(v.Str()), nil
+	default:
+		return nil, errors.Newf(codes.Invalid, "cannot parse YAML from %v, expected bytes or string", n)
+	}
+}
+
+// parse decodes each YAML document of the data into a Flux value.
+func parse(data []byte) ([]values.Value, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var docs []values.Value
+	for {
+		var i interface{}
+		if err := dec.Decode(&i); err == io.EOF {
+			return docs, nil
+		} else if err != nil {
+			return nil, errors.Wrap(err, codes.Invalid, "failed to parse YAML")
+		}
+		v, err := parseValue(i)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, v)
+	}
+}
+
+// parseValue converts a value produced by yaml.Unmarshal into a Flux value.
+func parseValue(i interface{}) (values.Value, error) {
+	switch t := i.(type) {
+	case nil:
+		return values.Null, nil
+	case string:
+		return values.NewString(t), nil
+	case bool:
+		return values.NewBool(t), nil
+	case int:
+		return values.NewInt(int64(t)), nil
+	case int64:
+		return values.NewInt(t), nil
+	case uint64:
+		if t <= math.MaxInt64 {
+			return values.NewInt(int64(t)), nil
+		}
+		return values.NewUInt(t), nil
+	case float64:
+		return values.NewFloat(t), nil
+	case time.Time:
+		return values.NewTime(values.ConvertTime(t)), nil
+	case []interface{}:
+		vals := make([]values.Value, len(t))
+		for i, e := range t {
+			v, err := parseValue(e)
+			if err != nil {
+				return nil, err
+			}
+			vals[i] = v
+		}
+		return newArray(vals)
+	case map[interface{}]interface{}:
+		vals := make(map[string]values.Value, len(t))
+		for k, e := range t {
+			v, err := parseValue(e)
+			if err != nil {
+				return nil, err
+			}
+			vals[fmt.Sprint(k)] = v
+		}
+		return values.NewObjectWithValues(vals), nil
+	}
+	return nil, errors.Newf(codes.Internal, "unsupported YAML type %T", i)
+}
+
+// newArray creates an array of the values.
+// The values must have the same type, except that
+// the ints of an array that also has floats become floats,
+// and that null values are nulls of the element type.
+func newArray(vals []values.Value) (values.Value, error) {
+	var elemType semantic.MonoType
+	for _, val := range vals {
+		if val.IsNull() {
+			continue
+		}
+		switch {
+		case elemType.Nature() == semantic.Invalid:
+			elemType = val.Type()
+		case val.Type().Equal(elemType):
+		case isNumber(val.Type()) && isNumber(elemType):
+			elemType = semantic.BasicFloat
+		default:
+			return nil, errors.Newf(codes.Invalid, "array values must all be the same type, found %v and %v", elemType, val.Type())
+		}
+	}
+	for i, val := range vals {
+		if val.IsNull() {
+			vals[i] = values.NewNull(elemType)
+		} else if elemType.Nature() == semantic.Float && val.Type().Nature() == semantic.Int {
+			vals[i] = values.NewFloat(float64(val.Int()))
+		}
+	}
+	return values.NewArrayWithBacking(semantic.NewArrayType(elemType), vals), nil
+}
+
+func isNumber(t semantic.MonoType) bool {
+	n := t.Nature()
+	return n == semantic.Int || n == semantic.Float
+}
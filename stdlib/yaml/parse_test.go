@@ -0,0 +1,125 @@
+package yaml
+
+import (
+	"testing"
+
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		want    []values.Value
+		wantErr string
+	}{
+		{
+			name: "mapping",
+			data: `
+name: sensor
+count: 2
+ratio: 0.5
+enabled: true
+since: 2021-01-01
+tags: [a, b]
+limits:
+  low: 1
+  high: 2.5
+`,
+			want: []values.Value{values.NewObjectWithValues(map[string]values.Value{
+				"name":    values.NewString("sensor"),
+				"count":   values.NewInt(2),
+				"ratio":   values.NewFloat(0.5),
+				"enabled": values.NewBool(true),
+				"since":   values.NewString("2021-01-01"),
+				"tags": values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicString), []values.Value{
+					values.NewString("a"),
+					values.NewString("b"),
+				}),
+				"limits": values.NewObjectWithValues(map[string]values.Value{
+					"low":  values.NewInt(1),
+					"high": values.NewFloat(2.5),
+				}),
+			})},
+		},
+		{
+			name:    "large numbers",
+			data:    `[1, 2.5, 18446744073709551615]`,
+			wantErr: "array values must all be the same type, found float and uint",
+		},
+		{
+			name: "mixed numbers",
+			data: `[1, 2.5]`,
+			want: []values.Value{values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicFloat), []values.Value{
+				values.NewFloat(1),
+				values.NewFloat(2.5),
+			})},
+		},
+		{
+			name: "integer keys",
+			data: `{1: a, 2: b}`,
+			want: []values.Value{values.NewObjectWithValues(map[string]values.Value{
+				"1": values.NewString("a"),
+				"2": values.NewString("b"),
+			})},
+		},
+		{
+			name: "documents",
+			data: "a: 1\n---\na: 2\n",
+			want: []values.Value{
+				values.NewObjectWithValues(map[string]values.Value{"a": values.NewInt(1)}),
+				values.NewObjectWithValues(map[string]values.Value{"a": values.NewInt(2)}),
+			},
+		},
+		{
+			name:    "invalid",
+			data:    "a: [1",
+			wantErr: "failed to parse YAML: yaml: line 1: did not find expected ',' or ']'",
+		},
+	}
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parse([]byte(tc.data))
+			if tc.wantErr != "" {
+				if err == nil {
+					t.Fatalf("expected error: %s", tc.wantErr)
+				}
+				if want, got := tc.wantErr, err.Error(); want != got {
+					t.Fatalf("unexpected error -want/+got:\n\t- %s\n\t+ %s", want, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tc.want) != len(got) {
+				t.Fatalf("unexpected number of documents -want/+got: %d/%d", len(tc.want), len(got))
+			}
+			for i := range tc.want {
+				if !tc.want[i].Equal(got[i]) {
+					t.Errorf("unequal values in document %d\nwant: %v\ngot:  %v", i, tc.want[i], got[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseNull(t *testing.T) {
+	docs, err := parse([]byte("a: ~\nb: [1, null]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, ok := docs[0].Object().Get("a")
+	if !ok || !a.IsNull() {
+		t.Errorf("expected property a to be null, got %v", a)
+	}
+	b, _ := docs[0].Object().Get("b")
+	if want, got := semantic.NewArrayType(semantic.BasicInt), b.Type(); !want.Equal(got) {
+		t.Fatalf("unexpected type -want/+got: %v/%v", want, got)
+	}
+	if v := b.Array().Get(1); !v.IsNull() {
+		t.Errorf("expected the second element to be null, got %v", v)
+	}
+}
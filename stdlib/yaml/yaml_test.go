@@ -0,0 +1,51 @@
+package yaml_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/influxdata/flux/dependencies/dependenciestest"
+	_ "github.com/influxdata/flux/fluxinit/static"
+	"github.com/influxdata/flux/runtime"
+)
+
+func TestYAMLEncode(t *testing.T) {
+	script := `
+import "yaml"
+import "internal/testutil"
+
+o = {
+    a: 1,
+    b: {
+        x: [1, 2],
+        y: "string",
+        z: 1m,
+    },
+    c: 1.1,
+    d: false,
+    e: /.*/,
+    f: 2019-08-14T10:03:12Z,
+}
+string(v: yaml.encode(v: o)) == "a: 1\nb:\n  x:\n  - 1\n  - 2\n  \"y\": string\n  z: 1m\nc: 1.1\nd: false\ne: .*\nf: \"2019-08-14T10:03:12Z\"\n" or testutil.fail()
+`
+	ctx := dependenciestest.Default().Inject(context.Background())
+	if _, _, err := runtime.Eval(ctx, script); err != nil {
+		t.Fatal("evaluation of yaml.encode failed: ", err)
+	}
+}
+
+func TestYAMLParse(t *testing.T) {
+	script := `
+import "yaml"
+import "internal/testutil"
+
+o = yaml.parse(data: "a: 1\nb:\n  x: [1.5, 2]\nc: string\n")
+(o.a == 1 and o.b.x[1] == 2.0 and o.c == "string") or testutil.fail()
+docs = yaml.parseAll(data: bytes(v: "name: web\n---\nname: db\n"))
+(length(arr: docs) == 2 and docs[1].name == "db") or testutil.fail()
+`
+	ctx := dependenciestest.Default().Inject(context.Background())
+	if _, _, err := runtime.Eval(ctx, script); err != nil {
+		t.Fatal("evaluation of yaml.parse failed: ", err)
+	}
+}
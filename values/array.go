@@ -21,6 +21,15 @@ type Array interface {
 	Sort(func(i, j Value) bool)
 }
 
+// Stream is an array that is a stream of tables, such as the value
+// returned by a transformation. Its tables are only produced when it
+// is executed, so its elements and its length cannot be read.
+type Stream interface {
+	Array
+	// IsStream marks the array as a stream of tables.
+	IsStream()
+}
+
 type array struct {
 	t        semantic.MonoType
 	elements []Value
@@ -0,0 +1,675 @@
+package values
+
+import (
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/internal/errors"
+	"github.com/influxdata/flux/semantic"
+)
+
+// Encode converts the Go value to a Value.
+//
+// Booleans, integers, unsigned integers, floats, strings, byte slices,
+// time.Time, time.Duration, Time, Duration and *regexp.Regexp are
+// converted to the matching basic types. Slices and arrays are converted
+// to arrays, maps with string keys to records and other maps to
+// dictionaries. Structs are converted to records.
+//
+// The properties of the record of a struct are its exported fields.
+// The name of a property is the name of its field, unless the field
+// has a flux tag:
+//
+//	type Point struct {
+//		Name  string    `flux:"_measurement"`
+//		Value *float64  `flux:"_value"`
+//		Time  time.Time `flux:"_time"`
+//		Extra string    `flux:"-"`
+//	}
+//
+// A field with the tag "-" is skipped. A nil pointer is converted to a
+// null value, with the type of its element where it can be determined,
+// and a Value is used as is.
+func Encode(v interface{}) (Value, error) {
+	return EncodeAs(v, semantic.MonoType{})
+}
+
+// EncodeAs converts the Go value to a Value like Encode, guided by the type
+// where the Go value does not determine the type of the Value, such as for
+// null values, empty arrays and numbers. Integers are converted to floats
+// where the type is float, for instance. The type is ignored where it is
+// a type variable.
+func EncodeAs(v interface{}, t semantic.MonoType) (Value, error) {
+	if v == nil {
+		return nullOf(t), nil
+	}
+	return encode(reflect.ValueOf(v), t)
+}
+
+var (
+	goTimeType     = reflect.TypeOf(time.Time{})
+	goDurationType = reflect.TypeOf(time.Duration(0))
+	timeType       = reflect.TypeOf(Time(0))
+	durationType   = reflect.TypeOf(Duration{})
+	regexpType     = reflect.TypeOf((*regexp.Regexp)(nil))
+	valueType      = reflect.TypeOf((*Value)(nil)).Elem()
+)
+
+func nullOf(t semantic.MonoType) Value {
+	if t.Nature() == semantic.Invalid {
+		return Null
+	}
+	return NewNull(t)
+}
+
+func encode(rv reflect.Value, t semantic.MonoType) (Value, error) {
+	if (rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface) && rv.IsNil() {
+		if t.Nature() == semantic.Invalid {
+			t, _ = typeOf(rv.Type())
+		}
+		return nullOf(t), nil
+	}
+	if rv.Type().Implements(valueType) {
+		return rv.Interface().(Value), nil
+	}
+	switch rv.Type() {
+	case goTimeType:
+		return NewTime(ConvertTime(rv.Interface().(time.Time))), nil
+	case goDurationType:
+		return NewDuration(ConvertDurationNsecs(time.Duration(rv.Int()))), nil
+	case timeType:
+		return NewTime(Time(rv.Int())), nil
+	case durationType:
+		return NewDuration(rv.Interface().(Duration)), nil
+	case regexpType:
+		return NewRegexp(rv.Interface().(*regexp.Regexp)), nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		return NewBool(rv.Bool()), nil
+	case reflect.String:
+		return NewString(rv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch t.Nature() {
+		case semantic.Float:
+			return NewFloat(float64(rv.Int())), nil
+		case semantic.UInt:
+			if rv.Int() < 0 {
+				return nil, errors.Newf(codes.Invalid, "cannot convert negative integer %d to uint", rv.Int())
+			}
+			return NewUInt(uint64(rv.Int())), nil
+		}
+		return NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch t.Nature() {
+		case semantic.Float:
+			return NewFloat(float64(rv.Uint())), nil
+		case semantic.Int:
+			return NewInt(int64(rv.Uint())), nil
+		}
+		return NewUInt(rv.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return NewFloat(rv.Float()), nil
+	case reflect.Ptr, reflect.Interface:
+		return encode(rv.Elem(), t)
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.Type().Elem().Kind() == reflect.Uint8 {
+			return NewBytes(rv.Bytes()), nil
+		}
+		return encodeArray(rv, t)
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String && t.Nature() != semantic.Dictionary {
+			return encodeMapRecord(rv, t)
+		}
+		return encodeDict(rv, t)
+	case reflect.Struct:
+		return encodeStruct(rv, t)
+	}
+	return nil, errors.Newf(codes.Invalid, "cannot convert a value of type %s to a Flux value", rv.Type())
+}
+
+// typeOf returns the type of the values the Go type is converted to,
+// if it does not depend on the value.
+func typeOf(typ reflect.Type) (semantic.MonoType, bool) {
+	return typeOfVisiting(typ, make(map[reflect.Type]bool))
+}
+
+// typeOfVisiting returns the type of the values the Go type is
+// converted to. The struct types that are visited are recorded,
+// as the type of a recursive struct depends on the value.
+func typeOfVisiting(typ reflect.Type, visiting map[reflect.Type]bool) (semantic.MonoType, bool) {
+	switch typ {
+	case goTimeType, timeType:
+		return semantic.BasicTime, true
+	case goDurationType, durationType:
+		return semantic.BasicDuration, true
+	case regexpType:
+		return semantic.BasicRegexp, true
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		return semantic.BasicBool, true
+	case reflect.String:
+		return semantic.BasicString, true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return semantic.BasicInt, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return semantic.BasicUint, true
+	case reflect.Float32, reflect.Float64:
+		return semantic.BasicFloat, true
+	case reflect.Ptr:
+		return typeOfVisiting(typ.Elem(), visiting)
+	case reflect.Slice, reflect.Array:
+		if typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8 {
+			return semantic.BasicBytes, true
+		}
+		if et, ok := typeOfVisiting(typ.Elem(), visiting); ok {
+			return semantic.NewArrayType(et), true
+		}
+	case reflect.Struct:
+		if visiting[typ] {
+			return semantic.MonoType{}, false
+		}
+		visiting[typ] = true
+		defer delete(visiting, typ)
+		fields := structFields(typ)
+		props := make([]semantic.PropertyType, 0, len(fields))
+		for _, f := range fields {
+			ft, ok := typeOfVisiting(typ.Field(f.index).Type, visiting)
+			if !ok {
+				return semantic.MonoType{}, false
+			}
+			props = append(props, semantic.PropertyType{Key: []byte(f.name), Value: ft})
+		}
+		return semantic.NewObjectType(props), true
+	}
+	return semantic.MonoType{}, false
+}
+
+func encodeArray(rv reflect.Value, t semantic.MonoType) (Value, error) {
+	var elemType semantic.MonoType
+	if t.Nature() == semantic.Array {
+		et, err := t.ElemType()
+		if err != nil {
+			return nil, err
+		}
+		elemType = et
+	}
+	elements := make([]Value, rv.Len())
+	for i := range elements {
+		v, err := encode(rv.Index(i), elemType)
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = v
+	}
+	if elemType.Nature() == semantic.Invalid {
+		if et, ok := typeOf(rv.Type().Elem()); ok {
+			elemType = et
+		} else if len(elements) > 0 {
+			elemType = elements[0].Type()
+		} else {
+			return nil, errors.New(codes.Invalid, "cannot infer the element type of an empty array")
+		}
+	}
+	return NewArrayWithBacking(semantic.NewArrayType(elemType), elements), nil
+}
+
+// propertyTypes returns the types of the properties of the record type.
+func propertyTypes(t semantic.MonoType) (map[string]semantic.MonoType, error) {
+	types := make(map[string]semantic.MonoType)
+	if t.Nature() != semantic.Object {
+		return types, nil
+	}
+	n, err := t.NumProperties()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < n; i++ {
+		p, err := t.RecordProperty(i)
+		if err != nil {
+			return nil, err
+		}
+		if types[p.Name()], err = p.TypeOf(); err != nil {
+			return nil, err
+		}
+	}
+	return types, nil
+}
+
+func encodeMapRecord(rv reflect.Value, t semantic.MonoType) (Value, error) {
+	types, err := propertyTypes(t)
+	if err != nil {
+		return nil, err
+	}
+	vals := make(map[string]Value, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k := iter.Key().String()
+		v, err := encode(iter.Value(), types[k])
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Inherit, "property %q", k)
+		}
+		vals[k] = v
+	}
+	return NewObjectWithValues(vals), nil
+}
+
+type structField struct {
+	index int
+	name  string
+}
+
+// structFields returns the fields of the struct type
+// that are properties of its records.
+func structFields(typ reflect.Type) []structField {
+	fields := make([]structField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		if f.PkgPath != "" {
+			// The field is not exported.
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("flux"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag = strings.TrimSpace(tag); tag != "" {
+				name = tag
+			}
+		}
+		fields = append(fields, structField{index: i, name: name})
+	}
+	return fields
+}
+
+func encodeStruct(rv reflect.Value, t semantic.MonoType) (Value, error) {
+	types, err := propertyTypes(t)
+	if err != nil {
+		return nil, err
+	}
+	fields := structFields(rv.Type())
+	vals := make(map[string]Value, len(fields))
+	for _, f := range fields {
+		v, err := encode(rv.Field(f.index), types[f.name])
+		if err != nil {
+			return nil, errors.Wrapf(err, codes.Inherit, "property %q", f.name)
+		}
+		vals[f.name] = v
+	}
+	return NewObjectWithValues(vals), nil
+}
+
+func encodeDict(rv reflect.Value, t semantic.MonoType) (Value, error) {
+	var keyType, valueType semantic.MonoType
+	if t.Nature() == semantic.Dictionary {
+		var err error
+		if keyType, err = t.KeyType(); err != nil {
+			return nil, err
+		}
+		if valueType, err = t.ValueType(); err != nil {
+			return nil, err
+		}
+	}
+	type entry struct{ k, v Value }
+	entries := make([]entry, 0, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		k, err := encode(iter.Key(), keyType)
+		if err != nil {
+			return nil, err
+		}
+		v, err := encode(iter.Value(), valueType)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry{k: k, v: v})
+	}
+	if keyType.Nature() == semantic.Invalid {
+		kt, ok := typeOf(rv.Type().Key())
+		if !ok && len(entries) > 0 {
+			kt, ok = entries[0].k.Type(), true
+		}
+		if !ok {
+			return nil, errors.New(codes.Invalid, "cannot infer the type of an empty dictionary")
+		}
+		keyType = kt
+	}
+	if valueType.Nature() == semantic.Invalid {
+		vt, ok := typeOf(rv.Type().Elem())
+		if !ok && len(entries) > 0 {
+			vt, ok = entries[0].v.Type(), true
+		}
+		if !ok {
+			return nil, errors.New(codes.Invalid, "cannot infer the type of an empty dictionary")
+		}
+		valueType = vt
+	}
+	b := NewDictBuilder(semantic.NewDictType(keyType, valueType))
+	for _, e := range entries {
+		if err := b.Insert(e.k, e.v); err != nil {
+			return nil, err
+		}
+	}
+	return b.Dict(), nil
+}
+
+// Decode stores the Value in the Go value that out points to.
+//
+// It is the reverse of Encode: records are stored in structs, using the
+// same names for the fields, or in maps with string keys, arrays in slices
+// and dictionaries in maps. A null value is stored as a nil pointer, or as
+// the zero value of types that are not pointers. Numbers are converted to
+// the type of the Go value, and a property of a record that has no field
+// in a struct is ignored.
+//
+// When out points to an empty interface, records are stored as
+// map[string]interface{}, arrays as []interface{}, dictionaries as
+// map[interface{}]interface{}, times as time.Time and durations without
+// months as time.Duration. Other basic values are stored as they are
+// returned by Unwrap.
+func Decode(v Value, out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.Newf(codes.Invalid, "cannot decode into a value of type %T; expected a non-nil pointer", out)
+	}
+	return decode(v, rv.Elem())
+}
+
+func decode(v Value, rv reflect.Value) error {
+	if rv.Type().Implements(valueType) && rv.Kind() == reflect.Interface {
+		rv.Set(reflect.ValueOf(v))
+		return nil
+	}
+	if v.IsNull() {
+		rv.Set(reflect.Zero(rv.Type()))
+		return nil
+	}
+	if rv.Type() == regexpType {
+		if v.Type().Nature() != semantic.Regexp {
+			return errors.Newf(codes.Invalid, "cannot decode a value of type %s into a value of type %s", v.Type(), rv.Type())
+		}
+		rv.Set(reflect.ValueOf(v.Regexp()))
+		return nil
+	}
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decode(v, rv.Elem())
+	}
+	if rv.Kind() == reflect.Interface && rv.NumMethod() == 0 {
+		gv, err := decodeAny(v)
+		if err != nil {
+			return err
+		}
+		if gv != nil {
+			rv.Set(reflect.ValueOf(gv))
+		}
+		return nil
+	}
+
+	mismatch := func() error {
+		return errors.Newf(codes.Invalid, "cannot decode a value of type %s into a value of type %s", v.Type(), rv.Type())
+	}
+	overflow := func() error {
+		return errors.Newf(codes.Invalid, "cannot decode %v into a value of type %s; it is out of range", v, rv.Type())
+	}
+	n := v.Type().Nature()
+	switch rv.Type() {
+	case goTimeType:
+		if n != semantic.Time {
+			return mismatch()
+		}
+		rv.Set(reflect.ValueOf(v.Time().Time()))
+		return nil
+	case goDurationType:
+		if n != semantic.Duration {
+			return mismatch()
+		}
+		d := v.Duration()
+		if d.Months() != 0 {
+			return errors.Newf(codes.Invalid, "cannot decode duration %v with months into a time.Duration", d)
+		}
+		rv.SetInt(int64(d.Duration()))
+		return nil
+	case timeType:
+		if n != semantic.Time {
+			return mismatch()
+		}
+		rv.SetInt(int64(v.Time()))
+		return nil
+	case durationType:
+		if n != semantic.Duration {
+			return mismatch()
+		}
+		rv.Set(reflect.ValueOf(v.Duration()))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		if n != semantic.Bool {
+			return mismatch()
+		}
+		rv.SetBool(v.Bool())
+	case reflect.String:
+		if n != semantic.String {
+			return mismatch()
+		}
+		rv.SetString(v.Str())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		switch n {
+		case semantic.Int:
+			i = v.Int()
+		case semantic.UInt:
+			if v.UInt() > math.MaxInt64 {
+				return overflow()
+			}
+			i = int64(v.UInt())
+		case semantic.Float:
+			f := v.Float()
+			if f != math.Trunc(f) {
+				return errors.Newf(codes.Invalid, "cannot decode the non-integral float %v into a value of type %s", f, rv.Type())
+			} else if f < math.MinInt64 || f >= -math.MinInt64 {
+				return overflow()
+			}
+			i = int64(f)
+		default:
+			return mismatch()
+		}
+		if rv.OverflowInt(i) {
+			return overflow()
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		switch n {
+		case semantic.Int:
+			if v.Int() < 0 {
+				return overflow()
+			}
+			u = uint64(v.Int())
+		case semantic.UInt:
+			u = v.UInt()
+		case semantic.Float:
+			f := v.Float()
+			if f != math.Trunc(f) {
+				return errors.Newf(codes.Invalid, "cannot decode the non-integral float %v into a value of type %s", f, rv.Type())
+			} else if f < 0 || f >= math.MaxUint64 {
+				return overflow()
+			}
+			u = uint64(f)
+		default:
+			return mismatch()
+		}
+		if rv.OverflowUint(u) {
+			return overflow()
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		switch n {
+		case semantic.Int:
+			rv.SetFloat(float64(v.Int()))
+		case semantic.UInt:
+			rv.SetFloat(float64(v.UInt()))
+		case semantic.Float:
+			rv.SetFloat(v.Float())
+		default:
+			return mismatch()
+		}
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 && n == semantic.Bytes {
+			rv.SetBytes(v.Bytes())
+			return nil
+		}
+		if n != semantic.Array {
+			return mismatch()
+		}
+		if _, ok := v.(Stream); ok {
+			return errors.Newf(codes.Invalid, "cannot decode a stream of tables into a value of type %s", rv.Type())
+		}
+		arr := v.Array()
+		s := reflect.MakeSlice(rv.Type(), arr.Len(), arr.Len())
+		var err error
+		arr.Range(func(i int, v Value) {
+			if err == nil {
+				err = decode(v, s.Index(i))
+			}
+		})
+		if err != nil {
+			return err
+		}
+		rv.Set(s)
+	case reflect.Map:
+		return decodeMap(v, rv, mismatch)
+	case reflect.Struct:
+		if n != semantic.Object {
+			return mismatch()
+		}
+		obj := v.Object()
+		for _, f := range structFields(rv.Type()) {
+			pv, ok := obj.Get(f.name)
+			if !ok {
+				continue
+			}
+			if err := decode(pv, rv.Field(f.index)); err != nil {
+				return errors.Wrapf(err, codes.Inherit, "property %q", f.name)
+			}
+		}
+	default:
+		return mismatch()
+	}
+	return nil
+}
+
+func decodeMap(v Value, rv reflect.Value, mismatch func() error) error {
+	m := reflect.MakeMap(rv.Type())
+	switch n := v.Type().Nature(); {
+	case n == semantic.Object && rv.Type().Key().Kind() == reflect.String:
+		var err error
+		v.Object().Range(func(k string, v Value) {
+			if err != nil {
+				return
+			}
+			ev := reflect.New(rv.Type().Elem()).Elem()
+			if err = decode(v, ev); err != nil {
+				err = errors.Wrapf(err, codes.Inherit, "property %q", k)
+				return
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(rv.Type().Key()), ev)
+		})
+		if err != nil {
+			return err
+		}
+	case n == semantic.Dictionary:
+		var err error
+		v.Dict().Range(func(key, value Value) {
+			if err != nil {
+				return
+			}
+			kv := reflect.New(rv.Type().Key()).Elem()
+			if err = decode(key, kv); err != nil {
+				return
+			}
+			ev := reflect.New(rv.Type().Elem()).Elem()
+			if err = decode(value, ev); err != nil {
+				return
+			}
+			m.SetMapIndex(kv, ev)
+		})
+		if err != nil {
+			return err
+		}
+	default:
+		return mismatch()
+	}
+	rv.Set(m)
+	return nil
+}
+
+// decodeAny converts the Value to the Go value stored
+// in an empty interface by Decode.
+func decodeAny(v Value) (interface{}, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+	switch v.Type().Nature() {
+	case semantic.Time:
+		return v.Time().Time(), nil
+	case semantic.Duration:
+		// Durations with months have no time.Duration equivalent.
+		if d := v.Duration(); d.Months() != 0 {
+			return d, nil
+		}
+		return v.Duration().Duration(), nil
+	case semantic.Array:
+		if _, ok := v.(Stream); ok {
+			return nil, errors.New(codes.Invalid, "cannot decode a stream of tables")
+		}
+		arr := v.Array()
+		a := make([]interface{}, arr.Len())
+		var err error
+		arr.Range(func(i int, v Value) {
+			if err == nil {
+				a[i], err = decodeAny(v)
+			}
+		})
+		return a, err
+	case semantic.Object:
+		obj := v.Object()
+		o := make(map[string]interface{}, obj.Len())
+		var err error
+		obj.Range(func(k string, v Value) {
+			if err == nil {
+				o[k], err = decodeAny(v)
+			}
+		})
+		return o, err
+	case semantic.Dictionary:
+		d := make(map[interface{}]interface{}, v.Dict().Len())
+		var err error
+		v.Dict().Range(func(key, value Value) {
+			if err != nil {
+				return
+			}
+			var k, val interface{}
+			if k, err = decodeAny(key); err != nil {
+				return
+			}
+			if val, err = decodeAny(value); err != nil {
+				return
+			}
+			d[k] = val
+		})
+		return d, err
+	}
+	return Unwrap(v), nil
+}
@@ -0,0 +1,247 @@
+package values_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/flux"
+	"github.com/influxdata/flux/codes"
+	"github.com/influxdata/flux/semantic"
+	"github.com/influxdata/flux/values"
+)
+
+type location struct {
+	Lat float64 `flux:"lat"`
+	Lon float64 `flux:"lon"`
+}
+
+type point struct {
+	Name     string    `flux:"_measurement"`
+	Value    *float64  `flux:"_value"`
+	Time     time.Time `flux:"_time"`
+	Tags     []string  `flux:"tags"`
+	Location location  `flux:"location"`
+	Count    int
+	Ignored  string `flux:"-"`
+	internal string
+}
+
+type array = values.Array
+
+// stream is a stream of tables, which
+// panics when its elements are read.
+type stream struct {
+	array
+}
+
+func (s stream) Array() values.Array { return s }
+func (stream) IsStream()             {}
+func (stream) Len() int              { panic("length of stream not supported") }
+func (stream) Range(func(i int, v values.Value)) {
+	panic("cannot range over values in stream")
+}
+
+func TestEncode_Struct(t *testing.T) {
+	ts := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	v, err := values.Encode(point{
+		Name:     "cpu",
+		Time:     ts,
+		Tags:     []string{},
+		Location: location{Lat: 1.5, Lon: -2},
+		Count:    3,
+		Ignored:  "ignored",
+		internal: "internal",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := values.NewObjectWithValues(map[string]values.Value{
+		"_measurement": values.NewString("cpu"),
+		"_value":       values.NewNull(semantic.BasicFloat),
+		"_time":        values.NewTime(values.ConvertTime(ts)),
+		"tags":         values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicString), nil),
+		"location": values.NewObjectWithValues(map[string]values.Value{
+			"lat": values.NewFloat(1.5),
+			"lon": values.NewFloat(-2),
+		}),
+		"Count": values.NewInt(3),
+	})
+	// Null values are not equal to each other,
+	// so the properties are compared one by one.
+	want.Range(func(k string, wv values.Value) {
+		gv, _ := v.Object().Get(k)
+		if wv.IsNull() && gv.IsNull() {
+			return
+		}
+		if !wv.Equal(gv) {
+			t.Errorf("unexpected property %q -want/+got:\n\t- %v\n\t+ %v", k, wv, gv)
+		}
+	})
+	if want, got := want.Type().String(), v.Type().String(); want != got {
+		t.Errorf("unexpected type -want/+got:\n\t- %s\n\t+ %s", want, got)
+	}
+}
+
+func TestDecode_Struct(t *testing.T) {
+	ts := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	v := values.NewObjectWithValues(map[string]values.Value{
+		"_measurement": values.NewString("cpu"),
+		"_value":       values.NewFloat(2.5),
+		"_time":        values.NewTime(values.ConvertTime(ts)),
+		"tags": values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicString), []values.Value{
+			values.NewString("a"),
+			values.NewString("b"),
+		}),
+		"location": values.NewObjectWithValues(map[string]values.Value{
+			"lat": values.NewFloat(1.5),
+			"lon": values.NewInt(-2),
+		}),
+		"Count": values.NewInt(3),
+		"extra": values.NewString("extra"),
+	})
+
+	var got point
+	if err := values.Decode(v, &got); err != nil {
+		t.Fatal(err)
+	}
+	value := 2.5
+	want := point{
+		Name:     "cpu",
+		Value:    &value,
+		Time:     ts,
+		Tags:     []string{"a", "b"},
+		Location: location{Lat: 1.5, Lon: -2},
+		Count:    3,
+	}
+	if !cmp.Equal(want, got, cmp.AllowUnexported(point{})) {
+		t.Errorf("unexpected value -want/+got:\n%s", cmp.Diff(want, got, cmp.AllowUnexported(point{})))
+	}
+
+	// A null value is stored as a nil pointer.
+	got.Value = &value
+	if err := values.Decode(values.NewObjectWithValues(map[string]values.Value{
+		"_value": values.NewNull(semantic.BasicFloat),
+	}), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Value != nil {
+		t.Errorf("expected a nil pointer, got %v", *got.Value)
+	}
+}
+
+func TestEncode_RoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		v    interface{}
+	}{
+		{name: "string", v: "abc"},
+		{name: "int", v: int64(-3)},
+		{name: "uint", v: uint64(3)},
+		{name: "float", v: 1.5},
+		{name: "bool", v: true},
+		{name: "bytes", v: []byte("abc")},
+		{name: "time", v: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "duration", v: time.Minute},
+		{name: "array", v: []interface{}{int64(1), int64(2)}},
+		{name: "record", v: map[string]interface{}{"a": "b", "c": nil}},
+		{name: "dict", v: map[interface{}]interface{}{int64(1): "a", int64(2): "b"}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v, err := values.Encode(tc.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got interface{}
+			if err := values.Decode(v, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !cmp.Equal(tc.v, got) {
+				t.Errorf("unexpected value -want/+got:\n%s", cmp.Diff(tc.v, got))
+			}
+		})
+	}
+}
+
+func TestEncodeAs(t *testing.T) {
+	v, err := values.EncodeAs([]int{1, 2}, semantic.NewArrayType(semantic.BasicFloat))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := values.NewArrayWithBacking(semantic.NewArrayType(semantic.BasicFloat), []values.Value{
+		values.NewFloat(1),
+		values.NewFloat(2),
+	})
+	if !want.Equal(v) {
+		t.Errorf("unexpected value -want/+got:\n\t- %v\n\t+ %v", want, v)
+	}
+
+	v, err = values.EncodeAs(nil, semantic.BasicString)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !v.IsNull() || v.Type().Nature() != semantic.String {
+		t.Errorf("expected a null string, got %v of type %v", v, v.Type())
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	var s string
+	if err := values.Decode(values.NewInt(1), &s); err == nil {
+		t.Error("expected an error decoding an int into a string")
+	} else if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+		t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+	}
+
+	if err := values.Decode(values.NewString("a"), s); err == nil {
+		t.Error("expected an error decoding into a value that is not a pointer")
+	}
+
+	var d time.Duration
+	months := values.NewDuration(values.ConvertDurationMonths(1))
+	if err := values.Decode(months, &d); err == nil {
+		t.Error("expected an error decoding a duration with months into a time.Duration")
+	}
+
+	for _, tt := range []struct {
+		v    values.Value
+		into interface{}
+	}{
+		{v: values.NewInt(128), into: new(int8)},
+		{v: values.NewInt(-1), into: new(uint64)},
+		{v: values.NewUInt(256), into: new(uint8)},
+		{v: values.NewUInt(math.MaxUint64), into: new(int64)},
+		{v: values.NewFloat(1.5), into: new(int64)},
+		{v: values.NewFloat(math.NaN()), into: new(int64)},
+		{v: values.NewFloat(1e19), into: new(int64)},
+		{v: values.NewFloat(-1), into: new(uint32)},
+		{v: values.NewFloat(70000), into: new(uint16)},
+	} {
+		if err := values.Decode(tt.v, tt.into); err == nil {
+			t.Errorf("expected an error decoding %v into %T", tt.v, tt.into)
+		} else if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+			t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+		}
+	}
+	var i8 int8
+	if err := values.Decode(values.NewFloat(-128), &i8); err != nil {
+		t.Errorf("unexpected error decoding -128.0 into an int8: %v", err)
+	} else if i8 != -128 {
+		t.Errorf("unexpected int8 -want/+got:\n\t- %d\n\t+ %d", -128, i8)
+	}
+
+	tables := stream{values.NewArray(semantic.NewArrayType(semantic.BasicInt))}
+	for _, into := range []interface{}{new([]int64), new(interface{})} {
+		if err := values.Decode(tables, into); err == nil {
+			t.Errorf("expected an error decoding a stream into %T", into)
+		} else if want, got := codes.Invalid, flux.ErrorCode(err); want != got {
+			t.Errorf("unexpected error code -want/+got:\n\t- %v\n\t+ %v", want, got)
+		}
+	}
+
+	if _, err := values.Encode(make(chan int)); err == nil {
+		t.Error("expected an error encoding a channel")
+	}
+}